@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/rhysd/actionlint"
+	"gopkg.in/yaml.v3"
+)
+
+// actionMetadataConfig configures sync_action_metadata, which crawls the
+// actions of organizations into the metadata file of rules.actions.
+type actionMetadataConfig struct {
+	// Organizations are crawled when the call names none.
+	Organizations []string `yaml:"organizations"`
+	// APIURL is the REST API of a GitHub Enterprise Server, such as
+	// https://github.example.com/api/v3. It defaults to github.com's.
+	APIURL string `yaml:"api-url"`
+}
+
+func (c actionMetadataConfig) validate() error {
+	if c.APIURL == "" {
+		return nil
+	}
+	u, err := url.Parse(c.APIURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("action-metadata: api-url must be an http or https URL, not %q", c.APIURL)
+	}
+	return nil
+}
+
+// apiURL returns the REST API the actions are crawled from.
+func (c actionMetadataConfig) apiURL() string {
+	if c.APIURL == "" {
+		return githubAPIBaseURL
+	}
+	return strings.TrimSuffix(c.APIURL, "/")
+}
+
+// actionMetadataEntry is an action in the metadata file, in the format of
+// actionlint's popular actions dataset.
+type actionMetadataEntry struct {
+	Name    string                           `json:"name"`
+	Inputs  actionlint.ActionMetadataInputs  `json:"inputs"`
+	Outputs actionlint.ActionMetadataOutputs `json:"outputs"`
+}
+
+// crawlFailure is a repository, or an action of one, that could not be
+// crawled. Its actions are kept as they were in the metadata file.
+type crawlFailure struct {
+	Repository string `json:"repository"`
+	Path       string `json:"path,omitempty"`
+	Error      string `json:"error"`
+}
+
+// syncedActionMetadata is the sync_action_metadata output.
+type syncedActionMetadata struct {
+	File          string              `json:"file"`
+	Organizations []string            `json:"organizations"`
+	Repositories  int                 `json:"repositories"`
+	Actions       []string            `json:"actions"`
+	Removed       []string            `json:"removed,omitempty"`
+	Failed        []crawlFailure      `json:"failed,omitempty"`
+	Changes       []linter.FileChange `json:"changes"`
+	Written       bool                `json:"written"`
+}
+
+// crawledActions are the actions found in the repositories of some
+// organizations, by spec without a ref.
+type crawledActions struct {
+	actions      map[string]actionMetadataEntry
+	repositories []string
+	failed       []crawlFailure
+}
+
+// crawlActionMetadata reads the action.yml of every action in the
+// repositories of orgs from their default branch, concurrency repositories
+// at a time. A repository or action that cannot be read is reported in
+// failed rather than failing the crawl.
+func crawlActionMetadata(ctx context.Context, client *http.Client, apiURL string, orgs []string, includeArchived bool, concurrency int) (*crawledActions, error) {
+	var repos []string
+	for _, org := range orgs {
+		listed, err := listOrganizationRepositories(ctx, client, apiURL, org, includeArchived)
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, listed...)
+	}
+
+	out := &crawledActions{actions: map[string]actionMetadataEntry{}, repositories: repos}
+	var mu sync.Mutex
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, repo := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			actions, failed := crawlRepositoryActions(ctx, client, apiURL, repo)
+			mu.Lock()
+			defer mu.Unlock()
+			for spec, a := range actions {
+				out.actions[spec] = a
+			}
+			out.failed = append(out.failed, failed...)
+		}()
+	}
+	wg.Wait()
+	sort.Slice(out.failed, func(i, j int) bool {
+		if out.failed[i].Repository != out.failed[j].Repository {
+			return out.failed[i].Repository < out.failed[j].Repository
+		}
+		return out.failed[i].Path < out.failed[j].Path
+	})
+	return out, ctx.Err()
+}
+
+// crawlRepositoryActions reads the metadata of the actions of repo, given
+// as owner/name: its root action and those in subdirectories, which are
+// used as owner/name/path. Vendored dependencies are skipped.
+func crawlRepositoryActions(ctx context.Context, client *http.Client, apiURL, repo string) (map[string]actionMetadataEntry, []crawlFailure) {
+	data, err := githubGet(ctx, client, fmt.Sprintf("%s/repos/%s/git/trees/HEAD?recursive=1", apiURL, repo))
+	var status *statusError
+	if errors.As(err, &status) && (status.code == http.StatusNotFound || status.code == http.StatusConflict) {
+		// Empty repositories have no tree
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []crawlFailure{{Repository: repo, Error: err.Error()}}
+	}
+	var tree struct {
+		Tree []struct {
+			Path string `json:"path"`
+			Type string `json:"type"`
+		} `json:"tree"`
+		Truncated bool `json:"truncated"`
+	}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, []crawlFailure{{Repository: repo, Error: err.Error()}}
+	}
+
+	// action.yml takes precedence over action.yaml, as on GitHub
+	files := map[string]string{}
+	for _, e := range tree.Tree {
+		name := path.Base(e.Path)
+		if e.Type != "blob" || (name != "action.yml" && name != "action.yaml") || strings.Contains("/"+e.Path, "/node_modules/") {
+			continue
+		}
+		dir := path.Dir(e.Path)
+		if prev, ok := files[dir]; !ok || path.Base(prev) == "action.yaml" {
+			files[dir] = e.Path
+		}
+	}
+
+	var failed []crawlFailure
+	if tree.Truncated {
+		failed = append(failed, crawlFailure{Repository: repo, Error: "the repository has too many files to list, so some actions may be missing"})
+	}
+	actions := map[string]actionMetadataEntry{}
+	for dir, p := range files {
+		content, err := fetchRepositoryFile(ctx, client, apiURL, repo, p)
+		if err != nil {
+			failed = append(failed, crawlFailure{Repository: repo, Path: p, Error: err.Error()})
+			continue
+		}
+		var meta actionlint.ActionMetadata
+		if err := yaml.Unmarshal(content, &meta); err != nil {
+			failed = append(failed, crawlFailure{Repository: repo, Path: p, Error: err.Error()})
+			continue
+		}
+		spec := strings.ToLower(repo)
+		if dir != "." {
+			spec += "/" + strings.ToLower(dir)
+		}
+		entry := actionMetadataEntry{Name: meta.Name, Inputs: meta.Inputs, Outputs: meta.Outputs}
+		if entry.Inputs == nil {
+			entry.Inputs = actionlint.ActionMetadataInputs{}
+		}
+		if entry.Outputs == nil {
+			entry.Outputs = actionlint.ActionMetadataOutputs{}
+		}
+		actions[spec] = entry
+	}
+	return actions, failed
+}
+
+// fetchRepositoryFile downloads the file at p of repo, given as
+// owner/name, from its default branch.
+func fetchRepositoryFile(ctx context.Context, client *http.Client, apiURL, repo, p string) ([]byte, error) {
+	data, err := githubGet(ctx, client, fmt.Sprintf("%s/repos/%s/contents/%s", apiURL, repo, p))
+	if err != nil {
+		return nil, err
+	}
+	var file repositoryContent
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", p, err)
+	}
+	if file.Encoding != "base64" {
+		return nil, fmt.Errorf("%s has unsupported encoding %q", p, file.Encoding)
+	}
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", p, err)
+	}
+	return content, nil
+}
+
+// actionMetadataChange returns the change making the metadata file at
+// file hold the crawled actions of orgs. Entries of other owners and for
+// a single ref are kept, and so are those of repositories that failed to
+// be crawled, while the other actions of orgs no longer found are removed.
+func actionMetadataChange(file string, orgs []string, crawled *crawledActions) (linter.FileChange, []string, error) {
+	existing, err := os.ReadFile(file)
+	created := os.IsNotExist(err)
+	if err != nil && !created {
+		return linter.FileChange{}, nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	entries := map[string]any{}
+	if len(existing) > 0 {
+		var old map[string]json.RawMessage
+		if err := json.Unmarshal(existing, &old); err != nil {
+			return linter.FileChange{}, nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		for spec, raw := range old {
+			entries[spec] = raw
+		}
+	}
+
+	failed := map[string]bool{}
+	for _, f := range crawled.failed {
+		failed[strings.ToLower(f.Repository)] = true
+	}
+	var removed []string
+	for spec := range entries {
+		owner, rest, _ := strings.Cut(strings.ToLower(spec), "/")
+		repo, _, _ := strings.Cut(rest, "/")
+		if strings.Contains(spec, "@") || !containsFold(orgs, owner) || failed[owner+"/"+repo] {
+			continue
+		}
+		if _, ok := crawled.actions[strings.ToLower(spec)]; !ok {
+			delete(entries, spec)
+			removed = append(removed, spec)
+		}
+	}
+	for spec, a := range crawled.actions {
+		entries[spec] = a
+	}
+	sort.Strings(removed)
+
+	content, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return linter.FileChange{}, nil, err
+	}
+	content = append(content, '\n')
+	return linter.FileChange{Path: file, Created: created, Diff: linter.UnifiedDiff(file, existing, content), Content: content}, removed, nil
+}
+
+// actionMetadataFile returns the metadata file sync_action_metadata writes:
+// file, or rules.actions.file of the configuration, relative to root.
+func actionMetadataFile(ctx context.Context, root, file string) (string, error) {
+	if file == "" {
+		file = settingsFrom(ctx).config.Rules.Actions.File
+	}
+	if file == "" {
+		return "", fmt.Errorf("pass file, or set rules.actions.file in the config file")
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(root, file)
+	}
+	return file, nil
+}
+
+// containsFold reports whether values holds s, ignoring case.
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
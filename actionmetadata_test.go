@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func syncActionMetadata(t *testing.T, args SyncActionMetadataParams) syncedActionMetadata {
+	t.Helper()
+	result, err := SyncActionMetadata(context.Background(), nil, &mcp.CallToolParamsFor[SyncActionMetadataParams]{Arguments: args})
+	require.NoError(t, err)
+	var out syncedActionMetadata
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out))
+	return out
+}
+
+func TestSyncActionMetadata(t *testing.T) {
+	file := func(content string) string {
+		return fmt.Sprintf(`{"content": %q, "encoding": "base64"}`, base64.StdEncoding.EncodeToString([]byte(content)))
+	}
+	var unauthorized []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer ghe-token" {
+			unauthorized = append(unauthorized, r.URL.Path)
+		}
+		switch strings.TrimPrefix(r.URL.Path, "/api/v3") {
+		case "/orgs/acme/repos":
+			_, _ = w.Write([]byte(`[
+				{"full_name": "acme/deploy", "archived": false},
+				{"full_name": "acme/Tools", "archived": false},
+				{"full_name": "acme/broken", "archived": false},
+				{"full_name": "acme/empty", "archived": false}
+			]`))
+		case "/repos/acme/deploy/git/trees/HEAD":
+			_, _ = w.Write([]byte(`{"tree": [
+				{"path": "action.yml", "type": "blob"},
+				{"path": "node_modules/dep/action.yml", "type": "blob"}
+			]}`))
+		case "/repos/acme/deploy/contents/action.yml":
+			_, _ = w.Write([]byte(file("name: Deploy\ninputs:\n  environment:\n    required: true\n  region:\n    default: eu\noutputs:\n  url:\n    description: Deployed URL\nruns:\n  using: node20\n  main: index.js\n")))
+		case "/repos/acme/Tools/git/trees/HEAD":
+			_, _ = w.Write([]byte(`{"tree": [
+				{"path": "lint", "type": "tree"},
+				{"path": "lint/action.yaml", "type": "blob"},
+				{"path": "lint/action.yml", "type": "blob"},
+				{"path": "README.md", "type": "blob"}
+			]}`))
+		case "/repos/acme/Tools/contents/lint/action.yml":
+			_, _ = w.Write([]byte(file("name: Lint\nruns:\n  using: composite\n  steps: []\n")))
+		case "/repos/acme/broken/git/trees/HEAD":
+			_, _ = w.Write([]byte(`{"tree": [{"path": "action.yml", "type": "blob"}]}`))
+		case "/repos/acme/broken/contents/action.yml":
+			_, _ = w.Write([]byte(file("inputs: [oops]\n")))
+		case "/repos/acme/empty/git/trees/HEAD":
+			w.WriteHeader(http.StatusConflict)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("GITHUB_TOKEN", "ghe-token")
+	s := useSettings(t, serverConfig{
+		Rules:          rules.Config{Actions: rules.ActionsConfig{File: ".github/actions.json"}},
+		ActionMetadata: actionMetadataConfig{Organizations: []string{"acme"}, APIURL: server.URL + "/api/v3/"},
+	}, true)
+
+	root := t.TempDir()
+	path := filepath.Join(root, ".github", "actions.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(`{
+  "acme/removed": {"name": "Removed", "inputs": {}, "outputs": {}},
+  "acme/broken": {"name": "Broken", "inputs": {}, "outputs": {}},
+  "acme/deploy@v1": {"name": "Pinned", "inputs": {}, "outputs": {}},
+  "other/action": {"name": "Other", "inputs": {}, "outputs": {}, "skip_inputs": true}
+}`), 0644))
+
+	out := syncActionMetadata(t, SyncActionMetadataParams{Directory: root, DryRun: true})
+	assert.Equal(t, path, out.File)
+	assert.Equal(t, 4, out.Repositories)
+	assert.Equal(t, []string{"acme/deploy", "acme/tools/lint"}, out.Actions)
+	assert.Equal(t, []string{"acme/removed"}, out.Removed, "actions of repositories that failed and for a ref are kept")
+	require.Len(t, out.Failed, 1)
+	assert.Equal(t, "acme/broken", out.Failed[0].Repository)
+	assert.Equal(t, "action.yml", out.Failed[0].Path)
+	require.Len(t, out.Changes, 1)
+	assert.False(t, out.Written)
+	assert.Empty(t, unauthorized, "GITHUB_TOKEN is sent to the configured API")
+
+	out = syncActionMetadata(t, SyncActionMetadataParams{Directory: root})
+	assert.True(t, out.Written)
+
+	metadata, err := rules.LoadActionsMetadata(rules.ActionsConfig{File: path}, "")
+	require.NoError(t, err)
+	assert.Nil(t, metadata.Lookup("acme/removed@v1"))
+	assert.NotNil(t, metadata.Lookup("acme/broken@v1"))
+	assert.Equal(t, "Pinned", metadata.Lookup("acme/deploy@v1").Name)
+	assert.True(t, metadata.Lookup("other/action@v1").SkipInputs)
+	deploy := metadata.Lookup("acme/deploy@v2")
+	require.NotNil(t, deploy)
+	assert.True(t, deploy.Inputs["environment"].Required)
+	assert.False(t, deploy.Inputs["region"].Required, "inputs with a default are optional")
+	assert.Contains(t, deploy.Outputs, "url")
+	assert.Equal(t, "Lint", metadata.Lookup("acme/tools/lint@main").Name)
+
+	// The written file is what the action-metadata rule checks steps against
+	l := linter.New(linter.Options{Rules: s.config.Rules})
+	result, err := l.Lint(context.Background(), linter.Input{Path: filepath.Join(root, ".github", "workflows", "deploy.yml"), Content: []byte(`on: push
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: acme/deploy@v3
+        with:
+          regoin: us
+`)})
+	require.NoError(t, err)
+	var messages []string
+	for _, e := range result.Errors {
+		if e.Kind == rules.KindActionMetadata {
+			messages = append(messages, e.Message)
+		}
+	}
+	assert.Len(t, messages, 2)
+
+	out = syncActionMetadata(t, SyncActionMetadataParams{Directory: root})
+	assert.Empty(t, out.Changes, "a second sync changes nothing")
+}
+
+func TestSyncActionMetadata_Errors(t *testing.T) {
+	useSettings(t, serverConfig{}, false)
+
+	call := func(args SyncActionMetadataParams) error {
+		_, err := SyncActionMetadata(context.Background(), nil, &mcp.CallToolParamsFor[SyncActionMetadataParams]{Arguments: args})
+		return err
+	}
+	assert.ErrorContains(t, call(SyncActionMetadataParams{}), "pass organizations")
+	assert.ErrorContains(t, call(SyncActionMetadataParams{Organizations: []string{"acme"}}), "pass file")
+	t.Setenv("GITHUB_TOKEN", "")
+	useSettings(t, serverConfig{}, false)
+	assert.ErrorContains(t, call(SyncActionMetadataParams{Organizations: []string{"acme"}, File: "actions.json"}), "needs a GitHub token")
+
+	assert.Error(t, actionMetadataConfig{APIURL: "github.example.com/api/v3"}.validate())
+	assert.NoError(t, actionMetadataConfig{APIURL: "https://github.example.com/api/v3"}.validate())
+}
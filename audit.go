@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+)
+
+// Concurrency of audit_repositories: how many repositories are audited at
+// once by default and at most.
+const (
+	defaultAuditConcurrency = 4
+	maxAuditConcurrency     = 16
+)
+
+// reposPageSize is the page size used to list the repositories of an
+// organization, the most the API allows.
+const reposPageSize = 100
+
+// auditTarget is a repository to audit: a local checkout, or a GitHub
+// repository as owner/name whose workflows are fetched.
+type auditTarget struct {
+	path, repo string
+}
+
+func (t auditTarget) name() string {
+	if t.repo != "" {
+		return t.repo
+	}
+	return t.path
+}
+
+// repositoryAudit is the result of linting every workflow of a repository.
+// Severities and Rules count its findings by severity and by kind, and
+// Results holds the files with findings when details are asked for.
+type repositoryAudit struct {
+	Repository      string              `json:"repository"`
+	TotalFiles      int                 `json:"total_files"`
+	FilesWithErrors int                 `json:"files_with_errors"`
+	TotalErrors     int                 `json:"total_errors"`
+	Severities      map[string]int      `json:"severities,omitempty"`
+	Rules           map[string]int      `json:"rules,omitempty"`
+	Results         []linter.LintResult `json:"results,omitempty"`
+	Error           string              `json:"error,omitempty"`
+
+	summary *linter.Summary
+}
+
+// auditReport is the audit_repositories output: the totals across every
+// repository and the repositories ranked by their findings.
+type auditReport struct {
+	Repositories             int               `json:"repositories"`
+	RepositoriesWithFindings int               `json:"repositories_with_findings"`
+	FailedRepositories       int               `json:"failed_repositories,omitempty"`
+	TotalFiles               int               `json:"total_files"`
+	TotalErrors              int               `json:"total_errors"`
+	Rules                    map[string]int    `json:"rules"`
+	Ranking                  []repositoryAudit `json:"ranking"`
+}
+
+// listOrganizationRepositories lists the repositories of org as
+// owner/name from the API at apiURL, leaving out archived ones unless
+// includeArchived is set.
+func listOrganizationRepositories(ctx context.Context, client *http.Client, apiURL, org string, includeArchived bool) ([]string, error) {
+	var repos []string
+	for page := 1; ; page++ {
+		u := fmt.Sprintf("%s/orgs/%s/repos?type=all&per_page=%d&page=%d", apiURL, url.PathEscape(org), reposPageSize, page)
+		data, err := githubGet(ctx, client, u)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories of %s: %w", org, err)
+		}
+		var listed []struct {
+			FullName string `json:"full_name"`
+			Archived bool   `json:"archived"`
+		}
+		if err := json.Unmarshal(data, &listed); err != nil {
+			return nil, fmt.Errorf("failed to list repositories of %s: %w", org, err)
+		}
+		for _, r := range listed {
+			if !r.Archived || includeArchived {
+				repos = append(repos, r.FullName)
+			}
+		}
+		if len(listed) < reposPageSize {
+			sort.Strings(repos)
+			return repos, nil
+		}
+	}
+}
+
+// auditRepositories lints the workflows of every target, concurrency of
+// them at a time, and ranks them by their findings. A repository that
+// cannot be read is reported with its error rather than failing the audit.
+func auditRepositories(ctx context.Context, l *linter.Linter, client *http.Client, targets []auditTarget, concurrency int, details bool) auditReport {
+	audits := make([]repositoryAudit, len(targets))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			audits[i] = auditRepository(ctx, l, client, t, details)
+		}()
+	}
+	wg.Wait()
+
+	report := auditReport{Repositories: len(audits), Rules: map[string]int{}}
+	for _, a := range audits {
+		if a.Error != "" {
+			report.FailedRepositories++
+		}
+		if a.TotalErrors > 0 {
+			report.RepositoriesWithFindings++
+		}
+		report.TotalFiles += a.TotalFiles
+		report.TotalErrors += a.TotalErrors
+		for kind, n := range a.Rules {
+			report.Rules[kind] += n
+		}
+	}
+	sort.SliceStable(audits, func(i, j int) bool {
+		if c := compareSeverities(audits[i].Severities, audits[j].Severities); c != 0 {
+			return c > 0
+		}
+		return audits[i].Repository < audits[j].Repository
+	})
+	report.Ranking = audits
+	return report
+}
+
+// compareSeverities compares two counts of findings by severity, the most
+// severe first: a repository with one critical finding ranks above one
+// with any number of errors.
+func compareSeverities(a, b map[string]int) int {
+	for _, s := range severityOrder {
+		if a[s] != b[s] {
+			return a[s] - b[s]
+		}
+	}
+	return 0
+}
+
+// auditRepository lints the workflows of t.
+func auditRepository(ctx context.Context, l *linter.Linter, client *http.Client, t auditTarget, details bool) repositoryAudit {
+	var summary *linter.Summary
+	var err error
+	if t.repo != "" {
+		summary, err = lintRemoteRepository(ctx, l, client, t.repo)
+	} else {
+		summary, err = lintLocalRepository(ctx, l, t.path)
+	}
+	if err != nil {
+		return repositoryAudit{Repository: t.name(), Error: err.Error()}
+	}
+
+	a := repositoryAudit{
+		Repository:      t.name(),
+		TotalFiles:      summary.TotalFiles,
+		FilesWithErrors: summary.FilesWithErrors,
+		TotalErrors:     summary.TotalErrors,
+		Severities:      map[string]int{},
+		Rules:           map[string]int{},
+		summary:         summary,
+	}
+	for _, r := range summary.Results {
+		for _, e := range r.Errors {
+			a.Severities[e.Severity]++
+			a.Rules[e.Kind]++
+		}
+		if details && len(r.Errors) > 0 {
+			a.Results = append(a.Results, r)
+		}
+	}
+	return a
+}
+
+// lintLocalRepository lints the workflows in .github/workflows of the
+// repository checked out at root.
+func lintLocalRepository(ctx context.Context, l *linter.Linter, root string) (*linter.Summary, error) {
+	dir := filepath.Join(root, linter.WorkflowsDir)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("%s has no %s directory", root, linter.WorkflowsDir)
+	}
+	files, err := linter.FindWorkflowFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	return l.LintFiles(ctx, files), nil
+}
+
+// lintRemoteRepository fetches the workflows of repo, given as owner/name,
+// from its default branch and lints them. They are linted as unnamed
+// content, as lint_from_url does, since the rest of the repository is not
+// available. A repository without workflows has no files.
+func lintRemoteRepository(ctx context.Context, l *linter.Linter, client *http.Client, repo string) (*linter.Summary, error) {
+	files, err := fetchWorkflowFiles(ctx, client, repo, filepath.ToSlash(linter.WorkflowsDir), "")
+	var status *statusError
+	if errors.As(err, &status) && status.code == http.StatusNotFound {
+		files, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+
+	summary := &linter.Summary{SchemaVersion: linter.SchemaVersion, TotalFiles: len(paths), Results: make([]linter.LintResult, 0, len(paths))}
+	for _, p := range paths {
+		result, err := l.Lint(ctx, linter.Input{Content: files[p]})
+		if err != nil {
+			result = &linter.LintResult{Errors: []linter.LintError{{Message: fmt.Sprintf("Failed to lint: %v", err), Severity: linter.SeverityError}}}
+		}
+		result.FilePath, result.SchemaVersion = p, 0
+		summary.Results = append(summary.Results, *result)
+		if !result.Valid {
+			summary.FilesWithErrors++
+			summary.TotalErrors += len(result.Errors)
+		}
+	}
+	return summary, nil
+}
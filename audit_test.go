@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	cleanWorkflow  = "on: push\npermissions:\n  contents: read\njobs:\n  build:\n    runs-on: ubuntu-latest\n    timeout-minutes: 10\n    steps:\n      - run: echo hi\n"
+	brokenWorkflow = "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    timeout: 10\n    steps:\n      - run: echo hi\n"
+)
+
+func auditRepos(t *testing.T, args AuditRepositoriesParams) auditReport {
+	t.Helper()
+	result, err := AuditRepositories(context.Background(), nil, &mcp.CallToolParamsFor[AuditRepositoriesParams]{Arguments: args})
+	require.NoError(t, err)
+	var out auditReport
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out))
+	return out
+}
+
+func TestAuditRepositories_Paths(t *testing.T) {
+	repo := func(workflows ...string) string {
+		root := t.TempDir()
+		dir := filepath.Join(root, ".github", "workflows")
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		for i, w := range workflows {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("w%d.yml", i)), []byte(w), 0644))
+		}
+		return root
+	}
+	clean := repo(cleanWorkflow)
+	broken := repo(cleanWorkflow, brokenWorkflow)
+	empty := t.TempDir()
+
+	report := auditRepos(t, AuditRepositoriesParams{Paths: []string{clean, broken, empty}, Details: true})
+	assert.Equal(t, 3, report.Repositories)
+	assert.Equal(t, 1, report.RepositoriesWithFindings)
+	assert.Equal(t, 1, report.FailedRepositories)
+	assert.Equal(t, 3, report.TotalFiles)
+	require.Len(t, report.Ranking, 3)
+
+	first := report.Ranking[0]
+	assert.Equal(t, broken, first.Repository)
+	assert.Equal(t, 2, first.TotalFiles)
+	assert.Equal(t, 1, first.FilesWithErrors)
+	assert.Equal(t, report.TotalErrors, first.TotalErrors)
+	assert.Positive(t, first.Severities[linter.SeverityError])
+	assert.Positive(t, first.Rules["syntax-check"])
+	require.Len(t, first.Results, 1)
+	assert.Equal(t, filepath.Join(broken, ".github", "workflows", "w1.yml"), first.Results[0].FilePath)
+
+	failed := report.Ranking[2]
+	assert.Equal(t, empty, failed.Repository)
+	assert.Contains(t, failed.Error, "has no .github/workflows directory")
+
+	// Details are left out by default
+	report = auditRepos(t, AuditRepositoriesParams{Paths: []string{broken}})
+	assert.Empty(t, report.Ranking[0].Results)
+
+	_, err := AuditRepositories(context.Background(), nil, &mcp.CallToolParamsFor[AuditRepositoriesParams]{})
+	assert.ErrorContains(t, err, "either paths or organization")
+	_, err = AuditRepositories(context.Background(), nil, &mcp.CallToolParamsFor[AuditRepositoriesParams]{
+		Arguments: AuditRepositoriesParams{Paths: []string{broken}, Concurrency: 100},
+	})
+	assert.ErrorContains(t, err, "concurrency must be between 1 and 16")
+}
+
+func TestAuditRepositories_Organization(t *testing.T) {
+	file := func(content string) string {
+		return fmt.Sprintf(`{"content": %q, "encoding": "base64"}`, base64.StdEncoding.EncodeToString([]byte(content)))
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orgs/acme/repos":
+			_, _ = w.Write([]byte(`[
+				{"full_name": "acme/api", "archived": false},
+				{"full_name": "acme/web", "archived": false},
+				{"full_name": "acme/docs", "archived": false},
+				{"full_name": "acme/legacy", "archived": true}
+			]`))
+		case "/repos/acme/api/contents/.github/workflows":
+			_, _ = w.Write([]byte(`[{"name": "ci.yml", "path": ".github/workflows/ci.yml", "type": "file"}]`))
+		case "/repos/acme/api/contents/.github/workflows/ci.yml":
+			_, _ = w.Write([]byte(file(cleanWorkflow)))
+		case "/repos/acme/web/contents/.github/workflows":
+			_, _ = w.Write([]byte(`[
+				{"name": "ci.yml", "path": ".github/workflows/ci.yml", "type": "file"},
+				{"name": "README.md", "path": ".github/workflows/README.md", "type": "file"}
+			]`))
+		case "/repos/acme/web/contents/.github/workflows/ci.yml":
+			_, _ = w.Write([]byte(file(brokenWorkflow)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	oldURL := githubAPIBaseURL
+	githubAPIBaseURL = server.URL
+	defer func() { githubAPIBaseURL = oldURL }()
+
+	report := auditRepos(t, AuditRepositoriesParams{Organization: "acme", Concurrency: 2, Details: true})
+	assert.Equal(t, 3, report.Repositories, "archived repositories are left out")
+	assert.Equal(t, 1, report.RepositoriesWithFindings)
+	assert.Zero(t, report.FailedRepositories, "a repository without workflows has no files")
+	assert.Equal(t, 2, report.TotalFiles)
+	require.Len(t, report.Ranking, 3)
+	assert.Equal(t, "acme/web", report.Ranking[0].Repository)
+	require.Len(t, report.Ranking[0].Results, 1)
+	assert.Equal(t, ".github/workflows/ci.yml", report.Ranking[0].Results[0].FilePath)
+	assert.Equal(t, []string{"acme/api", "acme/docs"}, []string{report.Ranking[1].Repository, report.Ranking[2].Repository})
+	assert.Positive(t, report.Rules["syntax-check"])
+
+	report = auditRepos(t, AuditRepositoriesParams{Organization: "acme", IncludeArchived: true})
+	assert.Equal(t, 4, report.Repositories)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// bytesPerToken is how many bytes of a result make a token, roughly, for
+// the tokenizers of the models clients run. JSON with short keys and
+// English messages comes close to four.
+const bytesPerToken = 4
+
+// messageLengths are the lengths, in runes, the messages of findings are
+// cut to in turn while a result is over its limit.
+var messageLengths = []int{200, 100, 50}
+
+// responseLimit returns the most bytes of text a lint tool may return, for
+// its max_bytes and max_response_tokens arguments, the smaller of the two
+// when both are given, or zero when neither is.
+func responseLimit(maxBytes, maxTokens int) (int, error) {
+	if maxBytes < 0 || maxTokens < 0 {
+		return 0, fmt.Errorf("max_bytes and max_response_tokens cannot be negative")
+	}
+	limit := maxBytes
+	if tokens := maxTokens * bytesPerToken; tokens > 0 && (limit == 0 || tokens < limit) {
+		limit = tokens
+	}
+	return limit, nil
+}
+
+// resultSize returns the bytes of text of result.
+func resultSize(result *mcp.CallToolResultFor[any]) int {
+	n := 0
+	for _, c := range result.Content {
+		if text, ok := c.(*mcp.TextContent); ok {
+			n += len(text.Text)
+		}
+	}
+	return n
+}
+
+// fitResult returns result, the output of a lint tool rendered by render,
+// or when it is larger than limit bytes, the output rendered again with
+// results trimmed until it fits: first without the edits of fixes, then
+// with shorter messages, and last without findings, the least severe and
+// latest first, which are counted in omitted_findings. Counts, totals and
+// valid are left as they are, so they still describe every finding. The
+// output may still be larger than limit when nothing is left to trim.
+//
+// results are the lint results the output holds. Their Errors are
+// replaced rather than changed, so slices shared with other results are
+// untouched. Content after the first item of result, such as the note of
+// an output_path file, is kept.
+func fitResult(limit int, result *mcp.CallToolResultFor[any], results []*LintResult, render func() (*mcp.CallToolResultFor[any], error)) (*mcp.CallToolResultFor[any], error) {
+	if limit <= 0 || resultSize(result) <= limit {
+		return result, nil
+	}
+	extra := result.Content[1:]
+	fits := func() (*mcp.CallToolResultFor[any], bool, error) {
+		trimmed, err := render()
+		if err != nil {
+			return nil, false, err
+		}
+		trimmed.Content = append(trimmed.Content, extra...)
+		trimmed.Meta["truncated"] = true
+		return trimmed, resultSize(trimmed) <= limit, nil
+	}
+
+	steps := []func(LintError) LintError{func(e LintError) LintError {
+		e.Fix = nil
+		return e
+	}}
+	for _, n := range messageLengths {
+		steps = append(steps, func(e LintError) LintError {
+			e.Message = truncateMessage(e.Message, n)
+			return e
+		})
+	}
+	for _, step := range steps {
+		for _, r := range results {
+			errs := make([]LintError, len(r.Errors))
+			for i, e := range r.Errors {
+				errs[i] = step(e)
+			}
+			r.Errors = errs
+		}
+		trimmed, ok, err := fits()
+		if err != nil || ok {
+			return trimmed, err
+		}
+	}
+
+	// Findings are dropped in order, as few as fit
+	type finding struct{ result, index int }
+	var order []finding
+	kept := make([][]LintError, len(results))
+	omitted := make([]int, len(results))
+	for i, r := range results {
+		kept[i], omitted[i] = r.Errors, r.OmittedFindings
+		for j := range r.Errors {
+			order = append(order, finding{i, j})
+		}
+	}
+	leastSevere := []string{linter.SeverityInfo, linter.SeverityWarning, linter.SeverityError, linter.SeverityCritical}
+	rank := func(f finding) int {
+		return slices.Index(leastSevere, kept[f.result][f.index].Severity)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if ra, rb := rank(a), rank(b); ra != rb {
+			return ra < rb
+		}
+		if a.result != b.result {
+			return a.result > b.result
+		}
+		return a.index > b.index
+	})
+	drop := func(n int) (*mcp.CallToolResultFor[any], bool, error) {
+		dropped := make([]map[int]bool, len(results))
+		for _, f := range order[:n] {
+			if dropped[f.result] == nil {
+				dropped[f.result] = map[int]bool{}
+			}
+			dropped[f.result][f.index] = true
+		}
+		for i, r := range results {
+			r.Errors = make([]LintError, 0, len(kept[i])-len(dropped[i]))
+			for j, e := range kept[i] {
+				if !dropped[i][j] {
+					r.Errors = append(r.Errors, e)
+				}
+			}
+			r.OmittedFindings = omitted[i] + len(dropped[i])
+		}
+		return fits()
+	}
+	n := sort.Search(len(order), func(n int) bool {
+		_, ok, err := drop(n)
+		return ok || err != nil
+	})
+	trimmed, _, err := drop(n)
+	return trimmed, err
+}
+
+// fittedResult renders out, the output of a lint tool holding lint, fitted
+// to limit bytes as fitResult does.
+func fittedResult(limit int, out any, lint *LintResult) (*mcp.CallToolResultFor[any], error) {
+	result, err := jsonResult(out)
+	if err != nil {
+		return nil, err
+	}
+	return fitResult(limit, result, []*LintResult{lint}, func() (*mcp.CallToolResultFor[any], error) {
+		return jsonResult(out)
+	})
+}
+
+// truncateMessage cuts message to n runes, ending it with an ellipsis.
+func truncateMessage(message string, n int) string {
+	runes := []rune(message)
+	if len(runes) <= n {
+		return message
+	}
+	return string(runes[:n-1]) + "…"
+}
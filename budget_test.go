@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseLimit(t *testing.T) {
+	for _, tc := range []struct {
+		bytes, tokens, want int
+	}{
+		{0, 0, 0},
+		{5000, 0, 5000},
+		{0, 1000, 4000},
+		{5000, 1000, 4000},
+		{3000, 1000, 3000},
+	} {
+		limit, err := responseLimit(tc.bytes, tc.tokens)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, limit, "max_bytes %d, max_response_tokens %d", tc.bytes, tc.tokens)
+	}
+	_, err := responseLimit(-1, 0)
+	assert.Error(t, err)
+}
+
+func TestFitResult(t *testing.T) {
+	long := strings.Repeat("a long explanation ", 20)
+	newSummary := func() *linter.Summary {
+		fix := &rules.Fix{ID: "fix-1", Description: "pin the action", Edits: []rules.Edit{{Line: 1, Column: 1, New: strings.Repeat("x", 400)}}}
+		return &linter.Summary{TotalFiles: 2, FilesWithErrors: 2, TotalErrors: 4, Results: []LintResult{
+			{FilePath: "a.yml", Errors: []LintError{
+				{Message: "critical " + long, Kind: "plaintext-secret", Severity: linter.SeverityCritical, Fix: fix},
+				{Message: "info " + long, Kind: "expression", Severity: linter.SeverityInfo},
+			}},
+			{FilePath: "b.yml", Errors: []LintError{
+				{Message: "warning " + long, Kind: "shellcheck", Severity: linter.SeverityWarning},
+				{Message: "info " + long, Kind: "expression", Severity: linter.SeverityInfo},
+			}},
+		}}
+	}
+	fit := func(limit int) (*linter.Summary, *mcp.CallToolResultFor[any]) {
+		t.Helper()
+		summary := newSummary()
+		original := summary.Results[0].Errors
+		results := []*LintResult{&summary.Results[0], &summary.Results[1]}
+		full, err := jsonResult(summary)
+		require.NoError(t, err)
+		result, err := fitResult(limit, full, results, func() (*mcp.CallToolResultFor[any], error) { return jsonResult(summary) })
+		require.NoError(t, err)
+		assert.NotNil(t, original[0].Fix, "the findings are trimmed in copies")
+		var out linter.Summary
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out))
+		assert.Equal(t, 4, out.TotalErrors, "totals count every finding")
+		return &out, result
+	}
+
+	out, result := fit(100000)
+	assert.NotNil(t, out.Results[0].Errors[0].Fix)
+	assert.Nil(t, result.Meta["truncated"])
+
+	out, result = fit(2000)
+	assert.LessOrEqual(t, resultSize(result), 2000)
+	assert.Equal(t, true, result.Meta["truncated"])
+	assert.Nil(t, out.Results[0].Errors[0].Fix, "fixes go first")
+	assert.Len(t, out.Results[1].Errors, 2)
+	assert.True(t, strings.HasSuffix(out.Results[0].Errors[0].Message, "…"))
+
+	out, result = fit(700)
+	assert.LessOrEqual(t, resultSize(result), 700)
+	require.Len(t, out.Results[0].Errors, 1)
+	assert.Equal(t, linter.SeverityCritical, out.Results[0].Errors[0].Severity, "the least severe findings are left out first")
+	assert.Equal(t, 1, out.Results[0].OmittedFindings)
+	assert.Equal(t, 2, len(out.Results[1].Errors)+out.Results[1].OmittedFindings)
+
+	out, result = fit(10)
+	assert.Greater(t, resultSize(result), 10, "the output is returned even when nothing is left to trim")
+	assert.Empty(t, out.Results[0].Errors)
+	assert.Equal(t, 2, out.Results[0].OmittedFindings)
+}
+
+func TestCheckAllWorkflows_MaxBytes(t *testing.T) {
+	useSettings(t, serverConfig{}, false)
+	dir := t.TempDir()
+	for i := range 10 {
+		var steps strings.Builder
+		for j := range 10 {
+			fmt.Fprintf(&steps, "      - run: echo ${{ github.undefined_%d }}\n", j)
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("ci%d.yml", i)), []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n"+steps.String()), 0644))
+	}
+	call := func(args CheckAllWorkflowsParams) (string, *mcp.CallToolResultFor[any]) {
+		t.Helper()
+		args.Directory = dir
+		result, err := CheckAllWorkflows(context.Background(), nil, &mcp.CallToolParamsFor[CheckAllWorkflowsParams]{Arguments: args})
+		require.NoError(t, err)
+		return result.Content[0].(*mcp.TextContent).Text, result
+	}
+
+	full, _ := call(CheckAllWorkflowsParams{})
+	text, result := call(CheckAllWorkflowsParams{MaxResponseTokens: 1000})
+	assert.Less(t, len(text), len(full))
+	assert.LessOrEqual(t, resultSize(result), 4000)
+
+	var summary linter.Summary
+	require.NoError(t, json.Unmarshal([]byte(text), &summary))
+	assert.Equal(t, 100, summary.TotalErrors)
+	findings := 0
+	for _, r := range summary.Results {
+		findings += len(r.Errors) + r.OmittedFindings
+	}
+	assert.Equal(t, 100, findings, "every finding is returned or counted as omitted")
+
+	text, _ = call(CheckAllWorkflowsParams{MaxBytes: 6000, ResultsAsMap: true})
+	var byPath mapSummary
+	require.NoError(t, json.Unmarshal([]byte(text), &byPath))
+	assert.Len(t, byPath.Results, 10)
+	assert.LessOrEqual(t, len(text), 6000)
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+)
+
+// Output formats of check_all_workflows.
+const (
+	formatJSON        = "json"
+	formatSlackBlocks = "slack_blocks"
+	formatTeamsCard   = "teams_card"
+)
+
+// Limits keeping chat messages readable and within the platforms' sizes.
+const (
+	maxChatFindings = 10
+	maxChatMessage  = 200
+)
+
+// severityOrder lists severities from the most to the least severe.
+var severityOrder = []string{linter.SeverityCritical, linter.SeverityError, linter.SeverityWarning, linter.SeverityInfo}
+
+// chatFinding is a finding with the file it is in.
+type chatFinding struct {
+	file string
+	linter.LintError
+}
+
+// chatDigest is what chat messages show of a summary: the headline, the
+// findings per severity, and the most severe findings.
+type chatDigest struct {
+	title      string
+	severities []string
+	counts     map[string]int
+	findings   []chatFinding
+	more       int
+}
+
+func digestSummary(summary *linter.Summary) chatDigest {
+	d := chatDigest{counts: map[string]int{}}
+	for _, r := range summary.Results {
+		for _, e := range r.Errors {
+			d.counts[e.Severity]++
+			d.findings = append(d.findings, chatFinding{file: r.FilePath, LintError: e})
+		}
+	}
+	for _, s := range severityOrder {
+		if d.counts[s] > 0 {
+			d.severities = append(d.severities, s)
+		}
+	}
+
+	rank := func(severity string) int {
+		for i, s := range severityOrder {
+			if s == severity {
+				return i
+			}
+		}
+		return len(severityOrder)
+	}
+	sort.SliceStable(d.findings, func(i, j int) bool {
+		return rank(d.findings[i].Severity) < rank(d.findings[j].Severity)
+	})
+	if len(d.findings) > maxChatFindings {
+		d.more = len(d.findings) - maxChatFindings
+		d.findings = d.findings[:maxChatFindings]
+	}
+
+	if summary.TotalErrors == 0 {
+		d.title = fmt.Sprintf("actionlint: all %d workflows pass", summary.TotalFiles)
+	} else {
+		findings := "findings"
+		if summary.TotalErrors == 1 {
+			findings = "finding"
+		}
+		d.title = fmt.Sprintf("actionlint: %d %s in %d of %d workflows", summary.TotalErrors, findings, summary.FilesWithErrors, summary.TotalFiles)
+	}
+	return d
+}
+
+// location is where a finding is, as file:line.
+func (f chatFinding) location() string {
+	name := filepath.ToSlash(f.file)
+	if f.Line > 0 {
+		return fmt.Sprintf("%s:%d", name, f.Line)
+	}
+	return name
+}
+
+func (f chatFinding) message() string {
+	msg := strings.Join(strings.Fields(f.Message), " ")
+	if r := []rune(msg); len(r) > maxChatMessage {
+		msg = string(r[:maxChatMessage-1]) + "…"
+	}
+	return msg
+}
+
+// slackEscape escapes the characters Slack's mrkdwn treats as markup.
+var slackEscape = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Fields   []slackText `json:"fields,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+// slackMessage is a message for Slack's chat.postMessage or an incoming
+// webhook. Text is shown in notifications and by clients without blocks.
+type slackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+// slackBlocks renders summary as a Slack message.
+func slackBlocks(summary *linter.Summary) slackMessage {
+	d := digestSummary(summary)
+	msg := slackMessage{
+		Text:   d.title,
+		Blocks: []slackBlock{{Type: "header", Text: &slackText{Type: "plain_text", Text: d.title}}},
+	}
+	if len(d.severities) > 0 {
+		var fields []slackText
+		for _, s := range d.severities {
+			fields = append(fields, slackText{Type: "mrkdwn", Text: fmt.Sprintf("*%s*\n%d", s, d.counts[s])})
+		}
+		msg.Blocks = append(msg.Blocks, slackBlock{Type: "section", Fields: fields})
+	}
+	if len(d.findings) > 0 {
+		var lines []string
+		for _, f := range d.findings {
+			lines = append(lines, fmt.Sprintf("• `%s` *%s* %s (`%s`)", slackEscape.Replace(f.location()), f.Severity, slackEscape.Replace(f.message()), f.Kind))
+		}
+		if d.more > 0 {
+			lines = append(lines, fmt.Sprintf("_and %d more_", d.more))
+		}
+		msg.Blocks = append(msg.Blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: strings.Join(lines, "\n")}})
+	}
+	if summary.Meta != nil {
+		msg.Blocks = append(msg.Blocks, slackBlock{Type: "context", Elements: []slackText{{Type: "mrkdwn", Text: "actionlint " + summary.Meta.ActionlintVersion}}})
+	}
+	return msg
+}
+
+type teamsElement struct {
+	Type     string      `json:"type"`
+	Text     string      `json:"text,omitempty"`
+	Size     string      `json:"size,omitempty"`
+	Weight   string      `json:"weight,omitempty"`
+	Color    string      `json:"color,omitempty"`
+	Wrap     bool        `json:"wrap,omitempty"`
+	IsSubtle bool        `json:"isSubtle,omitempty"`
+	Facts    []teamsFact `json:"facts,omitempty"`
+}
+
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type teamsCard struct {
+	Schema  string         `json:"$schema"`
+	Type    string         `json:"type"`
+	Version string         `json:"version"`
+	Body    []teamsElement `json:"body"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+// teamsMessage is a message carrying an Adaptive Card, as Teams incoming
+// webhooks and workflows accept it.
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+// teamsColors maps severities to the colors of Adaptive Card text.
+var teamsColors = map[string]string{
+	linter.SeverityCritical: "Attention",
+	linter.SeverityError:    "Attention",
+	linter.SeverityWarning:  "Warning",
+}
+
+// teamsAdaptiveCard renders summary as a Teams message.
+func teamsAdaptiveCard(summary *linter.Summary) teamsMessage {
+	d := digestSummary(summary)
+	color := "Good"
+	if len(d.severities) > 0 {
+		color = teamsColors[d.severities[0]]
+	}
+	body := []teamsElement{{Type: "TextBlock", Text: d.title, Size: "Large", Weight: "Bolder", Color: color, Wrap: true}}
+	if len(d.severities) > 0 {
+		facts := teamsElement{Type: "FactSet"}
+		for _, s := range d.severities {
+			facts.Facts = append(facts.Facts, teamsFact{Title: s, Value: fmt.Sprint(d.counts[s])})
+		}
+		body = append(body, facts)
+	}
+	for _, f := range d.findings {
+		body = append(body, teamsElement{
+			Type:  "TextBlock",
+			Text:  fmt.Sprintf("**%s** %s: %s (%s)", f.location(), f.Severity, f.message(), f.Kind),
+			Color: teamsColors[f.Severity],
+			Wrap:  true,
+		})
+	}
+	if d.more > 0 {
+		body = append(body, teamsElement{Type: "TextBlock", Text: fmt.Sprintf("and %d more", d.more), IsSubtle: true})
+	}
+	if summary.Meta != nil {
+		body = append(body, teamsElement{Type: "TextBlock", Text: "actionlint " + summary.Meta.ActionlintVersion, Size: "Small", IsSubtle: true})
+	}
+	return teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: teamsCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body:    body,
+			},
+		}},
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func chatSummary(n int) *linter.Summary {
+	result := linter.LintResult{FilePath: ".github/workflows/ci.yml"}
+	for i := 1; i <= n; i++ {
+		result.Errors = append(result.Errors, linter.LintError{Message: fmt.Sprintf("problem <%d>", i), Line: i, Kind: "expression", Severity: linter.SeverityWarning})
+	}
+	result.Errors = append(result.Errors, linter.LintError{Message: strings.Repeat("x", 300), Line: 99, Kind: "syntax-check", Severity: linter.SeverityError})
+	return &linter.Summary{
+		TotalFiles:      2,
+		FilesWithErrors: 1,
+		TotalErrors:     n + 1,
+		Results:         []linter.LintResult{result, {FilePath: ".github/workflows/ok.yml", Valid: true}},
+		Meta:            &linter.Meta{ActionlintVersion: "v1.7.7"},
+	}
+}
+
+func TestSlackBlocks(t *testing.T) {
+	msg := slackBlocks(chatSummary(12))
+	assert.Equal(t, "actionlint: 13 findings in 1 of 2 workflows", msg.Text)
+	require.Len(t, msg.Blocks, 4)
+	assert.Equal(t, "header", msg.Blocks[0].Type)
+	assert.Equal(t, []slackText{{Type: "mrkdwn", Text: "*error*\n1"}, {Type: "mrkdwn", Text: "*warning*\n12"}}, msg.Blocks[1].Fields)
+
+	lines := strings.Split(msg.Blocks[2].Text.Text, "\n")
+	require.Len(t, lines, maxChatFindings+1)
+	assert.True(t, strings.HasPrefix(lines[0], "• `.github/workflows/ci.yml:99` *error* xxx"), lines[0])
+	assert.True(t, strings.HasSuffix(lines[0], "… (`syntax-check`)"), lines[0])
+	assert.Equal(t, "• `.github/workflows/ci.yml:1` *warning* problem &lt;1&gt; (`expression`)", lines[1])
+	assert.Equal(t, "_and 3 more_", lines[maxChatFindings])
+	assert.Equal(t, "context", msg.Blocks[3].Type)
+
+	msg = slackBlocks(&linter.Summary{TotalFiles: 3})
+	assert.Equal(t, "actionlint: all 3 workflows pass", msg.Text)
+	assert.Len(t, msg.Blocks, 1)
+}
+
+func TestTeamsAdaptiveCard(t *testing.T) {
+	msg := teamsAdaptiveCard(chatSummary(1))
+	require.Len(t, msg.Attachments, 1)
+	assert.Equal(t, "application/vnd.microsoft.card.adaptive", msg.Attachments[0].ContentType)
+	card := msg.Attachments[0].Content
+	assert.Equal(t, "AdaptiveCard", card.Type)
+	require.Len(t, card.Body, 5)
+	assert.Equal(t, "Attention", card.Body[0].Color)
+	assert.Equal(t, []teamsFact{{Title: "error", Value: "1"}, {Title: "warning", Value: "1"}}, card.Body[1].Facts)
+	assert.Equal(t, "**.github/workflows/ci.yml:1** warning: problem <1> (expression)", card.Body[3].Text)
+	assert.Equal(t, "Warning", card.Body[3].Color)
+
+	data, err := json.Marshal(teamsAdaptiveCard(&linter.Summary{TotalFiles: 1}))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"$schema":"http://adaptivecards.io/schemas/adaptive-card.json"`)
+	assert.Contains(t, string(data), `"color":"Good"`)
+}
+
+func TestCheckAllWorkflows_Format(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ci.yml"), []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ github.undefined }}\n"), 0644))
+	call := func(args CheckAllWorkflowsParams) (*mcp.CallToolResultFor[any], error) {
+		return CheckAllWorkflows(context.Background(), nil, &mcp.CallToolParamsFor[CheckAllWorkflowsParams]{Arguments: args})
+	}
+
+	result, err := call(CheckAllWorkflowsParams{Directory: dir, Format: formatSlackBlocks})
+	require.NoError(t, err)
+	var slack slackMessage
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &slack))
+	assert.Equal(t, "actionlint: 1 finding in 1 of 1 workflows", slack.Text)
+
+	result, err = call(CheckAllWorkflowsParams{Directory: dir, Format: formatTeamsCard})
+	require.NoError(t, err)
+	var teams teamsMessage
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &teams))
+	assert.Equal(t, "message", teams.Type)
+
+	_, err = call(CheckAllWorkflowsParams{Directory: dir, Format: "html"})
+	assert.ErrorContains(t, err, `unknown format "html"`)
+	_, err = call(CheckAllWorkflowsParams{Directory: dir, Format: formatSlackBlocks, ResultsAsMap: true})
+	assert.ErrorContains(t, err, "results_as_map")
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+)
+
+// command is a CLI subcommand such as self-update. The flags function
+// returns a fresh flag set describing the subcommand's options, which lets
+// shell completion enumerate them without running the command.
+type command struct {
+	name    string
+	summary string
+	args    []string
+	flags   func() *flag.FlagSet
+	run     func(args []string) error
+}
+
+func subcommands() []command {
+	return []command{
+		{
+			name:    "lint",
+			summary: "Lint workflow files and exit non-zero on findings at the fail level",
+			flags:   func() *flag.FlagSet { return newLintFlagSet(&lintCommandOptions{}) },
+			run:     runLint,
+		},
+		{
+			name:    "scan-org",
+			summary: "Lint the workflows of every repository of a GitHub organization",
+			flags:   func() *flag.FlagSet { return newScanOrgFlagSet(&scanOrgOptions{}) },
+			run:     runScanOrg,
+		},
+		{
+			name:    "self-update",
+			summary: "Update the binary to the latest GitHub release",
+			flags:   func() *flag.FlagSet { return newSelfUpdateFlagSet(&selfUpdateOptions{}) },
+			run:     runSelfUpdate,
+		},
+		{
+			name:    "completion",
+			summary: "Generate a shell completion script",
+			args:    completionShells,
+			flags:   func() *flag.FlagSet { return flag.NewFlagSet("completion", flag.ContinueOnError) },
+			run:     runCompletion,
+		},
+	}
+}
+
+func lookupCommand(name string) *command {
+	for _, cmd := range subcommands() {
+		if cmd.name == name {
+			return &cmd
+		}
+	}
+	return nil
+}
+
+// pathFlag is a string flag holding a filesystem path. Completion scripts
+// offer file names for flags of this type.
+type pathFlag string
+
+func (p *pathFlag) String() string { return string(*p) }
+
+func (p *pathFlag) Set(v string) error {
+	*p = pathFlag(v)
+	return nil
+}
+
+// serverFlagSet returns the flags accepted when running the server itself.
+func serverFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("actionlint-mcp", flag.ContinueOnError)
+	registerServerFlags(fs, &serverOptions{})
+	return fs
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+const programName = "actionlint-mcp"
+
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+type completionFlag struct {
+	name       string
+	usage      string
+	takesValue bool
+	isPath     bool
+}
+
+type completionCommand struct {
+	name    string
+	summary string
+	args    []string
+	flags   []completionFlag
+}
+
+func runCompletion(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: %s completion %s", programName, strings.Join(completionShells, "|"))
+	}
+	return writeCompletion(os.Stdout, args[0])
+}
+
+func writeCompletion(w io.Writer, shell string) error {
+	commands := completionCommands()
+	top := completionFlags(serverFlagSet())
+
+	switch shell {
+	case "bash":
+		return writeBashCompletion(w, commands, top)
+	case "zsh":
+		return writeZshCompletion(w, commands, top)
+	case "fish":
+		return writeFishCompletion(w, commands, top)
+	case "powershell":
+		return writePowerShellCompletion(w, commands, top)
+	default:
+		return fmt.Errorf("unsupported shell %q (expected one of %s)", shell, strings.Join(completionShells, ", "))
+	}
+}
+
+func completionCommands() []completionCommand {
+	var out []completionCommand
+	for _, cmd := range subcommands() {
+		out = append(out, completionCommand{
+			name:    cmd.name,
+			summary: cmd.summary,
+			args:    cmd.args,
+			flags:   completionFlags(cmd.flags()),
+		})
+	}
+	return out
+}
+
+func completionFlags(fs *flag.FlagSet) []completionFlag {
+	var out []completionFlag
+	fs.VisitAll(func(f *flag.Flag) {
+		cf := completionFlag{name: f.Name, usage: f.Usage, takesValue: true}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			cf.takesValue = false
+		}
+		if _, ok := f.Value.(*pathFlag); ok {
+			cf.isPath = true
+		}
+		out = append(out, cf)
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].name < out[j].name })
+	return out
+}
+
+func flagWords(flags []completionFlag) string {
+	words := make([]string, 0, len(flags))
+	for _, f := range flags {
+		words = append(words, "-"+f.name)
+	}
+	return strings.Join(words, " ")
+}
+
+func flagsMatching(flags []completionFlag, match func(completionFlag) bool) []string {
+	var words []string
+	for _, f := range flags {
+		if match(f) {
+			words = append(words, "-"+f.name, "--"+f.name)
+		}
+	}
+	return words
+}
+
+func writeBashCompletion(w io.Writer, commands []completionCommand, top []completionFlag) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# bash completion for %s\n", programName)
+	b.WriteString("_actionlint_mcp() {\n")
+	b.WriteString("    local cur prev cmd word i\n")
+	b.WriteString("    cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	b.WriteString("    prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	b.WriteString("    cmd=\"\"\n")
+	b.WriteString("    for ((i = 1; i < COMP_CWORD; i++)); do\n")
+	b.WriteString("        word=\"${COMP_WORDS[i]}\"\n")
+	b.WriteString("        case \"$word\" in\n")
+	names := make([]string, 0, len(commands))
+	for _, c := range commands {
+		names = append(names, c.name)
+	}
+	fmt.Fprintf(&b, "            %s) cmd=\"$word\"; break ;;\n", strings.Join(names, "|"))
+	b.WriteString("        esac\n")
+	b.WriteString("    done\n\n")
+
+	b.WriteString("    case \"$cmd\" in\n")
+	writeBashCase(&b, "\"\"", strings.TrimSpace(strings.Join(names, " ")+" "+flagWords(top)), top)
+	for _, c := range commands {
+		words := strings.TrimSpace(strings.Join(c.args, " ") + " " + flagWords(c.flags))
+		writeBashCase(&b, c.name, words, c.flags)
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "complete -o default -F _actionlint_mcp %s\n", programName)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeBashCase(b *strings.Builder, label, words string, flags []completionFlag) {
+	fmt.Fprintf(b, "        %s)\n", label)
+	paths := flagsMatching(flags, func(f completionFlag) bool { return f.isPath })
+	values := flagsMatching(flags, func(f completionFlag) bool { return f.takesValue && !f.isPath })
+	if len(paths) > 0 || len(values) > 0 {
+		b.WriteString("            case \"$prev\" in\n")
+		if len(paths) > 0 {
+			fmt.Fprintf(b, "                %s) COMPREPLY=($(compgen -f -- \"$cur\")); return ;;\n", strings.Join(paths, "|"))
+		}
+		if len(values) > 0 {
+			fmt.Fprintf(b, "                %s) COMPREPLY=(); return ;;\n", strings.Join(values, "|"))
+		}
+		b.WriteString("            esac\n")
+	}
+	fmt.Fprintf(b, "            COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", words)
+	b.WriteString("            ;;\n")
+}
+
+func zshEscape(s string) string {
+	r := strings.NewReplacer("'", "'\\''", "[", "\\[", "]", "\\]", ":", "\\:")
+	return r.Replace(s)
+}
+
+func zshFlagSpecs(flags []completionFlag) []string {
+	specs := make([]string, 0, len(flags))
+	for _, f := range flags {
+		spec := fmt.Sprintf("'-%s[%s]", f.name, zshEscape(f.usage))
+		switch {
+		case f.isPath:
+			spec += fmt.Sprintf(":%s:_files", f.name)
+		case f.takesValue:
+			spec += fmt.Sprintf(":%s: ", f.name)
+		}
+		specs = append(specs, spec+"'")
+	}
+	return specs
+}
+
+func writeZshCompletion(w io.Writer, commands []completionCommand, top []completionFlag) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#compdef %s\n\n", programName)
+	b.WriteString("_actionlint-mcp() {\n")
+	b.WriteString("  local -a commands\n")
+	b.WriteString("  commands=(\n")
+	for _, c := range commands {
+		fmt.Fprintf(&b, "    '%s:%s'\n", c.name, zshEscape(c.summary))
+	}
+	b.WriteString("  )\n\n")
+
+	b.WriteString("  if (( CURRENT > 2 )); then\n")
+	b.WriteString("    case \"${words[2]}\" in\n")
+	for _, c := range commands {
+		fmt.Fprintf(&b, "      %s)\n", c.name)
+		specs := zshFlagSpecs(c.flags)
+		if len(c.args) > 0 {
+			specs = append(specs, fmt.Sprintf("'1:%s:(%s)'", c.name, strings.Join(c.args, " ")))
+		}
+		if len(specs) > 0 {
+			b.WriteString("        shift words; (( CURRENT-- ))\n")
+			fmt.Fprintf(&b, "        _arguments \\\n          %s\n", strings.Join(specs, " \\\n          "))
+		}
+		b.WriteString("        return ;;\n")
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("  fi\n\n")
+
+	specs := append(zshFlagSpecs(top), "'1: :{_describe command commands}'")
+	fmt.Fprintf(&b, "  _arguments \\\n    %s\n", strings.Join(specs, " \\\n    "))
+	b.WriteString("}\n\n")
+	b.WriteString("if [ \"$funcstack[1]\" = \"_actionlint-mcp\" ]; then\n")
+	b.WriteString("  _actionlint-mcp \"$@\"\n")
+	b.WriteString("else\n")
+	fmt.Fprintf(&b, "  compdef _actionlint-mcp %s\n", programName)
+	b.WriteString("fi\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func fishEscape(s string) string {
+	return strings.ReplaceAll(s, "'", "\\'")
+}
+
+func writeFishFlags(b *strings.Builder, condition string, flags []completionFlag) {
+	for _, f := range flags {
+		line := fmt.Sprintf("complete -c %s -n '%s' -o %s", programName, condition, f.name)
+		switch {
+		case f.isPath:
+			line += " -r -F"
+		case f.takesValue:
+			line += " -x"
+		}
+		fmt.Fprintf(b, "%s -d '%s'\n", line, fishEscape(f.usage))
+	}
+}
+
+func writeFishCompletion(w io.Writer, commands []completionCommand, top []completionFlag) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# fish completion for %s\n", programName)
+	fmt.Fprintf(&b, "complete -c %s -f\n", programName)
+	for _, c := range commands {
+		fmt.Fprintf(&b, "complete -c %s -n '__fish_use_subcommand' -a %s -d '%s'\n", programName, c.name, fishEscape(c.summary))
+	}
+	writeFishFlags(&b, "__fish_use_subcommand", top)
+	for _, c := range commands {
+		condition := "__fish_seen_subcommand_from " + c.name
+		if len(c.args) > 0 {
+			fmt.Fprintf(&b, "complete -c %s -n '%s' -a '%s'\n", programName, condition, strings.Join(c.args, " "))
+		}
+		writeFishFlags(&b, condition, c.flags)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func powerShellList(words []string) string {
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		quoted = append(quoted, "'"+strings.ReplaceAll(word, "'", "''")+"'")
+	}
+	return "@(" + strings.Join(quoted, ", ") + ")"
+}
+
+func writePowerShellCompletion(w io.Writer, commands []completionCommand, top []completionFlag) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# powershell completion for %s\n", programName)
+	fmt.Fprintf(&b, "Register-ArgumentCompleter -Native -CommandName '%s' -ScriptBlock {\n", programName)
+	b.WriteString("    param($wordToComplete, $commandAst, $cursorPosition)\n")
+	b.WriteString("    $words = @($commandAst.CommandElements | ForEach-Object { $_.ToString() })\n")
+	b.WriteString("    $command = ''\n")
+	b.WriteString("    if ($words.Count -gt 2 -or ($words.Count -eq 2 -and $wordToComplete -eq '')) {\n")
+	b.WriteString("        $command = $words[1]\n")
+	b.WriteString("    }\n")
+	b.WriteString("    $candidates = switch ($command) {\n")
+
+	names := make([]string, 0, len(commands))
+	for _, c := range commands {
+		names = append(names, c.name)
+		words := append(append([]string{}, c.args...), strings.Fields(flagWords(c.flags))...)
+		fmt.Fprintf(&b, "        '%s' { %s }\n", c.name, powerShellList(words))
+	}
+	fmt.Fprintf(&b, "        default { %s }\n", powerShellList(append(names, strings.Fields(flagWords(top))...)))
+	b.WriteString("    }\n")
+	b.WriteString("    $candidates | Where-Object { $_ -like \"$wordToComplete*\" } | ForEach-Object {\n")
+	b.WriteString("        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)\n")
+	b.WriteString("    }\n")
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
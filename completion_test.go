@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCompletion(t *testing.T) {
+	for _, shell := range completionShells {
+		t.Run(shell, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, writeCompletion(&buf, shell))
+
+			script := buf.String()
+			assert.Contains(t, script, "self-update")
+			assert.Contains(t, script, "completion")
+			assert.Contains(t, script, "require-signature")
+			assert.Contains(t, script, "fail-level")
+			assert.Contains(t, script, "powershell")
+		})
+	}
+}
+
+func TestWriteCompletion_UnsupportedShell(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeCompletion(&buf, "tcsh")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported shell")
+}
+
+func TestCompletionFlags(t *testing.T) {
+	fs := newSelfUpdateFlagSet(&selfUpdateOptions{})
+	var path string
+	fs.Var((*pathFlag)(&path), "config", "Path to a config file")
+
+	flags := completionFlags(fs)
+	byName := make(map[string]completionFlag)
+	for _, f := range flags {
+		byName[f.name] = f
+	}
+
+	assert.False(t, byName["check"].takesValue)
+	assert.True(t, byName["version"].takesValue)
+	assert.False(t, byName["version"].isPath)
+	assert.True(t, byName["config"].isPath)
+}
+
+func TestBashCompletionSyntax(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeCompletion(&buf, "bash"))
+
+	script := filepath.Join(t.TempDir(), "completion.bash")
+	require.NoError(t, os.WriteFile(script, buf.Bytes(), 0644))
+
+	out, err := exec.Command(bash, "-n", script).CombinedOutput()
+	assert.NoError(t, err, string(out))
+}
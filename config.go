@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"gopkg.in/yaml.v3"
+)
+
+// serverConfig is the configuration file given with -config.
+type serverConfig struct {
+	Rules     rules.Config    `yaml:"rules"`
+	Templates templatesConfig `yaml:"templates"`
+	History   historyConfig   `yaml:"history"`
+	Webhook   webhookConfig   `yaml:"webhook"`
+
+	ActionMetadata actionMetadataConfig `yaml:"action-metadata"`
+}
+
+// loadServerConfig reads the configuration file at path. An empty path
+// yields the defaults. Unknown keys are rejected so typos do not silently
+// fall back to defaults.
+func loadServerConfig(path string) (serverConfig, error) {
+	var cfg serverConfig
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return cfg, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if err := cfg.Rules.Validate(); err != nil {
+		return cfg, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if err := cfg.Templates.validate(); err != nil {
+		return cfg, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if err := cfg.Webhook.validate(); err != nil {
+		return cfg, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if err := cfg.ActionMetadata.validate(); err != nil {
+		return cfg, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
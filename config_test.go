@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadServerConfig(t *testing.T) {
+	cfg, err := loadServerConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, serverConfig{}, cfg)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("rules:\n  matrix:\n    max-combinations: 64\n"), 0644))
+
+	cfg, err = loadServerConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, 64, cfg.Rules.Matrix.MaxCombinations)
+
+	// Declared secrets and variables; an empty list still enables the check
+	declared := filepath.Join(dir, "declared.yaml")
+	require.NoError(t, os.WriteFile(declared, []byte("rules:\n  secrets: [NPM_TOKEN]\n  variables: []\n"), 0644))
+	cfg, err = loadServerConfig(declared)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"NPM_TOKEN"}, cfg.Rules.Secrets)
+	assert.NotNil(t, cfg.Rules.Variables)
+
+	empty := filepath.Join(dir, "empty.yaml")
+	require.NoError(t, os.WriteFile(empty, nil, 0644))
+	_, err = loadServerConfig(empty)
+	require.NoError(t, err)
+
+	typo := filepath.Join(dir, "typo.yaml")
+	require.NoError(t, os.WriteFile(typo, []byte("rules:\n  matrix:\n    max-combination: 64\n"), 0644))
+	_, err = loadServerConfig(typo)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "max-combination")
+
+	badPattern := filepath.Join(dir, "pattern.yaml")
+	require.NoError(t, os.WriteFile(badPattern, []byte("rules:\n  naming:\n    job-id:\n      pattern: '[a-z'\n"), 0644))
+	_, err = loadServerConfig(badPattern)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "naming-job-id")
+
+	templates := filepath.Join(dir, "templates.yaml")
+	require.NoError(t, os.WriteFile(templates, []byte("templates:\n  path: templates\n  repository: acme/.github\n"), 0644))
+	_, err = loadServerConfig(templates)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mutually exclusive")
+
+	webhook := filepath.Join(dir, "webhook.yaml")
+	require.NoError(t, os.WriteFile(webhook, []byte("webhook:\n  url: hooks.example.com/lint\n"), 0644))
+	_, err = loadServerConfig(webhook)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "http or https URL")
+
+	_, err = loadServerConfig(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// startDaemon re-executes the binary without -daemon in a new session and
+// returns once the child has started. The child owns the pid file.
+func startDaemon(args []string, opts serverOptions) error {
+	if opts.HTTPAddr == "" {
+		return errors.New("-daemon requires -http or -transport http; stdio cannot be served from the background")
+	}
+	if opts.Stdio {
+		return errors.New("-daemon cannot be combined with -stdio")
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate running binary: %w", err)
+	}
+
+	childArgs, err := daemonChildArgs(args)
+	if err != nil {
+		return err
+	}
+
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		return err
+	}
+	defer devNull.Close()
+
+	output := devNull
+	if opts.LogFile != "" {
+		output, err = os.OpenFile(opts.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		defer output.Close()
+	}
+
+	if opts.PidFile != "" {
+		if pid, running := runningPid(opts.PidFile); running {
+			return fmt.Errorf("already running with pid %d (%s)", pid, opts.PidFile)
+		}
+	}
+
+	cmd := exec.Command(exe, childArgs...)
+	cmd.Stdin = devNull
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.SysProcAttr = detachedProcAttr()
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start daemon: %w", err)
+	}
+
+	fmt.Printf("actionlint-mcp started in the background (pid %d)\n", cmd.Process.Pid)
+	return cmd.Process.Release()
+}
+
+// daemonChildArgs strips -daemon from args and makes the pid, log and
+// config file paths absolute so they stay valid for the detached child.
+func daemonChildArgs(args []string) ([]string, error) {
+	out := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") {
+			out = append(out, arg)
+			continue
+		}
+
+		switch name {
+		case "daemon":
+			continue
+		case "pid-file", "log-file", "config":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return nil, fmt.Errorf("flag -%s needs a value", name)
+				}
+				i++
+				value = args[i]
+			}
+			abs, err := filepath.Abs(value)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, "-"+name+"="+abs)
+		default:
+			out = append(out, arg)
+		}
+	}
+	return out, nil
+}
+
+// writePidFile records the current process ID, refusing to overwrite the
+// pid file of another live instance. Stale pid files are replaced.
+func writePidFile(path string) error {
+	if pid, running := runningPid(path); running && pid != os.Getpid() {
+		return fmt.Errorf("already running with pid %d (%s)", pid, path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create pid file directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write pid file: %w", err)
+	}
+	return nil
+}
+
+// runningPid reads the pid file at path and reports whether that process
+// is still alive.
+func runningPid(path string) (int, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	return pid, processAlive(pid)
+}
@@ -0,0 +1,13 @@
+//go:build !unix && !windows
+
+package main
+
+import "syscall"
+
+func detachedProcAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+func processAlive(int) bool {
+	return false
+}
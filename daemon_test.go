@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDaemonChildArgs(t *testing.T) {
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+
+	args, err := daemonChildArgs([]string{"-http", ":8080", "-daemon", "--pid-file", "run/mcp.pid", "-log-file=mcp.log", "-config", "mcp.yaml"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"-http", ":8080",
+		"-pid-file=" + filepath.Join(cwd, "run/mcp.pid"),
+		"-log-file=" + filepath.Join(cwd, "mcp.log"),
+		"-config=" + filepath.Join(cwd, "mcp.yaml"),
+	}, args)
+
+	_, err = daemonChildArgs([]string{"-pid-file"})
+	assert.Error(t, err)
+}
+
+func TestStartDaemon_RequiresHTTP(t *testing.T) {
+	err := startDaemon(nil, serverOptions{Daemon: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "-daemon requires -http")
+}
+
+func TestPidFile(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("write_and_read", func(t *testing.T) {
+		path := filepath.Join(dir, "nested", "mcp.pid")
+		require.NoError(t, writePidFile(path))
+
+		pid, running := runningPid(path)
+		assert.Equal(t, os.Getpid(), pid)
+		assert.True(t, running)
+
+		// Rewriting our own pid file is allowed
+		assert.NoError(t, writePidFile(path))
+	})
+
+	t.Run("stale_pid_file_is_replaced", func(t *testing.T) {
+		path := filepath.Join(dir, "stale.pid")
+		require.NoError(t, os.WriteFile(path, []byte("garbage\n"), 0644))
+
+		_, running := runningPid(path)
+		assert.False(t, running)
+		require.NoError(t, writePidFile(path))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, strconv.Itoa(os.Getpid())+"\n", string(data))
+	})
+
+	t.Run("live_pid_is_refused", func(t *testing.T) {
+		if os.Getppid() <= 1 {
+			t.Skip("no live parent process to point at")
+		}
+		path := filepath.Join(dir, "live.pid")
+		require.NoError(t, os.WriteFile(path, []byte(strconv.Itoa(os.Getppid())), 0644))
+
+		err := writePidFile(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already running")
+	})
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+)
+
+// Sizes of the check_all_workflows digest: how many rules it ranks, and
+// how many findings it shows by default and at most.
+const (
+	maxDigestRules        = 10
+	defaultDigestFindings = 5
+	maxDigestFindings     = 50
+)
+
+// ruleCount is how often a rule was reported, and in how many files.
+type ruleCount struct {
+	Kind     string `json:"kind"`
+	Severity string `json:"severity"`
+	Count    int    `json:"count"`
+	Files    int    `json:"files"`
+}
+
+// digestFinding is a finding with the file it is in.
+type digestFinding struct {
+	FilePath string `json:"file_path"`
+	linter.LintError
+}
+
+// workflowsDigest is the check_all_workflows output with digest set: the
+// totals, the findings per severity, the rules reported most and a finding
+// of each of the most severe rules, without the results of every file.
+type workflowsDigest struct {
+	TotalFiles       int             `json:"total_files"`
+	FilesWithErrors  int             `json:"files_with_errors"`
+	TotalErrors      int             `json:"total_errors"`
+	Severities       map[string]int  `json:"severities"`
+	TopRules         []ruleCount     `json:"top_rules"`
+	Findings         []digestFinding `json:"findings"`
+	MoreFindings     int             `json:"more_findings"`
+	CompareTo        string          `json:"compare_to,omitempty"`
+	BaselineFindings int             `json:"baseline_findings,omitempty"`
+	Meta             *linter.Meta    `json:"meta,omitempty"`
+}
+
+// checkDigestFindings checks the digest_findings parameter; zero asks for
+// the default.
+func checkDigestFindings(n int) error {
+	if n < 0 || n > maxDigestFindings {
+		return fmt.Errorf("digest_findings must be between 1 and %d", maxDigestFindings)
+	}
+	return nil
+}
+
+// digestWorkflows sums up summary. Findings holds up to limit findings, the
+// first of each rule, the most severe and most reported rules first, so one
+// noisy rule does not hide the others; the findings of a rule are in file
+// and line order.
+func digestWorkflows(summary *linter.Summary, limit int) workflowsDigest {
+	if limit == 0 {
+		limit = defaultDigestFindings
+	}
+	d := workflowsDigest{
+		TotalFiles:      summary.TotalFiles,
+		FilesWithErrors: summary.FilesWithErrors,
+		TotalErrors:     summary.TotalErrors,
+		Severities:      map[string]int{},
+		TopRules:        []ruleCount{},
+		Findings:        []digestFinding{},
+		Meta:            summary.Meta,
+	}
+
+	rules := map[string]*ruleCount{}
+	first := map[string]digestFinding{}
+	for _, r := range summary.Results {
+		seen := map[string]bool{}
+		for _, e := range r.Errors {
+			d.Severities[e.Severity]++
+			c := rules[e.Kind]
+			if c == nil {
+				c = &ruleCount{Kind: e.Kind, Severity: e.Severity}
+				rules[e.Kind] = c
+				first[e.Kind] = digestFinding{FilePath: r.FilePath, LintError: e}
+			}
+			c.Count++
+			if !seen[e.Kind] {
+				seen[e.Kind] = true
+				c.Files++
+			}
+		}
+	}
+
+	counts := make([]ruleCount, 0, len(rules))
+	for _, c := range rules {
+		counts = append(counts, *c)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Kind < counts[j].Kind
+	})
+	d.TopRules = counts[:min(len(counts), maxDigestRules)]
+
+	bySeverity := slices.Clone(counts)
+	sort.SliceStable(bySeverity, func(i, j int) bool {
+		return slices.Index(severityOrder, bySeverity[i].Severity) < slices.Index(severityOrder, bySeverity[j].Severity)
+	})
+	for _, c := range bySeverity[:min(len(bySeverity), limit)] {
+		d.Findings = append(d.Findings, first[c.Kind])
+	}
+	d.MoreFindings = d.TotalErrors - len(d.Findings)
+	return d
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigestWorkflows(t *testing.T) {
+	summary := chatSummary(12)
+	summary.Results = append(summary.Results, linter.LintResult{FilePath: ".github/workflows/release.yml", Errors: []linter.LintError{
+		{Message: "token leaked", Line: 3, Kind: "plaintext-secret", Severity: linter.SeverityCritical},
+		{Message: "problem <13>", Line: 4, Kind: "expression", Severity: linter.SeverityWarning},
+	}})
+	summary.TotalFiles, summary.FilesWithErrors, summary.TotalErrors = 3, 2, 15
+
+	d := digestWorkflows(summary, 0)
+	assert.Equal(t, 15, d.TotalErrors)
+	assert.Equal(t, map[string]int{"critical": 1, "error": 1, "warning": 13}, d.Severities)
+	assert.Equal(t, []ruleCount{
+		{Kind: "expression", Severity: linter.SeverityWarning, Count: 13, Files: 2},
+		{Kind: "plaintext-secret", Severity: linter.SeverityCritical, Count: 1, Files: 1},
+		{Kind: "syntax-check", Severity: linter.SeverityError, Count: 1, Files: 1},
+	}, d.TopRules)
+
+	// A finding of each rule, the most severe first
+	require.Len(t, d.Findings, 3)
+	assert.Equal(t, []string{"plaintext-secret", "syntax-check", "expression"}, []string{d.Findings[0].Kind, d.Findings[1].Kind, d.Findings[2].Kind})
+	assert.Equal(t, ".github/workflows/release.yml", d.Findings[0].FilePath)
+	assert.Equal(t, "problem <1>", d.Findings[2].Message)
+	assert.Equal(t, 12, d.MoreFindings)
+
+	d = digestWorkflows(summary, 1)
+	require.Len(t, d.Findings, 1)
+	assert.Equal(t, 14, d.MoreFindings)
+}
+
+func TestCheckAllWorkflows_Digest(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ci.yml"), []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ github.undefined }}\n"), 0644))
+	call := func(args CheckAllWorkflowsParams) (*mcp.CallToolResultFor[any], error) {
+		args.Directory = dir
+		return CheckAllWorkflows(context.Background(), nil, &mcp.CallToolParamsFor[CheckAllWorkflowsParams]{Arguments: args})
+	}
+
+	result, err := call(CheckAllWorkflowsParams{Digest: true, DigestFindings: 3})
+	require.NoError(t, err)
+	text := result.Content[0].(*mcp.TextContent).Text
+	assert.NotContains(t, text, `"results"`)
+	var d workflowsDigest
+	require.NoError(t, json.Unmarshal([]byte(text), &d))
+	assert.Equal(t, 1, d.TotalFiles)
+	assert.Equal(t, 1, d.TotalErrors)
+	require.Len(t, d.TopRules, 1)
+	assert.Equal(t, "expression", d.TopRules[0].Kind)
+	require.Len(t, d.Findings, 1)
+	assert.Equal(t, filepath.Join(dir, "ci.yml"), d.Findings[0].FilePath)
+	assert.Zero(t, d.MoreFindings)
+
+	for args, msg := range map[*CheckAllWorkflowsParams]string{
+		{Digest: true, Format: formatSlackBlocks}: "digest only applies to the json format",
+		{Digest: true, ResultsAsMap: true}:        "mutually exclusive",
+		{DigestFindings: 3}:                       "digest_findings needs digest",
+		{Digest: true, DigestFindings: 100}:       "digest_findings must be between 1 and 50",
+	} {
+		_, err := call(*args)
+		assert.ErrorContains(t, err, msg)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxAPIResponseSize caps the body of a GitHub API response. The largest
+// the tools read are recursive trees and pages of a hundred items, well
+// below it.
+const maxAPIResponseSize = 10 << 20
+
+// githubAPIBaseURL is the GitHub REST API the tools and self-update call.
+// It is a variable so tests can point it at a local server.
+var githubAPIBaseURL = "https://api.github.com"
+
+// statusError is returned by fetch for responses other than 200 OK.
+type statusError struct {
+	url, status string
+	code        int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("GET %s: unexpected status %s", e.url, e.status)
+}
+
+// githubGet returns the body of the GitHub API response to a GET of url,
+// failing when it is larger than maxAPIResponseSize.
+func githubGet(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	return fetch(ctx, client, url, maxAPIResponseSize)
+}
+
+// fetch returns the body of the response to a GET of url, failing when it
+// is larger than limit bytes. GITHUB_TOKEN is sent to the GitHub API only.
+func fetch(ctx context.Context, client *http.Client, url string, limit int) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "actionlint-mcp/"+version)
+	if token := settingsFrom(ctx).githubToken; token != "" && isGitHubAPI(ctx, url) {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{url: url, status: resp.Status, code: resp.StatusCode}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > limit {
+		return nil, fmt.Errorf("GET %s: response exceeds %d bytes", url, limit)
+	}
+	return data, nil
+}
+
+// isGitHubAPI reports whether rawURL is on github.com's API or the
+// configured GitHub Enterprise Server's, which GITHUB_TOKEN is sent to.
+// The scheme and host must match exactly, so a host such as
+// api.github.com.example does not get the token.
+func isGitHubAPI(ctx context.Context, rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User != nil {
+		return false
+	}
+	for _, base := range []string{githubAPIBaseURL, settingsFrom(ctx).config.ActionMetadata.APIURL} {
+		api, err := url.Parse(base)
+		if base == "" || err != nil {
+			continue
+		}
+		path := strings.TrimSuffix(api.Path, "/")
+		if u.Scheme == api.Scheme && strings.EqualFold(u.Host, api.Host) && (u.Path == path || strings.HasPrefix(u.Path, path+"/")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.NotFound(w, r)
+		case "/large":
+			_, _ = w.Write([]byte(strings.Repeat("x", maxAPIResponseSize+1)))
+		default:
+			_, _ = w.Write([]byte("0123456789"))
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	data, err := fetch(ctx, server.Client(), server.URL+"/ok", 10)
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789", string(data))
+	_, err = fetch(ctx, server.Client(), server.URL+"/ok", 9)
+	assert.ErrorContains(t, err, "response exceeds 9 bytes")
+
+	_, err = githubGet(ctx, server.Client(), server.URL+"/missing")
+	var status *statusError
+	require.ErrorAs(t, err, &status)
+	assert.Equal(t, http.StatusNotFound, status.code)
+
+	// API responses are capped well below release assets
+	_, err = githubGet(ctx, server.Client(), server.URL+"/large")
+	assert.ErrorContains(t, err, "response exceeds")
+	data, err = download(ctx, server.Client(), server.URL+"/large")
+	require.NoError(t, err)
+	assert.Len(t, data, maxAPIResponseSize+1)
+}
+
+func TestIsGitHubAPI(t *testing.T) {
+	s, err := newSettings(serverConfig{ActionMetadata: actionMetadataConfig{APIURL: "https://ghe.example.com/api/v3/"}}, false)
+	require.NoError(t, err)
+	ctx := withSettings(context.Background(), s)
+
+	for rawURL, want := range map[string]bool{
+		"https://api.github.com/repos/o/r":              true,
+		"https://API.github.com/repos/o/r":              true,
+		"https://ghe.example.com/api/v3/repos/o/r":      true,
+		"https://api.github.com.example/repos/o/r":      false,
+		"https://api.github.com@evil.example/repos":     false,
+		"https://evil.example/?https://api.github.com/": false,
+		"http://api.github.com/repos/o/r":               false,
+		"https://ghe.example.com/api/v3.evil/repos":     false,
+		"https://ghe.example.com/login":                 false,
+		"https://ghe.example.com.evil/api/v3/repos":     false,
+	} {
+		assert.Equal(t, want, isGitHubAPI(ctx, rawURL), rawURL)
+	}
+}
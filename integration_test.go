@@ -276,7 +276,7 @@ jobs:
 
 		textContent, ok := result.Content[0].(*mcp.TextContent)
 		require.True(t, ok)
-		assert.Contains(t, textContent.Text, "No workflow files found")
+		assert.Contains(t, textContent.Text, `"total_files": 0`)
 	})
 
 	t.Run("check_nonexistent_directory", func(t *testing.T) {
@@ -292,7 +292,7 @@ jobs:
 
 		textContent, ok := result.Content[0].(*mcp.TextContent)
 		require.True(t, ok)
-		assert.Contains(t, textContent.Text, "No workflow files found")
+		assert.Contains(t, textContent.Text, `"total_files": 0`)
 	})
 }
 
@@ -304,6 +304,7 @@ func TestEnvironmentVariables(t *testing.T) {
 		oldVal := os.Getenv("SHELLCHECK_COMMAND")
 		os.Setenv("SHELLCHECK_COMMAND", "shellcheck")
 		defer os.Setenv("SHELLCHECK_COMMAND", oldVal)
+		useSettings(t, serverConfig{}, false)
 
 		workflow := `name: Shell Test
 on: push
@@ -331,6 +332,7 @@ jobs:
 		oldVal := os.Getenv("PYFLAKES_COMMAND")
 		os.Setenv("PYFLAKES_COMMAND", "pyflakes")
 		defer os.Setenv("PYFLAKES_COMMAND", oldVal)
+		useSettings(t, serverConfig{}, false)
 
 		workflow := `name: Python Test
 on: push
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -458,6 +459,38 @@ jobs:
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
 	})
+
+	t.Run("oversized_workflow", func(t *testing.T) {
+		t.Setenv("MAX_WORKFLOW_SIZE", "1024")
+		useSettings(t, serverConfig{}, false)
+		params := &mcp.CallToolParamsFor[LintWorkflowParams]{
+			Arguments: LintWorkflowParams{
+				Content: "on: push\n" + strings.Repeat("# padding\n", 200),
+			},
+		}
+
+		_, err := LintWorkflow(context.Background(), session, params)
+		assert.ErrorIs(t, err, linter.ErrInputLimit)
+	})
+
+	t.Run("alias_bomb", func(t *testing.T) {
+		workflow := "a: &a [x, x, x, x, x, x, x, x, x, x]\n"
+		for i, prev := 0, "a"; i < 8; i++ {
+			next := fmt.Sprintf("b%d", i)
+			workflow += fmt.Sprintf("%s: &%s [%s]\n", next, next, strings.TrimSuffix(strings.Repeat("*"+prev+", ", 10), ", "))
+			prev = next
+		}
+
+		_, err := LintWorkflow(context.Background(), session, &mcp.CallToolParamsFor[LintWorkflowParams]{
+			Arguments: LintWorkflowParams{Content: workflow},
+		})
+		assert.ErrorIs(t, err, linter.ErrInputLimit)
+
+		_, err = FormatWorkflow(context.Background(), session, &mcp.CallToolParamsFor[FormatWorkflowParams]{
+			Arguments: FormatWorkflowParams{Content: workflow},
+		})
+		assert.ErrorIs(t, err, linter.ErrInputLimit)
+	})
 }
 
 func TestLintWorkflow_ErrorDetails(t *testing.T) {
@@ -603,4 +636,125 @@ jobs:
 			assert.NotNil(t, result)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestLintWorkflow_Scope(t *testing.T) {
+	workflow := `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ github.undefined_build }}
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ok
+      - run: echo ${{ github.undefined_test }}
+`
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	require.NoError(t, os.WriteFile(path, []byte(workflow), 0644))
+
+	for _, args := range []LintWorkflowParams{
+		{FilePath: path, Scope: "test"},
+		{Content: workflow, Scope: "test/2"},
+	} {
+		result, err := LintWorkflow(context.Background(), nil, &mcp.CallToolParamsFor[LintWorkflowParams]{Arguments: args})
+		require.NoError(t, err)
+
+		var scoped scopedResult
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &scoped))
+		assert.Equal(t, args.Scope, scoped.Scope)
+		assert.Equal(t, 1, scoped.OutOfScopeFindings)
+		require.Len(t, scoped.Errors, 1)
+		assert.Contains(t, scoped.Errors[0].Message, "undefined_test")
+		assert.False(t, scoped.Valid)
+	}
+
+	_, err := LintWorkflow(context.Background(), nil, &mcp.CallToolParamsFor[LintWorkflowParams]{Arguments: LintWorkflowParams{FilePath: path, Scope: "test/3"}})
+	assert.ErrorContains(t, err, `invalid scope "test/3"`)
+}
+
+func TestLintWorkflow_CompareTo(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ci.yml")
+	old := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ github.undefined_old }}\n"
+	require.NoError(t, os.WriteFile(path, []byte(old), 0644))
+	saved, err := LintWorkflow(context.Background(), nil, &mcp.CallToolParamsFor[LintWorkflowParams]{Arguments: LintWorkflowParams{FilePath: path}})
+	require.NoError(t, err)
+	baseline := filepath.Join(dir, "baseline.json")
+	require.NoError(t, os.WriteFile(baseline, []byte(saved.Content[0].(*mcp.TextContent).Text), 0644))
+
+	// The old finding moves down a line and a new one is added
+	require.NoError(t, os.WriteFile(path, []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ok\n      - run: echo ${{ github.undefined_old }}\n      - run: echo ${{ github.undefined_new }}\n"), 0644))
+	result, err := LintWorkflow(context.Background(), nil, &mcp.CallToolParamsFor[LintWorkflowParams]{Arguments: LintWorkflowParams{FilePath: path, CompareTo: baseline}})
+	require.NoError(t, err)
+	var compared comparedResult
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &compared))
+	assert.Equal(t, baseline, compared.CompareTo)
+	assert.Equal(t, 1, compared.BaselineFindings)
+	require.Len(t, compared.Errors, 1)
+	assert.Contains(t, compared.Errors[0].Message, "undefined_new")
+	assert.False(t, compared.Valid)
+
+	summary, err := CheckAllWorkflows(context.Background(), nil, &mcp.CallToolParamsFor[CheckAllWorkflowsParams]{Arguments: CheckAllWorkflowsParams{Directory: dir, CompareTo: baseline}})
+	require.NoError(t, err)
+	var comparedAll comparedSummary
+	require.NoError(t, json.Unmarshal([]byte(summary.Content[0].(*mcp.TextContent).Text), &comparedAll))
+	assert.Equal(t, 1, comparedAll.BaselineFindings)
+	assert.Equal(t, 1, comparedAll.TotalErrors)
+
+	_, err = LintWorkflow(context.Background(), nil, &mcp.CallToolParamsFor[LintWorkflowParams]{Arguments: LintWorkflowParams{FilePath: path, CompareTo: "no-such-ref"}})
+	assert.ErrorContains(t, err, "cannot compare with no-such-ref")
+}
+
+func TestLintWorkflow_FailOn(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ci.yml")
+	// Only a step-order warning, with the style pack: make runs before the
+	// checkout
+	require.NoError(t, os.WriteFile(path, []byte("on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n"), 0644))
+
+	lint := func(failOn string) LintResult {
+		result, err := LintWorkflow(context.Background(), nil, &mcp.CallToolParamsFor[LintWorkflowParams]{Arguments: LintWorkflowParams{FilePath: path, FailOn: failOn, Packs: []string{"style"}}})
+		require.NoError(t, err)
+		var out LintResult
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out))
+		return out
+	}
+	for failOn, valid := range map[string]bool{"": false, "info": false, "warning": false, "error": true} {
+		result := lint(failOn)
+		assert.Equal(t, valid, result.Valid, failOn)
+		require.Len(t, result.Errors, 1, "findings are reported whatever fail_on is")
+		assert.Equal(t, "warning", result.Errors[0].Severity)
+	}
+
+	check := func(failOn string) linter.Summary {
+		result, err := CheckAllWorkflows(context.Background(), nil, &mcp.CallToolParamsFor[CheckAllWorkflowsParams]{Arguments: CheckAllWorkflowsParams{Directory: dir, FailOn: failOn, Packs: []string{"style"}}})
+		require.NoError(t, err)
+		var out linter.Summary
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out))
+		return out
+	}
+	summary := check("error")
+	assert.Zero(t, summary.FilesWithErrors)
+	assert.Equal(t, 1, summary.TotalErrors)
+	assert.True(t, summary.Results[0].Valid)
+	summary = check("warning")
+	assert.Equal(t, 1, summary.FilesWithErrors)
+	assert.False(t, summary.Results[0].Valid)
+
+	// actionlint's own findings are errors: an unknown needs, a mistyped
+	// runner label, an undefined action input and an undefined property
+	require.NoError(t, os.WriteFile(path, []byte("on: push\njobs:\n  build:\n    runs-on: ubuntu-lastest\n    needs: [setup]\n    steps:\n      - uses: actions/checkout@v4\n        with:\n          fetch-depht: 0\n      - run: echo ${{ github.shaa }}\n"), 0644))
+	result := lint("error")
+	assert.False(t, result.Valid)
+	assert.Len(t, result.Errors, 4)
+	summary = check("error")
+	assert.Equal(t, 1, summary.FilesWithErrors)
+	assert.False(t, summary.Results[0].Valid)
+
+	_, err := LintWorkflow(context.Background(), nil, &mcp.CallToolParamsFor[LintWorkflowParams]{Arguments: LintWorkflowParams{FilePath: path, FailOn: "critical"}})
+	assert.ErrorContains(t, err, `unknown fail_on "critical"`)
+	_, err = CheckAllWorkflows(context.Background(), nil, &mcp.CallToolParamsFor[CheckAllWorkflowsParams]{Arguments: CheckAllWorkflowsParams{Directory: dir, FailOn: "none"}})
+	assert.ErrorContains(t, err, `unknown fail_on "none"`)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+)
+
+const lintUsage = "Usage: actionlint-mcp lint [-fail-level error|warning|info] [-format text|json] [-config file] [path ...]"
+
+// Exit codes of the lint command, as reviewdog and other CI wrappers
+// expect: findings at or above the fail level exit 1, and a run that could
+// not lint exits 2.
+const (
+	exitFindings  = 1
+	exitLintError = 2
+)
+
+// failLevels are the values of -fail-level, from the strictest.
+var failLevels = []string{linter.SeverityInfo, linter.SeverityWarning, linter.SeverityError}
+
+type lintCommandOptions struct {
+	FailLevel  string
+	Format     string
+	ConfigFile string
+}
+
+func newLintFlagSet(opts *lintCommandOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), lintUsage)
+		fs.PrintDefaults()
+	}
+
+	fs.StringVar(&opts.FailLevel, "fail-level", linter.SeverityError, "Exit with status 1 when a finding is at least this severe: error, warning or info")
+	fs.StringVar(&opts.Format, "format", "text", "Output format: text, one file:line:column: line per finding, or json")
+	fs.Var((*pathFlag)(&opts.ConfigFile), "config", "Read rule settings from this YAML configuration file")
+	return fs
+}
+
+// exitError makes main exit with code, printing err first when set.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error { return e.err }
+
+func runLint(args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return lintCommand(ctx, args, os.Stdout)
+}
+
+// lintCommand lints the workflow files and directories in args, by default
+// .github/workflows, writes the findings to out and returns an exitError
+// with exitFindings when one is at or above the fail level.
+func lintCommand(ctx context.Context, args []string, out io.Writer) error {
+	var opts lintCommandOptions
+	fs := newLintFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &exitError{code: exitLintError, err: err}
+	}
+	if !slices.Contains(failLevels, opts.FailLevel) {
+		return &exitError{code: exitLintError, err: fmt.Errorf("unknown fail level %q; use error, warning or info", opts.FailLevel)}
+	}
+	if opts.Format != "text" && opts.Format != formatJSON {
+		return &exitError{code: exitLintError, err: fmt.Errorf("unknown format %q; use text or %s", opts.Format, formatJSON)}
+	}
+
+	cfg, err := loadServerConfig(opts.ConfigFile)
+	if err != nil {
+		return &exitError{code: exitLintError, err: err}
+	}
+	s, err := newSettings(cfg, false)
+	if err != nil {
+		return &exitError{code: exitLintError, err: err}
+	}
+	ctx = withSettings(ctx, s)
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{".github/workflows"}
+	}
+	var files []string
+	for _, path := range paths {
+		path = linter.CleanPath(path)
+		info, err := os.Stat(path)
+		if err != nil {
+			return &exitError{code: exitLintError, err: err}
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		found, err := linter.FindWorkflowFiles(path)
+		if err != nil {
+			return &exitError{code: exitLintError, err: fmt.Errorf("failed to read directory: %w", err)}
+		}
+		files = append(files, found...)
+	}
+
+	summary := linter.New(s.lint).LintFiles(ctx, files)
+	if err := ctx.Err(); err != nil {
+		return &exitError{code: exitLintError, err: err}
+	}
+	if err := writeLintOutput(out, summary, opts.Format); err != nil {
+		return &exitError{code: exitLintError, err: err}
+	}
+	if failing(summary, opts.FailLevel) {
+		return &exitError{code: exitFindings}
+	}
+	return nil
+}
+
+// writeLintOutput writes summary to out in format. The text format is the
+// one of actionlint, which reviewdog reads with -efm="%f:%l:%c: %m".
+func writeLintOutput(out io.Writer, summary *linter.Summary, format string) error {
+	if format == formatJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(summary)
+	}
+	for _, r := range summary.Results {
+		for _, e := range r.Errors {
+			if _, err := fmt.Fprintf(out, "%s:%d:%d: %s: %s [%s]\n", r.FilePath, e.Line, e.Column, e.Severity, e.Message, e.Kind); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// failing reports whether a finding of summary is at least as severe as
+// level.
+func failing(summary *linter.Summary, level string) bool {
+	limit := slices.Index(severityOrder, level)
+	for _, r := range summary.Results {
+		for _, e := range r.Errors {
+			if i := slices.Index(severityOrder, e.Severity); i >= 0 && i <= limit {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exitCode returns the exit status main uses for err.
+func exitCode(err error) int {
+	var exit *exitError
+	if errors.As(err, &exit) {
+		return exit.code
+	}
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+func TestLintCommand(t *testing.T) {
+	dir := t.TempDir()
+	// A single warning, with the style pack the repository turns on
+	workflow := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    continue-on-error: true\n    steps:\n      - run: echo hi\n"
+	path := filepath.Join(dir, "ci.yml")
+	require.NoError(t, os.WriteFile(path, []byte(workflow), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".github"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, linter.RepositoryConfigFile), []byte("packs: [style]\n"), 0644))
+
+	var out bytes.Buffer
+	err := lintCommand(context.Background(), []string{dir}, &out)
+	assert.Equal(t, 0, exitCode(err), "warnings pass at the default fail level")
+	assert.Contains(t, out.String(), path+":5:24: warning: ")
+	assert.Contains(t, out.String(), "[failure-handling]")
+
+	out.Reset()
+	err = lintCommand(context.Background(), []string{"-fail-level", "warning", path}, &out)
+	assert.Equal(t, exitFindings, exitCode(err))
+	assert.NoError(t, errors.Unwrap(err), "nothing more is printed")
+
+	out.Reset()
+	err = lintCommand(context.Background(), []string{"-fail-level=info", "-format", "json", path}, &out)
+	assert.Equal(t, exitFindings, exitCode(err))
+	var summary linter.Summary
+	require.NoError(t, json.Unmarshal(out.Bytes(), &summary))
+	assert.Equal(t, 1, summary.TotalFiles)
+
+	require.NoError(t, os.WriteFile(path, []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    timeout: 10\n    steps:\n      - run: echo hi\n"), 0644))
+	err = lintCommand(context.Background(), []string{dir}, &out)
+	assert.Equal(t, exitFindings, exitCode(err), "errors fail at the default fail level")
+}
+
+func TestLintCommand_ActionlintFindings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	workflow := `on: push
+jobs:
+  build:
+    runs-on: ubuntu-lastest
+    needs: [setup]
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          fetch-depht: 0
+      - run: echo ${{ github.shaa }}
+`
+	require.NoError(t, os.WriteFile(path, []byte(workflow), 0644))
+
+	var out bytes.Buffer
+	err := lintCommand(context.Background(), []string{path}, &out)
+	assert.Equal(t, exitFindings, exitCode(err), "actionlint's findings fail at the default fail level")
+	for _, kind := range []string{"runner-label", "job-needs", "action", "expression"} {
+		assert.Contains(t, out.String(), ": error: ", kind)
+		assert.Contains(t, out.String(), "["+kind+"]")
+	}
+}
+
+func TestLintCommand_Errors(t *testing.T) {
+	for _, args := range [][]string{
+		{"-fail-level", "fatal"},
+		{"-format", "sarif"},
+		{filepath.Join(t.TempDir(), "missing.yml")},
+		{"-unknown"},
+	} {
+		err := lintCommand(context.Background(), args, &bytes.Buffer{})
+		assert.Equal(t, exitLintError, exitCode(err), "%v", args)
+	}
+}
@@ -2,17 +2,13 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"log"
 	"os"
-	"path/filepath"
-
-	"github.com/modelcontextprotocol/go-sdk/jsonschema"
-	"github.com/modelcontextprotocol/go-sdk/mcp"
-	"github.com/rhysd/actionlint"
+	"os/signal"
+	"syscall"
 )
 
 // Build variables set by ldflags
@@ -23,211 +19,104 @@ var (
 	builtBy = "unknown"
 )
 
-type LintWorkflowParams struct {
-	FilePath string `json:"file_path,omitempty" jsonschema:"description=Path to the workflow file to lint"`
-	Content  string `json:"content,omitempty" jsonschema:"description=Content of the workflow file to lint (if file_path is not provided)"`
-}
-
-type CheckAllWorkflowsParams struct {
-	Directory string `json:"directory,omitempty" jsonschema:"description=Directory to search for workflow files (defaults to .github/workflows)"`
-}
-
-type LintResult struct {
-	Errors   []LintError `json:"errors"`
-	Valid    bool        `json:"valid"`
-	FilePath string      `json:"file_path,omitempty"`
+// serverOptions holds the flags accepted when running the MCP server.
+type serverOptions struct {
+	ShowVersion bool
+	Stdio       bool
+	HTTPAddr    string
+	Transport   string
+	Listen      string
+	Daemon      bool
+	PidFile     string
+	LogFile     string
+	ConfigFile  string
+	AllowWrites bool
+	MaxMemory   byteSize
+	MaxRequest  byteSize
 }
 
-type LintError struct {
-	Message  string `json:"message"`
-	Line     int    `json:"line"`
-	Column   int    `json:"column"`
-	Kind     string `json:"kind"`
-	Severity string `json:"severity"`
+// registerServerFlags defines the server flags on fs so the same definitions
+// back both argument parsing and shell completion.
+func registerServerFlags(fs *flag.FlagSet, opts *serverOptions) {
+	fs.BoolVar(&opts.ShowVersion, "version", false, "Print version information")
+	fs.BoolVar(&opts.Stdio, "stdio", false, "Serve MCP over stdio alongside -http (stdio is the default without -http)")
+	fs.StringVar(&opts.HTTPAddr, "http", "", "Serve MCP over streamable HTTP on this address (e.g. :8080)")
+	fs.StringVar(&opts.Transport, "transport", "", "Transport to serve MCP over: stdio (the default) or http, which is -http on the -listen address")
+	fs.StringVar(&opts.Listen, "listen", "", "Address -transport http listens on (e.g. :8080)")
+	fs.BoolVar(&opts.Daemon, "daemon", false, "Detach and run in the background (requires -http)")
+	fs.Var((*pathFlag)(&opts.PidFile), "pid-file", "Write the server process ID to this file while running")
+	fs.Var((*pathFlag)(&opts.LogFile), "log-file", "Append daemon output to this file instead of discarding it")
+	fs.Var((*pathFlag)(&opts.ConfigFile), "config", "Read rule settings from this YAML configuration file")
+	fs.BoolVar(&opts.AllowWrites, "allow-writes", false, "Let tools write the files they change instead of only returning the changes")
+	fs.Var(&opts.MaxMemory, "max-memory", "Soft memory limit, as GOMEMLIMIT, above which tool calls are refused (e.g. 512MiB)")
+	fs.Var(&opts.MaxRequest, "max-request-size", "Largest HTTP request body accepted (e.g. 4MiB; defaults to 8MiB)")
 }
 
-func LintWorkflow(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[LintWorkflowParams]) (*mcp.CallToolResultFor[any], error) {
-	var filePath string
-	var content []byte
-	var err error
+// Transports accepted by -transport.
+const (
+	transportStdio = "stdio"
+	transportHTTP  = "http"
+)
 
-	if params.Arguments.FilePath != "" {
-		filePath = params.Arguments.FilePath
-		content, err = os.ReadFile(filePath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read file: %w", err)
+// resolveTransport folds -transport and -listen into -stdio and -http,
+// which the rest of the server reads.
+func (o *serverOptions) resolveTransport() error {
+	switch o.Transport {
+	case "":
+		if o.Listen != "" {
+			return errors.New("-listen needs -transport http")
 		}
-	} else if params.Arguments.Content != "" {
-		filePath = "inline.yml"
-		content = []byte(params.Arguments.Content)
-	} else {
-		return nil, fmt.Errorf("either file_path or content must be provided")
-	}
-
-	// Create linter with default options
-	configFile := ".github/actionlint.yaml"
-	if _, err := os.Stat(configFile); os.IsNotExist(err) {
-		configFile = ""
-	}
-
-	opts := &actionlint.LinterOptions{
-		Shellcheck:     os.Getenv("SHELLCHECK_COMMAND"),
-		Pyflakes:       os.Getenv("PYFLAKES_COMMAND"),
-		ConfigFile:     configFile,
-		IgnorePatterns: []string{},
-	}
-
-	linter, err := actionlint.NewLinter(io.Discard, opts)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create linter: %w", err)
-	}
-
-	// Run the linter
-	errs, err := linter.Lint(filePath, content, nil)
-	if err != nil {
-		return nil, fmt.Errorf("linting failed: %w", err)
-	}
-
-	// Convert errors to our format
-	result := LintResult{
-		Errors:   make([]LintError, 0, len(errs)),
-		Valid:    len(errs) == 0,
-		FilePath: filePath,
-	}
-
-	for _, e := range errs {
-		lintErr := LintError{
-			Message: e.Message,
-			Kind:    e.Kind,
+	case transportStdio:
+		if o.HTTPAddr != "" || o.Listen != "" {
+			return errors.New("-transport stdio cannot be combined with -http or -listen; use -stdio to serve both")
 		}
-
-		// Get position info
-		lintErr.Line = e.Line
-		lintErr.Column = e.Column
-
-		// Determine severity based on error kind
-		switch e.Kind {
-		case "syntax-check", "type-check":
-			lintErr.Severity = "error"
-		case "shellcheck", "pyflakes":
-			lintErr.Severity = "warning"
-		default:
-			lintErr.Severity = "info"
+	case transportHTTP:
+		switch {
+		case o.Listen == "" && o.HTTPAddr == "":
+			return errors.New("-transport http needs -listen")
+		case o.Listen != "" && o.HTTPAddr != "" && o.Listen != o.HTTPAddr:
+			return errors.New("-listen and -http name different addresses; pass only one")
+		case o.Listen != "":
+			o.HTTPAddr = o.Listen
 		}
-
-		result.Errors = append(result.Errors, lintErr)
-	}
-
-	// Convert result to JSON string for display
-	resultJSON, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	default:
+		return fmt.Errorf("unknown -transport %q; use %s or %s", o.Transport, transportStdio, transportHTTP)
 	}
-
-	return &mcp.CallToolResultFor[any]{
-		Content: []mcp.Content{
-			&mcp.TextContent{
-				Text: string(resultJSON),
-			},
-		},
-	}, nil
+	return nil
 }
 
-func CheckAllWorkflows(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[CheckAllWorkflowsParams]) (*mcp.CallToolResultFor[any], error) {
-	directory := ".github/workflows"
-	if params.Arguments.Directory != "" {
-		directory = params.Arguments.Directory
-	}
-
-	// Find all workflow files
-	pattern := filepath.Join(directory, "*.yml")
-	files1, _ := filepath.Glob(pattern)
-	pattern = filepath.Join(directory, "*.yaml")
-	files2, _ := filepath.Glob(pattern)
-
-	files := append(files1, files2...)
-
-	if len(files) == 0 {
-		return &mcp.CallToolResultFor[any]{
-			Content: []mcp.Content{
-				&mcp.TextContent{
-					Text: fmt.Sprintf("No workflow files found in %s", directory),
-				},
-			},
-		}, nil
-	}
-
-	// Lint all files
-	allResults := make(map[string]LintResult)
-
-	for _, file := range files {
-		// Call LintWorkflow for each file
-		lintParams := &mcp.CallToolParamsFor[LintWorkflowParams]{
-			Arguments: LintWorkflowParams{
-				FilePath: file,
-			},
-		}
-
-		result, err := LintWorkflow(ctx, session, lintParams)
-		if err != nil {
-			allResults[file] = LintResult{
-				Errors: []LintError{{
-					Message:  fmt.Sprintf("Failed to lint: %v", err),
-					Severity: "error",
-				}},
-				Valid:    false,
-				FilePath: file,
-			}
-			continue
-		}
-
-		// Parse the result back from JSON
-		var lintResult LintResult
-		if len(result.Content) > 0 {
-			if textContent, ok := result.Content[0].(*mcp.TextContent); ok {
-				if err := json.Unmarshal([]byte(textContent.Text), &lintResult); err == nil {
-					allResults[file] = lintResult
+func main() {
+	// Dispatch subcommands before parsing server flags
+	if len(os.Args) > 1 {
+		if cmd := lookupCommand(os.Args[1]); cmd != nil {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				if errors.Is(err, flag.ErrHelp) {
+					return
+				}
+				code := 1
+				var exit *exitError
+				if errors.As(err, &exit) {
+					code, err = exit.code, exit.err
 				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+				}
+				os.Exit(code)
 			}
+			return
 		}
 	}
 
-	// Format the results
-	summary := map[string]interface{}{
-		"total_files":       len(files),
-		"files_with_errors": 0,
-		"total_errors":      0,
-		"results":           allResults,
-	}
-
-	for _, result := range allResults {
-		if !result.Valid {
-			summary["files_with_errors"] = summary["files_with_errors"].(int) + 1
-			summary["total_errors"] = summary["total_errors"].(int) + len(result.Errors)
-		}
-	}
-
-	resultJSON, err := json.MarshalIndent(summary, "", "  ")
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal result: %w", err)
-	}
-
-	return &mcp.CallToolResultFor[any]{
-		Content: []mcp.Content{
-			&mcp.TextContent{
-				Text: string(resultJSON),
-			},
-		},
-	}, nil
-}
-
-func main() {
 	// Parse command line flags
-	versionFlag := flag.Bool("version", false, "Print version information")
+	var opts serverOptions
+	registerServerFlags(flag.CommandLine, &opts)
 	flag.Parse()
+	if err := opts.resolveTransport(); err != nil {
+		log.Fatal(err)
+	}
 
 	// Handle version flag
-	if *versionFlag {
+	if opts.ShowVersion {
 		fmt.Printf("actionlint-mcp %s\n", version)
 		fmt.Printf("  Commit: %s\n", commit)
 		fmt.Printf("  Built:  %s\n", date)
@@ -235,56 +124,35 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Create the server
-	server := mcp.NewServer(&mcp.Implementation{
-		Name:    "actionlint-mcp",
-		Version: version,
-	}, nil)
-
-	// Register the lint_workflow tool
-	lintSchema := &jsonschema.Schema{
-		Type: "object",
-		Properties: map[string]*jsonschema.Schema{
-			"file_path": {
-				Type:        "string",
-				Description: "Path to the workflow file to lint",
-			},
-			"content": {
-				Type:        "string",
-				Description: "Content of the workflow file to lint (if file_path is not provided)",
-			},
-		},
-		OneOf: []*jsonschema.Schema{
-			{Required: []string{"file_path"}},
-			{Required: []string{"content"}},
-		},
+	// Load the configuration before detaching so errors are reported
+	cfg, err := loadServerConfig(opts.ConfigFile)
+	if err != nil {
+		log.Fatal(err)
 	}
+	s, err := newSettings(cfg, opts.AllowWrites)
+	if err != nil {
+		log.Fatal(err)
+	}
+	startupSettings.Store(s)
+	maxRequestSize = int64(opts.MaxRequest)
 
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "lint_workflow",
-		Description: "Lint a GitHub Actions workflow file using actionlint",
-		InputSchema: lintSchema,
-	}, LintWorkflow)
-
-	// Register the check_all_workflows tool
-	checkSchema := &jsonschema.Schema{
-		Type: "object",
-		Properties: map[string]*jsonschema.Schema{
-			"directory": {
-				Type:        "string",
-				Description: "Directory to search for workflow files (defaults to .github/workflows)",
-			},
-		},
+	// Detach into the background; the child re-runs without -daemon
+	if opts.Daemon {
+		if err := startDaemon(os.Args[1:], opts); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
 
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "check_all_workflows",
-		Description: "Check all GitHub Actions workflow files in a directory",
-		InputSchema: checkSchema,
-	}, CheckAllWorkflows)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 
-	// Run the server
-	if err := server.Run(context.Background(), mcp.NewStdioTransport()); err != nil {
+	// Run the server, refusing tool calls once it nears its memory limit
+	applyMemoryLimit(int64(opts.MaxMemory))
+	server := newServer()
+	server.AddReceivingMiddleware(memoryGuard(memoryLimit()), settingsMiddleware(s))
+	err = run(ctx, server, opts)
+	stop()
+	if err != nil {
 		log.Fatal(err)
 	}
-}
\ No newline at end of file
+}
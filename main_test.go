@@ -1,12 +1,16 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -27,6 +31,11 @@ func (suite *ActionlintTestSuite) SetupSuite() {
 
 	// Create a mock session
 	suite.session = &mcp.ServerSession{}
+
+	// Let the fixer tools write, as with -allow-writes, keeping their
+	// backups in the temporary directory
+	suite.T().Setenv("TMPDIR", tempDir)
+	useSettings(suite.T(), serverConfig{}, true)
 }
 
 func (suite *ActionlintTestSuite) TearDownSuite() {
@@ -76,6 +85,62 @@ jobs:
 	assert.Equal(suite.T(), filePath, lintResult.FilePath)
 }
 
+func (suite *ActionlintTestSuite) TestLintWorkflow_SchemaVersion() {
+	t := suite.T()
+	content := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n"
+
+	result, err := LintWorkflow(context.Background(), suite.session, &mcp.CallToolParamsFor[LintWorkflowParams]{
+		Arguments: LintWorkflowParams{Content: content, ResultFormatVersion: 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Meta["schema_version"])
+	text := result.Content[0].(*mcp.TextContent).Text
+	assert.True(t, strings.HasPrefix(text, "{\n  \"schema_version\": 1,\n  \"errors\""), text)
+
+	var parsed struct {
+		SchemaVersion int  `json:"schema_version"`
+		Valid         bool `json:"valid"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(text), &parsed))
+	assert.Equal(t, linter.SchemaVersion, parsed.SchemaVersion)
+	assert.True(t, parsed.Valid)
+
+	_, err = LintWorkflow(context.Background(), suite.session, &mcp.CallToolParamsFor[LintWorkflowParams]{
+		Arguments: LintWorkflowParams{Content: content, ResultFormatVersion: 2},
+	})
+	assert.EqualError(t, err, "result_format_version 2 is not supported; this server produces version 1")
+
+	found, err := FindMisplacedWorkflows(context.Background(), suite.session, &mcp.CallToolParamsFor[FindMisplacedWorkflowsParams]{
+		Arguments: FindMisplacedWorkflowsParams{Directory: suite.tempDir},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, found.Meta["schema_version"])
+	text = found.Content[0].(*mcp.TextContent).Text
+	assert.True(t, strings.HasPrefix(text, "{\n  \"schema_version\": 1,\n  \"items\": ["), "outputs that are not objects are held in items: %s", text)
+}
+
+func (suite *ActionlintTestSuite) TestJSONResult_Versioned() {
+	t := suite.T()
+	text := func(v any) string {
+		result, err := jsonResult(v)
+		require.NoError(t, err)
+		var compact bytes.Buffer
+		require.NoError(t, json.Compact(&compact, []byte(result.Content[0].(*mcp.TextContent).Text)))
+		return compact.String()
+	}
+
+	assert.Equal(t, `{"schema_version":1,"tool":"apply_fixes"}`, text(map[string]string{"tool": "apply_fixes"}))
+	assert.Equal(t, `{"schema_version":1}`, text(struct{}{}))
+	assert.Equal(t, `{"schema_version":1,"items":["a","b"]}`, text([]string{"a", "b"}))
+	assert.Equal(t, `{"schema_version":1,"items":null}`, text(nil))
+
+	// The results of the linter carry their version, which is kept
+	result, err := linter.New(linter.Options{}).Lint(context.Background(), linter.Input{Content: []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n")})
+	require.NoError(t, err)
+	assert.Equal(t, linter.SchemaVersion, result.SchemaVersion)
+	assert.Equal(t, 1, strings.Count(text(patchResult{LintResult: result}), `"schema_version"`))
+}
+
 func (suite *ActionlintTestSuite) TestLintWorkflow_InvalidFile() {
 	// Create an invalid workflow file
 	invalidWorkflow := `name: Test
@@ -149,6 +214,15 @@ jobs:
 
 	assert.True(suite.T(), lintResult.Valid)
 	assert.Equal(suite.T(), "inline.yml", lintResult.FilePath)
+
+	// A filename names the content in the result
+	params.Arguments.Filename = ".github/workflows/ci.yml"
+	result, err = LintWorkflow(context.Background(), suite.session, params)
+	require.NoError(suite.T(), err)
+
+	err = json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &lintResult)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), ".github/workflows/ci.yml", lintResult.FilePath)
 }
 
 func (suite *ActionlintTestSuite) TestLintWorkflow_MissingInput() {
@@ -250,7 +324,493 @@ func (suite *ActionlintTestSuite) TestCheckAllWorkflows_EmptyDirectory() {
 	assert.Len(suite.T(), result.Content, 1)
 	textContent, ok := result.Content[0].(*mcp.TextContent)
 	require.True(suite.T(), ok)
-	assert.Contains(suite.T(), textContent.Text, "No workflow files found")
+	assert.Contains(suite.T(), textContent.Text, `"total_files": 0`)
+}
+
+func (suite *ActionlintTestSuite) TestCheckAllWorkflows_FileAsDirectory() {
+	filePath := filepath.Join(suite.tempDir, "single.yml")
+	require.NoError(suite.T(), os.WriteFile(filePath, []byte("on: push"), 0644))
+
+	params := &mcp.CallToolParamsFor[CheckAllWorkflowsParams]{
+		Arguments: CheckAllWorkflowsParams{
+			Directory: filePath,
+		},
+	}
+
+	result, err := CheckAllWorkflows(context.Background(), suite.session, params)
+	require.Error(suite.T(), err)
+	assert.Nil(suite.T(), result)
+	assert.Contains(suite.T(), err.Error(), "use lint_workflow")
+}
+
+func (suite *ActionlintTestSuite) TestMisplacedWorkflows() {
+	root := filepath.Join(suite.tempDir, "misplaced-repo")
+	workflow := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n"
+	for _, rel := range []string{"ci/build.yml", "ci/deploy.yml", ".github/workflows/deploy.yml"} {
+		path := filepath.Join(root, rel)
+		require.NoError(suite.T(), os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(suite.T(), os.WriteFile(path, []byte(workflow), 0644))
+	}
+
+	result, err := FindMisplacedWorkflows(context.Background(), suite.session, &mcp.CallToolParamsFor[FindMisplacedWorkflowsParams]{
+		Arguments: FindMisplacedWorkflowsParams{Directory: root},
+	})
+	require.NoError(suite.T(), err)
+
+	var found struct {
+		Items []map[string]string `json:"items"`
+	}
+	require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &found))
+	require.Len(suite.T(), found.Items, 2)
+	assert.Equal(suite.T(), filepath.Join(root, "ci", "build.yml"), found.Items[0]["path"])
+
+	result, err = MoveMisplacedWorkflows(context.Background(), suite.session, &mcp.CallToolParamsFor[MoveMisplacedWorkflowsParams]{
+		Arguments: MoveMisplacedWorkflowsParams{Directory: root},
+	})
+	require.NoError(suite.T(), err)
+
+	var moved struct {
+		Items []map[string]any `json:"items"`
+	}
+	require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &moved))
+	require.Len(suite.T(), moved.Items, 2)
+	assert.Equal(suite.T(), filepath.Join(root, ".github", "workflows", "build.yml"), moved.Items[0]["to"])
+	assert.Equal(suite.T(), true, moved.Items[0]["moved"])
+	assert.FileExists(suite.T(), filepath.Join(root, ".github", "workflows", "build.yml"))
+	assert.NoFileExists(suite.T(), filepath.Join(root, "ci", "build.yml"))
+
+	// An existing workflow is never overwritten
+	assert.Contains(suite.T(), moved.Items[1]["skipped"], "already exists")
+	assert.FileExists(suite.T(), filepath.Join(root, "ci", "deploy.yml"))
+}
+
+func (suite *ActionlintTestSuite) TestExtractScript() {
+	root := filepath.Join(suite.tempDir, "extract-repo")
+	path := filepath.Join(root, ".github", "workflows", "ci.yml")
+	workflow := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: |\n          echo one\n          echo two\n"
+	require.NoError(suite.T(), os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(suite.T(), os.WriteFile(path, []byte(workflow), 0644))
+
+	_, err := ExtractScript(context.Background(), suite.session, &mcp.CallToolParamsFor[ExtractScriptParams]{
+		Arguments: ExtractScriptParams{FilePath: path, Job: "test"},
+	})
+	require.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "must be provided")
+
+	result, err := ExtractScript(context.Background(), suite.session, &mcp.CallToolParamsFor[ExtractScriptParams]{
+		Arguments: ExtractScriptParams{FilePath: path, Job: "test", Step: 1},
+	})
+	require.NoError(suite.T(), err)
+
+	var extracted map[string]any
+	require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &extracted))
+	assert.Equal(suite.T(), filepath.Join(root, "scripts", "ci-test-step1.sh"), extracted["script"])
+	assert.FileExists(suite.T(), filepath.Join(root, "scripts", "ci-test-step1.sh"))
+}
+
+func (suite *ActionlintTestSuite) TestExtractCompositeAction() {
+	root := filepath.Join(suite.tempDir, "composite-repo")
+	path := filepath.Join(root, ".github", "workflows", "ci.yml")
+	workflow := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n      - run: make deps\n      - run: make tools\n  lint:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n      - run: make deps\n      - run: make tools\n      - run: make lint\n"
+	require.NoError(suite.T(), os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(suite.T(), os.WriteFile(path, []byte(workflow), 0644))
+
+	_, err := ExtractCompositeAction(context.Background(), suite.session, &mcp.CallToolParamsFor[ExtractCompositeActionParams]{
+		Arguments: ExtractCompositeActionParams{FilePath: path, Job: "test", FirstStep: 1},
+	})
+	require.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "must be provided")
+
+	args := ExtractCompositeActionParams{FilePath: path, Job: "test", FirstStep: 2, LastStep: 3, Name: "deps"}
+	result, err := ExtractCompositeAction(context.Background(), suite.session, &mcp.CallToolParamsFor[ExtractCompositeActionParams]{Arguments: args})
+	require.NoError(suite.T(), err)
+
+	var extracted map[string]any
+	require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &extracted))
+	assert.Equal(suite.T(), false, extracted["written"])
+	assert.Len(suite.T(), extracted["sites"], 2)
+	assert.Len(suite.T(), extracted["changes"], 2)
+	require.Len(suite.T(), extracted["lint"], 1)
+	assert.Equal(suite.T(), true, extracted["lint"].([]any)[0].(map[string]any)["valid"], "%v", extracted["lint"])
+	assert.NoFileExists(suite.T(), filepath.Join(root, ".github", "actions", "deps", "action.yml"))
+
+	args.Write = true
+	_, err = ExtractCompositeAction(context.Background(), suite.session, &mcp.CallToolParamsFor[ExtractCompositeActionParams]{Arguments: args})
+	require.NoError(suite.T(), err)
+	assert.FileExists(suite.T(), filepath.Join(root, ".github", "actions", "deps", "action.yml"))
+	written, err := os.ReadFile(path)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, strings.Count(string(written), "uses: ./.github/actions/deps"))
+}
+
+func (suite *ActionlintTestSuite) TestGenerateTestFixtures() {
+	dir := filepath.Join(suite.tempDir, "fixtures")
+
+	_, err := GenerateTestFixtures(context.Background(), suite.session, &mcp.CallToolParamsFor[GenerateTestFixturesParams]{
+		Arguments: GenerateTestFixturesParams{Directory: dir, Kinds: []string{"no-such-rule"}},
+	})
+	assert.ErrorContains(suite.T(), err, `no fixture for kind "no-such-rule"`)
+
+	result, err := GenerateTestFixtures(context.Background(), suite.session, &mcp.CallToolParamsFor[GenerateTestFixturesParams]{
+		Arguments: GenerateTestFixturesParams{Directory: dir, Kinds: []string{"checkout", "schedule"}, DryRun: true},
+	})
+	require.NoError(suite.T(), err)
+	var generated generatedFixtures
+	require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &generated))
+	assert.False(suite.T(), generated.Written)
+	require.Len(suite.T(), generated.Fixtures, 2)
+	assert.Equal(suite.T(), "checkout", generated.Fixtures[0].Kind)
+	assert.NoDirExists(suite.T(), dir)
+
+	result, err = GenerateTestFixtures(context.Background(), suite.session, &mcp.CallToolParamsFor[GenerateTestFixturesParams]{
+		Arguments: GenerateTestFixturesParams{Directory: dir},
+	})
+	require.NoError(suite.T(), err)
+	require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &generated))
+	assert.True(suite.T(), generated.Written)
+	assert.Len(suite.T(), generated.Fixtures, len(linter.Fixtures()))
+	assert.FileExists(suite.T(), filepath.Join(dir, "schedule.yml"))
+}
+
+func (suite *ActionlintTestSuite) TestLintPatch() {
+	base := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ github.undefined_one }}\n"
+	patch := "@@ -6 +6,2 @@\n       - run: echo ${{ github.undefined_one }}\n+      - run: echo ${{ github.undefined_two }}\n"
+
+	result, err := LintPatch(context.Background(), suite.session, &mcp.CallToolParamsFor[LintPatchParams]{
+		Arguments: LintPatchParams{Base: base, Filename: ".github/workflows/ci.yml", Patch: patch},
+	})
+	require.NoError(suite.T(), err)
+
+	var patched struct {
+		LintResult
+		UnchangedFindings int                     `json:"unchanged_findings"`
+		SecurityChanges   []linter.SecurityChange `json:"security_changes"`
+	}
+	require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &patched))
+	assert.Equal(suite.T(), ".github/workflows/ci.yml", patched.FilePath)
+	assert.Equal(suite.T(), 1, patched.UnchangedFindings)
+	assert.Empty(suite.T(), patched.SecurityChanges)
+	require.Len(suite.T(), patched.Errors, 1)
+	assert.Equal(suite.T(), 7, patched.Errors[0].Line)
+	assert.Contains(suite.T(), patched.Errors[0].Message, "undefined_two")
+
+	// Security-relevant changes are listed for reviewers
+	result, err = LintPatch(context.Background(), suite.session, &mcp.CallToolParamsFor[LintPatchParams]{
+		Arguments: LintPatchParams{Base: base, Patch: "@@ -1 +1 @@\n-on: push\n+on: pull_request_target\n"},
+	})
+	require.NoError(suite.T(), err)
+	require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &patched))
+	require.Len(suite.T(), patched.SecurityChanges, 1)
+	assert.Equal(suite.T(), linter.ChangeTrigger, patched.SecurityChanges[0].Kind)
+
+	_, err = LintPatch(context.Background(), suite.session, &mcp.CallToolParamsFor[LintPatchParams]{
+		Arguments: LintPatchParams{Base: base},
+	})
+	require.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "patch must be provided")
+}
+
+func (suite *ActionlintTestSuite) TestLintPatch_InputLimits() {
+	useSettings(suite.T(), serverConfig{}, false)
+	// The patch does not apply, so the error can only come from the base
+	patch := "@@ -1 +1 @@\n-on: workflow_dispatch\n+on: pull_request\n"
+
+	_, err := LintPatch(context.Background(), suite.session, &mcp.CallToolParamsFor[LintPatchParams]{
+		Arguments: LintPatchParams{Base: "on: push\nx: " + strings.Repeat("[", 200) + strings.Repeat("]", 200) + "\n", Patch: patch},
+	})
+	assert.ErrorIs(suite.T(), err, linter.ErrInputLimit, "the base is checked before the patch is applied")
+
+	path := filepath.Join(suite.tempDir, "large.yml")
+	require.NoError(suite.T(), os.WriteFile(path, []byte("on: push\n"+strings.Repeat("#", linter.DefaultMaxInputBytes)), 0644))
+	_, err = LintPatch(context.Background(), suite.session, &mcp.CallToolParamsFor[LintPatchParams]{
+		Arguments: LintPatchParams{FilePath: path, Patch: patch},
+	})
+	assert.ErrorIs(suite.T(), err, linter.ErrInputLimit)
+}
+
+func (suite *ActionlintTestSuite) TestDryRunWorkflow_ActMissing() {
+	suite.T().Setenv("ACT_COMMAND", filepath.Join(suite.tempDir, "no-such-act"))
+	useSettings(suite.T(), serverConfig{}, true)
+	path := filepath.Join(suite.tempDir, "act.yml")
+	require.NoError(suite.T(), os.WriteFile(path, []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n"), 0644))
+
+	_, err := DryRunWorkflow(context.Background(), suite.session, &mcp.CallToolParamsFor[DryRunWorkflowParams]{
+		Arguments: DryRunWorkflowParams{FilePath: path, Payload: "{"},
+	})
+	require.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "payload is not valid JSON")
+
+	_, err = DryRunWorkflow(context.Background(), suite.session, &mcp.CallToolParamsFor[DryRunWorkflowParams]{
+		Arguments: DryRunWorkflowParams{FilePath: path},
+	})
+	require.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "set ACT_COMMAND")
+}
+
+func (suite *ActionlintTestSuite) TestSimulateTrigger() {
+	dir := filepath.Join(suite.tempDir, "trigger")
+	require.NoError(suite.T(), os.MkdirAll(dir, 0755))
+	require.NoError(suite.T(), os.WriteFile(filepath.Join(dir, "ci.yml"), []byte("on:\n  push:\n    branches: [main]\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n"), 0644))
+
+	result, err := SimulateTrigger(context.Background(), suite.session, &mcp.CallToolParamsFor[SimulateTriggerParams]{
+		Arguments: SimulateTriggerParams{Directory: dir, Event: "push", Payload: `{"ref": "refs/heads/develop"}`},
+	})
+	require.NoError(suite.T(), err)
+
+	var triggers struct {
+		Items []linter.WorkflowTrigger `json:"items"`
+	}
+	require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &triggers))
+	require.Len(suite.T(), triggers.Items, 1)
+	assert.Equal(suite.T(), linter.TriggerFiltered, triggers.Items[0].Status)
+	assert.Contains(suite.T(), triggers.Items[0].Reasons, `branch "develop" does not match the branches filter (main)`)
+
+	_, err = SimulateTrigger(context.Background(), suite.session, &mcp.CallToolParamsFor[SimulateTriggerParams]{
+		Arguments: SimulateTriggerParams{Directory: dir, Event: "push", Payload: "[]"},
+	})
+	require.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "payload is not a JSON object")
+}
+
+func (suite *ActionlintTestSuite) TestApplyFixes() {
+	path := filepath.Join(suite.tempDir, "fixable.yml")
+	workflow := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo \"::add-path::/opt/bin\"\n"
+	require.NoError(suite.T(), os.WriteFile(path, []byte(workflow), 0644))
+
+	result, err := ApplyFixes(context.Background(), suite.session, &mcp.CallToolParamsFor[ApplyFixesParams]{
+		Arguments: ApplyFixesParams{FilePath: path},
+	})
+	require.NoError(suite.T(), err)
+
+	var fixed map[string]any
+	require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &fixed))
+	assert.Equal(suite.T(), []any{"deprecated-commands:6:14"}, fixed["applied"])
+	assert.Equal(suite.T(), true, fixed["written"])
+	backup, err := os.ReadFile(fixed["backup"].(string))
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), workflow, string(backup))
+
+	content, err := os.ReadFile(path)
+	require.NoError(suite.T(), err)
+	assert.Contains(suite.T(), string(content), `run: echo "/opt/bin" >> "$GITHUB_PATH"`)
+}
+
+func (suite *ActionlintTestSuite) TestFixerTools_DryRun() {
+	root := filepath.Join(suite.tempDir, "dry-run-repo")
+	path := filepath.Join(root, ".github", "workflows", "ci.yml")
+	workflow := "on: push\njobs:\n    test:\n        runs-on: ubuntu-latest\n        steps:\n        - run: echo \"::add-path::/opt/bin\"\n"
+	misplaced := filepath.Join(root, "ci", "build.yml")
+	for _, p := range []string{path, misplaced} {
+		require.NoError(suite.T(), os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(suite.T(), os.WriteFile(p, []byte(workflow), 0644))
+	}
+
+	check := func(result *mcp.CallToolResultFor[any], err error) map[string]any {
+		require.NoError(suite.T(), err)
+		var out map[string]any
+		require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out))
+		assert.Equal(suite.T(), false, out["written"])
+		return out
+	}
+	run := func(dryRun bool) {
+		fixed := check(ApplyFixes(context.Background(), suite.session, &mcp.CallToolParamsFor[ApplyFixesParams]{
+			Arguments: ApplyFixesParams{FilePath: path, DryRun: dryRun},
+		}))
+		assert.Contains(suite.T(), fixed["diff"], `+        - run: echo "/opt/bin" >> "$GITHUB_PATH"`)
+
+		formatted := check(FormatWorkflow(context.Background(), suite.session, &mcp.CallToolParamsFor[FormatWorkflowParams]{
+			Arguments: FormatWorkflowParams{FilePath: path, Write: true, DryRun: dryRun},
+		}))
+		assert.Contains(suite.T(), formatted["diff"], "+  test:")
+		assert.Contains(suite.T(), formatted, "formatted")
+
+		extracted := check(ExtractScript(context.Background(), suite.session, &mcp.CallToolParamsFor[ExtractScriptParams]{
+			Arguments: ExtractScriptParams{FilePath: path, Job: "test", Step: 1, DryRun: dryRun},
+		}))
+		assert.Len(suite.T(), extracted["changes"], 2)
+
+		result, err := MoveMisplacedWorkflows(context.Background(), suite.session, &mcp.CallToolParamsFor[MoveMisplacedWorkflowsParams]{
+			Arguments: MoveMisplacedWorkflowsParams{Directory: root, DryRun: dryRun},
+		})
+		require.NoError(suite.T(), err)
+		var moved struct {
+			Items []map[string]any `json:"items"`
+		}
+		require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &moved))
+		require.Len(suite.T(), moved.Items, 1)
+		assert.Equal(suite.T(), filepath.Join(root, ".github", "workflows", "build.yml"), moved.Items[0]["to"])
+		assert.Equal(suite.T(), false, moved.Items[0]["moved"])
+
+		content, err := os.ReadFile(path)
+		require.NoError(suite.T(), err)
+		assert.Equal(suite.T(), workflow, string(content))
+		assert.FileExists(suite.T(), misplaced)
+		assert.NoDirExists(suite.T(), filepath.Join(root, "scripts"))
+	}
+
+	// A dry run is asked for
+	run(true)
+
+	// Or the server does not allow writes
+	useSettings(suite.T(), serverConfig{}, false)
+	run(false)
+}
+
+func (suite *ActionlintTestSuite) TestFormatWorkflow() {
+	path := filepath.Join(suite.tempDir, "format.yml")
+	workflow := "jobs:\n    test:\n        runs-on: ubuntu-latest\n        steps:\n        - run: echo hi\non: push\n"
+	require.NoError(suite.T(), os.WriteFile(path, []byte(workflow), 0644))
+
+	_, err := FormatWorkflow(context.Background(), suite.session, &mcp.CallToolParamsFor[FormatWorkflowParams]{
+		Arguments: FormatWorkflowParams{Content: workflow, Write: true},
+	})
+	require.Error(suite.T(), err)
+	assert.Contains(suite.T(), err.Error(), "write requires file_path")
+
+	result, err := FormatWorkflow(context.Background(), suite.session, &mcp.CallToolParamsFor[FormatWorkflowParams]{
+		Arguments: FormatWorkflowParams{FilePath: path, Write: true},
+	})
+	require.NoError(suite.T(), err)
+
+	var formatted map[string]any
+	require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &formatted))
+	assert.Equal(suite.T(), true, formatted["written"])
+	assert.Contains(suite.T(), formatted["diff"], "+  test:")
+	assert.NotContains(suite.T(), formatted, "formatted")
+
+	content, err := os.ReadFile(path)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n", string(content))
+
+	// Formatting again changes nothing
+	result, err = FormatWorkflow(context.Background(), suite.session, &mcp.CallToolParamsFor[FormatWorkflowParams]{
+		Arguments: FormatWorkflowParams{Content: string(content)},
+	})
+	require.NoError(suite.T(), err)
+	require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &formatted))
+	assert.Equal(suite.T(), false, formatted["changed"])
+	assert.Equal(suite.T(), string(content), formatted["formatted"])
+}
+
+func (suite *ActionlintTestSuite) TestSuggestPermissions() {
+	path := filepath.Join(suite.tempDir, "permissions.yml")
+	workflow := "on: push\njobs:\n  release:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n      - uses: softprops/action-gh-release@v2\n"
+	require.NoError(suite.T(), os.WriteFile(path, []byte(workflow), 0644))
+
+	_, err := SuggestPermissions(context.Background(), suite.session, &mcp.CallToolParamsFor[SuggestPermissionsParams]{
+		Arguments: SuggestPermissionsParams{FilePath: path, Content: workflow},
+	})
+	require.Error(suite.T(), err)
+
+	result, err := SuggestPermissions(context.Background(), suite.session, &mcp.CallToolParamsFor[SuggestPermissionsParams]{
+		Arguments: SuggestPermissionsParams{FilePath: path},
+	})
+	require.NoError(suite.T(), err)
+
+	var suggestion permissionsSuggestion
+	require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &suggestion))
+	assert.Equal(suite.T(), path, suggestion.FilePath)
+	require.Len(suite.T(), suggestion.Jobs, 1)
+	assert.Equal(suite.T(), "permissions:\n  contents: write\n", suggestion.Jobs[0].YAML)
+	assert.Empty(suite.T(), suggestion.Jobs[0].Missing, "jobs without a permissions block are not compared")
+}
+
+func (suite *ActionlintTestSuite) TestRulePacks() {
+	path := filepath.Join(suite.tempDir, "packs.yml")
+	workflow := "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n"
+	require.NoError(suite.T(), os.WriteFile(path, []byte(workflow), 0644))
+
+	kinds := func(packs ...string) []string {
+		result, err := LintWorkflow(context.Background(), suite.session, &mcp.CallToolParamsFor[LintWorkflowParams]{
+			Arguments: LintWorkflowParams{FilePath: path, Packs: packs},
+		})
+		require.NoError(suite.T(), err)
+		var lint LintResult
+		require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &lint))
+		var out []string
+		for _, e := range lint.Errors {
+			out = append(out, e.Kind)
+		}
+		return out
+	}
+	assert.NotContains(suite.T(), kinds(), rules.KindJobTimeout)
+	assert.Contains(suite.T(), kinds("cost"), rules.KindJobTimeout)
+
+	_, err := LintWorkflow(context.Background(), suite.session, &mcp.CallToolParamsFor[LintWorkflowParams]{
+		Arguments: LintWorkflowParams{FilePath: path, Packs: []string{"speed"}},
+	})
+	require.Error(suite.T(), err)
+	_, err = CheckAllWorkflows(context.Background(), suite.session, &mcp.CallToolParamsFor[CheckAllWorkflowsParams]{
+		Arguments: CheckAllWorkflowsParams{Directory: suite.tempDir, Packs: []string{"speed"}},
+	})
+	require.Error(suite.T(), err)
+
+	result, err := ListRules(context.Background(), suite.session, &mcp.CallToolParamsFor[ListRulesParams]{
+		Arguments: ListRulesParams{Directory: suite.tempDir, Packs: []string{"cost"}},
+	})
+	require.NoError(suite.T(), err)
+	var list linter.RuleList
+	require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &list))
+	require.Len(suite.T(), list.Packs, len(rules.Packs))
+	for _, p := range list.Packs {
+		assert.Equal(suite.T(), p.Name == "cost", p.Enabled, p.Name)
+	}
+	for _, r := range list.Rules {
+		if r.Kind == rules.KindJobTimeout {
+			assert.True(suite.T(), r.Enabled)
+			assert.Equal(suite.T(), "cost", r.Pack)
+		}
+	}
+}
+
+func (suite *ActionlintTestSuite) TestInferWorkflowCall() {
+	path := filepath.Join(suite.tempDir, "deploy.yml")
+	workflow := "on: push\njobs:\n  deploy:\n    runs-on: ubuntu-latest\n    steps:\n      - run: ./deploy.sh\n        env:\n          TOKEN: ${{ secrets.DEPLOY_TOKEN }}\n"
+	require.NoError(suite.T(), os.WriteFile(path, []byte(workflow), 0644))
+
+	_, err := InferWorkflowCall(context.Background(), suite.session, &mcp.CallToolParamsFor[InferWorkflowCallParams]{
+		Arguments: InferWorkflowCallParams{FilePath: path},
+	})
+	require.Error(suite.T(), err)
+
+	_, err = InferWorkflowCall(context.Background(), suite.session, &mcp.CallToolParamsFor[InferWorkflowCallParams]{
+		Arguments: InferWorkflowCallParams{FilePath: path, Job: "missing"},
+	})
+	require.Error(suite.T(), err)
+
+	result, err := InferWorkflowCall(context.Background(), suite.session, &mcp.CallToolParamsFor[InferWorkflowCallParams]{
+		Arguments: InferWorkflowCallParams{FilePath: path, Job: "deploy"},
+	})
+	require.NoError(suite.T(), err)
+
+	var call linter.WorkflowCall
+	require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &call))
+	assert.Equal(suite.T(), []linter.CallSecret{{Name: "DEPLOY_TOKEN", Value: "${{ secrets.DEPLOY_TOKEN }}"}}, call.Secrets)
+	assert.Contains(suite.T(), call.Caller, "uses: ./.github/workflows/deploy.yml")
+}
+
+func (suite *ActionlintTestSuite) TestSuggestUpdatesForAction() {
+	dir := filepath.Join(suite.tempDir, "action-updates")
+	require.NoError(suite.T(), os.MkdirAll(dir, 0755))
+	workflow := "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/upload-artifact@v3-node20\n        with:\n          path: dist/\n"
+	require.NoError(suite.T(), os.WriteFile(filepath.Join(dir, "build.yml"), []byte(workflow), 0644))
+
+	_, err := SuggestUpdatesForAction(context.Background(), suite.session, &mcp.CallToolParamsFor[SuggestUpdatesForActionParams]{
+		Arguments: SuggestUpdatesForActionParams{Directory: dir},
+	})
+	require.Error(suite.T(), err)
+
+	result, err := SuggestUpdatesForAction(context.Background(), suite.session, &mcp.CallToolParamsFor[SuggestUpdatesForActionParams]{
+		Arguments: SuggestUpdatesForActionParams{Action: "actions/upload-artifact", Directory: dir},
+	})
+	require.NoError(suite.T(), err)
+
+	var updates linter.ActionUpdates
+	require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &updates))
+	assert.Equal(suite.T(), "actions/upload-artifact@v4", updates.Latest.Uses)
+	require.Len(suite.T(), updates.Usages, 1)
+	assert.Equal(suite.T(), 6, updates.Usages[0].Line)
+	assert.Contains(suite.T(), updates.Usages[0].Available, "overwrite")
 }
 
 func (suite *ActionlintTestSuite) TestCheckAllWorkflows_WithErrors() {
@@ -302,6 +862,48 @@ jobs:
 	assert.Greater(suite.T(), filesWithErrors, float64(0))
 }
 
+func (suite *ActionlintTestSuite) TestCheckAllWorkflows_ResultOrdering() {
+	workflowsDir := filepath.Join(suite.tempDir, "workflows-ordering")
+	require.NoError(suite.T(), os.MkdirAll(workflowsDir, 0755))
+
+	workflow := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo hi`
+	for _, name := range []string{"c.yml", "a.yaml", "b.yml"} {
+		require.NoError(suite.T(), os.WriteFile(filepath.Join(workflowsDir, name), []byte(workflow), 0644))
+	}
+
+	result, err := CheckAllWorkflows(context.Background(), suite.session, &mcp.CallToolParamsFor[CheckAllWorkflowsParams]{
+		Arguments: CheckAllWorkflowsParams{Directory: workflowsDir},
+	})
+	require.NoError(suite.T(), err)
+
+	var summary struct {
+		Results []LintResult `json:"results"`
+	}
+	require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &summary))
+	require.Len(suite.T(), summary.Results, 3)
+	assert.Equal(suite.T(), filepath.Join(workflowsDir, "a.yaml"), summary.Results[0].FilePath)
+	assert.Equal(suite.T(), filepath.Join(workflowsDir, "b.yml"), summary.Results[1].FilePath)
+	assert.Equal(suite.T(), filepath.Join(workflowsDir, "c.yml"), summary.Results[2].FilePath)
+
+	// The legacy map form is still available
+	result, err = CheckAllWorkflows(context.Background(), suite.session, &mcp.CallToolParamsFor[CheckAllWorkflowsParams]{
+		Arguments: CheckAllWorkflowsParams{Directory: workflowsDir, ResultsAsMap: true},
+	})
+	require.NoError(suite.T(), err)
+
+	var legacy struct {
+		Results map[string]LintResult `json:"results"`
+	}
+	require.NoError(suite.T(), json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &legacy))
+	assert.Len(suite.T(), legacy.Results, 3)
+	assert.Contains(suite.T(), legacy.Results, filepath.Join(workflowsDir, "b.yml"))
+}
+
 func TestActionlintTestSuite(t *testing.T) {
 	suite.Run(t, new(ActionlintTestSuite))
 }
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultMaxRequestSize caps the body of an HTTP request when
+// -max-request-size is not given. It leaves room for a workflow of the
+// largest size accepted, JSON-escaped, and the rest of the request.
+const defaultMaxRequestSize = 8 << 20
+
+// memoryHeadroom is the share of -max-memory the live heap may take before
+// tool calls are refused, leaving the rest for the call being served.
+const memoryHeadroom = 0.9
+
+// byteSize is a size flag written as GOMEMLIMIT is: a number of bytes,
+// optionally followed by B, KiB, MiB, GiB or TiB.
+type byteSize int64
+
+func (s *byteSize) String() string { return strconv.FormatInt(int64(*s), 10) }
+
+func (s *byteSize) Set(v string) error {
+	n, err := parseByteSize(v)
+	if err != nil {
+		return err
+	}
+	*s = byteSize(n)
+	return nil
+}
+
+// byteUnits are the suffixes parseByteSize accepts, longest first.
+var byteUnits = []struct {
+	suffix string
+	factor int64
+}{
+	{"TiB", 1 << 40},
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+	{"B", 1},
+}
+
+func parseByteSize(v string) (int64, error) {
+	number, factor := strings.TrimSpace(v), int64(1)
+	for _, u := range byteUnits {
+		if strings.HasSuffix(number, u.suffix) {
+			number, factor = strings.TrimSpace(strings.TrimSuffix(number, u.suffix)), u.factor
+			break
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 || n > math.MaxInt64/factor {
+		return 0, fmt.Errorf("invalid size %q; use a number of bytes, optionally followed by B, KiB, MiB, GiB or TiB", v)
+	}
+	return n * factor, nil
+}
+
+// applyMemoryLimit sets the soft memory limit of the runtime, as GOMEMLIMIT
+// does, so that the garbage collector works harder as the server nears it.
+// Zero leaves the limit from GOMEMLIMIT in place.
+func applyMemoryLimit(limit int64) {
+	if limit > 0 {
+		debug.SetMemoryLimit(limit)
+	}
+}
+
+// memoryLimit returns the soft memory limit of the runtime, set with
+// -max-memory or GOMEMLIMIT, or zero when there is none.
+func memoryLimit() int64 {
+	if limit := debug.SetMemoryLimit(-1); limit != math.MaxInt64 {
+		return limit
+	}
+	return 0
+}
+
+// liveHeap returns the bytes of heap the last garbage collection found in
+// use. Tests replace it.
+var liveHeap = defaultLiveHeap
+
+func defaultLiveHeap() uint64 {
+	sample := []metrics.Sample{{Name: "/gc/heap/live:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+// memoryGuard refuses tool calls while the live heap is above the
+// headroom of limit, even after collecting garbage, so that a server
+// already busy with large requests fails the next one instead of being
+// killed for running out of memory. Other methods, such as listing tools,
+// are always served. A zero limit disables it.
+func memoryGuard(limit int64) mcp.Middleware[*mcp.ServerSession] {
+	return func(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+		if limit <= 0 {
+			return next
+		}
+		threshold := uint64(float64(limit) * memoryHeadroom)
+		return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+			if method == "tools/call" && liveHeap() > threshold {
+				runtime.GC()
+				if live := liveHeap(); live > threshold {
+					return nil, fmt.Errorf("server is low on memory (%d MiB in use of the %d MiB limit); retry later", live>>20, limit>>20)
+				}
+			}
+			return next(ctx, session, method, params)
+		}
+	}
+}
+
+// maxRequestSize is the -max-request-size flag. Zero means
+// defaultMaxRequestSize.
+var maxRequestSize int64
+
+// limitRequestSize rejects HTTP requests whose body is larger than limit,
+// or defaultMaxRequestSize when it is zero, with 413 Request Entity Too
+// Large, before they are decoded.
+func limitRequestSize(handler http.Handler, limit int64) http.Handler {
+	if limit <= 0 {
+		limit = defaultMaxRequestSize
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			http.Error(w, fmt.Sprintf("request body is larger than the limit of %d bytes", limit), http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		handler.ServeHTTP(w, r)
+	})
+}
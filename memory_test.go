@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"0", 0},
+		{"1024", 1024},
+		{"100B", 100},
+		{"64KiB", 64 << 10},
+		{"512MiB", 512 << 20},
+		{"2 GiB", 2 << 30},
+		{"1TiB", 1 << 40},
+	}
+	for _, tt := range tests {
+		got, err := parseByteSize(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	for _, in := range []string{"", "MiB", "-1", "1.5GiB", "512MB", "9999999TiB"} {
+		_, err := parseByteSize(in)
+		assert.ErrorContains(t, err, "invalid size", in)
+	}
+}
+
+func TestMemoryGuard(t *testing.T) {
+	live := uint64(0)
+	liveHeap = func() uint64 { return live }
+	t.Cleanup(func() { liveHeap = defaultLiveHeap })
+
+	served := 0
+	next := func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		served++
+		return nil, nil
+	}
+	handler := memoryGuard(100 << 20)(next)
+
+	_, err := handler(context.Background(), nil, "tools/call", nil)
+	require.NoError(t, err)
+
+	live = 95 << 20
+	_, err = handler(context.Background(), nil, "tools/call", nil)
+	assert.ErrorContains(t, err, "server is low on memory (95 MiB in use of the 100 MiB limit)")
+	_, err = handler(context.Background(), nil, "tools/list", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, served)
+
+	// Without a limit every call is served
+	_, err = memoryGuard(0)(next)(context.Background(), nil, "tools/call", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, served)
+}
+
+func TestLimitRequestSize(t *testing.T) {
+	handler := limitRequestSize(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}), 16)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("small")))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("x", 17))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(t, rec.Body.String(), "larger than the limit of 16 bytes")
+
+	// Bodies of unknown length are cut off at the limit while being read
+	req := httptest.NewRequest(http.MethodPost, "/", io.MultiReader(strings.NewReader(strings.Repeat("x", 17))))
+	req.ContentLength = -1
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
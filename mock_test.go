@@ -111,7 +111,7 @@ func TestCheckAllWorkflowsWithMocks(t *testing.T) {
 
 		textContent, ok := result.Content[0].(*mcp.TextContent)
 		require.True(t, ok)
-		assert.Contains(t, textContent.Text, "No workflow files found")
+		assert.Contains(t, textContent.Text, `"total_files": 0`)
 	})
 
 	t.Run("empty_directory_param", func(t *testing.T) {
@@ -164,7 +164,30 @@ func TestErrorScenarios(t *testing.T) {
 					Content: "   \n\t  \n  ",
 				},
 			},
-			expectError: false, // Should process but find errors
+			expectError:   true,
+			errorContains: "content contains only whitespace",
+		},
+		{
+			name: "file_path_and_content",
+			params: &mcp.CallToolParamsFor[LintWorkflowParams]{
+				Arguments: LintWorkflowParams{
+					FilePath: "ci.yml",
+					Content:  "on: push",
+				},
+			},
+			expectError:   true,
+			errorContains: "mutually exclusive",
+		},
+		{
+			name: "filename_with_file_path",
+			params: &mcp.CallToolParamsFor[LintWorkflowParams]{
+				Arguments: LintWorkflowParams{
+					FilePath: "ci.yml",
+					Filename: ".github/workflows/ci.yml",
+				},
+			},
+			expectError:   true,
+			errorContains: "filename only applies to content",
 		},
 		{
 			name: "binary_file_path",
@@ -305,6 +328,8 @@ func TestConfigFileHandling(t *testing.T) {
 			t.Skip("Cannot create config file")
 		}
 		defer os.Remove(configPath)
+		// The config file is found when the server starts
+		useSettings(t, serverConfig{}, false)
 
 		workflow := `name: Test
 on: push
@@ -0,0 +1,213 @@
+package linter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KindAct is the kind of findings reported by act.
+const KindAct = "act"
+
+// DefaultActCommand is the act command used when none is configured.
+const DefaultActCommand = "act"
+
+// actTimeout bounds a single act run.
+const actTimeout = 2 * time.Minute
+
+var (
+	ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	actError   = regexp.MustCompile(`(?i)\berror\b`)
+)
+
+// ActOptions configures a run of nektos/act.
+type ActOptions struct {
+	// Command is the act command. Empty means DefaultActCommand.
+	Command string
+	// Event is the event to simulate. Empty means push.
+	Event string
+	// Payload is the JSON event payload passed to act, if any.
+	Payload []byte
+	// DryRun runs the jobs in act's dry-run mode instead of only listing
+	// them. It needs a working container runtime.
+	DryRun bool
+}
+
+// ActJob is a job act would run for the event.
+type ActJob struct {
+	Stage   int    `json:"stage"`
+	JobID   string `json:"job_id"`
+	JobName string `json:"job_name"`
+	Events  string `json:"events"`
+}
+
+// ActResult is the outcome of an act run.
+type ActResult struct {
+	Command  []string `json:"command"`
+	Jobs     []ActJob `json:"jobs,omitempty"`
+	ExitCode int      `json:"exit_code"`
+	Output   string   `json:"output"`
+}
+
+// RunAct runs act on the workflow at path to check that it resolves to
+// runnable jobs for the event. Problems act reports are returned as
+// findings of KindAct; the error is only non-nil when act could not run.
+func RunAct(ctx context.Context, path string, opts ActOptions) (*ActResult, []LintError, error) {
+	command := opts.Command
+	if command == "" {
+		command = DefaultActCommand
+	}
+	exe, err := exec.LookPath(command)
+	if err != nil {
+		return nil, nil, fmt.Errorf("act is not available: %w; install nektos/act or set ACT_COMMAND", err)
+	}
+
+	event := opts.Event
+	if event == "" {
+		event = "push"
+	}
+	args := []string{event, "-W", path}
+	if opts.DryRun {
+		args = append(args, "-n")
+	} else {
+		args = append(args, "--list")
+	}
+	if len(opts.Payload) > 0 {
+		f, err := os.CreateTemp("", "actionlint-mcp-event-*.json")
+		if err != nil {
+			return nil, nil, err
+		}
+		defer os.Remove(f.Name())
+		_, err = f.Write(opts.Payload)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		args = append(args, "-e", f.Name())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, actTimeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, exe, args...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	runErr := cmd.Run()
+
+	result := &ActResult{
+		Command: append([]string{command}, args...),
+		Output:  ansiEscape.ReplaceAllString(out.String(), ""),
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case runErr == nil:
+	case errors.As(runErr, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	default:
+		return nil, nil, fmt.Errorf("failed to run act: %w", runErr)
+	}
+
+	if !opts.DryRun {
+		result.Jobs = parseActList(result.Output)
+	}
+	if result.ExitCode == 0 {
+		return result, nil, nil
+	}
+	if ctx.Err() != nil {
+		return result, []LintError{actFinding(fmt.Sprintf("act did not finish within %s", actTimeout))}, nil
+	}
+	return result, actFindings(result.Output), nil
+}
+
+// actFindings turns the error lines of a failed act run into findings,
+// falling back to its last line of output.
+func actFindings(output string) []LintError {
+	var found []LintError
+	last := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		last = line
+		if actError.MatchString(line) {
+			found = append(found, actFinding(line))
+		}
+	}
+	if len(found) == 0 {
+		msg := "act failed without output"
+		if last != "" {
+			msg = last
+		}
+		found = append(found, actFinding(msg))
+	}
+	return found
+}
+
+func actFinding(msg string) LintError {
+	return LintError{
+		Message:  "act: " + msg,
+		Kind:     KindAct,
+		Severity: SeverityForKind(KindAct),
+	}
+}
+
+// parseActList reads the job table printed by act --list, whose columns
+// are aligned under their headers.
+func parseActList(output string) []ActJob {
+	lines := strings.Split(output, "\n")
+	header := -1
+	for i, l := range lines {
+		if strings.HasPrefix(l, "Stage") && strings.Contains(l, "Job ID") {
+			header = i
+			break
+		}
+	}
+	if header < 0 {
+		return nil
+	}
+
+	columns := []string{"Stage", "Job ID", "Job name", "Workflow name", "Workflow file", "Events"}
+	starts := make([]int, len(columns))
+	for i, c := range columns {
+		starts[i] = strings.Index(lines[header], c)
+		if starts[i] < 0 || (i > 0 && starts[i] < starts[i-1]) {
+			return nil
+		}
+	}
+	field := func(line string, i int) string {
+		if starts[i] >= len(line) {
+			return ""
+		}
+		end := len(line)
+		if i+1 < len(starts) && starts[i+1] < end {
+			end = starts[i+1]
+		}
+		return strings.TrimSpace(line[starts[i]:end])
+	}
+
+	var jobs []ActJob
+	for _, l := range lines[header+1:] {
+		stage, err := strconv.Atoi(field(l, 0))
+		if err != nil {
+			continue
+		}
+		jobs = append(jobs, ActJob{
+			Stage:   stage,
+			JobID:   field(l, 1),
+			JobName: field(l, 2),
+			Events:  field(l, 5),
+		})
+	}
+	return jobs
+}
@@ -0,0 +1,64 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAct writes a shell script standing in for act that prints output and
+// exits with code.
+func fakeAct(t *testing.T, output string, code int) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake act is a shell script")
+	}
+	path := filepath.Join(t.TempDir(), "act")
+	script := "#!/bin/sh\necho \"$@\" > \"$0.args\"\ncat <<'EOF'\n" + output + "\nEOF\nexit " + strconv.Itoa(code) + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(script), 0755))
+	return path
+}
+
+func TestRunAct_List(t *testing.T) {
+	act := fakeAct(t, `Stage  Job ID  Job name      Workflow name  Workflow file  Events
+0      lint    Lint code     CI             ci.yml         push,pull_request
+1      test    Run tests     CI             ci.yml         push,pull_request`, 0)
+
+	result, findings, err := RunAct(t.Context(), "ci.yml", ActOptions{Command: act, Event: "pull_request", Payload: []byte(`{"number": 1}`)})
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+	assert.Equal(t, []ActJob{
+		{Stage: 0, JobID: "lint", JobName: "Lint code", Events: "push,pull_request"},
+		{Stage: 1, JobID: "test", JobName: "Run tests", Events: "push,pull_request"},
+	}, result.Jobs)
+	assert.Equal(t, []string{act, "pull_request", "-W", "ci.yml", "--list", "-e"}, result.Command[:6])
+
+	args, err := os.ReadFile(act + ".args")
+	require.NoError(t, err)
+	assert.Contains(t, string(args), "pull_request -W ci.yml --list -e ")
+}
+
+func TestRunAct_Failure(t *testing.T) {
+	act := fakeAct(t, "level=info msg=\"planning\"\nError: workflow is not valid. 'ci.yml': unknown job 'build'", 1)
+
+	result, findings, err := RunAct(t.Context(), "ci.yml", ActOptions{Command: act, DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Contains(t, result.Command, "-n")
+	require.Len(t, findings, 1)
+	assert.Equal(t, LintError{
+		Message:  "act: Error: workflow is not valid. 'ci.yml': unknown job 'build'",
+		Kind:     KindAct,
+		Severity: SeverityError,
+	}, findings[0])
+}
+
+func TestRunAct_NotInstalled(t *testing.T) {
+	_, _, err := RunAct(t.Context(), "ci.yml", ActOptions{Command: filepath.Join(t.TempDir(), "missing-act")})
+	assert.ErrorContains(t, err, "act is not available")
+}
@@ -0,0 +1,218 @@
+package linter
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// ActionInput is an input in the schema of an action.
+type ActionInput struct {
+	Name     string `json:"name"`
+	Required bool   `json:"required"`
+}
+
+// ActionSchema is the inputs and outputs of one version of an action.
+type ActionSchema struct {
+	Uses    string        `json:"uses"`
+	Name    string        `json:"name,omitempty"`
+	Inputs  []ActionInput `json:"inputs"`
+	Outputs []string      `json:"outputs"`
+}
+
+// ActionUsage is a step using an action, compared with the latest version
+// of the action. Deprecated are the inputs the step passes that the latest
+// version no longer takes, and Available the inputs of the latest version
+// that the step's version lacks. Available is left out when the schema of
+// the step's version is unknown, as for commits and old versions, which
+// Known tells.
+type ActionUsage struct {
+	File       string   `json:"file"`
+	Line       int      `json:"line"`
+	Column     int      `json:"column"`
+	Uses       string   `json:"uses"`
+	Latest     bool     `json:"latest"`
+	Known      bool     `json:"known"`
+	Inputs     []string `json:"inputs"`
+	Deprecated []string `json:"deprecated,omitempty"`
+	Available  []string `json:"available,omitempty"`
+}
+
+// ActionUpdates is the latest schema of an action and how each of its
+// usages differs from it.
+type ActionUpdates struct {
+	Action string        `json:"action"`
+	Latest ActionSchema  `json:"latest"`
+	Usages []ActionUsage `json:"usages"`
+}
+
+// actionVersion parses a ref such as v4 or v4.1.2. ok is false for branches
+// and commits.
+func actionVersion(ref string) (v [3]int, ok bool) {
+	if !strings.HasPrefix(ref, "v") {
+		return v, false
+	}
+	fields := strings.Split(ref[1:], ".")
+	if len(fields) > 3 {
+		return v, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+// latestAction returns the spec of the newest version of action, as
+// owner/repo or owner/repo/path, among the popular actions actionlint
+// knows the inputs of.
+func latestAction(action string) (string, *actionlint.ActionMetadata) {
+	prefix := strings.ToLower(action) + "@"
+	var (
+		spec   string
+		meta   *actionlint.ActionMetadata
+		latest [3]int
+	)
+	for s, m := range actionlint.PopularActions {
+		if !strings.HasPrefix(strings.ToLower(s), prefix) || m == nil || m.Inputs == nil {
+			continue
+		}
+		v, ok := actionVersion(s[len(prefix):])
+		if !ok {
+			continue
+		}
+		if meta == nil || v[0] > latest[0] || v[0] == latest[0] && (v[1] > latest[1] || v[1] == latest[1] && v[2] > latest[2]) {
+			spec, meta, latest = s, m, v
+		}
+	}
+	return spec, meta
+}
+
+// actionSchema returns the inputs and outputs of meta, sorted by name.
+func actionSchema(spec string, meta *actionlint.ActionMetadata) ActionSchema {
+	schema := ActionSchema{Uses: spec, Name: meta.Name, Inputs: []ActionInput{}, Outputs: []string{}}
+	for _, in := range meta.Inputs {
+		schema.Inputs = append(schema.Inputs, ActionInput{Name: in.Name, Required: in.Required})
+	}
+	sort.Slice(schema.Inputs, func(i, j int) bool { return schema.Inputs[i].Name < schema.Inputs[j].Name })
+	for _, out := range meta.Outputs {
+		schema.Outputs = append(schema.Outputs, out.Name)
+	}
+	sort.Strings(schema.Outputs)
+	return schema
+}
+
+// SuggestActionUpdates returns the latest inputs schema of action, given
+// as owner/repo or owner/repo/path with or without a ref, and compares the
+// steps of files that use it with it. Usages are in the order of files and
+// then of the steps.
+func SuggestActionUpdates(action string, files []string) (*ActionUpdates, error) {
+	action, _, _ = strings.Cut(strings.TrimSpace(action), "@")
+	if action == "" || strings.HasPrefix(action, "./") || strings.HasPrefix(action, "docker://") {
+		return nil, fmt.Errorf("action must be given as owner/repo or owner/repo/path")
+	}
+	spec, meta := latestAction(action)
+	if meta == nil {
+		return nil, fmt.Errorf("the inputs of %s are not known; only the popular actions bundled with actionlint are", action)
+	}
+	updates := &ActionUpdates{Action: action, Latest: actionSchema(spec, meta), Usages: []ActionUsage{}}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if content, err = normalizeEncoding(content); err != nil {
+			continue
+		}
+		w, _ := actionlint.Parse(content)
+		if w == nil {
+			continue
+		}
+		var usages []ActionUsage
+		for _, id := range sortedJobs(w) {
+			for _, s := range w.Jobs[id].Steps {
+				exec, ok := s.Exec.(*actionlint.ExecAction)
+				if !ok || exec.Uses == nil {
+					continue
+				}
+				name, _, _ := strings.Cut(exec.Uses.Value, "@")
+				if !strings.EqualFold(name, action) {
+					continue
+				}
+				usages = append(usages, actionUsage(file, exec, spec, meta))
+			}
+		}
+		sort.Slice(usages, func(i, j int) bool { return usages[i].Line < usages[j].Line })
+		updates.Usages = append(updates.Usages, usages...)
+	}
+	return updates, nil
+}
+
+// actionUsage compares the step exec with the latest version of its
+// action.
+func actionUsage(file string, exec *actionlint.ExecAction, spec string, latest *actionlint.ActionMetadata) ActionUsage {
+	name, ref, _ := strings.Cut(exec.Uses.Value, "@")
+	_, latestRef, _ := strings.Cut(spec, "@")
+	u := ActionUsage{
+		File:   file,
+		Line:   exec.Uses.Pos.Line,
+		Column: exec.Uses.Pos.Col,
+		Uses:   exec.Uses.Value,
+		Inputs: []string{},
+	}
+	v, versioned := actionVersion(ref)
+	if l, _ := actionVersion(latestRef); versioned && v[0] >= l[0] {
+		u.Latest = true
+	}
+	for key, in := range exec.Inputs {
+		input := key
+		if in.Name != nil {
+			input = in.Name.Value
+		}
+		u.Inputs = append(u.Inputs, input)
+		if _, ok := latest.Inputs[key]; !ok {
+			u.Deprecated = append(u.Deprecated, input)
+		}
+	}
+	sort.Strings(u.Inputs)
+	sort.Strings(u.Deprecated)
+
+	// The dataset has the major versions, whose inputs their minor and
+	// patch versions are taken to share
+	own := popularAction(exec.Uses.Value)
+	if own == nil && versioned {
+		own = popularAction(name + "@v" + strconv.Itoa(v[0]))
+	}
+	u.Known = own != nil && own.Inputs != nil
+	if u.Known {
+		for key, in := range latest.Inputs {
+			if _, ok := own.Inputs[key]; !ok {
+				u.Available = append(u.Available, in.Name)
+			}
+		}
+		sort.Strings(u.Available)
+	}
+	return u
+}
+
+// popularAction returns the metadata actionlint bundles for spec, matching
+// it case-insensitively as GitHub does, or nil.
+func popularAction(spec string) *actionlint.ActionMetadata {
+	if m, ok := actionlint.PopularActions[spec]; ok {
+		return m
+	}
+	for s, m := range actionlint.PopularActions {
+		if strings.EqualFold(s, spec) {
+			return m
+		}
+	}
+	return nil
+}
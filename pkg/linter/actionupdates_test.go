@@ -0,0 +1,74 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestActionUpdates(t *testing.T) {
+	dir := t.TempDir()
+	ci := filepath.Join(dir, "ci.yml")
+	require.NoError(t, os.WriteFile(ci, []byte(`on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/upload-artifact@v4.3.1
+        with:
+          name: report
+          path: out/
+      - uses: actions/upload-artifact@v3-node20
+        with:
+          name: logs
+          path: logs/
+      - uses: actions/upload-artifact@0b7f8abb1508181956e8e162db84b466c27e18ce
+        with:
+          path: dist/
+          retention: 3
+`), 0644))
+	other := filepath.Join(dir, "release.yml")
+	require.NoError(t, os.WriteFile(other, []byte("on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n"), 0644))
+
+	updates, err := SuggestActionUpdates("Actions/Upload-Artifact@v3", []string{ci, other})
+	require.NoError(t, err)
+	assert.Equal(t, "Actions/Upload-Artifact", updates.Action)
+	assert.Equal(t, "actions/upload-artifact@v4", updates.Latest.Uses)
+	assert.Contains(t, updates.Latest.Inputs, ActionInput{Name: "path", Required: true})
+	assert.Contains(t, updates.Latest.Outputs, "artifact-id")
+
+	require.Len(t, updates.Usages, 3)
+	current := updates.Usages[0]
+	assert.Equal(t, 7, current.Line)
+	assert.True(t, current.Latest)
+	assert.True(t, current.Known, "minor versions share the inputs of their major version")
+	assert.Equal(t, []string{"name", "path"}, current.Inputs)
+	assert.Empty(t, current.Deprecated)
+	assert.Empty(t, current.Available)
+
+	old := updates.Usages[1]
+	assert.False(t, old.Latest)
+	assert.True(t, old.Known)
+	assert.Contains(t, old.Available, "overwrite")
+	assert.NotContains(t, old.Available, "name")
+
+	pinned := updates.Usages[2]
+	assert.False(t, pinned.Known)
+	assert.Nil(t, pinned.Available)
+	assert.Equal(t, []string{"retention"}, pinned.Deprecated)
+}
+
+func TestSuggestActionUpdates_Errors(t *testing.T) {
+	_, err := SuggestActionUpdates("./.github/actions/build", nil)
+	assert.Error(t, err)
+
+	_, err = SuggestActionUpdates("example/unknown-action", nil)
+	assert.ErrorContains(t, err, "not known")
+
+	_, err = SuggestActionUpdates("actions/checkout", []string{filepath.Join(t.TempDir(), "missing.yml")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,138 @@
+package linter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Baseline is the findings of workflows at an earlier revision, keyed by
+// cleaned file path, that OnlyNew leaves out.
+type Baseline map[string][]LintError
+
+// findingKey identifies a finding across revisions. Lines are left out,
+// since edits elsewhere in the file move them.
+type findingKey struct {
+	kind, message string
+}
+
+// OnlyNew removes the findings of result that b has for its file and
+// returns how many it removed. Each finding of b accounts for one finding
+// of result, so a problem that was copied is still reported once. Valid
+// then describes the new findings, so that it can gate a change.
+func (b Baseline) OnlyNew(result *LintResult) int {
+	known := map[findingKey]int{}
+	for _, e := range b[filepath.Clean(result.FilePath)] {
+		known[findingKey{e.Kind, e.Message}]++
+	}
+	kept := result.Errors[:0]
+	for _, e := range result.Errors {
+		k := findingKey{e.Kind, e.Message}
+		if known[k] > 0 {
+			known[k]--
+			continue
+		}
+		kept = append(kept, e)
+	}
+	removed := len(result.Errors) - len(kept)
+	result.Errors, result.Valid = kept, len(kept) == 0
+	return removed
+}
+
+// OnlyNew removes the findings of every result that b has, updates the
+// totals and returns how many findings it removed.
+func (s *Summary) OnlyNew(b Baseline) int {
+	results := s.Results
+	removed := 0
+	s.Results, s.FilesWithErrors, s.TotalErrors = make([]LintResult, 0, len(results)), 0, 0
+	for _, r := range results {
+		removed += b.OnlyNew(&r)
+		s.add(r)
+	}
+	return removed
+}
+
+// Baseline returns the findings to compare files with. compareTo is either
+// a file holding a saved result, as loaded by LoadBaseline, or a git ref,
+// at which each of files is linted with the options of l.
+func (l *Linter) Baseline(ctx context.Context, compareTo string, files []string) (Baseline, error) {
+	if info, err := os.Stat(compareTo); err == nil && info.Mode().IsRegular() {
+		return LoadBaseline(compareTo)
+	}
+	return l.BaselineAt(ctx, compareTo, files)
+}
+
+// LoadBaseline reads a saved result: the output of lint_workflow or
+// check_all_workflows, with results as a list or keyed by path. Findings
+// are matched to files by file_path, so the result must have been made
+// with the same paths.
+func LoadBaseline(path string) (Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var saved struct {
+		LintResult
+		Results json.RawMessage `json:"results"`
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+
+	var results []LintResult
+	switch {
+	case len(saved.Results) == 0:
+		if saved.FilePath == "" {
+			return nil, fmt.Errorf("baseline %s is not a lint_workflow or check_all_workflows result", path)
+		}
+		results = []LintResult{saved.LintResult}
+	case bytes.HasPrefix(bytes.TrimSpace(saved.Results), []byte("{")):
+		var byPath map[string]LintResult
+		if err := json.Unmarshal(saved.Results, &byPath); err != nil {
+			return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+		}
+		for p, r := range byPath {
+			r.FilePath = p
+			results = append(results, r)
+		}
+	default:
+		if err := json.Unmarshal(saved.Results, &results); err != nil {
+			return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+		}
+	}
+
+	b := Baseline{}
+	for _, r := range results {
+		p := filepath.Clean(CleanPath(r.FilePath))
+		b[p] = append(b[p], r.Errors...)
+	}
+	return b, nil
+}
+
+// BaselineAt lints each of files as it is at ref in its git repository.
+// Files the ref does not have have no findings, so all of theirs are new.
+func (l *Linter) BaselineAt(ctx context.Context, ref string, files []string) (Baseline, error) {
+	b := Baseline{}
+	for _, file := range files {
+		if file == InlineFileName {
+			return nil, fmt.Errorf("comparing with git ref %s needs the path of the workflow", ref)
+		}
+		dir := filepath.Dir(file)
+		if err := gitVerifyRef(ctx, dir, ref); err != nil {
+			return nil, err
+		}
+		content, ok := gitShow(ctx, dir, ref, filepath.Base(file))
+		if !ok {
+			continue
+		}
+		result, err := l.Lint(ctx, Input{Path: file, Content: content})
+		if err != nil {
+			return nil, fmt.Errorf("failed to lint %s at %s: %w", file, ref, err)
+		}
+		b[filepath.Clean(file)] = result.Errors
+	}
+	return b, nil
+}
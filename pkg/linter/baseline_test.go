@@ -0,0 +1,83 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseline_OnlyNew(t *testing.T) {
+	old := LintError{Message: "old", Line: 3, Kind: "expression"}
+	result := &LintResult{
+		FilePath: "ci.yml",
+		Errors: []LintError{
+			{Message: "old", Line: 5, Kind: "expression"},
+			{Message: "old", Line: 9, Kind: "expression"},
+			{Message: "new", Line: 7, Kind: "expression"},
+		},
+	}
+	removed := Baseline{"ci.yml": {old}}.OnlyNew(result)
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, []LintError{{Message: "old", Line: 9, Kind: "expression"}, {Message: "new", Line: 7, Kind: "expression"}}, result.Errors, "a copied problem is still new")
+	assert.False(t, result.Valid)
+
+	summary := &Summary{TotalFiles: 2}
+	summary.add(LintResult{FilePath: "a.yml", Errors: []LintError{old}})
+	summary.add(LintResult{FilePath: "b.yml", Errors: []LintError{old}})
+	assert.Equal(t, 1, summary.OnlyNew(Baseline{"a.yml": {old}}))
+	assert.Equal(t, 1, summary.FilesWithErrors)
+	assert.Equal(t, 1, summary.TotalErrors)
+	assert.True(t, summary.Results[0].Valid)
+}
+
+func TestLoadBaseline(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"result.json": `{"schema_version": 1, "errors": [{"message": "m", "kind": "k"}], "valid": false, "file_path": "./ci.yml"}`,
+		"list.json":   `{"total_files": 1, "results": [{"errors": [{"message": "m", "kind": "k"}], "valid": false, "file_path": "ci.yml"}]}`,
+		"map.json":    `{"total_files": 1, "results": {"ci.yml": {"errors": [{"message": "m", "kind": "k"}], "valid": false}}}`,
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		b, err := LoadBaseline(path)
+		require.NoError(t, err, name)
+		assert.Equal(t, Baseline{"ci.yml": {{Message: "m", Kind: "k"}}}, b, name)
+	}
+
+	path := filepath.Join(dir, "other.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"name": "x"}`), 0644))
+	_, err := LoadBaseline(path)
+	assert.ErrorContains(t, err, "not a lint_workflow or check_all_workflows result")
+}
+
+func TestLinter_BaselineAt(t *testing.T) {
+	dir := gitRepo(t)
+	path := filepath.Join(dir, "ci.yml")
+	require.NoError(t, os.WriteFile(path, []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ github.undefined_old }}\n"), 0644))
+	git(t, dir, "add", "ci.yml")
+	git(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "ci")
+	require.NoError(t, os.WriteFile(path, []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ github.undefined_old }}\n      - run: echo ${{ github.undefined_new }}\n"), 0644))
+	added := filepath.Join(dir, "added.yml")
+	require.NoError(t, os.WriteFile(added, []byte("on: push\njobs: {}\n"), 0644))
+
+	l := New(Options{})
+	b, err := l.Baseline(t.Context(), "HEAD", []string{path, added})
+	require.NoError(t, err)
+	require.Len(t, b[path], 1)
+	assert.Contains(t, b[path][0].Message, "undefined_old")
+	assert.NotContains(t, b, added, "files the ref does not have have no baseline")
+
+	result, err := l.Lint(t.Context(), Input{Path: path})
+	require.NoError(t, err)
+	assert.Equal(t, 1, b.OnlyNew(result))
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0].Message, "undefined_new")
+
+	_, err = l.Baseline(t.Context(), "v9", []string{path})
+	assert.ErrorContains(t, err, "cannot compare with v9")
+	_, err = l.BaselineAt(t.Context(), "HEAD", []string{InlineFileName})
+	assert.ErrorContains(t, err, "needs the path of the workflow")
+}
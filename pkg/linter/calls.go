@@ -0,0 +1,148 @@
+package linter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/rhysd/actionlint"
+)
+
+// KindReusableCalls is the kind of findings about chains of calls to local
+// reusable workflows.
+const KindReusableCalls = rules.KindReusableCalls
+
+// DefaultMaxWorkflowNesting is how many levels of workflows GitHub lets a
+// chain of reusable workflow calls connect, counting the caller.
+const DefaultMaxWorkflowNesting = 4
+
+// localCall is a job calling a reusable workflow in the same repository.
+type localCall struct {
+	pos  *actionlint.Pos
+	path string
+}
+
+// callGraph resolves calls to local reusable workflows, parsing each
+// workflow file once.
+type callGraph struct {
+	root  string
+	calls map[string][]localCall
+}
+
+// callsOf returns the local reusable workflows called by the jobs of the
+// workflow at path, in job order. content is used instead of reading the
+// file when it is not nil.
+func (g *callGraph) callsOf(path string, content []byte) []localCall {
+	if calls, ok := g.calls[path]; ok {
+		return calls
+	}
+	g.calls[path] = nil // Guards against reading the file again in a cycle
+
+	if content == nil {
+		var err error
+		if content, err = os.ReadFile(path); err != nil {
+			return nil // actionlint reports missing reusable workflows
+		}
+		if content, err = normalizeEncoding(content); err != nil {
+			return nil
+		}
+	}
+	w, _ := actionlint.Parse(content)
+	if w == nil {
+		return nil
+	}
+
+	ids := make([]string, 0, len(w.Jobs))
+	for id := range w.Jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var calls []localCall
+	for _, id := range ids {
+		call := w.Jobs[id].WorkflowCall
+		if call == nil || call.Uses == nil || !strings.HasPrefix(call.Uses.Value, "./") {
+			continue
+		}
+		calls = append(calls, localCall{
+			pos:  call.Uses.Pos,
+			path: filepath.Join(g.root, filepath.FromSlash(call.Uses.Value)),
+		})
+	}
+	g.calls[path] = calls
+	return calls
+}
+
+// longestChain returns the longest chain of calls starting at path, and
+// the first cycle found on the way, as lists of workflow files.
+func (g *callGraph) longestChain(path string, stack []string) (longest, cycle []string) {
+	stack = append(stack, path)
+	longest = stack
+	for _, c := range g.callsOf(path, nil) {
+		for i, p := range stack {
+			if p == c.path {
+				return longest, append(append([]string{}, stack[i:]...), c.path)
+			}
+		}
+		chain, cyc := g.longestChain(c.path, stack)
+		if cyc != nil {
+			return chain, cyc
+		}
+		if len(chain) > len(longest) {
+			longest = append([]string{}, chain...)
+		}
+	}
+	return longest, nil
+}
+
+// callGraphFindings reports the calls of the workflow at path that lead to
+// recursion between reusable workflows, or to a chain of reusable
+// workflows nested deeper than max levels.
+func callGraphFindings(path string, content []byte, max int) []LintError {
+	if max == 0 {
+		max = DefaultMaxWorkflowNesting
+	}
+	root := repositoryRoot(path)
+	g := &callGraph{root: root, calls: map[string][]localCall{}}
+	path = filepath.Clean(path)
+
+	var found []LintError
+	for _, c := range g.callsOf(path, content) {
+		chain, cycle := g.longestChain(c.path, []string{path})
+		var msg string
+		switch {
+		case cycle != nil:
+			msg = fmt.Sprintf("reusable workflows call each other in a cycle: %s. GitHub rejects recursive calls", describeChain(root, cycle))
+		case max > 0 && len(chain) > max:
+			msg = fmt.Sprintf("calling %s nests reusable workflows %d levels deep (%s), more than GitHub's limit of %d", relativeTo(root, c.path), len(chain), describeChain(root, chain), max)
+		default:
+			continue
+		}
+		found = append(found, LintError{
+			Message:  msg,
+			Line:     c.pos.Line,
+			Column:   c.pos.Col,
+			Kind:     KindReusableCalls,
+			Severity: SeverityForKind(KindReusableCalls),
+		})
+	}
+	return found
+}
+
+func describeChain(root string, chain []string) string {
+	names := make([]string, len(chain))
+	for i, p := range chain {
+		names[i] = relativeTo(root, p)
+	}
+	return strings.Join(names, " -> ")
+}
+
+func relativeTo(root, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
@@ -0,0 +1,69 @@
+package linter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeReusable writes a reusable workflow to the workflows directory of
+// repo whose jobs call the given local workflows.
+func writeReusable(t *testing.T, repo, name string, calls ...string) string {
+	t.Helper()
+	content := "on: workflow_call\njobs:\n"
+	if len(calls) == 0 {
+		content += "  work:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n"
+	}
+	for i, c := range calls {
+		content += "  call" + string(rune('a'+i)) + ":\n    uses: ./.github/workflows/" + c + "\n"
+	}
+	path := filepath.Join(repo, ".github", "workflows", name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestCallGraphFindings(t *testing.T) {
+	repo := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".github", "workflows"), 0755))
+
+	writeReusable(t, repo, "e.yml")
+	writeReusable(t, repo, "d.yml", "e.yml")
+	writeReusable(t, repo, "c.yml", "d.yml")
+	writeReusable(t, repo, "b.yml", "c.yml")
+	deep := writeReusable(t, repo, "a.yml", "b.yml", "c.yml")
+
+	findings := callGraphFindings(deep, nil, 0)
+	require.Len(t, findings, 1)
+	assert.Equal(t, LintError{
+		Message:  "calling .github/workflows/b.yml nests reusable workflows 5 levels deep (.github/workflows/a.yml -> .github/workflows/b.yml -> .github/workflows/c.yml -> .github/workflows/d.yml -> .github/workflows/e.yml), more than GitHub's limit of 4",
+		Line:     4,
+		Column:   11,
+		Kind:     KindReusableCalls,
+		Severity: SeverityError,
+	}, findings[0])
+	assert.Empty(t, callGraphFindings(deep, nil, 5))
+	assert.Empty(t, callGraphFindings(deep, nil, -1))
+
+	writeReusable(t, repo, "y.yml", "x.yml")
+	x := writeReusable(t, repo, "x.yml", "y.yml")
+	findings = callGraphFindings(x, nil, 0)
+	require.Len(t, findings, 1)
+	assert.Contains(t, findings[0].Message, "reusable workflows call each other in a cycle: .github/workflows/x.yml -> .github/workflows/y.yml -> .github/workflows/x.yml")
+
+	// Findings are part of linting a file in the repository
+	style := rules.Config{Reusable: rules.ReusableConfig{MaxDepth: -1}, Packs: []string{"style"}}
+	result, err := New(Options{Rules: style}).Lint(context.Background(), Input{Path: x})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	result, err = New(Options{Rules: style}).Lint(context.Background(), Input{Path: deep})
+	require.NoError(t, err)
+	assert.True(t, result.Valid, "%v", result.Errors)
+	result, err = New(Options{}).Lint(context.Background(), Input{Path: x})
+	require.NoError(t, err)
+	assert.True(t, result.Valid, "the cross-file checks only run with the style pack: %v", result.Errors)
+}
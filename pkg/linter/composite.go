@@ -0,0 +1,590 @@
+package linter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ActionsDir is where ExtractCompositeAction writes actions, relative to
+// the repository root.
+const ActionsDir = ".github/actions"
+
+// CompositeInput is an input of an extracted composite action. Value is
+// what each step using the action passes for it.
+type CompositeInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Value       string `json:"value"`
+}
+
+// CompositeSite is a run of steps replaced by the composite action.
+type CompositeSite struct {
+	Workflow string `json:"workflow"`
+	Job      string `json:"job"`
+	Line     int    `json:"line"`
+}
+
+// FileChange is a file an extraction creates or rewrites, with the diff
+// from its current content. A file that does not exist yet is diffed
+// against an empty one, and is created with Mode, or 0644 when it is zero.
+// Backup is where WriteChanges copied the file it replaced.
+type FileChange struct {
+	Path    string      `json:"path"`
+	Created bool        `json:"created,omitempty"`
+	Diff    string      `json:"diff"`
+	Content []byte      `json:"-"`
+	Mode    os.FileMode `json:"-"`
+	Backup  string      `json:"backup,omitempty"`
+}
+
+// CompositeExtraction is a run of steps moved into a composite action:
+// the action, the places it replaced the steps and the changed files.
+// Root is the repository root the changes are in.
+type CompositeExtraction struct {
+	Root     string           `json:"root"`
+	Action   string           `json:"action"`
+	Uses     string           `json:"uses"`
+	Inputs   []CompositeInput `json:"inputs"`
+	Outputs  []CallOutput     `json:"outputs"`
+	Sites    []CompositeSite  `json:"sites"`
+	Changes  []FileChange     `json:"changes"`
+	Warnings []string         `json:"warnings,omitempty"`
+}
+
+var (
+	// actionName matches the names of extracted actions.
+	actionName = regexp.MustCompile(`^[A-Za-z0-9][\w.-]*$`)
+	// compositeReference matches the references a composite action cannot
+	// resolve by itself: secrets, the inputs of the workflow and the
+	// outputs and results of the job's other steps.
+	compositeReference = regexp.MustCompile(`(^|[^\w.-])(?:(secrets|inputs)\.([A-Za-z_][\w-]*)|steps\.([A-Za-z_][\w-]*)\.(?:outputs\.([A-Za-z_][\w-]*)|(outcome|conclusion)))`)
+	// stepResult matches the references to the outputs and results of a
+	// step.
+	stepResult = regexp.MustCompile(`\bsteps\.([A-Za-z_][\w-]*)\.(?:outputs\.([A-Za-z_][\w-]*)|(outcome|conclusion))`)
+	// compositeUnsupported are the step keys composite actions do not take.
+	compositeUnsupported = []string{"timeout-minutes"}
+)
+
+// stepRun is a run of steps in a job matching the extracted ones.
+type stepRun struct {
+	path   string
+	job    string
+	jobKey *yaml.Node
+	jobVal *yaml.Node
+	steps  []*yaml.Node
+}
+
+// ExtractCompositeAction moves the steps first to last (counting from 1)
+// of a job into a composite action in ActionsDir/<name>/action.yml, and
+// replaces them, and every identical run of steps in the workflows next
+// to path, with a step using the action. References the action cannot
+// resolve itself, such as secrets, become inputs, and outputs of the steps
+// used later in the job become outputs. Nothing is written: the new
+// contents are returned in Changes for the caller to check and write with
+// WriteChanges. Existing actions are never overwritten.
+func ExtractCompositeAction(path, job string, first, last int, name, description string) (*CompositeExtraction, error) {
+	if !actionName.MatchString(name) {
+		return nil, fmt.Errorf("action name %q must start with a letter or digit and contain only letters, digits, '-', '_' and '.'", name)
+	}
+	dir := filepath.Dir(path)
+	if abs, err := filepath.Abs(dir); err != nil || !strings.HasSuffix(abs, string(filepath.Separator)+WorkflowsDir) {
+		return nil, fmt.Errorf("%s is not in %s, so the repository root the action belongs in is not known", path, WorkflowsDir)
+	}
+	root := repositoryRoot(path)
+	actionPath := filepath.Join(root, filepath.FromSlash(ActionsDir), name, "action.yml")
+	for _, p := range []string{actionPath, filepath.Join(filepath.Dir(actionPath), "action.yaml")} {
+		if _, err := os.Lstat(p); err == nil {
+			return nil, fmt.Errorf("%s already exists", p)
+		}
+	}
+
+	docs := map[string]*yaml.Node{}
+	raws := map[string][]byte{}
+	contents := map[string][]byte{}
+	load := func(p string) (*yaml.Node, error) {
+		if doc, ok := docs[p]; ok {
+			return doc, nil
+		}
+		raw, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		content, err := normalizeEncoding(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", p, err)
+		}
+		var doc yaml.Node
+		if err := yaml.Unmarshal(content, &doc); err != nil || len(doc.Content) == 0 {
+			return nil, fmt.Errorf("failed to parse %s", p)
+		}
+		docs[p], raws[p], contents[p] = doc.Content[0], raw, content
+		return doc.Content[0], nil
+	}
+
+	top, err := load(path)
+	if err != nil {
+		return nil, err
+	}
+	jobVal := mappingValue(mappingValue(top, "jobs"), job)
+	if jobVal == nil {
+		return nil, fmt.Errorf("job %q not found in %s", job, path)
+	}
+	steps := mappingValue(jobVal, "steps")
+	if last == 0 {
+		last = first
+	}
+	if steps == nil || steps.Kind != yaml.SequenceNode || first < 1 || last < first || last > len(steps.Content) {
+		return nil, fmt.Errorf("job %q has no steps %d to %d", job, first, last)
+	}
+	pattern := steps.Content[first-1 : last]
+	for i, s := range pattern {
+		if uses := scalarValue(mappingValue(s, "uses")); strings.HasPrefix(strings.ToLower(uses), "actions/checkout@") {
+			return nil, fmt.Errorf("step %d checks out the repository, which must happen before a local action can be used; extract the steps after it", first+i)
+		}
+	}
+	keys := make([]string, len(pattern))
+	for i, s := range pattern {
+		keys[i] = nodeKey(s)
+	}
+	defaults := defaultRun(top, jobVal)
+
+	files, err := FindWorkflowFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	out := &CompositeExtraction{
+		Root:    root,
+		Action:  actionPath,
+		Uses:    "./" + ActionsDir + "/" + name,
+		Inputs:  []CompositeInput{},
+		Outputs: []CallOutput{},
+		Sites:   []CompositeSite{},
+		Changes: []FileChange{},
+	}
+	var runs []stepRun
+	for _, f := range files {
+		doc, err := load(f)
+		if err != nil {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("%s was not searched: %v", f, err))
+			continue
+		}
+		jobs := mappingValue(doc, "jobs")
+		if jobs == nil || jobs.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(jobs.Content); i += 2 {
+			seq := mappingValue(jobs.Content[i+1], "steps")
+			if seq == nil || seq.Kind != yaml.SequenceNode {
+				continue
+			}
+			for s := 0; s+len(keys) <= len(seq.Content); s++ {
+				if !matchSteps(seq.Content[s:s+len(keys)], keys) {
+					continue
+				}
+				run := stepRun{path: f, job: jobs.Content[i].Value, jobKey: jobs.Content[i], jobVal: jobs.Content[i+1], steps: seq.Content[s : s+len(keys)]}
+				if other := defaultRun(doc, run.jobVal); other != defaults {
+					out.Warnings = append(out.Warnings, fmt.Sprintf("the steps at %s:%d were left in place, since job %q runs scripts with other defaults (%s) than job %q (%s)", f, run.steps[0].Line, run.job, other, job, defaults))
+				} else {
+					runs = append(runs, run)
+				}
+				s += len(keys) - 1
+			}
+		}
+	}
+
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("%s is not a workflow file in %s", path, dir)
+	}
+	action, with, outputs, rename, warnings := compositeAction(name, description, job, path, pattern, defaults, runs)
+	out.Warnings = append(out.Warnings, warnings...)
+	actionYAML, err := encodeYAML(action)
+	if err != nil {
+		return nil, err
+	}
+	out.Changes = append(out.Changes, FileChange{
+		Path:    actionPath,
+		Created: true,
+		Diff:    UnifiedDiff(actionPath, nil, []byte(actionYAML)),
+		Content: []byte(actionYAML),
+	})
+	for i := 0; i+1 < len(with.Content); i += 2 {
+		out.Inputs = append(out.Inputs, CompositeInput{
+			Name:        with.Content[i].Value,
+			Description: scalarValue(mappingValue(mappingValue(mappingValue(action, "inputs"), with.Content[i].Value), "description")),
+			Value:       with.Content[i+1].Value,
+		})
+	}
+	out.Outputs = append(out.Outputs, outputs...)
+
+	id := ""
+	for _, o := range outputs {
+		id, _, _ = strings.Cut(strings.TrimPrefix(o.Value, "${{ steps."), ".")
+	}
+	if len(rename) > 0 {
+		id = strings.ReplaceAll(name, ".", "-")
+	}
+
+	// Files are rewritten from their last run of steps up, so the lines of
+	// the runs above stay valid
+	byFile := map[string][]stepRun{}
+	var order []string
+	for _, r := range runs {
+		if _, ok := byFile[r.path]; !ok {
+			order = append(order, r.path)
+		}
+		byFile[r.path] = append(byFile[r.path], r)
+		out.Sites = append(out.Sites, CompositeSite{Workflow: r.path, Job: r.job, Line: r.steps[0].Line})
+	}
+	for _, f := range order {
+		lines := strings.SplitAfter(string(contents[f]), "\n")
+		fileRuns := byFile[f]
+		for i := len(fileRuns) - 1; i >= 0; i-- {
+			lines, err = replaceSteps(lines, fileRuns[i], out.Uses, id, with, rename)
+			if err != nil {
+				return nil, err
+			}
+		}
+		rewritten := []byte(strings.Join(lines, ""))
+		var check yaml.Node
+		if err := yaml.Unmarshal(rewritten, &check); err != nil {
+			return nil, fmt.Errorf("rewriting %s would produce invalid YAML: %w", f, err)
+		}
+		rewritten = keepLayout(raws[f], rewritten)
+		out.Changes = append(out.Changes, FileChange{
+			Path:    f,
+			Diff:    UnifiedDiff(f, raws[f], rewritten),
+			Content: rewritten,
+		})
+	}
+	return out, nil
+}
+
+// runDefaults are the defaults.run of a job, which the steps of a
+// composite action do not inherit.
+type runDefaults struct {
+	shell, dir string
+}
+
+func (d runDefaults) String() string {
+	if d.dir != "" {
+		return "shell " + d.shell + ", working-directory " + d.dir
+	}
+	return "shell " + d.shell
+}
+
+// defaultRun returns the defaults.run of a job, falling back to the
+// workflow's.
+func defaultRun(workflow, job *yaml.Node) runDefaults {
+	var d runDefaults
+	for _, n := range []*yaml.Node{job, workflow} {
+		run := mappingValue(mappingValue(n, "defaults"), "run")
+		if d.shell == "" {
+			d.shell = scalarValue(mappingValue(run, "shell"))
+		}
+		if d.dir == "" {
+			d.dir = scalarValue(mappingValue(run, "working-directory"))
+		}
+	}
+	if d.shell == "" {
+		d.shell = "bash"
+	}
+	return d
+}
+
+// compositeAction builds the action from the steps of pattern: it adds the
+// shell the steps ran with, turns the references the action cannot resolve
+// into inputs, and the outputs of the steps the jobs of runs use later into
+// outputs. It returns the action, the with: of the steps using it, its
+// outputs and, when the outputs of more than one step are used, how the
+// references to them are renamed.
+func compositeAction(name, description, job, path string, pattern []*yaml.Node, defaults runDefaults, runs []stepRun) (action, with *yaml.Node, outputs []CallOutput, rename map[string]string, warnings []string) {
+	ids := map[string]bool{}
+	for _, s := range pattern {
+		if id := scalarValue(mappingValue(s, "id")); id != "" {
+			ids[id] = true
+		}
+	}
+
+	steps := &yaml.Node{Kind: yaml.SequenceNode}
+	for i, s := range pattern {
+		c := copyNode(s)
+		if i == 0 {
+			c.HeadComment = "" // Stays above the step using the action
+		}
+		for _, key := range compositeUnsupported {
+			if k, _ := mappingEntry(c, key); k != nil {
+				warnings = append(warnings, fmt.Sprintf("step %d sets %s, which composite actions do not support, so it was dropped", i+1, key))
+				c.Content = removeEntry(c.Content, key)
+			}
+		}
+		if mappingValue(c, "run") != nil {
+			if mappingValue(c, "shell") == nil {
+				c.Content = append(c.Content, scalar("shell"), scalar(defaults.shell))
+			}
+			if defaults.dir != "" && mappingValue(c, "working-directory") == nil {
+				c.Content = append(c.Content, scalar("working-directory"), scalar(defaults.dir))
+			}
+		}
+		steps.Content = append(steps.Content, c)
+	}
+
+	// References the action cannot resolve become inputs
+	inputs := map[string]CompositeInput{}
+	var rewrites []rewrite
+	var collect func(n *yaml.Node, key string)
+	collect = func(n *yaml.Node, key string) {
+		switch n.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				collect(n.Content[i+1], n.Content[i].Value)
+			}
+		case yaml.SequenceNode:
+			for _, c := range n.Content {
+				collect(c, "")
+			}
+		case yaml.ScalarNode:
+			exprs := callExpression.FindAllStringSubmatch(n.Value, -1)
+			if key == "if" && !strings.Contains(n.Value, "${{") {
+				exprs = [][]string{{n.Value, n.Value}}
+			}
+			for _, e := range exprs {
+				for _, m := range compositeReference.FindAllStringSubmatch(callLiteral.ReplaceAllString(e[1], "''"), -1) {
+					var ref, input, desc string
+					switch {
+					case m[2] == "secrets" && m[3] == "GITHUB_TOKEN":
+						rewrites = append(rewrites, rewrite{regexp.MustCompile(`\bsecrets\.GITHUB_TOKEN\b`), "github.token"})
+						continue
+					case m[2] == "secrets":
+						ref, input, desc = "secrets."+m[3], strings.ToLower(strings.ReplaceAll(m[3], "_", "-")), "Value of secret "+m[3]
+					case m[2] == "inputs":
+						ref, input, desc = "inputs."+m[3], m[3], "Input "+m[3]+" of the calling workflow"
+					case ids[m[4]]:
+						continue
+					case m[5] != "":
+						ref, input, desc = "steps."+m[4]+".outputs."+m[5], m[4]+"-"+m[5], "Output "+m[5]+" of step "+m[4]
+					default:
+						ref, input, desc = "steps."+m[4]+"."+m[6], m[4]+"-"+m[6], "The "+m[6]+" of step "+m[4]
+					}
+					if _, ok := inputs[input]; ok {
+						continue
+					}
+					inputs[input] = CompositeInput{Name: input, Description: desc, Value: "${{ " + ref + " }}"}
+					rewrites = append(rewrites, rewrite{regexp.MustCompile(`(^|[^\w.-])` + regexp.QuoteMeta(ref) + `\b`), "${1}inputs." + input})
+				}
+			}
+		}
+	}
+	for _, s := range steps.Content {
+		collect(s, "")
+	}
+	for _, s := range steps.Content {
+		rewriteReferences(s, "", rewrites)
+	}
+
+	// Outputs of the steps used elsewhere in the jobs become outputs
+	used := map[string]bool{}
+	for _, r := range runs {
+		for _, ref := range stepReferences(r.jobVal, r.steps) {
+			m := stepResult.FindStringSubmatch(ref)
+			if !ids[m[1]] {
+				continue
+			}
+			if m[3] != "" {
+				warnings = append(warnings, fmt.Sprintf("job %q of %s reads steps.%s.%s, which becomes the %s of the whole action", r.job, r.path, m[1], m[3], m[3]))
+				continue
+			}
+			used[m[1]+"."+m[2]] = true
+		}
+	}
+	refs := make([]string, 0, len(used))
+	stepIDs := map[string]bool{}
+	for ref := range used {
+		refs = append(refs, ref)
+		id, _, _ := strings.Cut(ref, ".")
+		stepIDs[id] = true
+	}
+	sort.Strings(refs)
+	id := strings.ReplaceAll(name, ".", "-")
+	outs := &yaml.Node{Kind: yaml.MappingNode}
+	for _, ref := range refs {
+		step, output, _ := strings.Cut(ref, ".")
+		outName := output
+		if len(stepIDs) > 1 {
+			outName = step + "-" + output
+			if rename == nil {
+				rename = map[string]string{}
+			}
+			rename["steps."+step+".outputs."+output] = "steps." + id + ".outputs." + outName
+		}
+		o := CallOutput{Name: outName, Description: fmt.Sprintf("Output %s of step %s", output, step), Value: "${{ steps." + step + ".outputs." + output + " }}"}
+		outputs = append(outputs, o)
+		outs.Content = append(outs.Content, scalar(o.Name), mapping("description", scalar(o.Description), "value", scalar(o.Value)))
+	}
+
+	if description == "" {
+		description = fmt.Sprintf("Steps extracted from job %s of %s", job, filepath.Base(path))
+	}
+	action = mapping("name", scalar(name), "description", scalar(description))
+	with = &yaml.Node{Kind: yaml.MappingNode}
+	if len(inputs) > 0 {
+		names := make([]string, 0, len(inputs))
+		for n := range inputs {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		defs := &yaml.Node{Kind: yaml.MappingNode}
+		for _, n := range names {
+			in := inputs[n]
+			defs.Content = append(defs.Content, scalar(n), mapping("description", scalar(in.Description), "required", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"}))
+			with.Content = append(with.Content, scalar(n), scalar(in.Value))
+		}
+		action.Content = append(action.Content, scalar("inputs"), defs)
+	}
+	if len(outs.Content) > 0 {
+		action.Content = append(action.Content, scalar("outputs"), outs)
+	}
+	action.Content = append(action.Content, scalar("runs"), mapping("using", scalar("composite"), "steps", steps))
+	return action, with, outputs, rename, warnings
+}
+
+// stepReferences returns the references to step results in job outside
+// the steps of skip.
+func stepReferences(job *yaml.Node, skip []*yaml.Node) []string {
+	var refs []string
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		for _, s := range skip {
+			if n == s {
+				return
+			}
+		}
+		if n.Kind == yaml.ScalarNode {
+			refs = append(refs, stepResult.FindAllString(n.Value, -1)...)
+		}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(job)
+	return refs
+}
+
+// replaceSteps replaces the lines of the steps of r with a step using the
+// action, and renames references to the outputs of the steps in the rest
+// of the job.
+func replaceSteps(lines []string, r stepRun, uses, id string, with *yaml.Node, rename map[string]string) ([]string, error) {
+	first, last := r.steps[0], r.steps[len(r.steps)-1]
+	start := first.Line - 1
+	indent := first.Column - 1
+	if start >= len(lines) || len(lines[start]) < indent || strings.TrimSpace(lines[start][:indent]) != "-" {
+		return nil, fmt.Errorf("the steps at %s:%d do not start with \"- \" on the line of their first key", r.path, first.Line)
+	}
+	end := skipBlock(lines, last.Line, indent)
+	for end > start+1 && isBlankOrComment(lines[end-1]) {
+		end--
+	}
+
+	pad := strings.Repeat(" ", indent)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%suses: %s\n", lines[start][:indent], uses)
+	if id != "" {
+		fmt.Fprintf(&b, "%sid: %s\n", pad, id)
+	}
+	if len(with.Content) > 0 {
+		fmt.Fprintf(&b, "%swith:\n", pad)
+		for i := 0; i+1 < len(with.Content); i += 2 {
+			fmt.Fprintf(&b, "%s  %s: %s\n", pad, with.Content[i].Value, with.Content[i+1].Value)
+		}
+	}
+
+	jobStart := r.jobKey.Line - 1
+	jobEnd := skipBlock(lines, r.jobKey.Line, r.jobKey.Column)
+	out := make([]string, 0, len(lines))
+	for i, l := range lines {
+		switch {
+		case i == start:
+			out = append(out, b.String())
+		case i > start && i < end:
+		case i > jobStart && i < jobEnd:
+			for from, to := range rename {
+				l = regexp.MustCompile(`\b`+regexp.QuoteMeta(from)+`\b`).ReplaceAllString(l, to)
+			}
+			out = append(out, l)
+		default:
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+// skipBlock returns the index of the first line after line (counting from
+// 1) indented less than indent columns, ignoring blank lines and comments.
+func skipBlock(lines []string, line, indent int) int {
+	for i := line; i < len(lines); i++ {
+		if isBlankOrComment(lines[i]) {
+			continue
+		}
+		if len(lines[i])-len(strings.TrimLeft(lines[i], " ")) < indent {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+func isBlankOrComment(l string) bool {
+	t := strings.TrimSpace(l)
+	return t == "" || strings.HasPrefix(t, "#")
+}
+
+// removeEntry returns the content of a mapping without key.
+func removeEntry(content []*yaml.Node, key string) []*yaml.Node {
+	out := content[:0:0]
+	for i := 0; i+1 < len(content); i += 2 {
+		if content[i].Value != key {
+			out = append(out, content[i], content[i+1])
+		}
+	}
+	return out
+}
+
+// matchSteps reports whether steps are the steps whose keys are keys.
+func matchSteps(steps []*yaml.Node, keys []string) bool {
+	for i, s := range steps {
+		if nodeKey(s) != keys[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeKey renders n so that nodes with the same content, whatever their
+// layout, comments and key order, have the same key.
+func nodeKey(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return nodeKey(n.Alias)
+	case yaml.ScalarNode:
+		return strconv.Quote(n.Value)
+	case yaml.SequenceNode:
+		items := make([]string, len(n.Content))
+		for i, c := range n.Content {
+			items[i] = nodeKey(c)
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	case yaml.MappingNode:
+		pairs := make([]string, 0, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			pairs = append(pairs, nodeKey(n.Content[i])+":"+nodeKey(n.Content[i+1]))
+		}
+		sort.Strings(pairs)
+		return "{" + strings.Join(pairs, ",") + "}"
+	}
+	return ""
+}
@@ -0,0 +1,241 @@
+package linter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const compositeCI = `name: CI
+on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      # Set up the toolchain
+      - uses: actions/setup-node@v4
+        with:
+          node-version: 20
+      - id: install
+        run: |
+          npm ci
+          echo "dir=$(npm config get cache)" >> "$GITHUB_OUTPUT"
+        env:
+          NPM_TOKEN: ${{ secrets.NPM_TOKEN }}
+        timeout-minutes: 5
+
+      - run: npm test -- --cache ${{ steps.install.outputs.dir }}
+`
+
+const compositeRelease = `on:
+  push:
+    tags: ['v*']
+jobs:
+  publish:
+    runs-on: ubuntu-latest
+    steps:
+    - uses: actions/checkout@v4
+    - uses: actions/setup-node@v4
+      with: {node-version: 20}
+    - id: install
+      run: |
+        npm ci
+        echo "dir=$(npm config get cache)" >> "$GITHUB_OUTPUT"
+      timeout-minutes: 5
+      env:
+        NPM_TOKEN: ${{ secrets.NPM_TOKEN }}
+    - run: npm publish
+  windows:
+    runs-on: windows-latest
+    defaults:
+      run:
+        shell: pwsh
+    steps:
+      - uses: actions/setup-node@v4
+        with:
+          node-version: 20
+      - id: install
+        run: |
+          npm ci
+          echo "dir=$(npm config get cache)" >> "$GITHUB_OUTPUT"
+        env:
+          NPM_TOKEN: ${{ secrets.NPM_TOKEN }}
+        timeout-minutes: 5
+`
+
+func writeCompositeRepo(t *testing.T) string {
+	root := t.TempDir()
+	dir := filepath.Join(root, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ci.yml"), []byte(compositeCI), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "release.yml"), []byte(compositeRelease), 0600))
+	return root
+}
+
+func TestExtractCompositeAction(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	root := writeCompositeRepo(t)
+	ci := filepath.Join(root, ".github", "workflows", "ci.yml")
+	release := filepath.Join(root, ".github", "workflows", "release.yml")
+
+	out, err := ExtractCompositeAction(ci, "test", 2, 3, "setup", "")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, ".github", "actions", "setup", "action.yml"), out.Action)
+	assert.Equal(t, "./.github/actions/setup", out.Uses)
+	assert.Equal(t, []CompositeInput{{Name: "npm-token", Description: "Value of secret NPM_TOKEN", Value: "${{ secrets.NPM_TOKEN }}"}}, out.Inputs)
+	assert.Equal(t, []CallOutput{{Name: "dir", Description: "Output dir of step install", Value: "${{ steps.install.outputs.dir }}"}}, out.Outputs)
+	assert.Equal(t, []CompositeSite{{Workflow: ci, Job: "test", Line: 9}, {Workflow: release, Job: "publish", Line: 9}}, out.Sites)
+	require.Len(t, out.Warnings, 2)
+	assert.Contains(t, out.Warnings[0], `job "windows" runs scripts with other defaults (shell pwsh)`)
+	assert.Contains(t, out.Warnings[1], "timeout-minutes")
+
+	require.Len(t, out.Changes, 3)
+	action := string(out.Changes[0].Content)
+	assert.True(t, out.Changes[0].Created)
+	assert.Contains(t, action, "runs:\n  using: composite\n")
+	assert.Contains(t, action, "NPM_TOKEN: ${{ inputs.npm-token }}\n      shell: bash\n")
+	assert.NotContains(t, action, "timeout-minutes")
+	assert.NotContains(t, action, "# Set up the toolchain")
+
+	assert.Equal(t, ci, out.Changes[1].Path)
+	assert.Contains(t, string(out.Changes[1].Content), `      # Set up the toolchain
+      - uses: ./.github/actions/setup
+        id: install
+        with:
+          npm-token: ${{ secrets.NPM_TOKEN }}
+
+      - run: npm test -- --cache ${{ steps.install.outputs.dir }}
+`)
+	assert.Contains(t, out.Changes[1].Diff, "-      - id: install\n")
+	assert.Contains(t, string(out.Changes[2].Content), `    - uses: ./.github/actions/setup
+      id: install
+      with:
+        npm-token: ${{ secrets.NPM_TOKEN }}
+    - run: npm publish
+`)
+	_, err = os.Stat(out.Action)
+	assert.True(t, os.IsNotExist(err), "nothing is written")
+
+	require.NoError(t, WriteChanges(out.Changes))
+	info, err := os.Stat(release)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	written, err := os.ReadFile(out.Action)
+	require.NoError(t, err)
+	assert.Equal(t, action, string(written))
+
+	_, err = ExtractCompositeAction(ci, "test", 3, 3, "setup", "")
+	assert.ErrorContains(t, err, "already exists")
+}
+
+func TestExtractCompositeAction_Outputs(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	path := filepath.Join(dir, "build.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    outputs:
+      version: ${{ steps.version.outputs.value }}
+    defaults:
+      run:
+        working-directory: app
+    steps:
+      - id: check
+        run: echo "changed=true" >> "$GITHUB_OUTPUT"
+      - id: version
+        if: steps.check.outputs.changed == 'true' && inputs.release
+        run: echo "value=1.0" >> "$GITHUB_OUTPUT"
+        env:
+          TOKEN: ${{ secrets.GITHUB_TOKEN }}
+      - id: sha
+        run: echo "value=$GITHUB_SHA" >> "$GITHUB_OUTPUT"
+      - run: echo ${{ steps.sha.outputs.value }} ${{ steps.sha.outcome }}
+`), 0644))
+
+	out, err := ExtractCompositeAction(path, "build", 2, 3, "meta.data", "Compute the version")
+	require.NoError(t, err)
+	assert.Equal(t, []CompositeInput{
+		{Name: "check-changed", Description: "Output changed of step check", Value: "${{ steps.check.outputs.changed }}"},
+		{Name: "release", Description: "Input release of the calling workflow", Value: "${{ inputs.release }}"},
+	}, out.Inputs)
+	assert.Equal(t, []string{"sha-value", "version-value"}, []string{out.Outputs[0].Name, out.Outputs[1].Name})
+	require.Len(t, out.Warnings, 1)
+	assert.Contains(t, out.Warnings[0], "steps.sha.outcome")
+
+	action := string(out.Changes[0].Content)
+	assert.Contains(t, action, "description: Compute the version\n")
+	assert.Contains(t, action, "if: inputs.check-changed == 'true' && inputs.release\n")
+	assert.Contains(t, action, "TOKEN: ${{ github.token }}\n")
+	assert.Contains(t, action, "working-directory: app\n")
+
+	workflow := string(out.Changes[1].Content)
+	assert.Contains(t, workflow, "version: ${{ steps.meta-data.outputs.version-value }}\n")
+	assert.Contains(t, workflow, "      - uses: ./.github/actions/meta.data\n        id: meta-data\n")
+	assert.Contains(t, workflow, "echo ${{ steps.meta-data.outputs.sha-value }} ${{ steps.sha.outcome }}\n")
+}
+
+func TestExtractCompositeAction_Errors(t *testing.T) {
+	root := writeCompositeRepo(t)
+	ci := filepath.Join(root, ".github", "workflows", "ci.yml")
+
+	_, err := ExtractCompositeAction(ci, "test", 2, 2, "../escape", "")
+	assert.ErrorContains(t, err, "action name")
+
+	_, err = ExtractCompositeAction(ci, "missing", 2, 2, "setup", "")
+	assert.ErrorContains(t, err, "not found")
+
+	_, err = ExtractCompositeAction(ci, "test", 1, 2, "setup", "")
+	assert.ErrorContains(t, err, "step 1 checks out the repository")
+
+	_, err = ExtractCompositeAction(ci, "test", 3, 9, "setup", "")
+	assert.ErrorContains(t, err, "has no steps 3 to 9")
+
+	other := filepath.Join(t.TempDir(), "ci.yml")
+	require.NoError(t, os.WriteFile(other, []byte(compositeCI), 0644))
+	_, err = ExtractCompositeAction(other, "test", 2, 2, "setup", "")
+	assert.ErrorContains(t, err, "is not in .github/workflows")
+}
+
+func TestLintChanges(t *testing.T) {
+	root := writeCompositeRepo(t)
+	ci := filepath.Join(root, ".github", "workflows", "ci.yml")
+
+	out, err := ExtractCompositeAction(ci, "test", 2, 3, "setup", "")
+	require.NoError(t, err)
+	// Pass an input the action does not have, which only the staged action
+	// tells
+	for i, c := range out.Changes {
+		if c.Path == ci {
+			out.Changes[i].Content = []byte(strings.Replace(string(c.Content), "npm-token:", "npm_tokn:", 1))
+		}
+	}
+
+	results, err := New(Options{}).LintChanges(context.Background(), root, out.Changes)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, ci, results[0].FilePath)
+	assert.False(t, results[0].Valid)
+	found := false
+	for _, e := range results[0].Errors {
+		if e.Kind == "action" && strings.Contains(e.Message, "npm_tokn") {
+			found = true
+			assert.NotContains(t, e.Message, os.TempDir()+string(filepath.Separator)+"actionlint-mcp-")
+		}
+	}
+	assert.True(t, found, "errors: %v", results[0].Errors)
+	for _, e := range results[1].Errors {
+		assert.NotEqual(t, "action", e.Kind, "release.yml passes the inputs the action has: %v", e)
+	}
+
+	_, err = os.Stat(out.Action)
+	assert.True(t, os.IsNotExist(err))
+}
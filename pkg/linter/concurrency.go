@@ -0,0 +1,321 @@
+package linter
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/rhysd/actionlint"
+)
+
+// Kinds of findings about concurrency groups: runs that wait for a group
+// they hold themselves, and runs of one workflow that the runs of another
+// cancel through a shared group.
+const (
+	KindConcurrencyDeadlock   = rules.KindConcurrencyDeadlock
+	KindConcurrencyStarvation = rules.KindConcurrencyStarvation
+)
+
+var (
+	// groupExpression matches the ${{ }} expressions in a group name.
+	groupExpression = regexp.MustCompile(`\$\{\{(.*?)\}\}`)
+	// groupLiteral matches string literals in an expression.
+	groupLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	// groupReference matches the context references in an expression.
+	groupReference = regexp.MustCompile(`\b(github|inputs|matrix|env|vars|needs|steps|strategy|job|jobs|runner|secrets)\b(\.[\w-]+|\[[^\]]*\])*`)
+
+	// runVarying are the contexts whose values differ between the jobs of
+	// one run or between a caller and a reusable workflow.
+	runVarying = map[string]bool{"inputs": true, "matrix": true, "env": true, "needs": true, "steps": true, "strategy": true, "job": true, "jobs": true, "runner": true}
+	// sharedReferences are the references whose values the runs of
+	// different workflows for the same ref share.
+	sharedReferences = map[string]bool{"github.ref": true, "github.ref_name": true, "github.head_ref": true, "github.base_ref": true, "github.repository": true, "github.repository_owner": true}
+	// manualEvents are the events a person or another system triggers on
+	// purpose.
+	manualEvents = map[string]bool{"workflow_dispatch": true, "repository_dispatch": true}
+)
+
+// heldGroup is a concurrency group a run holds while its jobs run.
+type heldGroup struct {
+	group string
+	path  string
+	pos   *actionlint.Pos
+}
+
+// normalizeGroup returns the group name with the whitespace of its
+// expressions normalized, so equal names compare equal.
+func normalizeGroup(s string) string {
+	return strings.TrimSpace(groupExpression.ReplaceAllStringFunc(s, func(e string) string {
+		inner := groupExpression.FindStringSubmatch(e)[1]
+		return "${{ " + strings.Join(strings.Fields(inner), " ") + " }}"
+	}))
+}
+
+// groupReferences returns the context references of a group name.
+func groupReferences(group string) []string {
+	var refs []string
+	for _, m := range groupExpression.FindAllStringSubmatch(group, -1) {
+		refs = append(refs, groupReference.FindAllString(groupLiteral.ReplaceAllString(m[1], ""), -1)...)
+	}
+	return refs
+}
+
+// runGroup returns the normalized group of c when it has the same value
+// wherever it is evaluated in one run, or "".
+func runGroup(c *actionlint.Concurrency) string {
+	if c == nil || c.Group == nil {
+		return ""
+	}
+	for _, ref := range groupReferences(c.Group.Value) {
+		ctx, _, _ := strings.Cut(ref, ".")
+		if runVarying[ctx] || ref == "github.job" {
+			return ""
+		}
+	}
+	return normalizeGroup(c.Group.Value)
+}
+
+// sharedGroup returns the normalized group of c when the runs of different
+// workflows for the same ref evaluate it to the same value, or "".
+func sharedGroup(c *actionlint.Concurrency) string {
+	if c == nil || c.Group == nil {
+		return ""
+	}
+	for _, ref := range groupReferences(c.Group.Value) {
+		if !sharedReferences[ref] && !strings.HasPrefix(ref, "vars.") {
+			return ""
+		}
+	}
+	return normalizeGroup(c.Group.Value)
+}
+
+func findHeld(held []heldGroup, group string) (heldGroup, bool) {
+	for _, h := range held {
+		if h.group == group {
+			return h, true
+		}
+	}
+	return heldGroup{}, false
+}
+
+func sortedJobs(w *actionlint.Workflow) []string {
+	ids := make([]string, 0, len(w.Jobs))
+	for id := range w.Jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// concurrencyFindings reports the concurrency groups of the workflow at
+// path that deadlock, because a job or a reusable workflow it calls waits
+// for a group the run already holds, and, for workflows in WorkflowsDir,
+// the groups it shares with workflows triggered differently, whose runs
+// then cancel each other.
+func concurrencyFindings(path string, content []byte) []LintError {
+	root := repositoryRoot(path)
+	path = filepath.Clean(path)
+	r := &repoWorkflows{root: root, parsed: map[string]*actionlint.Workflow{}}
+	w := r.parse(path, content)
+	if w == nil {
+		return nil
+	}
+	found := r.deadlocks(path, w)
+	if filepath.Clean(root) != filepath.Dir(path) {
+		found = append(found, r.starvation(path, w)...)
+	}
+	return found
+}
+
+func (r *repoWorkflows) deadlocks(path string, w *actionlint.Workflow) []LintError {
+	var held []heldGroup
+	if g := runGroup(w.Concurrency); g != "" {
+		held = append(held, heldGroup{g, path, w.Concurrency.Group.Pos})
+	}
+
+	var found []LintError
+	report := func(pos *actionlint.Pos, msg string) {
+		found = append(found, LintError{
+			Message:  msg,
+			Line:     pos.Line,
+			Column:   pos.Col,
+			Kind:     KindConcurrencyDeadlock,
+			Severity: SeverityForKind(KindConcurrencyDeadlock),
+		})
+	}
+	for _, id := range sortedJobs(w) {
+		j := w.Jobs[id]
+		jobHeld := held
+		if g := runGroup(j.Concurrency); g != "" {
+			if h, ok := findHeld(held, g); ok {
+				report(j.Concurrency.Group.Pos, fmt.Sprintf("job %q waits for concurrency group %q, which its workflow holds while the job runs (line %d), so each waits for the other and GitHub cancels the run. give the job a group of its own", id, g, h.pos.Line))
+				continue
+			}
+			jobHeld = append(append([]heldGroup{}, held...), heldGroup{g, path, j.Concurrency.Group.Pos})
+		}
+		call := j.WorkflowCall
+		if call == nil || call.Uses == nil || !strings.HasPrefix(call.Uses.Value, "./") || len(jobHeld) == 0 {
+			continue
+		}
+		callee := filepath.Join(r.root, filepath.FromSlash(call.Uses.Value))
+		if waiting, holder, ok := r.callDeadlock(callee, jobHeld, []string{path}); ok {
+			where := fmt.Sprintf("line %d", holder.pos.Line)
+			if holder.path != path {
+				where = fmt.Sprintf("%s:%d", relativeTo(r.root, holder.path), holder.pos.Line)
+			}
+			report(call.Uses.Pos, fmt.Sprintf("%s waits for concurrency group %q at %s:%d, which this run already holds (%s), so each waits for the other and GitHub cancels the run. expressions such as github.workflow have the caller's values in a reusable workflow, so give it a group of its own", relativeTo(r.root, callee), waiting.group, relativeTo(r.root, waiting.path), waiting.pos.Line, where))
+		}
+	}
+	return found
+}
+
+// callDeadlock looks for a group that the reusable workflow at path, or a
+// workflow it calls, waits for although the caller holds it. It returns
+// the group waited for and the caller's group it equals.
+func (r *repoWorkflows) callDeadlock(path string, held []heldGroup, stack []string) (waiting, holder heldGroup, ok bool) {
+	for _, p := range stack {
+		if p == path {
+			return waiting, holder, false // Reported as a cycle of calls
+		}
+	}
+	stack = append(stack, path)
+	w := r.parse(path, nil)
+	if w == nil {
+		return waiting, holder, false
+	}
+	if g := runGroup(w.Concurrency); g != "" {
+		if h, found := findHeld(held, g); found {
+			return heldGroup{g, path, w.Concurrency.Group.Pos}, h, true
+		}
+		held = append(append([]heldGroup{}, held...), heldGroup{g, path, w.Concurrency.Group.Pos})
+	}
+	for _, id := range sortedJobs(w) {
+		j := w.Jobs[id]
+		jobHeld := held
+		if g := runGroup(j.Concurrency); g != "" {
+			if h, found := findHeld(held, g); found {
+				return heldGroup{g, path, j.Concurrency.Group.Pos}, h, true
+			}
+			jobHeld = append(append([]heldGroup{}, held...), heldGroup{g, path, j.Concurrency.Group.Pos})
+		}
+		if call := j.WorkflowCall; call != nil && call.Uses != nil && strings.HasPrefix(call.Uses.Value, "./") {
+			callee := filepath.Join(r.root, filepath.FromSlash(call.Uses.Value))
+			if waiting, holder, ok = r.callDeadlock(callee, jobHeld, stack); ok {
+				return waiting, holder, true
+			}
+		}
+	}
+	return waiting, holder, false
+}
+
+// groupUse is a concurrency group a workflow or one of its jobs uses.
+type groupUse struct {
+	group  string
+	pos    *actionlint.Pos
+	cancel bool
+}
+
+// sharedGroups returns the groups of w that runs of other workflows can
+// share.
+func sharedGroups(w *actionlint.Workflow) []groupUse {
+	var uses []groupUse
+	add := func(c *actionlint.Concurrency) {
+		if g := sharedGroup(c); g != "" {
+			cancel := c.CancelInProgress != nil && c.CancelInProgress.Expression == nil && c.CancelInProgress.Value
+			uses = append(uses, groupUse{g, c.Group.Pos, cancel})
+		}
+	}
+	add(w.Concurrency)
+	for _, id := range sortedJobs(w) {
+		add(w.Jobs[id].Concurrency)
+	}
+	return uses
+}
+
+// manualOnly reports whether w only runs when triggered on purpose, and
+// whether it runs on its own at all rather than only when called.
+func manualOnly(w *actionlint.Workflow) (manual, runs bool) {
+	manual = true
+	for _, e := range w.On {
+		name := e.EventName()
+		if name == "workflow_call" {
+			continue
+		}
+		runs = true
+		manual = manual && manualEvents[name]
+	}
+	return manual && runs, runs
+}
+
+func (r *repoWorkflows) starvation(path string, w *actionlint.Workflow) []LintError {
+	manual, runs := manualOnly(w)
+	if !runs {
+		return nil // Groups of reusable workflows are evaluated for their callers
+	}
+	own := sharedGroups(w)
+	if len(own) == 0 {
+		return nil
+	}
+	files, err := FindWorkflowFiles(filepath.Dir(path))
+	if err != nil {
+		return nil
+	}
+
+	var found []LintError
+	for _, u := range own {
+		var others []string
+		othersCancel := false
+		for _, f := range files {
+			if filepath.Clean(f) == path {
+				continue
+			}
+			other := r.parse(f, nil)
+			if other == nil {
+				continue
+			}
+			otherManual, otherRuns := manualOnly(other)
+			if !otherRuns || otherManual == manual {
+				continue
+			}
+			for _, o := range sharedGroups(other) {
+				if o.group == u.group {
+					others = append(others, fmt.Sprintf("%s:%d", relativeTo(r.root, f), o.pos.Line))
+					othersCancel = othersCancel || o.cancel
+				}
+			}
+		}
+		if len(others) == 0 {
+			continue
+		}
+
+		var msg string
+		if manual {
+			msg = fmt.Sprintf("concurrency group %q is also used by %s, which run automatically, while this workflow only runs when triggered by hand. ", u.group, strings.Join(others, ", "))
+			if othersCancel {
+				msg += "they cancel runs in progress, so the next automatic run cancels a running one of this workflow, such as a rollback, half-way. "
+			} else {
+				msg += "only one run of a group can wait, so a run of this workflow waiting for the group is cancelled when an automatic run queues after it, and may never start. "
+			}
+			msg += "give this workflow a group of its own"
+		} else {
+			msg = fmt.Sprintf("concurrency group %q is also used by %s, which only run when triggered by hand, such as a rollback. ", u.group, strings.Join(others, ", "))
+			if u.cancel {
+				msg += "this workflow cancels runs in progress, so its next run cancels a running one of them half-way. "
+			} else {
+				msg += "only one run of a group can wait, so a manual run waiting for the group is cancelled when this workflow queues a run after it, and may never start. "
+			}
+			msg += "give the manual workflow a group of its own"
+		}
+		found = append(found, LintError{
+			Message:  msg,
+			Line:     u.pos.Line,
+			Column:   u.pos.Col,
+			Kind:     KindConcurrencyStarvation,
+			Severity: SeverityForKind(KindConcurrencyStarvation),
+		})
+	}
+	return found
+}
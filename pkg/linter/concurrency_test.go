@@ -0,0 +1,120 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyFindings_Deadlock(t *testing.T) {
+	repo := t.TempDir()
+	dir := filepath.Join(repo, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+
+	ci := filepath.Join(dir, "ci.yml")
+	require.NoError(t, os.WriteFile(ci, []byte(`on: push
+concurrency:
+  group: ${{ github.workflow }}-${{ github.ref }}
+jobs:
+  build:
+    concurrency: ${{github.workflow}}-${{ github.ref }}
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+  per-matrix:
+    concurrency: ${{ github.workflow }}-${{ matrix.os }}
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+  deploy:
+    uses: ./.github/workflows/deploy.yml
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "deploy.yml"), []byte(`on: workflow_call
+jobs:
+  deploy:
+    uses: ./.github/workflows/release.yml
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "release.yml"), []byte(`on: workflow_call
+concurrency: ${{ github.workflow }}-${{ github.ref }}
+jobs:
+  release:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make release
+`), 0644))
+
+	findings := concurrencyFindings(ci, nil)
+	require.Len(t, findings, 2)
+	assert.Equal(t, 6, findings[0].Line)
+	assert.Equal(t, KindConcurrencyDeadlock, findings[0].Kind)
+	assert.Equal(t, SeverityError, findings[0].Severity)
+	assert.Contains(t, findings[0].Message, `job "build" waits for concurrency group "${{ github.workflow }}-${{ github.ref }}", which its workflow holds while the job runs (line 3)`)
+	assert.Equal(t, 16, findings[1].Line)
+	assert.Contains(t, findings[1].Message, `.github/workflows/deploy.yml waits for concurrency group "${{ github.workflow }}-${{ github.ref }}" at .github/workflows/release.yml:2, which this run already holds (line 3)`)
+}
+
+func TestConcurrencyFindings_Starvation(t *testing.T) {
+	repo := t.TempDir()
+	dir := filepath.Join(repo, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	deploy := write("deploy.yml", `on:
+  push:
+    branches: [main]
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    concurrency:
+      group: production
+      cancel-in-progress: true
+    steps:
+      - run: ./deploy.sh
+`)
+	rollback := write("rollback.yml", `on: workflow_dispatch
+concurrency: production
+jobs:
+  rollback:
+    runs-on: ubuntu-latest
+    steps:
+      - run: ./rollback.sh
+`)
+	write("manual.yml", `on: workflow_dispatch
+concurrency: production
+jobs:
+  noop:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+`)
+	other := write("staging.yml", `on: push
+concurrency: ${{ github.workflow }}-production
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - run: ./deploy.sh staging
+`)
+
+	findings := concurrencyFindings(rollback, nil)
+	require.Len(t, findings, 1)
+	assert.Equal(t, 2, findings[0].Line)
+	assert.Equal(t, KindConcurrencyStarvation, findings[0].Kind)
+	assert.Equal(t, SeverityWarning, findings[0].Severity)
+	assert.Contains(t, findings[0].Message, `concurrency group "production" is also used by .github/workflows/deploy.yml:8, which run automatically`)
+	assert.Contains(t, findings[0].Message, "the next automatic run cancels a running one of this workflow")
+
+	findings = concurrencyFindings(deploy, nil)
+	require.Len(t, findings, 1)
+	assert.Equal(t, 8, findings[0].Line)
+	assert.Contains(t, findings[0].Message, `also used by .github/workflows/manual.yml:2, .github/workflows/rollback.yml:2, which only run when triggered by hand`)
+
+	assert.Empty(t, concurrencyFindings(other, nil))
+}
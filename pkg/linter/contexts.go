@@ -0,0 +1,198 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"gopkg.in/yaml.v3"
+)
+
+// KindContextAvailability is the kind of findings for contexts used where
+// GitHub does not provide them, which fail the run when it starts or
+// reaches them. actionlint reports them as expression findings.
+const KindContextAvailability = rules.KindContextAvailability
+
+var (
+	// contextNotAllowed matches actionlint's finding for a context used
+	// where it is not available.
+	contextNotAllowed = regexp.MustCompile(`^context "([\w-]+)" is not allowed here\. (?:available contexts are (.+)|no context is available here)\. see (\S+) for more details$`)
+	// emptyObjectProperty matches actionlint's finding for a property of a
+	// context without properties, as matrix is in jobs without a matrix.
+	emptyObjectProperty = regexp.MustCompile(`^property "([\w-]+)" is not defined in object type \{\}$`)
+)
+
+// contextHints say how to do without a context where it is not available,
+// by context and by whether the expression is in a step, elsewhere in a
+// job, or outside the jobs. The first matching hint applies.
+var contextHints = []struct {
+	context string
+	where   func(path string) bool
+	hint    string
+}{
+	{"secrets", isPath("jobs.<job_id>.if"), "check the secret in a step of an earlier job, and read the result from needs.<job_id>.outputs"},
+	{"secrets", inSteps, "set an environment variable of the job from the secret, as HAS_TOKEN: ${{ secrets.TOKEN != '' }}, and check env.HAS_TOKEN"},
+	{"secrets", anywhere, "use vars for values that are not secret"},
+	{"env", inJob, "env is only set for steps; use vars for values shared by jobs, or an output of an earlier job from needs"},
+	{"env", anywhere, "use vars or inputs instead"},
+	{"steps", inJob, "steps only exist once they ran; read them in later steps, or pass them on as jobs.<job_id>.outputs"},
+	{"runner", anywhere, "the runner is only known once a job runs; read it in the job's steps"},
+	{"matrix", anywhere, "matrix only exists in jobs with a strategy.matrix"},
+	{"job", anywhere, "job only exists inside a job"},
+	{"strategy", anywhere, "strategy only exists inside a job"},
+	{"needs", anywhere, "needs only exists in jobs, for the jobs they list"},
+}
+
+func isPath(want string) func(string) bool {
+	return func(path string) bool { return path == want }
+}
+
+func inSteps(path string) bool {
+	return strings.HasPrefix(path, "jobs.<job_id>.steps[*]")
+}
+
+func inJob(path string) bool {
+	return strings.HasPrefix(path, "jobs.<job_id>.") && !inSteps(path)
+}
+
+func anywhere(string) bool { return true }
+
+// explainContexts rewrites actionlint's findings for contexts used where
+// they are not available, in content, as findings of
+// KindContextAvailability naming where the context was used, the contexts
+// available there and how to do without it. A matrix property read in a
+// job without a matrix is reported the same way, and the findings for
+// properties of a context already reported are dropped.
+func explainContexts(result *LintResult, content []byte) {
+	var doc yaml.Node
+	if yaml.Unmarshal(content, &doc) != nil || len(doc.Content) == 0 {
+		return
+	}
+	lines := strings.Split(string(content), "\n")
+
+	type position struct{ line, column int }
+	reported := map[position]bool{}
+	for i := range result.Errors {
+		e := &result.Errors[i]
+		if e.Kind != "expression" {
+			continue
+		}
+		m := contextNotAllowed.FindStringSubmatch(e.Message)
+		if m == nil {
+			continue
+		}
+		path, _ := yamlPathAt(doc.Content[0], e.Line, e.Column)
+		if path == "" {
+			path = "this position"
+		}
+		msg := fmt.Sprintf("context %q is not available in %s", m[1], path)
+		if m[2] != "" {
+			msg += ", which can only use " + m[2]
+		} else {
+			msg += ", which cannot use any context"
+		}
+		for _, h := range contextHints {
+			if h.context == m[1] && h.where(path) {
+				msg += ". " + h.hint
+				break
+			}
+		}
+		e.Message = msg + ". see " + m[3]
+		e.Kind, e.Severity = KindContextAvailability, SeverityForKind(KindContextAvailability)
+		reported[position{e.Line, e.Column}] = true
+	}
+
+	kept := result.Errors[:0]
+	for _, e := range result.Errors {
+		if e.Kind == "expression" {
+			if m := emptyObjectProperty.FindStringSubmatch(e.Message); m != nil {
+				if reported[position{e.Line, e.Column}] {
+					continue
+				}
+				if job := matrixWithoutJobMatrix(doc.Content[0], lines, e, m[1]); job != "" {
+					e.Message = fmt.Sprintf("matrix.%s is not available, since job %q has no strategy.matrix. define the matrix, or pass the value another way, as vars or inputs", m[1], job)
+					e.Kind, e.Severity = KindContextAvailability, SeverityForKind(KindContextAvailability)
+				}
+			}
+		}
+		kept = append(kept, e)
+	}
+	result.Errors = kept
+}
+
+// matrixWithoutJobMatrix returns the ID of the job e is in when e is about
+// matrix.property and the job has no matrix, or "".
+func matrixWithoutJobMatrix(root *yaml.Node, lines []string, e LintError, property string) string {
+	if e.Line < 1 || e.Line > len(lines) || e.Column < 1 || e.Column > len(lines[e.Line-1]) {
+		return ""
+	}
+	expr := strings.ToLower(strings.Join(strings.Fields(lines[e.Line-1][e.Column-1:]), ""))
+	if !strings.HasPrefix(expr, "matrix."+strings.ToLower(property)) {
+		return ""
+	}
+	_, job := yamlPathAt(root, e.Line, e.Column)
+	if job == "" {
+		return ""
+	}
+	if mappingValue(mappingValue(mappingValue(mappingValue(root, "jobs"), job), "strategy"), "matrix") != nil {
+		return ""
+	}
+	return job
+}
+
+// yamlPathAt returns the path of the value at line and column of the
+// workflow root, written as GitHub's documentation does, such as
+// jobs.<job_id>.steps[*].if, and the ID of the job it is in, if any.
+// Maps of names the workflow chooses, such as env and with, end the path.
+func yamlPathAt(root *yaml.Node, line, column int) (path, job string) {
+	var best *yaml.Node
+	var bestPath []string
+	var walk func(n *yaml.Node, p []string)
+	walk = func(n *yaml.Node, p []string) {
+		switch n.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				walk(n.Content[i+1], append(p[:len(p):len(p)], n.Content[i].Value))
+			}
+		case yaml.SequenceNode:
+			for _, c := range n.Content {
+				walk(c, append(p[:len(p):len(p)], "[*]"))
+			}
+		case yaml.ScalarNode:
+			before := n.Line < line || (n.Line == line && n.Column <= column)
+			if before && (best == nil || n.Line > best.Line || (n.Line == best.Line && n.Column > best.Column)) {
+				best, bestPath = n, p
+			}
+		}
+	}
+	walk(root, nil)
+	if best == nil {
+		return "", ""
+	}
+
+	var out []string
+	for i, k := range bestPath {
+		var prev string
+		if i > 0 {
+			prev = bestPath[i-1]
+		}
+		switch {
+		case i == 1 && prev == "jobs":
+			job, k = k, "<job_id>"
+		case k == "[*]":
+			if prev == "steps" {
+				out[len(out)-1] += k
+			}
+			continue
+		case i >= 2 && bestPath[i-2] == "workflow_call" && (prev == "inputs" || prev == "outputs" || prev == "secrets"):
+			k = "<" + strings.TrimSuffix(prev, "s") + "_id>"
+		case prev == "services":
+			k = "<service_id>"
+		case prev == "env" || prev == "with" || prev == "outputs" || prev == "secrets" || prev == "matrix":
+			return strings.Join(out, "."), job
+		}
+		out = append(out, k)
+	}
+	return strings.Join(out, "."), job
+}
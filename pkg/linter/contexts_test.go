@@ -0,0 +1,61 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainContexts(t *testing.T) {
+	src := `on:
+  workflow_call:
+    inputs:
+      region:
+        type: string
+        default: ${{ env.REGION }}
+env:
+  OS: ${{ runner.os }}
+jobs:
+  deploy:
+    if: secrets.TOKEN != ''
+    runs-on: ${{ env.RUNNER }}
+    timeout-minutes: ${{ steps.setup.outputs.minutes }}
+    steps:
+      - if: ${{ secrets.TOKEN }}
+        run: echo ${{ matrix.os }}
+`
+	result, err := New(Options{}).Lint(t.Context(), Input{Content: []byte(src)})
+	require.NoError(t, err)
+	for _, e := range result.Errors {
+		assert.NotEqual(t, KindContextAvailability, e.Kind, "actionlint's findings are kept without the style pack")
+	}
+
+	result, err = New(Options{Rules: rules.Config{Packs: []string{"style"}}}).Lint(t.Context(), Input{Content: []byte(src)})
+	require.NoError(t, err)
+
+	var found []LintError
+	for _, e := range result.Errors {
+		if e.Kind == KindContextAvailability {
+			found = append(found, e)
+		}
+		assert.NotContains(t, e.Message, "object type {}", "properties of contexts already reported are dropped")
+	}
+	require.Len(t, found, 7)
+	for _, e := range found {
+		assert.Equal(t, SeverityError, e.Severity)
+	}
+
+	assert.Equal(t, 6, found[0].Line)
+	assert.Contains(t, found[0].Message, `context "env" is not available in on.workflow_call.inputs.<input_id>.default, which can only use "github", "inputs", "vars". use vars or inputs instead. see https://`)
+	assert.Contains(t, found[1].Message, `context "runner" is not available in env, which can only use`)
+	assert.Contains(t, found[2].Message, `context "secrets" is not available in jobs.<job_id>.if, which can only use "github", "inputs", "needs", "vars". check the secret in a step of an earlier job`)
+	assert.Contains(t, found[3].Message, `context "env" is not available in jobs.<job_id>.runs-on`)
+	assert.Contains(t, found[3].Message, "env is only set for steps")
+	assert.Contains(t, found[4].Message, `context "steps" is not available in jobs.<job_id>.timeout-minutes`)
+	assert.Contains(t, found[5].Message, `context "secrets" is not available in jobs.<job_id>.steps[*].if`)
+	assert.Contains(t, found[5].Message, "check env.HAS_TOKEN")
+	assert.Equal(t, 16, found[6].Line)
+	assert.Contains(t, found[6].Message, `matrix.os is not available, since job "deploy" has no strategy.matrix`)
+}
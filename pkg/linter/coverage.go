@@ -0,0 +1,270 @@
+package linter
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// codeEvents are the events that run workflows for changes to the code,
+// whose paths filters decide which directories a workflow covers.
+var codeEvents = []string{"push", "pull_request"}
+
+// expectedEvents are the events a repository's workflows are expected to
+// listen to, and what goes unchecked without them.
+var expectedEvents = []struct {
+	name, reason string
+}{
+	{"pull_request", "changes are not checked before they are merged"},
+	{"push", "branches are not checked after changes are pushed or merged"},
+}
+
+// language is a language found in a repository by the extensions of its
+// files, and the setup actions and commands of a workflow that builds or
+// tests it.
+type language struct {
+	name       string
+	extensions []string
+	actions    []string
+	commands   *regexp.Regexp
+}
+
+// languages are the languages CheckCoverage looks for. Markup, data and
+// shell scripts are left out, since they are seldom built or tested.
+var languages = []language{
+	{"Go", []string{".go"}, []string{"actions/setup-go", "golangci/golangci-lint-action", "goreleaser/goreleaser-action"}, regexp.MustCompile(`\bgo (build|test|vet|install|run)\b|\bgolangci-lint\b|\bgoreleaser\b`)},
+	{"Python", []string{".py"}, []string{"actions/setup-python", "astral-sh/setup-uv", "snok/install-poetry"}, regexp.MustCompile(`\b(pytest|tox|nox|poetry|uv|pip|flake8|ruff|mypy)\b|\bpython3? -m\b`)},
+	{"JavaScript", []string{".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx"}, []string{"actions/setup-node", "pnpm/action-setup", "oven-sh/setup-bun"}, regexp.MustCompile(`\b(npm|npx|yarn|pnpm|bun|node|deno)\b`)},
+	{"Rust", []string{".rs"}, []string{"dtolnay/rust-toolchain", "actions-rs/toolchain", "actions-rust-lang/setup-rust-toolchain"}, regexp.MustCompile(`\bcargo\b`)},
+	{"Java", []string{".java", ".kt", ".kts", ".scala"}, []string{"actions/setup-java", "gradle/actions/setup-gradle", "gradle/gradle-build-action"}, regexp.MustCompile(`\b(mvn|mvnw|gradle|gradlew|sbt)\b`)},
+	{"Ruby", []string{".rb"}, []string{"ruby/setup-ruby"}, regexp.MustCompile(`\b(bundle|rake|rspec|rubocop)\b`)},
+	{"C#", []string{".cs", ".fs", ".vb"}, []string{"actions/setup-dotnet"}, regexp.MustCompile(`\bdotnet\b|\bmsbuild\b`)},
+	{"PHP", []string{".php"}, []string{"shivammathur/setup-php"}, regexp.MustCompile(`\b(composer|phpunit|phpstan)\b`)},
+	{"Swift", []string{".swift"}, []string{"swift-actions/setup-swift", "maxim-lobanov/setup-xcode"}, regexp.MustCompile(`\bswift (build|test)\b|\bxcodebuild\b`)},
+	{"C/C++", []string{".c", ".cc", ".cpp", ".cxx", ".h", ".hpp"}, []string{"lukka/run-cmake", "ilammy/msvc-dev-cmd"}, regexp.MustCompile(`\b(make|cmake|ninja|meson|bazel|gcc|clang)\b`)},
+}
+
+// Coverage is what CheckCoverage finds of the areas of a repository that
+// no workflow checks.
+type Coverage struct {
+	Directories   []DirectoryCoverage `json:"directories"`
+	Languages     []LanguageCoverage  `json:"languages"`
+	MissingEvents []MissingEvent      `json:"missing_events"`
+}
+
+// DirectoryCoverage is a top-level directory of the repository and the
+// workflows that run on push or pull_request for changes in it.
+type DirectoryCoverage struct {
+	Path      string   `json:"path"`
+	Covered   bool     `json:"covered"`
+	Workflows []string `json:"workflows"`
+}
+
+// LanguageCoverage is a language with files in the repository and the
+// workflows that set up its toolchain or run its build and test commands.
+type LanguageCoverage struct {
+	Language  string   `json:"language"`
+	Files     int      `json:"files"`
+	Covered   bool     `json:"covered"`
+	Workflows []string `json:"workflows"`
+}
+
+// MissingEvent is an expected event no workflow listens to.
+type MissingEvent struct {
+	Event  string `json:"event"`
+	Reason string `json:"reason"`
+}
+
+// coverageWorkflow is a workflow of the repository, as CheckCoverage reads
+// it.
+type coverageWorkflow struct {
+	path     string
+	workflow *actionlint.Workflow
+}
+
+// CheckCoverage reports, for the repository at root, the top-level
+// directories no workflow runs for on push or pull_request, given their
+// paths filters; the languages of its files no workflow builds or tests;
+// and the expected events no workflow listens to. Hidden directories and
+// those skipped by FindMisplacedWorkflows are left out. Workflows that
+// cannot be parsed cover nothing.
+func CheckCoverage(root string) (*Coverage, error) {
+	files, err := FindWorkflowFiles(filepath.Join(root, WorkflowsDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	workflows := make([]coverageWorkflow, 0, len(files))
+	for _, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		if content, err = normalizeEncoding(content); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		w, _ := actionlint.Parse(content)
+		if w != nil {
+			workflows = append(workflows, coverageWorkflow{filepath.ToSlash(filepath.Join(WorkflowsDir, filepath.Base(path))), w})
+		}
+	}
+
+	dirs, counts, err := repositoryFiles(root)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &Coverage{Directories: []DirectoryCoverage{}, Languages: []LanguageCoverage{}, MissingEvents: []MissingEvent{}}
+	names := make([]string, 0, len(dirs))
+	for name := range dirs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d := DirectoryCoverage{Path: name, Workflows: []string{}}
+		for _, w := range workflows {
+			if w.covers(dirs[name]) {
+				d.Workflows = append(d.Workflows, w.path)
+			}
+		}
+		d.Covered = len(d.Workflows) > 0
+		out.Directories = append(out.Directories, d)
+	}
+
+	for _, lang := range languages {
+		if counts[lang.name] == 0 {
+			continue
+		}
+		l := LanguageCoverage{Language: lang.name, Files: counts[lang.name], Workflows: []string{}}
+		for _, w := range workflows {
+			if w.builds(lang) {
+				l.Workflows = append(l.Workflows, w.path)
+			}
+		}
+		l.Covered = len(l.Workflows) > 0
+		out.Languages = append(out.Languages, l)
+	}
+	sort.SliceStable(out.Languages, func(i, j int) bool {
+		return out.Languages[i].Files > out.Languages[j].Files
+	})
+
+	for _, ev := range expectedEvents {
+		if !slices.ContainsFunc(workflows, func(w coverageWorkflow) bool { return w.event(ev.name) != nil }) {
+			out.MissingEvents = append(out.MissingEvents, MissingEvent{Event: ev.name, Reason: ev.reason})
+		}
+	}
+	return out, nil
+}
+
+// repositoryFiles walks the repository at root and returns the files of
+// each top-level directory, as slash-separated paths relative to root, and
+// how many files of each language it has.
+func repositoryFiles(root string) (map[string][]string, map[string]int, error) {
+	dirs := map[string][]string{}
+	counts := map[string]int{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") || skippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			if !strings.Contains(rel, "/") {
+				dirs[rel] = []string{}
+			}
+			return nil
+		}
+		if top, _, ok := strings.Cut(rel, "/"); ok {
+			dirs[top] = append(dirs[top], rel)
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		for _, lang := range languages {
+			if slices.Contains(lang.extensions, ext) {
+				counts[lang.name]++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read repository: %w", err)
+	}
+	return dirs, counts, nil
+}
+
+// event returns the webhook event of w called name, or nil when w does not
+// listen to it.
+func (w coverageWorkflow) event(name string) *actionlint.WebhookEvent {
+	for _, e := range w.workflow.On {
+		if hook, ok := e.(*actionlint.WebhookEvent); ok && strings.EqualFold(hook.EventName(), name) {
+			return hook
+		}
+	}
+	return nil
+}
+
+// covers reports whether w runs on push or pull_request for a change to
+// one of files. Filters that cannot be evaluated are taken to match, and
+// an empty directory is covered by workflows without paths filters only.
+func (w coverageWorkflow) covers(files []string) bool {
+	for _, name := range codeEvents {
+		hook := w.event(name)
+		if hook == nil {
+			continue
+		}
+		paths, ignore := hook.Paths, hook.PathsIgnore
+		if paths.IsEmpty() && ignore.IsEmpty() {
+			return true
+		}
+		for _, f := range files {
+			if !paths.IsEmpty() {
+				if ok, err := matchFilter(filterValues(paths), f); ok || err != nil {
+					return true
+				}
+				continue
+			}
+			if ok, err := matchFilter(filterValues(ignore), f); !ok || err != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// builds reports whether a step of w sets up the toolchain of lang or runs
+// one of its commands.
+func (w coverageWorkflow) builds(lang language) bool {
+	for _, job := range w.workflow.Jobs {
+		for _, step := range job.Steps {
+			switch exec := step.Exec.(type) {
+			case *actionlint.ExecAction:
+				if exec.Uses == nil {
+					continue
+				}
+				action, _, _ := strings.Cut(exec.Uses.Value, "@")
+				if slices.Contains(lang.actions, strings.ToLower(action)) {
+					return true
+				}
+			case *actionlint.ExecRun:
+				if exec.Run != nil && lang.commands.MatchString(exec.Run.Value) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
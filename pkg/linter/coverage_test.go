@@ -0,0 +1,62 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCoverage(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".github/workflows/go.yml":   "on:\n  push:\n    paths: ['cmd/**', 'internal/**', go.mod]\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n      - uses: actions/setup-go@v5\n      - run: go test ./...\n",
+		".github/workflows/docs.yml": "on:\n  workflow_dispatch:\n  push:\n    paths-ignore: ['cmd/**', 'internal/**', 'web/**', 'scripts/**']\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo docs\n",
+		"go.mod":                     "module example.com/app\n",
+		"cmd/app/main.go":            "package main\n",
+		"internal/util/util.go":      "package util\n",
+		"web/src/index.ts":           "export {}\n",
+		"web/src/app.tsx":            "export {}\n",
+		"docs/index.md":              "# Docs\n",
+		"scripts/release.py":         "print('hi')\n",
+		"node_modules/x/index.js":    "module.exports = {}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(root, "empty"), 0755))
+
+	coverage, err := CheckCoverage(root)
+	require.NoError(t, err)
+
+	goWorkflow, docsWorkflow := ".github/workflows/go.yml", ".github/workflows/docs.yml"
+	assert.Equal(t, []DirectoryCoverage{
+		{Path: "cmd", Covered: true, Workflows: []string{goWorkflow}},
+		{Path: "docs", Covered: true, Workflows: []string{docsWorkflow}},
+		{Path: "empty", Covered: false, Workflows: []string{}},
+		{Path: "internal", Covered: true, Workflows: []string{goWorkflow}},
+		{Path: "scripts", Covered: false, Workflows: []string{}},
+		{Path: "web", Covered: false, Workflows: []string{}},
+	}, coverage.Directories, "hidden and vendored directories are left out")
+
+	assert.Equal(t, []LanguageCoverage{
+		{Language: "Go", Files: 2, Covered: true, Workflows: []string{goWorkflow}},
+		{Language: "JavaScript", Files: 2, Covered: false, Workflows: []string{}},
+		{Language: "Python", Files: 1, Covered: false, Workflows: []string{}},
+	}, coverage.Languages)
+
+	assert.Equal(t, []MissingEvent{{Event: "pull_request", Reason: "changes are not checked before they are merged"}}, coverage.MissingEvents)
+
+	// Without workflows nothing is covered
+	empty := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(empty, "main.go"), []byte("package main\n"), 0644))
+	coverage, err = CheckCoverage(empty)
+	require.NoError(t, err)
+	assert.Empty(t, coverage.Directories)
+	assert.False(t, coverage.Languages[0].Covered)
+	assert.Len(t, coverage.MissingEvents, 2)
+}
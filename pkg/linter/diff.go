@@ -0,0 +1,126 @@
+package linter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around changes.
+const diffContext = 3
+
+// diffOp is one line of an edit script. a and b are the indexes of the
+// line in the old and new text where the operation applies.
+type diffOp struct {
+	kind byte
+	line string
+	a, b int
+}
+
+// UnifiedDiff returns the changes from before to after as a unified diff
+// of the file name, or "" when they are equal. It is meant for
+// workflow-sized inputs: the comparison takes time proportional to the
+// product of the line counts.
+func UnifiedDiff(name string, before, after []byte) string {
+	if string(before) == string(after) {
+		return ""
+	}
+	ops := diffLines(splitLines(string(before)), splitLines(string(after)))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", name, name)
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j
+			} else if j-end > 2*diffContext {
+				break
+			}
+		}
+		start := max(i-diffContext, 0)
+		stop := min(end+diffContext+1, len(ops))
+		writeHunk(&b, ops[start:stop])
+		i = stop
+	}
+	return b.String()
+}
+
+func writeHunk(b *strings.Builder, ops []diffOp) {
+	var oldLen, newLen int
+	for _, op := range ops {
+		if op.kind != '+' {
+			oldLen++
+		}
+		if op.kind != '-' {
+			newLen++
+		}
+	}
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(ops[0].a, oldLen), hunkRange(ops[0].b, newLen))
+	for _, op := range ops {
+		b.WriteByte(op.kind)
+		b.WriteString(op.line)
+		if !strings.HasSuffix(op.line, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the start and length of a hunk. An empty range starts
+// at the line before it.
+func hunkRange(start, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if n == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
+
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes an edit script from a to b from their longest common
+// subsequence.
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i], i, j})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', a[i], i, j})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j], i, j})
+			j++
+		}
+	}
+	return ops
+}
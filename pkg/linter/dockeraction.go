@@ -0,0 +1,164 @@
+package linter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/rhysd/actionlint"
+	"gopkg.in/yaml.v3"
+)
+
+// KindDockerAction is the kind of findings about local Docker container
+// actions that cannot run as declared.
+const KindDockerAction = rules.KindDockerAction
+
+// actionInputRef matches reads of an action's inputs in its args and env.
+var actionInputRef = regexp.MustCompile(`(?i)(?:^|[^\w.-])inputs\s*\.\s*([\w-]+)`)
+
+// dockerfileInstructions returns the instructions of the last stage of a
+// Dockerfile, upper-cased, with continuation lines joined.
+func dockerfileInstructions(content []byte) []string {
+	var instructions []string
+	var current strings.Builder
+	sc := bufio.NewScanner(bytes.NewReader(content))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if current.Len() == 0 && (line == "" || strings.HasPrefix(line, "#")) {
+			continue
+		}
+		if cont, ok := strings.CutSuffix(line, "\\"); ok {
+			current.WriteString(cont + " ")
+			continue
+		}
+		current.WriteString(line)
+		fields := strings.Fields(current.String())
+		current.Reset()
+		if len(fields) == 0 {
+			continue
+		}
+		keyword := strings.ToUpper(fields[0])
+		if keyword == "FROM" {
+			instructions = instructions[:0]
+		}
+		instructions = append(instructions, keyword)
+	}
+	return instructions
+}
+
+// dockerActionProblems returns what keeps the local action in dir from
+// running, when it is a Docker container action built from a Dockerfile.
+// Paths in the messages are relative to root.
+func dockerActionProblems(root, dir string) []string {
+	var file string
+	var data []byte
+	for _, name := range []string{"action.yml", "action.yaml"} {
+		var err error
+		if data, err = os.ReadFile(filepath.Join(dir, name)); err == nil {
+			file = filepath.Join(dir, name)
+			break
+		}
+	}
+	if file == "" {
+		return nil // actionlint reports missing local actions
+	}
+	var meta actionlint.ActionMetadata
+	if err := yaml.Unmarshal(data, &meta); err != nil || meta.Runs.Using != "docker" {
+		return nil
+	}
+	action := relativeTo(root, file)
+
+	var problems []string
+	if image := meta.Runs.Image; image != "" && !strings.HasPrefix(image, "docker://") {
+		dockerfile := filepath.Join(dir, filepath.FromSlash(image))
+		content, err := os.ReadFile(dockerfile)
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("%s builds its image from %s, which does not exist", action, relativeTo(root, dockerfile)))
+		case meta.Runs.Entrypoint == "":
+			if !slices.Contains(dockerfileInstructions(content), "ENTRYPOINT") {
+				problems = append(problems, fmt.Sprintf("%s sets no runs.entrypoint and %s sets no ENTRYPOINT in its final stage, so the action's args run as the command unless the base image sets one", action, relativeTo(root, dockerfile)))
+			}
+		}
+	}
+
+	var values []string
+	for _, a := range meta.Runs.Args {
+		if s, ok := a.(string); ok {
+			values = append(values, s)
+		}
+	}
+	names := make([]string, 0, len(meta.Runs.Env))
+	for name := range meta.Runs.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if s, ok := meta.Runs.Env[name].(string); ok {
+			values = append(values, s)
+		}
+	}
+	seen := map[string]bool{}
+	for _, v := range values {
+		for _, m := range actionInputRef.FindAllStringSubmatch(v, -1) {
+			input := strings.ToLower(m[1])
+			if _, ok := meta.Inputs[input]; ok || seen[input] {
+				continue
+			}
+			seen[input] = true
+			problems = append(problems, fmt.Sprintf("%s passes inputs.%s to the container, but declares no input %q, so it is always empty", action, m[1], m[1]))
+		}
+	}
+	return problems
+}
+
+// dockerActionFindings reports the steps of the workflow at path that use a
+// local Docker container action whose Dockerfile is missing, that has no
+// entrypoint, or whose args and env read undeclared inputs.
+func dockerActionFindings(path string, content []byte) []LintError {
+	w, _ := actionlint.Parse(content)
+	if w == nil {
+		return nil
+	}
+	root := repositoryRoot(path)
+
+	ids := make([]string, 0, len(w.Jobs))
+	for id := range w.Jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	problems := map[string][]string{}
+	var found []LintError
+	for _, id := range ids {
+		for _, step := range w.Jobs[id].Steps {
+			exec, ok := step.Exec.(*actionlint.ExecAction)
+			if !ok || exec.Uses == nil || !strings.HasPrefix(exec.Uses.Value, "./") {
+				continue
+			}
+			dir := filepath.Join(root, filepath.FromSlash(exec.Uses.Value))
+			p, ok := problems[dir]
+			if !ok {
+				p = dockerActionProblems(root, dir)
+				problems[dir] = p
+			}
+			for _, msg := range p {
+				found = append(found, LintError{
+					Message:  msg,
+					Line:     exec.Uses.Pos.Line,
+					Column:   exec.Uses.Pos.Col,
+					Kind:     KindDockerAction,
+					Severity: SeverityForKind(KindDockerAction),
+				})
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,76 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerfileInstructions(t *testing.T) {
+	dockerfile := `FROM golang:1.24 AS build
+ENTRYPOINT ["/bin/false"]
+
+# Final stage
+FROM alpine
+RUN apk add \
+    git
+entrypoint ["/run.sh"]
+`
+	assert.Equal(t, []string{"FROM", "RUN", "ENTRYPOINT"}, dockerfileInstructions([]byte(dockerfile)))
+}
+
+func TestDockerActionFindings(t *testing.T) {
+	repo := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	write(".github/actions/missing/action.yml", "name: missing\nruns:\n  using: docker\n  image: Dockerfile\n")
+	write(".github/actions/noentry/action.yml", `name: noentry
+inputs:
+  target:
+    description: what to build
+runs:
+  using: docker
+  image: Dockerfile
+  args: ["${{ inputs.target }}", "${{ inputs.mode }}"]
+  env:
+    LEVEL: ${{ inputs.level }}
+`)
+	write(".github/actions/noentry/Dockerfile", "FROM golang:1.24 AS build\nENTRYPOINT [\"/bin/build\"]\nFROM alpine\nCOPY run.sh /run.sh\n")
+	write(".github/actions/ok/action.yml", "name: ok\nruns:\n  using: docker\n  image: Dockerfile\n")
+	write(".github/actions/ok/Dockerfile", "FROM alpine\nENTRYPOINT [\"/run.sh\"]\n")
+	write(".github/actions/remote/action.yml", "name: remote\nruns:\n  using: docker\n  image: docker://alpine:3\n")
+
+	workflow := `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: ./.github/actions/missing
+      - uses: ./.github/actions/noentry
+      - uses: ./.github/actions/ok
+      - uses: ./.github/actions/remote
+      - uses: ./.github/actions/missing
+`
+	write(".github/workflows/ci.yml", workflow)
+
+	findings := dockerActionFindings(filepath.Join(repo, ".github", "workflows", "ci.yml"), []byte(workflow))
+	require.Len(t, findings, 5)
+	assert.Equal(t, LintError{
+		Message:  ".github/actions/missing/action.yml builds its image from .github/actions/missing/Dockerfile, which does not exist",
+		Line:     6,
+		Column:   15,
+		Kind:     KindDockerAction,
+		Severity: SeverityWarning,
+	}, findings[0])
+	assert.Equal(t, ".github/actions/noentry/action.yml sets no runs.entrypoint and .github/actions/noentry/Dockerfile sets no ENTRYPOINT in its final stage, so the action's args run as the command unless the base image sets one", findings[1].Message)
+	assert.Equal(t, `.github/actions/noentry/action.yml passes inputs.mode to the container, but declares no input "mode", so it is always empty`, findings[2].Message)
+	assert.Equal(t, `.github/actions/noentry/action.yml passes inputs.level to the container, but declares no input "level", so it is always empty`, findings[3].Message)
+	assert.Equal(t, 7, findings[3].Line)
+	assert.Equal(t, 10, findings[4].Line)
+}
@@ -0,0 +1,132 @@
+package linter
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"gopkg.in/yaml.v3"
+)
+
+// KindNotWorkflow is the kind reported when the input is a recognizable
+// YAML file that is not a workflow.
+const KindNotWorkflow = rules.KindNotWorkflow
+
+// KindMultiDocument is the kind of the warning reported for files holding
+// more than one YAML document.
+const KindMultiDocument = rules.KindMultiDocument
+
+// documentSeparator matches a YAML document start marker on its own line.
+var documentSeparator = regexp.MustCompile(`^---[ \t]*(#.*)?\r?$`)
+
+// document is one YAML document of a file. Line is the 1-based line in the
+// file where the document's content starts.
+type document struct {
+	Line    int
+	Content []byte
+}
+
+// splitDocuments splits content on "---" separator lines. Documents that
+// hold nothing but blank lines and comments are dropped, so a file with a
+// single leading "---" is one document.
+func splitDocuments(content []byte) []document {
+	lines := bytes.SplitAfter(content, []byte("\n"))
+
+	var docs []document
+	start := 0
+	flush := func(end int) {
+		doc := bytes.Join(lines[start:end], nil)
+		if hasYAMLContent(doc) {
+			docs = append(docs, document{Line: start + 1, Content: doc})
+		}
+	}
+
+	for i, line := range lines {
+		if documentSeparator.Match(bytes.TrimSuffix(line, []byte("\n"))) {
+			flush(i)
+			start = i + 1
+		}
+	}
+	flush(len(lines))
+
+	return docs
+}
+
+func hasYAMLContent(doc []byte) bool {
+	for _, line := range bytes.Split(doc, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 && line[0] != '#' {
+			return true
+		}
+	}
+	return false
+}
+
+// DocumentKind identifies what a YAML document appears to be.
+type DocumentKind string
+
+// Document kinds recognized by DetectDocumentKind.
+const (
+	DocumentUnknown    DocumentKind = "unknown"
+	DocumentWorkflow   DocumentKind = "workflow"
+	DocumentAction     DocumentKind = "action"
+	DocumentDependabot DocumentKind = "dependabot"
+	DocumentCompose    DocumentKind = "docker-compose"
+)
+
+// DetectDocumentKind guesses the kind of YAML document from its top-level
+// keys. Content that does not parse, or whose shape is not recognized, is
+// DocumentUnknown so that actionlint gets to report on it.
+func DetectDocumentKind(content []byte) DocumentKind {
+	var doc map[string]any
+	if err := yaml.Unmarshal(content, &doc); err != nil || doc == nil {
+		return DocumentUnknown
+	}
+
+	has := func(key string) bool {
+		_, ok := doc[key]
+		return ok
+	}
+
+	switch {
+	case has("jobs") || has("on"):
+		return DocumentWorkflow
+	case has("runs"):
+		return DocumentAction
+	case has("updates") && has("version"):
+		return DocumentDependabot
+	case has("services"):
+		return DocumentCompose
+	default:
+		return DocumentUnknown
+	}
+}
+
+// notWorkflowResult reports that path holds a kind of document actionlint
+// cannot check, in place of actionlint's misleading key errors.
+func notWorkflowResult(path string, kind DocumentKind) *LintResult {
+	var msg string
+	switch kind {
+	case DocumentAction:
+		msg = "this looks like an action metadata file (action.yml), not a workflow; actionlint checks workflows only, so validate it against the action metadata schema instead"
+	case DocumentDependabot:
+		msg = "this looks like a Dependabot configuration (dependabot.yml), not a workflow; validate it against the Dependabot configuration schema instead"
+	case DocumentCompose:
+		msg = "this looks like a Docker Compose file, not a workflow; validate it with `docker compose config` instead"
+	default:
+		msg = fmt.Sprintf("this looks like a %s file, not a workflow", kind)
+	}
+
+	return &LintResult{
+		Errors: []LintError{{
+			Message:  msg,
+			Line:     1,
+			Column:   1,
+			Kind:     KindNotWorkflow,
+			Severity: SeverityForKind(KindNotWorkflow),
+		}},
+		Valid:    false,
+		FilePath: path,
+	}
+}
@@ -0,0 +1,115 @@
+package linter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectDocumentKind(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    DocumentKind
+	}{
+		{"workflow", validWorkflow, DocumentWorkflow},
+		{"workflow without jobs", "on: push\n", DocumentWorkflow},
+		{"action", "name: My Action\ndescription: Does things\nruns:\n  using: node20\n  main: index.js\n", DocumentAction},
+		{"dependabot", "version: 2\nupdates:\n  - package-ecosystem: gomod\n    directory: /\n", DocumentDependabot},
+		{"compose", "services:\n  web:\n    image: nginx\n", DocumentCompose},
+		{"unrecognized mapping", "foo: bar\n", DocumentUnknown},
+		{"not a mapping", "- a\n- b\n", DocumentUnknown},
+		{"invalid yaml", "key: [unclosed", DocumentUnknown},
+		{"empty", "", DocumentUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DetectDocumentKind([]byte(tt.content)))
+		})
+	}
+}
+
+func TestLint_NotWorkflow(t *testing.T) {
+	action := []byte("name: My Action\nruns:\n  using: composite\n  steps: []\n")
+
+	// Without the style pack, actionlint's findings are reported as is
+	result, err := New(Options{}).Lint(context.Background(), Input{Path: "action.yml", Content: action})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	for _, e := range result.Errors {
+		assert.NotEqual(t, KindNotWorkflow, e.Kind)
+	}
+
+	result, err = New(Options{Rules: rules.Config{Packs: []string{"style"}}}).Lint(context.Background(), Input{Path: "action.yml", Content: action})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, KindNotWorkflow, result.Errors[0].Kind)
+	assert.Equal(t, SeverityError, result.Errors[0].Severity)
+	assert.Contains(t, result.Errors[0].Message, "action metadata")
+}
+
+func TestSplitDocuments(t *testing.T) {
+	docs := splitDocuments([]byte("---\n# leading comment\non: push\n--- # second\nfoo: bar\n---\n\n"))
+	require.Len(t, docs, 2)
+	assert.Equal(t, 2, docs[0].Line)
+	assert.Equal(t, "# leading comment\non: push\n", string(docs[0].Content))
+	assert.Equal(t, 5, docs[1].Line)
+	assert.Equal(t, "foo: bar\n", string(docs[1].Content))
+
+	// A separator inside a block scalar must be indented, so it is not split
+	assert.Len(t, splitDocuments([]byte("on: push\njobs:\n  a:\n    steps:\n      - run: |\n          ---\n")), 1)
+}
+
+func TestLint_MultiDocument(t *testing.T) {
+	content := validWorkflow + "\n---\n" + invalidWorkflow
+
+	// actionlint, like GitHub, only reads the first document
+	result, err := New(Options{}).Lint(context.Background(), Input{Content: []byte(content)})
+	require.NoError(t, err)
+	assert.True(t, result.Valid, "%v", result.Errors)
+
+	result, err = New(Options{Rules: rules.Config{Packs: []string{"style"}}}).Lint(context.Background(), Input{Content: []byte(content)})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.GreaterOrEqual(t, len(result.Errors), 2)
+
+	warning := result.Errors[0]
+	assert.Equal(t, KindMultiDocument, warning.Kind)
+	assert.Equal(t, SeverityWarning, warning.Severity)
+	assert.Equal(t, 9, warning.Line)
+	assert.Contains(t, warning.Message, "2 YAML documents")
+
+	// Findings in the second document carry its index and file line numbers
+	finding := result.Errors[1]
+	assert.Contains(t, finding.Message, "document 2: ")
+	assert.Contains(t, finding.Message, "nonexistent")
+	assert.Equal(t, 13, finding.Line)
+}
+
+func TestLint_MultiDocumentCrossFile(t *testing.T) {
+	workflows := filepath.Join(t.TempDir(), ".github", "workflows")
+	require.NoError(t, os.MkdirAll(workflows, 0755))
+	path := filepath.Join(workflows, "ci.yml")
+	require.NoError(t, os.WriteFile(path, []byte("---\n"+validWorkflow+"\n---\n"+invalidWorkflow), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workflows, "test.yml"), []byte(validWorkflow), 0644))
+
+	// The first document is checked against the other workflows
+	result, err := New(Options{Rules: rules.Config{Packs: []string{"style"}}}).Lint(context.Background(), Input{Path: path})
+	require.NoError(t, err)
+	var duplicates []LintError
+	for _, e := range result.Errors {
+		if e.Kind == KindDuplicateName {
+			duplicates = append(duplicates, e)
+		}
+	}
+	require.Len(t, duplicates, 1)
+	assert.Contains(t, duplicates[0].Message, "document 1: ")
+	assert.Equal(t, 2, duplicates[0].Line)
+}
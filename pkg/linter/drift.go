@@ -0,0 +1,298 @@
+package linter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// Kinds of deviation from a golden template.
+const (
+	DriftTrigger     = "removed-trigger"
+	DriftJob         = "removed-job"
+	DriftStep        = "removed-step"
+	DriftPermissions = "permissions"
+	DriftPin         = "unpinned-action"
+)
+
+// securityActions are actions whose removal weakens a workflow's security,
+// matched by prefix.
+var securityActions = []string{
+	"step-security/harden-runner",
+	"github/codeql-action/",
+	"actions/dependency-review-action",
+	"ossf/scorecard-action",
+	"aquasecurity/trivy-action",
+	"anchore/scan-action",
+	"snyk/actions/",
+	"gitleaks/gitleaks-action",
+	"trufflesecurity/trufflehog",
+	"sigstore/cosign-installer",
+	"actions/attest-build-provenance",
+}
+
+// permissionScopes are the scopes of the job token, which read-all and
+// write-all cover.
+var permissionScopes = []string{
+	"actions", "attestations", "checks", "contents", "deployments", "discussions", "id-token",
+	"issues", "packages", "pages", "pull-requests", "repository-projects", "security-events", "statuses",
+}
+
+// permissionLevels orders the access levels of a permission scope.
+var permissionLevels = map[string]int{"none": 0, "read": 1, "write": 2}
+
+// Deviation is a way a workflow departs from its golden template. Line is
+// the line in the workflow it concerns, or 0 when the workflow lacks what
+// the template has.
+type Deviation struct {
+	Kind     string `json:"kind"`
+	Job      string `json:"job,omitempty"`
+	Message  string `json:"message"`
+	Line     int    `json:"line,omitempty"`
+	Severity string `json:"severity"`
+}
+
+// TemplateDrift compares a workflow with the golden template it was
+// created from and reports semantic deviations: triggers, jobs and steps
+// the workflow dropped, permissions it widened, and actions it no longer
+// pins to a commit. Additions that do not widen permissions are not
+// deviations, and formatting and comments are ignored.
+func TemplateDrift(template, workflow []byte) ([]Deviation, error) {
+	tw, errs := actionlint.Parse(template)
+	if tw == nil || len(errs) > 0 {
+		return nil, fmt.Errorf("template cannot be parsed: %s", errs[0].Message)
+	}
+	w, errs := actionlint.Parse(workflow)
+	if w == nil || len(errs) > 0 {
+		return nil, fmt.Errorf("workflow cannot be parsed: %s", errs[0].Message)
+	}
+
+	var out []Deviation
+	events := map[string]bool{}
+	for _, e := range w.On {
+		events[e.EventName()] = true
+	}
+	for _, e := range tw.On {
+		if !events[e.EventName()] {
+			out = append(out, Deviation{
+				Kind:     DriftTrigger,
+				Message:  fmt.Sprintf("the template runs on %q, but the workflow does not", e.EventName()),
+				Severity: SeverityWarning,
+			})
+		}
+	}
+
+	out = append(out, permissionDrift("", tw.Permissions, w.Permissions)...)
+
+	ids := make([]string, 0, len(tw.Jobs))
+	for id := range tw.Jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		tj := tw.Jobs[id]
+		j, ok := w.Jobs[id]
+		if !ok {
+			out = append(out, Deviation{
+				Kind:     DriftJob,
+				Job:      tj.ID.Value,
+				Message:  fmt.Sprintf("job %q of the template is missing", tj.ID.Value),
+				Severity: SeverityWarning,
+			})
+			continue
+		}
+		tp, p := tj.Permissions, j.Permissions
+		if tp == nil {
+			tp = tw.Permissions
+		}
+		if p == nil {
+			p = w.Permissions
+		}
+		if tj.Permissions != nil || j.Permissions != nil {
+			out = append(out, permissionDrift(j.ID.Value, tp, p)...)
+		}
+		out = append(out, stepDrift(tj, j)...)
+	}
+	return out, nil
+}
+
+// permissionDrift reports the scopes to which perms give more access than
+// the template's perms. A nil block grants the repository defaults, which
+// are treated as write access to everything.
+func permissionDrift(job string, template, perms *actionlint.Permissions) []Deviation {
+	if template == nil {
+		return nil
+	}
+	where := "the workflow"
+	if job != "" {
+		where = fmt.Sprintf("job %q", job)
+	}
+	if perms == nil {
+		return []Deviation{{
+			Kind:     DriftPermissions,
+			Job:      job,
+			Message:  fmt.Sprintf("the template restricts the permissions of %s, but the workflow does not, so it gets the repository's default token permissions", where),
+			Severity: SeverityError,
+		}}
+	}
+
+	scopes := map[string]bool{}
+	if template.All != nil || perms.All != nil {
+		for _, s := range permissionScopes {
+			scopes[s] = true
+		}
+	}
+	for s := range template.Scopes {
+		scopes[s] = true
+	}
+	for s := range perms.Scopes {
+		scopes[s] = true
+	}
+	names := make([]string, 0, len(scopes))
+	for s := range scopes {
+		if permissionLevel(perms, s) > permissionLevel(template, s) {
+			names = append(names, s)
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return nil
+	}
+
+	if perms.All != nil {
+		return []Deviation{{
+			Kind:     DriftPermissions,
+			Job:      job,
+			Message:  fmt.Sprintf("%s grants %q, which gives more access than the template to %s", where, perms.All.Value, strings.Join(names, ", ")),
+			Line:     perms.All.Pos.Line,
+			Severity: SeverityError,
+		}}
+	}
+	out := make([]Deviation, 0, len(names))
+	for _, s := range names {
+		v := perms.Scopes[s].Value
+		out = append(out, Deviation{
+			Kind:     DriftPermissions,
+			Job:      job,
+			Message:  fmt.Sprintf("%s grants %q access to %q, more than the template", where, v.Value, s),
+			Line:     v.Pos.Line,
+			Severity: SeverityError,
+		})
+	}
+	return out
+}
+
+// permissionLevel returns the access p grants to scope. A nil block grants
+// the repository defaults, which are treated as write access.
+func permissionLevel(p *actionlint.Permissions, scope string) int {
+	if p == nil {
+		return permissionLevels["write"]
+	}
+	if p.All != nil {
+		switch p.All.Value {
+		case "write-all":
+			return permissionLevels["write"]
+		case "read-all":
+			return permissionLevels["read"]
+		}
+		return 0
+	}
+	if s, ok := p.Scopes[scope]; ok && s.Value != nil {
+		return permissionLevels[s.Value.Value]
+	}
+	return 0
+}
+
+// stepKey identifies a step across copies of a workflow: the action it
+// uses without its version, or its id or name.
+func stepKey(s *actionlint.Step) string {
+	if exec, ok := s.Exec.(*actionlint.ExecAction); ok && exec.Uses != nil {
+		name, _, _ := strings.Cut(exec.Uses.Value, "@")
+		return "uses:" + strings.ToLower(name)
+	}
+	if s.ID != nil {
+		return "id:" + s.ID.Value
+	}
+	if s.Name != nil {
+		return "name:" + s.Name.Value
+	}
+	return ""
+}
+
+// stepDrift reports the steps of the template's job the workflow's job
+// dropped, and actions the template pins to a commit but the workflow
+// does not.
+func stepDrift(template, job *actionlint.Job) []Deviation {
+	steps := map[string]*actionlint.Step{}
+	for _, s := range job.Steps {
+		if k := stepKey(s); k != "" {
+			steps[k] = s
+		}
+	}
+
+	var out []Deviation
+	for _, ts := range template.Steps {
+		k := stepKey(ts)
+		if k == "" {
+			continue
+		}
+		s, ok := steps[k]
+		texec, _ := ts.Exec.(*actionlint.ExecAction)
+		isAction := strings.HasPrefix(k, "uses:")
+		if !ok {
+			what, severity := "step "+describeTemplateStep(ts), SeverityWarning
+			if isAction && isSecurityAction(texec.Uses.Value) {
+				what, severity = "security "+what, SeverityError
+			}
+			out = append(out, Deviation{
+				Kind:     DriftStep,
+				Job:      job.ID.Value,
+				Message:  fmt.Sprintf("%s of the template is missing from job %q", what, job.ID.Value),
+				Severity: severity,
+			})
+			continue
+		}
+		if !isAction {
+			continue
+		}
+		exec := s.Exec.(*actionlint.ExecAction)
+		if _, ref, _ := strings.Cut(texec.Uses.Value, "@"); commitSHA.MatchString(ref) {
+			if _, ref, _ := strings.Cut(exec.Uses.Value, "@"); !commitSHA.MatchString(ref) {
+				out = append(out, Deviation{
+					Kind:     DriftPin,
+					Job:      job.ID.Value,
+					Message:  fmt.Sprintf("the template pins %q to a commit, but the workflow uses %q", texec.Uses.Value, exec.Uses.Value),
+					Line:     exec.Uses.Pos.Line,
+					Severity: SeverityWarning,
+				})
+			}
+		}
+	}
+	return out
+}
+
+// describeTemplateStep names a step of a template, to follow "step".
+func describeTemplateStep(s *actionlint.Step) string {
+	switch {
+	case s.Name != nil:
+		return fmt.Sprintf("%q", s.Name.Value)
+	case s.ID != nil:
+		return fmt.Sprintf("%q", s.ID.Value)
+	}
+	if exec, ok := s.Exec.(*actionlint.ExecAction); ok {
+		return fmt.Sprintf("using %q", exec.Uses.Value)
+	}
+	return ""
+}
+
+func isSecurityAction(uses string) bool {
+	name, _, _ := strings.Cut(strings.ToLower(uses), "@")
+	for _, a := range securityActions {
+		if strings.HasPrefix(name, a) {
+			return true
+		}
+	}
+	return false
+}
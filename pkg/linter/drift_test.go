@@ -0,0 +1,92 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const driftTemplate = `name: CI
+on:
+  push:
+  pull_request:
+permissions:
+  contents: read
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: step-security/harden-runner@v2
+      - uses: actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683
+      - name: Test
+        run: make test
+  scan:
+    runs-on: ubuntu-latest
+    permissions:
+      security-events: write
+    steps:
+      - uses: github/codeql-action/analyze@v3
+`
+
+func TestTemplateDrift(t *testing.T) {
+	workflow := `name: CI
+on:
+  push:
+permissions:
+  contents: write
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - name: Test
+        run: make test
+      - run: make lint
+`
+	deviations, err := TemplateDrift([]byte(driftTemplate), []byte(workflow))
+	require.NoError(t, err)
+	assert.Equal(t, []Deviation{
+		{Kind: DriftTrigger, Message: `the template runs on "pull_request", but the workflow does not`, Severity: SeverityWarning},
+		{Kind: DriftPermissions, Message: `the workflow grants "write" access to "contents", more than the template`, Line: 5, Severity: SeverityError},
+		{Kind: DriftStep, Job: "build", Message: `security step using "step-security/harden-runner@v2" of the template is missing from job "build"`, Severity: SeverityError},
+		{Kind: DriftPin, Job: "build", Message: `the template pins "actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683" to a commit, but the workflow uses "actions/checkout@v4"`, Line: 10, Severity: SeverityWarning},
+		{Kind: DriftJob, Job: "scan", Message: `job "scan" of the template is missing`, Severity: SeverityWarning},
+	}, deviations)
+}
+
+func TestTemplateDrift_Permissions(t *testing.T) {
+	workflow := `name: CI
+on: [push, pull_request]
+permissions: write-all
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: step-security/harden-runner@v3
+      - uses: actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683
+      - name: Test
+        run: make test
+  scan:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: github/codeql-action/analyze@v3
+`
+	deviations, err := TemplateDrift([]byte(driftTemplate), []byte(workflow))
+	require.NoError(t, err)
+	require.Len(t, deviations, 2)
+	assert.Equal(t, DriftPermissions, deviations[0].Kind)
+	assert.Equal(t, 3, deviations[0].Line)
+	assert.Contains(t, deviations[0].Message, `the workflow grants "write-all", which gives more access than the template to actions, attestations, checks, contents,`)
+	// The job the template restricts inherits write-all too
+	assert.Equal(t, "scan", deviations[1].Job)
+	assert.NotContains(t, deviations[1].Message, "security-events")
+
+	// Matching the template is not a deviation
+	deviations, err = TemplateDrift([]byte(driftTemplate), []byte(driftTemplate))
+	require.NoError(t, err)
+	assert.Empty(t, deviations)
+
+	_, err = TemplateDrift([]byte(driftTemplate), []byte("- not\n- a workflow\n"))
+	assert.ErrorContains(t, err, "workflow cannot be parsed")
+}
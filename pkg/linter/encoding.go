@@ -0,0 +1,76 @@
+package linter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// errOddUTF16 is returned for UTF-16 content with a trailing partial code unit.
+var errOddUTF16 = errors.New("invalid UTF-16 content: odd number of bytes")
+
+// normalizeEncoding converts content to UTF-8 without a byte order mark and
+// with LF line endings, which is what actionlint and the external linters
+// expect. UTF-16 is recognized by its byte order mark, or by the NUL bytes
+// of an ASCII first character when the mark is missing.
+//
+// Lines are unchanged and columns count characters of the decoded text, so
+// positions in findings match what an editor shows for the original file.
+func normalizeEncoding(content []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(content, bomUTF8):
+		content = content[len(bomUTF8):]
+	case bytes.HasPrefix(content, bomUTF16LE):
+		return decodeUTF16(content[len(bomUTF16LE):], binary.LittleEndian)
+	case bytes.HasPrefix(content, bomUTF16BE):
+		return decodeUTF16(content[len(bomUTF16BE):], binary.BigEndian)
+	case len(content) >= 2 && content[0] != 0 && content[1] == 0:
+		return decodeUTF16(content, binary.LittleEndian)
+	case len(content) >= 2 && content[0] == 0 && content[1] != 0:
+		return decodeUTF16(content, binary.BigEndian)
+	}
+
+	return bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n")), nil
+}
+
+func decodeUTF16(content []byte, order binary.ByteOrder) ([]byte, error) {
+	if len(content)%2 != 0 {
+		return nil, errOddUTF16
+	}
+
+	units := make([]uint16, len(content)/2)
+	for i := range units {
+		units[i] = order.Uint16(content[2*i:])
+	}
+
+	out := make([]byte, 0, len(units))
+	for _, r := range utf16.Decode(units) {
+		out = utf8.AppendRune(out, r)
+	}
+
+	return normalizeEncoding(out)
+}
+
+// keepLayout returns updated, an edit of the normalized content of
+// original, with the line endings, final newline and UTF-8 byte order mark
+// of original, so that writing it back only changes what was edited.
+func keepLayout(original, updated []byte) []byte {
+	if bytes.Contains(original, []byte("\r\n")) && !bytes.Contains(updated, []byte("\r\n")) {
+		updated = bytes.ReplaceAll(updated, []byte("\n"), []byte("\r\n"))
+	}
+	if len(original) > 0 && !bytes.HasSuffix(original, []byte("\n")) {
+		updated = bytes.TrimSuffix(bytes.TrimSuffix(updated, []byte("\n")), []byte("\r"))
+	}
+	if bytes.HasPrefix(original, bomUTF8) && !bytes.HasPrefix(updated, bomUTF8) {
+		updated = append(append([]byte{}, bomUTF8...), updated...)
+	}
+	return updated
+}
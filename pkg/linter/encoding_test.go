@@ -0,0 +1,84 @@
+package linter
+
+import (
+	"context"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeUTF16(s string, bigEndian bool) []byte {
+	var out []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		if bigEndian {
+			out = append(out, byte(u>>8), byte(u))
+		} else {
+			out = append(out, byte(u), byte(u>>8))
+		}
+	}
+	return out
+}
+
+func TestNormalizeEncoding(t *testing.T) {
+	want := "on: push\nname: café\n"
+	windows := "on: push\r\nname: café\r\n"
+
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{"utf-8", []byte(want)},
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, want...)},
+		{"crlf", []byte(windows)},
+		{"utf-16le bom", append([]byte{0xFF, 0xFE}, encodeUTF16(windows, false)...)},
+		{"utf-16be bom", append([]byte{0xFE, 0xFF}, encodeUTF16(windows, true)...)},
+		{"utf-16le without bom", encodeUTF16(want, false)},
+		{"utf-16be without bom", encodeUTF16(want, true)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeEncoding(tt.content)
+			require.NoError(t, err)
+			assert.Equal(t, want, string(got))
+		})
+	}
+
+	_, err := normalizeEncoding([]byte{0xFF, 0xFE, 'o'})
+	assert.ErrorIs(t, err, errOddUTF16)
+}
+
+func TestLint_WindowsEncodedWorkflow(t *testing.T) {
+	workflow := "foo: bar\r\non: push\r\njobs:\r\n  test:\r\n    runs-on: ubuntu-latest\r\n    steps:\r\n      - run: echo hi\r\n"
+	content := append([]byte{0xFF, 0xFE}, encodeUTF16(workflow, false)...)
+
+	result, err := New(Options{}).Lint(context.Background(), Input{Content: content})
+	require.NoError(t, err)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "syntax-check", result.Errors[0].Kind)
+	assert.Equal(t, 1, result.Errors[0].Line)
+	assert.Equal(t, 1, result.Errors[0].Column)
+}
+
+func TestKeepLayout(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		updated  string
+		want     string
+	}{
+		{"unix", "a: 1\nb: 2\n", "a: one\nb: 2\n", "a: one\nb: 2\n"},
+		{"windows", "a: 1\r\nb: 2\r\n", "a: one\nb: 2\n", "a: one\r\nb: 2\r\n"},
+		{"no final newline", "a: 1\nb: 2", "a: one\nb: 2\n", "a: one\nb: 2"},
+		{"windows without final newline", "a: 1\r\nb: 2", "a: one\nb: 2\n", "a: one\r\nb: 2"},
+		{"byte order mark", "\uFEFFa: 1\n", "a: one\n", "\uFEFFa: one\n"},
+		{"already windows", "a: 1\r\n", "a: one\r\n", "a: one\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, string(keepLayout([]byte(tt.original), []byte(tt.updated))))
+		})
+	}
+}
@@ -0,0 +1,493 @@
+package linter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindExternalLinter is the kind of findings about shellcheck and pyflakes
+// runs that did not complete, such as ones stopped by their timeout.
+const KindExternalLinter = "external-linter"
+
+// Limits of a single shellcheck or pyflakes run, used when Options leaves
+// them zero.
+const (
+	DefaultExternalTimeout     = 30 * time.Second
+	DefaultExternalOutputLimit = 1 << 20
+)
+
+// externalSlots bounds how many external linters run at once across every
+// lint, as actionlint does for its own runs.
+var externalSlots = make(chan struct{}, runtime.NumCPU())
+
+// errOutputLimit stops a run whose output outgrows its limit.
+var errOutputLimit = errors.New("output limit exceeded")
+
+// shellcheckExcludes are the checks actionlint disables because the
+// placeholders written over ${{ }} expressions trip them, or because they
+// look at the local environment rather than the runner's.
+const shellcheckExcludes = "SC1091,SC2194,SC2050,SC2154,SC2157,SC2043"
+
+// externalCommand is an external linter resolved to an executable and its
+// leading arguments, as actionlint accepts a command line in place of a
+// path, or to a WebAssembly module run in process.
+type externalCommand struct {
+	name   string
+	exe    string
+	args   []string
+	module *wasmModule
+}
+
+// resolveExternal looks cmd up, returning false when it cannot be found, in
+// which case the check is skipped as actionlint does.
+func resolveExternal(name, cmd string) (externalCommand, bool) {
+	if cmd == "" {
+		return externalCommand{}, false
+	}
+	if exe, err := exec.LookPath(cmd); err == nil {
+		return externalCommand{name: name, exe: exe}, true
+	}
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 {
+		return externalCommand{}, false
+	}
+	exe, err := exec.LookPath(fields[0])
+	if err != nil {
+		return externalCommand{}, false
+	}
+	return externalCommand{name: name, exe: exe, args: fields[1:]}, true
+}
+
+// externalLinters are the external linters a Linter runs, nil for those it
+// does not, and the reasons the ones it is configured with cannot run.
+type externalLinters struct {
+	shellcheck *externalCommand
+	pyflakes   *externalCommand
+	problems   []string
+}
+
+// externalLinters resolves the external linters of l. A shellcheck command
+// that is found takes precedence over a WebAssembly build, which is
+// ShellcheckWASM or, failing that, the embedded one.
+func (l *Linter) externalLinters() externalLinters {
+	var ls externalLinters
+	if cmd, ok := resolveExternal("shellcheck", l.opts.Shellcheck); ok {
+		ls.shellcheck = &cmd
+	} else {
+		if l.opts.Shellcheck != "" {
+			ls.problems = append(ls.problems, notFound("shellcheck", l.opts.Shellcheck))
+		}
+		m, err := loadWASM(l.opts.ShellcheckWASM)
+		switch {
+		case err != nil:
+			ls.problems = append(ls.problems, wasmError(l.opts.ShellcheckWASM, err))
+		case m != nil:
+			ls.shellcheck = &externalCommand{name: "shellcheck", module: m}
+		}
+	}
+	if cmd, ok := resolveExternal("pyflakes", l.opts.Pyflakes); ok {
+		ls.pyflakes = &cmd
+	} else if l.opts.Pyflakes != "" {
+		ls.problems = append(ls.problems, notFound("pyflakes", l.opts.Pyflakes))
+	}
+	return ls
+}
+
+func notFound(name, cmd string) string {
+	return fmt.Sprintf("%s command %q was not found, so scripts were not checked with it", name, cmd)
+}
+
+// ruleExternalLinter runs shellcheck and pyflakes for the rules checking
+// scripts with them, each run bounded by a timeout and a cap on its
+// output, and reports the runs that did not complete. It waits for every
+// run at the end of the workflow, so the findings of the runs are in place
+// when actionlint collects them.
+type ruleExternalLinter struct {
+	actionlint.RuleBase
+	ctx      context.Context
+	timeout  time.Duration
+	limit    int
+	failures *externalFailures
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+}
+
+func newExternalLinter(ctx context.Context, timeout time.Duration, limit int, failures *externalFailures) *ruleExternalLinter {
+	if timeout <= 0 {
+		timeout = DefaultExternalTimeout
+	}
+	if limit <= 0 {
+		limit = DefaultExternalOutputLimit
+	}
+	return &ruleExternalLinter{
+		RuleBase: actionlint.NewRuleBase(KindExternalLinter, "Reports shellcheck and pyflakes runs that did not complete"),
+		ctx:      ctx,
+		timeout:  timeout,
+		limit:    limit,
+		failures: failures,
+	}
+}
+
+// VisitWorkflowPost waits for the runs started for the workflow.
+func (rule *ruleExternalLinter) VisitWorkflowPost(*actionlint.Workflow) error {
+	rule.wg.Wait()
+	return nil
+}
+
+// run runs cmd with args and stdin in the background, passing its output
+// to done when it completes. Runs that fail, time out or write too much are
+// reported at pos instead. Output of a non-zero exit still counts as
+// complete when there is some, since linters exit non-zero on findings.
+func (rule *ruleExternalLinter) run(cmd externalCommand, args []string, stdin string, combine bool, pos *actionlint.Pos, done func([]byte) error) {
+	rule.wg.Add(1)
+	go func() {
+		defer rule.wg.Done()
+		select {
+		case externalSlots <- struct{}{}:
+		case <-rule.ctx.Done():
+			rule.report(pos, "%s was not run for this script: %v", cmd.name, rule.ctx.Err())
+			return
+		}
+		out, err := rule.exec(cmd, args, stdin, combine)
+		<-externalSlots
+		if err == nil {
+			err = done(out)
+		}
+		var failed *externalRunError
+		if errors.As(err, &failed) {
+			rule.failures.add(failed.summary())
+		}
+		if err != nil {
+			rule.report(pos, "%s", err)
+		}
+	}()
+}
+
+// exec runs cmd once within the limits of the rule.
+func (rule *ruleExternalLinter) exec(cmd externalCommand, args []string, stdin string, combine bool) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(rule.ctx, rule.timeout)
+	defer cancel()
+	stop, stopOutput := context.WithCancelCause(ctx)
+	defer stopOutput(nil)
+
+	stdout := &limitedBuffer{limit: rule.limit, stop: stopOutput}
+	stderr := &limitedBuffer{limit: rule.limit, stop: stopOutput}
+	errOut := stderr
+	if combine {
+		errOut = stdout
+	}
+	args = append(append([]string{}, cmd.args...), args...)
+	var err error
+	if cmd.module != nil {
+		err = cmd.module.run(stop, cmd.name, args, strings.NewReader(stdin), stdout, errOut)
+	} else {
+		c := exec.CommandContext(stop, cmd.exe, args...)
+		c.Stdin = strings.NewReader(stdin)
+		c.Stdout = stdout
+		c.Stderr = errOut
+		// Children left holding the pipes must not keep the run alive
+		c.WaitDelay = time.Second
+		err = c.Run()
+	}
+
+	switch {
+	case errors.Is(context.Cause(stop), errOutputLimit):
+		return nil, fmt.Errorf("external linter %s wrote more than %d bytes of output checking this script and was stopped, so its findings are not reported", cmd.name, rule.limit)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded) && rule.ctx.Err() == nil:
+		return nil, fmt.Errorf("external linter %s timed out after %s checking this script and was stopped, so its findings are not reported", cmd.name, rule.timeout)
+	case rule.ctx.Err() != nil:
+		return nil, fmt.Errorf("external linter %s was stopped checking this script: %v", cmd.name, rule.ctx.Err())
+	}
+	if err != nil && (!exited(err) || stdout.Len() == 0) {
+		msg := strings.TrimSpace(stderr.String())
+		if combine {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		return nil, &externalRunError{name: cmd.name, err: err, stderr: msg}
+	}
+	return stdout.Bytes(), nil
+}
+
+// externalRunError is a run of an external linter that could not start or
+// exited with an error and no findings, such as for a flag it does not
+// know. It points at the environment rather than the script.
+type externalRunError struct {
+	name   string
+	err    error
+	stderr string
+}
+
+func (e *externalRunError) Error() string {
+	return fmt.Sprintf("external linter %s did not run successfully checking this script: %s", e.name, e.detail())
+}
+
+// summary describes the failure apart from the script it happened for.
+func (e *externalRunError) summary() string {
+	return fmt.Sprintf("%s did not run successfully: %s", e.name, e.detail())
+}
+
+func (e *externalRunError) detail() string {
+	if e.stderr == "" {
+		return e.err.Error()
+	}
+	return e.err.Error() + ": " + e.stderr
+}
+
+// externalFailures collects the distinct failures of the external linters
+// run for a lint, for its metadata.
+type externalFailures struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (f *externalFailures) add(msg string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !slices.Contains(f.msgs, msg) {
+		f.msgs = append(f.msgs, msg)
+	}
+}
+
+func (f *externalFailures) list() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.msgs)
+}
+
+func (rule *ruleExternalLinter) report(pos *actionlint.Pos, format string, args ...any) {
+	rule.mu.Lock()
+	defer rule.mu.Unlock()
+	rule.Errorf(pos, format, args...)
+}
+
+// limitedBuffer keeps up to limit bytes of output, and stops the run once
+// more is written. The buffer is not embedded, so that copies of the
+// output go through Write rather than bytes.Buffer's ReadFrom.
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+	stop  context.CancelCauseFunc
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); len(p) > room {
+		b.buf.Write(p[:max(room, 0)])
+		b.stop(errOutputLimit)
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) Len() int       { return b.buf.Len() }
+func (b *limitedBuffer) Bytes() []byte  { return b.buf.Bytes() }
+func (b *limitedBuffer) String() string { return b.buf.String() }
+
+// ruleShellcheck checks run: scripts with shellcheck the way actionlint's
+// rule of the same name does, through ruleExternalLinter.
+type ruleShellcheck struct {
+	actionlint.RuleBase
+	cmd           externalCommand
+	runner        *ruleExternalLinter
+	workflowShell string
+	jobShell      string
+	runnerShell   string
+	mu            sync.Mutex
+}
+
+func newShellcheck(cmd externalCommand, runner *ruleExternalLinter) *ruleShellcheck {
+	return &ruleShellcheck{
+		RuleBase: actionlint.NewRuleBase("shellcheck", `Checks for shell script sources in "run:" using shellcheck`),
+		cmd:      cmd,
+		runner:   runner,
+	}
+}
+
+// VisitWorkflowPre records the workflow's default shell.
+func (rule *ruleShellcheck) VisitWorkflowPre(n *actionlint.Workflow) error {
+	rule.workflowShell = ""
+	if n.Defaults != nil && n.Defaults.Run != nil && n.Defaults.Run.Shell != nil {
+		rule.workflowShell = n.Defaults.Run.Shell.Value
+	}
+	return nil
+}
+
+// VisitJobPre records the job's default shell, and PowerShell for jobs on
+// Windows runners.
+func (rule *ruleShellcheck) VisitJobPre(n *actionlint.Job) error {
+	rule.jobShell, rule.runnerShell = "", ""
+	if n.Defaults != nil && n.Defaults.Run != nil && n.Defaults.Run.Shell != nil {
+		rule.jobShell = n.Defaults.Run.Shell.Value
+	}
+	if n.RunsOn != nil {
+		for _, label := range n.RunsOn.Labels {
+			if l := strings.ToLower(label.Value); l == "windows" || strings.HasPrefix(l, "windows-") {
+				rule.runnerShell = "pwsh"
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// VisitStep checks the script of a run: step written for bash or sh.
+func (rule *ruleShellcheck) VisitStep(n *actionlint.Step) error {
+	run, ok := n.Exec.(*actionlint.ExecRun)
+	if !ok || run.Run == nil {
+		return nil
+	}
+	shell := "bash"
+	for _, s := range []string{rule.runnerShell, rule.workflowShell, rule.jobShell} {
+		if s != "" {
+			shell = s
+		}
+	}
+	if run.Shell != nil {
+		shell = run.Shell.Value
+	}
+	sh, _, _ := strings.Cut(shell, " ")
+	if sh != "bash" && sh != "sh" {
+		return nil
+	}
+
+	// The script runs with the options GitHub passes to the shell, which
+	// is a line shellcheck reports findings after
+	setup := "set -e"
+	if sh == "bash" {
+		setup = "set -eo pipefail"
+	}
+	script := setup + "\n" + sanitizeExpressions(run.Run.Value) + "\n"
+	args := []string{"--norc", "-f", "json", "-x", "--shell", sh, "-e", shellcheckExcludes, "-"}
+	pos := run.RunPos
+	rule.runner.run(rule.cmd, args, script, false, pos, func(out []byte) error {
+		var findings []struct {
+			Line    int    `json:"line"`
+			Column  int    `json:"column"`
+			Level   string `json:"level"`
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(out, &findings); err != nil {
+			return fmt.Errorf("could not parse the output of external linter %s: %v", rule.cmd.name, err)
+		}
+		rule.mu.Lock()
+		defer rule.mu.Unlock()
+		for _, f := range findings {
+			rule.Errorf(pos, "shellcheck reported issue in this script: SC%d:%s:%d:%d: %s", f.Code, f.Level, f.Line-1, f.Column, strings.TrimSuffix(f.Message, "."))
+		}
+		return nil
+	})
+	return nil
+}
+
+// rulePyflakes checks the scripts of steps run with shell: python with
+// pyflakes the way actionlint's rule of the same name does, through
+// ruleExternalLinter.
+type rulePyflakes struct {
+	actionlint.RuleBase
+	cmd            externalCommand
+	runner         *ruleExternalLinter
+	workflowPython *bool
+	jobPython      *bool
+	mu             sync.Mutex
+}
+
+func newPyflakes(cmd externalCommand, runner *ruleExternalLinter) *rulePyflakes {
+	return &rulePyflakes{
+		RuleBase: actionlint.NewRuleBase("pyflakes", `Checks for Python script when "shell: python" is configured using Pyflakes`),
+		cmd:      cmd,
+		runner:   runner,
+	}
+}
+
+// isPython reports whether shell runs Python, or nil when it is not set.
+func isPython(shell *actionlint.String) *bool {
+	if shell == nil {
+		return nil
+	}
+	python := shell.Value == "python" || strings.HasPrefix(shell.Value, "python ")
+	return &python
+}
+
+// VisitWorkflowPre records whether the workflow's default shell is Python.
+func (rule *rulePyflakes) VisitWorkflowPre(n *actionlint.Workflow) error {
+	rule.workflowPython = nil
+	if n.Defaults != nil && n.Defaults.Run != nil {
+		rule.workflowPython = isPython(n.Defaults.Run.Shell)
+	}
+	return nil
+}
+
+// VisitJobPre records whether the job's default shell is Python.
+func (rule *rulePyflakes) VisitJobPre(n *actionlint.Job) error {
+	rule.jobPython = nil
+	if n.Defaults != nil && n.Defaults.Run != nil {
+		rule.jobPython = isPython(n.Defaults.Run.Shell)
+	}
+	return nil
+}
+
+// VisitStep checks the script of a run: step written for Python.
+func (rule *rulePyflakes) VisitStep(n *actionlint.Step) error {
+	run, ok := n.Exec.(*actionlint.ExecRun)
+	if !ok || run.Run == nil {
+		return nil
+	}
+	python := false
+	for _, p := range []*bool{rule.workflowPython, rule.jobPython, isPython(run.Shell)} {
+		if p != nil {
+			python = *p
+		}
+	}
+	if !python {
+		return nil
+	}
+
+	pos := run.RunPos
+	// Syntax errors are written to stderr, so the output is combined
+	rule.runner.run(rule.cmd, nil, sanitizeExpressions(run.Run.Value), true, pos, func(out []byte) error {
+		rule.mu.Lock()
+		defer rule.mu.Unlock()
+		for _, line := range strings.Split(string(out), "\n") {
+			// Lines following a syntax error quote the script
+			if _, msg, ok := strings.Cut(line, "<stdin>:"); ok {
+				rule.Errorf(pos, "pyflakes reported issue in this script: %s", strings.TrimSuffix(msg, "\r"))
+			}
+		}
+		return nil
+	})
+	return nil
+}
+
+// sanitizeExpressions writes underscores over the ${{ }} expressions of a
+// script, as actionlint does before running a linter on it, since spaces
+// can break its syntax.
+func sanitizeExpressions(src string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(src, "${{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(src[start:], "}}")
+		if end < 0 {
+			break
+		}
+		end += start + 2
+		b.WriteString(src[:start])
+		b.WriteString(strings.Repeat("_", end-start))
+		src = src[end:]
+	}
+	b.WriteString(src)
+	return b.String()
+}
@@ -0,0 +1,110 @@
+package linter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const scriptWorkflow = `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo $FOO ${{ github.ref }}
+      - run: print(x)
+        shell: python
+      - run: Write-Output hi
+        shell: pwsh
+`
+
+// fakeLinter writes an executable shell script standing in for an external
+// linter.
+func fakeLinter(t *testing.T, name, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake linters are shell scripts")
+	}
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755))
+	return path
+}
+
+func TestLint_ExternalLinters(t *testing.T) {
+	shellcheck := fakeLinter(t, "shellcheck", `input=$(cat)
+case "$input" in
+*'echo $FOO ________________'*) ;;
+*) exit 3 ;;
+esac
+echo '[{"line":2,"column":6,"level":"info","code":2086,"message":"Double quote to prevent globbing and word splitting."}]'
+exit 1`)
+	pyflakes := fakeLinter(t, "pyflakes", `echo "<stdin>:1:7: undefined name 'x'"
+exit 1`)
+
+	result, err := New(Options{Shellcheck: shellcheck, Pyflakes: pyflakes}).Lint(context.Background(), Input{Content: []byte(scriptWorkflow)})
+	require.NoError(t, err)
+	require.Len(t, result.Errors, 2, "%v", result.Errors)
+	assert.Equal(t, LintError{
+		Message:  "shellcheck reported issue in this script: SC2086:info:1:6: Double quote to prevent globbing and word splitting",
+		Line:     6,
+		Column:   9,
+		Kind:     "shellcheck",
+		Severity: SeverityWarning,
+	}, result.Errors[0])
+	assert.Equal(t, "pyflakes reported issue in this script: 1:7: undefined name 'x'", result.Errors[1].Message)
+	assert.Equal(t, 7, result.Errors[1].Line)
+	assert.True(t, result.Meta.Shellcheck)
+	assert.True(t, result.Meta.Pyflakes)
+}
+
+func TestLint_ExternalLinterLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+		meta   string
+	}{
+		{
+			name:   "timeout",
+			script: "sleep 10",
+			want:   "external linter shellcheck timed out after 200ms checking this script and was stopped, so its findings are not reported",
+		},
+		{
+			name:   "output limit",
+			script: "while :; do echo '[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[['; done",
+			want:   "external linter shellcheck wrote more than 1024 bytes of output checking this script and was stopped, so its findings are not reported",
+		},
+		{
+			name:   "failure",
+			script: "echo 'unknown option' >&2; exit 2",
+			want:   "external linter shellcheck did not run successfully checking this script: exit status 2: unknown option",
+			meta:   "shellcheck did not run successfully: exit status 2: unknown option",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(Options{
+				Shellcheck:          fakeLinter(t, "shellcheck", tt.script),
+				ExternalTimeout:     200 * time.Millisecond,
+				ExternalOutputLimit: 1024,
+			})
+			start := time.Now()
+			result, err := l.Lint(context.Background(), Input{Content: []byte(scriptWorkflow)})
+			require.NoError(t, err)
+			assert.Less(t, time.Since(start), 5*time.Second)
+			require.Len(t, result.Errors, 1, "%v", result.Errors)
+			assert.Equal(t, KindExternalLinter, result.Errors[0].Kind)
+			assert.Equal(t, SeverityWarning, result.Errors[0].Severity)
+			assert.Equal(t, tt.want, result.Errors[0].Message)
+			assert.Equal(t, 6, result.Errors[0].Line)
+			assert.Equal(t, tt.meta, result.Meta.ExternalLinterError, "only failures to run point at the environment")
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package linter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"gopkg.in/yaml.v3"
+)
+
+// ScriptsDir is where ExtractScript writes scripts, relative to the
+// repository root.
+const ScriptsDir = "scripts"
+
+// ScriptExtraction reports a run: script moved into its own file, and the
+// changes doing so makes.
+type ScriptExtraction struct {
+	Workflow string       `json:"workflow"`
+	Script   string       `json:"script"`
+	Run      string       `json:"run"`
+	Lines    int          `json:"lines"`
+	Changes  []FileChange `json:"changes"`
+}
+
+// shells maps the shells whose scripts can be extracted to the header of
+// the script file, which keeps the error handling GitHub applies to them.
+var shells = map[string]string{
+	"bash": "#!/usr/bin/env bash\nset -eo pipefail\n",
+	"sh":   "#!/bin/sh\nset -e\n",
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ExtractScript moves the run: script of a step into
+// scripts/<workflow>-<job>-<step>.sh at the repository root and rewrites
+// the step to invoke it. step counts from 1, like the long-script finding.
+// Only bash and sh scripts without ${{ }} expressions can be extracted,
+// since expressions are not expanded in script files. Nothing is written:
+// the script and the workflow are returned as changes for WriteChanges,
+// the script first. Existing scripts are never overwritten.
+func ExtractScript(path, job string, step int) (*ScriptExtraction, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	content, err := normalizeEncoding(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("%s is empty", path)
+	}
+	root := doc.Content[0]
+
+	jobNode := mappingValue(mappingValue(root, "jobs"), job)
+	if jobNode == nil {
+		return nil, fmt.Errorf("job %q not found in %s", job, path)
+	}
+	steps := mappingValue(jobNode, "steps")
+	if steps == nil || steps.Kind != yaml.SequenceNode || step < 1 || step > len(steps.Content) {
+		return nil, fmt.Errorf("job %q has no step %d", job, step)
+	}
+	stepNode := steps.Content[step-1]
+
+	runKey, run := mappingEntry(stepNode, "run")
+	if run == nil || run.Kind != yaml.ScalarNode {
+		return nil, fmt.Errorf("step %d of job %q has no run: script", step, job)
+	}
+	if strings.Contains(run.Value, "${{") {
+		return nil, fmt.Errorf("the script of step %d of job %q uses ${{ }} expressions, which are not expanded in script files; pass them through env: first", step, job)
+	}
+
+	shell := scalarValue(mappingValue(stepNode, "shell"))
+	for _, n := range []*yaml.Node{jobNode, root} {
+		if shell == "" {
+			shell = scalarValue(mappingValue(mappingValue(mappingValue(n, "defaults"), "run"), "shell"))
+		}
+	}
+	if shell == "" {
+		// Windows runners default to PowerShell
+		shell = "bash"
+		if strings.Contains(strings.ToLower(scalarValue(mappingValue(jobNode, "runs-on"))), "windows") {
+			shell = "pwsh"
+		}
+	}
+	header, ok := shells[shell]
+	if !ok {
+		return nil, fmt.Errorf("step %d of job %q runs with shell %q; only bash and sh scripts can be extracted", step, job, shell)
+	}
+
+	name := scriptName(path, job, step, scalarValue(mappingValue(stepNode, "id")), scalarValue(mappingValue(stepNode, "name")))
+	rel := filepath.ToSlash(filepath.Join(ScriptsDir, name))
+	scriptPath := filepath.Join(repositoryRoot(path), rel)
+	if _, err := os.Lstat(scriptPath); err == nil {
+		return nil, fmt.Errorf("%s already exists", scriptPath)
+	}
+
+	command := fmt.Sprintf(`%s "$GITHUB_WORKSPACE/%s"`, shell, rel)
+	rewritten := replaceRun(content, runKey, command)
+	var check yaml.Node
+	if err := yaml.Unmarshal(rewritten, &check); err != nil {
+		return nil, fmt.Errorf("rewriting step %d of job %q would produce invalid YAML: %w", step, job, err)
+	}
+	rewritten = keepLayout(raw, rewritten)
+
+	script := []byte(header + "\n" + strings.TrimRight(run.Value, "\n") + "\n")
+	return &ScriptExtraction{
+		Workflow: path,
+		Script:   scriptPath,
+		Run:      command,
+		Lines:    rules.ScriptLines(run.Value),
+		Changes: []FileChange{
+			{Path: scriptPath, Created: true, Diff: UnifiedDiff(scriptPath, nil, script), Content: script, Mode: 0755},
+			{Path: path, Diff: UnifiedDiff(path, raw, rewritten), Content: rewritten},
+		},
+	}, nil
+}
+
+// scriptName names the script after the workflow file, the job and the
+// step's id, name or position, in that order of preference.
+func scriptName(path, job string, step int, id, name string) string {
+	label := slug(id)
+	if label == "" {
+		label = slug(name)
+	}
+	if label == "" {
+		label = fmt.Sprintf("step%d", step)
+	}
+	workflow := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return slug(workflow) + "-" + slug(job) + "-" + label + ".sh"
+}
+
+func slug(s string) string {
+	return strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// repositoryRoot returns the root of the repository a workflow in
+// WorkflowsDir belongs to, or the workflow's own directory otherwise.
+func repositoryRoot(path string) string {
+	dir := filepath.Dir(path)
+	if abs, err := filepath.Abs(dir); err == nil && strings.HasSuffix(abs, string(filepath.Separator)+WorkflowsDir) {
+		return filepath.Dir(filepath.Dir(dir))
+	}
+	return dir
+}
+
+// replaceRun replaces the run: entry starting at key, including every
+// following line of its value, with a single-line run: command.
+func replaceRun(content []byte, key *yaml.Node, command string) []byte {
+	lines := strings.SplitAfter(string(content), "\n")
+	start := key.Line - 1
+	indent := key.Column - 1
+
+	end := start + 1
+	for i := start + 1; i < len(lines); i++ {
+		trimmed := strings.TrimLeft(lines[i], " ")
+		if strings.TrimSpace(trimmed) == "" {
+			continue
+		}
+		if len(lines[i])-len(trimmed) <= indent {
+			break
+		}
+		end = i + 1
+	}
+
+	var b strings.Builder
+	for _, l := range lines[:start] {
+		b.WriteString(l)
+	}
+	b.WriteString(lines[start][:indent] + "run: " + command + "\n")
+	for _, l := range lines[end:] {
+		b.WriteString(l)
+	}
+	return []byte(b.String())
+}
+
+// mappingEntry returns the key and value nodes of key in the mapping n.
+func mappingEntry(n *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i], n.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	_, v := mappingEntry(n, key)
+	return v
+}
+
+func scalarValue(n *yaml.Node) string {
+	if n == nil || n.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return n.Value
+}
@@ -0,0 +1,108 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const extractWorkflow = `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - name: Build all
+        run: |
+          make deps
+          make build
+
+        env:
+          CI: true
+      - run: echo ${{ github.sha }}
+  windows:
+    runs-on: windows-latest
+    steps:
+      - run: dir
+`
+
+func TestExtractScript(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	root := t.TempDir()
+	path := filepath.Join(root, ".github", "workflows", "ci.yml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(extractWorkflow), 0644))
+
+	got, err := ExtractScript(path, "build", 2)
+	require.NoError(t, err)
+
+	scriptPath := filepath.Join(root, "scripts", "ci-build-build-all.sh")
+	assert.Equal(t, path, got.Workflow)
+	assert.Equal(t, scriptPath, got.Script)
+	assert.Equal(t, `bash "$GITHUB_WORKSPACE/scripts/ci-build-build-all.sh"`, got.Run)
+	assert.Equal(t, 2, got.Lines)
+	require.Len(t, got.Changes, 2)
+	assert.True(t, got.Changes[0].Created)
+	assert.Contains(t, got.Changes[1].Diff, "-          make deps\n")
+	assert.NoFileExists(t, scriptPath, "nothing is written until WriteChanges")
+	require.NoError(t, WriteChanges(got.Changes))
+
+	script, err := os.ReadFile(scriptPath)
+	require.NoError(t, err)
+	assert.Equal(t, "#!/usr/bin/env bash\nset -eo pipefail\n\nmake deps\nmake build\n", string(script))
+	info, err := os.Stat(scriptPath)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&0100, "script is executable")
+
+	workflow, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(workflow), "      - name: Build all\n        run: bash \"$GITHUB_WORKSPACE/scripts/ci-build-build-all.sh\"\n\n        env:\n          CI: true\n")
+	assert.NotContains(t, string(workflow), "make deps")
+
+	result, err := New(DefaultOptions()).Lint(t.Context(), Input{Path: path})
+	require.NoError(t, err)
+	assert.True(t, result.Valid, "%v", result.Errors)
+
+	_, err = ExtractScript(path, "build", 2)
+	assert.ErrorContains(t, err, "already exists")
+}
+
+func TestExtractScript_Refusals(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "ci.yml")
+	require.NoError(t, os.WriteFile(path, []byte(extractWorkflow), 0644))
+
+	tests := []struct {
+		name string
+		job  string
+		step int
+		want string
+	}{
+		{"unknown job", "deploy", 1, `job "deploy" not found`},
+		{"step out of range", "build", 4, `job "build" has no step 4`},
+		{"uses step", "build", 1, "has no run: script"},
+		{"expressions", "build", 3, "uses ${{ }} expressions"},
+		{"powershell", "windows", 1, `runs with shell "pwsh"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExtractScript(path, tt.job, tt.step)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+
+	// Outside .github/workflows scripts go next to the workflow, and are
+	// never overwritten
+	existing := filepath.Join(root, "scripts", "ci-build-build-all.sh")
+	require.NoError(t, os.MkdirAll(filepath.Dir(existing), 0755))
+	require.NoError(t, os.WriteFile(existing, []byte("keep"), 0644))
+	_, err := ExtractScript(path, "build", 2)
+	assert.ErrorContains(t, err, "already exists")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, extractWorkflow, string(content))
+}
@@ -0,0 +1,57 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IsWorkflowFile reports whether name has a workflow file extension. The
+// check is case-insensitive so that CI.YML saved on Windows is found too.
+func IsWorkflowFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yml", ".yaml":
+		return true
+	default:
+		return false
+	}
+}
+
+// FindWorkflowFiles returns the workflow files directly inside dir, sorted
+// by path. Each directory entry is listed once, so files are not counted
+// twice on case-insensitive filesystems. A missing dir, or a path that is
+// not a directory, yields no files.
+func FindWorkflowFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if info, statErr := os.Stat(dir); os.IsNotExist(statErr) || (statErr == nil && !info.IsDir()) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && IsWorkflowFile(entry.Name()) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// CleanPath accepts backslash separators in a path parameter on every
+// platform, so Windows-style paths sent by a client work on Unix hosts. A
+// path naming an existing file is returned unchanged, since a backslash is
+// a legal file name character there.
+func CleanPath(p string) string {
+	if filepath.Separator == '\\' || !strings.Contains(p, `\`) {
+		return p
+	}
+	if _, err := os.Stat(p); err == nil {
+		return p
+	}
+	return strings.ReplaceAll(p, `\`, "/")
+}
@@ -0,0 +1,58 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsWorkflowFile(t *testing.T) {
+	assert.True(t, IsWorkflowFile("ci.yml"))
+	assert.True(t, IsWorkflowFile("ci.yaml"))
+	assert.True(t, IsWorkflowFile("CI.YML"))
+	assert.True(t, IsWorkflowFile("release.Yaml"))
+	assert.False(t, IsWorkflowFile("README.md"))
+	assert.False(t, IsWorkflowFile("yml"))
+}
+
+func TestFindWorkflowFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.yml", "A.YAML", "c.YML", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("on: push"), 0644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "nested.yml"), 0755))
+
+	files, err := FindWorkflowFiles(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "A.YAML"),
+		filepath.Join(dir, "b.yml"),
+		filepath.Join(dir, "c.YML"),
+	}, files)
+
+	files, err = FindWorkflowFiles(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	files, err = FindWorkflowFiles(filepath.Join(dir, "b.yml"))
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestCleanPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("backslashes are native separators on Windows")
+	}
+
+	assert.Equal(t, ".github/workflows", CleanPath(`.github\workflows`))
+	assert.Equal(t, "ci.yml", CleanPath("ci.yml"))
+
+	// An existing file with a backslash in its name is left alone
+	literal := filepath.Join(t.TempDir(), `odd\name.yml`)
+	require.NoError(t, os.WriteFile(literal, []byte("on: push"), 0644))
+	assert.Equal(t, literal, CleanPath(literal))
+}
@@ -0,0 +1,282 @@
+package linter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/rhysd/actionlint"
+)
+
+// SkippedFix is a fix ApplyFixes did not apply, with the reason.
+type SkippedFix struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// attachFixes gives each finding the fix a rule offered for its kind and
+// position. Findings at the same position share the fix.
+func attachFixes(result *LintResult, rs []actionlint.Rule) {
+	type key struct {
+		kind         string
+		line, column int
+	}
+	offered := map[key]*rules.Fix{}
+	for _, r := range rs {
+		if f, ok := r.(rules.Fixer); ok {
+			for _, fix := range f.Fixes() {
+				offered[key{fix.Kind, fix.Line, fix.Column}] = fix
+			}
+		}
+	}
+	if len(offered) == 0 {
+		return
+	}
+	for i := range result.Errors {
+		e := &result.Errors[i]
+		e.Fix = offered[key{e.Kind, e.Line, e.Column}]
+	}
+}
+
+// unquotedGlob matches a line whose mapping value or sequence entry is a
+// pattern starting with * or !, which YAML reads as an alias or a tag
+// rather than a string. The pattern is captured.
+var unquotedGlob = regexp.MustCompile(`^\s*(?:-\s+|[\w.-]+:\s+)([*!][^\s'#]*)\s*(?:#.*)?$`)
+
+// quoteGlobs offers fixes quoting the patterns of content that YAML reads
+// as an alias or a tag, such as - *.md or - !docs/** in a paths filter,
+// for the syntax-check findings on their lines. YAML fails to parse an
+// alias that names no anchor, and reads a tag as an empty value.
+func quoteGlobs(result *LintResult, content []byte) {
+	lines := strings.Split(string(content), "\n")
+	for i := range result.Errors {
+		e := &result.Errors[i]
+		if e.Kind != "syntax-check" || e.Fix != nil || e.Line < 1 || e.Line > len(lines) {
+			continue
+		}
+		line := strings.TrimSuffix(lines[e.Line-1], "\r")
+		m := unquotedGlob.FindStringSubmatchIndex(line)
+		if m == nil || e.Column != 0 && e.Column != m[2]+1 {
+			continue
+		}
+		glob := line[m[2]:m[3]]
+		e.Fix = &rules.Fix{
+			Kind:        e.Kind,
+			Line:        e.Line,
+			Column:      e.Column,
+			Description: fmt.Sprintf("Quote %s, which YAML does not read as a string", glob),
+			Edits:       []rules.Edit{{Line: e.Line, Column: m[2] + 1, Old: glob, New: "'" + glob + "'"}},
+		}
+	}
+}
+
+// offsetFix moves a fix down by lines, for documents after the first.
+func offsetFix(fix *rules.Fix, lines int) {
+	fix.Line += lines
+	for i := range fix.Edits {
+		fix.Edits[i].Line += lines
+	}
+}
+
+// assignFixIDs names the fixes of result after the kind and position of
+// their finding.
+func assignFixIDs(result *LintResult) {
+	for _, e := range result.Errors {
+		if e.Fix != nil {
+			e.Fix.ID = fmt.Sprintf("%s:%d:%d", e.Fix.Kind, e.Fix.Line, e.Fix.Column)
+		}
+	}
+}
+
+// Fixes returns the distinct fixes offered for the findings of result, in
+// the order of the findings.
+func (r *LintResult) Fixes() []*rules.Fix {
+	var out []*rules.Fix
+	seen := map[string]bool{}
+	for _, e := range r.Errors {
+		if e.Fix != nil && !seen[e.Fix.ID] {
+			seen[e.Fix.ID] = true
+			out = append(out, e.Fix)
+		}
+	}
+	return out
+}
+
+// ApplyFixes applies fixes to content, which must be the content they
+// were computed for. A fix whose text is no longer where it was, or that
+// overlaps a fix at an earlier position, is skipped. It returns the new
+// content and the IDs of the applied fixes.
+func ApplyFixes(content []byte, fixes []*rules.Fix) ([]byte, []string, []SkippedFix) {
+	ordered := append([]*rules.Fix(nil), fixes...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	text := string(content)
+	applied := []string{}
+	var skipped []SkippedFix
+	var accepted []editSpan
+	type extent struct{ start, end int }
+	var taken []extent
+	for _, fix := range ordered {
+		spans, err := locateFix(text, fix)
+		if err != nil {
+			skipped = append(skipped, SkippedFix{ID: fix.ID, Reason: err.Error()})
+			continue
+		}
+		ext := extent{spans[0].start, spans[len(spans)-1].end}
+		overlaps := false
+		for _, t := range taken {
+			if ext.start < t.end && t.start < ext.end {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			skipped = append(skipped, SkippedFix{ID: fix.ID, Reason: "overlaps another fix"})
+			continue
+		}
+		taken = append(taken, ext)
+		accepted = append(accepted, spans...)
+		applied = append(applied, fix.ID)
+	}
+
+	// Apply from the end so that earlier offsets stay valid
+	sort.SliceStable(accepted, func(i, j int) bool { return accepted[i].start > accepted[j].start })
+	for _, s := range accepted {
+		text = text[:s.start] + s.new + text[s.end:]
+	}
+	sort.Strings(applied)
+	return []byte(text), applied, skipped
+}
+
+// editSpan is an edit resolved to byte offsets of the text.
+type editSpan struct {
+	start, end int
+	new        string
+}
+
+// locateFix resolves the edits of fix in text.
+func locateFix(text string, fix *rules.Fix) ([]editSpan, error) {
+	var spans []editSpan
+	prevEnd := 0
+	for _, e := range fix.Edits {
+		from, ok := offsetOf(text, e.Line, e.Column)
+		if !ok {
+			return nil, fmt.Errorf("line %d column %d is outside the file", e.Line, e.Column)
+		}
+		from = max(from, prevEnd)
+		start := from
+		if e.Old != "" {
+			i := strings.Index(text[from:], e.Old)
+			if i < 0 {
+				return nil, fmt.Errorf("%q was not found; the file changed since it was linted", e.Old)
+			}
+			start = from + i
+		}
+		spans = append(spans, editSpan{start, start + len(e.Old), e.New})
+		prevEnd = start + len(e.Old)
+	}
+	if len(spans) == 0 {
+		return nil, fmt.Errorf("fix has no edits")
+	}
+	return spans, nil
+}
+
+// offsetOf returns the byte offset of a 1-based line and column, where the
+// column counts characters.
+func offsetOf(text string, line, column int) (int, bool) {
+	offset := 0
+	for l := 1; l < line; l++ {
+		i := strings.IndexByte(text[offset:], '\n')
+		if i < 0 {
+			return 0, false
+		}
+		offset += i + 1
+	}
+	for c := 1; c < column; c++ {
+		if offset >= len(text) || text[offset] == '\n' {
+			return 0, false
+		}
+		_, size := utf8.DecodeRuneInString(text[offset:])
+		offset += size
+	}
+	return offset, true
+}
+
+// FixResult reports the fixes FixFile applied to a file. Changes holds the
+// fixed file when any fix applied.
+type FixResult struct {
+	FilePath string       `json:"file_path"`
+	Applied  []string     `json:"applied"`
+	Skipped  []SkippedFix `json:"skipped,omitempty"`
+	Diff     string       `json:"diff,omitempty"`
+	Changes  []FileChange `json:"-"`
+}
+
+// FixFile lints the workflow at path and applies the fixes with the given
+// IDs, or all offered fixes when ids is empty. The file is not written:
+// pass Changes to WriteChanges to save the fixes. IDs that match no fix are
+// reported as skipped.
+func (l *Linter) FixFile(ctx context.Context, path string, ids []string) (*FixResult, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	content, err := normalizeEncoding(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode file: %w", err)
+	}
+	result, err := l.Lint(ctx, Input{Path: path, Content: content})
+	if err != nil {
+		return nil, err
+	}
+
+	offered := result.Fixes()
+	selected := offered
+	var unknown []SkippedFix
+	if len(ids) > 0 {
+		byID := make(map[string]*rules.Fix, len(offered))
+		for _, f := range offered {
+			byID[f.ID] = f
+		}
+		selected = nil
+		chosen := map[string]bool{}
+		for _, id := range ids {
+			f, ok := byID[id]
+			switch {
+			case !ok:
+				unknown = append(unknown, SkippedFix{ID: id, Reason: "no such fix"})
+			case !chosen[id]:
+				chosen[id] = true
+				selected = append(selected, f)
+			}
+		}
+	}
+
+	fixed, applied, skipped := ApplyFixes(content, selected)
+	if len(applied) > 0 {
+		fixed = keepLayout(raw, fixed)
+	} else {
+		fixed = raw
+	}
+	out := &FixResult{
+		FilePath: path,
+		Applied:  applied,
+		Skipped:  append(skipped, unknown...),
+		Diff:     UnifiedDiff(path, raw, fixed),
+	}
+	if len(applied) > 0 {
+		out.Changes = []FileChange{{Path: path, Diff: out.Diff, Content: fixed}}
+	}
+	return out, nil
+}
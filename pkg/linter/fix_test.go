@@ -0,0 +1,191 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixableWorkflow = `on: push
+jobs:
+  build:
+    if: ${{ 'false' }}
+    runs-on: ubuntu-latest
+    steps:
+      - id: version
+        run: |
+          echo "::set-output name=tag::v1"
+          echo "::set-output name=sha::abc"
+      - run: echo ${{ steps.version.outputs.tag }} ${{ steps.version.outputs.sha }}
+`
+
+func TestFixFile(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	require.NoError(t, os.WriteFile(path, []byte(fixableWorkflow), 0600))
+	l := New(Options{Rules: rules.Config{Packs: []string{"style"}}})
+
+	result, err := l.Lint(t.Context(), Input{Path: path})
+	require.NoError(t, err)
+	fixes := result.Fixes()
+	require.Len(t, fixes, 2, "deprecated commands of one script share a fix")
+	assert.Equal(t, "constant-condition:4:9", fixes[0].ID)
+	assert.Equal(t, "deprecated-commands:8:14", fixes[1].ID)
+
+	fixed, err := l.FixFile(t.Context(), path, []string{"deprecated-commands:8:14", "nope:1:1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deprecated-commands:8:14"}, fixed.Applied)
+	assert.Equal(t, []SkippedFix{{ID: "nope:1:1", Reason: "no such fix"}}, fixed.Skipped)
+	assert.Contains(t, fixed.Diff, "+          echo \"tag=v1\" >> \"$GITHUB_OUTPUT\"\n+          echo \"sha=abc\" >> \"$GITHUB_OUTPUT\"\n")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, fixableWorkflow, string(content), "nothing is written until WriteChanges")
+	require.NoError(t, WriteChanges(fixed.Changes))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	fixed, err = l.FixFile(t.Context(), path, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"constant-condition:4:9"}, fixed.Applied)
+	require.NoError(t, WriteChanges(fixed.Changes))
+
+	result, err = l.Lint(t.Context(), Input{Path: path})
+	require.NoError(t, err)
+	assert.True(t, result.Valid, "%v", result.Errors)
+}
+
+func TestFixFile_Layout(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	workflow := "on: push\r\njobs:\r\n  test:\r\n    runs-on: ubuntu-latest\r\n    steps:\r\n      - run: echo \"::add-path::/opt/bin\""
+	require.NoError(t, os.WriteFile(path, []byte(workflow), 0755))
+
+	fixed, err := New(Options{}).FixFile(t.Context(), path, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deprecated-commands:6:14"}, fixed.Applied)
+	assert.Contains(t, fixed.Diff, "     steps:\r\n-      - run: echo \"::add-path::/opt/bin\"\n\\ No newline at end of file\n+      - run: echo \"/opt/bin\" >> \"$GITHUB_PATH\"\n\\ No newline at end of file\n", "the diff is of the file as written")
+	require.NoError(t, WriteChanges(fixed.Changes))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "on: push\r\njobs:\r\n  test:\r\n    runs-on: ubuntu-latest\r\n    steps:\r\n      - run: echo \"/opt/bin\" >> \"$GITHUB_PATH\"", string(content), "line endings and the missing final newline are kept")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+}
+
+func TestFixFile_Egress(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "deploy.yml")
+	workflow := "on: push\njobs:\n  deploy:\n    runs-on: ubuntu-latest\n    environment: prod\n    steps:\n      - name: Deploy\n        run: ./deploy.sh\n"
+	require.NoError(t, os.WriteFile(path, []byte(workflow), 0600))
+	l := New(Options{Rules: rules.Config{Egress: rules.EgressConfig{Enabled: true}}})
+
+	fixed, err := l.FixFile(t.Context(), path, []string{"egress-hardening:3:3"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"egress-hardening:3:3"}, fixed.Applied)
+	require.NoError(t, WriteChanges(fixed.Changes))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "on: push\njobs:\n  deploy:\n    runs-on: ubuntu-latest\n    environment: prod\n    steps:\n      - uses: step-security/harden-runner@v2\n        with:\n          egress-policy: audit\n      - name: Deploy\n        run: ./deploy.sh\n", string(content))
+
+	result, err := l.Lint(t.Context(), Input{Path: path})
+	require.NoError(t, err)
+	assert.True(t, result.Valid, "%v", result.Errors)
+}
+
+func TestFixFile_ShellStrictness(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "test.yml")
+	workflow := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n      - run: make test 2>&1 | tee test.log\n"
+	require.NoError(t, os.WriteFile(path, []byte(workflow), 0600))
+	l := New(Options{Rules: rules.Config{Packs: []string{"style"}}})
+
+	fixed, err := l.FixFile(t.Context(), path, []string{"shell-strictness:7:14"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"shell-strictness:7:14"}, fixed.Applied)
+	require.NoError(t, WriteChanges(fixed.Changes))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "on: push\njobs:\n  test:\n    defaults:\n      run:\n        shell: bash\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n      - run: make test 2>&1 | tee test.log\n", string(content))
+
+	result, err := l.Lint(t.Context(), Input{Path: path})
+	require.NoError(t, err)
+	assert.True(t, result.Valid, "%v", result.Errors)
+}
+
+func TestFixFile_Globs(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "docs.yml")
+	workflow := "on:\n  push:\n    paths:\n      - *.md\n      - !docs/** # generated\n  pull_request:\n    branches: *\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo docs\n"
+	require.NoError(t, os.WriteFile(path, []byte(workflow), 0600))
+	l := New(Options{})
+
+	result, err := l.Lint(t.Context(), Input{Path: path})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Fixes())
+	assert.Equal(t, "syntax-check", result.Fixes()[0].Kind)
+
+	// YAML stops at the first alias, so the patterns are fixed in turn
+	for range 3 {
+		fixed, err := l.FixFile(t.Context(), path, nil)
+		require.NoError(t, err)
+		require.NotEmpty(t, fixed.Applied)
+		require.NoError(t, WriteChanges(fixed.Changes))
+		if result, err = l.Lint(t.Context(), Input{Path: path}); err == nil && result.Valid {
+			break
+		}
+	}
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "on:\n  push:\n    paths:\n      - '*.md'\n      - '!docs/**' # generated\n  pull_request:\n    branches: '*'\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo docs\n", string(content))
+	assert.True(t, result.Valid, "%v", result.Errors)
+}
+
+func TestFixFile_Pins(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	workflow := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n      - uses: actions/setup-go@v5\n"
+	require.NoError(t, os.WriteFile(path, []byte(workflow), 0600))
+	sha := "11bd71901bbe5b1630ceea73d27597364c9af683"
+	l := New(Options{Rules: rules.Config{Pins: rules.PinsConfig{Commits: map[string]string{"actions/checkout@v4": sha}}}.WithPacks("security")})
+
+	result, err := l.Lint(t.Context(), Input{Path: path})
+	require.NoError(t, err)
+	require.Len(t, result.Fixes(), 1, "only refs with a known commit are fixed")
+
+	fixed, err := l.FixFile(t.Context(), path, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"unpinned-action:6:15"}, fixed.Applied)
+	require.NoError(t, WriteChanges(fixed.Changes))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "      - uses: actions/checkout@"+sha+" # v4\n      - uses: actions/setup-go@v5\n")
+}
+
+func TestApplyFixes(t *testing.T) {
+	content := []byte("a: one\nb: two\n")
+	fixes := []*rules.Fix{
+		{ID: "first", Line: 1, Column: 4, Edits: []rules.Edit{{Line: 1, Column: 4, Old: "one", New: "1"}}},
+		{ID: "stale", Line: 2, Column: 4, Edits: []rules.Edit{{Line: 2, Column: 4, Old: "three", New: "3"}}},
+		{ID: "insert", Line: 2, Column: 1, Edits: []rules.Edit{{Line: 2, Column: 1, New: "# b\n"}}},
+		{ID: "overlap", Line: 1, Column: 6, Edits: []rules.Edit{{Line: 1, Column: 6, Old: "e\nb", New: "x"}}},
+	}
+
+	out, applied, skipped := ApplyFixes(content, fixes)
+	assert.Equal(t, "a: 1\n# b\nb: two\n", string(out))
+	assert.Equal(t, []string{"first", "insert"}, applied)
+	require.Len(t, skipped, 2)
+	assert.Equal(t, SkippedFix{ID: "overlap", Reason: "overlaps another fix"}, skipped[0])
+	assert.Equal(t, "stale", skipped[1].ID)
+	assert.Contains(t, skipped[1].Reason, "file changed since it was linted")
+}
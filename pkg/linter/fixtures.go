@@ -0,0 +1,78 @@
+package linter
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+)
+
+// fixtureFiles are the fixture workflows, one per kind of finding, named
+// after the kind. The first line of each is a comment saying what is broken.
+//
+//go:embed fixtures/*.yml
+var fixtureFiles embed.FS
+
+// Fixture is an intentionally broken workflow that the linter reports a
+// finding of Kind for with the default options, and Pack turned on when
+// the kind belongs to one.
+type Fixture struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	Pack        string `json:"pack,omitempty"`
+	Description string `json:"description"`
+	Content     []byte `json:"-"`
+}
+
+// Fixtures returns the fixture workflows, sorted by kind.
+func Fixtures() []Fixture {
+	entries, _ := fixtureFiles.ReadDir("fixtures")
+	out := make([]Fixture, 0, len(entries))
+	for _, e := range entries {
+		content, _ := fixtureFiles.ReadFile(path.Join("fixtures", e.Name()))
+		kind := strings.TrimSuffix(e.Name(), ".yml")
+		first, _, _ := strings.Cut(string(content), "\n")
+		out = append(out, Fixture{
+			Name:        e.Name(),
+			Kind:        kind,
+			Pack:        rules.PackOf(kind),
+			Description: strings.TrimPrefix(strings.TrimPrefix(first, "# "), kind+": "),
+			Content:     content,
+		})
+	}
+	return out
+}
+
+// FixtureChanges returns the changes creating the fixtures of kinds in dir,
+// or all of them when kinds is empty, and the fixtures they create. A file
+// of the same name in dir is replaced.
+func FixtureChanges(dir string, kinds []string) ([]FileChange, []Fixture, error) {
+	all := Fixtures()
+	for _, k := range kinds {
+		if !slices.ContainsFunc(all, func(f Fixture) bool { return f.Kind == k }) {
+			return nil, nil, fmt.Errorf("no fixture for kind %q", k)
+		}
+	}
+
+	var changes []FileChange
+	var fixtures []Fixture
+	for _, f := range all {
+		if len(kinds) > 0 && !slices.Contains(kinds, f.Kind) {
+			continue
+		}
+		target := filepath.Join(dir, f.Name)
+		existing, err := os.ReadFile(target)
+		created := os.IsNotExist(err)
+		if err != nil && !created {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", target, err)
+		}
+		changes = append(changes, FileChange{Path: target, Created: created, Diff: UnifiedDiff(target, existing, f.Content), Content: f.Content})
+		fixtures = append(fixtures, f)
+	}
+	return changes, fixtures, nil
+}
@@ -0,0 +1,60 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFixtures lints every fixture where it is generated and checks that
+// it is reported for its kind, so the fixtures keep exercising their rule.
+func TestFixtures(t *testing.T) {
+	dir := t.TempDir()
+	changes, fixtures, err := FixtureChanges(dir, nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, fixtures)
+	require.NoError(t, WriteChanges(changes))
+
+	for _, f := range fixtures {
+		t.Run(f.Kind, func(t *testing.T) {
+			assert.NotEmpty(t, f.Description)
+			assert.NotContains(t, f.Description, f.Kind+":")
+			kinds := func(opts Options) []string {
+				result, err := New(opts).Lint(t.Context(), Input{Path: filepath.Join(dir, f.Name)})
+				require.NoError(t, err)
+				var out []string
+				for _, e := range result.Errors {
+					out = append(out, e.Kind)
+				}
+				return out
+			}
+			if f.Pack == "" {
+				assert.Contains(t, kinds(Options{}), f.Kind)
+				return
+			}
+			assert.NotContains(t, kinds(Options{}), f.Kind, "checks of packs do not run by default")
+			assert.Contains(t, kinds(Options{Rules: rules.Config{Packs: []string{f.Pack}}}), f.Kind)
+		})
+	}
+}
+
+func TestFixtureChanges(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "schedule.yml")
+	require.NoError(t, os.WriteFile(existing, []byte("on: push\n"), 0644))
+
+	changes, fixtures, err := FixtureChanges(dir, []string{"schedule", "syntax-check"})
+	require.NoError(t, err)
+	require.Len(t, fixtures, 2)
+	assert.Equal(t, "schedule", fixtures[0].Kind)
+	assert.Equal(t, existing, changes[0].Path)
+	assert.False(t, changes[0].Created)
+	assert.True(t, changes[1].Created)
+
+	_, _, err = FixtureChanges(dir, []string{"no-such-rule"})
+	assert.ErrorContains(t, err, `no fixture for kind "no-such-rule"`)
+}
@@ -0,0 +1,101 @@
+package linter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// topLevelOrder is the canonical order of the top-level workflow keys.
+// Other keys follow them in their original order.
+var topLevelOrder = []string{"name", "run-name", "on", "permissions", "env", "defaults", "concurrency", "jobs"}
+
+// Format returns content as canonical YAML: indented by two spaces, with
+// top-level keys in topLevelOrder and comments kept. A true: key, which
+// YAML 1.1 tools write when they re-serialize on:, is turned back into
+// on:. Scalar styles are kept as written, so a bare on: stays bare.
+func Format(content []byte) ([]byte, error) {
+	normalized, err := normalizeEncoding(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode file: %w", err)
+	}
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+
+	dec := yaml.NewDecoder(bytes.NewReader(normalized))
+	docs := 0
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		if len(doc.Content) == 0 {
+			continue // Only comments, which the encoder cannot write alone
+		}
+		if doc.Content[0].Kind == yaml.MappingNode {
+			canonicalizeWorkflow(doc.Content[0])
+		}
+		if err := enc.Encode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to format YAML: %w", err)
+		}
+		docs++
+	}
+	if docs == 0 {
+		return nil, fmt.Errorf("nothing to format: the input has no YAML content")
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to format YAML: %w", err)
+	}
+
+	return keepLayout(content, out.Bytes()), nil
+}
+
+// canonicalizeWorkflow restores an on: key turned into true: and sorts the
+// top-level keys of a workflow mapping.
+func canonicalizeWorkflow(m *yaml.Node) {
+	type entry struct{ key, value *yaml.Node }
+	if len(m.Content) == 0 {
+		return
+	}
+	entries := make([]entry, 0, len(m.Content)/2)
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		key := m.Content[i]
+		if key.Tag == "!!bool" && strings.EqualFold(key.Value, "true") {
+			key.Tag, key.Value, key.Style = "!!str", "on", 0
+		}
+		entries = append(entries, entry{key, m.Content[i+1]})
+	}
+
+	rank := func(e entry) int {
+		if i := slices.Index(topLevelOrder, e.key.Value); i >= 0 {
+			return i
+		}
+		return len(topLevelOrder)
+	}
+	first := entries[0].key
+	slices.SortStableFunc(entries, func(a, b entry) int { return rank(a) - rank(b) })
+
+	// A comment above the first key heads the file and stays at the top
+	if moved := entries[0].key; moved != first && first.HeadComment != "" {
+		head := first.HeadComment
+		if moved.HeadComment != "" {
+			head += "\n" + moved.HeadComment
+		}
+		moved.HeadComment, first.HeadComment = head, ""
+	}
+
+	m.Content = m.Content[:0]
+	for _, e := range entries {
+		m.Content = append(m.Content, e.key, e.value)
+	}
+}
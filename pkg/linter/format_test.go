@@ -0,0 +1,80 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	src := `# CI workflow
+jobs:
+    build:   # the only job
+        runs-on: ubuntu-latest
+        steps:
+        - run: |
+            make build
+true:
+    push:
+        branches: [main]
+name: CI
+permissions: {}
+`
+	want := `# CI workflow
+name: CI
+on:
+  push:
+    branches: [main]
+permissions: {}
+jobs:
+  build: # the only job
+    runs-on: ubuntu-latest
+    steps:
+      - run: |
+          make build
+`
+	got, err := Format([]byte(src))
+	require.NoError(t, err)
+	assert.Equal(t, want, string(got))
+
+	again, err := Format(got)
+	require.NoError(t, err)
+	assert.Equal(t, want, string(again), "formatting is idempotent")
+
+	got, err = Format([]byte("\"on\": push\r\njobs: {}\r\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "\"on\": push\r\njobs: {}\r\n", string(got), "quoting and line endings are kept")
+
+	_, err = Format([]byte("jobs: [\n"))
+	assert.ErrorContains(t, err, "failed to parse YAML")
+
+	_, err = Format([]byte("# only a comment\n"))
+	assert.ErrorContains(t, err, "nothing to format")
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	assert.Empty(t, UnifiedDiff("ci.yml", []byte("a\n"), []byte("a\n")))
+
+	before := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	after := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13"
+	assert.Equal(t, `--- ci.yml
++++ ci.yml
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+\ No newline at end of file
+`, UnifiedDiff("ci.yml", []byte(before), []byte(after)))
+
+	assert.Equal(t, "--- x\n+++ x\n@@ -0,0 +1 @@\n+a\n", UnifiedDiff("x", nil, []byte("a\n")))
+}
@@ -0,0 +1,74 @@
+package linter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// GitRefs are the branches and tags of a git repository.
+type GitRefs struct {
+	Branches []string `json:"branches"`
+	Tags     []string `json:"tags"`
+}
+
+// ListGitRefs lists the branches and tags of the git repository dir is in.
+// Branches include those of the remotes, under their name on the remote,
+// since a clone usually only has the default branch locally.
+func ListGitRefs(ctx context.Context, dir string) (GitRefs, error) {
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "for-each-ref", "--format=%(refname)", "refs/heads", "refs/remotes", "refs/tags")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return GitRefs{}, fmt.Errorf("failed to list the git refs of %s: %s", dir, msg)
+		}
+		return GitRefs{}, fmt.Errorf("failed to list the git refs of %s: %w", dir, err)
+	}
+
+	branches, tags := map[string]bool{}, map[string]bool{}
+	for _, ref := range strings.Fields(string(out)) {
+		switch {
+		case strings.HasPrefix(ref, "refs/heads/"):
+			branches[strings.TrimPrefix(ref, "refs/heads/")] = true
+		case strings.HasPrefix(ref, "refs/remotes/"):
+			_, name, ok := strings.Cut(strings.TrimPrefix(ref, "refs/remotes/"), "/")
+			if ok && name != "HEAD" {
+				branches[name] = true
+			}
+		case strings.HasPrefix(ref, "refs/tags/"):
+			tags[strings.TrimPrefix(ref, "refs/tags/")] = true
+		}
+	}
+	return GitRefs{Branches: slices.Sorted(maps.Keys(branches)), Tags: slices.Sorted(maps.Keys(tags))}, nil
+}
+
+// gitVerifyRef returns an error unless ref names a commit of the git
+// repository dir is in.
+func gitVerifyRef(ctx context.Context, dir, ref string) error {
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "--verify", "--quiet", "--end-of-options", ref+"^{commit}")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("cannot compare with %s: %s", ref, msg)
+		}
+		return fmt.Errorf("cannot compare with %s: it is neither a result file nor a git ref of %s", ref, dir)
+	}
+	return nil
+}
+
+// gitShow returns the content of the file name of dir at ref, and whether
+// the ref has it.
+func gitShow(ctx context.Context, dir, ref, name string) ([]byte, bool) {
+	out, err := exec.CommandContext(ctx, "git", "-C", dir, "show", ref+":./"+name).Output()
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
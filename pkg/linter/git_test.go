@@ -0,0 +1,44 @@
+package linter
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gitRepo creates a git repository with a commit on main, or skips the
+// test when git is not installed.
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	dir := t.TempDir()
+	git(t, dir, "init", "-q", "-b", "main")
+	git(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial")
+	return dir
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(t, err, "%s", out)
+}
+
+func TestListGitRefs(t *testing.T) {
+	dir := gitRepo(t)
+	git(t, dir, "branch", "develop")
+	git(t, dir, "tag", "v1.0.0")
+	git(t, dir, "update-ref", "refs/remotes/origin/release/1.x", "HEAD")
+	git(t, dir, "update-ref", "refs/remotes/origin/main", "HEAD")
+	git(t, dir, "symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+
+	refs, err := ListGitRefs(t.Context(), dir)
+	require.NoError(t, err)
+	assert.Equal(t, GitRefs{Branches: []string{"develop", "main", "release/1.x"}, Tags: []string{"v1.0.0"}}, refs)
+
+	_, err = ListGitRefs(t.Context(), t.TempDir())
+	assert.ErrorContains(t, err, "failed to list the git refs")
+}
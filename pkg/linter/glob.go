@@ -0,0 +1,77 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// globs caches compiled filter patterns.
+var globs sync.Map
+
+// globRegexp compiles a branch, tag or path filter pattern with GitHub's
+// syntax: * matches anything but /, ** matches anything, ? and + make the
+// preceding character optional or repeatable, [] is a character class and
+// \ escapes the next character.
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := globs.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?', '+':
+			b.WriteByte(c)
+		case '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in pattern %q", pattern)
+			}
+			b.WriteString(pattern[i : i+end+1])
+			i += end
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	globs.Store(pattern, re)
+	return re, nil
+}
+
+// matchFilter reports whether value is selected by patterns, where a later
+// pattern overrides earlier ones and a leading ! excludes.
+func matchFilter(patterns []string, value string) (bool, error) {
+	matched := false
+	for _, p := range patterns {
+		negated := strings.HasPrefix(p, "!")
+		re, err := globRegexp(strings.TrimPrefix(p, "!"))
+		if err != nil {
+			return false, err
+		}
+		if re.MatchString(value) {
+			matched = !negated
+		}
+	}
+	return matched, nil
+}
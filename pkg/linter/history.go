@@ -0,0 +1,144 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// Kinds of flakiness hints.
+const (
+	HintMissingTimeout     = "missing-timeout"
+	HintMissingConcurrency = "missing-concurrency"
+	HintUnpinnedAction     = "unpinned-action"
+)
+
+// Hint priorities, from the share of recent runs that ended the way the
+// hint explains.
+const (
+	PriorityHigh   = "high"
+	PriorityMedium = "medium"
+	PriorityLow    = "low"
+)
+
+// highPriorityRate is the share of runs above which a hint is high priority.
+const highPriorityRate = 0.2
+
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// RunHistory counts the conclusions of recent runs of a workflow.
+type RunHistory struct {
+	Runs      int `json:"runs"`
+	Failures  int `json:"failures"`
+	Cancelled int `json:"cancelled"`
+	TimedOut  int `json:"timed_out"`
+}
+
+// FailureRate is the share of runs that did not succeed.
+func (h RunHistory) FailureRate() float64 {
+	if h.Runs == 0 {
+		return 0
+	}
+	return float64(h.Failures+h.Cancelled+h.TimedOut) / float64(h.Runs)
+}
+
+// FlakinessHint is a workflow setting that can explain failed or cancelled
+// runs.
+type FlakinessHint struct {
+	Kind     string `json:"kind"`
+	Message  string `json:"message"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Priority string `json:"priority"`
+}
+
+// FlakinessHints looks for missing timeouts, missing concurrency groups and
+// actions not pinned to a commit in a workflow, and ranks them by how many
+// runs in h ended the way each can cause: hung jobs are cancelled or time
+// out, overlapping runs race and fail or are cancelled by hand, and moving
+// action refs break runs that used to pass.
+func FlakinessHints(content []byte, h RunHistory) ([]FlakinessHint, error) {
+	content, err := normalizeEncoding(content)
+	if err != nil {
+		return nil, err
+	}
+	w, errs := actionlint.Parse(content)
+	if w == nil {
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("workflow cannot be parsed: %s", errs[0].Message)
+		}
+		return nil, fmt.Errorf("workflow cannot be parsed")
+	}
+
+	var hints []FlakinessHint
+	add := func(kind string, pos *actionlint.Pos, outcomes int, format string, args ...any) {
+		hint := FlakinessHint{
+			Kind:     kind,
+			Message:  fmt.Sprintf(format, args...),
+			Priority: priority(outcomes, h.Runs),
+		}
+		if pos != nil {
+			hint.Line, hint.Column = pos.Line, pos.Col
+		}
+		hints = append(hints, hint)
+	}
+
+	if w.Concurrency == nil {
+		add(HintMissingConcurrency, nil, h.Failures+h.Cancelled,
+			"workflow has no concurrency group, so runs for the same ref overlap and can race or be cancelled by hand")
+	}
+
+	ids := make([]string, 0, len(w.Jobs))
+	for id := range w.Jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		job := w.Jobs[id]
+		if job.WorkflowCall == nil && job.TimeoutMinutes == nil {
+			add(HintMissingTimeout, job.Pos, h.Cancelled+h.TimedOut,
+				"job %q has no timeout-minutes, so a hung run holds its runner for up to 6 hours", job.ID.Value)
+		}
+		for _, step := range job.Steps {
+			action, ok := step.Exec.(*actionlint.ExecAction)
+			if !ok || action.Uses == nil {
+				continue
+			}
+			uses := action.Uses.Value
+			if unpinnedAction(uses) {
+				add(HintUnpinnedAction, action.Uses.Pos, h.Failures,
+					"action %s is not pinned to a commit SHA, so upstream changes can break runs", uses)
+			}
+		}
+	}
+
+	rank := map[string]int{PriorityHigh: 0, PriorityMedium: 1, PriorityLow: 2}
+	sort.SliceStable(hints, func(i, j int) bool {
+		return rank[hints[i].Priority] < rank[hints[j].Priority]
+	})
+	return hints, nil
+}
+
+// unpinnedAction reports whether uses refers to a remote action by a tag or
+// branch. Local actions, docker images and expressions are not checked.
+func unpinnedAction(uses string) bool {
+	if strings.HasPrefix(uses, "./") || strings.HasPrefix(uses, "docker://") || strings.Contains(uses, "${{") {
+		return false
+	}
+	_, ref, ok := strings.Cut(uses, "@")
+	return !ok || !commitSHA.MatchString(ref)
+}
+
+func priority(outcomes, runs int) string {
+	switch {
+	case runs == 0 || outcomes == 0:
+		return PriorityLow
+	case float64(outcomes)/float64(runs) >= highPriorityRate:
+		return PriorityHigh
+	default:
+		return PriorityMedium
+	}
+}
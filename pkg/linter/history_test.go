@@ -0,0 +1,47 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlakinessHints(t *testing.T) {
+	workflow := `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    timeout-minutes: 10
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-go@0aaccfd150d50ccaeb58ebd88d36e91967a5f35b
+      - uses: ./.github/actions/local
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: go test ./...
+`
+	hints, err := FlakinessHints([]byte(workflow), RunHistory{Runs: 10, Cancelled: 3, Failures: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []FlakinessHint{
+		{Kind: HintMissingConcurrency, Message: "workflow has no concurrency group, so runs for the same ref overlap and can race or be cancelled by hand", Priority: PriorityHigh},
+		{Kind: HintMissingTimeout, Message: `job "test" has no timeout-minutes, so a hung run holds its runner for up to 6 hours`, Line: 10, Column: 3, Priority: PriorityHigh},
+		{Kind: HintUnpinnedAction, Message: "action actions/checkout@v4 is not pinned to a commit SHA, so upstream changes can break runs", Line: 7, Column: 15, Priority: PriorityMedium},
+	}, hints)
+
+	// Without runs nothing is prioritized
+	hints, err = FlakinessHints([]byte(workflow), RunHistory{})
+	require.NoError(t, err)
+	for _, h := range hints {
+		assert.Equal(t, PriorityLow, h.Priority)
+	}
+
+	_, err = FlakinessHints([]byte("on: [push"), RunHistory{})
+	assert.ErrorContains(t, err, "cannot be parsed")
+}
+
+func TestRunHistory_FailureRate(t *testing.T) {
+	assert.Zero(t, RunHistory{}.FailureRate())
+	assert.InDelta(t, 0.5, RunHistory{Runs: 8, Failures: 2, Cancelled: 1, TimedOut: 1}.FailureRate(), 1e-9)
+}
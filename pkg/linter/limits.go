@@ -0,0 +1,150 @@
+package linter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInputLimit is wrapped by the errors of workflows that exceed one of
+// the InputLimits, which are not linted.
+var ErrInputLimit = errors.New("workflow exceeds an input limit")
+
+// Input limits, used when InputLimits leaves them zero. GitHub itself
+// rejects workflows well below them, so only documents crafted to exhaust
+// the server reach them.
+const (
+	DefaultMaxInputBytes   = 1 << 20
+	DefaultMaxNestingDepth = 100
+	DefaultMaxYAMLNodes    = 100000
+)
+
+// InputLimits bound the workflows a Linter accepts, so that content sent
+// by an untrusted client cannot exhaust the memory or stack of a
+// long-running server. They are checked before actionlint parses the
+// workflow.
+type InputLimits struct {
+	// MaxBytes caps the size of a workflow. Zero means
+	// DefaultMaxInputBytes.
+	MaxBytes int
+	// MaxDepth caps how deeply the mappings and sequences of a workflow
+	// nest, counting those reached through aliases. Zero means
+	// DefaultMaxNestingDepth.
+	MaxDepth int
+	// MaxNodes caps the number of YAML nodes of a workflow with its
+	// aliases expanded, which is what a billion laughs document inflates.
+	// Zero means DefaultMaxYAMLNodes.
+	MaxNodes int
+}
+
+func (lim InputLimits) withDefaults() InputLimits {
+	if lim.MaxBytes <= 0 {
+		lim.MaxBytes = DefaultMaxInputBytes
+	}
+	if lim.MaxDepth <= 0 {
+		lim.MaxDepth = DefaultMaxNestingDepth
+	}
+	if lim.MaxNodes <= 0 {
+		lim.MaxNodes = DefaultMaxYAMLNodes
+	}
+	return lim
+}
+
+// CheckInput returns an error wrapping ErrInputLimit when content exceeds
+// one of lim. Every document of content is checked. Content that is not
+// valid YAML passes, since the syntax error is reported by the lint.
+func CheckInput(content []byte, lim InputLimits) error {
+	lim = lim.withDefaults()
+	if len(content) > lim.MaxBytes {
+		return fmt.Errorf("%w: %d bytes is more than the limit of %d", ErrInputLimit, len(content), lim.MaxBytes)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	w := &limitWalker{lim: lim, sizes: map[*yaml.Node]nodeSize{}}
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			return nil
+		}
+		if _, err := w.walk(&doc, 0); err != nil {
+			return err
+		}
+	}
+}
+
+// ReadInput reads the workflow at path, failing without reading the rest
+// of it once it exceeds lim.
+func ReadInput(path string, lim InputLimits) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	limit := lim.withDefaults().MaxBytes
+	content, err := io.ReadAll(io.LimitReader(f, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > limit {
+		return nil, fmt.Errorf("%w: %s is more than the limit of %d bytes", ErrInputLimit, path, limit)
+	}
+	return content, nil
+}
+
+// nodeSize is the number of nodes of a node with its aliases expanded,
+// and how deeply they nest below it.
+type nodeSize struct {
+	nodes, height int
+}
+
+// limitWalker measures YAML nodes without expanding aliases: the size of
+// an anchored node is kept, and an alias to it counts it again, so the
+// walk is linear in the document while the totals are those of the
+// expanded document.
+type limitWalker struct {
+	lim   InputLimits
+	nodes int
+	sizes map[*yaml.Node]nodeSize
+}
+
+func (w *limitWalker) walk(n *yaml.Node, depth int) (nodeSize, error) {
+	if n.Kind == yaml.AliasNode {
+		// An alias whose anchor is still being walked refers to one of its
+		// own ancestors, which yaml.v3 refuses to decode
+		size := w.sizes[n.Alias]
+		w.nodes += size.nodes
+		return size, w.check(depth + size.height)
+	}
+
+	w.nodes++
+	if err := w.check(depth); err != nil {
+		return nodeSize{}, err
+	}
+	size := nodeSize{nodes: 1}
+	for _, c := range n.Content {
+		child, err := w.walk(c, depth+1)
+		if err != nil {
+			return nodeSize{}, err
+		}
+		size.nodes += child.nodes
+		size.height = max(size.height, child.height+1)
+	}
+	if n.Anchor != "" {
+		w.sizes[n] = size
+	}
+	return size, nil
+}
+
+func (w *limitWalker) check(depth int) error {
+	switch {
+	case depth > w.lim.MaxDepth:
+		return fmt.Errorf("%w: YAML nested more than %d levels deep", ErrInputLimit, w.lim.MaxDepth)
+	case w.nodes > w.lim.MaxNodes:
+		return fmt.Errorf("%w: more than %d YAML nodes once aliases are expanded", ErrInputLimit, w.lim.MaxNodes)
+	}
+	return nil
+}
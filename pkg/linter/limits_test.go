@@ -0,0 +1,100 @@
+package linter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// billionLaughs returns a document whose aliases expand each level to ten
+// copies of the one before.
+func billionLaughs(levels int) string {
+	var b strings.Builder
+	b.WriteString("a0: &a0 [lol]\n")
+	for i := 1; i <= levels; i++ {
+		b.WriteString("a" + string(rune('0'+i)) + ": &a" + string(rune('0'+i)) + " [")
+		for j := 0; j < 10; j++ {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString("*a" + string(rune('0'+i-1)))
+		}
+		b.WriteString("]\n")
+	}
+	return b.String()
+}
+
+func TestCheckInput(t *testing.T) {
+	workflow := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n"
+
+	tests := []struct {
+		name    string
+		content string
+		limits  InputLimits
+		want    string
+	}{
+		{"workflow", workflow, InputLimits{}, ""},
+		{"syntax error", "on: [push\n", InputLimits{}, ""},
+		{"too large", workflow, InputLimits{MaxBytes: 10}, "more than the limit of 10"},
+		{"too deep", strings.Repeat("[", 20) + strings.Repeat("]", 20), InputLimits{MaxDepth: 10}, "nested more than 10 levels"},
+		{"billion laughs", billionLaughs(9), InputLimits{}, "once aliases are expanded"},
+		{"few aliases", billionLaughs(2), InputLimits{}, ""},
+		{"deep through aliases", "a: &a [[[[x]]]]\nb: [[[[*a]]]]\n", InputLimits{MaxDepth: 8}, "nested more than 8 levels"},
+		{"later document", workflow + "---\n" + billionLaughs(9), InputLimits{}, "once aliases are expanded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckInput([]byte(tt.content), tt.limits)
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInputLimit)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestLinter_InputLimits(t *testing.T) {
+	l := New(Options{Limits: InputLimits{MaxBytes: 64}})
+	content := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n"
+
+	_, err := l.Lint(context.Background(), Input{Content: []byte(content)})
+	assert.ErrorIs(t, err, ErrInputLimit)
+
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	_, err = l.Lint(context.Background(), Input{Path: path})
+	assert.ErrorIs(t, err, ErrInputLimit)
+
+	summary := l.LintFiles(context.Background(), []string{path})
+	require.Len(t, summary.Results, 1)
+	assert.Contains(t, summary.Results[0].Errors[0].Message, "exceeds an input limit")
+}
+
+func FuzzLint(f *testing.F) {
+	f.Add("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n")
+	f.Add("on: push\njobs:\n  test: &t\n    runs-on: ubuntu-latest\n    steps: [{run: make}]\n  copy: *t\n")
+	f.Add(billionLaughs(9))
+	f.Add(strings.Repeat("- ", 200) + "x\n")
+	f.Add("a: &a [*a]\n")
+	f.Add("on: push\n---\njobs: {}\n")
+
+	l := New(Options{})
+	f.Fuzz(func(t *testing.T, content string) {
+		if err := CheckInput([]byte(content), InputLimits{}); err != nil {
+			if !errors.Is(err, ErrInputLimit) {
+				t.Fatalf("CheckInput returned an unexpected error: %v", err)
+			}
+			return
+		}
+		// Whatever passes the limits must lint without panicking
+		_, _ = l.Lint(context.Background(), Input{Content: []byte(content)})
+	})
+}
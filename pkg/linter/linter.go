@@ -0,0 +1,373 @@
+// Package linter lints GitHub Actions workflow files with actionlint and
+// reports the findings in the format used by the actionlint-mcp server.
+//
+// It can be embedded by other Go programs that want the same behavior as
+// the MCP tools without running the server:
+//
+//	l := linter.New(linter.DefaultOptions())
+//	result, err := l.Lint(ctx, linter.Input{Path: ".github/workflows/ci.yml"})
+package linter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/rhysd/actionlint"
+)
+
+// DefaultConfigFile is the actionlint configuration picked up by
+// DefaultOptions when it exists in the working directory.
+const DefaultConfigFile = ".github/actionlint.yaml"
+
+// InlineFileName names workflows linted from content without a path.
+const InlineFileName = "inline.yml"
+
+// ErrNoInput is returned when an Input has neither a path nor content.
+var ErrNoInput = errors.New("either a path or content must be provided")
+
+// InitError is returned when a Linter cannot be set up for a workflow
+// because the actionlint configuration, or that of its repository, is
+// invalid. Nothing is linted.
+type InitError struct {
+	// Op is what failed, such as "create linter".
+	Op  string
+	Err error
+}
+
+func (e *InitError) Error() string { return fmt.Sprintf("failed to %s: %v", e.Op, e.Err) }
+
+func (e *InitError) Unwrap() error { return e.Err }
+
+// Options configures a Linter.
+type Options struct {
+	// Shellcheck is the shellcheck command used to check run: scripts.
+	// Empty disables the check.
+	Shellcheck string
+	// Pyflakes is the pyflakes command used to check Python scripts.
+	// Empty disables the check.
+	Pyflakes string
+	// ShellcheckWASM is the path to a WASI build of shellcheck, run in
+	// process when the Shellcheck command is empty or not found. Empty
+	// means the build embedded with the shellcheck_wasm tag, if any.
+	ShellcheckWASM string
+	// ExternalTimeout bounds a single shellcheck or pyflakes run. Zero means
+	// DefaultExternalTimeout.
+	ExternalTimeout time.Duration
+	// ExternalOutputLimit caps the bytes of output kept from a single
+	// shellcheck or pyflakes run. Zero means DefaultExternalOutputLimit.
+	ExternalOutputLimit int
+	// ConfigFile is the path to an actionlint.yaml configuration. Empty
+	// means no configuration file is used.
+	ConfigFile string
+	// Rules configures the checks run in addition to actionlint's own.
+	Rules rules.Config
+	// Limits bound the size and shape of the workflows linted.
+	Limits InputLimits
+	// SkipRefFilters turns off checking branches and tags filters against
+	// the refs of the git repository, for template repositories, whose
+	// workflows are written for the refs of the repositories created from
+	// them.
+	SkipRefFilters bool
+	// ServerVersion identifies the program embedding the linter in result
+	// metadata.
+	ServerVersion string
+}
+
+// DefaultOptions returns the options used by the MCP server: external
+// linters from SHELLCHECK_COMMAND, SHELLCHECK_WASM and PYFLAKES_COMMAND,
+// their timeout in
+// seconds from EXTERNAL_LINTER_TIMEOUT, the size limit of workflows in
+// bytes from MAX_WORKFLOW_SIZE, and DefaultConfigFile when it exists.
+func DefaultOptions() Options {
+	configFile := DefaultConfigFile
+	if _, err := os.Stat(configFile); os.IsNotExist(err) {
+		configFile = ""
+	}
+	var timeout time.Duration
+	if seconds, err := strconv.Atoi(os.Getenv("EXTERNAL_LINTER_TIMEOUT")); err == nil && seconds > 0 {
+		timeout = time.Duration(seconds) * time.Second
+	}
+	var limits InputLimits
+	if size, err := strconv.Atoi(os.Getenv("MAX_WORKFLOW_SIZE")); err == nil && size > 0 {
+		limits.MaxBytes = size
+	}
+
+	return Options{
+		Shellcheck:      os.Getenv("SHELLCHECK_COMMAND"),
+		ShellcheckWASM:  os.Getenv("SHELLCHECK_WASM"),
+		Pyflakes:        os.Getenv("PYFLAKES_COMMAND"),
+		ExternalTimeout: timeout,
+		ConfigFile:      configFile,
+		Limits:          limits,
+	}
+}
+
+// Input is a single workflow to lint. When Content is nil the workflow is
+// read from Path; otherwise Path only names the workflow in the result and
+// defaults to InlineFileName.
+type Input struct {
+	Path    string
+	Content []byte
+}
+
+// Linter lints workflows with a fixed set of options. It is safe for
+// concurrent use.
+type Linter struct {
+	opts Options
+}
+
+// New returns a Linter using opts.
+func New(opts Options) *Linter {
+	return &Linter{opts: opts}
+}
+
+// Options returns the options the Linter was created with.
+func (l *Linter) Options() Options {
+	return l.opts
+}
+
+// Lint lints a single workflow. Problems in the workflow are reported in
+// the result; the error is only non-nil when linting could not run.
+func (l *Linter) Lint(ctx context.Context, in Input) (*LintResult, error) {
+	start := time.Now()
+	failures := &externalFailures{}
+	result, err := l.lint(ctx, in, failures)
+	if err != nil {
+		return nil, err
+	}
+	result.SchemaVersion = SchemaVersion
+	result.Meta = l.newMeta(start, failures.list())
+	return result, nil
+}
+
+func (l *Linter) lint(ctx context.Context, in Input, failures *externalFailures) (*LintResult, error) {
+	path, content := in.Path, in.Content
+	switch {
+	case content != nil:
+		if path == "" {
+			path = InlineFileName
+		}
+	case path != "":
+		var err error
+		content, err = ReadInput(path, l.opts.Limits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+	default:
+		return nil, ErrNoInput
+	}
+
+	content, err := normalizeEncoding(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode file: %w", err)
+	}
+	if err := CheckInput(content, l.opts.Limits); err != nil {
+		return nil, err
+	}
+
+	// The rules created for the document being linted, kept to collect the
+	// fixes they offer
+	var custom []actionlint.Rule
+	cfg := l.opts.Rules
+	if path != InlineFileName {
+		cfg.Root = repositoryRoot(path)
+		packs, err := repositoryPacks(cfg.Root)
+		if err != nil {
+			return nil, &InitError{Op: "load repository config", Err: err}
+		}
+		cfg = cfg.WithPacks(packs...)
+	}
+	// shellcheck and pyflakes are run by the linter's own rules rather than
+	// actionlint's, which cannot stop a run that hangs
+	external := l.externalLinters()
+	linter, err := actionlint.NewLinter(io.Discard, &actionlint.LinterOptions{
+		ConfigFile:     l.configFile(path),
+		IgnorePatterns: []string{},
+		OnRulesCreated: func(builtin []actionlint.Rule) []actionlint.Rule {
+			custom = rules.New(cfg)
+			rs := append(builtin, custom...)
+			if external.shellcheck != nil || external.pyflakes != nil {
+				runner := newExternalLinter(ctx, l.opts.ExternalTimeout, l.opts.ExternalOutputLimit, failures)
+				if external.shellcheck != nil {
+					rs = append(rs, newShellcheck(*external.shellcheck, runner))
+				}
+				if external.pyflakes != nil {
+					rs = append(rs, newPyflakes(*external.pyflakes, runner))
+				}
+				rs = append(rs, runner)
+			}
+			return rs
+		},
+	})
+	if err != nil {
+		return nil, &InitError{Op: "create linter", Err: err}
+	}
+
+	project, err := l.project(path)
+	if err != nil {
+		return nil, &InitError{Op: "load project config", Err: err}
+	}
+
+	// GitHub only reads the first document, as actionlint does, but with
+	// the style pack every document is linted so that problems are not
+	// hidden behind the separator.
+	docs := splitDocuments(content)
+	if len(docs) <= 1 || !cfg.Active(KindMultiDocument) {
+		result, err := lintDocument(linter, project, cfg, path, content, &custom)
+		if err != nil {
+			return nil, err
+		}
+		if path != InlineFileName {
+			result.Errors = append(result.Errors, l.crossFileFindings(ctx, cfg, path, content)...)
+			result.Valid = len(result.Errors) == 0
+		}
+		assignFixIDs(result)
+		return result, nil
+	}
+
+	result := &LintResult{
+		Errors: []LintError{{
+			Message:  fmt.Sprintf("file contains %d YAML documents; GitHub Actions only reads the first one", len(docs)),
+			Line:     docs[1].Line - 1,
+			Column:   1,
+			Kind:     KindMultiDocument,
+			Severity: SeverityForKind(KindMultiDocument),
+		}},
+		FilePath: path,
+	}
+	for i, doc := range docs {
+		r, err := lintDocument(linter, project, cfg, path, doc.Content, &custom)
+		if err != nil {
+			return nil, err
+		}
+		// The first document is the workflow GitHub reads, so it is the
+		// one checked against the rest of the repository
+		if i == 0 && path != InlineFileName {
+			r.Errors = append(r.Errors, l.crossFileFindings(ctx, cfg, path, doc.Content)...)
+		}
+		moved := map[*rules.Fix]bool{}
+		for _, e := range r.Errors {
+			e.Message = fmt.Sprintf("document %d: %s", i+1, e.Message)
+			if e.Line > 0 {
+				e.Line += doc.Line - 1
+			}
+			if e.Fix != nil && !moved[e.Fix] {
+				moved[e.Fix] = true
+				offsetFix(e.Fix, doc.Line-1)
+			}
+			result.Errors = append(result.Errors, e)
+		}
+	}
+
+	assignFixIDs(result)
+	return result, nil
+}
+
+// project returns the repository path belongs to, so that its
+// .github/actionlint.yaml applies as it would for the actionlint CLI. The
+// configuration file path is linted with takes precedence, and unnamed
+// content has no project. A new set of projects is made for every lint,
+// so no configuration is cached across repositories.
+func (l *Linter) project(path string) (*actionlint.Project, error) {
+	if l.configFile(path) != "" || path == InlineFileName {
+		return nil, nil
+	}
+	return actionlint.NewProjects().At(path)
+}
+
+// configFile returns the actionlint configuration file to lint path with.
+// A ConfigFile that is the .github/actionlint.yaml of a repository, as the
+// one DefaultOptions finds in the working directory, only applies to the
+// files of that repository. The files of another repository, which a
+// server shared by several of them lints, get their own repository's
+// configuration and runner labels instead.
+func (l *Linter) configFile(path string) string {
+	cfg := l.opts.ConfigFile
+	if cfg == "" || path == InlineFileName || !isRepositoryConfig(cfg) {
+		return cfg
+	}
+	root, err := filepath.Abs(filepath.Dir(filepath.Dir(cfg)))
+	if err != nil {
+		return cfg
+	}
+	file, err := filepath.Abs(path)
+	if err != nil {
+		return cfg
+	}
+	if rel := relativeTo(root, file); strings.HasPrefix(rel, "../") || filepath.IsAbs(rel) {
+		return ""
+	}
+	return cfg
+}
+
+// isRepositoryConfig reports whether path is the actionlint configuration
+// of a repository rather than a file shared by several.
+func isRepositoryConfig(path string) bool {
+	switch filepath.Base(path) {
+	case "actionlint.yaml", "actionlint.yml":
+		return filepath.Base(filepath.Dir(path)) == ".github"
+	}
+	return false
+}
+
+func lintDocument(linter *actionlint.Linter, project *actionlint.Project, cfg rules.Config, path string, content []byte, custom *[]actionlint.Rule) (*LintResult, error) {
+	if cfg.Active(KindNotWorkflow) {
+		if kind := DetectDocumentKind(content); kind != DocumentWorkflow && kind != DocumentUnknown {
+			return notWorkflowResult(path, kind), nil
+		}
+	}
+
+	*custom = nil
+	errs, err := linter.Lint(path, content, project)
+	if err != nil {
+		return nil, fmt.Errorf("linting failed: %w", err)
+	}
+
+	result := newResult(path, errs)
+	if cfg.Active(KindContextAvailability) {
+		explainContexts(result, content)
+	}
+	attachFixes(result, *custom)
+	quoteGlobs(result, content)
+	return result, nil
+}
+
+// crossFileFindings returns the findings of the checks run with cfg that
+// read other files of the repository than the workflow at path.
+func (l *Linter) crossFileFindings(ctx context.Context, cfg rules.Config, path string, content []byte) []LintError {
+	var errs []LintError
+	if cfg.Active(KindReusableCalls) {
+		errs = append(errs, callGraphFindings(path, content, cfg.Reusable.MaxDepth)...)
+	}
+	if cfg.Active(KindOutputContract) {
+		errs = append(errs, outputContractFindings(path, content)...)
+	}
+	if cfg.Active(KindDockerAction) {
+		errs = append(errs, dockerActionFindings(path, content)...)
+	}
+	if cfg.Active(KindDuplicateName) {
+		errs = append(errs, duplicateNameFindings(path, content)...)
+	}
+	if cfg.Active(rules.KindSchedule) {
+		errs = append(errs, scheduleCollisionFindings(path, content)...)
+	}
+	if cfg.Active(KindConcurrencyDeadlock) || cfg.Active(KindConcurrencyStarvation) {
+		for _, e := range concurrencyFindings(path, content) {
+			if cfg.Active(e.Kind) {
+				errs = append(errs, e)
+			}
+		}
+	}
+	if cfg.Active(KindUnknownRef) && !l.opts.SkipRefFilters {
+		errs = append(errs, refFilterFindings(ctx, path, content)...)
+	}
+	return errs
+}
@@ -0,0 +1,225 @@
+package linter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validWorkflow = `name: Test
+on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - run: echo "Hello World"`
+
+const invalidWorkflow = `name: Test
+on: push
+jobs:
+  job1:
+    needs: nonexistent
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "test"`
+
+func TestLint_Content(t *testing.T) {
+	l := New(Options{})
+
+	result, err := l.Lint(context.Background(), Input{Content: []byte(validWorkflow)})
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, InlineFileName, result.FilePath)
+	assert.Equal(t, SchemaVersion, result.SchemaVersion)
+
+	result, err = l.Lint(context.Background(), Input{Path: "ci.yml", Content: []byte(invalidWorkflow)})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.NotEmpty(t, result.Errors)
+	assert.Equal(t, "ci.yml", result.FilePath)
+	assert.Contains(t, result.Errors[0].Message, "nonexistent")
+	assert.Equal(t, 4, result.Errors[0].Line)
+	assert.NotEmpty(t, result.Errors[0].Severity)
+}
+
+func TestLint_Path(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	require.NoError(t, os.WriteFile(path, []byte(validWorkflow), 0644))
+
+	result, err := New(Options{}).Lint(context.Background(), Input{Path: path})
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, path, result.FilePath)
+
+	_, err = New(Options{}).Lint(context.Background(), Input{Path: filepath.Join(t.TempDir(), "missing.yml")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read file")
+}
+
+func TestLint_NoInput(t *testing.T) {
+	_, err := New(Options{}).Lint(context.Background(), Input{})
+	assert.ErrorIs(t, err, ErrNoInput)
+}
+
+func TestLint_ConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "actionlint.yaml")
+	require.NoError(t, os.WriteFile(config, []byte("self-hosted-runner:\n  labels:\n    - custom-runner\n"), 0644))
+
+	workflow := []byte(`on: push
+jobs:
+  test:
+    runs-on: custom-runner
+    steps:
+      - run: echo hi`)
+
+	result, err := New(Options{}).Lint(context.Background(), Input{Content: workflow})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+
+	result, err = New(Options{ConfigFile: config}).Lint(context.Background(), Input{Content: workflow})
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+
+	require.NoError(t, os.WriteFile(config, []byte("self-hosted-runner: [custom-runner\n"), 0644))
+	_, err = New(Options{ConfigFile: config}).Lint(context.Background(), Input{Content: workflow})
+	var initErr *InitError
+	require.ErrorAs(t, err, &initErr)
+	assert.Equal(t, "create linter", initErr.Op)
+	assert.Contains(t, err.Error(), "failed to create linter")
+}
+
+func TestSeverityForKind(t *testing.T) {
+	assert.Equal(t, SeverityError, SeverityForKind("syntax-check"))
+	assert.Equal(t, SeverityError, SeverityForKind("type-check"))
+	assert.Equal(t, SeverityWarning, SeverityForKind("shellcheck"))
+	assert.Equal(t, SeverityWarning, SeverityForKind("pyflakes"))
+	assert.Equal(t, SeverityError, SeverityForKind("expression"))
+	assert.Equal(t, SeverityError, SeverityForKind("job-needs"))
+	assert.Equal(t, SeverityWarning, SeverityForKind("deprecated-commands"))
+}
+
+func TestLint_Meta(t *testing.T) {
+	l := New(Options{Shellcheck: "definitely-not-a-shellcheck-binary", ServerVersion: "v1.2.3"})
+
+	result, err := l.Lint(context.Background(), Input{Content: []byte(validWorkflow)})
+	require.NoError(t, err)
+	require.NotNil(t, result.Meta)
+	assert.NotEmpty(t, result.Meta.ActionlintVersion)
+	assert.Equal(t, "v1.2.3", result.Meta.ServerVersion)
+	assert.GreaterOrEqual(t, result.Meta.DurationMS, 0.0)
+	assert.False(t, result.Meta.Shellcheck, "missing commands are reported as not run")
+	assert.False(t, result.Meta.Pyflakes)
+	assert.Equal(t, `shellcheck command "definitely-not-a-shellcheck-binary" was not found, so scripts were not checked with it`, result.Meta.ExternalLinterError)
+
+	summary := l.LintFiles(context.Background(), nil)
+	assert.Equal(t, SchemaVersion, summary.SchemaVersion)
+	require.NotNil(t, summary.Meta)
+	assert.Equal(t, "v1.2.3", summary.Meta.ServerVersion)
+	assert.Equal(t, result.Meta.ExternalLinterError, summary.Meta.ExternalLinterError)
+
+	result, err = New(Options{}).Lint(context.Background(), Input{Content: []byte(validWorkflow)})
+	require.NoError(t, err)
+	assert.Empty(t, result.Meta.ExternalLinterError, "linters that are not configured are not an error")
+}
+
+func TestLint_ProjectConfig(t *testing.T) {
+	repo := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(repo, ".git"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".github", "workflows"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".github", "actionlint.yaml"), []byte("self-hosted-runner:\n  labels:\n    - custom-runner\n"), 0644))
+
+	workflow := []byte(`on: push
+jobs:
+  test:
+    runs-on: custom-runner
+    steps:
+      - run: echo hi`)
+	path := filepath.Join(repo, ".github", "workflows", "ci.yml")
+
+	// Named content picks up the config of the repository it belongs to
+	result, err := New(Options{}).Lint(context.Background(), Input{Path: path, Content: workflow})
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, path, result.FilePath)
+
+	// Unnamed content does not
+	result, err = New(Options{}).Lint(context.Background(), Input{Content: workflow})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+}
+
+func TestLint_ProjectConfigIsolation(t *testing.T) {
+	repos := map[string]string{}
+	for _, name := range []string{"a", "b"} {
+		repo := t.TempDir()
+		require.NoError(t, os.Mkdir(filepath.Join(repo, ".git"), 0755))
+		require.NoError(t, os.MkdirAll(filepath.Join(repo, ".github", "workflows"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repo, ".github", "actionlint.yaml"), []byte("self-hosted-runner:\n  labels:\n    - "+name+"-runner\n"), 0644))
+		repos[name] = repo
+	}
+	workflow := func(label string) []byte {
+		return []byte("on: push\njobs:\n  test:\n    runs-on: " + label + "\n    steps:\n      - run: echo hi\n")
+	}
+
+	// The configuration of repository a, as DefaultOptions finds it when
+	// the server runs there, applies to a's files and unnamed content
+	l := New(Options{ConfigFile: filepath.Join(repos["a"], ".github", "actionlint.yaml")})
+	result, err := l.Lint(context.Background(), Input{Path: filepath.Join(repos["a"], ".github", "workflows", "ci.yml"), Content: workflow("a-runner")})
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	result, err = l.Lint(context.Background(), Input{Content: workflow("a-runner")})
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+
+	// but not to those of repository b, which get b's labels only
+	path := filepath.Join(repos["b"], ".github", "workflows", "ci.yml")
+	result, err = l.Lint(context.Background(), Input{Path: path, Content: workflow("b-runner")})
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	result, err = l.Lint(context.Background(), Input{Path: path, Content: workflow("a-runner")})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+
+	// A configuration outside of a repository applies everywhere
+	shared := filepath.Join(t.TempDir(), "actionlint.yaml")
+	require.NoError(t, os.WriteFile(shared, []byte("self-hosted-runner:\n  labels:\n    - shared-runner\n"), 0644))
+	result, err = New(Options{ConfigFile: shared}).Lint(context.Background(), Input{Path: path, Content: workflow("shared-runner")})
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+}
+
+func TestLint_RepositoryFiles(t *testing.T) {
+	repo := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".github", "workflows"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "package-lock.json"), []byte("{}"), 0644))
+
+	workflow := []byte(`on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    timeout-minutes: 10
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-node@v4
+      - run: npm test`)
+
+	// Rules looking at other files see the repository of the workflow
+	cost := Options{Rules: rules.Config{Packs: []string{"cost"}}}
+	result, err := New(cost).Lint(context.Background(), Input{Path: filepath.Join(repo, ".github", "workflows", "ci.yml"), Content: workflow})
+	require.NoError(t, err)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "setup-cache", result.Errors[0].Kind)
+	assert.Contains(t, result.Errors[0].Message, `add "cache: npm"`)
+
+	result, err = New(cost).Lint(context.Background(), Input{Content: workflow})
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+}
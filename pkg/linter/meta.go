@@ -0,0 +1,67 @@
+package linter
+
+import (
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const actionlintModule = "github.com/rhysd/actionlint"
+
+// Meta describes how a result was produced. It is meant for triaging
+// reports where the same workflow lints differently in two environments.
+// In a Summary, DurationMS covers all of the files.
+type Meta struct {
+	ActionlintVersion string  `json:"actionlint_version"`
+	ServerVersion     string  `json:"server_version,omitempty"`
+	DurationMS        float64 `json:"duration_ms"`
+	Shellcheck        bool    `json:"shellcheck"`
+	// ShellcheckWASM is set when shellcheck ran as a WebAssembly build in
+	// process rather than as a command.
+	ShellcheckWASM bool `json:"shellcheck_wasm,omitempty"`
+	Pyflakes       bool `json:"pyflakes"`
+	// ExternalLinterError explains why a configured shellcheck or pyflakes
+	// produced no findings: its command was not found, or it failed to
+	// run, as for a flag it does not know. Empty when both ran or are not
+	// configured.
+	ExternalLinterError string `json:"external_linter_error,omitempty"`
+
+	// failures are the failed runs behind ExternalLinterError, which a
+	// Summary gathers from its results
+	failures []string
+}
+
+// ActionlintVersion returns the version of the actionlint module linked
+// into the binary, or "unknown" when build information is unavailable.
+var ActionlintVersion = sync.OnceValue(func() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == actionlintModule {
+				if dep.Replace != nil {
+					return dep.Replace.Version
+				}
+				return dep.Version
+			}
+		}
+	}
+	return "unknown"
+})
+
+// newMeta returns the metadata for a lint that started at start, during
+// which the external linters failed as described by failures.
+func (l *Linter) newMeta(start time.Time, failures []string) *Meta {
+	failures = slices.Compact(slices.Sorted(slices.Values(failures)))
+	external := l.externalLinters()
+	return &Meta{
+		ActionlintVersion:   ActionlintVersion(),
+		ServerVersion:       l.opts.ServerVersion,
+		DurationMS:          float64(time.Since(start).Microseconds()) / 1000,
+		Shellcheck:          external.shellcheck != nil,
+		ShellcheckWASM:      external.shellcheck != nil && external.shellcheck.module != nil,
+		Pyflakes:            external.pyflakes != nil,
+		ExternalLinterError: strings.Join(append(external.problems, failures...), "; "),
+		failures:            failures,
+	}
+}
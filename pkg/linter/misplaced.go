@@ -0,0 +1,86 @@
+package linter
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WorkflowsDir is where GitHub looks for workflows, relative to the
+// repository root. Subdirectories of it are not read.
+var WorkflowsDir = filepath.Join(".github", "workflows")
+
+// skippedDirs are never searched for misplaced workflows. Workflow
+// templates intentionally live outside WorkflowsDir.
+var skippedDirs = map[string]bool{
+	".git":               true,
+	"node_modules":       true,
+	"vendor":             true,
+	"workflow-templates": true,
+}
+
+// MisplacedWorkflow is a workflow file that GitHub ignores because of
+// where it lives in the repository.
+type MisplacedWorkflow struct {
+	Path          string `json:"path"`
+	SuggestedPath string `json:"suggested_path"`
+}
+
+// FindMisplacedWorkflows walks the repository at root for YAML files that
+// look like workflows but are not directly inside WorkflowsDir, where
+// GitHub would never run them. Results are sorted by path.
+func FindMisplacedWorkflows(root string) ([]MisplacedWorkflow, error) {
+	workflowsDir := filepath.Join(root, WorkflowsDir)
+
+	var found []MisplacedWorkflow
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && skippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !IsWorkflowFile(path) || filepath.Dir(path) == workflowsDir {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if looksLikeWorkflow(content) {
+			found = append(found, MisplacedWorkflow{
+				Path:          path,
+				SuggestedPath: filepath.Join(workflowsDir, filepath.Base(path)),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return found, nil
+}
+
+// looksLikeWorkflow is stricter than DetectDocumentKind: both triggers and
+// jobs must be present, so that other YAML with an "on" key is not flagged.
+func looksLikeWorkflow(content []byte) bool {
+	content, err := normalizeEncoding(content)
+	if err != nil {
+		return false
+	}
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return false
+	}
+	_, hasOn := doc["on"]
+	_, hasJobs := doc["jobs"]
+	return hasOn && hasJobs
+}
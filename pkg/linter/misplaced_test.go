@@ -0,0 +1,38 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindMisplacedWorkflows(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	write(".github/workflows/ci.yml", validWorkflow)
+	write(".github/workflows/nested/deploy.yml", validWorkflow)
+	write(".github/workflow/typo.yaml", validWorkflow)
+	write("ci/release.YML", validWorkflow)
+	write(".github/dependabot.yml", "version: 2\nupdates: []\n")
+	write("config.yml", "on: true\n")
+	write("node_modules/pkg/ci.yml", validWorkflow)
+	write("workflow-templates/template.yml", validWorkflow)
+
+	found, err := FindMisplacedWorkflows(root)
+	require.NoError(t, err)
+
+	suggested := filepath.Join(root, ".github", "workflows")
+	assert.Equal(t, []MisplacedWorkflow{
+		{Path: filepath.Join(root, ".github/workflow/typo.yaml"), SuggestedPath: filepath.Join(suggested, "typo.yaml")},
+		{Path: filepath.Join(root, ".github/workflows/nested/deploy.yml"), SuggestedPath: filepath.Join(suggested, "deploy.yml")},
+		{Path: filepath.Join(root, "ci/release.YML"), SuggestedPath: filepath.Join(suggested, "release.YML")},
+	}, found)
+}
@@ -0,0 +1,189 @@
+package linter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/rhysd/actionlint"
+)
+
+// KindDuplicateName is the kind of findings about workflow names and check
+// names shared by several workflows of a repository.
+const KindDuplicateName = rules.KindDuplicateName
+
+// checkRun is a check a workflow reports for a job. viaCall is set when the
+// job is a job of a reusable workflow, and pos is then the caller's uses:.
+type checkRun struct {
+	name    string
+	job     string
+	pos     *actionlint.Pos
+	viaCall bool
+}
+
+// repoWorkflows parses the workflows of a repository once each.
+type repoWorkflows struct {
+	root   string
+	parsed map[string]*actionlint.Workflow
+}
+
+func (r *repoWorkflows) parse(path string, content []byte) *actionlint.Workflow {
+	if w, ok := r.parsed[path]; ok {
+		return w
+	}
+	r.parsed[path] = nil
+	if content == nil {
+		var err error
+		if content, err = os.ReadFile(path); err != nil {
+			return nil
+		}
+		if content, err = normalizeEncoding(content); err != nil {
+			return nil
+		}
+	}
+	w, _ := actionlint.Parse(content)
+	r.parsed[path] = w
+	return w
+}
+
+// onlyCalled reports whether w only runs when another workflow calls it,
+// so that it has no name or checks of its own.
+func onlyCalled(w *actionlint.Workflow) bool {
+	for _, e := range w.On {
+		if _, ok := e.(*actionlint.WorkflowCallEvent); !ok {
+			return false
+		}
+	}
+	return len(w.On) > 0
+}
+
+// checkRuns returns the checks the jobs of w report, following calls to
+// local reusable workflows, whose checks are named "caller / callee".
+// Matrix jobs and names set by expressions are skipped, since their checks
+// are named from values known only at run time.
+func (r *repoWorkflows) checkRuns(w *actionlint.Workflow, seen map[*actionlint.Workflow]bool) []checkRun {
+	if w == nil || seen[w] {
+		return nil
+	}
+	seen[w] = true
+	defer delete(seen, w)
+
+	ids := make([]string, 0, len(w.Jobs))
+	for id := range w.Jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var runs []checkRun
+	for _, id := range ids {
+		job := w.Jobs[id]
+		name := job.ID.Value
+		if job.Name != nil {
+			name = job.Name.Value
+		}
+		if strings.Contains(name, "${{") || (job.Strategy != nil && job.Strategy.Matrix != nil) {
+			continue
+		}
+
+		call := job.WorkflowCall
+		if call == nil {
+			runs = append(runs, checkRun{name: name, job: job.ID.Value, pos: job.ID.Pos})
+			continue
+		}
+		if call.Uses == nil || !strings.HasPrefix(call.Uses.Value, "./") {
+			continue
+		}
+		callee := r.parse(filepath.Join(r.root, filepath.FromSlash(call.Uses.Value)), nil)
+		for _, c := range r.checkRuns(callee, seen) {
+			runs = append(runs, checkRun{name: name + " / " + c.name, job: job.ID.Value, pos: call.Uses.Pos, viaCall: true})
+		}
+	}
+	return runs
+}
+
+// duplicateNameFindings reports the name of the workflow at path when
+// another workflow in its directory has the same name, and the checks of
+// its calls to reusable workflows that another job of the repository
+// reports under the same name, which makes a required check on that name
+// ambiguous. Only workflows in WorkflowsDir are compared.
+func duplicateNameFindings(path string, content []byte) []LintError {
+	root := repositoryRoot(path)
+	path = filepath.Clean(path)
+	dir := filepath.Dir(path)
+	if filepath.Clean(root) == dir {
+		return nil
+	}
+	r := &repoWorkflows{root: root, parsed: map[string]*actionlint.Workflow{}}
+	w := r.parse(path, content)
+	if w == nil || onlyCalled(w) {
+		return nil
+	}
+	files, err := FindWorkflowFiles(dir)
+	if err != nil {
+		return nil
+	}
+
+	type producer struct {
+		file string
+		run  checkRun
+	}
+	var names []string
+	producers := map[string][]producer{}
+	for _, f := range files {
+		if f == path {
+			continue
+		}
+		other := r.parse(f, nil)
+		if other == nil || onlyCalled(other) {
+			continue
+		}
+		if w.Name != nil && other.Name != nil && other.Name.Value == w.Name.Value {
+			names = append(names, relativeTo(root, f))
+		}
+		for _, c := range r.checkRuns(other, map[*actionlint.Workflow]bool{}) {
+			producers[c.name] = append(producers[c.name], producer{relativeTo(root, f), c})
+		}
+	}
+
+	var found []LintError
+	if len(names) > 0 && !strings.Contains(w.Name.Value, "${{") {
+		found = append(found, LintError{
+			Message:  fmt.Sprintf("workflow name %q is also used by %s, so the workflows cannot be told apart in the Actions tab and in the checks of a pull request", w.Name.Value, strings.Join(names, ", ")),
+			Line:     w.Name.Pos.Line,
+			Column:   w.Name.Pos.Col,
+			Kind:     KindDuplicateName,
+			Severity: SeverityForKind(KindDuplicateName),
+		})
+	}
+
+	own := r.checkRuns(w, map[*actionlint.Workflow]bool{})
+	reported := map[string]bool{}
+	for _, c := range own {
+		var others []string
+		for _, o := range own {
+			if o.name == c.name && o.job != c.job && (c.viaCall || o.viaCall) {
+				others = append(others, fmt.Sprintf("job %q", o.job))
+			}
+		}
+		for _, p := range producers[c.name] {
+			if c.viaCall || p.run.viaCall {
+				others = append(others, fmt.Sprintf("job %q of %s", p.run.job, p.file))
+			}
+		}
+		if len(others) == 0 || reported[c.name] {
+			continue
+		}
+		reported[c.name] = true
+		found = append(found, LintError{
+			Message:  fmt.Sprintf("check %q reported by job %q is also reported by %s, so a required check on that name is ambiguous. give the jobs different names", c.name, c.job, strings.Join(others, ", ")),
+			Line:     c.pos.Line,
+			Column:   c.pos.Col,
+			Kind:     KindDuplicateName,
+			Severity: SeverityForKind(KindDuplicateName),
+		})
+	}
+	return found
+}
@@ -0,0 +1,66 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplicateNameFindings(t *testing.T) {
+	repo := t.TempDir()
+	dir := filepath.Join(repo, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	write("deploy.yml", "name: Deploy\non: workflow_call\njobs:\n  run:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo deploy\n")
+	ci := write("ci.yml", `name: CI
+on: pull_request
+jobs:
+  staging:
+    name: deploy
+    uses: ./.github/workflows/deploy.yml
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+`)
+	write("release.yml", `name: CI
+on: push
+jobs:
+  prod:
+    name: deploy
+    uses: ./.github/workflows/deploy.yml
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+`)
+
+	findings := duplicateNameFindings(ci, nil)
+	require.Len(t, findings, 2)
+	assert.Equal(t, LintError{
+		Message:  `workflow name "CI" is also used by .github/workflows/release.yml, so the workflows cannot be told apart in the Actions tab and in the checks of a pull request`,
+		Line:     1,
+		Column:   7,
+		Kind:     KindDuplicateName,
+		Severity: SeverityWarning,
+	}, findings[0])
+	assert.Equal(t, `check "deploy / run" reported by job "staging" is also reported by job "prod" of .github/workflows/release.yml, so a required check on that name is ambiguous. give the jobs different names`, findings[1].Message)
+	assert.Equal(t, 6, findings[1].Line)
+
+	// Plain jobs sharing a name are common and not reported, and reusable
+	// workflows have no checks of their own
+	assert.Empty(t, duplicateNameFindings(filepath.Join(dir, "deploy.yml"), nil))
+
+	// Files outside the workflows directory are not compared
+	other := filepath.Join(repo, "ci.yml")
+	require.NoError(t, os.WriteFile(other, []byte("name: CI\non: push\njobs: {}\n"), 0644))
+	assert.Empty(t, duplicateNameFindings(other, nil))
+}
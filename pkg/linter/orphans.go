@@ -0,0 +1,155 @@
+package linter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// RepositoryFacts is what FindOrphanedWorkflows knows of the repository
+// the workflows belong to. Zero values mean unknown, and the triggers that
+// depend on them are not checked.
+type RepositoryFacts struct {
+	// Branches are the names of the branches of the repository.
+	Branches []string
+	// Template is set for a template repository.
+	Template bool
+	// NoDeployments is set when the repository has no deployments.
+	NoDeployments bool
+}
+
+// DeadTrigger is an event a workflow listens to that can never fire in the
+// repository.
+type DeadTrigger struct {
+	Event  string `json:"event"`
+	Reason string `json:"reason"`
+}
+
+// OrphanedWorkflow reports the triggers of a workflow that can never fire.
+// Orphaned is set when none of its triggers can, so it never runs.
+type OrphanedWorkflow struct {
+	FilePath string        `json:"file_path"`
+	Name     string        `json:"name,omitempty"`
+	Orphaned bool          `json:"orphaned"`
+	Triggers []DeadTrigger `json:"triggers"`
+}
+
+// FindOrphanedWorkflows reports, for the workflow files of a repository,
+// the triggers that can never fire in it: workflow_run triggers naming no
+// workflow of files, and, as far as facts tell, branch filters no branch
+// matches, release events in a template repository and deployment events
+// in a repository without deployments. files must be all the workflows of
+// the repository. Workflows with no such trigger are left out.
+func FindOrphanedWorkflows(files []string, facts RepositoryFacts) ([]OrphanedWorkflow, error) {
+	workflows := make([]*actionlint.Workflow, len(files))
+	names := map[string]bool{}
+	for i, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		if content, err = normalizeEncoding(content); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		workflows[i], _ = actionlint.Parse(content)
+		names[workflowName(path, workflows[i])] = true
+	}
+
+	out := []OrphanedWorkflow{}
+	for i, w := range workflows {
+		if w == nil || len(w.On) == 0 {
+			continue
+		}
+		o := OrphanedWorkflow{FilePath: files[i]}
+		if w.Name != nil {
+			o.Name = w.Name.Value
+		}
+		for _, e := range w.On {
+			if reason := deadTrigger(e, facts, names); reason != "" {
+				o.Triggers = append(o.Triggers, DeadTrigger{Event: e.EventName(), Reason: reason})
+			}
+		}
+		if len(o.Triggers) > 0 {
+			o.Orphaned = len(o.Triggers) == len(w.On)
+			out = append(out, o)
+		}
+	}
+	return out, nil
+}
+
+// workflowName is the name workflow_run filters know a workflow by: its
+// name, or its path in the repository when it has none.
+func workflowName(path string, w *actionlint.Workflow) string {
+	if w != nil && w.Name != nil {
+		return w.Name.Value
+	}
+	return filepath.ToSlash(filepath.Join(".github", "workflows", filepath.Base(path)))
+}
+
+// deadTrigger returns why e can never fire, or "" when it can or the facts
+// do not tell. names are the names of the workflows of the repository.
+func deadTrigger(e actionlint.Event, facts RepositoryFacts, names map[string]bool) string {
+	hook, ok := e.(*actionlint.WebhookEvent)
+	if !ok {
+		return ""
+	}
+	switch hook.EventName() {
+	case "release":
+		if facts.Template {
+			return "the repository is a template repository, which is not released: repositories created from it get the workflow, but not its releases"
+		}
+	case "deployment", "deployment_status":
+		if facts.NoDeployments {
+			return "the repository has no deployments, so no deployment integration creates the events"
+		}
+	case "push":
+		if !hook.Tags.IsEmpty() || !hook.TagsIgnore.IsEmpty() {
+			return "" // Tag pushes can still trigger it
+		}
+		return deadBranches(hook.Branches, "branch", facts.Branches)
+	case "pull_request", "pull_request_target":
+		return deadBranches(hook.Branches, "base branch", facts.Branches)
+	case "workflow_run":
+		var missing []string
+		for _, w := range hook.Workflows {
+			if !names[w.Value] {
+				missing = append(missing, w.Value)
+			}
+		}
+		if len(hook.Workflows) > 0 && len(missing) == len(hook.Workflows) {
+			return fmt.Sprintf("no workflow of the repository is named %s, and workflow_run only follows workflows of the same repository", strings.Join(quoteAll(missing), " or "))
+		}
+		if reason := deadBranches(hook.Branches, "branch", facts.Branches); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+// deadBranches returns why no event passes a branches filter when none of
+// branches matches it, or "" when one does or the branches are unknown.
+func deadBranches(filter *actionlint.WebhookEventFilter, what string, branches []string) string {
+	if filter.IsEmpty() || branches == nil {
+		return ""
+	}
+	patterns := filterValues(filter)
+	if slices.ContainsFunc(branches, func(b string) bool {
+		ok, err := matchFilter(patterns, b)
+		return ok || err != nil
+	}) {
+		return ""
+	}
+	return fmt.Sprintf("no %s of the repository matches the %s filter (%s)", what, filter.Name.Value, strings.Join(patterns, ", "))
+}
+
+func quoteAll(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = fmt.Sprintf("%q", v)
+	}
+	return out
+}
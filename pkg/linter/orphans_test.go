@@ -0,0 +1,63 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindOrphanedWorkflows(t *testing.T) {
+	dir := t.TempDir()
+	workflows := map[string]string{
+		"ci.yml":      "name: CI\non:\n  push:\n    branches: [main]\n  pull_request:\n    branches: ['release/**']\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n",
+		"legacy.yml":  "on:\n  push:\n    branches: [master, '!master']\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n",
+		"tags.yml":    "on:\n  push:\n    branches: [master]\n    tags: ['v*']\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n",
+		"release.yml": "on:\n  release:\n    types: [published]\n  workflow_dispatch:\njobs:\n  publish:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n",
+		"deploy.yml":  "on: [deployment_status]\njobs:\n  notify:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n",
+		"after.yml":   "on:\n  workflow_run:\n    workflows: [CI, Build]\n    types: [completed]\n  schedule:\n    - cron: '0 0 * * *'\njobs:\n  report:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n",
+		"gone.yml":    "on:\n  workflow_run:\n    workflows: [Nightly]\njobs:\n  report:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n",
+		"unnamed.yml": "on:\n  workflow_run:\n    workflows: [.github/workflows/legacy.yml]\njobs:\n  report:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n",
+	}
+	for name, content := range workflows {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	files, err := FindWorkflowFiles(dir)
+	require.NoError(t, err)
+
+	// Without facts only workflow_run triggers can be checked
+	got, err := FindOrphanedWorkflows(files, RepositoryFacts{})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, OrphanedWorkflow{
+		FilePath: filepath.Join(dir, "gone.yml"),
+		Orphaned: true,
+		Triggers: []DeadTrigger{{Event: "workflow_run", Reason: `no workflow of the repository is named "Nightly", and workflow_run only follows workflows of the same repository`}},
+	}, got[0])
+
+	got, err = FindOrphanedWorkflows(files, RepositoryFacts{Branches: []string{"main", "feature/x"}, Template: true, NoDeployments: true})
+	require.NoError(t, err)
+	byFile := map[string]OrphanedWorkflow{}
+	for _, o := range got {
+		byFile[filepath.Base(o.FilePath)] = o
+	}
+	assert.Len(t, byFile, 5, "%v", got)
+
+	ci := byFile["ci.yml"]
+	assert.Equal(t, "CI", ci.Name)
+	assert.False(t, ci.Orphaned, "pushes to main still trigger it")
+	assert.Equal(t, []DeadTrigger{{Event: "pull_request", Reason: "no base branch of the repository matches the branches filter (release/**)"}}, ci.Triggers)
+
+	assert.True(t, byFile["legacy.yml"].Orphaned)
+	assert.Contains(t, byFile["legacy.yml"].Triggers[0].Reason, "(master, !master)")
+	assert.False(t, byFile["release.yml"].Orphaned, "workflow_dispatch can still run it")
+	assert.Contains(t, byFile["release.yml"].Triggers[0].Reason, "template repository")
+	assert.True(t, byFile["deploy.yml"].Orphaned)
+	assert.Contains(t, byFile["deploy.yml"].Triggers[0].Reason, "no deployments")
+	assert.True(t, byFile["gone.yml"].Orphaned)
+	assert.NotContains(t, byFile, "tags.yml", "tag pushes can still trigger it")
+	assert.NotContains(t, byFile, "after.yml", "CI is a workflow of the repository")
+	assert.NotContains(t, byFile, "unnamed.yml", "workflows without a name are known by their path")
+}
@@ -0,0 +1,219 @@
+package linter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/rhysd/actionlint"
+)
+
+// KindOutputContract is the kind of findings about job outputs that are
+// consumed but can never be set.
+const KindOutputContract = rules.KindOutputContract
+
+var (
+	// needsOutputRef matches reads of another job's outputs.
+	needsOutputRef = regexp.MustCompile(`(?i)(?:^|[^\w.-])needs\s*\.\s*([\w-]+)\s*\.\s*outputs\s*\.\s*([\w-]+)`)
+	// stepOutputRef matches reads of a step's outputs in a job output.
+	stepOutputRef = regexp.MustCompile(`(?i)(?:^|[^\w.-])steps\s*\.\s*([\w-]+)\s*\.\s*outputs\b`)
+	// jobOutputRef matches reads of a job's outputs in a workflow_call output.
+	jobOutputRef = regexp.MustCompile(`(?i)(?:^|[^\w.-])jobs\s*\.\s*([\w-]+)\s*\.\s*outputs\s*\.\s*([\w-]+)`)
+)
+
+// outputState is a declared output. reason is empty when the output can be
+// set, and otherwise explains why it is always empty, continuing a
+// sentence that starts with the output's name.
+type outputState struct {
+	line   int
+	reason string
+}
+
+// workflowOutputs are the outputs declared in one workflow file, keyed by
+// lower case names.
+type workflowOutputs struct {
+	w    *actionlint.Workflow
+	jobs map[string]map[string]*outputState
+	call map[string]*outputState
+}
+
+// outputContracts resolves the outputs of workflows and the local reusable
+// workflows they call, parsing each file once.
+type outputContracts struct {
+	root  string
+	files map[string]*workflowOutputs
+}
+
+// load returns the outputs of the workflow at path. content is used instead
+// of reading the file when it is not nil.
+func (c *outputContracts) load(path string, content []byte) *workflowOutputs {
+	if o, ok := c.files[path]; ok {
+		return o
+	}
+	c.files[path] = nil // Guards against reading the file again in a cycle
+
+	if content == nil {
+		var err error
+		if content, err = os.ReadFile(path); err != nil {
+			return nil
+		}
+		if content, err = normalizeEncoding(content); err != nil {
+			return nil
+		}
+	}
+	w, _ := actionlint.Parse(content)
+	if w == nil {
+		return nil
+	}
+
+	o := &workflowOutputs{w: w, jobs: map[string]map[string]*outputState{}, call: map[string]*outputState{}}
+	for id, job := range w.Jobs {
+		o.jobs[strings.ToLower(id)] = c.jobOutputs(job)
+	}
+	for _, e := range w.On {
+		call, ok := e.(*actionlint.WorkflowCallEvent)
+		if !ok {
+			continue
+		}
+		for name, out := range call.Outputs {
+			if out.Value == nil {
+				continue
+			}
+			o.call[strings.ToLower(name)] = &outputState{
+				line:   out.Value.Pos.Line,
+				reason: c.callOutputReason(o, out.Value.Value),
+			}
+		}
+	}
+	c.files[path] = o
+	return o
+}
+
+// jobOutputs returns the outputs of job. The outputs of a job calling a
+// local reusable workflow are the outputs of that workflow.
+func (c *outputContracts) jobOutputs(job *actionlint.Job) map[string]*outputState {
+	if call := job.WorkflowCall; call != nil {
+		if call.Uses == nil || !strings.HasPrefix(call.Uses.Value, "./") {
+			return nil
+		}
+		if callee := c.callee(call.Uses.Value); callee != nil {
+			return callee.call
+		}
+		return nil
+	}
+
+	steps := map[string]bool{}
+	for _, s := range job.Steps {
+		if s.ID != nil {
+			steps[strings.ToLower(s.ID.Value)] = true
+		}
+	}
+	outputs := make(map[string]*outputState, len(job.Outputs))
+	for name, out := range job.Outputs {
+		if out.Value == nil {
+			continue
+		}
+		state := &outputState{line: out.Value.Pos.Line}
+		refs := stepOutputRef.FindAllStringSubmatch(out.Value.Value, -1)
+		var missing string
+		for _, m := range refs {
+			if steps[strings.ToLower(m[1])] {
+				missing = ""
+				break
+			}
+			if missing == "" {
+				missing = m[1]
+			}
+		}
+		if missing != "" {
+			state.reason = fmt.Sprintf("is set from steps.%s, but job %q has no step with that id", missing, job.ID.Value)
+		}
+		outputs[strings.ToLower(name)] = state
+	}
+	return outputs
+}
+
+// callOutputReason explains why a workflow_call output with the given
+// value is always empty, or returns "" when one of the job outputs it reads
+// can be set.
+func (c *outputContracts) callOutputReason(o *workflowOutputs, value string) string {
+	var reason string
+	for _, m := range jobOutputRef.FindAllStringSubmatch(value, -1) {
+		job, name := strings.ToLower(m[1]), strings.ToLower(m[2])
+		ref := fmt.Sprintf("jobs.%s.outputs.%s", m[1], m[2])
+		var r string
+		if _, ok := o.w.Jobs[job]; !ok {
+			r = fmt.Sprintf("is set from %s, but there is no job %q", ref, m[1])
+		} else if outputs := o.jobs[job]; outputs == nil {
+			return "" // A remote or unreadable reusable workflow
+		} else if state, ok := outputs[name]; !ok {
+			r = fmt.Sprintf("is set from %s, which job %q does not declare", ref, m[1])
+		} else if state.reason != "" {
+			r = fmt.Sprintf("is set from %s, which %s", ref, state.reason)
+		} else {
+			return ""
+		}
+		if reason == "" {
+			reason = r
+		}
+	}
+	return reason
+}
+
+// callee returns the outputs of the local reusable workflow at uses.
+func (c *outputContracts) callee(uses string) *workflowOutputs {
+	return c.load(filepath.Join(c.root, filepath.FromSlash(uses)), nil)
+}
+
+// outputContractFindings reports the reads of needs.<job>.outputs.<name> in
+// the workflow at path whose output is declared but can never be set,
+// following outputs through local reusable workflows. actionlint reports
+// the problem where the output is declared; this reports it where the
+// output is used, naming the declaration.
+func outputContractFindings(path string, content []byte) []LintError {
+	root := repositoryRoot(path)
+	c := &outputContracts{root: root, files: map[string]*workflowOutputs{}}
+	path = filepath.Clean(path)
+	o := c.load(path, content)
+	if o == nil {
+		return nil
+	}
+
+	ids := make([]string, 0, len(o.w.Jobs))
+	for id := range o.w.Jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var found []LintError
+	for _, id := range ids {
+		for _, s := range rules.Strings(o.w.Jobs[id]) {
+			seen := map[string]bool{}
+			for _, m := range needsOutputRef.FindAllStringSubmatch(s.Value, -1) {
+				job, name := strings.ToLower(m[1]), strings.ToLower(m[2])
+				state := o.jobs[job][name]
+				if state == nil || state.reason == "" || seen[job+"."+name] {
+					continue
+				}
+				seen[job+"."+name] = true
+
+				producer := fmt.Sprintf("job %q", m[1])
+				if call := o.w.Jobs[job].WorkflowCall; call != nil {
+					producer = relativeTo(root, filepath.Join(root, filepath.FromSlash(call.Uses.Value)))
+				}
+				found = append(found, LintError{
+					Message:  fmt.Sprintf("needs.%s.outputs.%s is always empty: output %q of %s (line %d) %s", m[1], m[2], m[2], producer, state.line, state.reason),
+					Line:     s.Pos.Line,
+					Column:   s.Pos.Col,
+					Kind:     KindOutputContract,
+					Severity: SeverityForKind(KindOutputContract),
+				})
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,91 @@
+package linter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputContractFindings(t *testing.T) {
+	repo := t.TempDir()
+	dir := filepath.Join(repo, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+
+	reusable := `on:
+  workflow_call:
+    outputs:
+      version:
+        value: ${{ jobs.build.outputs.ver }}
+      tag:
+        value: ${{ jobs.build.outputs.tag }}
+      sha:
+        value: ${{ jobs.build.outputs.sha }}
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    outputs:
+      tag: ${{ steps.missing.outputs.tag }}
+      sha: ${{ steps.rev.outputs.sha }}
+    steps:
+      - id: rev
+        run: echo "sha=$(git rev-parse HEAD)" >> "$GITHUB_OUTPUT"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "reusable.yml"), []byte(reusable), 0644))
+
+	caller := `on: push
+jobs:
+  call:
+    uses: ./.github/workflows/reusable.yml
+  local:
+    runs-on: ubuntu-latest
+    outputs:
+      name: ${{ steps.nope.outputs.name }}
+    steps:
+      - run: echo hi
+  use:
+    needs: [call, local]
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ needs.call.outputs.version }} ${{ needs.call.outputs.sha }}
+      - run: echo ${{ needs.call.outputs.tag }}
+      - run: echo ${{ needs.local.outputs.name }}
+`
+	path := filepath.Join(dir, "caller.yml")
+	require.NoError(t, os.WriteFile(path, []byte(caller), 0644))
+
+	findings := outputContractFindings(path, nil)
+	require.Len(t, findings, 3)
+	assert.Equal(t, LintError{
+		Message:  `needs.call.outputs.version is always empty: output "version" of .github/workflows/reusable.yml (line 5) is set from jobs.build.outputs.ver, which job "build" does not declare`,
+		Line:     15,
+		Column:   14,
+		Kind:     KindOutputContract,
+		Severity: SeverityWarning,
+	}, findings[0])
+	assert.Equal(t, `needs.call.outputs.tag is always empty: output "tag" of .github/workflows/reusable.yml (line 7) is set from jobs.build.outputs.tag, which is set from steps.missing, but job "build" has no step with that id`, findings[1].Message)
+	assert.Equal(t, 16, findings[1].Line)
+	assert.Equal(t, `needs.local.outputs.name is always empty: output "name" of job "local" (line 8) is set from steps.nope, but job "local" has no step with that id`, findings[2].Message)
+
+	// Findings are part of linting a file in the repository, but not
+	// inline content, whose reusable workflows cannot be read
+	style := Options{Rules: rules.Config{Packs: []string{"style"}}}
+	result, err := New(style).Lint(context.Background(), Input{Path: path})
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	var kinds []string
+	for _, e := range result.Errors {
+		kinds = append(kinds, e.Kind)
+	}
+	assert.Contains(t, kinds, KindOutputContract)
+
+	result, err = New(style).Lint(context.Background(), Input{Content: []byte(caller)})
+	require.NoError(t, err)
+	for _, e := range result.Errors {
+		assert.NotEqual(t, KindOutputContract, e.Kind)
+	}
+}
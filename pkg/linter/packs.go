@@ -0,0 +1,149 @@
+package linter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/rhysd/actionlint"
+	"gopkg.in/yaml.v3"
+)
+
+// RepositoryConfigFile is the file of a repository turning on rule packs
+// for its workflows, relative to its root.
+const RepositoryConfigFile = ".github/actionlint-mcp.yaml"
+
+// repositoryConfig is the content of RepositoryConfigFile.
+type repositoryConfig struct {
+	// Packs are the rule packs turned on for the repository, in addition
+	// to the configured ones.
+	Packs []string `yaml:"packs"`
+}
+
+// repositoryPacks returns the rule packs the RepositoryConfigFile of the
+// repository at root turns on, if it has one.
+func repositoryPacks(root string) ([]string, error) {
+	file := filepath.Join(root, RepositoryConfigFile)
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cfg repositoryConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid %s: %w", file, err)
+	}
+	if err := rules.ValidatePacks(cfg.Packs); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", file, err)
+	}
+	return cfg.Packs, nil
+}
+
+// RuleInfo describes a rule: the kind of its findings, what it checks,
+// their severity, the pack it belongs to, if any, and whether it runs.
+type RuleInfo struct {
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	Severity    string `json:"severity"`
+	Pack        string `json:"pack,omitempty"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// linterChecks are the checks the linter runs itself rather than as rules,
+// with what they check.
+var linterChecks = []struct{ kind, description string }{
+	{KindContextAvailability, "Checks that contexts are used where GitHub provides them"},
+	{KindNotWorkflow, "Checks that files are workflows rather than other recognizable YAML files"},
+	{KindMultiDocument, "Checks that files hold a single YAML document, linting every document"},
+	{KindReusableCalls, "Checks that chains of calls to local reusable workflows do not loop or nest too deep"},
+	{KindOutputContract, "Checks that the job outputs other jobs read can be set"},
+	{KindDockerAction, "Checks that local Docker container actions can run as declared"},
+	{KindDuplicateName, "Checks that workflow and check names are unique in the repository"},
+	{KindConcurrencyDeadlock, "Checks that runs do not wait for a concurrency group they hold"},
+	{KindConcurrencyStarvation, "Checks that manual workflows do not share concurrency groups with automatic ones"},
+	{KindUnknownRef, "Checks that branch and tag filters match refs of the repository"},
+}
+
+// PackInfo is a rule pack and whether it is turned on.
+type PackInfo struct {
+	rules.Pack
+	Enabled bool `json:"enabled"`
+}
+
+// RuleList lists the rule packs and the rules, actionlint's first.
+type RuleList struct {
+	Packs []PackInfo `json:"packs"`
+	Rules []RuleInfo `json:"rules"`
+}
+
+// ListRules returns the rules and rule packs, and whether they run for the
+// workflows of the repository at root, whose RepositoryConfigFile may turn
+// on further packs. An empty root only takes the options into account.
+// Rules that need settings, such as undefined-secret, are listed as
+// running even before they are configured.
+func (l *Linter) ListRules(root string) (*RuleList, error) {
+	cfg := l.opts.Rules
+	if root != "" {
+		packs, err := repositoryPacks(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load repository config: %w", err)
+		}
+		cfg = cfg.WithPacks(packs...)
+	}
+
+	list := &RuleList{}
+	all := make([]string, len(rules.Packs))
+	for i, p := range rules.Packs {
+		all[i] = p.Name
+		list.Packs = append(list.Packs, PackInfo{Pack: p, Enabled: slices.Contains(cfg.Packs, p.Name)})
+	}
+
+	// actionlint creates its rules for each workflow it lints
+	var builtin []actionlint.Rule
+	linter, err := actionlint.NewLinter(io.Discard, &actionlint.LinterOptions{
+		OnRulesCreated: func(rs []actionlint.Rule) []actionlint.Rule {
+			builtin = rs
+			return rs
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create linter: %w", err)
+	}
+	if _, err := linter.Lint(InlineFileName, []byte("on: push\njobs: {}\n"), nil); err != nil {
+		return nil, fmt.Errorf("linting failed: %w", err)
+	}
+	for _, r := range builtin {
+		list.Rules = append(list.Rules, RuleInfo{Kind: r.Name(), Description: r.Description(), Severity: SeverityForKind(r.Name()), Enabled: true})
+	}
+	for _, r := range rules.New(cfg.WithPacks(all...)) {
+		if _, ok := r.(*rules.RuleFixes); ok {
+			continue // Reports nothing of its own
+		}
+		list.Rules = append(list.Rules, RuleInfo{
+			Kind:        r.Name(),
+			Description: r.Description(),
+			Severity:    SeverityForKind(r.Name()),
+			Pack:        rules.PackOf(r.Name()),
+			Enabled:     cfg.Active(r.Name()),
+		})
+	}
+	for _, c := range linterChecks {
+		list.Rules = append(list.Rules, RuleInfo{
+			Kind:        c.kind,
+			Description: c.description,
+			Severity:    SeverityForKind(c.kind),
+			Pack:        rules.PackOf(c.kind),
+			Enabled:     cfg.Active(c.kind),
+		})
+	}
+	return list, nil
+}
@@ -0,0 +1,88 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryPacks(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	workflow := filepath.Join(dir, "ci.yml")
+	require.NoError(t, os.WriteFile(workflow, []byte("on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n"), 0644))
+
+	kinds := func() []string {
+		t.Helper()
+		result, err := New(Options{}).Lint(t.Context(), Input{Path: workflow})
+		require.NoError(t, err)
+		var out []string
+		for _, e := range result.Errors {
+			out = append(out, e.Kind)
+		}
+		return out
+	}
+	assert.NotContains(t, kinds(), "job-timeout")
+
+	config := filepath.Join(root, RepositoryConfigFile)
+	require.NoError(t, os.WriteFile(config, []byte("packs: [cost]\n"), 0644))
+	assert.Contains(t, kinds(), "job-timeout")
+
+	require.NoError(t, os.WriteFile(config, []byte("packs: [speed]\n"), 0644))
+	_, err := New(Options{}).Lint(t.Context(), Input{Path: workflow})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown rule pack "speed"`)
+
+	require.NoError(t, os.WriteFile(config, []byte("pack: [cost]\n"), 0644))
+	_, err = New(Options{}).Lint(t.Context(), Input{Path: workflow})
+	assert.Error(t, err, "unknown keys are rejected")
+}
+
+func TestListRules(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".github"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, RepositoryConfigFile), []byte("packs: [style]\n"), 0644))
+
+	opts := Options{}
+	opts.Rules.Packs = []string{"cost"}
+	list, err := New(opts).ListRules(root)
+	require.NoError(t, err)
+
+	packs := map[string]bool{}
+	for _, p := range list.Packs {
+		packs[p.Name] = p.Enabled
+	}
+	assert.Equal(t, map[string]bool{"cloud": false, "cost": true, "security": false, "style": true}, packs)
+
+	rules := map[string]RuleInfo{}
+	for _, r := range list.Rules {
+		rules[r.Kind] = r
+	}
+	assert.Equal(t, RuleInfo{Kind: "expression", Description: rules["expression"].Description, Severity: SeverityError, Enabled: true}, rules["expression"])
+	assert.NotEmpty(t, rules["expression"].Description)
+	assert.True(t, rules["undefined-secret"].Enabled)
+	assert.Empty(t, rules["undefined-secret"].Pack)
+	assert.True(t, rules["matrix-size"].Enabled)
+	assert.Equal(t, "cost", rules["matrix-size"].Pack)
+	assert.False(t, rules["fork-safety"].Enabled)
+	assert.Equal(t, "security", rules["fork-safety"].Pack)
+	assert.Equal(t, RuleInfo{Kind: KindReusableCalls, Description: rules[KindReusableCalls].Description, Severity: SeverityError, Pack: "style", Enabled: true}, rules[KindReusableCalls], "the linter's own checks are listed too")
+	assert.Equal(t, "cloud", rules["cloud-deploy"].Pack)
+	assert.False(t, rules["cloud-deploy"].Enabled)
+	assert.True(t, rules["job-timeout"].Enabled)
+	assert.True(t, rules["naming-job-id"].Enabled)
+	assert.NotContains(t, rules, "fixes")
+
+	list, err = New(Options{}).ListRules("")
+	require.NoError(t, err)
+	for _, p := range list.Packs {
+		assert.False(t, p.Enabled)
+	}
+	for _, r := range list.Rules {
+		assert.Equal(t, r.Pack == "", r.Enabled, "only rules outside packs run by default: %s", r.Kind)
+	}
+}
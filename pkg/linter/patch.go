@@ -0,0 +1,170 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// hunk is one hunk of a unified diff: where it starts in the old file and
+// its lines, each with its marker and line ending.
+type hunk struct {
+	oldStart int
+	lines    []string
+}
+
+// ApplyPatch applies a unified diff of a single file to base and returns
+// the patched content with the line numbers, in the patched content, of
+// the added lines. Hunks whose line numbers are off are applied where
+// their context matches closest to the stated position, since diffs
+// written by hand or by agents often miscount.
+func ApplyPatch(base []byte, patch string) ([]byte, map[int]bool, error) {
+	normalized, err := normalizeEncoding(base)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode base file: %w", err)
+	}
+	hunks, err := parsePatch(patch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lines := splitLines(string(normalized))
+	var out []string
+	added := map[int]bool{}
+	pos := 0
+	for i, h := range hunks {
+		var old []string
+		for _, l := range h.lines {
+			if l[0] != '+' {
+				old = append(old, l[1:])
+			}
+		}
+		want := h.oldStart - 1
+		if len(old) == 0 {
+			want = h.oldStart // Pure insertions start after the stated line
+		}
+		at, ok := findHunk(lines, old, pos, want)
+		if !ok {
+			return nil, nil, fmt.Errorf("hunk %d (@@ -%d) does not apply: its context and removed lines are not in the base file", i+1, h.oldStart)
+		}
+		out = append(out, lines[pos:at]...)
+		for _, l := range h.lines {
+			switch l[0] {
+			case '+':
+				out = append(out, l[1:])
+				added[len(out)] = true
+			case ' ':
+				out = append(out, l[1:])
+			}
+		}
+		pos = at + len(old)
+	}
+	out = append(out, lines[pos:]...)
+	return []byte(strings.Join(out, "")), added, nil
+}
+
+// findHunk returns where old occurs in lines at or after from, choosing
+// the occurrence closest to want.
+func findHunk(lines, old []string, from, want int) (int, bool) {
+	best, found := 0, false
+	for at := from; at+len(old) <= len(lines); at++ {
+		if !linesEqual(lines[at:at+len(old)], old) {
+			continue
+		}
+		if !found || abs(at-want) < abs(best-want) {
+			best, found = at, true
+		}
+	}
+	return best, found
+}
+
+func linesEqual(a, b []string) bool {
+	for i := range a {
+		if strings.TrimSuffix(a[i], "\n") != strings.TrimSuffix(b[i], "\n") {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// parsePatch reads the hunks of a unified diff. File headers and other
+// preamble lines are skipped, but a diff of several files is rejected.
+// Hunks may hold more lines than their header counts, as long as no file
+// header follows.
+func parsePatch(patch string) ([]hunk, error) {
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+	isFileHeader := func(i int) bool {
+		return strings.HasPrefix(lines[i], "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ")
+	}
+
+	var hunks []hunk
+	files := 0
+	inHunk := false
+	for i := 0; i < len(lines); i++ {
+		text := lines[i]
+		switch {
+		case isFileHeader(i):
+			files++
+			if files > 1 {
+				return nil, fmt.Errorf("patch changes several files; pass the diff of one file")
+			}
+			inHunk = false
+			i++ // Skip the +++ line
+		case strings.HasPrefix(text, "@@"):
+			m := hunkHeader.FindStringSubmatch(text)
+			if m == nil {
+				return nil, fmt.Errorf("invalid hunk header %q", text)
+			}
+			start, _ := strconv.Atoi(m[1])
+			hunks = append(hunks, hunk{oldStart: start})
+			inHunk = true
+		case !inHunk:
+			// Preamble such as "diff --git" or "index" lines
+		case strings.HasPrefix(text, `\`):
+			// "\ No newline at end of file" applies to the previous line
+			h := &hunks[len(hunks)-1]
+			if n := len(h.lines); n > 0 {
+				h.lines[n-1] = strings.TrimSuffix(h.lines[n-1], "\n")
+			}
+		case text == "":
+			// A blank context line trimmed by an editor, or the end of the
+			// patch
+			if i < len(lines)-1 {
+				hunks[len(hunks)-1].lines = append(hunks[len(hunks)-1].lines, " \n")
+			}
+		case strings.ContainsRune(" +-", rune(text[0])):
+			hunks[len(hunks)-1].lines = append(hunks[len(hunks)-1].lines, text+"\n")
+		default:
+			return nil, fmt.Errorf("invalid line in hunk: %q", text)
+		}
+	}
+	if len(hunks) == 0 {
+		return nil, fmt.Errorf("patch has no hunks")
+	}
+	return hunks, nil
+}
+
+// OnlyLines removes the findings of result that are not on one of lines
+// and returns how many it removed. Findings without a line, which concern
+// the whole file, are kept. Valid still describes the whole file.
+func OnlyLines(result *LintResult, lines map[int]bool) int {
+	kept := result.Errors[:0]
+	for _, e := range result.Errors {
+		if e.Line == 0 || lines[e.Line] {
+			kept = append(kept, e)
+		}
+	}
+	removed := len(result.Errors) - len(kept)
+	result.Errors = kept
+	return removed
+}
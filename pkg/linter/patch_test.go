@@ -0,0 +1,63 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const patchBase = `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ github.undefined_one }}
+      - run: echo done
+`
+
+func TestApplyPatch(t *testing.T) {
+	patch := `diff --git a/ci.yml b/ci.yml
+--- a/ci.yml
++++ b/ci.yml
+@@ -5,3 +5,4 @@
+     steps:
+       - run: echo ${{ github.undefined_one }}
++      - run: echo ${{ github.undefined_two }}
+       - run: echo done
+`
+	patched, added, err := ApplyPatch([]byte(patchBase), patch)
+	require.NoError(t, err)
+	assert.Contains(t, string(patched), "      - run: echo ${{ github.undefined_one }}\n      - run: echo ${{ github.undefined_two }}\n      - run: echo done\n")
+	assert.Equal(t, map[int]bool{7: true}, added)
+
+	// Miscounted line numbers are tolerated when the context is unique
+	misnumbered := "@@ -40,2 +40,2 @@\n-      - run: echo done\n+      - run: echo finished\n"
+	patched, added, err = ApplyPatch([]byte(patchBase), misnumbered)
+	require.NoError(t, err)
+	assert.Contains(t, string(patched), "echo finished\n")
+	assert.Equal(t, map[int]bool{7: true}, added)
+
+	// New files are patched from an empty base
+	patched, added, err = ApplyPatch(nil, "--- /dev/null\n+++ b/ci.yml\n@@ -0,0 +1,2 @@\n+on: push\n+jobs: {}\n\\ No newline at end of file\n")
+	require.NoError(t, err)
+	assert.Equal(t, "on: push\njobs: {}", string(patched))
+	assert.Len(t, added, 2)
+}
+
+func TestApplyPatch_Errors(t *testing.T) {
+	_, _, err := ApplyPatch([]byte(patchBase), "not a diff")
+	assert.ErrorContains(t, err, "patch has no hunks")
+
+	_, _, err = ApplyPatch([]byte(patchBase), "@@ -1 +1 @@\n-on: pull_request\n+on: push\n")
+	assert.ErrorContains(t, err, "hunk 1 (@@ -1) does not apply")
+
+	_, _, err = ApplyPatch([]byte(patchBase), "--- a/x.yml\n+++ b/x.yml\n@@ -1 +1 @@\n-on: push\n+on: [push]\n--- a/y.yml\n+++ b/y.yml\n@@ -1 +1 @@\n-a\n+b\n")
+	assert.ErrorContains(t, err, "several files")
+}
+
+func TestOnlyLines(t *testing.T) {
+	result := &LintResult{Errors: []LintError{{Line: 0}, {Line: 3}, {Line: 7}}}
+	assert.Equal(t, 1, OnlyLines(result, map[int]bool{7: true}))
+	assert.Equal(t, []LintError{{Line: 0}, {Line: 7}}, result.Errors)
+}
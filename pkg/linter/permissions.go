@@ -0,0 +1,160 @@
+package linter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/rhysd/actionlint"
+)
+
+// JobPermissions is the least GITHUB_TOKEN access a job needs, inferred
+// from the actions it uses and the commands it runs. Unknown lists what
+// the inference could not see through, such as actions missing from the
+// dataset, so the job may need more. Missing and Excess compare the job's
+// effective permissions block with the suggestion, and are left out when
+// the job has none.
+type JobPermissions struct {
+	Job         string            `json:"job"`
+	Line        int               `json:"line"`
+	Permissions map[string]string `json:"permissions"`
+	Unknown     []string          `json:"unknown,omitempty"`
+	Missing     []string          `json:"missing,omitempty"`
+	Excess      []string          `json:"excess,omitempty"`
+	YAML        string            `json:"yaml"`
+}
+
+// SuggestPermissions returns the permissions each job of a workflow
+// needs, sorted by job ID. path is where content is saved, used to find
+// the repository root that cfg's dataset file is relative to.
+func SuggestPermissions(content []byte, path string, cfg rules.Config) ([]JobPermissions, error) {
+	content, err := normalizeEncoding(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode content: %w", err)
+	}
+	root := ""
+	if path != "" && path != InlineFileName {
+		root = repositoryRoot(path)
+	}
+	actions, err := rules.LoadActionPermissions(cfg.Permissions, root)
+	if err != nil {
+		return nil, err
+	}
+	w, errs := actionlint.Parse(content)
+	if w == nil || len(w.Jobs) == 0 {
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("failed to parse workflow: %s", errs[0].Message)
+		}
+		return nil, fmt.Errorf("workflow has no jobs")
+	}
+
+	ids := make([]string, 0, len(w.Jobs))
+	for id := range w.Jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	out := make([]JobPermissions, 0, len(ids))
+	for _, id := range ids {
+		j := w.Jobs[id]
+		jp := JobPermissions{Job: id, Permissions: map[string]string{}}
+		if j.ID != nil && j.ID.Pos != nil {
+			jp.Line = j.ID.Pos.Line
+		}
+		if j.WorkflowCall != nil && j.WorkflowCall.Uses != nil {
+			jp.Unknown = append(jp.Unknown, fmt.Sprintf("calls %s, whose jobs need the permissions this job grants", j.WorkflowCall.Uses.Value))
+		}
+		for i, s := range j.Steps {
+			needs, known := rules.StepPermissions(s, actions, j.Env, w.Env)
+			for scope, level := range needs {
+				if permissionLevels[level] > permissionLevels[jp.Permissions[scope]] {
+					jp.Permissions[scope] = level
+				}
+			}
+			if !known {
+				jp.Unknown = append(jp.Unknown, describeStep(i+1, s))
+			}
+		}
+
+		perms := j.Permissions
+		if perms == nil {
+			perms = w.Permissions
+		}
+		if perms != nil {
+			jp.Missing, jp.Excess = comparePermissions(perms, jp.Permissions, len(jp.Unknown) == 0)
+		}
+		jp.YAML = permissionsYAML(jp.Permissions)
+		out = append(out, jp)
+	}
+	return out, nil
+}
+
+// describeStep names a step whose needs are unknown.
+func describeStep(n int, s *actionlint.Step) string {
+	switch exec := s.Exec.(type) {
+	case *actionlint.ExecAction:
+		if exec.Uses != nil {
+			return fmt.Sprintf("step %d uses %s, which is not in the permissions dataset", n, exec.Uses.Value)
+		}
+	case *actionlint.ExecRun:
+		return fmt.Sprintf("step %d calls the API with gh api", n)
+	}
+	return fmt.Sprintf("step %d", n)
+}
+
+// comparePermissions returns the scopes perms grants less access to than
+// needed, and, when the needs are complete, the scopes it grants more.
+func comparePermissions(perms *actionlint.Permissions, needed map[string]string, complete bool) (missing, excess []string) {
+	scopes := map[string]bool{}
+	for s := range needed {
+		scopes[s] = true
+	}
+	for s := range perms.Scopes {
+		scopes[s] = true
+	}
+	names := make([]string, 0, len(scopes))
+	for s := range scopes {
+		names = append(names, s)
+	}
+	sort.Strings(names)
+
+	for _, s := range names {
+		if permissionLevel(perms, s) < permissionLevels[needed[s]] {
+			missing = append(missing, s+": "+needed[s])
+		}
+	}
+	if !complete {
+		return missing, nil
+	}
+	if perms.All != nil {
+		if perms.All.Value == "write-all" || perms.All.Value == "read-all" {
+			excess = append(excess, perms.All.Value)
+		}
+		return missing, excess
+	}
+	for _, s := range names {
+		if granted, ok := perms.Scopes[s]; ok && granted.Value != nil && permissionLevel(perms, s) > permissionLevels[needed[s]] {
+			excess = append(excess, s+": "+granted.Value.Value)
+		}
+	}
+	return missing, excess
+}
+
+// permissionsYAML renders perms as a permissions block.
+func permissionsYAML(perms map[string]string) string {
+	if len(perms) == 0 {
+		return "permissions: {}\n"
+	}
+	scopes := make([]string, 0, len(perms))
+	for s := range perms {
+		scopes = append(scopes, s)
+	}
+	sort.Strings(scopes)
+	var b strings.Builder
+	b.WriteString("permissions:\n")
+	for _, s := range scopes {
+		fmt.Fprintf(&b, "  %s: %s\n", s, perms[s])
+	}
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestPermissions(t *testing.T) {
+	src := `on: push
+permissions:
+  contents: write
+  issues: write
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-go@v5
+      - run: go test ./...
+  scan:
+    runs-on: ubuntu-latest
+    permissions: read-all
+    steps:
+      - uses: actions/checkout@v4
+      - uses: github/codeql-action/init@v3
+      - uses: acme/custom@v1
+      - uses: github/codeql-action/analyze@v3
+  call:
+    uses: ./.github/workflows/reusable.yml
+`
+	jobs, err := SuggestPermissions([]byte(src), InlineFileName, rules.Config{})
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+
+	call := jobs[0]
+	assert.Equal(t, "call", call.Job)
+	assert.Empty(t, call.Permissions)
+	require.Len(t, call.Unknown, 1)
+	assert.Contains(t, call.Unknown[0], "calls ./.github/workflows/reusable.yml")
+	assert.Equal(t, "permissions: {}\n", call.YAML)
+
+	scan := jobs[1]
+	assert.Equal(t, "scan", scan.Job)
+	assert.Equal(t, 12, scan.Line)
+	assert.Equal(t, map[string]string{"actions": "read", "contents": "read", "security-events": "write"}, scan.Permissions)
+	assert.Equal(t, []string{"step 3 uses acme/custom@v1, which is not in the permissions dataset"}, scan.Unknown)
+	assert.Equal(t, []string{"security-events: write"}, scan.Missing)
+	assert.Empty(t, scan.Excess, "excess access is not reported while some needs are unknown")
+
+	test := jobs[2]
+	assert.Equal(t, map[string]string{"contents": "read"}, test.Permissions)
+	assert.Empty(t, test.Unknown)
+	assert.Empty(t, test.Missing)
+	assert.Equal(t, []string{"contents: write", "issues: write"}, test.Excess)
+	assert.Equal(t, "permissions:\n  contents: read\n", test.YAML)
+}
+
+func TestSuggestPermissions_Invalid(t *testing.T) {
+	_, err := SuggestPermissions([]byte("on: push\n"), InlineFileName, rules.Config{})
+	assert.Error(t, err)
+}
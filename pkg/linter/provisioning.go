@@ -0,0 +1,285 @@
+package linter
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/rhysd/actionlint"
+)
+
+// Scopes a secret or variable is required or defined at.
+const (
+	ScopeRepository   = "repository"
+	ScopeOrganization = "organization"
+	ScopeEnvironment  = "environment"
+	ScopeCaller       = "caller"
+)
+
+// settingReference matches a secret or a configuration variable read by an
+// expression.
+var settingReference = regexp.MustCompile(`\b(secrets|vars)\s*(?:\.\s*([A-Za-z_][\w-]*)|\[\s*'([^']+)'\s*\])`)
+
+// SettingReference is where a workflow reads a secret or variable. Job is
+// empty for the workflow's own keys, such as its env.
+type SettingReference struct {
+	Job    string `json:"job,omitempty"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// Requirement is a secret or configuration variable a workflow reads, and
+// the narrowest scope it can be provisioned at: environment when it is
+// only read by jobs deploying to Environment, which also see the secrets
+// and variables of the repository and its organization; repository
+// otherwise, which organization ones shared with the repository satisfy
+// too; and caller for the secrets a reusable workflow declares, which its
+// callers pass.
+type Requirement struct {
+	Kind        string             `json:"kind"`
+	Name        string             `json:"name"`
+	Scope       string             `json:"scope"`
+	Environment string             `json:"environment,omitempty"`
+	References  []SettingReference `json:"references"`
+}
+
+// WorkflowRequirements are the requirements of one workflow file.
+type WorkflowRequirements struct {
+	File         string        `json:"file"`
+	Requirements []Requirement `json:"requirements"`
+}
+
+// ChecklistItem is a secret or variable to provision, and the workflows
+// needing it. Status and ProvidedBy are set by Compare: ProvidedBy names
+// where the repository defines it, as repository, organization or
+// environment NAME.
+type ChecklistItem struct {
+	Kind        string   `json:"kind"`
+	Name        string   `json:"name"`
+	Scope       string   `json:"scope"`
+	Environment string   `json:"environment,omitempty"`
+	Workflows   []string `json:"workflows"`
+	Status      string   `json:"status,omitempty"`
+	ProvidedBy  []string `json:"provided_by,omitempty"`
+}
+
+// Provisioning is the secrets and variables a set of workflows require,
+// per workflow and as a checklist across them.
+type Provisioning struct {
+	Workflows []WorkflowRequirements `json:"workflows"`
+	Checklist []ChecklistItem        `json:"checklist"`
+}
+
+// SettingsScope is the names of the secrets and variables defined at one
+// scope of a repository: its own, its organization's shared with it, or
+// one of its environments'.
+type SettingsScope struct {
+	Scope       string
+	Environment string
+	Secrets     []string
+	Variables   []string
+}
+
+// ProvisioningChecklist returns the secrets and configuration variables
+// files read. GITHUB_TOKEN, which every run gets, is left out, as are the
+// secrets a job passes to a reusable workflow with secrets: inherit.
+func ProvisioningChecklist(files []string) (*Provisioning, error) {
+	p := &Provisioning{Workflows: []WorkflowRequirements{}, Checklist: []ChecklistItem{}}
+	type itemKey struct{ kind, name, scope, environment string }
+	items := map[itemKey]*ChecklistItem{}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if content, err = normalizeEncoding(content); err != nil {
+			continue
+		}
+		w, _ := actionlint.Parse(content)
+		if w == nil {
+			continue
+		}
+		reqs := workflowRequirements(w)
+		p.Workflows = append(p.Workflows, WorkflowRequirements{File: file, Requirements: reqs})
+		for _, r := range reqs {
+			k := itemKey{r.Kind, r.Name, r.Scope, r.Environment}
+			item := items[k]
+			if item == nil {
+				item = &ChecklistItem{Kind: r.Kind, Name: r.Name, Scope: r.Scope, Environment: r.Environment}
+				items[k] = item
+			}
+			if n := len(item.Workflows); n == 0 || item.Workflows[n-1] != file {
+				item.Workflows = append(item.Workflows, file)
+			}
+		}
+	}
+
+	for _, item := range items {
+		p.Checklist = append(p.Checklist, *item)
+	}
+	sort.Slice(p.Checklist, func(i, j int) bool {
+		a, b := p.Checklist[i], p.Checklist[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Scope != b.Scope {
+			return a.Scope < b.Scope
+		}
+		return a.Environment < b.Environment
+	})
+	return p, nil
+}
+
+// workflowRequirements returns the requirements of w, sorted by kind and
+// name.
+func workflowRequirements(w *actionlint.Workflow) []Requirement {
+	declared := map[string]bool{}
+	for _, e := range w.On {
+		if call, ok := e.(*actionlint.WorkflowCallEvent); ok {
+			for name := range call.Secrets {
+				declared[strings.ToUpper(name)] = true
+			}
+		}
+	}
+
+	type reqKey struct{ kind, name, environment string }
+	byKey := map[reqKey]*Requirement{}
+	var order []reqKey
+	add := func(s *actionlint.String, job, environment string) {
+		for _, m := range settingReference.FindAllStringSubmatch(s.Value, -1) {
+			kind, name := "variable", strings.ToUpper(m[2]+m[3])
+			if m[1] == "secrets" {
+				kind = "secret"
+				if name == "GITHUB_TOKEN" {
+					continue
+				}
+			}
+			scope, env := ScopeRepository, environment
+			switch {
+			case kind == "secret" && declared[name]:
+				scope, env = ScopeCaller, ""
+			case env != "":
+				scope = ScopeEnvironment
+			}
+			k := reqKey{kind, name, env}
+			r := byKey[k]
+			if r == nil {
+				r = &Requirement{Kind: kind, Name: name, Scope: scope, Environment: env}
+				byKey[k] = r
+				order = append(order, k)
+			}
+			r.References = append(r.References, SettingReference{Job: job, Line: s.Pos.Line, Column: s.Pos.Col})
+		}
+	}
+
+	inJobs := map[*actionlint.String]bool{}
+	for _, id := range sortedJobs(w) {
+		job := w.Jobs[id]
+		environment := ""
+		if job.Environment != nil && job.Environment.Name != nil {
+			environment = job.Environment.Name.Value
+		}
+		for _, s := range rules.Strings(job) {
+			inJobs[s] = true
+			add(s, id, environment)
+		}
+	}
+	for _, s := range rules.Strings(w) {
+		if !inJobs[s] {
+			add(s, "", "")
+		}
+	}
+
+	// A secret read by the workflow or a job without an environment must
+	// be provisioned where every job sees it
+	reqs := make([]Requirement, 0, len(order))
+	for _, k := range order {
+		if wide := byKey[reqKey{k.kind, k.name, ""}]; k.environment != "" && wide != nil {
+			wide.References = append(wide.References, byKey[k].References...)
+			delete(byKey, k)
+		}
+	}
+	for _, k := range order {
+		if r := byKey[k]; r != nil {
+			reqs = append(reqs, *r)
+		}
+	}
+	for i := range reqs {
+		sort.SliceStable(reqs[i].References, func(a, b int) bool {
+			x, y := reqs[i].References[a], reqs[i].References[b]
+			return x.Line < y.Line || x.Line == y.Line && x.Column < y.Column
+		})
+	}
+	sort.SliceStable(reqs, func(i, j int) bool {
+		if reqs[i].Kind != reqs[j].Kind {
+			return reqs[i].Kind < reqs[j].Kind
+		}
+		if reqs[i].Name != reqs[j].Name {
+			return reqs[i].Name < reqs[j].Name
+		}
+		return reqs[i].Environment < reqs[j].Environment
+	})
+	return reqs
+}
+
+// Environments returns the environments the checklist needs secrets or
+// variables of, sorted. Environments named by expressions are left out.
+func (p *Provisioning) Environments() []string {
+	seen := map[string]bool{}
+	var envs []string
+	for _, item := range p.Checklist {
+		if item.Scope == ScopeEnvironment && !seen[item.Environment] && !strings.Contains(item.Environment, "${{") {
+			seen[item.Environment] = true
+			envs = append(envs, item.Environment)
+		}
+	}
+	sort.Strings(envs)
+	return envs
+}
+
+// Compare marks each checklist item provisioned or missing according to
+// the settings of the repository. An environment item is provisioned by
+// its environment, the repository or the organization, and any other item
+// by the repository or the organization. Secrets declared by a reusable
+// workflow are left unmarked, since its callers pass them, and items of
+// environments named by expressions are unknown unless the repository or
+// the organization provides them.
+func (p *Provisioning) Compare(settings []SettingsScope) {
+	for i := range p.Checklist {
+		item := &p.Checklist[i]
+		if item.Scope == ScopeCaller {
+			continue
+		}
+		item.Status, item.ProvidedBy = "missing", nil
+		for _, s := range settings {
+			if s.Scope == ScopeEnvironment && (item.Scope != ScopeEnvironment || s.Environment != item.Environment) {
+				continue
+			}
+			names := s.Variables
+			if item.Kind == "secret" {
+				names = s.Secrets
+			}
+			for _, name := range names {
+				if strings.EqualFold(name, item.Name) {
+					where := s.Scope
+					if s.Scope == ScopeEnvironment {
+						where += " " + s.Environment
+					}
+					item.Status = "provisioned"
+					item.ProvidedBy = append(item.ProvidedBy, where)
+					break
+				}
+			}
+		}
+		if item.Status == "missing" && strings.Contains(item.Environment, "${{") {
+			item.Status = "unknown"
+		}
+	}
+}
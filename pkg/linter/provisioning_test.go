@@ -0,0 +1,95 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const deployWorkflow = `on: push
+env:
+  REGISTRY: ${{ vars.REGISTRY }}
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: npm publish
+        env:
+          NODE_AUTH_TOKEN: ${{ secrets.npm_token }}
+          GH_TOKEN: ${{ secrets.GITHUB_TOKEN }}
+  deploy:
+    runs-on: ubuntu-latest
+    environment: production
+    steps:
+      - run: ./deploy.sh ${{ vars.REGION }}
+        env:
+          KEY: ${{ secrets.DEPLOY_KEY }}
+          NPM: ${{ secrets['NPM_TOKEN'] }}
+`
+
+const reusableWorkflow = `on:
+  workflow_call:
+    secrets:
+      token:
+        required: true
+jobs:
+  release:
+    runs-on: ubuntu-latest
+    environment: ${{ inputs.environment }}
+    steps:
+      - run: ./release.sh
+        env:
+          TOKEN: ${{ secrets.TOKEN }}
+          KEY: ${{ secrets.SIGNING_KEY }}
+`
+
+func TestProvisioningChecklist(t *testing.T) {
+	dir := t.TempDir()
+	deploy, reusable := filepath.Join(dir, "deploy.yml"), filepath.Join(dir, "release.yml")
+	require.NoError(t, os.WriteFile(deploy, []byte(deployWorkflow), 0644))
+	require.NoError(t, os.WriteFile(reusable, []byte(reusableWorkflow), 0644))
+
+	p, err := ProvisioningChecklist([]string{deploy, reusable})
+	require.NoError(t, err)
+	require.Len(t, p.Workflows, 2)
+
+	assert.Equal(t, []Requirement{
+		{Kind: "secret", Name: "DEPLOY_KEY", Scope: ScopeEnvironment, Environment: "production", References: []SettingReference{{Job: "deploy", Line: 18, Column: 16}}},
+		{Kind: "secret", Name: "NPM_TOKEN", Scope: ScopeRepository, References: []SettingReference{{Job: "build", Line: 10, Column: 28}, {Job: "deploy", Line: 19, Column: 16}}},
+		{Kind: "variable", Name: "REGION", Scope: ScopeEnvironment, Environment: "production", References: []SettingReference{{Job: "deploy", Line: 16, Column: 14}}},
+		{Kind: "variable", Name: "REGISTRY", Scope: ScopeRepository, References: []SettingReference{{Line: 3, Column: 13}}},
+	}, p.Workflows[0].Requirements)
+
+	reqs := p.Workflows[1].Requirements
+	require.Len(t, reqs, 2)
+	assert.Equal(t, "SIGNING_KEY", reqs[0].Name)
+	assert.Equal(t, ScopeEnvironment, reqs[0].Scope)
+	assert.Equal(t, "TOKEN", reqs[1].Name)
+	assert.Equal(t, ScopeCaller, reqs[1].Scope)
+	assert.Empty(t, reqs[1].Environment)
+
+	require.Len(t, p.Checklist, 6)
+	assert.Equal(t, []string{"production"}, p.Environments())
+
+	p.Compare([]SettingsScope{
+		{Scope: ScopeRepository, Secrets: []string{"NPM_TOKEN"}},
+		{Scope: ScopeOrganization, Variables: []string{"REGISTRY"}, Secrets: []string{"DEPLOY_KEY"}},
+		{Scope: ScopeEnvironment, Environment: "production", Secrets: []string{"DEPLOY_KEY"}},
+		{Scope: ScopeEnvironment, Environment: "staging", Variables: []string{"REGION"}},
+	})
+	status := map[string]ChecklistItem{}
+	for _, item := range p.Checklist {
+		status[item.Name] = item
+	}
+	assert.Equal(t, "provisioned", status["DEPLOY_KEY"].Status)
+	assert.Equal(t, []string{"organization", "environment production"}, status["DEPLOY_KEY"].ProvidedBy)
+	assert.Equal(t, "provisioned", status["NPM_TOKEN"].Status)
+	assert.Equal(t, "missing", status["REGION"].Status, "staging does not provide production's variables")
+	assert.Equal(t, "provisioned", status["REGISTRY"].Status)
+	assert.Equal(t, "unknown", status["SIGNING_KEY"].Status)
+	assert.Empty(t, status["TOKEN"].Status)
+	assert.Equal(t, []string{reusable}, status["TOKEN"].Workflows)
+}
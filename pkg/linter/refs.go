@@ -0,0 +1,78 @@
+package linter
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/rhysd/actionlint"
+)
+
+// KindUnknownRef is the kind of findings about branches and tags filter
+// entries that match no ref of the git repository.
+const KindUnknownRef = rules.KindUnknownRef
+
+// refFilterFindings reports the entries of the branches and tags filters of
+// the workflow at path that match none of the branches or tags of the git
+// repository it is in. Nothing is reported outside a git repository, and
+// branches or tags are only checked when the repository has some, since a
+// shallow clone may have neither.
+func refFilterFindings(ctx context.Context, path string, content []byte) []LintError {
+	w, _ := actionlint.Parse(content)
+	if w == nil {
+		return nil
+	}
+	refs, err := ListGitRefs(ctx, filepath.Dir(path))
+	if err != nil {
+		return nil
+	}
+
+	var found []LintError
+	for _, e := range w.On {
+		hook, ok := e.(*actionlint.WebhookEvent)
+		if !ok {
+			continue
+		}
+		switch hook.EventName() {
+		case "push":
+			found = append(found, unknownRefs(hook.Branches, "branch", refs.Branches)...)
+			found = append(found, unknownRefs(hook.BranchesIgnore, "branch", refs.Branches)...)
+			found = append(found, unknownRefs(hook.Tags, "tag", refs.Tags)...)
+			found = append(found, unknownRefs(hook.TagsIgnore, "tag", refs.Tags)...)
+		case "pull_request", "pull_request_target", "workflow_run":
+			found = append(found, unknownRefs(hook.Branches, "branch", refs.Branches)...)
+			found = append(found, unknownRefs(hook.BranchesIgnore, "branch", refs.Branches)...)
+		}
+	}
+	return found
+}
+
+// unknownRefs reports the entries of filter that match none of refs, which
+// are the names of the branches or tags of the repository, as what says.
+// Exclusions are left alone: they only narrow what the other entries match.
+func unknownRefs(filter *actionlint.WebhookEventFilter, what string, refs []string) []LintError {
+	if filter.IsEmpty() || len(refs) == 0 {
+		return nil
+	}
+	var found []LintError
+	for _, v := range filter.Values {
+		if strings.HasPrefix(v.Value, "!") {
+			continue
+		}
+		re, err := globRegexp(v.Value)
+		if err != nil || slices.ContainsFunc(refs, re.MatchString) {
+			continue
+		}
+		found = append(found, LintError{
+			Message:  fmt.Sprintf("no %s of the repository matches %q in the %s filter, so the entry currently selects nothing", what, v.Value, filter.Name.Value),
+			Line:     v.Pos.Line,
+			Column:   v.Pos.Col,
+			Kind:     KindUnknownRef,
+			Severity: SeverityForKind(KindUnknownRef),
+		})
+	}
+	return found
+}
@@ -0,0 +1,70 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefFilterFindings(t *testing.T) {
+	dir := gitRepo(t)
+	git(t, dir, "branch", "release/1.x")
+	git(t, dir, "tag", "v1.0.0")
+	path := filepath.Join(dir, ".github", "workflows", "ci.yml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	content := `on:
+  push:
+    branches: [main, master, 'release/**', '!release/old']
+    tags: ['v*', 'release-*']
+  pull_request:
+    branches-ignore: [develop]
+  workflow_run:
+    workflows: [build]
+    branches: ['feature/*']
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo hi
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	style := rules.Config{Packs: []string{"style"}}
+	result, err := New(Options{Rules: style}).Lint(t.Context(), Input{Path: path})
+	require.NoError(t, err)
+	var found []LintError
+	for _, e := range result.Errors {
+		if e.Kind == KindUnknownRef {
+			found = append(found, e)
+		}
+	}
+	require.Len(t, found, 4)
+	assert.Equal(t, `no branch of the repository matches "master" in the branches filter, so the entry currently selects nothing`, found[0].Message)
+	assert.Equal(t, 3, found[0].Line)
+	assert.Equal(t, "warning", found[0].Severity)
+	assert.Contains(t, found[1].Message, `no tag of the repository matches "release-*" in the tags filter`)
+	assert.Contains(t, found[2].Message, `"develop" in the branches-ignore filter`)
+	assert.Contains(t, found[3].Message, `"feature/*"`)
+
+	result, err = New(Options{Rules: style, SkipRefFilters: true}).Lint(t.Context(), Input{Path: path})
+	require.NoError(t, err)
+	for _, e := range result.Errors {
+		assert.NotEqual(t, KindUnknownRef, e.Kind)
+	}
+}
+
+func TestRefFilterFindings_NoRefs(t *testing.T) {
+	content := []byte("on:\n  push:\n    branches: [master]\n    tags: ['v*']\njobs: {}\n")
+
+	// Outside a git repository
+	assert.Empty(t, refFilterFindings(t.Context(), filepath.Join(t.TempDir(), "ci.yml"), content))
+
+	// A repository without tags only has its branches checked
+	found := refFilterFindings(t.Context(), filepath.Join(gitRepo(t), "ci.yml"), content)
+	require.Len(t, found, 1)
+	assert.Contains(t, found[0].Message, `"master"`)
+}
@@ -0,0 +1,106 @@
+package linter
+
+import (
+	"slices"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/rhysd/actionlint"
+)
+
+// Severity levels reported for findings.
+const (
+	SeverityCritical = "critical"
+	SeverityError    = "error"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// severities lists the severity levels from the most to the least severe.
+var severities = []string{SeverityCritical, SeverityError, SeverityWarning, SeverityInfo}
+
+// AtLeast reports whether severity is at least as severe as level. An
+// unknown severity or level is never.
+func AtLeast(severity, level string) bool {
+	i, limit := slices.Index(severities, severity), slices.Index(severities, level)
+	return i >= 0 && limit >= 0 && i <= limit
+}
+
+// SchemaVersion is the version of the result format: LintResult, Summary
+// and the outputs of the MCP tools built on them. It is raised for changes
+// that could break a parser, such as a renamed field or severity; fields
+// are added without raising it.
+const SchemaVersion = 1
+
+// LintResult holds the findings for a single workflow file.
+type LintResult struct {
+	// SchemaVersion is the version of the result format, set on the
+	// results Lint returns. The results of a Summary leave it out, since
+	// the Summary has it.
+	SchemaVersion int         `json:"schema_version,omitempty"`
+	Errors        []LintError `json:"errors"`
+	Valid         bool        `json:"valid"`
+	FilePath      string      `json:"file_path,omitempty"`
+	// OmittedFindings is the number of findings left out of Errors to fit
+	// a response size limit. Valid, and the totals of a Summary, still
+	// count them.
+	OmittedFindings int   `json:"omitted_findings,omitempty"`
+	Meta            *Meta `json:"meta,omitempty"`
+}
+
+// LintError is a single finding reported by actionlint.
+type LintError struct {
+	Message  string `json:"message"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Kind     string `json:"kind"`
+	Severity string `json:"severity"`
+	// Fix is a change that resolves the finding, when one is known.
+	// Findings reported at the same place can share a fix.
+	Fix *rules.Fix `json:"fix,omitempty"`
+}
+
+// FailOn sets Valid to whether r has no finding at least as severe as
+// level, so less severe findings are still reported without failing the
+// file.
+func (r *LintResult) FailOn(level string) {
+	r.Valid = !slices.ContainsFunc(r.Errors, func(e LintError) bool {
+		return AtLeast(e.Severity, level)
+	})
+}
+
+// SeverityForKind maps an actionlint rule kind to a severity level.
+// actionlint's own checks report workflows that fail or misbehave when
+// they run, so they are errors, except for deprecated workflow commands,
+// which still work.
+func SeverityForKind(kind string) string {
+	switch kind {
+	case rules.KindPlaintextSecret:
+		return SeverityCritical
+	case "syntax-check", "type-check", "expression", "job-needs", "runner-label", "action", "events", "matrix", "credentials", "env-var", "glob", "id", "if-cond", "permissions", "shell-name", "workflow-call", KindNotWorkflow, KindContextAvailability, KindAct, KindReusableCalls, KindConcurrencyDeadlock, rules.KindMatrixSize, rules.KindSecretEnvFile, rules.KindRunnerShell:
+		return SeverityError
+	case "deprecated-commands", "shellcheck", "pyflakes", KindMultiDocument, KindOutputContract, KindDockerAction, KindDuplicateName, rules.KindMatrixInclude, rules.KindConstantCondition, rules.KindUnreachableJob, rules.KindEventFilter, rules.KindEnvFile, rules.KindCheckout, rules.KindFailureHandling, rules.KindUndefinedVariable, rules.KindUndefinedSecret, rules.KindUndefinedLabel, rules.KindRelease, rules.KindSchedule, rules.KindRequiredSteps, rules.KindStepOrder, rules.KindTokenPermissions, rules.KindEgress, rules.KindUnpinnedAction, rules.KindForkSafety, rules.KindDeprecatedInput, rules.KindActionMetadata, rules.KindPortableScript, rules.KindRunnerTool, rules.KindMatrixOS, rules.KindGHCLI, rules.KindCloudDeploy, rules.KindShellStrictness, rules.KindWorkingDirectory, KindConcurrencyStarvation, KindExternalLinter, KindUnknownRef:
+		return SeverityWarning
+	default:
+		return SeverityInfo
+	}
+}
+
+func newResult(path string, errs []*actionlint.Error) *LintResult {
+	result := &LintResult{
+		Errors:   make([]LintError, 0, len(errs)),
+		Valid:    len(errs) == 0,
+		FilePath: path,
+	}
+
+	for _, e := range errs {
+		result.Errors = append(result.Errors, LintError{
+			Message:  e.Message,
+			Line:     e.Line,
+			Column:   e.Column,
+			Kind:     e.Kind,
+			Severity: SeverityForKind(e.Kind),
+		})
+	}
+
+	return result
+}
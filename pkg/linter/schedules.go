@@ -0,0 +1,122 @@
+package linter
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/rhysd/actionlint"
+)
+
+// selfHosted reports whether a job of w runs on self-hosted runners.
+func selfHosted(w *actionlint.Workflow) bool {
+	for _, job := range w.Jobs {
+		if job.RunsOn == nil {
+			continue
+		}
+		if job.RunsOn.Group != nil {
+			return true
+		}
+		for _, l := range job.RunsOn.Labels {
+			if strings.EqualFold(l.Value, "self-hosted") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scheduleMinutes returns the cron schedules of w and the minutes of the
+// day each runs at.
+func scheduleMinutes(w *actionlint.Workflow) map[*actionlint.String]map[int]bool {
+	crons := map[*actionlint.String]map[int]bool{}
+	for _, e := range w.On {
+		sched, ok := e.(*actionlint.ScheduledEvent)
+		if !ok {
+			continue
+		}
+		for _, c := range sched.Cron {
+			minutes, err := rules.ScheduleMinutes(c.Value)
+			if err != nil {
+				continue
+			}
+			set := make(map[int]bool, len(minutes))
+			for _, m := range minutes {
+				set[m] = true
+			}
+			crons[c] = set
+		}
+	}
+	return crons
+}
+
+// scheduleCollisionFindings reports the cron schedules of the workflow at
+// path that start at the same minute as a schedule of another workflow in
+// its directory, when both run on self-hosted runners, which then have to
+// take every job at once. Only workflows in WorkflowsDir are compared.
+func scheduleCollisionFindings(path string, content []byte) []LintError {
+	root := repositoryRoot(path)
+	path = filepath.Clean(path)
+	dir := filepath.Dir(path)
+	if filepath.Clean(root) == dir {
+		return nil
+	}
+	r := &repoWorkflows{root: root, parsed: map[string]*actionlint.Workflow{}}
+	w := r.parse(path, content)
+	if w == nil || !selfHosted(w) {
+		return nil
+	}
+	own := scheduleMinutes(w)
+	if len(own) == 0 {
+		return nil
+	}
+	files, err := FindWorkflowFiles(dir)
+	if err != nil {
+		return nil
+	}
+
+	var found []LintError
+	for _, e := range w.On {
+		sched, ok := e.(*actionlint.ScheduledEvent)
+		if !ok {
+			continue
+		}
+		for _, c := range sched.Cron {
+			minutes := own[c]
+			var others []string
+			first := -1
+			for _, f := range files {
+				other := r.parse(f, nil)
+				if f == path || other == nil || !selfHosted(other) {
+					continue
+				}
+				shared := -1
+				for _, set := range scheduleMinutes(other) {
+					for m := range set {
+						if minutes[m] && (shared < 0 || m < shared) {
+							shared = m
+						}
+					}
+				}
+				if shared >= 0 {
+					others = append(others, relativeTo(root, f))
+					if first < 0 || shared < first {
+						first = shared
+					}
+				}
+			}
+			if len(others) == 0 {
+				continue
+			}
+			found = append(found, LintError{
+				Message:  fmt.Sprintf("cron schedule %q starts at %02d:%02d UTC like a schedule of %s, and both run on self-hosted runners, which then get all their jobs at once. move one of them by a few minutes", c.Value, first/60, first%60, strings.Join(others, ", ")),
+				Line:     c.Pos.Line,
+				Column:   c.Pos.Col,
+				Kind:     rules.KindSchedule,
+				Severity: SeverityForKind(rules.KindSchedule),
+			})
+		}
+	}
+	return found
+}
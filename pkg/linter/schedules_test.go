@@ -0,0 +1,41 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleCollisionFindings(t *testing.T) {
+	repo := t.TempDir()
+	dir := filepath.Join(repo, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	write := func(name, cron, runsOn string) string {
+		content := "on:\n  schedule:\n    - cron: '" + cron + "'\njobs:\n  job:\n    runs-on: " + runsOn + "\n    steps:\n      - run: echo hi\n"
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	nightly := write("nightly.yml", "0 2 * * *", "[self-hosted, linux]")
+	write("backup.yml", "0 */2 * * *", "self-hosted")
+	write("hosted.yml", "0 2 * * *", "ubuntu-latest")
+	write("offset.yml", "5 2 * * *", "self-hosted")
+
+	findings := scheduleCollisionFindings(nightly, nil)
+	require.Len(t, findings, 1)
+	assert.Equal(t, LintError{
+		Message:  `cron schedule "0 2 * * *" starts at 02:00 UTC like a schedule of .github/workflows/backup.yml, and both run on self-hosted runners, which then get all their jobs at once. move one of them by a few minutes`,
+		Line:     3,
+		Column:   13,
+		Kind:     rules.KindSchedule,
+		Severity: SeverityWarning,
+	}, findings[0])
+
+	assert.Empty(t, scheduleCollisionFindings(filepath.Join(dir, "hosted.yml"), nil))
+	assert.Empty(t, scheduleCollisionFindings(filepath.Join(dir, "offset.yml"), nil))
+}
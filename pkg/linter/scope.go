@@ -0,0 +1,92 @@
+package linter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ScopeLines returns the lines of content that scope covers: a job, given
+// by its ID, or one of its steps, given as job/step where step is the
+// step's id or its position counting from 1. A job or step ends where the
+// next one starts, so comments between two of them belong to the first.
+func ScopeLines(content []byte, scope string) (map[int]bool, error) {
+	content, err := normalizeEncoding(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode content: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse workflow: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("workflow is empty")
+	}
+	root := doc.Content[0]
+	total := strings.Count(string(content), "\n") + 1
+
+	jobID, step, hasStep := strings.Cut(scope, "/")
+	jobsKey, jobs := mappingEntry(root, "jobs")
+	if jobs == nil || jobs.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("workflow has no jobs")
+	}
+
+	// The jobs end where the next top-level key after them starts
+	end := total
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if k := root.Content[i]; k.Line > jobsKey.Line && k.Line <= end {
+			end = k.Line - 1
+		}
+	}
+
+	start := 0
+	var job *yaml.Node
+	for i := 0; i+1 < len(jobs.Content); i += 2 {
+		k := jobs.Content[i]
+		if job != nil {
+			end = k.Line - 1
+			break
+		}
+		if k.Value == jobID {
+			start, job = k.Line, jobs.Content[i+1]
+		}
+	}
+	if job == nil {
+		return nil, fmt.Errorf("job %q not found", jobID)
+	}
+
+	if hasStep {
+		steps := mappingValue(job, "steps")
+		if steps == nil || steps.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("job %q has no steps", jobID)
+		}
+		index := -1
+		if n, err := strconv.Atoi(step); err == nil {
+			if n >= 1 && n <= len(steps.Content) {
+				index = n - 1
+			}
+		} else {
+			for i, s := range steps.Content {
+				if scalarValue(mappingValue(s, "id")) == step {
+					index = i
+					break
+				}
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("job %q has no step %s", jobID, step)
+		}
+		start = steps.Content[index].Line
+		if index+1 < len(steps.Content) {
+			end = steps.Content[index+1].Line - 1
+		}
+	}
+
+	lines := make(map[int]bool, end-start+1)
+	for l := start; l <= end; l++ {
+		lines[l] = true
+	}
+	return lines, nil
+}
@@ -0,0 +1,61 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const scopeWorkflow = `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - id: compile
+        run: |
+          make
+
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+env:
+  GO: "1"
+`
+
+func lineRange(from, to int) map[int]bool {
+	lines := map[int]bool{}
+	for l := from; l <= to; l++ {
+		lines[l] = true
+	}
+	return lines
+}
+
+func TestScopeLines(t *testing.T) {
+	for _, tt := range []struct {
+		scope string
+		lines map[int]bool
+	}{
+		{"build", lineRange(3, 10)},
+		{"build/1", lineRange(6, 6)},
+		{"build/2", lineRange(7, 10)},
+		{"build/compile", lineRange(7, 10)},
+		{"test", lineRange(11, 14)},
+		{"test/1", lineRange(14, 14)},
+	} {
+		t.Run(tt.scope, func(t *testing.T) {
+			lines, err := ScopeLines([]byte(scopeWorkflow), tt.scope)
+			require.NoError(t, err)
+			assert.Equal(t, tt.lines, lines)
+		})
+	}
+
+	_, err := ScopeLines([]byte(scopeWorkflow), "deploy")
+	assert.ErrorContains(t, err, `job "deploy" not found`)
+	_, err = ScopeLines([]byte(scopeWorkflow), "build/3")
+	assert.ErrorContains(t, err, `job "build" has no step 3`)
+	_, err = ScopeLines([]byte("on: push\n"), "build")
+	assert.ErrorContains(t, err, "no jobs")
+}
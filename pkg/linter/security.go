@@ -0,0 +1,209 @@
+package linter
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// Kinds of security-relevant change to a workflow.
+const (
+	ChangeWritePermission = "write-permission"
+	ChangeTrigger         = "privileged-trigger"
+	ChangeUnpinned        = "unpinned-action"
+)
+
+// privilegedTriggers run with a write token and the repository's secrets
+// for events that forks can cause, with the reason.
+var privilegedTriggers = map[string]string{
+	"pull_request_target": "runs with a write token and the repository's secrets for pull requests from forks",
+	"workflow_run":        "runs with a write token and the repository's secrets after workflows that pull requests from forks can trigger",
+}
+
+// SecurityChange is a change to a workflow that reviewers should look at.
+// Line is the line in the changed workflow, or 0 when the change is that
+// something is missing.
+type SecurityChange struct {
+	Kind    string `json:"kind"`
+	Job     string `json:"job,omitempty"`
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+}
+
+// SecurityChanges compares a workflow before and after a change and
+// reports what the change grants that the workflow did not have: scopes
+// with write access, privileged triggers, and actions used by tag or branch
+// that were pinned to a commit or not used before. base is empty for a new
+// workflow, and nothing is reported when head cannot be parsed.
+func SecurityChanges(base, head []byte) []SecurityChange {
+	w, _ := actionlint.Parse(head)
+	if w == nil {
+		return nil
+	}
+	bw, _ := actionlint.Parse(base)
+	if bw == nil || len(bytes.TrimSpace(base)) == 0 {
+		// Everything is new, so compare with a workflow granting nothing
+		bw = &actionlint.Workflow{Permissions: &actionlint.Permissions{}}
+	}
+
+	var out []SecurityChange
+	events := map[string]bool{}
+	for _, e := range bw.On {
+		events[e.EventName()] = true
+	}
+	for _, e := range w.On {
+		why, ok := privilegedTriggers[e.EventName()]
+		if !ok || events[e.EventName()] {
+			continue
+		}
+		c := SecurityChange{
+			Kind:    ChangeTrigger,
+			Message: fmt.Sprintf("the workflow now runs on %q, which %s", e.EventName(), why),
+		}
+		if hook, ok := e.(*actionlint.WebhookEvent); ok && hook.Pos != nil {
+			c.Line = hook.Pos.Line
+		}
+		out = append(out, c)
+	}
+
+	ids := make([]string, 0, len(w.Jobs))
+	ownPermissions := true
+	for id, j := range w.Jobs {
+		ids = append(ids, id)
+		ownPermissions = ownPermissions && j.Permissions != nil
+	}
+	sort.Strings(ids)
+
+	if w.Permissions != nil || !ownPermissions {
+		out = append(out, writeChanges("", nil, bw.Permissions, w.Permissions)...)
+	}
+	for _, id := range ids {
+		j, bj := w.Jobs[id], bw.Jobs[id]
+		if j.Permissions == nil && (bj == nil || bj.Permissions == nil) {
+			continue // Changes to the inherited permissions are reported once
+		}
+		before, after := bw.Permissions, j.Permissions
+		if bj != nil && bj.Permissions != nil {
+			before = bj.Permissions
+		}
+		if after == nil {
+			after = w.Permissions
+		}
+		out = append(out, writeChanges(j.ID.Value, j.ID.Pos, before, after)...)
+	}
+
+	pinned, unpinned := map[string]bool{}, map[string]bool{}
+	for _, uses := range workflowUses(bw) {
+		name, _, _ := strings.Cut(strings.ToLower(uses.Value), "@")
+		if unpinnedAction(uses.Value) {
+			unpinned[name] = true
+		} else {
+			pinned[name] = true
+		}
+	}
+	reported := map[string]bool{}
+	for _, id := range ids {
+		for _, uses := range jobUses(w.Jobs[id]) {
+			name, _, _ := strings.Cut(strings.ToLower(uses.Value), "@")
+			if !unpinnedAction(uses.Value) || unpinned[name] || reported[uses.Value] {
+				continue
+			}
+			reported[uses.Value] = true
+			msg := fmt.Sprintf("%q is new and refers to a tag or branch, so the code it runs can change without a change to the workflow. pin it to a commit SHA", uses.Value)
+			if pinned[name] {
+				msg = fmt.Sprintf("%q was pinned to a commit and now refers to a tag or branch, which can be moved to different code. keep it pinned to a commit SHA", uses.Value)
+			}
+			out = append(out, SecurityChange{Kind: ChangeUnpinned, Job: id, Message: msg, Line: uses.Pos.Line})
+		}
+	}
+	return out
+}
+
+// writeChanges reports the scopes after grants write access to that
+// before does not. pos is the position of the job, or nil for the
+// workflow's permissions.
+func writeChanges(job string, pos *actionlint.Pos, before, after *actionlint.Permissions) []SecurityChange {
+	where := "the workflow"
+	if job != "" {
+		where = fmt.Sprintf("job %q", job)
+	}
+	scopes := append([]string{}, permissionScopes...)
+	if after != nil {
+		for s := range after.Scopes {
+			if !slices.Contains(permissionScopes, s) {
+				scopes = append(scopes, s)
+			}
+		}
+	}
+	var names []string
+	for _, s := range scopes {
+		if permissionLevel(after, s) == permissionLevels["write"] && permissionLevel(before, s) < permissionLevels["write"] {
+			names = append(names, s)
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return nil
+	}
+
+	switch {
+	case after == nil:
+		c := SecurityChange{
+			Kind:    ChangeWritePermission,
+			Job:     job,
+			Message: fmt.Sprintf("%s does not restrict its permissions, so it gets the repository's default token permissions, which can include write access to every scope", where),
+		}
+		if pos != nil {
+			c.Line = pos.Line
+		}
+		return []SecurityChange{c}
+	case after.All != nil:
+		return []SecurityChange{{
+			Kind:    ChangeWritePermission,
+			Job:     job,
+			Message: fmt.Sprintf("%s now grants %q, giving write access to %s", where, after.All.Value, strings.Join(names, ", ")),
+			Line:    after.All.Pos.Line,
+		}}
+	}
+	out := make([]SecurityChange, 0, len(names))
+	for _, s := range names {
+		c := SecurityChange{
+			Kind:    ChangeWritePermission,
+			Job:     job,
+			Message: fmt.Sprintf("%s now grants write access to %q", where, s),
+		}
+		if scope, ok := after.Scopes[s]; ok && scope.Value != nil {
+			c.Line = scope.Value.Pos.Line
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// workflowUses returns the uses: of every job and step of w.
+func workflowUses(w *actionlint.Workflow) []*actionlint.String {
+	var out []*actionlint.String
+	for _, j := range w.Jobs {
+		out = append(out, jobUses(j)...)
+	}
+	return out
+}
+
+// jobUses returns the reusable workflow a job calls and the actions its
+// steps use.
+func jobUses(j *actionlint.Job) []*actionlint.String {
+	var out []*actionlint.String
+	if j.WorkflowCall != nil && j.WorkflowCall.Uses != nil {
+		out = append(out, j.WorkflowCall.Uses)
+	}
+	for _, s := range j.Steps {
+		if exec, ok := s.Exec.(*actionlint.ExecAction); ok && exec.Uses != nil {
+			out = append(out, exec.Uses)
+		}
+	}
+	return out
+}
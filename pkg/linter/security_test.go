@@ -0,0 +1,120 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const securityBase = `on: push
+permissions:
+  contents: read
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@b4ffde65f46336ab88eb53be808477a3936bae11
+      - uses: actions/setup-go@v5
+  release:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: write
+    steps:
+      - run: echo release
+`
+
+func TestSecurityChanges(t *testing.T) {
+	head := `on:
+  push:
+  pull_request_target:
+permissions:
+  contents: read
+  packages: write
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-go@v6
+      - uses: docker/login-action@v3
+  release:
+    runs-on: ubuntu-latest
+    permissions: write-all
+    steps:
+      - run: echo release
+`
+	changes := SecurityChanges([]byte(securityBase), []byte(head))
+	require.Len(t, changes, 5)
+
+	assert.Equal(t, ChangeTrigger, changes[0].Kind)
+	assert.Equal(t, 3, changes[0].Line)
+	assert.Contains(t, changes[0].Message, `now runs on "pull_request_target"`)
+
+	assert.Equal(t, ChangeWritePermission, changes[1].Kind)
+	assert.Equal(t, "", changes[1].Job)
+	assert.Equal(t, 6, changes[1].Line)
+	assert.Equal(t, `the workflow now grants write access to "packages"`, changes[1].Message)
+
+	assert.Equal(t, "release", changes[2].Job)
+	assert.Equal(t, 16, changes[2].Line)
+	assert.Contains(t, changes[2].Message, `job "release" now grants "write-all", giving write access to actions, attestations, checks, deployments`)
+	assert.NotContains(t, changes[2].Message, "contents")
+
+	// A tag bump of an unpinned action is not a change, but a removed pin
+	// and a new action are
+	assert.Equal(t, ChangeUnpinned, changes[3].Kind)
+	assert.Equal(t, 11, changes[3].Line)
+	assert.Contains(t, changes[3].Message, `"actions/checkout@v4" was pinned to a commit`)
+	assert.Equal(t, 13, changes[4].Line)
+	assert.Contains(t, changes[4].Message, `"docker/login-action@v3" is new`)
+}
+
+func TestSecurityChanges_Unchanged(t *testing.T) {
+	assert.Empty(t, SecurityChanges([]byte(securityBase), []byte(securityBase)))
+	assert.Nil(t, SecurityChanges([]byte(securityBase), []byte("on: [")))
+}
+
+func TestSecurityChanges_Permissions(t *testing.T) {
+	// Removing a job's permissions falls back to the repository defaults
+	head := `on: push
+permissions:
+  contents: read
+jobs:
+  release:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo release
+`
+	base := `on: push
+permissions:
+  contents: read
+jobs:
+  release:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+    steps:
+      - run: echo release
+`
+	assert.Empty(t, SecurityChanges([]byte(base), []byte(head)))
+
+	head = `on: push
+jobs:
+  release:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo release
+`
+	changes := SecurityChanges([]byte(base), []byte(head))
+	require.Len(t, changes, 2)
+	assert.Contains(t, changes[0].Message, "the workflow does not restrict its permissions")
+	assert.Equal(t, 0, changes[0].Line)
+	assert.Contains(t, changes[1].Message, `job "release" does not restrict its permissions`)
+	assert.Equal(t, 3, changes[1].Line)
+
+	// Everything in a new workflow is new
+	changes = SecurityChanges(nil, []byte(head))
+	require.Len(t, changes, 1)
+	assert.Equal(t, ChangeWritePermission, changes[0].Kind)
+}
@@ -0,0 +1,12 @@
+//go:build shellcheck_wasm
+
+package linter
+
+import _ "embed"
+
+// embeddedShellcheck is a WASI build of shellcheck, placed at
+// pkg/linter/shellcheck.wasm, or fetched there by make
+// build-shellcheck-wasm, before building with the shellcheck_wasm tag.
+//
+//go:embed shellcheck.wasm
+var embeddedShellcheck []byte
@@ -0,0 +1,6 @@
+//go:build !shellcheck_wasm
+
+package linter
+
+// embeddedShellcheck is only set in builds with the shellcheck_wasm tag.
+var embeddedShellcheck []byte
@@ -0,0 +1,114 @@
+package linter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxStagedFile is the size of the largest file LintChanges copies.
+const maxStagedFile = 1 << 20
+
+// LintChanges lints the workflows among changes as they will be once
+// written, without writing them: the .github directory of the repository
+// at root is copied to a temporary one, where the changes are applied, so
+// that new local actions are checked along with the workflows using them.
+// Results are in the order of changes and name the changed files.
+func (l *Linter) LintChanges(ctx context.Context, root string, changes []FileChange) ([]*LintResult, error) {
+	stage, err := os.MkdirTemp("", "actionlint-mcp-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(stage)
+
+	if err := copyTree(filepath.Join(root, ".github"), filepath.Join(stage, ".github")); err != nil {
+		return nil, fmt.Errorf("failed to copy %s: %w", filepath.Join(root, ".github"), err)
+	}
+	// actionlint takes the directory holding .git as the repository root
+	if err := os.WriteFile(filepath.Join(stage, ".git"), nil, 0644); err != nil {
+		return nil, err
+	}
+	staged := make([]string, len(changes))
+	for i, c := range changes {
+		rel := relativeTo(root, c.Path)
+		if strings.HasPrefix(rel, "../") || filepath.IsAbs(rel) {
+			return nil, fmt.Errorf("%s is outside of %s", c.Path, root)
+		}
+		staged[i] = filepath.Join(stage, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(staged[i]), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(staged[i], c.Content, 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	// The repository's own configuration is found in the copy
+	opts := l.opts
+	if opts.ConfigFile != "" {
+		if rel := relativeTo(root, opts.ConfigFile); !strings.HasPrefix(rel, "../") && !filepath.IsAbs(rel) {
+			opts.ConfigFile = ""
+		}
+	}
+	stagedLinter := New(opts)
+
+	var results []*LintResult
+	for i, c := range changes {
+		if filepath.Base(filepath.Dir(c.Path)) != "workflows" {
+			continue
+		}
+		result, err := stagedLinter.Lint(ctx, Input{Path: staged[i]})
+		if err != nil {
+			return nil, err
+		}
+		result.FilePath = c.Path
+		for j := range result.Errors {
+			result.Errors[j].Message = strings.ReplaceAll(result.Errors[j].Message, stage, filepath.Clean(root))
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// copyTree copies the regular files of src up to maxStagedFile bytes into
+// dst. A missing src is left out.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == src {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		info, err := d.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Size() > maxStagedFile {
+			return err
+		}
+		in, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
@@ -0,0 +1,107 @@
+package linter
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Summary aggregates the results of linting several workflow files.
+// Results are sorted by file path so repeated runs produce identical output.
+type Summary struct {
+	// SchemaVersion is the version of the result format, set on the
+	// summaries LintFiles returns.
+	SchemaVersion   int          `json:"schema_version,omitempty"`
+	TotalFiles      int          `json:"total_files"`
+	FilesWithErrors int          `json:"files_with_errors"`
+	TotalErrors     int          `json:"total_errors"`
+	Results         []LintResult `json:"results"`
+	Meta            *Meta        `json:"meta,omitempty"`
+}
+
+// LintFiles lints each file and aggregates the results. A file that cannot
+// be linted is reported as an invalid result carrying the failure, so one
+// bad file never hides the others.
+func (l *Linter) LintFiles(ctx context.Context, files []string) *Summary {
+	start := time.Now()
+	var failures []string
+	summary := &Summary{
+		SchemaVersion: SchemaVersion,
+		TotalFiles:    len(files),
+		Results:       make([]LintResult, 0, len(files)),
+	}
+
+	for _, file := range files {
+		result, err := l.Lint(ctx, Input{Path: file})
+		if err != nil {
+			result = &LintResult{
+				Errors: []LintError{{
+					Message:  fmt.Sprintf("Failed to lint: %v", err),
+					Severity: SeverityError,
+				}},
+				Valid:    false,
+				FilePath: file,
+			}
+		}
+		if result.Meta != nil {
+			failures = append(failures, result.Meta.failures...)
+		}
+		summary.add(*result)
+	}
+
+	sort.SliceStable(summary.Results, func(i, j int) bool {
+		return summary.Results[i].FilePath < summary.Results[j].FilePath
+	})
+	summary.Meta = l.newMeta(start, failures)
+	return summary
+}
+
+// ResultsByPath returns the results keyed by file path, the shape used by
+// check_all_workflows before results became a sorted list.
+func (s *Summary) ResultsByPath() map[string]LintResult {
+	m := make(map[string]LintResult, len(s.Results))
+	for _, r := range s.Results {
+		m[r.FilePath] = r
+	}
+	return m
+}
+
+// KeepKinds drops every finding whose kind is not one of kinds, and
+// updates the totals.
+func (s *Summary) KeepKinds(kinds ...string) {
+	results := s.Results
+	s.Results, s.FilesWithErrors, s.TotalErrors = make([]LintResult, 0, len(results)), 0, 0
+	for _, r := range results {
+		errs := make([]LintError, 0, len(r.Errors))
+		for _, e := range r.Errors {
+			if slices.Contains(kinds, e.Kind) {
+				errs = append(errs, e)
+			}
+		}
+		r.Errors, r.Valid = errs, len(errs) == 0
+		s.add(r)
+	}
+}
+
+// FailOn applies LintResult.FailOn to every result and recounts
+// FilesWithErrors. TotalErrors still counts every finding.
+func (s *Summary) FailOn(level string) {
+	s.FilesWithErrors = 0
+	for i := range s.Results {
+		s.Results[i].FailOn(level)
+		if !s.Results[i].Valid {
+			s.FilesWithErrors++
+		}
+	}
+}
+
+func (s *Summary) add(result LintResult) {
+	result.SchemaVersion = 0
+	s.Results = append(s.Results, result)
+	if !result.Valid {
+		s.FilesWithErrors++
+		s.TotalErrors += len(result.Errors)
+	}
+}
@@ -0,0 +1,63 @@
+package linter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintFiles(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yml")
+	invalid := filepath.Join(dir, "invalid.yml")
+	missing := filepath.Join(dir, "missing.yml")
+	require.NoError(t, os.WriteFile(valid, []byte(validWorkflow), 0644))
+	require.NoError(t, os.WriteFile(invalid, []byte(invalidWorkflow), 0644))
+
+	summary := New(Options{}).LintFiles(context.Background(), []string{valid, missing, invalid})
+	results := summary.ResultsByPath()
+
+	assert.Equal(t, 3, summary.TotalFiles)
+	assert.Equal(t, 2, summary.FilesWithErrors)
+	assert.Equal(t, len(results[invalid].Errors)+1, summary.TotalErrors)
+	require.Len(t, summary.Results, 3)
+
+	// Results are sorted by path regardless of input order
+	assert.Equal(t, invalid, summary.Results[0].FilePath)
+	assert.Equal(t, missing, summary.Results[1].FilePath)
+	assert.Equal(t, valid, summary.Results[2].FilePath)
+
+	assert.True(t, results[valid].Valid)
+	assert.False(t, results[invalid].Valid)
+
+	// Files that cannot be read are reported rather than dropped
+	failed := results[missing]
+	assert.False(t, failed.Valid)
+	require.Len(t, failed.Errors, 1)
+	assert.Contains(t, failed.Errors[0].Message, "Failed to lint")
+	assert.Equal(t, SeverityError, failed.Errors[0].Severity)
+}
+
+func TestSummary_FailOn(t *testing.T) {
+	summary := &Summary{TotalFiles: 3, FilesWithErrors: 2, TotalErrors: 3, Results: []LintResult{
+		{FilePath: "a.yml", Errors: []LintError{{Severity: SeverityCritical}}},
+		{FilePath: "b.yml", Errors: []LintError{{Severity: SeverityWarning}, {Severity: SeverityInfo}}},
+		{FilePath: "c.yml", Errors: []LintError{}, Valid: true},
+	}}
+
+	summary.FailOn(SeverityError)
+	assert.Equal(t, 1, summary.FilesWithErrors)
+	assert.Equal(t, 3, summary.TotalErrors, "every finding is still counted")
+	assert.Equal(t, []bool{false, true, true}, []bool{summary.Results[0].Valid, summary.Results[1].Valid, summary.Results[2].Valid})
+
+	summary.FailOn(SeverityInfo)
+	assert.Equal(t, 2, summary.FilesWithErrors)
+
+	assert.True(t, AtLeast(SeverityCritical, SeverityWarning))
+	assert.False(t, AtLeast(SeverityInfo, SeverityWarning))
+	assert.False(t, AtLeast("unknown", SeverityInfo))
+}
@@ -0,0 +1,411 @@
+package linter
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/rhysd/actionlint"
+)
+
+// Workflow statuses reported by SimulateTrigger.
+const (
+	TriggerRuns          = "triggered"
+	TriggerFiltered      = "filtered"
+	TriggerNotSubscribed = "not-subscribed"
+	TriggerUnknown       = "unknown"
+)
+
+// defaultTypes are the activity types an event triggers for when the
+// workflow does not list any. Events not listed trigger for all types.
+var defaultTypes = map[string][]string{
+	"pull_request":        {"opened", "synchronize", "reopened"},
+	"pull_request_target": {"opened", "synchronize", "reopened"},
+}
+
+// TriggerEvent is an event to simulate.
+type TriggerEvent struct {
+	// Name is the event name, such as push or pull_request.
+	Name string
+	// Payload is the webhook payload. Only the fields the filters need are
+	// read: action, ref, pull_request.base.ref, workflow_run.head_branch,
+	// workflow_run.name and, for push, the files of commits.
+	Payload map[string]any
+	// ChangedFiles are the files the event changes, for paths filters. nil
+	// means they are taken from the commits of a push payload.
+	ChangedFiles []string
+}
+
+// WorkflowTrigger reports whether a workflow runs for an event and why.
+type WorkflowTrigger struct {
+	FilePath string       `json:"file_path"`
+	Name     string       `json:"name,omitempty"`
+	Status   string       `json:"status"`
+	Reasons  []string     `json:"reasons"`
+	Jobs     []JobTrigger `json:"jobs,omitempty"`
+}
+
+// JobTrigger reports whether a job of a triggered workflow runs. Only
+// comparisons of github.event_name in if: conditions are evaluated.
+type JobTrigger struct {
+	ID     string `json:"id"`
+	Runs   bool   `json:"runs"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// SimulateTrigger reports, for each workflow file, whether ev triggers it,
+// which of its filters decided, and which jobs run.
+func SimulateTrigger(files []string, ev TriggerEvent) ([]WorkflowTrigger, error) {
+	out := make([]WorkflowTrigger, 0, len(files))
+	for _, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		if content, err = normalizeEncoding(content); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		out = append(out, simulateWorkflow(path, content, ev))
+	}
+	return out, nil
+}
+
+func simulateWorkflow(path string, content []byte, ev TriggerEvent) WorkflowTrigger {
+	result := WorkflowTrigger{FilePath: path}
+	w, errs := actionlint.Parse(content)
+	if w == nil {
+		reason := "the workflow cannot be parsed"
+		if len(errs) > 0 {
+			reason += ": " + errs[0].Message
+		}
+		result.Status, result.Reasons = TriggerUnknown, []string{reason}
+		return result
+	}
+	if w.Name != nil {
+		result.Name = w.Name.Value
+	}
+
+	var events []string
+	var matched actionlint.Event
+	for _, e := range w.On {
+		events = append(events, e.EventName())
+		if strings.EqualFold(e.EventName(), ev.Name) {
+			matched = e
+		}
+	}
+	if matched == nil {
+		result.Status = TriggerNotSubscribed
+		result.Reasons = []string{fmt.Sprintf("the workflow does not listen to %s (it listens to %s)", ev.Name, strings.Join(events, ", "))}
+		return result
+	}
+
+	result.Status, result.Reasons = TriggerRuns, []string{fmt.Sprintf("the workflow listens to %s", ev.Name)}
+	if hook, ok := matched.(*actionlint.WebhookEvent); ok {
+		s := &filterCheck{ev: ev, hook: hook, status: TriggerRuns}
+		s.run()
+		result.Status = s.status
+		result.Reasons = append(result.Reasons, s.reasons...)
+	}
+	if result.Status != TriggerFiltered {
+		result.Jobs = simulateJobs(w, ev.Name)
+	}
+	return result
+}
+
+// filterCheck evaluates the filters of a webhook event against a payload.
+// The first filter that excludes the event decides; filters that cannot be
+// evaluated make the outcome unknown.
+type filterCheck struct {
+	ev      TriggerEvent
+	hook    *actionlint.WebhookEvent
+	status  string
+	reasons []string
+}
+
+func (c *filterCheck) filtered(format string, args ...any) {
+	if c.status != TriggerFiltered {
+		c.status = TriggerFiltered
+		c.reasons = append(c.reasons, fmt.Sprintf(format, args...))
+	}
+}
+
+func (c *filterCheck) unknown(format string, args ...any) {
+	if c.status == TriggerRuns {
+		c.status = TriggerUnknown
+	}
+	c.reasons = append(c.reasons, fmt.Sprintf(format, args...))
+}
+
+func (c *filterCheck) passed(format string, args ...any) {
+	c.reasons = append(c.reasons, fmt.Sprintf(format, args...))
+}
+
+func (c *filterCheck) run() {
+	c.checkTypes()
+	name := strings.ToLower(c.ev.Name)
+	switch name {
+	case "push":
+		c.checkPush()
+	case "pull_request", "pull_request_target":
+		branch := payloadString(c.ev.Payload, "pull_request", "base", "ref")
+		c.checkRef("base branch", branch, "pull_request.base.ref", c.hook.Branches, c.hook.BranchesIgnore)
+		c.checkPaths()
+	case "workflow_run":
+		c.checkWorkflows()
+		branch := payloadString(c.ev.Payload, "workflow_run", "head_branch")
+		c.checkRef("branch", branch, "workflow_run.head_branch", c.hook.Branches, c.hook.BranchesIgnore)
+	}
+}
+
+func (c *filterCheck) checkTypes() {
+	types := make([]string, 0, len(c.hook.Types))
+	for _, t := range c.hook.Types {
+		types = append(types, t.Value)
+	}
+	which := "types"
+	if len(types) == 0 {
+		types = defaultTypes[strings.ToLower(c.ev.Name)]
+		which = "default types"
+	}
+	if len(types) == 0 {
+		return
+	}
+
+	action := payloadString(c.ev.Payload, "action")
+	switch {
+	case action == "":
+		c.unknown("the payload has no action to check against the %s (%s)", which, strings.Join(types, ", "))
+	case !slices.Contains(types, action):
+		c.filtered("activity type %q is not in the %s (%s)", action, which, strings.Join(types, ", "))
+	default:
+		c.passed("activity type %q is in the %s", action, which)
+	}
+}
+
+func (c *filterCheck) checkPush() {
+	ref := payloadString(c.ev.Payload, "ref")
+	hasBranches := !c.hook.Branches.IsEmpty() || !c.hook.BranchesIgnore.IsEmpty()
+	hasTags := !c.hook.Tags.IsEmpty() || !c.hook.TagsIgnore.IsEmpty()
+
+	switch {
+	case strings.HasPrefix(ref, "refs/tags/"):
+		tag := strings.TrimPrefix(ref, "refs/tags/")
+		if hasBranches && !hasTags {
+			c.filtered("tag %q was pushed, but the workflow only filters branches, so tag pushes never trigger it", tag)
+			return
+		}
+		c.checkRef("tag", tag, "ref", c.hook.Tags, c.hook.TagsIgnore)
+		if !c.hook.Paths.IsEmpty() || !c.hook.PathsIgnore.IsEmpty() {
+			c.passed("paths filters are not evaluated for tag pushes")
+		}
+		return
+	case strings.HasPrefix(ref, "refs/heads/"):
+		branch := strings.TrimPrefix(ref, "refs/heads/")
+		if hasTags && !hasBranches {
+			c.filtered("branch %q was pushed, but the workflow only filters tags, so branch pushes never trigger it", branch)
+			return
+		}
+		c.checkRef("branch", branch, "ref", c.hook.Branches, c.hook.BranchesIgnore)
+	case hasBranches || hasTags:
+		c.unknown("the payload has no ref under refs/heads/ or refs/tags/ to check the branch and tag filters")
+	}
+	c.checkPaths()
+}
+
+// checkRef checks a branch or tag against a filter and its -ignore
+// counterpart, at most one of which is set.
+func (c *filterCheck) checkRef(what, value, field string, filter, ignore *actionlint.WebhookEventFilter) {
+	if filter.IsEmpty() && ignore.IsEmpty() {
+		return
+	}
+	if value == "" {
+		c.unknown("the payload has no %s to check the %s filter", field, filterName(filter, ignore))
+		return
+	}
+	if !filter.IsEmpty() {
+		ok, err := matchFilter(filterValues(filter), value)
+		switch {
+		case err != nil:
+			c.unknown("the %s filter cannot be evaluated: %s", filter.Name.Value, err)
+		case ok:
+			c.passed("%s %q matches the %s filter", what, value, filter.Name.Value)
+		default:
+			c.filtered("%s %q does not match the %s filter (%s)", what, value, filter.Name.Value, strings.Join(filterValues(filter), ", "))
+		}
+		return
+	}
+	ok, err := matchFilter(filterValues(ignore), value)
+	switch {
+	case err != nil:
+		c.unknown("the %s filter cannot be evaluated: %s", ignore.Name.Value, err)
+	case ok:
+		c.filtered("%s %q matches the %s filter (%s)", what, value, ignore.Name.Value, strings.Join(filterValues(ignore), ", "))
+	default:
+		c.passed("%s %q does not match the %s filter", what, value, ignore.Name.Value)
+	}
+}
+
+func (c *filterCheck) checkPaths() {
+	paths, ignore := c.hook.Paths, c.hook.PathsIgnore
+	if c.status == TriggerFiltered || (paths.IsEmpty() && ignore.IsEmpty()) {
+		return
+	}
+	files := c.ev.ChangedFiles
+	if files == nil && strings.EqualFold(c.ev.Name, "push") {
+		files = pushedFiles(c.ev.Payload)
+	}
+	if files == nil {
+		c.unknown("the %s filter needs the changed files; pass them as changed_files", filterName(paths, ignore))
+		return
+	}
+
+	if !paths.IsEmpty() {
+		for _, f := range files {
+			ok, err := matchFilter(filterValues(paths), f)
+			if err != nil {
+				c.unknown("the paths filter cannot be evaluated: %s", err)
+				return
+			}
+			if ok {
+				c.passed("changed file %q matches the paths filter", f)
+				return
+			}
+		}
+		c.filtered("none of the %d changed files match the paths filter (%s)", len(files), strings.Join(filterValues(paths), ", "))
+		return
+	}
+	for _, f := range files {
+		ok, err := matchFilter(filterValues(ignore), f)
+		if err != nil {
+			c.unknown("the paths-ignore filter cannot be evaluated: %s", err)
+			return
+		}
+		if !ok {
+			c.passed("changed file %q does not match the paths-ignore filter", f)
+			return
+		}
+	}
+	c.filtered("all %d changed files match the paths-ignore filter (%s)", len(files), strings.Join(filterValues(ignore), ", "))
+}
+
+func (c *filterCheck) checkWorkflows() {
+	if len(c.hook.Workflows) == 0 {
+		return
+	}
+	names := make([]string, 0, len(c.hook.Workflows))
+	for _, w := range c.hook.Workflows {
+		names = append(names, w.Value)
+	}
+	name := payloadString(c.ev.Payload, "workflow_run", "name")
+	switch {
+	case name == "":
+		c.unknown("the payload has no workflow_run.name to check the workflows filter")
+	case !slices.Contains(names, name):
+		c.filtered("workflow %q is not in the workflows filter (%s)", name, strings.Join(names, ", "))
+	default:
+		c.passed("workflow %q is in the workflows filter", name)
+	}
+}
+
+// simulateJobs reports which jobs run for the event, following needs.
+func simulateJobs(w *actionlint.Workflow, event string) []JobTrigger {
+	ids := make([]string, 0, len(w.Jobs))
+	for id := range w.Jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	done := map[string]*JobTrigger{}
+	var resolve func(id string, seen map[string]bool) *JobTrigger
+	resolve = func(id string, seen map[string]bool) *JobTrigger {
+		if r, ok := done[id]; ok {
+			return r
+		}
+		job, ok := w.Jobs[id]
+		if !ok || seen[id] {
+			return &JobTrigger{ID: id, Runs: true} // Reported by actionlint
+		}
+		seen[id] = true
+		defer delete(seen, id)
+
+		r := &JobTrigger{ID: job.ID.Value, Runs: true}
+		if !rules.ConditionAllowsEvent(job.If, event) {
+			r.Runs, r.Reason = false, fmt.Sprintf("its if: condition %q excludes the %s event", job.If.Value, event)
+		} else if !rules.ConditionRunsAfterSkips(job.If) {
+			for _, need := range job.Needs {
+				if n := resolve(strings.ToLower(need.Value), seen); !n.Runs {
+					r.Runs, r.Reason = false, fmt.Sprintf("it needs job %q, which does not run", need.Value)
+					break
+				}
+			}
+		}
+		done[id] = r
+		return r
+	}
+
+	out := make([]JobTrigger, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, *resolve(id, map[string]bool{}))
+	}
+	return out
+}
+
+func filterValues(f *actionlint.WebhookEventFilter) []string {
+	out := make([]string, 0, len(f.Values))
+	for _, v := range f.Values {
+		out = append(out, v.Value)
+	}
+	return out
+}
+
+func filterName(filter, ignore *actionlint.WebhookEventFilter) string {
+	if !filter.IsEmpty() {
+		return filter.Name.Value
+	}
+	return ignore.Name.Value
+}
+
+// pushedFiles returns the files added, modified or removed by the commits
+// of a push payload, or nil when it lists no commits.
+func pushedFiles(payload map[string]any) []string {
+	commits, ok := payload["commits"].([]any)
+	if !ok {
+		return nil
+	}
+	seen := map[string]bool{}
+	files := []string{}
+	for _, c := range commits {
+		commit, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, key := range []string{"added", "modified", "removed"} {
+			list, _ := commit[key].([]any)
+			for _, f := range list {
+				if s, ok := f.(string); ok && !seen[s] {
+					seen[s] = true
+					files = append(files, s)
+				}
+			}
+		}
+	}
+	return files
+}
+
+// payloadString returns the string at the path of keys in payload, or ""
+// when there is none.
+func payloadString(payload map[string]any, keys ...string) string {
+	var v any = payload
+	for _, k := range keys {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return ""
+		}
+		v = m[k]
+	}
+	s, _ := v.(string)
+	return s
+}
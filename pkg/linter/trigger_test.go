@@ -0,0 +1,184 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchFilter(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		value    string
+		want     bool
+	}{
+		{[]string{"main"}, "main", true},
+		{[]string{"main"}, "main2", false},
+		{[]string{"releases/*"}, "releases/v1", true},
+		{[]string{"releases/*"}, "releases/v1/fix", false},
+		{[]string{"releases/**"}, "releases/v1/fix", true},
+		{[]string{"**.md"}, "docs/guide/intro.md", true},
+		{[]string{"v[12].*"}, "v2.0", true},
+		{[]string{"v[12].*"}, "v3.0", false},
+		{[]string{"releases/**", "!releases/**-alpha"}, "releases/v1-alpha", false},
+		{[]string{"releases/**", "!releases/**-alpha", "releases/v1-alpha"}, "releases/v1-alpha", true},
+		{[]string{`feature\*`}, "feature*", true},
+		{[]string{`feature\*`}, "feature1", false},
+	}
+	for _, tt := range tests {
+		got, err := matchFilter(tt.patterns, tt.value)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "%v matching %q", tt.patterns, tt.value)
+	}
+
+	_, err := matchFilter([]string{"[abc"}, "a")
+	assert.ErrorContains(t, err, "unclosed [")
+}
+
+func writeWorkflow(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestSimulateTrigger_Push(t *testing.T) {
+	dir := t.TempDir()
+	ci := writeWorkflow(t, dir, "ci.yml", `name: CI
+on:
+  push:
+    branches: [main]
+    paths-ignore: ['**.md']
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: go test ./...
+  deploy:
+    if: github.event_name == 'pull_request'
+    runs-on: ubuntu-latest
+    steps:
+      - run: ./deploy.sh
+  notify:
+    needs: deploy
+    runs-on: ubuntu-latest
+    steps:
+      - run: ./notify.sh
+`)
+	release := writeWorkflow(t, dir, "release.yml", `on:
+  push:
+    tags: ['v*']
+jobs:
+  release:
+    runs-on: ubuntu-latest
+    steps:
+      - run: ./release.sh
+`)
+	pr := writeWorkflow(t, dir, "pr.yml", `on: pull_request
+jobs:
+  check:
+    runs-on: ubuntu-latest
+    steps:
+      - run: ./check.sh
+`)
+	payload := map[string]any{
+		"ref":     "refs/heads/main",
+		"commits": []any{map[string]any{"modified": []any{"main.go", "README.md"}}},
+	}
+
+	results, err := SimulateTrigger([]string{ci, release, pr}, TriggerEvent{Name: "push", Payload: payload})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, TriggerRuns, results[0].Status)
+	assert.Equal(t, "CI", results[0].Name)
+	assert.Contains(t, results[0].Reasons, `branch "main" matches the branches filter`)
+	assert.Contains(t, results[0].Reasons, `changed file "main.go" does not match the paths-ignore filter`)
+	assert.Equal(t, []JobTrigger{
+		{ID: "deploy", Runs: false, Reason: `its if: condition "github.event_name == 'pull_request'" excludes the push event`},
+		{ID: "notify", Runs: false, Reason: `it needs job "deploy", which does not run`},
+		{ID: "test", Runs: true},
+	}, results[0].Jobs)
+
+	assert.Equal(t, TriggerFiltered, results[1].Status)
+	assert.Contains(t, results[1].Reasons, `branch "main" was pushed, but the workflow only filters tags, so branch pushes never trigger it`)
+	assert.Empty(t, results[1].Jobs)
+
+	assert.Equal(t, TriggerNotSubscribed, results[2].Status)
+	assert.Equal(t, []string{"the workflow does not listen to push (it listens to pull_request)"}, results[2].Reasons)
+
+	// Only ignored files changed
+	results, err = SimulateTrigger([]string{ci}, TriggerEvent{Name: "push", Payload: payload, ChangedFiles: []string{"README.md"}})
+	require.NoError(t, err)
+	assert.Equal(t, TriggerFiltered, results[0].Status)
+	assert.Contains(t, results[0].Reasons, "all 1 changed files match the paths-ignore filter (**.md)")
+
+	// Tag pushes
+	results, err = SimulateTrigger([]string{ci, release}, TriggerEvent{Name: "push", Payload: map[string]any{"ref": "refs/tags/v1.2.0"}})
+	require.NoError(t, err)
+	assert.Equal(t, TriggerFiltered, results[0].Status)
+	assert.Equal(t, TriggerRuns, results[1].Status)
+	assert.Contains(t, results[1].Reasons, `tag "v1.2.0" matches the tags filter`)
+}
+
+func TestSimulateTrigger_PullRequest(t *testing.T) {
+	dir := t.TempDir()
+	path := writeWorkflow(t, dir, "pr.yml", `on:
+  pull_request:
+    branches: [main]
+    paths: ['src/**']
+jobs:
+  check:
+    runs-on: ubuntu-latest
+    steps:
+      - run: ./check.sh
+`)
+	payload := func(action, base string) map[string]any {
+		return map[string]any{"action": action, "pull_request": map[string]any{"base": map[string]any{"ref": base}}}
+	}
+
+	results, err := SimulateTrigger([]string{path}, TriggerEvent{Name: "pull_request", Payload: payload("opened", "main"), ChangedFiles: []string{"src/a.go"}})
+	require.NoError(t, err)
+	assert.Equal(t, TriggerRuns, results[0].Status)
+	assert.Equal(t, []JobTrigger{{ID: "check", Runs: true}}, results[0].Jobs)
+
+	results, err = SimulateTrigger([]string{path}, TriggerEvent{Name: "pull_request", Payload: payload("labeled", "main"), ChangedFiles: []string{"src/a.go"}})
+	require.NoError(t, err)
+	assert.Equal(t, TriggerFiltered, results[0].Status)
+	assert.Contains(t, results[0].Reasons, `activity type "labeled" is not in the default types (opened, synchronize, reopened)`)
+
+	results, err = SimulateTrigger([]string{path}, TriggerEvent{Name: "pull_request", Payload: payload("opened", "develop"), ChangedFiles: []string{"src/a.go"}})
+	require.NoError(t, err)
+	assert.Equal(t, TriggerFiltered, results[0].Status)
+	assert.Contains(t, results[0].Reasons, `base branch "develop" does not match the branches filter (main)`)
+
+	// Without changed files the paths filter cannot be decided
+	results, err = SimulateTrigger([]string{path}, TriggerEvent{Name: "pull_request", Payload: payload("opened", "main")})
+	require.NoError(t, err)
+	assert.Equal(t, TriggerUnknown, results[0].Status)
+	assert.Contains(t, results[0].Reasons, "the paths filter needs the changed files; pass them as changed_files")
+	assert.NotEmpty(t, results[0].Jobs)
+}
+
+func TestSimulateTrigger_WorkflowRun(t *testing.T) {
+	path := writeWorkflow(t, t.TempDir(), "after.yml", `on:
+  workflow_run:
+    workflows: [CI]
+    types: [completed]
+jobs:
+  report:
+    runs-on: ubuntu-latest
+    steps:
+      - run: ./report.sh
+`)
+	results, err := SimulateTrigger([]string{path}, TriggerEvent{Name: "workflow_run", Payload: map[string]any{
+		"action":       "completed",
+		"workflow_run": map[string]any{"name": "Release"},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, TriggerFiltered, results[0].Status)
+	assert.Contains(t, results[0].Reasons, `workflow "Release" is not in the workflows filter (CI)`)
+}
@@ -0,0 +1,98 @@
+package linter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// wasmRuntime runs the WebAssembly builds of external linters. It is
+// shared by every lint, so that a module is only compiled once, and closes
+// a run's module when its context is done, which is how timeouts and
+// output caps stop it.
+var wasmRuntime = sync.OnceValue(func() wazero.Runtime {
+	ctx := context.Background()
+	rt := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCloseOnContextDone(true))
+	wasi_snapshot_preview1.MustInstantiate(ctx, rt)
+	return rt
+})
+
+var (
+	wasmModulesMu sync.Mutex
+	wasmModules   = map[string]*wasmModule{}
+)
+
+// wasmModule is a WASI build of an external linter, compiled for
+// wasmRuntime.
+type wasmModule struct {
+	compiled wazero.CompiledModule
+	err      error
+}
+
+// loadWASM returns the module at path, or the embedded shellcheck when
+// path is empty, compiling it on first use. It returns nil without an error
+// when path is empty and no module is embedded.
+func loadWASM(path string) (*wasmModule, error) {
+	if path == "" && embeddedShellcheck == nil {
+		return nil, nil
+	}
+	wasmModulesMu.Lock()
+	defer wasmModulesMu.Unlock()
+	if m := wasmModules[path]; m != nil {
+		return m, m.err
+	}
+
+	m := &wasmModule{}
+	code := embeddedShellcheck
+	if path != "" {
+		code, m.err = os.ReadFile(path)
+	}
+	if m.err == nil {
+		m.compiled, m.err = wasmRuntime().CompileModule(context.Background(), code)
+	}
+	wasmModules[path] = m
+	return m, m.err
+}
+
+// run runs the module with args like a command, as name. Like exec.Cmd it
+// returns an error for a non-zero exit status, a *sys.ExitError.
+func (m *wasmModule) run(ctx context.Context, name string, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	cfg := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(append([]string{name}, args...)...).
+		WithStdin(stdin).
+		WithStdout(stdout).
+		WithStderr(stderr)
+	mod, err := wasmRuntime().InstantiateModule(ctx, m.compiled, cfg)
+	if mod != nil {
+		mod.Close(context.Background())
+	}
+	return err
+}
+
+// exited reports whether a run that failed with err exited on its own with
+// a non-zero status, rather than failing to start or being stopped.
+func exited(err error) bool {
+	switch e := err.(type) {
+	case *exec.ExitError:
+		return e.ExitCode() >= 0
+	case *sys.ExitError:
+		return e.ExitCode() != sys.ExitCodeContextCanceled && e.ExitCode() != sys.ExitCodeDeadlineExceeded
+	}
+	return false
+}
+
+// wasmError describes why the shellcheck module at path could not be used.
+func wasmError(path string, err error) string {
+	if path == "" {
+		return fmt.Sprintf("the embedded shellcheck module could not be loaded: %v", err)
+	}
+	return fmt.Sprintf("shellcheck module %q could not be loaded: %v", path, err)
+}
@@ -0,0 +1,90 @@
+package linter
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeShellcheckWASM stands in for shellcheck: it reports a finding for
+// scripts that echo, and never finishes for scripts that sleep.
+const fakeShellcheckWASM = `package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func main() {
+	in, _ := io.ReadAll(os.Stdin)
+	if strings.Contains(string(in), "sleep") {
+		for {
+		}
+	}
+	if len(os.Args) < 7 || os.Args[6] != "bash" {
+		fmt.Fprintln(os.Stderr, "unexpected arguments", os.Args)
+		os.Exit(2)
+	}
+	fmt.Println(` + "`" + `[{"line":2,"column":6,"level":"info","code":2086,"message":"Double quote to prevent globbing and word splitting."}]` + "`" + `)
+	os.Exit(1)
+}
+`
+
+// buildWASM compiles src for WASI with the go command running the tests.
+func buildWASM(t *testing.T, src string) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("compiles a WebAssembly module")
+	}
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module fake\n\ngo 1.21\n"), 0644))
+	out := filepath.Join(dir, "shellcheck.wasm")
+	cmd := exec.Command(goCmd, "build", "-o", out, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm", "GOFLAGS=")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("cannot build for wasip1: %v: %s", err, output)
+	}
+	return out
+}
+
+func TestLint_ShellcheckWASM(t *testing.T) {
+	module := buildWASM(t, fakeShellcheckWASM)
+
+	l := New(Options{Shellcheck: "definitely-not-a-shellcheck-binary", ShellcheckWASM: module, ExternalTimeout: 2 * time.Second})
+	result, err := l.Lint(context.Background(), Input{Content: []byte(scriptWorkflow)})
+	require.NoError(t, err)
+	require.Len(t, result.Errors, 1, "%v", result.Errors)
+	assert.Equal(t, "shellcheck", result.Errors[0].Kind)
+	assert.Equal(t, "shellcheck reported issue in this script: SC2086:info:1:6: Double quote to prevent globbing and word splitting", result.Errors[0].Message)
+	assert.True(t, result.Meta.Shellcheck)
+	assert.True(t, result.Meta.ShellcheckWASM)
+	assert.Contains(t, result.Meta.ExternalLinterError, `shellcheck command "definitely-not-a-shellcheck-binary" was not found`)
+
+	hung := "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: sleep 100\n"
+	result, err = New(Options{ShellcheckWASM: module, ExternalTimeout: 200 * time.Millisecond}).Lint(context.Background(), Input{Content: []byte(hung)})
+	require.NoError(t, err)
+	require.Len(t, result.Errors, 1, "%v", result.Errors)
+	assert.Equal(t, KindExternalLinter, result.Errors[0].Kind)
+	assert.Contains(t, result.Errors[0].Message, "external linter shellcheck timed out after 200ms")
+	assert.Empty(t, result.Meta.ExternalLinterError)
+
+	result, err = New(Options{ShellcheckWASM: filepath.Join(t.TempDir(), "missing.wasm")}).Lint(context.Background(), Input{Content: []byte(scriptWorkflow)})
+	require.NoError(t, err)
+	assert.Empty(t, result.Errors)
+	assert.False(t, result.Meta.Shellcheck)
+	assert.Contains(t, result.Meta.ExternalLinterError, "missing.wasm\" could not be loaded")
+}
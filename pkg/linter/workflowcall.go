@@ -0,0 +1,506 @@
+package linter
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CallInput is an input of a generated workflow_call trigger. Value is
+// what the caller passes for it.
+type CallInput struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Required    bool   `json:"required"`
+	Default     string `json:"default,omitempty"`
+	Value       string `json:"value"`
+}
+
+// CallSecret is a secret of a generated workflow_call trigger.
+type CallSecret struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CallOutput is an output of a generated workflow_call trigger.
+type CallOutput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Value       string `json:"value"`
+}
+
+// WorkflowCall is a job turned into a reusable workflow: the inputs,
+// secrets and outputs of its workflow_call trigger, the trigger itself,
+// the whole reusable workflow, and the job that calls it in place of the
+// original one.
+type WorkflowCall struct {
+	Job      string       `json:"job"`
+	Inputs   []CallInput  `json:"inputs"`
+	Secrets  []CallSecret `json:"secrets"`
+	Outputs  []CallOutput `json:"outputs"`
+	Trigger  string       `json:"trigger"`
+	Workflow string       `json:"workflow"`
+	Caller   string       `json:"caller"`
+}
+
+var (
+	// callExpression matches the ${{ }} expressions in a value.
+	callExpression = regexp.MustCompile(`\$\{\{(.*?)\}\}`)
+	// callLiteral matches string literals in an expression.
+	callLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	// callReference matches the references a reusable workflow cannot
+	// resolve by itself: inputs of the workflow, secrets, workflow-level
+	// environment variables and the outputs and results of needed jobs.
+	callReference = regexp.MustCompile(`\b(github\.event\.inputs|inputs|env|secrets)\.([A-Za-z_][\w-]*)|\bneeds\.([A-Za-z_][\w-]*)\.(?:outputs\.([A-Za-z_][\w-]*)|(result))`)
+	// shellVariable matches the variables a script expands.
+	shellVariable = regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*)`)
+)
+
+// callInference collects what a job references while it is walked.
+type callInference struct {
+	root, job *yaml.Node
+	jobID     string
+	local     map[string]bool
+	inputs    []CallInput
+	names     map[string]string
+	secrets   []CallSecret
+	jobEnv    []string
+	rewrites  map[string]string
+}
+
+// InferWorkflowCall infers the workflow_call trigger that a reusable
+// workflow made of job needs from the expressions the job references: the
+// inputs of the workflow and the workflow-level environment variables,
+// which a called workflow does not see, the secrets, and the outputs and
+// results of the jobs it needs. Input types and defaults are taken from
+// the workflow_dispatch or workflow_call inputs and the environment
+// values they replace.
+func InferWorkflowCall(content []byte, jobID string) (*WorkflowCall, error) {
+	content, err := normalizeEncoding(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode content: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse workflow: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("workflow is empty")
+	}
+	root := doc.Content[0]
+	job := mappingValue(mappingValue(root, "jobs"), jobID)
+	if job == nil || job.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("job %q not found", jobID)
+	}
+	if mappingValue(job, "uses") != nil {
+		return nil, fmt.Errorf("job %q already calls a reusable workflow", jobID)
+	}
+
+	inf := &callInference{
+		root:     root,
+		job:      job,
+		jobID:    jobID,
+		local:    map[string]bool{},
+		names:    map[string]string{},
+		rewrites: map[string]string{},
+	}
+	for _, k := range mappingKeys(mappingValue(job, "env")) {
+		inf.local[k] = true
+	}
+	if steps := mappingValue(job, "steps"); steps != nil {
+		for _, s := range steps.Content {
+			for _, k := range mappingKeys(mappingValue(s, "env")) {
+				inf.local[k] = true
+			}
+		}
+	}
+	for i := 0; i+1 < len(job.Content); i += 2 {
+		if k := job.Content[i].Value; k != "needs" && k != "if" {
+			inf.walk(job.Content[i+1], k) // needs: and if: stay with the caller
+		}
+	}
+
+	call := &WorkflowCall{Job: jobID, Inputs: inf.inputs, Secrets: inf.secrets, Outputs: []CallOutput{}}
+	if call.Inputs == nil {
+		call.Inputs = []CallInput{}
+	}
+	if call.Secrets == nil {
+		call.Secrets = []CallSecret{}
+	}
+	outputs := mappingValue(job, "outputs")
+	for _, name := range mappingKeys(outputs) {
+		call.Outputs = append(call.Outputs, CallOutput{
+			Name:        name,
+			Description: fmt.Sprintf("Output %s of job %s", name, jobID),
+			Value:       fmt.Sprintf("${{ jobs.%s.outputs.%s }}", jobID, name),
+		})
+	}
+
+	trigger := triggerNode(call)
+	if call.Trigger, err = encodeYAML(mapping("on", trigger)); err != nil {
+		return nil, err
+	}
+	if call.Workflow, err = encodeYAML(inf.workflow(trigger)); err != nil {
+		return nil, err
+	}
+	if call.Caller, err = encodeYAML(inf.caller(call)); err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
+// walk collects the references of the scalars under n. key is the key n
+// is the value of, since if: conditions are expressions without ${{ }}.
+func (inf *callInference) walk(n *yaml.Node, key string) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			inf.walk(n.Content[i+1], n.Content[i].Value)
+		}
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			inf.walk(c, "")
+		}
+	case yaml.ScalarNode:
+		var exprs []string
+		if key == "if" && !strings.Contains(n.Value, "${{") {
+			exprs = []string{n.Value}
+		}
+		for _, m := range callExpression.FindAllStringSubmatch(n.Value, -1) {
+			exprs = append(exprs, m[1])
+		}
+		for _, e := range exprs {
+			for _, m := range callReference.FindAllStringSubmatch(callLiteral.ReplaceAllString(e, ""), -1) {
+				inf.reference(m)
+			}
+		}
+		if key == "run" {
+			for _, m := range shellVariable.FindAllStringSubmatch(n.Value, -1) {
+				if v := mappingValue(mappingValue(inf.root, "env"), m[1]); v != nil && !inf.local[m[1]] {
+					inf.env(m[1], v)
+				}
+			}
+		}
+	}
+}
+
+// reference records one match of callReference.
+func (inf *callInference) reference(m []string) {
+	switch m[1] {
+	case "github.event.inputs", "inputs":
+		name := m[2]
+		ref := m[1] + "." + name
+		if m[1] == "github.event.inputs" {
+			inf.rewrites[ref] = "inputs." + name
+		}
+		if _, ok := inf.names["inputs."+name]; ok {
+			return
+		}
+		in := CallInput{Name: name, Type: "string", Required: true, Value: "${{ inputs." + name + " }}"}
+		def := inputDefinition(inf.root, name)
+		if d := scalarValue(mappingValue(def, "description")); d != "" {
+			in.Description = d
+		} else {
+			in.Description = "Inferred from " + ref
+		}
+		switch t := scalarValue(mappingValue(def, "type")); t {
+		case "boolean", "number":
+			in.Type = t
+		}
+		if d := mappingValue(def, "default"); d != nil {
+			in.Default = d.Value
+			in.Required = false
+		} else if def != nil {
+			in.Required = scalarValue(mappingValue(def, "required")) == "true"
+		}
+		inf.add("inputs."+name, in)
+	case "env":
+		if v := mappingValue(mappingValue(inf.root, "env"), m[2]); v != nil && !inf.local[m[2]] {
+			inf.env(m[2], v)
+		}
+	case "secrets":
+		if m[2] == "GITHUB_TOKEN" {
+			return
+		}
+		for _, s := range inf.secrets {
+			if s.Name == m[2] {
+				return
+			}
+		}
+		inf.secrets = append(inf.secrets, CallSecret{Name: m[2], Value: "${{ secrets." + m[2] + " }}"})
+	default:
+		needed, output := m[3], m[4]
+		ref := "needs." + needed + ".outputs." + output
+		name := strings.ToLower(output)
+		what := fmt.Sprintf("output %s of job %s", output, needed)
+		if m[5] != "" {
+			ref = "needs." + needed + ".result"
+			name = strings.ToLower(needed) + "-result"
+			what = "result of job " + needed
+		}
+		if _, ok := inf.names[ref]; ok {
+			return
+		}
+		in := CallInput{Name: name, Type: "string", Description: "The " + what, Required: true, Value: "${{ " + ref + " }}"}
+		in.Name = inf.add(ref, in)
+		inf.rewrites[ref] = "inputs." + in.Name
+	}
+}
+
+// env records the workflow-level environment variable name, which the
+// reusable job sets from an input of the same name. The input's type is
+// that of the YAML value, so a quoted number stays a string.
+func (inf *callInference) env(name string, value *yaml.Node) {
+	key := "env." + name
+	if _, ok := inf.names[key]; ok {
+		return
+	}
+	in := CallInput{
+		Name:        strings.ToLower(name),
+		Type:        "string",
+		Description: "Value of the " + name + " environment variable",
+		Required:    true,
+		Value:       "${{ env." + name + " }}",
+	}
+	if !strings.Contains(value.Value, "${{") {
+		in.Default = value.Value
+		in.Required = false
+		switch value.Tag {
+		case "!!bool":
+			in.Type = "boolean"
+		case "!!int", "!!float":
+			in.Type = "number"
+		}
+	}
+	in.Name = inf.add(key, in)
+	inf.jobEnv = append(inf.jobEnv, name)
+}
+
+// add appends in, renamed when another input already has its name, and
+// returns the name it got.
+func (inf *callInference) add(ref string, in CallInput) string {
+	taken := func(n string) bool {
+		for _, other := range inf.inputs {
+			if strings.EqualFold(other.Name, n) {
+				return true
+			}
+		}
+		return false
+	}
+	base := in.Name
+	for i := 2; taken(in.Name); i++ {
+		in.Name = fmt.Sprintf("%s-%d", base, i)
+	}
+	inf.names[ref] = in.Name
+	inf.inputs = append(inf.inputs, in)
+	return in.Name
+}
+
+// inputDefinition returns the workflow_dispatch or workflow_call input
+// name of the workflow.
+func inputDefinition(root *yaml.Node, name string) *yaml.Node {
+	on := mappingValue(root, "on")
+	for _, event := range []string{"workflow_dispatch", "workflow_call"} {
+		inputs := mappingValue(mappingValue(on, event), "inputs")
+		for _, k := range mappingKeys(inputs) {
+			if strings.EqualFold(k, name) {
+				return mappingValue(inputs, k)
+			}
+		}
+	}
+	return nil
+}
+
+// workflow returns the reusable workflow: the job without its needs: and
+// if:, which stay with the caller, reading what it referenced from inputs.
+func (inf *callInference) workflow(trigger *yaml.Node) *yaml.Node {
+	job := copyNode(inf.job)
+	var content []*yaml.Node
+	for i := 0; i+1 < len(job.Content); i += 2 {
+		if k := job.Content[i].Value; k != "needs" && k != "if" {
+			content = append(content, job.Content[i], job.Content[i+1])
+		}
+	}
+	job.Content = content
+	var rewrites []rewrite
+	for from, to := range inf.rewrites {
+		rewrites = append(rewrites, rewrite{regexp.MustCompile(`\b` + regexp.QuoteMeta(from) + `\b`), to})
+	}
+	rewriteReferences(job, "", rewrites)
+
+	if len(inf.jobEnv) > 0 {
+		env := mappingValue(job, "env")
+		if env == nil {
+			env = &yaml.Node{Kind: yaml.MappingNode}
+			job.Content = append(job.Content, scalar("env"), env)
+		}
+		for _, name := range inf.jobEnv {
+			env.Content = append(env.Content, scalar(name), scalar("${{ inputs."+inf.names["env."+name]+" }}"))
+		}
+	}
+
+	w := &yaml.Node{Kind: yaml.MappingNode}
+	if name := mappingValue(inf.root, "name"); name != nil {
+		w.Content = append(w.Content, scalar("name"), copyNode(name))
+	}
+	w.Content = append(w.Content, scalar("on"), trigger)
+	if perms := mappingValue(inf.root, "permissions"); perms != nil && mappingValue(job, "permissions") == nil {
+		w.Content = append(w.Content, scalar("permissions"), copyNode(perms))
+	}
+	w.Content = append(w.Content, scalar("jobs"), mapping(inf.jobID, job))
+	return w
+}
+
+// rewrite replaces a reference with the input taking its place.
+type rewrite struct {
+	from *regexp.Regexp
+	to   string
+}
+
+// rewriteReferences applies rewrites to the expressions under n.
+func rewriteReferences(n *yaml.Node, key string, rewrites []rewrite) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			rewriteReferences(n.Content[i+1], n.Content[i].Value, rewrites)
+		}
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			rewriteReferences(c, "", rewrites)
+		}
+	case yaml.ScalarNode:
+		replace := func(e string) string {
+			for _, r := range rewrites {
+				e = r.from.ReplaceAllString(e, r.to)
+			}
+			return e
+		}
+		if key == "if" && !strings.Contains(n.Value, "${{") {
+			n.Value = replace(n.Value)
+			return
+		}
+		n.Value = callExpression.ReplaceAllStringFunc(n.Value, replace)
+	}
+}
+
+// caller returns the job calling the reusable workflow in place of the
+// original one, which keeps its needs:, if: and permissions:.
+func (inf *callInference) caller(call *WorkflowCall) *yaml.Node {
+	job := &yaml.Node{Kind: yaml.MappingNode}
+	for _, k := range []string{"name", "needs", "if", "permissions"} {
+		if v := mappingValue(inf.job, k); v != nil {
+			job.Content = append(job.Content, scalar(k), copyNode(v))
+		}
+	}
+	job.Content = append(job.Content, scalar("uses"), scalar("./"+WorkflowsDir+"/"+inf.jobID+".yml"))
+	if len(call.Inputs) > 0 {
+		with := &yaml.Node{Kind: yaml.MappingNode}
+		for _, in := range call.Inputs {
+			with.Content = append(with.Content, scalar(in.Name), scalar(in.Value))
+		}
+		job.Content = append(job.Content, scalar("with"), with)
+	}
+	if len(call.Secrets) > 0 {
+		secrets := &yaml.Node{Kind: yaml.MappingNode}
+		for _, s := range call.Secrets {
+			secrets.Content = append(secrets.Content, scalar(s.Name), scalar(s.Value))
+		}
+		job.Content = append(job.Content, scalar("secrets"), secrets)
+	}
+	return mapping(inf.jobID, job)
+}
+
+// triggerNode renders the workflow_call trigger of call.
+func triggerNode(call *WorkflowCall) *yaml.Node {
+	wc := &yaml.Node{Kind: yaml.MappingNode}
+	if len(call.Inputs) > 0 {
+		inputs := &yaml.Node{Kind: yaml.MappingNode}
+		for _, in := range call.Inputs {
+			def := mapping("description", scalar(in.Description), "type", scalar(in.Type), "required", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(in.Required)})
+			if in.Default != "" || !in.Required {
+				tag := "!!str"
+				switch in.Type {
+				case "boolean":
+					tag = "!!bool"
+				case "number":
+					tag = "!!float"
+					if _, err := strconv.Atoi(in.Default); err == nil {
+						tag = "!!int"
+					}
+				}
+				def.Content = append(def.Content, scalar("default"), &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: in.Default})
+			}
+			inputs.Content = append(inputs.Content, scalar(in.Name), def)
+		}
+		wc.Content = append(wc.Content, scalar("inputs"), inputs)
+	}
+	if len(call.Secrets) > 0 {
+		secrets := &yaml.Node{Kind: yaml.MappingNode}
+		for _, s := range call.Secrets {
+			secrets.Content = append(secrets.Content, scalar(s.Name), mapping("required", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"}))
+		}
+		wc.Content = append(wc.Content, scalar("secrets"), secrets)
+	}
+	if len(call.Outputs) > 0 {
+		outputs := &yaml.Node{Kind: yaml.MappingNode}
+		for _, o := range call.Outputs {
+			outputs.Content = append(outputs.Content, scalar(o.Name), mapping("description", scalar(o.Description), "value", scalar(o.Value)))
+		}
+		wc.Content = append(wc.Content, scalar("outputs"), outputs)
+	}
+	if len(wc.Content) == 0 {
+		return mapping("workflow_call", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: ""})
+	}
+	return mapping("workflow_call", wc)
+}
+
+func scalar(v string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+}
+
+// mapping returns a mapping of alternating keys and value nodes.
+func mapping(kv ...any) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i+1 < len(kv); i += 2 {
+		n.Content = append(n.Content, scalar(kv[i].(string)), kv[i+1].(*yaml.Node))
+	}
+	return n
+}
+
+func mappingKeys(n *yaml.Node) []string {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	keys := make([]string, 0, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		keys = append(keys, n.Content[i].Value)
+	}
+	return keys
+}
+
+func copyNode(n *yaml.Node) *yaml.Node {
+	c := *n
+	c.Content = make([]*yaml.Node, len(n.Content))
+	for i, child := range n.Content {
+		c.Content[i] = copyNode(child)
+	}
+	return &c
+}
+
+func encodeYAML(n *yaml.Node) (string, error) {
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(n); err != nil {
+		return "", fmt.Errorf("failed to render YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("failed to render YAML: %w", err)
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,101 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const reusableSource = `name: Release
+on:
+  workflow_dispatch:
+    inputs:
+      dry-run:
+        description: Only print what would be published
+        type: boolean
+        default: false
+      channel:
+        type: choice
+        options: [stable, beta]
+        required: true
+env:
+  NODE_VERSION: "20"
+  RETRIES: 3
+  REGISTRY: ${{ vars.REGISTRY }}
+  UNUSED: x
+permissions:
+  contents: read
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    outputs:
+      version: ${{ steps.v.outputs.version }}
+    steps:
+      - id: v
+        run: echo "version=1.0.0" >> "$GITHUB_OUTPUT"
+  publish:
+    needs: build
+    if: needs.build.result == 'success' && !github.event.inputs.dry-run
+    runs-on: ubuntu-latest
+    outputs:
+      url: ${{ steps.publish.outputs.url }}
+    env:
+      LOCAL: yes
+    steps:
+      - if: github.event.inputs.dry-run != 'true'
+        run: echo publishing
+      - uses: actions/setup-node@v4
+        with:
+          node-version: ${{ env.NODE_VERSION }}
+      - id: publish
+        run: npm publish --tag ${{ inputs.channel }} --registry "$REGISTRY" $LOCAL || sleep ${RETRIES}
+        env:
+          NODE_AUTH_TOKEN: ${{ secrets.NPM_TOKEN }}
+          VERSION: ${{ needs.build.outputs.version }}
+          GH_TOKEN: ${{ secrets.GITHUB_TOKEN }}
+`
+
+func TestInferWorkflowCall(t *testing.T) {
+	call, err := InferWorkflowCall([]byte(reusableSource), "publish")
+	require.NoError(t, err)
+
+	assert.Equal(t, []CallInput{
+		{Name: "dry-run", Type: "boolean", Description: "Only print what would be published", Default: "false", Value: "${{ inputs.dry-run }}"},
+		{Name: "node_version", Type: "string", Description: "Value of the NODE_VERSION environment variable", Default: "20", Value: "${{ env.NODE_VERSION }}"},
+		{Name: "channel", Type: "string", Description: "Inferred from inputs.channel", Required: true, Value: "${{ inputs.channel }}"},
+		{Name: "registry", Type: "string", Description: "Value of the REGISTRY environment variable", Required: true, Value: "${{ env.REGISTRY }}"},
+		{Name: "retries", Type: "number", Description: "Value of the RETRIES environment variable", Default: "3", Value: "${{ env.RETRIES }}"},
+		{Name: "version", Type: "string", Description: "The output version of job build", Required: true, Value: "${{ needs.build.outputs.version }}"},
+	}, call.Inputs)
+	assert.Equal(t, []CallSecret{{Name: "NPM_TOKEN", Value: "${{ secrets.NPM_TOKEN }}"}}, call.Secrets)
+	assert.Equal(t, []CallOutput{{Name: "url", Description: "Output url of job publish", Value: "${{ jobs.publish.outputs.url }}"}}, call.Outputs)
+
+	assert.Contains(t, call.Trigger, "on:\n  workflow_call:\n    inputs:\n      dry-run:\n")
+	assert.Contains(t, call.Trigger, "      dry-run:\n        description: Only print what would be published\n        type: boolean\n        required: false\n        default: false\n")
+	assert.Contains(t, call.Trigger, "    secrets:\n      NPM_TOKEN:\n        required: true\n")
+
+	assert.Contains(t, call.Caller, "publish:\n  needs: build\n  if: needs.build.result == 'success' && !github.event.inputs.dry-run\n  uses:")
+	assert.Contains(t, call.Caller, "  uses: ./.github/workflows/publish.yml\n  with:\n")
+	assert.Contains(t, call.Workflow, "permissions:\n  contents: read\n")
+	assert.Contains(t, call.Caller, "    version: ${{ needs.build.outputs.version }}\n")
+
+	// The reusable workflow reads everything from its inputs and is valid
+	assert.NotContains(t, call.Workflow, "needs")
+	assert.Contains(t, call.Workflow, "- if: inputs.dry-run != 'true'")
+	assert.Contains(t, call.Trigger, "        default: 3\n")
+	assert.Contains(t, call.Workflow, "VERSION: ${{ inputs.version }}")
+	assert.Contains(t, call.Workflow, "NODE_VERSION: ${{ inputs.node_version }}")
+	assert.Contains(t, call.Workflow, "REGISTRY: ${{ inputs.registry }}")
+	_, errs := actionlint.Parse([]byte(call.Workflow))
+	assert.Empty(t, errs)
+}
+
+func TestInferWorkflowCall_Errors(t *testing.T) {
+	_, err := InferWorkflowCall([]byte(reusableSource), "missing")
+	assert.ErrorContains(t, err, `job "missing" not found`)
+
+	_, err = InferWorkflowCall([]byte("on: push\njobs:\n  call:\n    uses: ./.github/workflows/x.yml\n"), "call")
+	assert.ErrorContains(t, err, "already calls a reusable workflow")
+}
@@ -0,0 +1,93 @@
+package linter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteChanges writes the changes of an extraction or fix, creating the
+// directories of new files and keeping the permissions of existing ones.
+// The files it replaces are first copied to a new directory under the
+// temporary directory, and the copies set as the Backup of their change,
+// so that RestoreChanges can roll the write back. When a change cannot be
+// written, the changes written before it are rolled back.
+func WriteChanges(changes []FileChange) error {
+	var backups string
+	for i := range changes {
+		c := &changes[i]
+		perm := os.FileMode(0644)
+		if c.Mode != 0 {
+			perm = c.Mode
+		}
+		if info, err := os.Stat(c.Path); err == nil {
+			perm = info.Mode().Perm()
+			if backups == "" {
+				if backups, err = os.MkdirTemp("", "actionlint-mcp-backup-"); err != nil {
+					return errors.Join(fmt.Errorf("failed to back up %s: %w", c.Path, err), RestoreChanges(changes[:i]))
+				}
+			}
+			if err := backup(c, filepath.Join(backups, fmt.Sprintf("%d-%s", i+1, filepath.Base(c.Path))), perm); err != nil {
+				return errors.Join(err, RestoreChanges(changes[:i]))
+			}
+		} else if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+			return errors.Join(err, RestoreChanges(changes[:i]))
+		}
+		if err := os.WriteFile(c.Path, c.Content, perm); err != nil {
+			return errors.Join(fmt.Errorf("failed to write %s: %w", c.Path, err), RestoreChanges(changes[:i+1]))
+		}
+	}
+	return nil
+}
+
+// backup copies the file of c to path and records it as the backup of c.
+func backup(c *FileChange, path string, perm os.FileMode) error {
+	original, err := os.ReadFile(c.Path)
+	if err != nil {
+		return fmt.Errorf("failed to back up %s: %w", c.Path, err)
+	}
+	if err := os.WriteFile(path, original, perm); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", c.Path, err)
+	}
+	c.Backup = path
+	return nil
+}
+
+// RestoreChanges rolls back changes written by WriteChanges, in reverse
+// order: files with a backup get their previous content back, and the
+// files the changes created are removed. Directories created for them are
+// left in place.
+func RestoreChanges(changes []FileChange) error {
+	var errs []error
+	for i := len(changes) - 1; i >= 0; i-- {
+		c := changes[i]
+		if c.Backup == "" {
+			if err := os.Remove(c.Path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("failed to remove %s: %w", c.Path, err))
+			}
+			continue
+		}
+		if err := restore(c); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s from %s: %w", c.Path, c.Backup, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// restore copies the backup of c back to its file, with the permissions
+// the file had.
+func restore(c FileChange) error {
+	info, err := os.Stat(c.Backup)
+	if err != nil {
+		return err
+	}
+	original, err := os.ReadFile(c.Backup)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.Path, original, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chmod(c.Path, info.Mode().Perm())
+}
@@ -0,0 +1,67 @@
+package linter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteChanges(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	root := t.TempDir()
+	workflow := filepath.Join(root, ".github", "workflows", "ci.yml")
+	script := filepath.Join(root, "scripts", "build.sh")
+	require.NoError(t, os.MkdirAll(filepath.Dir(workflow), 0755))
+	require.NoError(t, os.WriteFile(workflow, []byte("on: push\r\n"), 0600))
+
+	changes := []FileChange{
+		{Path: script, Created: true, Content: []byte("make\n"), Mode: 0755},
+		{Path: workflow, Content: []byte("on: pull_request\r\n")},
+	}
+	require.NoError(t, WriteChanges(changes))
+
+	info, err := os.Stat(script)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	assert.Empty(t, changes[0].Backup, "new files have no backup")
+
+	info, err = os.Stat(workflow)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "permissions are kept")
+	require.NotEmpty(t, changes[1].Backup)
+	backup, err := os.ReadFile(changes[1].Backup)
+	require.NoError(t, err)
+	assert.Equal(t, "on: push\r\n", string(backup))
+
+	require.NoError(t, RestoreChanges(changes))
+	content, err := os.ReadFile(workflow)
+	require.NoError(t, err)
+	assert.Equal(t, "on: push\r\n", string(content))
+	info, err = os.Stat(workflow)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.NoFileExists(t, script)
+}
+
+func TestWriteChanges_RollsBack(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	root := t.TempDir()
+	workflow := filepath.Join(root, "ci.yml")
+	require.NoError(t, os.WriteFile(workflow, []byte("on: push\n"), 0644))
+	blocker := filepath.Join(root, "scripts")
+	require.NoError(t, os.WriteFile(blocker, nil, 0644))
+
+	changes := []FileChange{
+		{Path: workflow, Content: []byte("on: pull_request\n")},
+		{Path: filepath.Join(blocker, "build.sh"), Created: true, Content: []byte("make\n")},
+	}
+	err := WriteChanges(changes)
+	require.Error(t, err)
+
+	content, err := os.ReadFile(workflow)
+	require.NoError(t, err)
+	assert.Equal(t, "on: push\n", string(content), "the changes written before the failure are rolled back")
+}
@@ -0,0 +1,280 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindActionMetadata is the name of RuleActionMetadata.
+const KindActionMetadata = "action-metadata"
+
+// ActionsConfig configures the metadata of actions actionlint does not
+// know, such as the private actions of an organization or a GitHub
+// Enterprise Server, for the action-metadata rule.
+type ActionsConfig struct {
+	// Metadata maps actions, as owner/repo@ref, owner/repo/path@ref, or
+	// either without a ref for every version, to their metadata, written
+	// as in action.yml. Only name, inputs and outputs are used.
+	Metadata map[string]*actionlint.ActionMetadata `yaml:"metadata"`
+	// File is a JSON file in the format of actionlint's popular actions
+	// dataset, relative to the repository root. Metadata overrides it.
+	File string `yaml:"file"`
+}
+
+// Validate reports actions that are not remote actions.
+func (c ActionsConfig) Validate() error {
+	for spec, meta := range c.Metadata {
+		if err := validateActionSpec(spec); err != nil {
+			return fmt.Errorf("actions: %w", err)
+		}
+		if meta == nil {
+			return fmt.Errorf("actions: %s has no metadata", spec)
+		}
+	}
+	return nil
+}
+
+// validateActionSpec reports specs that do not name a remote action.
+func validateActionSpec(spec string) error {
+	name, ref, hasRef := strings.Cut(spec, "@")
+	owner, repo, _ := strings.Cut(name, "/")
+	if owner == "" || repo == "" || strings.HasPrefix(name, ".") || strings.Contains(name, ":") || hasRef && ref == "" {
+		return fmt.Errorf("%q is not an action; use owner/repo@ref, owner/repo/path@ref, or either without a ref", spec)
+	}
+	return nil
+}
+
+// ActionsMetadata maps actions, by their lower-case spec with or without a
+// ref, to their metadata, whose inputs and outputs are keyed by lower-case
+// name.
+type ActionsMetadata map[string]*actionlint.ActionMetadata
+
+// Lookup returns the metadata of the action spec, as written in uses:, or
+// nil. Metadata for the ref spec names is preferred to metadata for every
+// version.
+func (m ActionsMetadata) Lookup(spec string) *actionlint.ActionMetadata {
+	spec = strings.ToLower(spec)
+	if meta, ok := m[spec]; ok {
+		return meta
+	}
+	name, _, _ := strings.Cut(spec, "@")
+	return m[name]
+}
+
+// add adds the metadata of spec to m, with its inputs and outputs keyed
+// by lower-case name.
+func (m ActionsMetadata) add(spec string, meta *actionlint.ActionMetadata) {
+	normalized := &actionlint.ActionMetadata{
+		Name:        meta.Name,
+		Inputs:      make(actionlint.ActionMetadataInputs, len(meta.Inputs)),
+		Outputs:     make(actionlint.ActionMetadataOutputs, len(meta.Outputs)),
+		SkipInputs:  meta.SkipInputs,
+		SkipOutputs: meta.SkipOutputs,
+	}
+	for id, in := range meta.Inputs {
+		input := actionlint.ActionMetadataInput{Name: id}
+		if in != nil {
+			input = *in
+			if input.Name == "" {
+				input.Name = id
+			}
+		}
+		normalized.Inputs[strings.ToLower(id)] = &input
+	}
+	for id, out := range meta.Outputs {
+		output := actionlint.ActionMetadataOutput{Name: id}
+		if out != nil && out.Name != "" {
+			output.Name = out.Name
+		}
+		normalized.Outputs[strings.ToLower(id)] = &output
+	}
+	m[strings.ToLower(spec)] = normalized
+}
+
+// LoadActionsMetadata returns the metadata of cfg. root is the repository
+// root that cfg.File is relative to.
+func LoadActionsMetadata(cfg ActionsConfig, root string) (ActionsMetadata, error) {
+	out := ActionsMetadata{}
+	if cfg.File != "" {
+		file := cfg.File
+		if !filepath.IsAbs(file) && root != "" {
+			file = filepath.Join(root, file)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return out, fmt.Errorf("actions metadata file %s cannot be read: %w", file, err)
+		}
+		var metadata map[string]*actionlint.ActionMetadata
+		if err := json.Unmarshal(data, &metadata); err != nil {
+			return out, fmt.Errorf("actions metadata file %s: %w", file, err)
+		}
+		for spec, meta := range metadata {
+			if err := validateActionSpec(spec); err != nil {
+				return out, fmt.Errorf("actions metadata file %s: %w", file, err)
+			}
+			if meta != nil {
+				out.add(spec, meta)
+			}
+		}
+	}
+	for spec, meta := range cfg.Metadata {
+		if meta != nil {
+			out.add(spec, meta)
+		}
+	}
+	return out, nil
+}
+
+// RuleActionMetadata checks the steps using actions given metadata in the
+// configuration the way actionlint checks popular actions: inputs the
+// action does not define, required inputs that are not passed, and reads
+// of outputs it does not set. Actions actionlint knows are left to it.
+type RuleActionMetadata struct {
+	actionlint.RuleBase
+	metadata ActionsMetadata
+	loadErr  error
+}
+
+// NewActionMetadata creates a RuleActionMetadata. root is the repository
+// root that cfg.File is relative to.
+func NewActionMetadata(cfg ActionsConfig, root string) *RuleActionMetadata {
+	metadata, err := LoadActionsMetadata(cfg, root)
+	return &RuleActionMetadata{
+		RuleBase: actionlint.NewRuleBase(KindActionMetadata, "Checks inputs and outputs of actions given metadata in the configuration"),
+		metadata: metadata,
+		loadErr:  err,
+	}
+}
+
+// VisitWorkflowPre reports metadata that cannot be loaded.
+func (rule *RuleActionMetadata) VisitWorkflowPre(n *actionlint.Workflow) error {
+	if rule.loadErr != nil {
+		rule.Errorf(&actionlint.Pos{Line: 1, Col: 1}, "%v", rule.loadErr)
+	}
+	return nil
+}
+
+// VisitJobPre checks the inputs of the job's steps and the outputs they
+// read.
+func (rule *RuleActionMetadata) VisitJobPre(n *actionlint.Job) error {
+	if len(rule.metadata) == 0 {
+		return nil
+	}
+	outputs := map[string]*actionlint.ActionMetadata{}
+	for _, s := range n.Steps {
+		rule.checkOutputs(Strings(s), outputs)
+		exec, ok := s.Exec.(*actionlint.ExecAction)
+		if !ok || exec.Uses == nil {
+			continue
+		}
+		meta := rule.lookup(exec.Uses.Value)
+		if meta == nil {
+			continue
+		}
+		if !meta.SkipInputs {
+			rule.checkInputs(meta, exec)
+		}
+		if s.ID != nil && !meta.SkipOutputs {
+			outputs[strings.ToLower(s.ID.Value)] = meta
+		}
+	}
+	for _, o := range n.Outputs {
+		if o.Value != nil {
+			rule.checkOutputs([]*actionlint.String{o.Value}, outputs)
+		}
+	}
+	return nil
+}
+
+// lookup returns the configured metadata of the action spec names, or nil
+// for local, Docker and dynamic actions and the actions actionlint knows.
+func (rule *RuleActionMetadata) lookup(spec string) *actionlint.ActionMetadata {
+	if strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "docker://") || strings.Contains(spec, "${{") {
+		return nil
+	}
+	if _, ok := actionlint.PopularActions[spec]; ok {
+		return nil
+	}
+	return rule.metadata.Lookup(spec)
+}
+
+// checkInputs reports the inputs exec passes that meta does not define,
+// and the required ones it does not pass, with actionlint's messages.
+func (rule *RuleActionMetadata) checkInputs(meta *actionlint.ActionMetadata, exec *actionlint.ExecAction) {
+	spec := strconv.Quote(exec.Uses.Value)
+	ids := make([]string, 0, len(exec.Inputs))
+	for id := range exec.Inputs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if _, ok := meta.Inputs[id]; !ok && exec.Inputs[id].Name != nil {
+			rule.Errorf(exec.Inputs[id].Name.Pos, "input %q is not defined in action %s. available inputs are %s", exec.Inputs[id].Name.Value, spec, inputNames(meta, false))
+		}
+	}
+
+	required := make([]string, 0, len(meta.Inputs))
+	for id, in := range meta.Inputs {
+		if _, ok := exec.Inputs[id]; in.Required && !ok {
+			required = append(required, in.Name)
+		}
+	}
+	sort.Strings(required)
+	for _, name := range required {
+		rule.Errorf(exec.Uses.Pos, "missing input %q which is required by action %s. all required inputs are %s", name, spec, inputNames(meta, true))
+	}
+}
+
+// checkOutputs reports reads in strs of outputs that the actions of the
+// steps in outputs, by ID, do not set.
+func (rule *RuleActionMetadata) checkOutputs(strs []*actionlint.String, outputs map[string]*actionlint.ActionMetadata) {
+	if len(outputs) == 0 {
+		return
+	}
+	for _, s := range strs {
+		for _, loc := range stepsRef.FindAllStringIndex(s.Value, -1) {
+			m := stepOutput.FindStringSubmatch(s.Value[loc[1]:])
+			if m == nil {
+				continue
+			}
+			meta := outputs[strings.ToLower(m[1])]
+			if meta == nil {
+				continue
+			}
+			if _, ok := meta.Outputs[strings.ToLower(m[2])]; !ok {
+				rule.Errorf(s.Pos, "property %q is not defined in object type %s", strings.ToLower(m[2]), outputsType(meta))
+			}
+		}
+	}
+}
+
+// inputNames returns the quoted names of the inputs of meta, or only of
+// the required ones, sorted.
+func inputNames(meta *actionlint.ActionMetadata, required bool) string {
+	names := make([]string, 0, len(meta.Inputs))
+	for _, in := range meta.Inputs {
+		if in.Required || !required {
+			names = append(names, strconv.Quote(in.Name))
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// outputsType formats the outputs of meta as actionlint formats the type
+// of the outputs of a step.
+func outputsType(meta *actionlint.ActionMetadata) string {
+	props := make([]string, 0, len(meta.Outputs))
+	for id := range meta.Outputs {
+		props = append(props, id+": string")
+	}
+	sort.Strings(props)
+	return "{" + strings.Join(props, "; ") + "}"
+}
@@ -0,0 +1,108 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestActionMetadata(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "actions.json"), []byte(`{
+  "acme/deploy@v1": {
+    "name": "Deploy",
+    "inputs": {"environment": {"name": "environment", "required": true}, "region": {"name": "region", "required": false}},
+    "outputs": {"url": {"name": "url"}}
+  }
+}`), 0644))
+
+	var cfg ActionsConfig
+	require.NoError(t, yaml.Unmarshal([]byte(`
+file: actions.json
+metadata:
+  Acme/Build:
+    name: Build
+    inputs:
+      Target:
+        required: true
+      cache:
+        required: true
+        default: true
+    outputs:
+      digest:
+        description: Image digest
+  acme/dynamic@v1:
+    inputs:
+      config:
+        required: true
+`), &cfg))
+	require.NoError(t, cfg.Validate())
+
+	src := `on: push
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    outputs:
+      digest: ${{ steps.build.outputs.image }}
+    steps:
+      - id: build
+        uses: acme/build@main
+        with:
+          target: app
+      - id: deploy
+        uses: acme/deploy@v1
+        with:
+          regoin: eu
+      - run: echo "${{ steps.deploy.outputs.url }} ${{ steps.build.outputs.Digest }} ${{ steps.deploy.outputs.link }}"
+      - uses: acme/deploy@v2
+        with:
+          anything: true
+      - uses: actions/checkout@v4
+      - uses: ./.github/actions/local
+`
+	errs := lintWith(t, func() actionlint.Rule { return NewActionMetadata(cfg, root) }, src)
+	require.Len(t, errs, 4, "acme/deploy@v2 has no metadata")
+
+	assert.Equal(t, KindActionMetadata, errs[0].Kind)
+	assert.Equal(t, 6, errs[0].Line)
+	assert.Contains(t, errs[0].Message, `property "image" is not defined in object type {digest: string}`)
+	assert.Equal(t, 13, errs[1].Line)
+	assert.Contains(t, errs[1].Message, `missing input "environment" which is required by action "acme/deploy@v1". all required inputs are "environment"`)
+	assert.Equal(t, 15, errs[2].Line)
+	assert.Contains(t, errs[2].Message, `input "regoin" is not defined in action "acme/deploy@v1". available inputs are "environment", "region"`)
+	assert.Equal(t, 16, errs[3].Line)
+	assert.Contains(t, errs[3].Message, `property "link" is not defined in object type {url: string}`)
+
+	errs = lintWith(t, func() actionlint.Rule { return NewActionMetadata(cfg, root) }, `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: acme/dynamic@v1
+`)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, `missing input "config"`)
+
+	errs = lintWith(t, func() actionlint.Rule { return NewActionMetadata(ActionsConfig{File: "missing.json"}, root) }, src)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, "missing.json cannot be read")
+
+	assert.Error(t, ActionsConfig{Metadata: map[string]*actionlint.ActionMetadata{"./local": {}}}.Validate())
+	assert.Error(t, ActionsConfig{Metadata: map[string]*actionlint.ActionMetadata{"acme@v1": {}}}.Validate())
+	assert.Error(t, ActionsConfig{Metadata: map[string]*actionlint.ActionMetadata{"acme/deploy@": {}}}.Validate())
+}
+
+func TestActionsMetadata_Lookup(t *testing.T) {
+	m := ActionsMetadata{}
+	m.add("acme/deploy", &actionlint.ActionMetadata{Name: "any"})
+	m.add("Acme/Deploy@V2", &actionlint.ActionMetadata{Name: "v2"})
+
+	assert.Equal(t, "v2", m.Lookup("acme/deploy@v2").Name)
+	assert.Equal(t, "any", m.Lookup("ACME/deploy@v1").Name)
+	assert.Nil(t, m.Lookup("acme/build@v1"))
+}
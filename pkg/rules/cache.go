@@ -0,0 +1,206 @@
+package rules
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindSetupCache is the name of RuleSetupCache.
+const KindSetupCache = "setup-cache"
+
+// lockfileSearchDepth is how many directories below the repository root
+// lockfiles are looked for.
+const lockfileSearchDepth = 3
+
+// lockfile is a file whose presence selects a value of a setup action's
+// cache input.
+type lockfile struct {
+	name  string
+	cache string
+}
+
+// setupAction describes the built-in caching of a setup action.
+type setupAction struct {
+	lockfiles []lockfile
+	// since is the first major version with the cache input, and
+	// cachedBy the first that caches without it, if any.
+	since, cachedBy int
+}
+
+var setupActions = map[string]setupAction{
+	"actions/setup-node": {
+		lockfiles: []lockfile{{"package-lock.json", "npm"}, {"npm-shrinkwrap.json", "npm"}, {"yarn.lock", "yarn"}, {"pnpm-lock.yaml", "pnpm"}},
+		since:     2,
+	},
+	"actions/setup-python": {
+		lockfiles: []lockfile{{"requirements.txt", "pip"}, {"Pipfile.lock", "pipenv"}, {"poetry.lock", "poetry"}},
+		since:     2,
+	},
+	"actions/setup-java": {
+		lockfiles: []lockfile{{"pom.xml", "maven"}, {"build.gradle", "gradle"}, {"build.gradle.kts", "gradle"}, {"build.sbt", "sbt"}},
+		since:     2,
+	},
+	"actions/setup-go": {
+		lockfiles: []lockfile{{"go.sum", "true"}},
+		since:     3,
+		cachedBy:  4,
+	},
+}
+
+// toolchains are package managers without a setup action that caches for
+// them, recognized by the commands run: scripts use.
+var toolchains = []struct {
+	command  *regexp.Regexp
+	lockfile string
+	advice   string
+}{
+	{regexp.MustCompile(`\bcargo +(build|test|check|clippy|run)\b`), "Cargo.lock", "add Swatinem/rust-cache, or actions/cache on ~/.cargo and target,"},
+	{regexp.MustCompile(`\bbundle +install\b`), "Gemfile.lock", `use ruby/setup-ruby with "bundler-cache: true"`},
+	{regexp.MustCompile(`\bcomposer +install\b`), "composer.lock", `add actions/cache on the directory printed by "composer config cache-files-dir"`},
+}
+
+var majorVersion = regexp.MustCompile(`^v?(\d+)`)
+
+// RuleSetupCache flags dependencies downloaded on every run because a
+// setup action's cache input is not set, or because a job installs with a
+// package manager no step caches for. Only lockfiles present in the
+// repository are suggested, so nothing is reported outside one.
+type RuleSetupCache struct {
+	actionlint.RuleBase
+	root  string
+	found map[string]string
+}
+
+// NewSetupCache creates a RuleSetupCache for the repository at root, which
+// may be empty when the workflow is not in a repository.
+func NewSetupCache(root string) *RuleSetupCache {
+	return &RuleSetupCache{
+		RuleBase: actionlint.NewRuleBase(KindSetupCache, "Checks for dependencies that could be cached"),
+		root:     root,
+		found:    map[string]string{},
+	}
+}
+
+// VisitJobPre checks the setup actions and install commands of the job.
+func (rule *RuleSetupCache) VisitJobPre(n *actionlint.Job) error {
+	if rule.root == "" {
+		return nil
+	}
+	caches := false
+	for _, s := range n.Steps {
+		if a, ok := s.Exec.(*actionlint.ExecAction); ok && a.Uses != nil && strings.Contains(strings.ToLower(a.Uses.Value), "cache") {
+			caches = true
+		}
+	}
+
+	reported := map[string]bool{}
+	for _, s := range n.Steps {
+		switch exec := s.Exec.(type) {
+		case *actionlint.ExecAction:
+			rule.checkSetupAction(exec)
+			if exec.Uses != nil && strings.HasPrefix(exec.Uses.Value, "ruby/setup-ruby@") && exec.Inputs["bundler-cache"] != nil {
+				reported["Gemfile.lock"] = true
+			}
+		case *actionlint.ExecRun:
+			if caches || exec.Run == nil {
+				continue
+			}
+			for _, t := range toolchains {
+				if reported[t.lockfile] || !t.command.MatchString(exec.Run.Value) {
+					continue
+				}
+				reported[t.lockfile] = true
+				lock := rule.lockfile(t.lockfile)
+				if lock == "" {
+					continue
+				}
+				rule.Errorf(exec.Run.Pos, "dependencies locked in %s are downloaded on every run. %s to cache them", lock, t.advice)
+			}
+		}
+	}
+	return nil
+}
+
+func (rule *RuleSetupCache) checkSetupAction(exec *actionlint.ExecAction) {
+	if exec.Uses == nil {
+		return
+	}
+	name, ref, ok := strings.Cut(exec.Uses.Value, "@")
+	setup, known := setupActions[strings.ToLower(name)]
+	if !ok || !known || exec.Inputs["cache"] != nil {
+		return
+	}
+	m := majorVersion.FindStringSubmatch(ref)
+	if m == nil {
+		return // Pinned to a commit or branch, so the version is unknown
+	}
+	major, _ := strconv.Atoi(m[1])
+	if major < setup.since || (setup.cachedBy > 0 && major >= setup.cachedBy) {
+		return
+	}
+
+	for _, l := range setup.lockfiles {
+		lock := rule.lockfile(l.name)
+		if lock == "" {
+			continue
+		}
+		suggestion := "\"cache: " + l.cache + "\""
+		if lock != l.name {
+			suggestion += " and \"cache-dependency-path: " + lock + "\""
+		}
+		rule.Errorf(exec.Uses.Pos, "%q has no \"cache\" input, so dependencies locked in %s are downloaded on every run. add %s to cache them", exec.Uses.Value, lock, suggestion)
+		return
+	}
+}
+
+// lockfile returns the slash-separated path of the first file named name
+// in the repository, looking at shallower directories first, or "" when
+// there is none.
+func (rule *RuleSetupCache) lockfile(name string) string {
+	if found, ok := rule.found[name]; ok {
+		return found
+	}
+	found := findFile(rule.root, name, lockfileSearchDepth)
+	rule.found[name] = found
+	return found
+}
+
+// findFile searches root and up to depth levels of directories below it,
+// breadth first, skipping hidden and build directories.
+func findFile(root, name string, depth int) string {
+	dirs := []string{""}
+	for level := 0; level <= depth && len(dirs) > 0; level++ {
+		var next []string
+		for _, dir := range dirs {
+			if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(dir), name)); err == nil {
+				return path.Join(dir, name)
+			}
+			entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(dir)))
+			if err != nil {
+				continue
+			}
+			for _, e := range entries {
+				if e.IsDir() && !skipDir(e) {
+					next = append(next, path.Join(dir, e.Name()))
+				}
+			}
+		}
+		dirs = next
+	}
+	return ""
+}
+
+func skipDir(e fs.DirEntry) bool {
+	switch e.Name() {
+	case "node_modules", "vendor", "target", "dist", "build":
+		return true
+	}
+	return strings.HasPrefix(e.Name(), ".")
+}
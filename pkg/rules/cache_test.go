@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cacheWorkflow = `on: push
+jobs:
+  web:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/setup-node@v4
+      - uses: actions/setup-python@v5
+        with:
+          cache: pip
+      - uses: actions/setup-go@v5
+      - uses: actions/setup-go@v3
+      - run: cargo build --release
+      - run: cargo test
+  cached:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: Swatinem/rust-cache@v2
+      - run: cargo build
+`
+
+func TestSetupCache(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "web"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "node_modules", "x"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "node_modules", "x", "package-lock.json"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "web", "yarn.lock"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.sum"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "Cargo.lock"), nil, 0644))
+
+	errs := lintWith(t, func() actionlint.Rule { return NewSetupCache(root) }, cacheWorkflow)
+	require.Len(t, errs, 3)
+	assert.Equal(t, KindSetupCache, errs[0].Kind)
+	assert.Contains(t, errs[0].Message, `"actions/setup-node@v4" has no "cache" input, so dependencies locked in web/yarn.lock are downloaded on every run. add "cache: yarn" and "cache-dependency-path: web/yarn.lock"`)
+	assert.Equal(t, 6, errs[0].Line)
+	assert.Contains(t, errs[1].Message, `"actions/setup-go@v3" has no "cache" input, so dependencies locked in go.sum are downloaded on every run. add "cache: true"`)
+	assert.Contains(t, errs[2].Message, "dependencies locked in Cargo.lock are downloaded on every run. add Swatinem/rust-cache")
+	assert.Equal(t, 12, errs[2].Line)
+}
+
+func TestSetupCache_NoRepository(t *testing.T) {
+	assert.Empty(t, lintWith(t, func() actionlint.Rule { return NewSetupCache("") }, cacheWorkflow))
+
+	// Lockfiles missing from the repository are not suggested
+	assert.Empty(t, lintWith(t, func() actionlint.Rule { return NewSetupCache(t.TempDir()) }, cacheWorkflow))
+}
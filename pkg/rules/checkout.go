@@ -0,0 +1,142 @@
+package rules
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindCheckout is the name of RuleCheckout.
+const KindCheckout = "checkout"
+
+// CheckoutConfig configures the checkout rule.
+type CheckoutConfig struct {
+	// PersistCredentials flags checkouts that leave the job's token in
+	// .git/config although no later step runs git commands needing it. It
+	// is off by default since it applies to most workflows.
+	PersistCredentials bool `yaml:"persist-credentials"`
+}
+
+var (
+	// historyCommand matches commands that need more than the last commit.
+	historyCommand = regexp.MustCompile(`\bgit +(describe|log|rev-list|shortlog|merge-base)\b|\b(git-cliff|conventional-changelog|standard-version|semantic-release|goreleaser|changeset +version)\b`)
+	// historyActions need the history of the checkout.
+	historyActions = []string{"goreleaser/goreleaser-action", "orhun/git-cliff-action", "cycjimmy/semantic-release-action"}
+
+	// credentialCommand matches git commands that authenticate with the
+	// persisted token.
+	credentialCommand = regexp.MustCompile(`\bgit +(push|fetch|pull|submodule|ls-remote)\b`)
+	// credentialActions run git commands with the persisted token.
+	credentialActions = []string{"stefanzweifel/git-auto-commit-action", "ad-m/github-push-action", "EndBug/add-and-commit"}
+)
+
+// checkoutStep is the state of an actions/checkout step seen in a job.
+type checkoutStep struct {
+	uses        *actionlint.String
+	shallow     bool
+	persists    bool
+	credentials bool
+}
+
+// RuleCheckout flags actions/checkout configurations that do not fit the
+// rest of the job: a shallow clone before steps that read the history, a
+// token or SSH key written into the workflow, and, when configured,
+// credentials persisted for steps that never use them.
+type RuleCheckout struct {
+	actionlint.RuleBase
+	cfg CheckoutConfig
+}
+
+// NewCheckout creates a RuleCheckout.
+func NewCheckout(cfg CheckoutConfig) *RuleCheckout {
+	return &RuleCheckout{
+		RuleBase: actionlint.NewRuleBase(KindCheckout, "Checks actions/checkout configurations against the steps that follow"),
+		cfg:      cfg,
+	}
+}
+
+// VisitJobPre checks each checkout of the job against the steps up to the
+// next checkout.
+func (rule *RuleCheckout) VisitJobPre(n *actionlint.Job) error {
+	var current *checkoutStep
+	done := func() {
+		if current != nil && rule.cfg.PersistCredentials && current.persists && !current.credentials {
+			rule.Errorf(current.uses.Pos, "%q keeps the job's token in .git/config for every later step, but none runs git commands that need it. add \"persist-credentials: false\"", current.uses.Value)
+		}
+	}
+
+	for _, s := range n.Steps {
+		switch exec := s.Exec.(type) {
+		case *actionlint.ExecAction:
+			if exec.Uses == nil {
+				continue
+			}
+			name, _, _ := strings.Cut(exec.Uses.Value, "@")
+			if strings.EqualFold(name, "actions/checkout") {
+				done()
+				current = rule.checkout(exec)
+				continue
+			}
+			if current == nil {
+				continue
+			}
+			if current.shallow && containsFold(historyActions, name) {
+				rule.Errorf(exec.Uses.Pos, "%q needs the repository history, but %q at line %d fetches only the last commit. add \"fetch-depth: 0\" to it", exec.Uses.Value, current.uses.Value, current.uses.Pos.Line)
+				current.shallow = false
+			}
+			if containsFold(credentialActions, name) {
+				current.credentials = true
+			}
+		case *actionlint.ExecRun:
+			if current == nil || exec.Run == nil {
+				continue
+			}
+			if current.shallow {
+				if m := historyCommand.FindString(exec.Run.Value); m != "" {
+					rule.Errorf(exec.Run.Pos, "%q needs the repository history, but %q at line %d fetches only the last commit. add \"fetch-depth: 0\" to it", m, current.uses.Value, current.uses.Pos.Line)
+					current.shallow = false
+				}
+			}
+			if credentialCommand.MatchString(exec.Run.Value) {
+				current.credentials = true
+			}
+		}
+	}
+	done()
+	return nil
+}
+
+// checkout reads the inputs of a checkout step and checks its secrets.
+func (rule *RuleCheckout) checkout(exec *actionlint.ExecAction) *checkoutStep {
+	c := &checkoutStep{uses: exec.Uses, shallow: true, persists: true}
+
+	if in := exec.Inputs["fetch-depth"]; in != nil && in.Value != nil {
+		v := strings.TrimSpace(in.Value.Value)
+		if n, err := strconv.Atoi(v); err != nil || n == 0 {
+			c.shallow = false // Full history, or an expression that may be
+		}
+	}
+	if in := exec.Inputs["persist-credentials"]; in != nil && in.Value != nil {
+		c.persists = strings.TrimSpace(in.Value.Value) != "false"
+	}
+
+	for _, key := range []string{"token", "ssh-key"} {
+		in := exec.Inputs[key]
+		if in == nil || in.Value == nil || in.Value.Value == "" || strings.Contains(in.Value.Value, "${{") {
+			continue
+		}
+		rule.Errorf(in.Value.Pos, "%q input of %q is written into the workflow, so anyone who can read the repository can use it. store it as a secret and pass ${{ secrets.NAME }} instead", key, exec.Uses.Value)
+	}
+	return c
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
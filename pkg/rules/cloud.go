@@ -0,0 +1,166 @@
+package rules
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindCloudDeploy is the name of RuleCloudDeploy.
+const KindCloudDeploy = "cloud-deploy"
+
+// CloudDeployConfig configures the cloud-deploy rule.
+type CloudDeployConfig struct {
+	// Enabled turns the rule on. It is off by default.
+	Enabled bool `yaml:"enabled"`
+}
+
+// staticCredential is a way of passing a cloud provider a long-lived
+// credential, with how to authenticate with OIDC instead.
+type staticCredential struct {
+	provider string
+	oidc     string
+}
+
+var (
+	awsOIDC   = "assume a role with OIDC instead, giving aws-actions/configure-aws-credentials role-to-assume and the job id-token: write"
+	gcpOIDC   = "use workload identity federation instead, giving google-github-actions/auth workload_identity_provider and the job id-token: write"
+	azureOIDC = "log in with OIDC instead, giving azure/login client-id, tenant-id and subscription-id and the job id-token: write"
+
+	// staticCredentialEnv are the environment variables the cloud CLIs and
+	// Terraform providers read static credentials from. Only the secret
+	// half of each pair is listed, so a pair is reported once.
+	staticCredentialEnv = map[string]staticCredential{
+		"AWS_SECRET_ACCESS_KEY": {"AWS", awsOIDC},
+		"GOOGLE_CREDENTIALS":    {"Google Cloud", gcpOIDC},
+		"ARM_CLIENT_SECRET":     {"Azure", "set ARM_USE_OIDC: true with ARM_CLIENT_ID, ARM_TENANT_ID and ARM_SUBSCRIPTION_ID instead, and give the job id-token: write"},
+		"AZURE_CLIENT_SECRET":   {"Azure", azureOIDC},
+	}
+	// staticCredentialInputs are the inputs of the login actions that take
+	// static credentials, by action.
+	staticCredentialInputs = map[string]map[string]staticCredential{
+		"aws-actions/configure-aws-credentials": {"aws-secret-access-key": {"AWS", awsOIDC}},
+		"google-github-actions/auth":            {"credentials_json": {"Google Cloud", gcpOIDC}},
+		"azure/login":                           {"creds": {"Azure", azureOIDC}},
+	}
+
+	// awsCommand matches scripts running the AWS CLI.
+	awsCommand = regexp.MustCompile(`(?m)(?:^|[;&|(` + "`" + `]|\b(?:then|do|else|exec)\s+)\s*aws\s+[a-z]`)
+	// awsRegionFlag matches AWS CLI commands given a region.
+	awsRegionFlag = regexp.MustCompile(`\s--region[\s=]`)
+	// infrastructureChange matches commands applying infrastructure
+	// changes.
+	infrastructureChange = regexp.MustCompile(`\b(?:terraform|tofu|terragrunt)\s+(?:run-all\s+)?(?:apply|destroy)\b`)
+)
+
+// RuleCloudDeploy flags cloud deployments that hold credentials longer or
+// more widely than they need, or fail to run: AWS, Google Cloud and Azure
+// credentials passed as long-lived secrets in env: or to the login actions
+// instead of through OIDC, AWS CLI commands without a region, and
+// Terraform applies in jobs without an environment, whose protection rules
+// would gate them. Plans are not checked, since they change nothing.
+type RuleCloudDeploy struct {
+	actionlint.RuleBase
+	cfg CloudDeployConfig
+	env *actionlint.Env
+}
+
+// NewCloudDeploy creates a RuleCloudDeploy.
+func NewCloudDeploy(cfg CloudDeployConfig) *RuleCloudDeploy {
+	return &RuleCloudDeploy{
+		RuleBase: actionlint.NewRuleBase(KindCloudDeploy, "Checks cloud deployments for static credentials, missing regions and unprotected applies"),
+		cfg:      cfg,
+	}
+}
+
+// VisitWorkflowPre checks the workflow's env.
+func (rule *RuleCloudDeploy) VisitWorkflowPre(n *actionlint.Workflow) error {
+	rule.env = n.Env
+	if rule.cfg.Enabled {
+		rule.checkEnv(n.Env)
+	}
+	return nil
+}
+
+// VisitJobPre checks the job's env and steps.
+func (rule *RuleCloudDeploy) VisitJobPre(n *actionlint.Job) error {
+	if !rule.cfg.Enabled {
+		return nil
+	}
+	rule.checkEnv(n.Env)
+
+	region := hasEnv([]*actionlint.Env{n.Env, rule.env}, "AWS_REGION", "AWS_DEFAULT_REGION")
+	for _, s := range n.Steps {
+		rule.checkEnv(s.Env)
+		switch exec := s.Exec.(type) {
+		case *actionlint.ExecAction:
+			if exec.Uses == nil {
+				continue
+			}
+			name, _, _ := strings.Cut(exec.Uses.Value, "@")
+			name = strings.ToLower(name)
+			for input, c := range staticCredentialInputs[name] {
+				if in, ok := exec.Inputs[input]; ok && in.Name != nil {
+					rule.Errorf(in.Name.Pos, "%q passes a long-lived %s credential in %q, which works until it is rotated wherever it leaks. %s", exec.Uses.Value, c.provider, input, c.oidc)
+				}
+			}
+			if name == "aws-actions/configure-aws-credentials" {
+				// The action exports AWS_REGION for the later steps. Versions
+				// actionlint knows already have aws-region checked
+				_, ok := exec.Inputs["aws-region"]
+				if _, known := actionlint.PopularActions[strings.ToLower(exec.Uses.Value)]; !ok && !known {
+					rule.Errorf(exec.Uses.Pos, "%q is not given aws-region, which it needs to get credentials. set aws-region", exec.Uses.Value)
+				}
+				region = true
+			}
+		case *actionlint.ExecRun:
+			if exec.Run == nil {
+				continue
+			}
+			if !region && awsCommand.MatchString(exec.Run.Value) && !awsRegionFlag.MatchString(exec.Run.Value) && !hasEnv([]*actionlint.Env{s.Env}, "AWS_REGION", "AWS_DEFAULT_REGION") {
+				rule.Errorf(exec.Run.Pos, "script runs the AWS CLI without a region, so its commands fail with \"You must specify a region\". set AWS_REGION in env:, pass --region, or set aws-region of aws-actions/configure-aws-credentials in an earlier step")
+			}
+			if m := infrastructureChange.FindString(exec.Run.Value); m != "" && n.Environment == nil {
+				rule.Errorf(exec.Run.Pos, "script runs %q, but job %q has no environment, so nothing reviews or restricts the changes it applies. run it in a job with an environment whose protection rules require a review or limit the deploying branches", strings.Join(strings.Fields(m), " "), n.ID.Value)
+			}
+		}
+	}
+	return nil
+}
+
+// checkEnv reports static cloud credentials set in env.
+func (rule *RuleCloudDeploy) checkEnv(env *actionlint.Env) {
+	if env == nil {
+		return
+	}
+	for _, v := range env.Vars {
+		if v.Name == nil {
+			continue
+		}
+		if c, ok := staticCredentialEnv[strings.ToUpper(v.Name.Value)]; ok {
+			rule.Errorf(v.Name.Pos, "%s passes a long-lived %s credential, which works until it is rotated wherever it leaks. %s", v.Name.Value, c.provider, c.oidc)
+		}
+	}
+}
+
+// hasEnv reports whether any of envs sets one of names, or may set it
+// through an expression.
+func hasEnv(envs []*actionlint.Env, names ...string) bool {
+	for _, e := range envs {
+		if e == nil {
+			continue
+		}
+		if e.Expression != nil {
+			return true
+		}
+		for _, v := range e.Vars {
+			for _, name := range names {
+				if v.Name != nil && strings.EqualFold(v.Name.Value, name) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudDeploy(t *testing.T) {
+	src := `on: push
+env:
+  GOOGLE_CREDENTIALS: ${{ secrets.GCP_KEY }}
+jobs:
+  infra:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: aws-actions/configure-aws-credentials@v4
+        with:
+          aws-access-key-id: ${{ secrets.AWS_ACCESS_KEY_ID }}
+          aws-secret-access-key: ${{ secrets.AWS_SECRET_ACCESS_KEY }}
+          aws-region: eu-west-1
+      - run: aws s3 ls
+      - run: terraform plan
+      - run: terraform apply -auto-approve
+  deploy:
+    runs-on: ubuntu-latest
+    environment: production
+    env:
+      ARM_CLIENT_SECRET: ${{ secrets.ARM_CLIENT_SECRET }}
+    steps:
+      - uses: aws-actions/configure-aws-credentials@0123456789abcdef0123456789abcdef01234567
+        with:
+          role-to-assume: arn:aws:iam::123456789012:role/deploy
+      - run: terraform apply -auto-approve
+  cli:
+    runs-on: ubuntu-latest
+    steps:
+      - run: aws s3 sync dist s3://bucket
+      - run: aws s3 sync dist s3://bucket --region eu-west-1
+      - run: aws sts get-caller-identity
+        env:
+          AWS_REGION: eu-west-1
+`
+	errs := lintWith(t, func() actionlint.Rule { return NewCloudDeploy(CloudDeployConfig{Enabled: true}) }, src)
+	require.Len(t, errs, 6)
+	assert.Equal(t, 3, errs[0].Line)
+	assert.Contains(t, errs[0].Message, "GOOGLE_CREDENTIALS passes a long-lived Google Cloud credential")
+	assert.Equal(t, 11, errs[1].Line)
+	assert.Contains(t, errs[1].Message, `in "aws-secret-access-key"`)
+	assert.Contains(t, errs[1].Message, "role-to-assume")
+	assert.Equal(t, 15, errs[2].Line)
+	assert.Contains(t, errs[2].Message, `script runs "terraform apply", but job "infra" has no environment`)
+	assert.Equal(t, 20, errs[3].Line)
+	assert.Contains(t, errs[3].Message, "ARM_USE_OIDC")
+	assert.Equal(t, 22, errs[4].Line)
+	assert.Contains(t, errs[4].Message, "is not given aws-region")
+	assert.Equal(t, 29, errs[5].Line)
+	assert.Contains(t, errs[5].Message, "AWS CLI without a region")
+
+	assert.Empty(t, lintWith(t, func() actionlint.Rule { return NewCloudDeploy(CloudDeployConfig{}) }, src))
+}
@@ -0,0 +1,183 @@
+package rules
+
+import (
+	"cmp"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindConstantCondition is the name of RuleConstantCondition.
+const KindConstantCondition = "constant-condition"
+
+// RuleConstantCondition flags if: conditions whose value does not depend on
+// anything, such as ${{ 'false' }} (a non-empty string, so always true) or
+// checks combined with "|| true". A bare true or false is left alone since
+// it is the usual way to switch a job or step on or off.
+type RuleConstantCondition struct {
+	actionlint.RuleBase
+	fixes
+}
+
+// NewConstantCondition creates a RuleConstantCondition.
+func NewConstantCondition() *RuleConstantCondition {
+	return &RuleConstantCondition{
+		RuleBase: actionlint.NewRuleBase(KindConstantCondition, "Checks for if: conditions that are always true or always false"),
+	}
+}
+
+// VisitJobPre checks the job's condition.
+func (rule *RuleConstantCondition) VisitJobPre(n *actionlint.Job) error {
+	rule.check(n.If)
+	return nil
+}
+
+// VisitStep checks the step's condition.
+func (rule *RuleConstantCondition) VisitStep(n *actionlint.Step) error {
+	rule.check(n.If)
+	return nil
+}
+
+func (rule *RuleConstantCondition) check(cond *actionlint.String) {
+	expr := parseCondition(cond)
+	if expr == nil {
+		return
+	}
+	if _, ok := expr.(*actionlint.BoolNode); ok {
+		return
+	}
+
+	value, known := constantValue(expr)
+	if !known {
+		return
+	}
+
+	if s, ok := expr.(*actionlint.StringNode); ok {
+		if v := strings.ToLower(s.Value); v == "false" || v == "true" {
+			rule.Errorf(cond.Pos, "if: condition %q is always true because '%s' is a non-empty string. write %s without quotes for a boolean", cond.Value, s.Value, v)
+			rule.addFix(KindConstantCondition, cond.Pos, "Use the boolean "+v+" instead of a string", Edit{Line: cond.Pos.Line, Column: cond.Pos.Col, Old: cond.Value, New: v})
+			return
+		}
+	}
+
+	outcome := "false, so it never runs"
+	if value {
+		outcome = "true"
+	}
+	rule.Errorf(cond.Pos, "if: condition %q is always %s", cond.Value, outcome)
+}
+
+// parseCondition parses an if: condition, which GitHub evaluates as an
+// expression with or without ${{ }}. Conditions with text around ${{ }} are
+// already reported by actionlint and yield nil, as do syntax errors.
+func parseCondition(cond *actionlint.String) actionlint.ExprNode {
+	if cond == nil {
+		return nil
+	}
+
+	src := cond.Value
+	if cond.ContainsExpression() {
+		if !cond.IsExpressionAssigned() {
+			return nil
+		}
+		src = strings.TrimSpace(src)[len("${{"):]
+	} else {
+		src += "}}" // The lexer expects the closing marker
+	}
+
+	expr, err := actionlint.NewExprParser().Parse(actionlint.NewExprLexer(src))
+	if err != nil {
+		return nil
+	}
+	return expr
+}
+
+// constantValue evaluates the truthiness of n when it does not depend on
+// contexts or function calls.
+func constantValue(n actionlint.ExprNode) (value, known bool) {
+	switch n := n.(type) {
+	case *actionlint.BoolNode:
+		return n.Value, true
+	case *actionlint.NullNode:
+		return false, true
+	case *actionlint.IntNode:
+		return n.Value != 0, true
+	case *actionlint.FloatNode:
+		return n.Value != 0, true
+	case *actionlint.StringNode:
+		return n.Value != "", true
+	case *actionlint.NotOpNode:
+		v, ok := constantValue(n.Operand)
+		return !v, ok
+	case *actionlint.LogicalOpNode:
+		l, lok := constantValue(n.Left)
+		r, rok := constantValue(n.Right)
+		if n.Kind == actionlint.LogicalOpNodeKindAnd {
+			if (lok && !l) || (rok && !r) {
+				return false, true
+			}
+			return true, lok && rok
+		}
+		if (lok && l) || (rok && r) {
+			return true, true
+		}
+		return false, lok && rok
+	case *actionlint.CompareOpNode:
+		return compareLiterals(n)
+	default:
+		return false, false
+	}
+}
+
+// compareLiterals evaluates a comparison between two literals of the same
+// type. Strings compare case-insensitively, as in GitHub expressions.
+func compareLiterals(n *actionlint.CompareOpNode) (value, known bool) {
+	var c int
+	switch l := n.Left.(type) {
+	case *actionlint.StringNode:
+		r, ok := n.Right.(*actionlint.StringNode)
+		if !ok {
+			return false, false
+		}
+		c = strings.Compare(strings.ToLower(l.Value), strings.ToLower(r.Value))
+	case *actionlint.IntNode:
+		r, ok := n.Right.(*actionlint.IntNode)
+		if !ok {
+			return false, false
+		}
+		c = cmp.Compare(l.Value, r.Value)
+	case *actionlint.FloatNode:
+		r, ok := n.Right.(*actionlint.FloatNode)
+		if !ok {
+			return false, false
+		}
+		c = cmp.Compare(l.Value, r.Value)
+	case *actionlint.BoolNode:
+		r, ok := n.Right.(*actionlint.BoolNode)
+		if !ok || !n.Kind.IsEqualityOp() {
+			return false, false
+		}
+		if l.Value != r.Value {
+			c = 1
+		}
+	default:
+		return false, false
+	}
+
+	switch n.Kind {
+	case actionlint.CompareOpNodeKindEq:
+		return c == 0, true
+	case actionlint.CompareOpNodeKindNotEq:
+		return c != 0, true
+	case actionlint.CompareOpNodeKindLess:
+		return c < 0, true
+	case actionlint.CompareOpNodeKindLessEq:
+		return c <= 0, true
+	case actionlint.CompareOpNodeKindGreater:
+		return c > 0, true
+	case actionlint.CompareOpNodeKindGreaterEq:
+		return c >= 0, true
+	default:
+		return false, false
+	}
+}
@@ -0,0 +1,52 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstantCondition(t *testing.T) {
+	newRule := func() actionlint.Rule { return NewConstantCondition() }
+
+	tests := []struct {
+		cond string
+		want string
+	}{
+		{cond: `${{ github.ref == 'refs/heads/main' }}`},
+		{cond: `github.event_name == 'push'`},
+		{cond: `false`},
+		{cond: `${{ true }}`},
+		{cond: `${{ always() }}`},
+		{cond: `"${{ 'false' }}"`, want: `is always true because 'false' is a non-empty string. write false without quotes`},
+		{cond: `github.ref == 'refs/heads/main' || true`, want: "is always true"},
+		{cond: `${{ success() && false }}`, want: "is always false, so it never runs"},
+		{cond: `${{ 'Main' == 'main' }}`, want: "is always true"},
+		{cond: `${{ 1 > 2 }}`, want: "is always false"},
+		{cond: `${{ !null }}`, want: "is always true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cond, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n        if: " + tt.cond + "\n"
+			errs := lintWith(t, newRule, src)
+			if tt.want == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			require.Len(t, errs, 1)
+			assert.Equal(t, KindConstantCondition, errs[0].Kind)
+			assert.Contains(t, errs[0].Message, tt.want)
+			assert.Equal(t, 7, errs[0].Line)
+		})
+	}
+}
+
+func TestConstantCondition_Job(t *testing.T) {
+	src := "on: push\njobs:\n  test:\n    if: \"${{ 'true' }}\"\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n"
+	errs := lintWith(t, func() actionlint.Rule { return NewConstantCondition() }, src)
+	require.Len(t, errs, 1)
+	assert.Equal(t, 4, errs[0].Line)
+}
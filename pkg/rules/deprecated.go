@@ -0,0 +1,240 @@
+package rules
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rhysd/actionlint"
+)
+
+//go:generate go run ./internal/gendeprecated -o deprecated-inputs.txt
+
+// KindDeprecatedInput is the name of RuleDeprecatedInput.
+const KindDeprecatedInput = "deprecated-input"
+
+// DeprecatedInputsConfig configures the dataset of deprecated and renamed
+// inputs of actions, which the deprecated-input rule uses.
+type DeprecatedInputsConfig struct {
+	// Actions add to and override the embedded dataset, mapping an action,
+	// as owner/repo or owner/repo/path, to its deprecated inputs. An input
+	// mapped to an empty hint and replacement is no longer flagged.
+	Actions map[string]map[string]DeprecatedInput `yaml:"actions"`
+	// File is a dataset in the embedded format, relative to the repository
+	// root, which adds to and overrides the embedded one. Actions override
+	// it in turn.
+	File string `yaml:"file"`
+}
+
+// DeprecatedInput is a deprecated input of an action: the input replacing
+// it, if any, the major version it is deprecated from, as v2, or empty for
+// every version, and how to migrate.
+type DeprecatedInput struct {
+	Replacement string `yaml:"replacement" json:"replacement,omitempty"`
+	Since       string `yaml:"since" json:"since,omitempty"`
+	Hint        string `yaml:"hint" json:"hint,omitempty"`
+}
+
+// Validate reports versions that are not a major version tag, and inputs
+// with a version but neither a replacement nor a hint.
+func (c DeprecatedInputsConfig) Validate() error {
+	for action, inputs := range c.Actions {
+		for input, d := range inputs {
+			if d.Since != "" && d.Replacement == "" && d.Hint == "" {
+				return fmt.Errorf("deprecated-inputs: %s: %q needs a replacement or a hint", action, input)
+			}
+			if d.Since != "" && !sinceVersion.MatchString(d.Since) {
+				return fmt.Errorf("deprecated-inputs: %s: since of %q must be a major version such as v2, not %q", action, input, d.Since)
+			}
+		}
+	}
+	return nil
+}
+
+// DeprecatedInputs maps actions, by their lower-case name without a ref,
+// to their deprecated inputs by lower-case name.
+type DeprecatedInputs map[string]map[string]DeprecatedInput
+
+// sinceVersion matches the major version tags deprecations apply from.
+var sinceVersion = regexp.MustCompile(`^v\d+$`)
+
+//go:embed deprecated-inputs.txt
+var deprecatedInputsData string
+
+var (
+	deprecatedInputsOnce sync.Once
+	deprecatedInputs     DeprecatedInputs
+)
+
+// parseDeprecatedInputs adds the dataset in data to into.
+func parseDeprecatedInputs(data string, into DeprecatedInputs) error {
+	for i, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) < 4 {
+			return fmt.Errorf("line %d: want an action, an input, a replacement or -, a version or - and a hint", i+1)
+		}
+		d := DeprecatedInput{Hint: strings.Join(fields[4:], " ")}
+		if fields[2] != "-" {
+			d.Replacement = fields[2]
+		}
+		if fields[3] != "-" {
+			if !sinceVersion.MatchString(fields[3]) {
+				return fmt.Errorf("line %d: %q is not a major version such as v2", i+1, fields[3])
+			}
+			d.Since = fields[3]
+		}
+		if d.Replacement == "" && d.Hint == "" {
+			return fmt.Errorf("line %d: an input without a replacement needs a hint", i+1)
+		}
+		action := strings.ToLower(fields[0])
+		if into[action] == nil {
+			into[action] = map[string]DeprecatedInput{}
+		}
+		into[action][strings.ToLower(fields[1])] = d
+	}
+	return nil
+}
+
+// embeddedDeprecatedInputs parses the embedded dataset once.
+func embeddedDeprecatedInputs() DeprecatedInputs {
+	deprecatedInputsOnce.Do(func() {
+		deprecatedInputs = DeprecatedInputs{}
+		if err := parseDeprecatedInputs(deprecatedInputsData, deprecatedInputs); err != nil {
+			panic("deprecated-inputs.txt: " + err.Error())
+		}
+	})
+	return deprecatedInputs
+}
+
+// LoadDeprecatedInputs returns the embedded dataset with the changes of
+// cfg. root is the repository root that cfg.File is relative to.
+func LoadDeprecatedInputs(cfg DeprecatedInputsConfig, root string) (DeprecatedInputs, error) {
+	out := DeprecatedInputs{}
+	for action, inputs := range embeddedDeprecatedInputs() {
+		out[action] = map[string]DeprecatedInput{}
+		for name, d := range inputs {
+			out[action][name] = d
+		}
+	}
+	if cfg.File != "" {
+		file := cfg.File
+		if !filepath.IsAbs(file) && root != "" {
+			file = filepath.Join(root, file)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return out, fmt.Errorf("deprecated inputs file %s cannot be read: %w", file, err)
+		}
+		if err := parseDeprecatedInputs(string(data), out); err != nil {
+			return out, fmt.Errorf("deprecated inputs file %s: %w", file, err)
+		}
+	}
+	for action, inputs := range cfg.Actions {
+		action = strings.ToLower(action)
+		if out[action] == nil {
+			out[action] = map[string]DeprecatedInput{}
+		}
+		for name, d := range inputs {
+			if d == (DeprecatedInput{}) {
+				delete(out[action], strings.ToLower(name))
+				continue
+			}
+			out[action][strings.ToLower(name)] = d
+		}
+	}
+	return out, nil
+}
+
+// RuleDeprecatedInput flags inputs of actions that are deprecated, renamed
+// or removed, according to a dataset of popular actions, with how to
+// migrate. actionlint only knows the inputs each version of an action
+// takes, so it misses inputs that are deprecated but still accepted, and
+// reports removed ones without saying what replaces them. Renamed inputs
+// get a fix renaming them.
+type RuleDeprecatedInput struct {
+	actionlint.RuleBase
+	fixes
+	inputs  DeprecatedInputs
+	loadErr error
+}
+
+// NewDeprecatedInput creates a RuleDeprecatedInput. root is the repository
+// root that cfg.File is relative to.
+func NewDeprecatedInput(cfg DeprecatedInputsConfig, root string) *RuleDeprecatedInput {
+	inputs, err := LoadDeprecatedInputs(cfg, root)
+	return &RuleDeprecatedInput{
+		RuleBase: actionlint.NewRuleBase(KindDeprecatedInput, "Checks for deprecated and renamed inputs of popular actions"),
+		inputs:   inputs,
+		loadErr:  err,
+	}
+}
+
+// VisitWorkflowPre reports a dataset that cannot be loaded.
+func (rule *RuleDeprecatedInput) VisitWorkflowPre(n *actionlint.Workflow) error {
+	if rule.loadErr != nil {
+		rule.Errorf(&actionlint.Pos{Line: 1, Col: 1}, "%v", rule.loadErr)
+	}
+	return nil
+}
+
+// VisitStep checks the inputs the step passes to its action.
+func (rule *RuleDeprecatedInput) VisitStep(n *actionlint.Step) error {
+	exec, ok := n.Exec.(*actionlint.ExecAction)
+	if !ok || exec.Uses == nil {
+		return nil
+	}
+	name, ref, _ := strings.Cut(strings.ToLower(exec.Uses.Value), "@")
+	deprecated := rule.inputs[name]
+	if len(deprecated) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(exec.Inputs))
+	for input := range exec.Inputs {
+		names = append(names, input)
+	}
+	sort.Strings(names)
+	for _, input := range names {
+		d, ok := deprecated[input]
+		in := exec.Inputs[input]
+		if !ok || in.Name == nil || !appliesTo(d, ref) {
+			continue
+		}
+		hint := d.Hint
+		if hint == "" {
+			hint = fmt.Sprintf("use %q instead", d.Replacement)
+		}
+		rule.Errorf(in.Name.Pos, "input %q of %q is deprecated. %s", in.Name.Value, exec.Uses.Value, hint)
+
+		if d.Replacement != "" && exec.Inputs[strings.ToLower(d.Replacement)] == nil {
+			rule.addFix(KindDeprecatedInput, in.Name.Pos, "Rename the input to "+d.Replacement,
+				Edit{Line: in.Name.Pos.Line, Column: in.Name.Pos.Col, Old: in.Name.Value, New: d.Replacement})
+		}
+	}
+	return nil
+}
+
+// appliesTo reports whether d applies to the version of an action ref
+// names. Refs that are not a version tag, such as commits and branches,
+// are taken to be the latest version.
+func appliesTo(d DeprecatedInput, ref string) bool {
+	if d.Since == "" {
+		return true
+	}
+	m := majorVersion.FindStringSubmatch(ref)
+	if m == nil || !strings.HasPrefix(ref, "v") {
+		return true
+	}
+	major, _ := strconv.Atoi(m[1])
+	since, _ := strconv.Atoi(d.Since[1:])
+	return major >= since
+}
@@ -0,0 +1,112 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeprecatedInput(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/setup-python@v5
+        with:
+          version: "3.12"
+      - uses: codecov/codecov-action@v4
+        with:
+          file: coverage.txt
+      - uses: codecov/codecov-action@v5
+        with:
+          File: coverage.txt
+          files: other.txt
+      - uses: actions/cache@v4
+        with:
+          path: ~/.cache
+          key: cache
+          save-always: true
+      - uses: docker/build-push-action@0565240e2d4ab88bba5387d719585280857ece09
+        with:
+          path: .
+`
+	rule := NewDeprecatedInput(DeprecatedInputsConfig{}, "")
+	errs := lintWith(t, func() actionlint.Rule { return rule }, src)
+	require.Len(t, errs, 4, "codecov-action v4 still takes file")
+
+	assert.Equal(t, 8, errs[0].Line)
+	assert.Equal(t, KindDeprecatedInput, errs[0].Kind)
+	assert.Contains(t, errs[0].Message, `input "version" of "actions/setup-python@v5" is deprecated. use "python-version" instead`)
+	assert.Contains(t, errs[1].Message, `input "File" of "codecov/codecov-action@v5" is deprecated. use files`)
+	assert.Contains(t, errs[2].Message, "save the cache with a separate actions/cache/save step")
+	assert.Equal(t, 23, errs[3].Line, "commits are taken to be the latest version")
+
+	// Inputs whose replacement is already passed are not renamed
+	fixes := rule.Fixes()
+	require.Len(t, fixes, 2)
+	assert.Equal(t, []Edit{{Line: 8, Column: 11, Old: "version", New: "python-version"}}, fixes[0].Edits)
+	assert.Equal(t, []Edit{{Line: 23, Column: 11, Old: "path", New: "context"}}, fixes[1].Edits)
+}
+
+func TestDeprecatedInput_Config(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "deprecated.txt"), []byte("# Ours\nacme/deploy env environment v2\n"), 0644))
+
+	src := `on: push
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: acme/deploy@v1
+        with:
+          env: production
+      - uses: acme/deploy@v2
+        with:
+          env: production
+          region: eu
+      - uses: actions/setup-node@v4
+        with:
+          version: 20
+`
+	cfg := DeprecatedInputsConfig{
+		File: "deprecated.txt",
+		Actions: map[string]map[string]DeprecatedInput{
+			"Acme/Deploy":        {"region": {Hint: "regions are read from the environment"}},
+			"actions/setup-node": {"version": {}},
+		},
+	}
+	errs := lintWith(t, func() actionlint.Rule { return NewDeprecatedInput(cfg, root) }, src)
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Message, `input "env" of "acme/deploy@v2" is deprecated. use "environment" instead`)
+	assert.Contains(t, errs[1].Message, `input "region" of "acme/deploy@v2" is deprecated. regions are read from the environment`)
+
+	errs = lintWith(t, func() actionlint.Rule { return NewDeprecatedInput(DeprecatedInputsConfig{File: "missing.txt"}, root) }, src)
+	require.NotEmpty(t, errs)
+	assert.Contains(t, errs[0].Message, "missing.txt cannot be read")
+
+	assert.Error(t, DeprecatedInputsConfig{Actions: map[string]map[string]DeprecatedInput{"acme/deploy": {"env": {Replacement: "environment", Since: "2"}}}}.Validate())
+	assert.Error(t, DeprecatedInputsConfig{Actions: map[string]map[string]DeprecatedInput{"acme/deploy": {"env": {Since: "v2"}}}}.Validate())
+}
+
+func TestParseDeprecatedInputs(t *testing.T) {
+	assert.NotEmpty(t, embeddedDeprecatedInputs()["actions/setup-python"])
+
+	for data, msg := range map[string]string{
+		"acme/deploy env\n":                   "line 1: want an action",
+		"acme/deploy env environment 2\n":     `"2" is not a major version`,
+		"\nacme/deploy env - v2\n":            "line 2: an input without a replacement needs a hint",
+		"acme/deploy env environment v2 ok\n": "",
+	} {
+		err := parseDeprecatedInputs(data, DeprecatedInputs{})
+		if msg == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorContains(t, err, msg)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindEgress is the name of RuleEgress.
+const KindEgress = "egress-hardening"
+
+// hardenRunner is the action monitoring and restricting the network egress
+// of a job.
+const hardenRunner = "step-security/harden-runner"
+
+// EgressConfig configures the egress-hardening rule.
+type EgressConfig struct {
+	// Enabled turns the rule on. It is off by default.
+	Enabled bool `yaml:"enabled"`
+	// Actions are regular expressions matching the uses: of further steps
+	// that harden a job's egress, in addition to harden-runner.
+	Actions []string `yaml:"actions"`
+	// Uses is the action the suggested step uses, by default
+	// step-security/harden-runner@v2. Set it to pin a commit.
+	Uses string `yaml:"uses"`
+	// Policy is the egress-policy of the suggested step: audit, the
+	// default, only records the endpoints the job connects to, and block
+	// denies all but AllowedEndpoints.
+	Policy string `yaml:"egress-policy"`
+	// AllowedEndpoints are the host:port endpoints a blocking step allows.
+	AllowedEndpoints []string `yaml:"allowed-endpoints"`
+}
+
+// Validate reports invalid patterns and policies.
+func (c EgressConfig) Validate() error {
+	for _, p := range c.Actions {
+		if _, err := compilePattern(p); err != nil {
+			return fmt.Errorf("invalid egress action pattern %q: %w", p, err)
+		}
+	}
+	switch c.Policy {
+	case "", "audit":
+	case "block":
+		if len(c.AllowedEndpoints) == 0 {
+			return fmt.Errorf("egress-policy block needs allowed-endpoints, or every connection of the job is denied")
+		}
+	default:
+		return fmt.Errorf("egress-policy must be audit or block, not %q", c.Policy)
+	}
+	return nil
+}
+
+var (
+	// egressActions publish or deploy, or get cloud credentials to do so.
+	egressActions = []string{
+		"actions/deploy-pages",
+		"aws-actions/configure-aws-credentials",
+		"azure/login",
+		"docker/login-action",
+		"google-github-actions/auth",
+		"goreleaser/goreleaser-action",
+		"JamesIves/github-pages-deploy-action",
+		"peaceiris/actions-gh-pages",
+		"pypa/gh-action-pypi-publish",
+		"softprops/action-gh-release",
+	}
+	// egressPushActions publish when their push input is true, or set by
+	// an expression.
+	egressPushActions = []string{
+		"docker/build-push-action",
+	}
+	// egressCommand matches commands that publish or deploy.
+	egressCommand = regexp.MustCompile(`\b(npm|yarn|pnpm|poetry|gradle|\./gradlew) +publish\b|\b(twine +upload|cargo +publish|gem +push|docker +push|mvn +deploy|dotnet +nuget +push|terraform +apply|goreleaser +release|flyctl +deploy)\b|\bdocker +buildx +build\b[^\n]*--push\b|\bhelm +(upgrade|install|push)\b|\bkubectl +(apply|rollout|set +image)\b`)
+)
+
+// RuleEgress flags jobs that publish or deploy without
+// step-security/harden-runner, or an equivalent, as their first step, and
+// offers to insert it. Such jobs hold credentials that a compromised
+// dependency could send anywhere, which monitoring egress reveals and
+// blocking it prevents.
+type RuleEgress struct {
+	actionlint.RuleBase
+	fixes
+	cfg         EgressConfig
+	permissions *actionlint.Permissions
+}
+
+// NewEgress creates a RuleEgress.
+func NewEgress(cfg EgressConfig) *RuleEgress {
+	return &RuleEgress{
+		RuleBase: actionlint.NewRuleBase(KindEgress, "Checks that publishing and deployment jobs harden their network egress"),
+		cfg:      cfg,
+	}
+}
+
+// VisitWorkflowPre records the workflow's permissions.
+func (rule *RuleEgress) VisitWorkflowPre(n *actionlint.Workflow) error {
+	rule.permissions = n.Permissions
+	return nil
+}
+
+// VisitJobPre checks that sensitive jobs start with a hardening step.
+func (rule *RuleEgress) VisitJobPre(n *actionlint.Job) error {
+	if !rule.cfg.Enabled || len(n.Steps) == 0 || n.Container != nil || !hardenableRunner(n.RunsOn) {
+		return nil
+	}
+	why := rule.sensitive(n)
+	if why == "" {
+		return nil
+	}
+
+	for i, s := range n.Steps {
+		exec, ok := s.Exec.(*actionlint.ExecAction)
+		if !ok || exec.Uses == nil || !rule.hardens(exec.Uses.Value) {
+			continue
+		}
+		if i > 0 {
+			rule.Errorf(exec.Uses.Pos, "%q is step %d of job %q, so the steps before it run unmonitored. move it to the start of the job", exec.Uses.Value, i+1, n.ID.Value)
+		}
+		return nil
+	}
+
+	uses := rule.cfg.Uses
+	if uses == "" {
+		uses = hardenRunner + "@v2"
+	}
+	policy := rule.cfg.Policy
+	if policy == "" {
+		policy = "audit"
+	}
+	rule.Errorf(n.ID.Pos, "job %q %s, but does not start with %s, so nothing monitors where its network traffic goes. add it as the first step with \"egress-policy: %s\"", n.ID.Value, why, hardenRunner, policy)
+
+	first := n.Steps[0]
+	if first.Pos != nil && first.Pos.Col > 2 {
+		indent := strings.Repeat(" ", first.Pos.Col-1)
+		step := fmt.Sprintf("uses: %s\n%swith:\n%s  egress-policy: %s\n", uses, indent, indent, policy)
+		if policy == "block" {
+			step += fmt.Sprintf("%s  allowed-endpoints: >\n", indent)
+			for _, e := range rule.cfg.AllowedEndpoints {
+				step += fmt.Sprintf("%s    %s\n", indent, e)
+			}
+		}
+		step += indent[2:] + "- "
+		rule.addFix(KindEgress, n.ID.Pos, "Start the job with "+hardenRunner,
+			Edit{Line: first.Pos.Line, Column: first.Pos.Col, New: step})
+	}
+	return nil
+}
+
+// sensitive returns why the job needs its egress hardened, or "" when it
+// does not.
+func (rule *RuleEgress) sensitive(n *actionlint.Job) string {
+	if n.Environment != nil && n.Environment.Name != nil {
+		return fmt.Sprintf("deploys to environment %q", n.Environment.Name.Value)
+	}
+	perms := n.Permissions
+	if perms == nil {
+		perms = rule.permissions
+	}
+	if perms != nil && accessLevel(perms, "id-token") == "write" {
+		return "requests an OIDC token"
+	}
+	for _, s := range n.Steps {
+		switch exec := s.Exec.(type) {
+		case *actionlint.ExecAction:
+			if exec.Uses == nil {
+				continue
+			}
+			name, _, _ := strings.Cut(exec.Uses.Value, "@")
+			for _, a := range egressActions {
+				if strings.EqualFold(name, a) {
+					return fmt.Sprintf("uses %s", exec.Uses.Value)
+				}
+			}
+			for _, a := range egressPushActions {
+				if !strings.EqualFold(name, a) {
+					continue
+				}
+				if push := exec.Inputs["push"]; push != nil && push.Value != nil && (push.Value.Value == "true" || push.Value.ContainsExpression()) {
+					return fmt.Sprintf("pushes with %s", exec.Uses.Value)
+				}
+			}
+		case *actionlint.ExecRun:
+			if exec.Run != nil {
+				if m := egressCommand.FindString(exec.Run.Value); m != "" {
+					return fmt.Sprintf("runs %q", strings.Join(strings.Fields(m), " "))
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// hardens reports whether uses is harden-runner or a configured
+// equivalent.
+func (rule *RuleEgress) hardens(uses string) bool {
+	name, _, _ := strings.Cut(uses, "@")
+	if strings.EqualFold(name, hardenRunner) {
+		return true
+	}
+	for _, p := range rule.cfg.Actions {
+		if re, err := compilePattern(p); err == nil && re.MatchString(uses) {
+			return true
+		}
+	}
+	return false
+}
+
+// hardenableRunner reports whether harden-runner can run on the runner,
+// which it only does on Linux. Runners given by an expression are assumed
+// to be Linux.
+func hardenableRunner(r *actionlint.Runner) bool {
+	if r == nil {
+		return false
+	}
+	for _, l := range r.Labels {
+		v := strings.ToLower(l.Value)
+		if strings.HasPrefix(v, "windows") || strings.HasPrefix(v, "macos") || v == "self-hosted" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEgress(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: go test ./...
+  deploy:
+    runs-on: ubuntu-latest
+    environment: production
+    steps:
+      - uses: actions/checkout@v4
+      - run: ./deploy.sh
+  publish:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: step-security/harden-runner@v2
+      - run: npm publish
+  image:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: acme/egress-guard@v1
+      - run: docker push ghcr.io/acme/app
+  windows:
+    runs-on: windows-latest
+    steps:
+      - run: cargo publish
+  push-image:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: docker/build-push-action@v6
+        with:
+          push: true
+  build-image:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: docker/build-push-action@v6
+        with:
+          push: false
+  registry:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: docker/login-action@v3
+        with:
+          registry: ghcr.io
+`
+	cfg := EgressConfig{Enabled: true, Actions: []string{`^acme/egress-guard@`}}
+	errs := lintWith(t, func() actionlint.Rule { return NewEgress(cfg) }, src)
+	require.Len(t, errs, 4)
+
+	assert.Equal(t, 7, errs[0].Line)
+	assert.Contains(t, errs[0].Message, `job "deploy" deploys to environment "production", but does not start with step-security/harden-runner`)
+	assert.Contains(t, errs[0].Message, `"egress-policy: audit"`)
+	assert.Equal(t, 17, errs[1].Line)
+	assert.Contains(t, errs[1].Message, `is step 2 of job "publish"`)
+	assert.Equal(t, 28, errs[2].Line)
+	assert.Contains(t, errs[2].Message, `job "push-image" pushes with docker/build-push-action@v6`)
+	assert.Equal(t, 40, errs[3].Line)
+	assert.Contains(t, errs[3].Message, `job "registry" uses docker/login-action@v3`)
+	for _, e := range errs {
+		assert.Equal(t, KindEgress, e.Kind)
+	}
+
+	errs = lintWith(t, func() actionlint.Rule { return NewEgress(EgressConfig{}) }, src)
+	assert.Empty(t, errs, "the rule is off by default")
+}
+
+func TestEgress_Fix(t *testing.T) {
+	src := `on: push
+permissions:
+  id-token: write
+jobs:
+  release:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+`
+	rule := NewEgress(EgressConfig{Enabled: true, Uses: "step-security/harden-runner@abc123", Policy: "block", AllowedEndpoints: []string{"github.com:443"}})
+	errs := lintWith(t, func() actionlint.Rule { return rule }, src)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, "requests an OIDC token")
+
+	require.Len(t, rule.Fixes(), 1)
+	fix := rule.Fixes()[0]
+	assert.Equal(t, 5, fix.Line)
+	assert.Equal(t, []Edit{{Line: 8, Column: 9, New: "uses: step-security/harden-runner@abc123\n        with:\n          egress-policy: block\n          allowed-endpoints: >\n            github.com:443\n      - "}}, fix.Edits)
+}
+
+func TestEgressConfig_Validate(t *testing.T) {
+	assert.NoError(t, EgressConfig{Policy: "audit"}.Validate())
+	assert.Error(t, EgressConfig{Policy: "block"}.Validate())
+	assert.Error(t, EgressConfig{Policy: "deny"}.Validate())
+	assert.Error(t, EgressConfig{Actions: []string{"("}}.Validate())
+}
@@ -0,0 +1,247 @@
+package rules
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindEnvFile is the name of RuleEnvFile.
+const KindEnvFile = "env-file"
+
+var (
+	// envFileRedirect matches a shell redirect into $GITHUB_OUTPUT or
+	// $GITHUB_ENV.
+	envFileRedirect = regexp.MustCompile(`>>?\s*["']?\$\{?(GITHUB_OUTPUT|GITHUB_ENV)\}?["']?`)
+	// envFileWrite matches the start of an echo or printf writing a
+	// name=value or name<<delimiter entry.
+	envFileWrite = regexp.MustCompile(`^\s*(echo|printf)\s+((?:-[a-zA-Z]+\s+)*)(["']?)([A-Za-z_][\w-]*)(=|<<)`)
+	// stepsRef matches the steps context, whose suffix decides which
+	// outputs an expression reads.
+	stepsRef      = regexp.MustCompile(`(?i)(?:^|[^\w.-])steps\b`)
+	stepOutput    = regexp.MustCompile(`(?i)^\s*\.\s*([\w-]+)\s*\.\s*outputs\s*\.\s*([\w-]+)`)
+	stepOutputs   = regexp.MustCompile(`(?i)^\s*\.\s*([\w-]+)\s*\.\s*outputs\b`)
+	stepProperty  = regexp.MustCompile(`(?i)^\s*\.\s*[\w-]+\s*\.\s*[\w-]+`)
+	stringPointer = reflect.TypeOf(&actionlint.String{})
+)
+
+// RuleEnvFile flags misuse of the $GITHUB_OUTPUT and $GITHUB_ENV files in
+// run: scripts: outputs of steps without an id, outputs nothing in the job
+// reads, and multiline values written without a heredoc delimiter, which
+// GitHub rejects or truncates. Only writes done with echo or printf on a
+// single statement are understood; anything else is left alone.
+type RuleEnvFile struct {
+	actionlint.RuleBase
+}
+
+// NewEnvFile creates a RuleEnvFile.
+func NewEnvFile() *RuleEnvFile {
+	return &RuleEnvFile{
+		RuleBase: actionlint.NewRuleBase(KindEnvFile, "Checks writes to $GITHUB_OUTPUT and $GITHUB_ENV"),
+	}
+}
+
+// envFileEntry is one name written to an environment file. escapes is set
+// when the command turns \n into a newline.
+type envFileEntry struct {
+	file    string
+	name    string
+	op      string
+	value   string
+	escapes bool
+}
+
+// VisitJobPre checks the scripts of the job's steps against the outputs the
+// job reads.
+func (rule *RuleEnvFile) VisitJobPre(n *actionlint.Job) error {
+	var refs *outputRefs
+	for _, step := range n.Steps {
+		exec, ok := step.Exec.(*actionlint.ExecRun)
+		if !ok || exec.Run == nil {
+			continue
+		}
+		script := exec.Run.Value
+		entries := parseEnvFileWrites(script)
+		for _, e := range entries {
+			rule.checkValue(exec.Run.Pos, e)
+		}
+
+		if !strings.Contains(script, "GITHUB_OUTPUT") {
+			continue
+		}
+		if step.ID == nil {
+			rule.Errorf(exec.Run.Pos, "step writes to $GITHUB_OUTPUT but has no id:, so nothing can read its outputs. add an id: to the step")
+			continue
+		}
+		if step.ID.ContainsExpression() {
+			continue
+		}
+
+		if refs == nil {
+			refs = collectOutputRefs(n)
+		}
+		id := strings.ToLower(step.ID.Value)
+		reported := map[string]bool{}
+		for _, e := range entries {
+			if e.file != "GITHUB_OUTPUT" || reported[e.name] || refs.reads(id, e.name) {
+				continue
+			}
+			reported[e.name] = true
+			rule.Errorf(exec.Run.Pos, "output %q of step %q is written to $GITHUB_OUTPUT but never read in job %q", e.name, step.ID.Value, n.ID.Value)
+		}
+	}
+	return nil
+}
+
+// checkValue flags name=value entries whose value spans several lines.
+func (rule *RuleEnvFile) checkValue(pos *actionlint.Pos, e envFileEntry) {
+	if e.op != "=" {
+		return
+	}
+	multiline := strings.Contains(e.value, "\n") ||
+		strings.Contains(e.value, "$(cat ") ||
+		(e.escapes && strings.Contains(strings.TrimSuffix(e.value, `\n`), `\n`))
+	if !multiline {
+		return
+	}
+	rule.Errorf(pos, "value of %q written to $%s may span several lines, which needs a delimiter: write %s<<EOF, the value and EOF on separate lines", e.name, e.file, e.name)
+}
+
+// parseEnvFileWrites returns the entries written with echo or printf in
+// statements redirected to an environment file.
+func parseEnvFileWrites(script string) []envFileEntry {
+	var out []envFileEntry
+	for _, stmt := range splitStatements(script) {
+		m := envFileRedirect.FindStringSubmatchIndex(stmt)
+		if m == nil {
+			continue
+		}
+		file := stmt[m[2]:m[3]]
+		cmd := stmt[:m[0]]
+		w := envFileWrite.FindStringSubmatch(cmd)
+		if w == nil {
+			continue
+		}
+		value := cmd[len(w[0]):]
+		if quote := w[3]; quote != "" {
+			if end := strings.LastIndex(value, quote); end >= 0 {
+				value = value[:end]
+			}
+		}
+		out = append(out, envFileEntry{
+			file:    file,
+			name:    w[4],
+			op:      w[5],
+			value:   value,
+			escapes: w[1] == "printf" || strings.Contains(w[2], "e"),
+		})
+	}
+	return out
+}
+
+// splitStatements splits a shell script at newlines outside quotes, so a
+// quoted value spanning lines stays in one statement.
+func splitStatements(script string) []string {
+	var out []string
+	var quote rune
+	start := 0
+	escaped := false
+	for i, c := range script {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '\n':
+			out = append(out, script[start:i])
+			start = i + 1
+		}
+	}
+	return append(out, script[start:])
+}
+
+// outputRefs records which step outputs a job reads. all is set when an
+// expression reads the steps context in a way that is not understood.
+type outputRefs struct {
+	all     bool
+	steps   map[string]bool
+	outputs map[string]bool
+}
+
+func (r *outputRefs) reads(id, name string) bool {
+	return r.all || r.steps[id] || r.outputs[id+"."+strings.ToLower(name)]
+}
+
+// collectOutputRefs scans every string of the job for reads of step
+// outputs.
+func collectOutputRefs(job *actionlint.Job) *outputRefs {
+	refs := &outputRefs{steps: map[string]bool{}, outputs: map[string]bool{}}
+	var strs []*actionlint.String
+	collectStrings(reflect.ValueOf(job), &strs)
+	for _, s := range strs {
+		for _, loc := range stepsRef.FindAllStringIndex(s.Value, -1) {
+			rest := s.Value[loc[1]:]
+			if m := stepOutput.FindStringSubmatch(rest); m != nil {
+				refs.outputs[strings.ToLower(m[1]+"."+m[2])] = true
+			} else if m := stepOutputs.FindStringSubmatch(rest); m != nil {
+				refs.steps[strings.ToLower(m[1])] = true
+			} else if !stepProperty.MatchString(rest) {
+				refs.all = true
+			}
+		}
+	}
+	return refs
+}
+
+// Strings returns every *actionlint.String reachable from node, such as a
+// job or a step, in field order and the key order of maps.
+func Strings(node any) []*actionlint.String {
+	var strs []*actionlint.String
+	collectStrings(reflect.ValueOf(node), &strs)
+	return strs
+}
+
+// collectStrings appends every *actionlint.String reachable from v.
+func collectStrings(v reflect.Value, out *[]*actionlint.String) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		if v.Type() == stringPointer {
+			*out = append(*out, v.Interface().(*actionlint.String))
+			return
+		}
+		collectStrings(v.Elem(), out)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				collectStrings(v.Field(i), out)
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			collectStrings(v.Index(i), out)
+		}
+	case reflect.Map:
+		// In key order, so the strings of a map come out the same way on
+		// every run
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			collectStrings(v.MapIndex(k), out)
+		}
+	}
+}
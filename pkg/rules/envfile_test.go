@@ -0,0 +1,96 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvFile(t *testing.T) {
+	newRule := func() actionlint.Rule { return NewEnvFile() }
+
+	tests := []struct {
+		name  string
+		steps string
+		want  []string
+	}{
+		{
+			name: "outputs read by later steps",
+			steps: `      - id: version
+        run: |
+          echo "tag=v1" >> "$GITHUB_OUTPUT"
+          echo sha=abc >> $GITHUB_OUTPUT
+      - run: echo ${{ steps.version.outputs.tag }} ${{ steps.VERSION.outputs.SHA }}
+      - run: |
+          {
+            echo "notes<<EOF"
+            cat notes.md
+            echo EOF
+          } >> "$GITHUB_OUTPUT"
+        id: notes
+        if: ${{ toJSON(steps.notes.outputs) != '{}' }}`,
+		},
+		{
+			name:  "step without id",
+			steps: `      - run: echo "tag=v1" >> "$GITHUB_OUTPUT"`,
+			want:  []string{"step writes to $GITHUB_OUTPUT but has no id:"},
+		},
+		{
+			name: "output never read",
+			steps: `      - id: version
+        run: |
+          echo "tag=v1" >> "$GITHUB_OUTPUT"
+          echo "sha=abc" >> "${GITHUB_OUTPUT}"
+      - run: echo ${{ steps.version.outputs.tag }} ${{ steps.version.outcome }}`,
+			want: []string{`output "sha" of step "version" is written to $GITHUB_OUTPUT but never read in job "build"`},
+		},
+		{
+			name: "steps context read as a whole",
+			steps: `      - id: version
+        run: echo "tag=v1" >> "$GITHUB_OUTPUT"
+      - run: echo '${{ toJSON(steps) }}'`,
+		},
+		{
+			name: "multiline values",
+			steps: `      - run: |
+          echo "NOTES=$(cat notes.md)" >> "$GITHUB_ENV"
+          echo -e "LIST=a\nb" >> $GITHUB_ENV
+          printf 'ONE=%s\n' "$x" >> $GITHUB_ENV
+          echo "BODY=first
+          second" >> $GITHUB_ENV`,
+			want: []string{
+				`value of "NOTES" written to $GITHUB_ENV may span several lines`,
+				`value of "LIST" written to $GITHUB_ENV may span several lines`,
+				`value of "BODY" written to $GITHUB_ENV may span several lines`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n" + tt.steps + "\n"
+			errs := lintWith(t, newRule, src)
+			require.Len(t, errs, len(tt.want), "%v", errs)
+			for i, want := range tt.want {
+				assert.Equal(t, KindEnvFile, errs[i].Kind)
+				assert.Contains(t, errs[i].Message, want)
+			}
+		})
+	}
+}
+
+func TestStrings_MapOrder(t *testing.T) {
+	env := &actionlint.Env{Vars: map[string]*actionlint.EnvVar{}}
+	for _, name := range []string{"zeta", "alpha", "mu", "beta", "omega"} {
+		env.Vars[name] = &actionlint.EnvVar{Value: &actionlint.String{Value: name}}
+	}
+	for range 20 {
+		var values []string
+		for _, s := range Strings(env) {
+			values = append(values, s.Value)
+		}
+		assert.Equal(t, []string{"alpha", "beta", "mu", "omega", "zeta"}, values)
+	}
+}
@@ -0,0 +1,89 @@
+package rules
+
+import (
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindEventFilter is the name of RuleEventFilter.
+const KindEventFilter = "event-filter"
+
+// RuleEventFilter flags event filters that cannot work as written. It
+// complements actionlint's events rule, which already rejects filters
+// unavailable for an event, invalid activity types, and a filter combined
+// with its -ignore counterpart.
+type RuleEventFilter struct {
+	actionlint.RuleBase
+}
+
+// NewEventFilter creates a RuleEventFilter.
+func NewEventFilter() *RuleEventFilter {
+	return &RuleEventFilter{
+		RuleBase: actionlint.NewRuleBase(KindEventFilter, "Checks for event filters that never match or are ignored"),
+	}
+}
+
+// VisitWorkflowPre checks the filters of each webhook event.
+func (rule *RuleEventFilter) VisitWorkflowPre(w *actionlint.Workflow) error {
+	for _, e := range w.On {
+		hook, ok := e.(*actionlint.WebhookEvent)
+		if !ok {
+			continue
+		}
+
+		for _, f := range []*actionlint.WebhookEventFilter{hook.Branches, hook.Tags, hook.Paths} {
+			rule.checkPatterns(hook, f)
+		}
+
+		if hook.Hook.Value == "push" && (!hook.Tags.IsEmpty() || !hook.TagsIgnore.IsEmpty()) {
+			for _, f := range []*actionlint.WebhookEventFilter{hook.Paths, hook.PathsIgnore} {
+				if !f.IsEmpty() {
+					rule.Errorf(f.Name.Pos, "%q filter of \"push\" event is not evaluated for tag pushes, so tags matching the tag filters trigger the workflow whatever files they change", f.Name.Value)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkPatterns flags a filter that only has negated patterns, which
+// matches nothing, and patterns that a later negation removes again.
+func (rule *RuleEventFilter) checkPatterns(hook *actionlint.WebhookEvent, f *actionlint.WebhookEventFilter) {
+	if f.IsEmpty() {
+		return
+	}
+
+	positive := false
+	for _, v := range f.Values {
+		if !strings.HasPrefix(v.Value, "!") {
+			positive = true
+			break
+		}
+	}
+	if !positive {
+		rule.Errorf(f.Name.Pos, "%q filter of %q event only has negated patterns, so it matches nothing. add a positive pattern such as \"**\" before them", f.Name.Value, hook.Hook.Value)
+		return
+	}
+
+	// Later patterns override earlier ones, so only the last inclusion of a
+	// pattern decides whether it matches
+	for i, v := range f.Values {
+		if strings.HasPrefix(v.Value, "!") {
+			continue
+		}
+		var negated *actionlint.String
+		for _, later := range f.Values[i+1:] {
+			if later.Value == v.Value {
+				negated = nil
+				break
+			}
+			if negated == nil && later.Value == "!"+v.Value {
+				negated = later
+			}
+		}
+		if negated != nil {
+			rule.Errorf(v.Pos, "pattern %q in %q filter of %q event never matches because %q after it excludes it again", v.Value, f.Name.Value, hook.Hook.Value, negated.Value)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventFilter(t *testing.T) {
+	newRule := func() actionlint.Rule { return NewEventFilter() }
+
+	tests := []struct {
+		name string
+		on   string
+		want string
+	}{
+		{
+			name: "valid filters",
+			on:   "push:\n    branches: [main, 'releases/**', '!releases/**-alpha']\n    paths: ['src/**']",
+		},
+		{
+			name: "only negated patterns",
+			on:   "pull_request:\n    branches: ['!main']",
+			want: `"branches" filter of "pull_request" event only has negated patterns, so it matches nothing`,
+		},
+		{
+			name: "pattern excluded again",
+			on:   "push:\n    branches: [main, dev, '!main']",
+			want: `pattern "main" in "branches" filter of "push" event never matches because "!main" after it excludes it again`,
+		},
+		{
+			name: "pattern included again",
+			on:   "push:\n    branches: [main, '!main', main]",
+		},
+		{
+			name: "paths with tags",
+			on:   "push:\n    tags: ['v*']\n    paths: ['src/**']",
+			want: `"paths" filter of "push" event is not evaluated for tag pushes`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "on:\n  " + tt.on + "\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n"
+			errs := lintWith(t, newRule, src)
+			if tt.want == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			require.Len(t, errs, 1)
+			assert.Equal(t, KindEventFilter, errs[0].Kind)
+			assert.Contains(t, errs[0].Message, tt.want)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package rules
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindFailureHandling is the name of RuleFailureHandling.
+const KindFailureHandling = "failure-handling"
+
+// FailureConfig configures the failure-handling rule.
+type FailureConfig struct {
+	// FailFast flags matrices that leave fail-fast at its default, which
+	// cancels the other combinations when one fails. It is off by default
+	// since most matrices do.
+	FailFast bool `yaml:"fail-fast"`
+}
+
+var (
+	// stepResult matches reads of a step's outcome or conclusion.
+	stepResult = regexp.MustCompile(`(?i)(?:^|[^\w.-])steps\s*\.\s*([\w-]+)\s*\.\s*(?:outcome|conclusion)\b`)
+	// stepOutputRead matches reads of a step's outputs.
+	stepOutputRead = regexp.MustCompile(`(?i)(?:^|[^\w.-])steps\s*\.\s*([\w-]+)\s*\.\s*outputs\b`)
+)
+
+// RuleFailureHandling flags failure settings that hide failures: a job
+// that always passes because of continue-on-error, steps whose outputs are
+// used after they may have failed, and, when configured, matrices that
+// leave fail-fast at its default.
+type RuleFailureHandling struct {
+	actionlint.RuleBase
+	cfg FailureConfig
+}
+
+// NewFailureHandling creates a RuleFailureHandling.
+func NewFailureHandling(cfg FailureConfig) *RuleFailureHandling {
+	return &RuleFailureHandling{
+		RuleBase: actionlint.NewRuleBase(KindFailureHandling, "Checks for continue-on-error and fail-fast settings that hide failures"),
+		cfg:      cfg,
+	}
+}
+
+// VisitJobPre checks the failure settings of the job and its steps.
+func (rule *RuleFailureHandling) VisitJobPre(n *actionlint.Job) error {
+	if c := n.ContinueOnError; c != nil && c.Expression == nil && c.Value {
+		rule.Errorf(c.Pos, "job %q sets \"continue-on-error: true\", so the workflow succeeds when it fails and a required check on it always passes. use an expression such as ${{ matrix.experimental }} to allow only some runs to fail", n.ID.Value)
+	}
+
+	if s := n.Strategy; rule.cfg.FailFast && s != nil && s.Matrix != nil && s.FailFast == nil {
+		rule.Errorf(s.Matrix.Pos, "matrix of job %q leaves \"fail-fast\" at its default true, so one failing combination cancels the others and hides their results. set \"fail-fast: false\" when the combinations are independent, or \"fail-fast: true\" to keep it", n.ID.Value)
+	}
+
+	rule.checkFailedOutputs(n)
+	return nil
+}
+
+// checkFailedOutputs flags reads of the outputs of a step that continues on
+// error when nothing in the job checks whether the step succeeded.
+func (rule *RuleFailureHandling) checkFailedOutputs(n *actionlint.Job) {
+	var all []*actionlint.String
+	collectStrings(reflect.ValueOf(n), &all)
+	checked := map[string]bool{}
+	for _, s := range all {
+		for _, m := range stepResult.FindAllStringSubmatch(s.Value, -1) {
+			checked[strings.ToLower(m[1])] = true
+		}
+	}
+
+	failing := map[string]bool{}
+	for _, step := range n.Steps {
+		var strs []*actionlint.String
+		collectStrings(reflect.ValueOf(step), &strs)
+		rule.checkReads(strs, failing, checked)
+
+		c := step.ContinueOnError
+		if step.ID != nil && c != nil && c.Expression == nil && c.Value {
+			id := strings.ToLower(step.ID.Value)
+			if !checked[id] {
+				failing[id] = true
+			}
+		}
+	}
+
+	var outputs []*actionlint.String
+	for _, o := range n.Outputs {
+		if o.Value != nil {
+			outputs = append(outputs, o.Value)
+		}
+	}
+	rule.checkReads(outputs, failing, checked)
+}
+
+// checkReads reports the first read of each failing step's outputs in strs.
+func (rule *RuleFailureHandling) checkReads(strs []*actionlint.String, failing, checked map[string]bool) {
+	for _, s := range strs {
+		for _, m := range stepOutputRead.FindAllStringSubmatch(s.Value, -1) {
+			id := strings.ToLower(m[1])
+			if !failing[id] {
+				continue
+			}
+			delete(failing, id)
+			rule.Errorf(s.Pos, "outputs of step %q are used, but the step sets \"continue-on-error: true\" and may have failed without setting them. check steps.%s.outcome before using them", m[1], m[1])
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const failureWorkflow = `on: push
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    continue-on-error: true
+    steps:
+      - run: make lint
+  test:
+    runs-on: ubuntu-latest
+    continue-on-error: ${{ matrix.experimental }}
+    strategy:
+      matrix:
+        experimental: [false, true]
+    outputs:
+      version: ${{ steps.probe.outputs.version }}
+    steps:
+      - id: probe
+        continue-on-error: true
+        run: echo "version=1" >> "$GITHUB_OUTPUT"
+      - run: echo ${{ steps.probe.outputs.version }}
+      - id: checked
+        continue-on-error: true
+        run: echo "ok=1" >> "$GITHUB_OUTPUT"
+      - if: steps.checked.outcome == 'success'
+        run: echo ${{ steps.checked.outputs.ok }}
+  build:
+    runs-on: ubuntu-latest
+    strategy:
+      fail-fast: false
+      matrix:
+        os: [ubuntu-latest, macos-latest]
+    steps:
+      - run: make
+`
+
+func TestFailureHandling(t *testing.T) {
+	errs := lintWith(t, func() actionlint.Rule { return NewFailureHandling(FailureConfig{}) }, failureWorkflow)
+	require.Len(t, errs, 2)
+	assert.Equal(t, KindFailureHandling, errs[0].Kind)
+	assert.Contains(t, errs[0].Message, `job "lint" sets "continue-on-error: true"`)
+	assert.Equal(t, 5, errs[0].Line)
+	assert.Contains(t, errs[1].Message, `outputs of step "probe" are used, but the step sets "continue-on-error: true"`)
+	assert.Equal(t, 20, errs[1].Line)
+}
+
+func TestFailureHandling_FailFast(t *testing.T) {
+	errs := lintWith(t, func() actionlint.Rule { return NewFailureHandling(FailureConfig{FailFast: true}) }, failureWorkflow)
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[1].Message, `matrix of job "test" leaves "fail-fast" at its default true`)
+	assert.Equal(t, 12, errs[1].Line)
+}
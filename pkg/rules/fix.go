@@ -0,0 +1,123 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// Edit replaces the first occurrence of Old at or after Line and Column
+// with New. The edits of a fix are applied in order, each one searching
+// after the end of the previous one. An empty Old inserts New at Line and
+// Column.
+type Edit struct {
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Old    string `json:"old"`
+	New    string `json:"new"`
+}
+
+// Fix is a machine-applicable change resolving the finding of Kind at Line
+// and Column. ID is set by the linter once the finding's final position is
+// known.
+type Fix struct {
+	ID          string `json:"id"`
+	Kind        string `json:"-"`
+	Line        int    `json:"-"`
+	Column      int    `json:"-"`
+	Description string `json:"description"`
+	Edits       []Edit `json:"edits"`
+}
+
+// Fixer is implemented by rules that offer fixes for findings, their own
+// or those of actionlint's rules.
+type Fixer interface {
+	Fixes() []*Fix
+}
+
+// fixes collects the fixes of a rule. Rules embed it to implement Fixer.
+type fixes struct {
+	list []*Fix
+}
+
+// Fixes returns the fixes recorded so far.
+func (f *fixes) Fixes() []*Fix {
+	return f.list
+}
+
+func (f *fixes) addFix(kind string, pos *actionlint.Pos, description string, edits ...Edit) {
+	f.list = append(f.list, &Fix{
+		Kind:        kind,
+		Line:        pos.Line,
+		Column:      pos.Col,
+		Description: description,
+		Edits:       edits,
+	})
+}
+
+// KindDeprecatedCommands is the kind of actionlint's findings for
+// deprecated workflow commands, which RuleFixes offers fixes for.
+const KindDeprecatedCommands = "deprecated-commands"
+
+// deprecatedCommand matches an echo of a deprecated workflow command that
+// makes up a whole line of a script.
+var deprecatedCommand = regexp.MustCompile(`^echo\s+(["']?)::(set-output|save-state|set-env)\s+name=([a-zA-Z][a-zA-Z_-]*)::(.*?)(["']?)\s*$|^echo\s+(["']?)::add-path::(.*?)(["']?)\s*$`)
+
+// commandFiles maps deprecated workflow commands to the environment file
+// replacing them.
+var commandFiles = map[string]string{
+	"set-output": "GITHUB_OUTPUT",
+	"save-state": "GITHUB_STATE",
+	"set-env":    "GITHUB_ENV",
+}
+
+// RuleFixes reports nothing itself. It attaches fixes to findings of
+// actionlint's own rules that can be fixed mechanically.
+type RuleFixes struct {
+	actionlint.RuleBase
+	fixes
+}
+
+// NewFixes creates a RuleFixes.
+func NewFixes() *RuleFixes {
+	return &RuleFixes{
+		RuleBase: actionlint.NewRuleBase("fixes", "Offers fixes for findings of actionlint's rules"),
+	}
+}
+
+// VisitStep offers to replace deprecated workflow commands written with
+// echo by writes to the matching environment file.
+func (rule *RuleFixes) VisitStep(n *actionlint.Step) error {
+	exec, ok := n.Exec.(*actionlint.ExecRun)
+	if !ok || exec.Run == nil {
+		return nil
+	}
+
+	var edits []Edit
+	for _, line := range strings.Split(exec.Run.Value, "\n") {
+		stmt := strings.TrimSpace(line)
+		m := deprecatedCommand.FindStringSubmatch(stmt)
+		if m == nil {
+			continue
+		}
+		var replacement string
+		if m[2] != "" {
+			if m[1] != m[5] {
+				continue
+			}
+			replacement = fmt.Sprintf(`echo %s%s=%s%s >> "$%s"`, m[1], m[3], m[4], m[1], commandFiles[m[2]])
+		} else {
+			if m[6] != m[8] {
+				continue
+			}
+			replacement = fmt.Sprintf(`echo %s%s%s >> "$GITHUB_PATH"`, m[6], m[7], m[6])
+		}
+		edits = append(edits, Edit{Line: exec.Run.Pos.Line, Column: exec.Run.Pos.Col, Old: stmt, New: replacement})
+	}
+	if len(edits) > 0 {
+		rule.addFix(KindDeprecatedCommands, exec.Run.Pos, "Replace deprecated workflow commands with environment files", edits...)
+	}
+	return nil
+}
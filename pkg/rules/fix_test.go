@@ -0,0 +1,55 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixes(t *testing.T) {
+	rule := NewFixes()
+	errs := lintWith(t, func() actionlint.Rule { return rule }, `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - id: v
+        run: |
+          echo "::set-output name=tag::$TAG"
+          echo ::save-state name=pid::$$
+          echo '::add-path::/opt/bin'
+          echo "::set-env name=MIXED::quotes'
+`)
+	assert.Empty(t, errs, "the rule reports nothing itself")
+
+	fixes := rule.Fixes()
+	require.Len(t, fixes, 1)
+	fix := fixes[0]
+	assert.Equal(t, KindDeprecatedCommands, fix.Kind)
+	assert.Equal(t, 7, fix.Line)
+	assert.Equal(t, []Edit{
+		{Line: 7, Column: 14, Old: `echo "::set-output name=tag::$TAG"`, New: `echo "tag=$TAG" >> "$GITHUB_OUTPUT"`},
+		{Line: 7, Column: 14, Old: `echo ::save-state name=pid::$$`, New: `echo pid=$$ >> "$GITHUB_STATE"`},
+		{Line: 7, Column: 14, Old: `echo '::add-path::/opt/bin'`, New: `echo '/opt/bin' >> "$GITHUB_PATH"`},
+	}, fix.Edits)
+}
+
+func TestConstantConditionFix(t *testing.T) {
+	rule := NewConstantCondition()
+	errs := lintWith(t, func() actionlint.Rule { return rule }, `on: push
+jobs:
+  build:
+    if: ${{ 'false' }}
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo hi
+        if: github.ref == 'refs/heads/main' || true
+`)
+	require.Len(t, errs, 2)
+
+	fixes := rule.Fixes()
+	require.Len(t, fixes, 1, "only quoted booleans have a fix")
+	assert.Equal(t, []Edit{{Line: 4, Column: 9, Old: "${{ 'false' }}", New: "false"}}, fixes[0].Edits)
+}
@@ -0,0 +1,167 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindForkSafety is the name of RuleForkSafety.
+const KindForkSafety = "fork-safety"
+
+var (
+	// forkEvents are the events whose runs for pull requests from forks get
+	// no secrets and a read-only GITHUB_TOKEN.
+	forkEvents = []string{"pull_request", "pull_request_review", "pull_request_review_comment"}
+	// forkGuard matches conditions that tell runs from forks apart, or that
+	// check whether a secret is set.
+	forkGuard = regexp.MustCompile(`head\s*\.\s*repo\b|\bgithub\s*\.\s*event_name\b|\bsecrets\b`)
+	// forkSecret matches the secrets runs from forks do not get.
+	forkSecret = regexp.MustCompile(`\bsecrets\s*(?:\.\s*([A-Za-z_][\w-]*)|\[\s*'([^']+)'\s*\])`)
+)
+
+// RuleForkSafety flags jobs of pull_request workflows that need secrets or
+// write access, which GitHub withholds from runs for pull requests from
+// forks: their secrets are empty and their GITHUB_TOKEN is read-only, so
+// the jobs fail, or quietly do nothing, for outside contributors. Jobs and
+// steps whose if: tells runs from forks apart are left alone.
+type RuleForkSafety struct {
+	actionlint.RuleBase
+	actions     ActionPermissions
+	event       string
+	permissions *actionlint.Permissions
+}
+
+// NewForkSafety creates a RuleForkSafety. root is the repository root that
+// cfg.File is relative to.
+func NewForkSafety(cfg PermissionsConfig, root string) *RuleForkSafety {
+	actions, _ := LoadActionPermissions(cfg, root) // Reported by the token-permissions rule
+	return &RuleForkSafety{
+		RuleBase: actionlint.NewRuleBase(KindForkSafety, "Checks that pull_request jobs work for pull requests from forks"),
+		actions:  actions,
+	}
+}
+
+// VisitWorkflowPre records the first event whose runs from forks are
+// restricted, and the workflow's permissions.
+func (rule *RuleForkSafety) VisitWorkflowPre(n *actionlint.Workflow) error {
+	rule.event = ""
+	rule.permissions = n.Permissions
+	for _, e := range n.On {
+		for _, name := range forkEvents {
+			if e.EventName() == name && rule.event == "" {
+				rule.event = name
+			}
+		}
+	}
+	return nil
+}
+
+// VisitJobPre checks the secrets and permissions the job needs.
+func (rule *RuleForkSafety) VisitJobPre(n *actionlint.Job) error {
+	if rule.event == "" || guardsForks(n.If) {
+		return nil
+	}
+
+	// Strings of steps that only run outside of forks are not checked
+	skip := map[*actionlint.String]bool{n.If: true}
+	var steps []*actionlint.Step
+	for _, s := range n.Steps {
+		if guardsForks(s.If) {
+			for _, str := range Strings(s) {
+				skip[str] = true
+			}
+			continue
+		}
+		steps = append(steps, s)
+	}
+
+	rule.checkSecrets(n, skip)
+
+	perms := n.Permissions
+	if perms == nil {
+		perms = rule.permissions
+	}
+	if perms != nil {
+		var write []string
+		if perms.All != nil && perms.All.Value == "write-all" {
+			write = append(write, "write-all")
+		}
+		for name := range perms.Scopes {
+			if accessLevel(perms, name) == "write" {
+				write = append(write, name+": write")
+			}
+		}
+		sort.Strings(write)
+		if len(write) > 0 {
+			rule.Errorf(perms.Pos, "job %q is granted %s, but %s runs for pull requests from forks get a read-only GITHUB_TOKEN, so its steps writing with the token fail for outside contributors. guard them with if: github.event.pull_request.head.repo.full_name == github.repository, or do the writing in a workflow_run workflow", n.ID.Value, strings.Join(write, ", "), rule.event)
+		}
+		return nil
+	}
+
+	// Without a permissions block, the needs of known actions tell
+	for _, s := range steps {
+		exec, ok := s.Exec.(*actionlint.ExecAction)
+		if !ok || exec.Uses == nil {
+			continue
+		}
+		needs, _ := StepPermissions(s, rule.actions)
+		var write []string
+		for scope, level := range needs {
+			if level == "write" && scope != "id-token" {
+				write = append(write, fmt.Sprintf("%q", scope+": write"))
+			}
+		}
+		sort.Strings(write)
+		if len(write) > 0 {
+			rule.Errorf(exec.Uses.Pos, "%q needs %s, but %s runs for pull requests from forks get a read-only GITHUB_TOKEN, so it fails for outside contributors. guard the step with if: github.event.pull_request.head.repo.full_name == github.repository, or run it from a pull_request_target or workflow_run workflow that does not check out the pull request's code", exec.Uses.Value, strings.Join(write, " and "), rule.event)
+		}
+	}
+	return nil
+}
+
+// checkSecrets reports the secrets the job reads outside of the strings in
+// skip, once per job at the earliest of them, in name order.
+func (rule *RuleForkSafety) checkSecrets(n *actionlint.Job, skip map[*actionlint.String]bool) {
+	if call := n.WorkflowCall; call != nil && call.InheritSecrets && call.Uses != nil {
+		rule.Errorf(call.Uses.Pos, "job %q passes its secrets to %s, but %s runs for pull requests from forks get none, so the reusable workflow runs without them for outside contributors. guard the job with if: github.event.pull_request.head.repo.full_name == github.repository", n.ID.Value, call.Uses.Value, rule.event)
+		return
+	}
+
+	var pos *actionlint.Pos
+	var names []string
+	seen := map[string]bool{}
+	for _, s := range Strings(n) {
+		if skip[s] {
+			continue
+		}
+		for _, m := range forkSecret.FindAllStringSubmatch(s.Value, -1) {
+			name := m[1] + m[2]
+			if strings.EqualFold(name, "GITHUB_TOKEN") || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+			if pos == nil || s.Pos.Line < pos.Line || (s.Pos.Line == pos.Line && s.Pos.Col < pos.Col) {
+				pos = s.Pos
+			}
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	sort.Strings(names)
+	what := "secret " + names[0]
+	if len(names) > 1 {
+		what = "secrets " + strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+	}
+	rule.Errorf(pos, "job %q reads %s, but %s runs for pull requests from forks get no secrets other than GITHUB_TOKEN, so they are empty for outside contributors and the steps using them fail or do nothing. guard those steps with if: github.event.pull_request.head.repo.full_name == github.repository, or check that the secret is set", n.ID.Value, what, rule.event)
+}
+
+// guardsForks reports whether cond tells runs from forks apart.
+func guardsForks(cond *actionlint.String) bool {
+	return cond != nil && forkGuard.MatchString(cond.Value)
+}
@@ -0,0 +1,115 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForkSafety(t *testing.T) {
+	newRule := func() actionlint.Rule { return NewForkSafety(PermissionsConfig{}, "") }
+
+	tests := []struct {
+		name string
+		on   string
+		src  string
+		want []string
+	}{
+		{
+			name: "secrets",
+			on:   "pull_request",
+			src: `    steps:
+      - run: ./deploy.sh
+        env:
+          TOKEN: ${{ secrets.DEPLOY_TOKEN }}
+          KEY: ${{ secrets['SIGNING_KEY'] }}
+          GH_TOKEN: ${{ secrets.GITHUB_TOKEN }}`,
+			want: []string{`job "build" reads secrets DEPLOY_TOKEN and SIGNING_KEY, but pull_request runs for pull requests from forks get no secrets`},
+		},
+		{
+			name: "guarded step",
+			on:   "pull_request",
+			src: `    steps:
+      - if: github.event.pull_request.head.repo.full_name == github.repository
+        run: ./deploy.sh
+        env:
+          TOKEN: ${{ secrets.DEPLOY_TOKEN }}
+      - if: env.TOKEN != ''
+        run: ./upload.sh
+        env:
+          TOKEN: ${{ secrets.CODECOV_TOKEN }}`,
+			want: []string{`job "build" reads secret CODECOV_TOKEN`},
+		},
+		{
+			name: "guarded job",
+			on:   "pull_request",
+			src: `    if: github.event_name == 'push'
+    permissions:
+      contents: write
+    steps:
+      - run: echo ${{ secrets.TOKEN }}`,
+		},
+		{
+			name: "write permissions",
+			on:   "[push, pull_request_review]",
+			src: `    permissions:
+      contents: read
+      pull-requests: write
+      issues: write
+    steps:
+      - run: echo`,
+			want: []string{`job "build" is granted issues: write, pull-requests: write, but pull_request_review runs for pull requests from forks get a read-only GITHUB_TOKEN`},
+		},
+		{
+			name: "actions needing write access",
+			on:   "pull_request",
+			src: `    steps:
+      - uses: actions/labeler@v5
+      - uses: actions/checkout@v4`,
+			want: []string{`"actions/labeler@v5" needs "pull-requests: write", but pull_request runs for pull requests from forks get a read-only GITHUB_TOKEN`},
+		},
+		{
+			name: "read-only permissions",
+			on:   "pull_request",
+			src: `    permissions:
+      contents: read
+    steps:
+      - uses: actions/labeler@v5`,
+		},
+		{
+			name: "pull_request_target",
+			on:   "pull_request_target",
+			src: `    permissions:
+      pull-requests: write
+    steps:
+      - uses: actions/labeler@v5
+      - run: echo ${{ secrets.TOKEN }}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "on: " + tt.on + "\njobs:\n  build:\n    runs-on: ubuntu-latest\n" + tt.src + "\n"
+			errs := lintWith(t, newRule, src)
+			require.Len(t, errs, len(tt.want), "%v", errs)
+			for i, want := range tt.want {
+				assert.Equal(t, KindForkSafety, errs[i].Kind)
+				assert.Contains(t, errs[i].Message, want)
+			}
+		})
+	}
+}
+
+func TestForkSafety_InheritSecrets(t *testing.T) {
+	src := `on: pull_request
+jobs:
+  call:
+    uses: ./.github/workflows/deploy.yml
+    secrets: inherit
+`
+	errs := lintWith(t, func() actionlint.Rule { return NewForkSafety(PermissionsConfig{}, "") }, src)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, `job "call" passes its secrets to ./.github/workflows/deploy.yml`)
+}
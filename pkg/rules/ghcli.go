@@ -0,0 +1,139 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindGHCLI is the name of RuleGHCLI.
+const KindGHCLI = "gh-cli"
+
+var (
+	// ghCommand matches a script running gh, with its subcommand.
+	ghCommand = regexp.MustCompile(`(?m)(?:^|[;&|(` + "`" + `]|\b(?:then|do|else|exec|time)\s+)\s*gh\s+([\w-]+)`)
+	// ghWithoutAuth are the subcommands of gh that do not need it to be
+	// logged in.
+	ghWithoutAuth = map[string]bool{"auth": true, "alias": true, "completion": true, "config": true, "help": true, "version": true, "--help": true, "--version": true}
+	// ghTokenInScript matches scripts that set gh's token or log it in
+	// themselves.
+	ghTokenInScript = regexp.MustCompile(`\b(?:GH_TOKEN|GITHUB_TOKEN|GH_ENTERPRISE_TOKEN)=|\bgh\s+auth\s+login\b`)
+	// ghTokenForJob matches scripts that log gh in for the later steps of
+	// the job, or pass them a token through GITHUB_ENV.
+	ghTokenForJob = regexp.MustCompile(`\bgh\s+auth\s+login\b|\b(?:GH_TOKEN|GITHUB_TOKEN)=[^\n]*\bGITHUB_ENV\b`)
+
+	// ghDeprecated are the gh commands and flags gh deprecated, with what
+	// to use instead.
+	ghDeprecated = []struct {
+		pattern *regexp.Regexp
+		what    string
+		instead string
+	}{
+		{regexp.MustCompile(`\bgh\s+repo\s+(?:delete|archive|unarchive|rename)\b[^\n;&|]*\s--confirm\b`), "--confirm of gh repo delete, archive, unarchive and rename", "use --yes instead"},
+		{regexp.MustCompile(`\bgh\s+label\s+delete\b[^\n;&|]*\s--confirm\b`), "--confirm of gh label delete", "use --yes instead"},
+		{regexp.MustCompile(`\bgh\s+repo\s+create\b[^\n;&|]*\s(?:--confirm|-y)\b`), "--confirm of gh repo create", "drop it, since gh repo create does not prompt when it is given a name"},
+		{regexp.MustCompile(`\bgh\s+repo\s+create\b[^\n;&|]*\s--enable-(?:issues|wiki)\b`), "--enable-issues and --enable-wiki of gh repo create", "use --disable-issues and --disable-wiki instead"},
+		{regexp.MustCompile(`\bgh\s+repo\s+list\b[^\n;&|]*\s--(?:public|private)\b`), "--public and --private of gh repo list", "use --visibility instead"},
+		{regexp.MustCompile(`\bgh\s+(?:pr|issue)\s+(?:view|list)\b[^\n;&|]*\bprojectCards\b`), "the projectCards field of gh pr and gh issue, which read classic projects GitHub removed", "use projectItems instead"},
+	}
+)
+
+// RuleGHCLI flags run: scripts whose gh commands fail when they run: gh
+// run in steps without GH_TOKEN or GITHUB_TOKEN, which is not logged in on
+// hosted runners, commands needing write access the job's token is not
+// granted, and deprecated commands and flags. Tokens set or logged in by
+// the script or an earlier step are accepted, and the permissions of
+// tokens other than the job's are not checked.
+type RuleGHCLI struct {
+	actionlint.RuleBase
+	fixes
+	permissions *actionlint.Permissions
+	env         *actionlint.Env
+}
+
+// NewGHCLI creates a RuleGHCLI.
+func NewGHCLI() *RuleGHCLI {
+	return &RuleGHCLI{
+		RuleBase: actionlint.NewRuleBase(KindGHCLI, "Checks that gh commands in run: scripts can authenticate and are not deprecated"),
+	}
+}
+
+// VisitWorkflowPre records the workflow's permissions and environment.
+func (rule *RuleGHCLI) VisitWorkflowPre(n *actionlint.Workflow) error {
+	rule.permissions = n.Permissions
+	rule.env = n.Env
+	return nil
+}
+
+// VisitJobPre checks the gh commands of the job's scripts.
+func (rule *RuleGHCLI) VisitJobPre(n *actionlint.Job) error {
+	perms := n.Permissions
+	if perms == nil {
+		perms = rule.permissions
+	}
+	loggedIn := false
+	for _, s := range n.Steps {
+		exec, ok := s.Exec.(*actionlint.ExecRun)
+		if !ok || exec.Run == nil {
+			continue
+		}
+		script := exec.Run.Value
+		if cmd := authenticatedCommand(script); cmd != "" && !loggedIn && !ghTokenInScript.MatchString(script) {
+			if _, set := tokenVar([]*actionlint.Env{s.Env, n.Env, rule.env}); !set {
+				rule.Errorf(exec.Run.Pos, "script runs %q, but neither GH_TOKEN nor GITHUB_TOKEN is set for the step, so gh is not logged in and fails. set GH_TOKEN: ${{ github.token }} in the step's env", cmd)
+				if s.Env == nil && s.Pos != nil && s.Pos.Col > 1 {
+					indent := strings.Repeat(" ", s.Pos.Col-1)
+					rule.addFix(KindGHCLI, exec.Run.Pos, "Pass the job's token to gh in GH_TOKEN",
+						Edit{Line: s.Pos.Line, Column: s.Pos.Col, New: fmt.Sprintf("env:\n%s  GH_TOKEN: ${{ github.token }}\n%s", indent, indent)})
+				}
+			}
+		}
+		if perms != nil && tokenEnv([]*actionlint.Env{s.Env, n.Env, rule.env}) {
+			rule.checkPermissions(exec, perms)
+		}
+		for _, d := range ghDeprecated {
+			if d.pattern.MatchString(script) {
+				rule.Errorf(exec.Run.Pos, "script uses %s, which gh deprecated. %s", d.what, d.instead)
+			}
+		}
+		loggedIn = loggedIn || ghTokenForJob.MatchString(script)
+	}
+	return nil
+}
+
+// checkPermissions reports the gh commands of exec needing write access
+// that perms does not grant. Read access is not checked, since public
+// repositories can be read without it.
+func (rule *RuleGHCLI) checkPermissions(exec *actionlint.ExecRun, perms *actionlint.Permissions) {
+	commands := map[string]string{}
+	for _, c := range tokenCommands {
+		if !c.ghCommand || c.level != "write" || accessLevel(perms, c.scope) == "write" {
+			continue
+		}
+		if m := c.pattern.FindString(exec.Run.Value); m != "" && commands[c.scope] == "" {
+			commands[c.scope] = strings.Join(strings.Fields(m), " ")
+		}
+	}
+	scopes := make([]string, 0, len(commands))
+	for scope := range commands {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	for _, scope := range scopes {
+		rule.Errorf(exec.Run.Pos, "script runs %q with the job's token, which needs %q, but the job's permissions do not grant it", commands[scope], scope+": write")
+	}
+}
+
+// authenticatedCommand returns the first gh command of script that needs
+// gh to be logged in, as gh and its subcommand, or "".
+func authenticatedCommand(script string) string {
+	for _, m := range ghCommand.FindAllStringSubmatch(script, -1) {
+		if !ghWithoutAuth[m[1]] {
+			return "gh " + m[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGHCLI(t *testing.T) {
+	src := `on: push
+permissions:
+  contents: read
+jobs:
+  release:
+    runs-on: ubuntu-latest
+    steps:
+      - run: gh --version
+      - run: gh release create v1 --generate-notes
+      - run: gh pr create --fill
+        env:
+          GH_TOKEN: ${{ github.token }}
+      - run: gh pr list
+        env:
+          GH_TOKEN: ${{ secrets.BOT_TOKEN }}
+      - run: GH_TOKEN=${{ secrets.BOT_TOKEN }} gh pr merge --auto
+      - run: echo "GH_TOKEN=${{ secrets.BOT_TOKEN }}" >> "$GITHUB_ENV"
+      - run: gh issue comment 1 --body hi
+  cleanup:
+    runs-on: ubuntu-latest
+    env:
+      GH_TOKEN: ${{ secrets.ADMIN_TOKEN }}
+    steps:
+      - run: |
+          gh repo delete old --confirm
+          gh repo list --public --json name
+`
+	rule := NewGHCLI()
+	errs := lintWith(t, func() actionlint.Rule { return rule }, src)
+	require.Len(t, errs, 4)
+	assert.Equal(t, 9, errs[0].Line)
+	assert.Contains(t, errs[0].Message, `script runs "gh release", but neither GH_TOKEN nor GITHUB_TOKEN is set for the step`)
+	assert.Equal(t, 10, errs[1].Line)
+	assert.Contains(t, errs[1].Message, `script runs "gh pr create" with the job's token, which needs "pull-requests: write"`)
+	assert.Equal(t, 24, errs[2].Line)
+	assert.Contains(t, errs[2].Message, "--confirm of gh repo delete")
+	assert.Contains(t, errs[2].Message, "use --yes instead")
+	assert.Contains(t, errs[3].Message, "use --visibility instead")
+
+	require.Len(t, rule.Fixes(), 1)
+	assert.Equal(t, []Edit{{Line: 9, Column: 9, New: "env:\n          GH_TOKEN: ${{ github.token }}\n        "}}, rule.Fixes()[0].Edits)
+}
@@ -0,0 +1,162 @@
+// Command gendeprecated refreshes the generated section of the deprecated
+// inputs dataset. It reads the action.yml of the default branch of every
+// popular action actionlint knows, and lists the inputs that declare a
+// deprecationMessage and are not already in the section maintained by
+// hand.
+//
+//	go run ./internal/gendeprecated -o deprecated-inputs.txt
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rhysd/actionlint"
+	"gopkg.in/yaml.v3"
+)
+
+// marker starts the generated section of the dataset. Lines after it are
+// rewritten.
+const marker = "# Generated section"
+
+// rawBaseURL serves the files of GitHub repositories.
+const rawBaseURL = "https://raw.githubusercontent.com"
+
+// errNotFound is returned for actions without an action.yml or
+// action.yaml.
+var errNotFound = errors.New("not found")
+
+type actionFile struct {
+	Inputs map[string]struct {
+		DeprecationMessage string `yaml:"deprecationMessage"`
+	} `yaml:"inputs"`
+}
+
+func main() {
+	out := flag.String("o", "deprecated-inputs.txt", "dataset to refresh")
+	flag.Parse()
+	if err := run(context.Background(), *out); err != nil {
+		fmt.Fprintln(os.Stderr, "gendeprecated:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	head, _, ok := strings.Cut(string(data), marker)
+	if !ok {
+		return fmt.Errorf("%s has no line starting with %q", file, marker)
+	}
+
+	// Inputs maintained by hand are left to their lines
+	known := map[string]bool{}
+	for _, line := range strings.Split(head, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && !strings.HasPrefix(fields[0], "#") {
+			known[strings.ToLower(fields[0]+" "+fields[1])] = true
+		}
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	var lines []string
+	for _, action := range popularActions() {
+		meta, err := fetchAction(ctx, client, action)
+		if errors.Is(err, errNotFound) {
+			fmt.Fprintf(os.Stderr, "gendeprecated: %s: no action.yml, skipped\n", action)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", action, err)
+		}
+		for name, input := range meta.Inputs {
+			msg := strings.Join(strings.Fields(input.DeprecationMessage), " ")
+			if msg == "" || known[strings.ToLower(action+" "+name)] {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%s %s - - %s", action, name, msg))
+		}
+	}
+	sort.Strings(lines)
+
+	var b strings.Builder
+	b.WriteString(head)
+	b.WriteString(marker + ", rewritten by `make update-deprecated-inputs`.\n")
+	for _, l := range lines {
+		b.WriteString(l + "\n")
+	}
+	return os.WriteFile(file, []byte(b.String()), 0644)
+}
+
+// popularActions returns the actions actionlint knows, without their
+// versions, sorted.
+func popularActions() []string {
+	seen := map[string]bool{}
+	var out []string
+	for spec := range actionlint.PopularActions {
+		name, _, _ := strings.Cut(spec, "@")
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// fetchAction reads the metadata of action, as owner/repo or
+// owner/repo/path, from its default branch.
+func fetchAction(ctx context.Context, client *http.Client, action string) (*actionFile, error) {
+	parts := strings.SplitN(action, "/", 3)
+	if len(parts) < 2 {
+		return nil, errNotFound
+	}
+	dir := parts[0] + "/" + parts[1] + "/HEAD"
+	if len(parts) == 3 {
+		dir += "/" + parts[2]
+	}
+	for _, name := range []string{"action.yml", "action.yaml"} {
+		data, err := get(ctx, client, rawBaseURL+"/"+dir+"/"+name)
+		if errors.Is(err, errNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var meta actionFile
+		if err := yaml.Unmarshal(data, &meta); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return &meta, nil
+	}
+	return nil, errNotFound
+}
+
+func get(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	case http.StatusNotFound:
+		return nil, errNotFound
+	}
+	return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+}
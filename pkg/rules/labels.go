@@ -0,0 +1,117 @@
+package rules
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindUndefinedLabel is the name of the rule checking label names.
+const KindUndefinedLabel = "undefined-label"
+
+var (
+	// labelComparison matches a comparison of the label of a labeled or
+	// unlabeled event with a literal, either way round.
+	labelComparison = regexp.MustCompile(`(?i)\bgithub\s*\.\s*event\s*\.\s*label\s*\.\s*name\s*(?:==|!=)\s*'([^']*)'|'([^']*)'\s*(?:==|!=)\s*github\s*\.\s*event\s*\.\s*label\s*\.\s*name\b`)
+	// labelContains matches a check for a label of the pull request or
+	// issue of the event.
+	labelContains = regexp.MustCompile(`(?i)\bcontains\s*\(\s*github\s*\.\s*event\s*\.\s*(?:pull_request|issue)\s*\.\s*labels\s*\.\s*\*\s*\.\s*name\s*,\s*'([^']*)'\s*\)`)
+	// ghLabelCommand matches a gh command that takes labels.
+	ghLabelCommand = regexp.MustCompile(`\bgh\s+(?:pr|issue)\s+(?:create|edit|list)\b[^\n;|&]*`)
+	// ghLabelFlag matches a label flag of a gh command and its value.
+	ghLabelFlag = regexp.MustCompile(`(?:\s--(?:add-label|remove-label|label)|\s-l)(?:\s+|=)(?:"([^"]*)"|'([^']*)'|([^\s"']+))`)
+)
+
+// RuleUndefinedLabel flags label names that the repository does not have:
+// those compared with the label of a labeled event or looked for among the
+// labels of a pull request or issue in expressions, and those given to the
+// label flags of gh pr and gh issue in run: scripts. A condition on a
+// label that does not exist never holds, and gh fails to add it. Label
+// names are compared ignoring case, as GitHub does.
+type RuleUndefinedLabel struct {
+	actionlint.RuleBase
+	defined map[string]bool
+}
+
+// NewUndefinedLabel creates a RuleUndefinedLabel accepting the given label
+// names. A nil list disables the rule, since labels are only known when
+// they were listed or fetched.
+func NewUndefinedLabel(defined []string) *RuleUndefinedLabel {
+	rule := &RuleUndefinedLabel{
+		RuleBase: actionlint.NewRuleBase(KindUndefinedLabel, "Checks for label names the repository does not have"),
+	}
+	if defined != nil {
+		rule.defined = make(map[string]bool, len(defined))
+		for _, name := range defined {
+			rule.defined[strings.ToLower(name)] = true
+		}
+	}
+	return rule
+}
+
+// VisitJobPre checks the expressions and run: scripts of the job.
+func (rule *RuleUndefinedLabel) VisitJobPre(n *actionlint.Job) error {
+	if rule.defined == nil {
+		return nil
+	}
+	conditions := map[*actionlint.String]bool{n.If: true}
+	for _, s := range n.Steps {
+		conditions[s.If] = true
+	}
+	var strs []*actionlint.String
+	collectStrings(reflect.ValueOf(n), &strs)
+	for _, s := range strs {
+		exprs := []string{s.Value}
+		if !conditions[s] || strings.Contains(s.Value, "${{") {
+			exprs = exprs[:0]
+			for _, m := range expressionBody.FindAllStringSubmatch(s.Value, -1) {
+				exprs = append(exprs, m[1])
+			}
+		}
+		var names []string
+		for _, e := range exprs {
+			for _, m := range labelComparison.FindAllStringSubmatch(e, -1) {
+				names = append(names, m[1]+m[2])
+			}
+			for _, m := range labelContains.FindAllStringSubmatch(e, -1) {
+				names = append(names, m[1])
+			}
+		}
+		rule.check(s.Pos, names, "so the condition on it never holds")
+	}
+
+	for _, s := range n.Steps {
+		exec, ok := s.Exec.(*actionlint.ExecRun)
+		if !ok || exec.Run == nil {
+			continue
+		}
+		var names []string
+		for _, cmd := range ghLabelCommand.FindAllString(exec.Run.Value, -1) {
+			for _, m := range ghLabelFlag.FindAllStringSubmatch(cmd, -1) {
+				for _, name := range strings.Split(m[1]+m[2]+m[3], ",") {
+					if name = strings.TrimSpace(name); name != "" && !strings.ContainsAny(name, "${}") {
+						names = append(names, name)
+					}
+				}
+			}
+		}
+		rule.check(exec.Run.Pos, names, "so the gh command fails or, when listing, finds nothing")
+	}
+	return nil
+}
+
+// check reports the names the repository does not have, once each,
+// saying what consequence it has.
+func (rule *RuleUndefinedLabel) check(pos *actionlint.Pos, names []string, consequence string) {
+	seen := map[string]bool{}
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		if rule.defined[lower] || seen[lower] {
+			continue
+		}
+		seen[lower] = true
+		rule.Errorf(pos, "label %q does not exist in the repository, %s", name, consequence)
+	}
+}
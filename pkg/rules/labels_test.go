@@ -0,0 +1,43 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const labelsWorkflow = `on:
+  pull_request:
+    types: [labeled]
+jobs:
+  deploy:
+    if: github.event.label.name == 'Deploy' || 'ready-to-ship' == github.event.label.name
+    runs-on: ubuntu-latest
+    steps:
+      - if: contains(github.event.pull_request.labels.*.name, 'skip-tests')
+        run: echo skipping
+      - run: |
+          gh pr edit "$PR" --add-label "needs review,deploy" --remove-label=wip
+          gh issue list -l bug --state open
+          gh pr edit "$PR" --add-label "${{ inputs.label }}" --label "$LABEL"
+        env:
+          PR: ${{ github.event.pull_request.number }}
+      - run: echo "--label not-a-gh-flag"
+`
+
+func TestUndefinedLabel(t *testing.T) {
+	assert.Empty(t, lintWith(t, func() actionlint.Rule { return NewUndefinedLabel(nil) }, labelsWorkflow), "disabled without labels")
+
+	errs := lintWith(t, func() actionlint.Rule { return NewUndefinedLabel([]string{"deploy", "bug"}) }, labelsWorkflow)
+	require.Len(t, errs, 4)
+	assert.Equal(t, KindUndefinedLabel, errs[0].Kind)
+	assert.Equal(t, `label "ready-to-ship" does not exist in the repository, so the condition on it never holds`, errs[0].Message)
+	assert.Equal(t, 6, errs[0].Line)
+	assert.Contains(t, errs[1].Message, `"skip-tests"`)
+	assert.Equal(t, 9, errs[1].Line)
+	assert.Contains(t, errs[2].Message, `label "needs review" does not exist in the repository, so the gh command fails`)
+	assert.Equal(t, 11, errs[2].Line)
+	assert.Contains(t, errs[3].Message, `"wip"`)
+}
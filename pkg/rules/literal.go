@@ -0,0 +1,138 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+	"gopkg.in/yaml.v3"
+)
+
+// KindLiteralExpression is the name of RuleLiteralExpression.
+const KindLiteralExpression = "literal-expression"
+
+// RuleLiteralExpression flags ${{ }} expressions that only yield a
+// constant, such as ${{ 'main' }} or ${{ 10 }}, which read as if something
+// were evaluated. Offers a fix writing the constant in their place, unless
+// the plain YAML would no longer be the same string: ${{ 'true' }} as a
+// whole value is the string "true", where true is a boolean, which is why
+// such wrappers are sometimes written, so those are reported without a
+// fix. if: conditions are left to constant-condition.
+type RuleLiteralExpression struct {
+	actionlint.RuleBase
+	fixes
+}
+
+// NewLiteralExpression creates a RuleLiteralExpression.
+func NewLiteralExpression() *RuleLiteralExpression {
+	return &RuleLiteralExpression{
+		RuleBase: actionlint.NewRuleBase(KindLiteralExpression, "Checks for expressions that only yield a constant"),
+	}
+}
+
+// VisitWorkflowPre checks every string of the workflow but its conditions.
+func (rule *RuleLiteralExpression) VisitWorkflowPre(n *actionlint.Workflow) error {
+	conditions := map[*actionlint.String]bool{}
+	for _, job := range n.Jobs {
+		conditions[job.If] = true
+		for _, s := range job.Steps {
+			conditions[s.If] = true
+		}
+	}
+	for _, s := range Strings(n) {
+		if !conditions[s] && s.ContainsExpression() {
+			rule.check(s)
+		}
+	}
+	return nil
+}
+
+// check reports the literal expressions of s, once per string.
+func (rule *RuleLiteralExpression) check(s *actionlint.String) {
+	var edits []Edit
+	var first string
+	count := 0
+	fixable := true
+	for _, m := range expressionBody.FindAllStringSubmatch(s.Value, -1) {
+		expr, err := actionlint.NewExprParser().Parse(actionlint.NewExprLexer(m[1] + "}}"))
+		if err != nil {
+			continue
+		}
+		value, what, ok := literalText(expr)
+		if !ok {
+			continue
+		}
+		count++
+		if first == "" {
+			first = fmt.Sprintf("%s always yields %s", m[0], what)
+		}
+		if s.IsExpressionAssigned() && !plainString(value, expr) {
+			fixable = false
+		}
+		edits = append(edits, Edit{Line: s.Pos.Line, Column: s.Pos.Col, Old: m[0], New: value})
+	}
+	if count == 0 {
+		return
+	}
+	msg := first
+	switch {
+	case count == 2:
+		msg += ", and so does 1 other expression of the value"
+	case count > 2:
+		msg += fmt.Sprintf(", and so do %d other expressions of the value", count-1)
+	}
+	if !fixable {
+		rule.Errorf(s.Pos, "%s. it is the whole value, which YAML would not read as the same string without ${{ }}, so quote the constant instead", msg)
+		return
+	}
+	rule.Errorf(s.Pos, "%s. write the constant without ${{ }}", msg)
+	rule.addFix(KindLiteralExpression, s.Pos, "Write the constant in place of the expression", edits...)
+}
+
+// literalText returns the text a literal expression is replaced with in a
+// string, as GitHub converts it, and describes it. ok is false for other
+// expressions.
+func literalText(expr actionlint.ExprNode) (text, what string, ok bool) {
+	switch n := expr.(type) {
+	case *actionlint.StringNode:
+		return n.Value, fmt.Sprintf("the string %q", n.Value), true
+	case *actionlint.IntNode:
+		v := strconv.Itoa(n.Value)
+		return v, "the number " + v, true
+	case *actionlint.FloatNode:
+		v := strconv.FormatFloat(n.Value, 'f', -1, 64)
+		return v, "the number " + v, true
+	case *actionlint.BoolNode:
+		v := strconv.FormatBool(n.Value)
+		return v, "the boolean " + v, true
+	case *actionlint.NullNode:
+		return "", "null, an empty string", true
+	}
+	return "", "", false
+}
+
+// plainString reports whether text, written as a plain YAML value, reads as
+// the value of the literal expr: the same string, number or boolean.
+func plainString(text string, expr actionlint.ExprNode) bool {
+	if text == "" || strings.Contains(text, "${{") {
+		return false
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(text), &node); err != nil || len(node.Content) != 1 {
+		return false
+	}
+	v := node.Content[0]
+	if v.Kind != yaml.ScalarNode || v.Style != 0 || v.Value != text {
+		return false
+	}
+	switch expr.(type) {
+	case *actionlint.StringNode:
+		return v.Tag == "!!str"
+	case *actionlint.IntNode, *actionlint.FloatNode:
+		return v.Tag == "!!int" || v.Tag == "!!float"
+	case *actionlint.BoolNode:
+		return v.Tag == "!!bool"
+	}
+	return false
+}
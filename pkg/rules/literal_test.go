@@ -0,0 +1,53 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiteralExpression(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+		fix   []string
+	}{
+		{value: `${{ github.ref_name }}`},
+		{value: `${{ format('v{0}', github.run_number) }}`},
+		{value: `plain`},
+		{value: `${{ 'main' }}`, want: `${{ 'main' }} always yields the string "main". write the constant without ${{ }}`, fix: []string{"main"}},
+		{value: `release-${{ 'it''s' }}-${{ 10 }}`, want: `${{ 'it''s' }} always yields the string "it's", and so does 1 other expression of the value`, fix: []string{"it's", "10"}},
+		{value: `${{ 3.50 }}`, want: "the number 3.5", fix: []string{"3.5"}},
+		{value: `x${{ null }}`, want: "null, an empty string", fix: []string{""}},
+		{value: `${{ 'true' }}`, want: `always yields the string "true". it is the whole value, which YAML would not read as the same string`},
+		{value: `${{ 'a: b' }}`, want: "quote the constant instead"},
+		{value: `${{ '' }}`, want: "quote the constant instead"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    if: ${{ 'main' }}\n    env:\n      VALUE: \"" + tt.value + "\"\n    steps:\n      - run: echo hi\n"
+			rule := NewLiteralExpression()
+			errs := lintWith(t, func() actionlint.Rule { return rule }, src)
+			if tt.want == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			require.Len(t, errs, 1)
+			assert.Equal(t, KindLiteralExpression, errs[0].Kind)
+			assert.Contains(t, errs[0].Message, tt.want)
+			if tt.fix == nil {
+				assert.Empty(t, rule.Fixes())
+				return
+			}
+			require.Len(t, rule.Fixes(), 1)
+			var got []string
+			for _, e := range rule.Fixes()[0].Edits {
+				got = append(got, e.New)
+			}
+			assert.Equal(t, tt.fix, got)
+		})
+	}
+}
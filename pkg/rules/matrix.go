@@ -0,0 +1,266 @@
+package rules
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// Rule names, reported as the kind of their findings.
+const (
+	KindMatrixSize    = "matrix-size"
+	KindMatrixInclude = "matrix-include"
+)
+
+// DefaultMaxMatrixCombinations is GitHub's limit on the number of jobs a
+// single matrix may generate.
+const DefaultMaxMatrixCombinations = 256
+
+// maxEnumerated bounds the combinations enumerated to count a matrix
+// exactly. Larger matrices are reported without applying exclusions.
+const maxEnumerated = 1 << 16
+
+// MatrixConfig configures the matrix rules.
+type MatrixConfig struct {
+	// MaxCombinations is the largest number of combinations a matrix may
+	// expand to. Zero means DefaultMaxMatrixCombinations.
+	MaxCombinations int `yaml:"max-combinations"`
+}
+
+// combination is one job of an expanded matrix, keyed by lower-case
+// matrix key like actionlint.Matrix.Rows.
+type combination map[string]actionlint.RawYAMLValue
+
+// RuleMatrixSize flags matrices that expand to more combinations than
+// allowed, counting exclude and include entries the way GitHub does.
+type RuleMatrixSize struct {
+	actionlint.RuleBase
+	max int
+}
+
+// NewMatrixSize creates a RuleMatrixSize.
+func NewMatrixSize(cfg MatrixConfig) *RuleMatrixSize {
+	max := cfg.MaxCombinations
+	if max <= 0 {
+		max = DefaultMaxMatrixCombinations
+	}
+	return &RuleMatrixSize{
+		RuleBase: actionlint.NewRuleBase(KindMatrixSize, "Checks that matrices do not expand to too many jobs"),
+		max:      max,
+	}
+}
+
+// VisitJobPre checks the matrix of the job.
+func (rule *RuleMatrixSize) VisitJobPre(n *actionlint.Job) error {
+	m := staticMatrix(n)
+	if m == nil {
+		return nil
+	}
+
+	size := 1
+	for _, row := range m.Rows {
+		size *= len(row.Values)
+		if size > maxEnumerated {
+			if rule.max < maxEnumerated {
+				rule.Errorf(m.Pos, "matrix expands to more than %d combinations before exclusions, more than the limit of %d", maxEnumerated, rule.max)
+			}
+			return nil
+		}
+	}
+
+	if count := countCombinations(m); count > rule.max {
+		rule.Errorf(m.Pos, "matrix expands to %d combinations, more than the limit of %d", count, rule.max)
+	}
+	return nil
+}
+
+// RuleMatrixInclude flags include entries that set only some of the matrix
+// keys and match no combination. GitHub runs such an entry as an extra job
+// where the keys it does not set are empty, rather than extending the jobs
+// it was meant to extend.
+type RuleMatrixInclude struct {
+	actionlint.RuleBase
+}
+
+// NewMatrixInclude creates a RuleMatrixInclude.
+func NewMatrixInclude() *RuleMatrixInclude {
+	return &RuleMatrixInclude{
+		RuleBase: actionlint.NewRuleBase(KindMatrixInclude, "Checks that matrix include entries extend the combinations they name"),
+	}
+}
+
+// VisitJobPre checks the include entries of the job's matrix.
+func (rule *RuleMatrixInclude) VisitJobPre(n *actionlint.Job) error {
+	m := staticMatrix(n)
+	if m == nil || len(m.Rows) == 0 || m.Include == nil {
+		return nil
+	}
+
+	for _, inc := range m.Include.Combinations {
+		var missing, unknown []string
+		for key := range m.Rows {
+			a, ok := inc.Assigns[key]
+			if !ok {
+				missing = append(missing, key)
+			} else if !rowHasValue(m.Rows[key], a.Value) {
+				unknown = append(unknown, key)
+			}
+		}
+		if len(missing) == 0 || len(unknown) == 0 {
+			continue // A full combination or one that extends existing jobs
+		}
+
+		sort.Strings(missing)
+		sort.Strings(unknown)
+		a := inc.Assigns[unknown[0]]
+		rule.Errorf(
+			a.Value.Pos(),
+			"matrix include entry matches no combination because %s %s is not a value of the matrix. it runs as an extra job without %s instead of extending existing jobs",
+			a.Key.Value,
+			a.Value.String(),
+			strings.Join(missing, ", "),
+		)
+	}
+	return nil
+}
+
+// staticMatrix returns the job's matrix when it is written out in full, or
+// nil when there is none or any part of it is built by an expression.
+func staticMatrix(n *actionlint.Job) *actionlint.Matrix {
+	if n.Strategy == nil || n.Strategy.Matrix == nil {
+		return nil
+	}
+	m := n.Strategy.Matrix
+	if m.Expression != nil {
+		return nil
+	}
+	for _, row := range m.Rows {
+		if row.Expression != nil || row.Values == nil {
+			return nil
+		}
+	}
+	if m.Include != nil && m.Include.ContainsExpression() {
+		return nil
+	}
+	if m.Exclude != nil && m.Exclude.ContainsExpression() {
+		return nil
+	}
+	return m
+}
+
+// countCombinations expands m: the product of its rows, less the excluded
+// combinations, plus include entries that match no combination.
+func countCombinations(m *actionlint.Matrix) int {
+	kept := expandMatrix(m)
+	count := len(kept)
+	if m.Include != nil {
+		for _, inc := range m.Include.Combinations {
+			if !extendsAny(kept, inc, m.Rows) {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// expandMatrix returns the combinations of the rows of m that are not
+// excluded, before include entries extend them.
+func expandMatrix(m *actionlint.Matrix) []combination {
+	keys := make([]string, 0, len(m.Rows))
+	for key := range m.Rows {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	combos := []combination{}
+	if len(keys) > 0 {
+		combos = append(combos, combination{})
+		for _, key := range keys {
+			next := make([]combination, 0, len(combos)*len(m.Rows[key].Values))
+			for _, c := range combos {
+				for _, v := range m.Rows[key].Values {
+					nc := make(combination, len(c)+1)
+					for k, cv := range c {
+						nc[k] = cv
+					}
+					nc[key] = v
+					next = append(next, nc)
+				}
+			}
+			combos = next
+		}
+	}
+
+	kept := combos[:0]
+	for _, c := range combos {
+		if m.Exclude == nil || !anyMatches(c, m.Exclude.Combinations, nil) {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// anyMatches reports whether one of filters matches c. When rows is not
+// nil, only keys of the matrix rows are compared.
+func anyMatches(c combination, filters []*actionlint.MatrixCombination, rows map[string]*actionlint.MatrixRow) bool {
+	for _, f := range filters {
+		if matches(c, f, rows) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(c combination, f *actionlint.MatrixCombination, rows map[string]*actionlint.MatrixRow) bool {
+	for key, a := range f.Assigns {
+		if rows != nil {
+			if _, ok := rows[key]; !ok {
+				continue
+			}
+		}
+		v, ok := c[key]
+		if !ok || !valueMatches(v, a.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// extendsAny reports whether the include entry inc adds its values to at
+// least one of combos, which it does when it does not change any of their
+// original matrix values.
+func extendsAny(combos []combination, inc *actionlint.MatrixCombination, rows map[string]*actionlint.MatrixRow) bool {
+	for _, c := range combos {
+		if matches(c, inc, rows) {
+			return true
+		}
+	}
+	return false
+}
+
+func rowHasValue(row *actionlint.MatrixRow, v actionlint.RawYAMLValue) bool {
+	for _, rv := range row.Values {
+		if valueMatches(rv, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// valueMatches reports whether v matches filter. Objects match when every
+// property of filter matches, as for exclude entries.
+func valueMatches(v, filter actionlint.RawYAMLValue) bool {
+	vo, ok1 := v.(*actionlint.RawYAMLObject)
+	fo, ok2 := filter.(*actionlint.RawYAMLObject)
+	if !ok1 || !ok2 {
+		return v.Equals(filter)
+	}
+	for name, fp := range fo.Props {
+		vp, ok := vo.Props[name]
+		if !ok || !valueMatches(vp, fp) {
+			return false
+		}
+	}
+	return true
+}
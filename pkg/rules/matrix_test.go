@@ -0,0 +1,121 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func matrixWorkflow(strategy string) string {
+	return `on: push
+jobs:
+  test:
+    runs-on: ${{ matrix.os }}
+    strategy:
+      matrix:
+` + strategy + `
+    steps:
+      - run: echo hi
+`
+}
+
+func TestMatrixSize(t *testing.T) {
+	limit := func(max int) func() actionlint.Rule {
+		return func() actionlint.Rule { return NewMatrixSize(MatrixConfig{MaxCombinations: max}) }
+	}
+
+	tests := []struct {
+		name   string
+		matrix string
+		max    int
+		want   string
+	}{
+		{
+			name:   "within default limit",
+			matrix: "        os: [a, b, c]\n        node: [1, 2, 3]",
+		},
+		{
+			name:   "over configured limit",
+			matrix: "        os: [a, b, c]\n        node: [1, 2, 3]",
+			max:    8,
+			want:   "matrix expands to 9 combinations, more than the limit of 8",
+		},
+		{
+			name:   "exclusions are subtracted",
+			matrix: "        os: [a, b, c]\n        node: [1, 2, 3]\n        exclude:\n          - os: a",
+			max:    8,
+		},
+		{
+			name:   "unmatched includes add jobs",
+			matrix: "        os: [a, b]\n        node: [1, 2]\n        include:\n          - os: c\n            node: 3\n          - os: a\n            extra: true",
+			max:    4,
+			want:   "matrix expands to 5 combinations",
+		},
+		{
+			name:   "over GitHub limit",
+			matrix: "        a: [1, 2, 3, 4, 5, 6, 7, 8, 9]\n        b: [1, 2, 3, 4, 5, 6, 7, 8, 9]\n        c: [1, 2, 3, 4]",
+			want:   "matrix expands to 324 combinations, more than the limit of 256",
+		},
+		{
+			name:   "expressions are skipped",
+			matrix: "        os: ${{ fromJSON(vars.OS) }}\n        node: [1, 2, 3]",
+			max:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := lintWith(t, limit(tt.max), matrixWorkflow(tt.matrix))
+			if tt.want == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			require.Len(t, errs, 1)
+			assert.Equal(t, KindMatrixSize, errs[0].Kind)
+			assert.Contains(t, errs[0].Message, tt.want)
+		})
+	}
+}
+
+func TestMatrixInclude(t *testing.T) {
+	newRule := func() actionlint.Rule { return NewMatrixInclude() }
+
+	tests := []struct {
+		name   string
+		matrix string
+		want   string
+	}{
+		{
+			name:   "extends matching combinations",
+			matrix: "        os: [ubuntu, windows]\n        node: [18, 20]\n        include:\n          - os: windows\n            shell: pwsh",
+		},
+		{
+			name:   "full new combination",
+			matrix: "        os: [ubuntu, windows]\n        node: [18, 20]\n        include:\n          - os: macos\n            node: 20",
+		},
+		{
+			name:   "extends every combination",
+			matrix: "        os: [ubuntu, windows]\n        include:\n          - experimental: false",
+		},
+		{
+			name:   "typo in partial entry",
+			matrix: "        os: [ubuntu, windows]\n        node: [18, 20]\n        include:\n          - os: windwos\n            shell: pwsh",
+			want:   `because os "windwos" is not a value of the matrix. it runs as an extra job without node`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := lintWith(t, newRule, matrixWorkflow(tt.matrix))
+			if tt.want == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			require.Len(t, errs, 1)
+			assert.Equal(t, KindMatrixInclude, errs[0].Kind)
+			assert.Contains(t, errs[0].Message, tt.want)
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindMatrixOS is the name of RuleMatrixOS.
+const KindMatrixOS = "matrix-os"
+
+// runnerOSNames are the values of runner.os for each operating system.
+var runnerOSNames = map[string]string{"linux": "Linux", "macos": "macOS", "windows": "Windows"}
+
+// RuleMatrixOS flags conditions and matrices that do not fit the runners
+// a job runs on: steps whose if: compares runner.os, or the matrix value
+// runs-on reads, with values none of the job's runners has, so they never
+// run, and matrices that set the key runs-on reads in include entries
+// that only extend some combinations, which then run without a runner.
+// actionlint reports matrix keys the matrix does not define at all.
+type RuleMatrixOS struct {
+	actionlint.RuleBase
+}
+
+// NewMatrixOS creates a RuleMatrixOS.
+func NewMatrixOS() *RuleMatrixOS {
+	return &RuleMatrixOS{
+		RuleBase: actionlint.NewRuleBase(KindMatrixOS, "Checks that OS conditions and matrices fit the runners of the job"),
+	}
+}
+
+// VisitJobPre checks the job's matrix and the conditions of its steps.
+func (rule *RuleMatrixOS) VisitJobPre(n *actionlint.Job) error {
+	runners, fromMatrix := jobRunners(n)
+	if fromMatrix {
+		rule.checkMatrix(n, runners)
+	}
+	if len(runners) == 0 {
+		return nil
+	}
+	labels := make([]string, len(runners))
+	for i, r := range runners {
+		labels[i] = r.label
+	}
+	for _, s := range n.Steps {
+		if on, _ := runnersOf(s, runners); s.If == nil || len(on) > 0 {
+			continue
+		}
+		if cond := parseCondition(s.If); cond != nil {
+			if _, known := constantValue(cond); known {
+				continue // Reported by constant-condition
+			}
+		}
+		if len(runners) == 1 {
+			rule.Errorf(s.If.Pos, "step never runs because its if: condition does not hold on %s, the only runner job %q runs on", labels[0], n.ID.Value)
+		} else {
+			rule.Errorf(s.If.Pos, "step never runs because its if: condition holds on none of the runners job %q runs on: %s", n.ID.Value, strings.Join(labels, ", "))
+		}
+	}
+	return nil
+}
+
+// checkMatrix reports combinations of the matrix that the key runs-on
+// reads is not set for, as when only some include entries set it.
+func (rule *RuleMatrixOS) checkMatrix(n *actionlint.Job, runners []jobRunner) {
+	m := staticMatrix(n)
+	if m == nil || len(runners) == 0 || len(m.Rows) == 0 || m.Include == nil {
+		return
+	}
+	key := runners[0].key
+	if _, ok := m.Rows[key]; ok {
+		return
+	}
+	size := 1
+	for _, row := range m.Rows {
+		if size *= len(row.Values); size > maxEnumerated {
+			return
+		}
+	}
+
+	var unset []string
+	for _, c := range expandMatrix(m) {
+		set := false
+		for _, inc := range m.Include.Combinations {
+			if _, ok := inc.Assigns[key]; ok && matches(c, inc, m.Rows) {
+				set = true
+				break
+			}
+		}
+		if !set {
+			unset = append(unset, formatCombination(c))
+		}
+	}
+	if len(unset) == 0 {
+		return
+	}
+	pos := n.ID.Pos
+	if n.RunsOn.LabelsExpr != nil {
+		pos = n.RunsOn.LabelsExpr.Pos
+	} else {
+		for _, l := range n.RunsOn.Labels {
+			if matrixReference.MatchString(l.Value) {
+				pos = l.Pos
+				break
+			}
+		}
+	}
+	unsetBy := "combination " + unset[0] + " does"
+	if len(unset) > 1 {
+		unsetBy = fmt.Sprintf("%d combinations, such as %s, do", len(unset), unset[0])
+	}
+	rule.Errorf(pos, "runs-on reads matrix.%s, which only include entries set, and %s not set it, so it gets no runner. set %s in every combination, or make it a row of the matrix", key, unsetBy, key)
+}
+
+// formatCombination writes c as {key: value, ...}, sorted by key.
+func formatCombination(c combination) string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + ": " + c[k].String()
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// runnersOf returns the runners of runners that step may run on, judging
+// by the comparisons of runner.os and of the matrix value runs-on reads
+// in its if: condition. Runners the condition cannot be told for are
+// kept, and known is false when there are any.
+func runnersOf(step *actionlint.Step, runners []jobRunner) (on []jobRunner, known bool) {
+	cond := parseCondition(step.If)
+	if cond == nil {
+		return runners, step.If == nil
+	}
+	known = true
+	for _, r := range runners {
+		v, ok := runnerCondition(cond, r)
+		if v || !ok {
+			on = append(on, r)
+		}
+		known = known && ok
+	}
+	return on, known
+}
+
+// runnerCondition evaluates the truthiness of n on runner r when it only
+// depends on runner.os and the matrix value runs-on reads.
+func runnerCondition(n actionlint.ExprNode, r jobRunner) (value, known bool) {
+	switch n := n.(type) {
+	case *actionlint.NotOpNode:
+		v, ok := runnerCondition(n.Operand, r)
+		return !v, ok
+	case *actionlint.LogicalOpNode:
+		l, lok := runnerCondition(n.Left, r)
+		rv, rok := runnerCondition(n.Right, r)
+		if n.Kind == actionlint.LogicalOpNodeKindAnd {
+			if (lok && !l) || (rok && !rv) {
+				return false, true
+			}
+			return true, lok && rok
+		}
+		if (lok && l) || (rok && rv) {
+			return true, true
+		}
+		return false, lok && rok
+	case *actionlint.CompareOpNode:
+		l, lok := runnerString(n.Left, r)
+		rv, rok := runnerString(n.Right, r)
+		if !lok || !rok {
+			return false, false
+		}
+		switch n.Kind {
+		case actionlint.CompareOpNodeKindEq:
+			return strings.EqualFold(l, rv), true
+		case actionlint.CompareOpNodeKindNotEq:
+			return !strings.EqualFold(l, rv), true
+		}
+		return false, false
+	case *actionlint.FuncCallNode:
+		if len(n.Args) != 2 {
+			return false, false
+		}
+		s, sok := runnerString(n.Args[0], r)
+		sub, subok := runnerString(n.Args[1], r)
+		if !sok || !subok {
+			return false, false
+		}
+		s, sub = strings.ToLower(s), strings.ToLower(sub)
+		switch strings.ToLower(n.Callee) {
+		case "startswith":
+			return strings.HasPrefix(s, sub), true
+		case "endswith":
+			return strings.HasSuffix(s, sub), true
+		case "contains":
+			return strings.Contains(s, sub), true
+		}
+		return false, false
+	}
+	return constantValue(n)
+}
+
+// runnerString returns the value of n on runner r when it is a string
+// literal, runner.os or the matrix value runs-on reads.
+func runnerString(n actionlint.ExprNode, r jobRunner) (string, bool) {
+	switch n := n.(type) {
+	case *actionlint.StringNode:
+		return n.Value, true
+	case *actionlint.ObjectDerefNode:
+		v, ok := n.Receiver.(*actionlint.VariableNode)
+		if !ok {
+			return "", false
+		}
+		switch {
+		case strings.EqualFold(v.Name, "runner") && strings.EqualFold(n.Property, "os") && r.os != "":
+			return runnerOSNames[r.os], true
+		case strings.EqualFold(v.Name, "matrix") && r.key != "" && strings.EqualFold(n.Property, r.key):
+			return r.value, true
+		}
+	}
+	return "", false
+}
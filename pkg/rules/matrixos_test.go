@@ -0,0 +1,99 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatrixOS(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    strategy:
+      matrix:
+        os: [macos-latest, windows-latest]
+    runs-on: ${{ matrix.os }}
+    steps:
+      - if: runner.os == 'Linux'
+        run: sudo apt-get update
+      - if: matrix.os == 'ubuntu-latest' && github.event_name == 'push'
+        run: echo never
+      - if: startsWith(matrix.os, 'windows') || runner.os == 'macOS'
+        run: echo always
+      - if: runner.os != 'Windows' && env.DEBUG
+        run: echo sometimes
+      - if: false
+        run: echo reported by constant-condition
+  linux:
+    runs-on: ubuntu-latest
+    steps:
+      - if: runner.os == 'windows'
+        run: echo never
+  self-hosted:
+    runs-on: [self-hosted, gpu]
+    steps:
+      - if: runner.os == 'Windows'
+        run: echo unknown
+  include:
+    strategy:
+      matrix:
+        node: [18, 20, 22]
+        include:
+          - node: 18
+            os: ubuntu-latest
+          - node: 22
+            os: windows-latest
+    runs-on: ${{ matrix.os }}
+    steps:
+      - run: npm test
+  rows:
+    strategy:
+      matrix:
+        os: [ubuntu-latest]
+        include:
+          - os: windows-latest
+    runs-on: ${{ matrix.os }}
+    steps:
+      - run: npm test
+`
+	errs := lintWith(t, func() actionlint.Rule { return NewMatrixOS() }, src)
+	require.Len(t, errs, 4)
+
+	assert.Equal(t, 9, errs[0].Line)
+	assert.Equal(t, KindMatrixOS, errs[0].Kind)
+	assert.Contains(t, errs[0].Message, `step never runs because its if: condition holds on none of the runners job "test" runs on: macos-latest, windows-latest`)
+	assert.Equal(t, 11, errs[1].Line)
+	assert.Equal(t, 22, errs[2].Line)
+	assert.Contains(t, errs[2].Message, `does not hold on ubuntu-latest, the only runner job "linux" runs on`)
+	assert.Equal(t, 38, errs[3].Line)
+	assert.Contains(t, errs[3].Message, `runs-on reads matrix.os, which only include entries set, and combination {node: "20"} does not set it`)
+}
+
+func TestRunnersOf(t *testing.T) {
+	runners := []jobRunner{
+		{label: "ubuntu-latest", os: "linux", key: "os", value: "ubuntu-latest"},
+		{label: "windows-latest", os: "windows", key: "os", value: "windows-latest"},
+	}
+	for cond, want := range map[string][]string{
+		"runner.os == 'Linux'":                 {"ubuntu-latest"},
+		"${{ runner.os != 'linux' }}":          {"windows-latest"},
+		"!contains(matrix.os, 'ubuntu')":       {"windows-latest"},
+		"endsWith(matrix.os, '-latest')":       {"ubuntu-latest", "windows-latest"},
+		"runner.os == 'macOS'":                 nil,
+		"runner.os == 'Linux' || env.ANYWHERE": {"ubuntu-latest", "windows-latest"},
+	} {
+		step := &actionlint.Step{If: &actionlint.String{Value: cond}}
+		on, _ := runnersOf(step, runners)
+		var labels []string
+		for _, r := range on {
+			labels = append(labels, r.label)
+		}
+		assert.Equal(t, want, labels, cond)
+	}
+
+	_, known := runnersOf(&actionlint.Step{If: &actionlint.String{Value: "runner.os == 'Linux' && env.X"}}, runners)
+	assert.False(t, known)
+}
@@ -0,0 +1,181 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/rhysd/actionlint"
+)
+
+// Naming rule names. They form the naming- family of kinds.
+const (
+	KindNamingWorkflowName = "naming-workflow-name"
+	KindNamingJobID        = "naming-job-id"
+	KindNamingStepName     = "naming-step-name"
+	KindNamingEnvVar       = "naming-env-var"
+)
+
+// NamingConfig configures the naming rules. A rule only runs when its
+// convention sets a pattern.
+type NamingConfig struct {
+	WorkflowName NamingConvention `yaml:"workflow-name"`
+	JobID        NamingConvention `yaml:"job-id"`
+	StepName     NamingConvention `yaml:"step-name"`
+	EnvVar       NamingConvention `yaml:"env-var"`
+}
+
+// NamingConvention is a pair of regular expressions a name is checked
+// against. Go regular expressions cannot express negation, so forbidden
+// names have their own pattern.
+type NamingConvention struct {
+	// Pattern must match every name.
+	Pattern string `yaml:"pattern"`
+	// Forbid must not match any name.
+	Forbid string `yaml:"forbid"`
+}
+
+func (c NamingConvention) enabled() bool {
+	return c.Pattern != "" || c.Forbid != ""
+}
+
+// conventions returns the configured conventions by rule name.
+func (c NamingConfig) conventions() map[string]NamingConvention {
+	return map[string]NamingConvention{
+		KindNamingWorkflowName: c.WorkflowName,
+		KindNamingJobID:        c.JobID,
+		KindNamingStepName:     c.StepName,
+		KindNamingEnvVar:       c.EnvVar,
+	}
+}
+
+// Validate reports patterns that are not valid regular expressions.
+func (c NamingConfig) Validate() error {
+	for name, conv := range c.conventions() {
+		for _, p := range []string{conv.Pattern, conv.Forbid} {
+			if _, err := compilePattern(p); err != nil {
+				return fmt.Errorf("invalid %s pattern %q: %w", name, p, err)
+			}
+		}
+	}
+	return nil
+}
+
+// patterns caches compiled patterns, since rules are created for every
+// workflow that is linted.
+var patterns sync.Map
+
+func compilePattern(p string) (*regexp.Regexp, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if re, ok := patterns.Load(p); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(p)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(p, re)
+	return re, nil
+}
+
+// RuleNaming checks one kind of name against a configured convention.
+type RuleNaming struct {
+	actionlint.RuleBase
+	what    string
+	pattern *regexp.Regexp
+	forbid  *regexp.Regexp
+}
+
+// NewNaming returns the naming rules that cfg configures, sorted by name.
+// Conventions with invalid patterns are skipped; Validate reports them.
+func NewNaming(cfg NamingConfig) []actionlint.Rule {
+	var out []actionlint.Rule
+	convs := cfg.conventions()
+	names := make([]string, 0, len(convs))
+	for name := range convs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	descriptions := map[string]string{
+		KindNamingWorkflowName: "workflow name",
+		KindNamingJobID:        "job ID",
+		KindNamingStepName:     "step name",
+		KindNamingEnvVar:       "environment variable",
+	}
+	for _, name := range names {
+		conv := convs[name]
+		if !conv.enabled() {
+			continue
+		}
+		pattern, err1 := compilePattern(conv.Pattern)
+		forbid, err2 := compilePattern(conv.Forbid)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		out = append(out, &RuleNaming{
+			RuleBase: actionlint.NewRuleBase(name, "Checks that each "+descriptions[name]+" follows the naming convention"),
+			what:     descriptions[name],
+			pattern:  pattern,
+			forbid:   forbid,
+		})
+	}
+	return out
+}
+
+// VisitWorkflowPre checks the workflow name and workflow-level env.
+func (rule *RuleNaming) VisitWorkflowPre(n *actionlint.Workflow) error {
+	switch rule.Name() {
+	case KindNamingWorkflowName:
+		rule.check(n.Name)
+	case KindNamingEnvVar:
+		rule.checkEnv(n.Env)
+	}
+	return nil
+}
+
+// VisitJobPre checks the job ID and job-level env.
+func (rule *RuleNaming) VisitJobPre(n *actionlint.Job) error {
+	switch rule.Name() {
+	case KindNamingJobID:
+		rule.check(n.ID)
+	case KindNamingEnvVar:
+		rule.checkEnv(n.Env)
+	}
+	return nil
+}
+
+// VisitStep checks the step name and step-level env.
+func (rule *RuleNaming) VisitStep(n *actionlint.Step) error {
+	switch rule.Name() {
+	case KindNamingStepName:
+		rule.check(n.Name)
+	case KindNamingEnvVar:
+		rule.checkEnv(n.Env)
+	}
+	return nil
+}
+
+func (rule *RuleNaming) checkEnv(env *actionlint.Env) {
+	if env == nil {
+		return
+	}
+	for _, v := range env.Vars {
+		rule.check(v.Name)
+	}
+}
+
+func (rule *RuleNaming) check(s *actionlint.String) {
+	if s == nil || s.ContainsExpression() {
+		return
+	}
+	if rule.pattern != nil && !rule.pattern.MatchString(s.Value) {
+		rule.Errorf(s.Pos, "%s %q does not match the naming convention %q", rule.what, s.Value, rule.pattern.String())
+	}
+	if rule.forbid != nil && rule.forbid.MatchString(s.Value) {
+		rule.Errorf(s.Pos, "%s %q matches the forbidden naming pattern %q", rule.what, s.Value, rule.forbid.String())
+	}
+}
@@ -0,0 +1,70 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const namingWorkflow = `name: ci build
+on: push
+env:
+  GITHUB_CUSTOM: x
+jobs:
+  Build_All:
+    runs-on: ubuntu-latest
+    env:
+      MY_VAR: y
+    steps:
+      - name: checkout code
+        run: echo hi
+        env:
+          lower: z
+      - name: ${{ matrix.name }}
+        run: echo hi
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - name: Run tests
+        run: echo hi
+`
+
+func lintNaming(t *testing.T, cfg NamingConfig) []*actionlint.Error {
+	t.Helper()
+	rs := NewNaming(cfg)
+	require.Len(t, rs, 1)
+	return lintWith(t, func() actionlint.Rule { return NewNaming(cfg)[0] }, namingWorkflow)
+}
+
+func TestNaming(t *testing.T) {
+	assert.Empty(t, NewNaming(NamingConfig{}), "conventions without patterns are disabled")
+
+	errs := lintNaming(t, NamingConfig{JobID: NamingConvention{Pattern: `^[a-z0-9]+(-[a-z0-9]+)*$`}})
+	require.Len(t, errs, 1)
+	assert.Equal(t, KindNamingJobID, errs[0].Kind)
+	assert.Contains(t, errs[0].Message, `job ID "Build_All" does not match the naming convention`)
+
+	errs = lintNaming(t, NamingConfig{EnvVar: NamingConvention{Pattern: `^[A-Z][A-Z0-9_]*$`, Forbid: `^GITHUB_`}})
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Message, `environment variable "GITHUB_CUSTOM" matches the forbidden naming pattern "^GITHUB_"`)
+	assert.Contains(t, errs[1].Message, `environment variable "lower" does not match`)
+
+	// Names built by expressions are not checked
+	errs = lintNaming(t, NamingConfig{StepName: NamingConvention{Pattern: `^[A-Z]`}})
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, `step name "checkout code"`)
+
+	errs = lintNaming(t, NamingConfig{WorkflowName: NamingConvention{Pattern: `^[A-Z]`}})
+	require.Len(t, errs, 1)
+	assert.Equal(t, KindNamingWorkflowName, errs[0].Kind)
+}
+
+func TestNamingConfig_Validate(t *testing.T) {
+	assert.NoError(t, NamingConfig{StepName: NamingConvention{Pattern: "^[A-Z]"}}.Validate())
+
+	err := NamingConfig{EnvVar: NamingConvention{Forbid: "("}}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), KindNamingEnvVar)
+}
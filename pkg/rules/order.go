@@ -0,0 +1,282 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindStepOrder is the name of RuleStepOrder.
+const KindStepOrder = "step-order"
+
+// commandPrefix matches where a shell command starts in a script.
+const commandPrefix = `(?m)(?:^|[;&|(]|\$\()\s*(?:sudo\s+)?`
+
+// toolchain is a language toolchain: the actions installing it, the
+// commands run: scripts use it with, and the directories it caches.
+type toolchain struct {
+	setup   []string
+	command *regexp.Regexp
+	cache   *regexp.Regexp
+}
+
+var orderToolchains = []toolchain{
+	{
+		setup:   []string{"actions/setup-node"},
+		command: regexp.MustCompile(commandPrefix + `(npm|npx|node|yarn|pnpm|corepack)(?:\s|$)`),
+		cache:   regexp.MustCompile(`\.npm\b|node_modules|\byarn\b|\.yarn\b|pnpm-store`),
+	},
+	{
+		setup:   []string{"actions/setup-python"},
+		command: regexp.MustCompile(commandPrefix + `(python3?|pip3?|pytest|poetry|pipenv|tox|nox)(?:\s|$)`),
+		cache:   regexp.MustCompile(`\bpip\b|\.venv\b|\bpoetry\b|\bpipenv\b|\.tox\b`),
+	},
+	{
+		setup:   []string{"actions/setup-go"},
+		command: regexp.MustCompile(commandPrefix + `(go\s+(?:build|test|run|vet|install|generate|get|mod|list))(?:\s|$)`),
+		cache:   regexp.MustCompile(`go-build|pkg/mod\b`),
+	},
+	{
+		setup:   []string{"actions/setup-java"},
+		command: regexp.MustCompile(commandPrefix + `(mvn|\./mvnw|gradle|\./gradlew|java|sbt)(?:\s|$)`),
+		cache:   regexp.MustCompile(`\.m2\b|\.gradle\b|\.ivy2\b|\.sbt\b`),
+	},
+	{
+		setup:   []string{"actions/setup-dotnet"},
+		command: regexp.MustCompile(commandPrefix + `(dotnet)(?:\s|$)`),
+		cache:   regexp.MustCompile(`\.nuget\b`),
+	},
+	{
+		setup:   []string{"ruby/setup-ruby"},
+		command: regexp.MustCompile(commandPrefix + `(bundle|ruby|gem|rake)(?:\s|$)`),
+		cache:   regexp.MustCompile(`vendor/bundle|\.gem\b`),
+	},
+	{
+		setup:   []string{"dtolnay/rust-toolchain", "actions-rust-lang/setup-rust-toolchain"},
+		command: regexp.MustCompile(commandPrefix + `(cargo|rustc|rustup)(?:\s|$)`),
+		cache:   regexp.MustCompile(`\.cargo\b|(?:^|/)target(?:/|$)`),
+	},
+}
+
+var (
+	// projectCommand matches commands that read the project's files, so
+	// fail without a checkout.
+	projectCommand = regexp.MustCompile(commandPrefix + `(make|(?:npm|yarn|pnpm)\s+(?:ci|install|test|run)|go\s+(?:build|test|vet)\s+\./|cargo\s+(?:build|test|check|clippy)|mvn|\./mvnw|\./gradlew|pip3?\s+install\s+(?:-r|-e|\.)|pytest|docker\s+build\s+.*\s\.)(?:\s|$)`)
+	// fetchCommand matches commands that bring files into the workspace
+	// without actions/checkout.
+	fetchCommand = regexp.MustCompile(`\bgit\s+clone\b|\bgh\s+repo\s+clone\b`)
+	// fetchActions bring files into the workspace.
+	fetchActions = []string{"actions/checkout", "actions/download-artifact", "dawidd6/action-download-artifact"}
+)
+
+// RuleStepOrder flags steps that run too early or too late for the steps
+// that depend on them: caches restored after the commands they are for,
+// setup actions after commands that already ran with the runner's
+// preinstalled toolchain, uploads of files a later step writes, and steps
+// that need the repository before actions/checkout.
+type RuleStepOrder struct {
+	actionlint.RuleBase
+}
+
+// NewStepOrder creates a RuleStepOrder.
+func NewStepOrder() *RuleStepOrder {
+	return &RuleStepOrder{
+		RuleBase: actionlint.NewRuleBase(KindStepOrder, "Checks the order of dependent steps"),
+	}
+}
+
+// VisitJobPre checks the order of the job's steps.
+func (rule *RuleStepOrder) VisitJobPre(n *actionlint.Job) error {
+	rule.checkCheckout(n.Steps)
+	rule.checkToolchains(n.Steps)
+	rule.checkUploads(n.Steps)
+	return nil
+}
+
+// usesName returns the action a step uses, lower-cased and without its
+// version, or "" for run: steps.
+func usesName(s *actionlint.Step) (string, *actionlint.ExecAction) {
+	exec, ok := s.Exec.(*actionlint.ExecAction)
+	if !ok || exec.Uses == nil {
+		return "", nil
+	}
+	name, _, _ := strings.Cut(exec.Uses.Value, "@")
+	return strings.ToLower(name), exec
+}
+
+func runScript(s *actionlint.Step) *actionlint.String {
+	if exec, ok := s.Exec.(*actionlint.ExecRun); ok {
+		return exec.Run
+	}
+	return nil
+}
+
+// checkCheckout reports the first step that needs the repository's files
+// before the job checks them out: a local action, a setup action caching
+// by a lockfile, a hashFiles() input, or a command building the project.
+func (rule *RuleStepOrder) checkCheckout(steps []*actionlint.Step) {
+	checkout := -1
+	for i, s := range steps {
+		if name, _ := usesName(s); name == "actions/checkout" {
+			checkout = i
+			break
+		}
+	}
+
+	for i, s := range steps {
+		if i == checkout {
+			return
+		}
+		var pos *actionlint.Pos
+		var what string
+		name, exec := usesName(s)
+		switch {
+		case containsFold(fetchActions, name):
+			return
+		case exec != nil && strings.HasPrefix(exec.Uses.Value, "./"):
+			pos, what = exec.Uses.Pos, fmt.Sprintf("%q is an action in the repository", exec.Uses.Value)
+		case exec != nil:
+			if _, setup := setupActions[name]; setup && exec.Inputs["cache"] != nil {
+				pos, what = exec.Uses.Pos, fmt.Sprintf("%q caches by the repository's lockfile", exec.Uses.Value)
+				break
+			}
+			for _, in := range exec.Inputs {
+				if in.Value == nil || !strings.Contains(in.Value.Value, "hashFiles(") {
+					continue
+				}
+				if pos == nil || in.Value.Pos.Line < pos.Line {
+					pos, what = in.Value.Pos, fmt.Sprintf("hashFiles() in input %q hashes files of the repository", in.Name.Value)
+				}
+			}
+		default:
+			run := runScript(s)
+			if run == nil {
+				continue
+			}
+			if fetchCommand.MatchString(run.Value) {
+				return
+			}
+			if m := projectCommand.FindStringSubmatch(run.Value); m != nil {
+				pos, what = run.Pos, fmt.Sprintf("%q works on the repository's files", strings.Join(strings.Fields(m[1]), " "))
+			}
+		}
+		if pos == nil {
+			continue
+		}
+		if checkout < 0 {
+			rule.Errorf(pos, "%s, but the job has no actions/checkout step, so they are not in the workspace. add one before this step", what)
+		} else {
+			rule.Errorf(pos, "%s, but actions/checkout only runs at line %d, after this step. move the checkout before it", what, stepPos(steps[checkout]).Line)
+		}
+		return
+	}
+}
+
+// checkToolchains reports setup actions and dependency caches that come
+// after a run: step already used their toolchain.
+func (rule *RuleStepOrder) checkToolchains(steps []*actionlint.Step) {
+	for _, t := range orderToolchains {
+		var used *actionlint.String
+		var command string
+		for _, s := range steps {
+			if run := runScript(s); run != nil {
+				if m := t.command.FindStringSubmatch(run.Value); m != nil && used == nil {
+					used, command = run, strings.Join(strings.Fields(m[1]), " ")
+				}
+				continue
+			}
+			if used == nil {
+				continue
+			}
+			name, exec := usesName(s)
+			switch {
+			case containsFold(t.setup, name):
+				rule.Errorf(exec.Uses.Pos, "%q runs after the step at line %d already ran %q with the runner's preinstalled version. move it before that step", exec.Uses.Value, used.Pos.Line, command)
+			case name == "actions/cache" || name == "actions/cache/restore":
+				in := exec.Inputs["path"]
+				if in == nil || in.Value == nil || !t.cache.MatchString(in.Value.Value) {
+					continue
+				}
+				rule.Errorf(exec.Uses.Pos, "%q restores the cache after the step at line %d already ran %q without it. move it before that step", exec.Uses.Value, used.Pos.Line, command)
+			}
+		}
+	}
+}
+
+// checkUploads reports artifact uploads of paths that no step before the
+// upload mentions but a later run: step does, such as a coverage report
+// uploaded before the tests that write it.
+func (rule *RuleStepOrder) checkUploads(steps []*actionlint.Step) {
+uploads:
+	for i, s := range steps {
+		name, exec := usesName(s)
+		if name != "actions/upload-artifact" {
+			continue
+		}
+		in := exec.Inputs["path"]
+		if in == nil || in.Value == nil {
+			continue
+		}
+		for _, p := range uploadPaths(in.Value.Value) {
+			if mentions(steps[:i], p) {
+				continue
+			}
+			for _, later := range steps[i+1:] {
+				if run := runScript(later); run != nil && strings.Contains(run.Value, p) {
+					rule.Errorf(exec.Uses.Pos, "%q uploads %q, which the step at line %d refers to only after the upload. move the upload after that step", exec.Uses.Value, p, run.Pos.Line)
+					continue uploads
+				}
+			}
+		}
+	}
+}
+
+// uploadPaths returns the literal part of each path of an upload's path
+// input: up to the first wildcard, without exclusions, expressions and
+// paths too short to search for.
+func uploadPaths(input string) []string {
+	var paths []string
+	for _, line := range strings.Split(input, "\n") {
+		p := strings.TrimSpace(line)
+		if p == "" || strings.HasPrefix(p, "!") || strings.Contains(p, "${{") {
+			continue
+		}
+		if i := strings.IndexAny(p, "*?["); i >= 0 {
+			p = p[:i]
+		}
+		p = strings.TrimSuffix(strings.TrimPrefix(p, "./"), "/")
+		if len(p) >= 3 && !strings.HasPrefix(p, "~") && !strings.HasPrefix(p, "/") {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// mentions reports whether a script or input of steps contains s.
+func mentions(steps []*actionlint.Step, s string) bool {
+	for _, step := range steps {
+		for _, str := range Strings(step) {
+			if strings.Contains(str.Value, s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stepPos returns the position of the uses: or run: of a step.
+func stepPos(s *actionlint.Step) *actionlint.Pos {
+	switch exec := s.Exec.(type) {
+	case *actionlint.ExecAction:
+		if exec.Uses != nil {
+			return exec.Uses.Pos
+		}
+	case *actionlint.ExecRun:
+		if exec.Run != nil {
+			return exec.Run.Pos
+		}
+	}
+	return s.Pos
+}
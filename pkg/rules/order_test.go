@@ -0,0 +1,108 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lintStepOrder(t *testing.T, src string) []*actionlint.Error {
+	t.Helper()
+	return lintWith(t, func() actionlint.Rule { return NewStepOrder() }, src)
+}
+
+func TestStepOrder_Checkout(t *testing.T) {
+	errs := lintStepOrder(t, `on: push
+jobs:
+  local:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: ./.github/actions/build
+      - uses: actions/checkout@v4
+  missing:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/cache@v4
+        with:
+          path: ~/.npm
+          key: npm-${{ hashFiles('package-lock.json') }}
+      - run: npm ci
+  artifact:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/download-artifact@v4
+      - run: make deploy
+  ok:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/setup-node@v4
+        with:
+          cache: npm
+      - run: npm ci
+`)
+	require.Len(t, errs, 2)
+	assert.Equal(t, 6, errs[0].Line)
+	assert.Contains(t, errs[0].Message, `"./.github/actions/build" is an action in the repository, but actions/checkout only runs at line 7`)
+	assert.Equal(t, 14, errs[1].Line)
+	assert.Contains(t, errs[1].Message, `hashFiles() in input "key" hashes files of the repository, but the job has no actions/checkout step`)
+}
+
+func TestStepOrder_Toolchains(t *testing.T) {
+	errs := lintStepOrder(t, `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - run: |
+          echo "let's go build"
+          npm ci
+      - uses: actions/setup-node@v4
+        with:
+          node-version: 22
+      - uses: actions/cache@v4
+        with:
+          path: node_modules
+          key: modules
+      - uses: actions/setup-python@v5
+      - run: pytest
+`)
+	require.Len(t, errs, 2)
+	assert.Equal(t, 10, errs[0].Line)
+	assert.Contains(t, errs[0].Message, `"actions/setup-node@v4" runs after the step at line 7 already ran "npm"`)
+	assert.Equal(t, 13, errs[1].Line)
+	assert.Contains(t, errs[1].Message, `"actions/cache@v4" restores the cache after the step at line 7`)
+}
+
+func TestStepOrder_Uploads(t *testing.T) {
+	errs := lintStepOrder(t, `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: actions/upload-artifact@v4
+        with:
+          name: coverage
+          path: coverage.out
+      - run: go test -coverprofile=coverage.out ./...
+      - run: go build -o dist/app .
+      - uses: actions/upload-artifact@v4
+        with:
+          name: binaries
+          path: |
+            dist/*
+            !dist/*.txt
+`)
+	require.Len(t, errs, 1)
+	assert.Equal(t, 7, errs[0].Line)
+	assert.Contains(t, errs[0].Message, `uploads "coverage.out", which the step at line 11 refers to only after the upload`)
+	assert.Equal(t, KindStepOrder, errs[0].Kind)
+}
+
+func TestUploadPaths(t *testing.T) {
+	assert.Equal(t, []string{"test-results", "coverage/lcov.info"}, uploadPaths("test-results/**\n./coverage/lcov.info\n!skip\n**/*.xml\n${{ env.OUT }}\n~/logs\n"))
+}
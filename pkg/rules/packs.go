@@ -0,0 +1,152 @@
+package rules
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Pack is a named group of checks that do not run by default. Turning a
+// pack on, in the configuration, in a repository or for a single call,
+// runs all of its checks, in addition to actionlint's. Without packs, the
+// findings are actionlint's, and those of the rules that only report once
+// configured.
+type Pack struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Kinds       []string `json:"kinds"`
+}
+
+// The kinds of the checks the linter runs itself, on the document as a
+// whole or across the files of the repository, which packs group too.
+const (
+	KindContextAvailability   = "context-availability"
+	KindNotWorkflow           = "not-workflow"
+	KindMultiDocument         = "multi-document"
+	KindReusableCalls         = "reusable-calls"
+	KindOutputContract        = "output-contract"
+	KindDockerAction          = "docker-action"
+	KindDuplicateName         = "duplicate-name"
+	KindConcurrencyDeadlock   = "concurrency-deadlock"
+	KindConcurrencyStarvation = "concurrency-starvation"
+	KindUnknownRef            = "unknown-ref"
+)
+
+// Packs are the rule packs, sorted by name.
+var Packs = []Pack{
+	{"cloud", "Static credentials, missing regions and unprotected applies in AWS, Google Cloud, Azure and Terraform deployments", []string{KindCloudDeploy}},
+	{"cost", "Jobs that can run, and be billed, for longer or more often than they need, or that set up their toolchains without caching", []string{KindJobTimeout, KindMatrixSize, KindSetupCache, KindSchedule}},
+	{"security", "Secrets, tokens and permissions that leak or do not work, unpinned actions, and the network egress of publishing and deployment jobs", []string{KindEgress, KindUnpinnedAction, KindPlaintextSecret, KindSecretEnvFile, KindCheckout, KindTokenPermissions, KindForkSafety, KindRelease, KindGHCLI}},
+	{"style", "Mistakes actionlint does not catch, in conditions, scripts, steps and the contracts between workflows, jobs and actions, and misspelled or unconventional names, unless the naming settings give other conventions", []string{
+		KindSpelling, KindNamingJobID, KindNamingEnvVar,
+		KindMatrixInclude, KindConstantCondition, KindLiteralExpression, KindUnreachableJob, KindEventFilter, KindMatrixOS,
+		KindLongScript, KindEnvFile, KindShellStrictness, KindRunnerShell, KindPortableScript, KindRunnerTool, KindWorkingDirectory,
+		KindFailureHandling, KindStepOrder, KindDeprecatedInput, KindContextAvailability, KindNotWorkflow, KindMultiDocument,
+		KindReusableCalls, KindOutputContract, KindDockerAction, KindDuplicateName, KindConcurrencyDeadlock, KindConcurrencyStarvation, KindUnknownRef,
+	}},
+}
+
+// styleNaming are the naming conventions of the style pack.
+var styleNaming = NamingConfig{
+	JobID:  NamingConvention{Pattern: `^[a-z][a-z0-9_-]*$`},
+	EnvVar: NamingConvention{Pattern: `^[A-Z_][A-Z0-9_]*$`},
+}
+
+// LookupPack returns the pack called name.
+func LookupPack(name string) (Pack, bool) {
+	for _, p := range Packs {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Pack{}, false
+}
+
+// PackOf returns the name of the pack kind belongs to, or "" for the
+// checks that always run.
+func PackOf(kind string) string {
+	for _, p := range Packs {
+		if slices.Contains(p.Kinds, kind) {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+// ValidatePacks reports names that are not packs.
+func ValidatePacks(names []string) error {
+	for _, name := range names {
+		if _, ok := LookupPack(name); !ok {
+			known := make([]string, len(Packs))
+			for i, p := range Packs {
+				known[i] = p.Name
+			}
+			return fmt.Errorf("unknown rule pack %q; use %s", name, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// WithPacks returns c with the packs names turned on as well.
+func (c Config) WithPacks(names ...string) Config {
+	packs := slices.Clone(c.Packs)
+	for _, name := range names {
+		if !slices.Contains(packs, name) {
+			packs = append(packs, name)
+		}
+	}
+	c.Packs = packs
+	return c
+}
+
+// resolve returns c with the settings of the rules of its packs turned
+// on. Naming conventions that are set are kept.
+func (c Config) resolve() Config {
+	for _, name := range c.Packs {
+		switch name {
+		case "cloud":
+			c.CloudDeploy.Enabled = true
+		case "cost":
+			c.JobTimeout.Enabled = true
+		case "security":
+			c.Egress.Enabled = true
+			c.Pins.Enabled = true
+		case "style":
+			c.Spelling.Enabled = true
+			if !c.Naming.JobID.enabled() {
+				c.Naming.JobID = styleNaming.JobID
+			}
+			if !c.Naming.EnvVar.enabled() {
+				c.Naming.EnvVar = styleNaming.EnvVar
+			}
+		}
+	}
+	return c
+}
+
+// Active reports whether the checks of kind run with c. The checks of a
+// pack run when the pack is on, or for those with an enabled setting, when
+// it is set; the others always run, though some only report once
+// configured.
+func (c Config) Active(kind string) bool {
+	c = c.resolve()
+	switch kind {
+	case KindCloudDeploy:
+		return c.CloudDeploy.Enabled
+	case KindJobTimeout:
+		return c.JobTimeout.Enabled
+	case KindEgress:
+		return c.Egress.Enabled
+	case KindUnpinnedAction:
+		return c.Pins.Enabled
+	case KindSpelling:
+		return c.Spelling.Enabled
+	}
+	if conv, ok := c.Naming.conventions()[kind]; ok {
+		return conv.enabled()
+	}
+	if pack := PackOf(kind); pack != "" {
+		return slices.Contains(c.Packs, pack)
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacks(t *testing.T) {
+	for i, p := range Packs {
+		if i > 0 {
+			assert.Less(t, Packs[i-1].Name, p.Name, "packs are sorted by name")
+		}
+		assert.NotEmpty(t, p.Description)
+		for _, kind := range p.Kinds {
+			assert.Equal(t, p.Name, PackOf(kind))
+			assert.False(t, Config{}.Active(kind), "%s does not run by default", kind)
+			assert.True(t, Config{}.WithPacks(p.Name).Active(kind), "%s runs with pack %s", kind, p.Name)
+		}
+	}
+	assert.Empty(t, PackOf(KindUndefinedSecret))
+	assert.True(t, Config{}.Active(KindUndefinedSecret))
+	assert.False(t, Config{}.Active(KindShellStrictness), "checks without settings only run with their pack")
+	assert.True(t, Config{Egress: EgressConfig{Enabled: true}}.Active(KindEgress), "settings still turn checks on")
+
+	// Packs add to the configured ones without changing them
+	cfg := Config{Packs: []string{"cost"}}
+	with := cfg.WithPacks("cloud", "cost")
+	assert.Equal(t, []string{"cost", "cloud"}, with.Packs)
+	assert.Equal(t, []string{"cost"}, cfg.Packs)
+
+	// Conventions that are set win over the style pack's
+	styled := Config{Naming: NamingConfig{JobID: NamingConvention{Pattern: "^ci-"}}}.WithPacks("style").resolve()
+	assert.Equal(t, "^ci-", styled.Naming.JobID.Pattern)
+	assert.Equal(t, styleNaming.EnvVar, styled.Naming.EnvVar)
+	names := map[string]bool{}
+	for _, r := range New(Config{}.WithPacks("style")) {
+		names[r.Name()] = true
+	}
+	assert.True(t, names[KindNamingJobID])
+	assert.True(t, names[KindNamingEnvVar])
+	assert.False(t, names[KindNamingStepName])
+}
+
+func TestValidatePacks(t *testing.T) {
+	require.NoError(t, ValidatePacks([]string{"cloud", "style"}))
+	err := ValidatePacks([]string{"speed"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown rule pack "speed"; use cloud, cost, security, style`)
+	assert.Error(t, Config{Packs: []string{"speed"}}.Validate())
+}
@@ -0,0 +1,109 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindUnpinnedAction is the name of RuleUnpinnedAction.
+const KindUnpinnedAction = "unpinned-action"
+
+// commitSHA matches a full commit SHA.
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// PinsConfig configures the unpinned-action rule.
+type PinsConfig struct {
+	// Enabled turns the rule on. It is off by default.
+	Enabled bool `yaml:"enabled"`
+	// Commits maps actions and reusable workflows, as owner/repo@ref or
+	// owner/repo/path@ref, to the full commit SHA their ref points to,
+	// which the rule's fixes pin them to.
+	Commits map[string]string `yaml:"commits"`
+}
+
+// Validate reports entries that are not remote actions with a ref, or
+// whose commit is not a full SHA.
+func (c PinsConfig) Validate() error {
+	for spec, sha := range c.Commits {
+		if err := validateActionSpec(spec); err != nil {
+			return fmt.Errorf("pins: %w", err)
+		}
+		if !strings.Contains(spec, "@") {
+			return fmt.Errorf("pins: %q has no ref; use owner/repo@ref", spec)
+		}
+		if !commitSHA.MatchString(sha) {
+			return fmt.Errorf("pins: the commit of %s must be a full lower-case SHA, not %q", spec, sha)
+		}
+	}
+	return nil
+}
+
+// RuleUnpinnedAction flags actions and reusable workflows used by tag or
+// branch rather than commit SHA, so whoever can move the ref changes what
+// runs. Offers a fix pinning the ones whose commit the configuration
+// gives, keeping the ref in a comment. Local actions, Docker images and
+// refs given by expressions are skipped.
+type RuleUnpinnedAction struct {
+	actionlint.RuleBase
+	fixes
+	cfg     PinsConfig
+	commits map[string]string
+}
+
+// NewUnpinnedAction creates a RuleUnpinnedAction.
+func NewUnpinnedAction(cfg PinsConfig) *RuleUnpinnedAction {
+	commits := make(map[string]string, len(cfg.Commits))
+	for spec, sha := range cfg.Commits {
+		commits[strings.ToLower(spec)] = sha
+	}
+	return &RuleUnpinnedAction{
+		RuleBase: actionlint.NewRuleBase(KindUnpinnedAction, "Checks that actions and reusable workflows are pinned to a commit SHA"),
+		cfg:      cfg,
+		commits:  commits,
+	}
+}
+
+// VisitJobPre checks the reusable workflow the job calls.
+func (rule *RuleUnpinnedAction) VisitJobPre(n *actionlint.Job) error {
+	if n.WorkflowCall != nil {
+		rule.check(n.WorkflowCall.Uses, "reusable workflow")
+	}
+	return nil
+}
+
+// VisitStep checks the action the step uses.
+func (rule *RuleUnpinnedAction) VisitStep(n *actionlint.Step) error {
+	if exec, ok := n.Exec.(*actionlint.ExecAction); ok {
+		rule.check(exec.Uses, "action")
+	}
+	return nil
+}
+
+func (rule *RuleUnpinnedAction) check(uses *actionlint.String, what string) {
+	if !rule.cfg.Enabled || uses == nil || uses.ContainsExpression() {
+		return
+	}
+	spec := uses.Value
+	if strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "docker://") {
+		return
+	}
+	name, ref, ok := strings.Cut(spec, "@")
+	if !ok || commitSHA.MatchString(ref) {
+		return
+	}
+	rule.Errorf(uses.Pos, "%s %s is not pinned to a commit SHA, so whoever can move %q changes what runs. pin it to the commit's full SHA, keeping the ref in a comment", what, spec, ref)
+
+	sha, known := rule.commits[strings.ToLower(spec)]
+	if !known {
+		return
+	}
+	pinned := name + "@" + sha
+	if !uses.Quoted {
+		pinned += " # " + ref
+	}
+	rule.addFix(KindUnpinnedAction, uses.Pos, fmt.Sprintf("Pin %s to %s", spec, sha[:12]),
+		Edit{Line: uses.Pos.Line, Column: uses.Pos.Col, Old: spec, New: pinned})
+}
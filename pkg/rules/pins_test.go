@@ -0,0 +1,52 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnpinnedAction(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: "actions/setup-go@v5"
+      - uses: actions/cache@0c45773b623bea8c8e75f6c82b208c3cf94ea4f9 # v4
+      - uses: ./.github/actions/build
+      - uses: docker://alpine:3
+  release:
+    uses: acme/workflows/.github/workflows/release.yml@main
+`
+	sha := "11bd71901bbe5b1630ceea73d27597364c9af683"
+	cfg := PinsConfig{Enabled: true, Commits: map[string]string{"Actions/Checkout@v4": sha, "actions/setup-go@v5": sha}}
+	rule := NewUnpinnedAction(cfg)
+	errs := lintWith(t, func() actionlint.Rule { return rule }, src)
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[0].Message, `action actions/checkout@v4 is not pinned to a commit SHA, so whoever can move "v4" changes what runs`)
+	assert.Equal(t, 7, errs[1].Line)
+	assert.Contains(t, errs[2].Message, "reusable workflow acme/workflows/.github/workflows/release.yml@main")
+	for _, e := range errs {
+		assert.Equal(t, KindUnpinnedAction, e.Kind)
+	}
+
+	fixes := rule.Fixes()
+	require.Len(t, fixes, 2, "only refs with a known commit are fixed")
+	assert.Equal(t, []Edit{{Line: 6, Column: 15, Old: "actions/checkout@v4", New: "actions/checkout@" + sha + " # v4"}}, fixes[0].Edits)
+	assert.Equal(t, "actions/setup-go@"+sha, fixes[1].Edits[0].New, "quoted values get no comment")
+
+	errs = lintWith(t, func() actionlint.Rule { return NewUnpinnedAction(PinsConfig{}) }, src)
+	assert.Empty(t, errs, "the rule is off by default")
+}
+
+func TestPinsConfig_Validate(t *testing.T) {
+	sha := "11bd71901bbe5b1630ceea73d27597364c9af683"
+	require.NoError(t, PinsConfig{Commits: map[string]string{"actions/checkout@v4": sha}}.Validate())
+	assert.ErrorContains(t, PinsConfig{Commits: map[string]string{"actions/checkout": sha}}.Validate(), "has no ref")
+	assert.ErrorContains(t, PinsConfig{Commits: map[string]string{"./local@v1": sha}}.Validate(), "is not an action")
+	assert.ErrorContains(t, PinsConfig{Commits: map[string]string{"actions/checkout@v4": "11bd719"}}.Validate(), "full lower-case SHA")
+}
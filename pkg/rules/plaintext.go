@@ -0,0 +1,181 @@
+package rules
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindPlaintextSecret is the name of RulePlaintextSecret.
+const KindPlaintextSecret = "plaintext-secret"
+
+// credentialPattern is a well-known credential format.
+type credentialPattern struct {
+	what string
+	re   *regexp.Regexp
+}
+
+var (
+	credentialPatterns = []credentialPattern{
+		{"an AWS access key ID", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		{"a GitHub token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+		{"a GitHub fine-grained token", regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,}\b`)},
+		{"a private key", regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY(?: BLOCK)?-----`)},
+	}
+
+	// secretName matches names that suggest the value is a credential.
+	secretName = regexp.MustCompile(`(?i)token|secret|passw(or)?d|passwd|api[_-]?key|access[_-]?key|private[_-]?key|credential|auth`)
+	// tokenLike matches values that could be a generated token: long, with
+	// no spaces, in the alphabets tokens are encoded in.
+	tokenLike = regexp.MustCompile(`^[A-Za-z0-9+/=_\-.]{20,}$`)
+	// notToken matches long values that are not tokens: commit SHAs and
+	// digests, URLs, and paths.
+	notToken = regexp.MustCompile(`^(?:[0-9a-f]{40}|[0-9a-f]{64}|sha\d+:.*|.*://.*|[./].*)$`)
+)
+
+// minTokenEntropy is the Shannon entropy, in bits per character, above
+// which a value under a credential-like name is taken for a token.
+const minTokenEntropy = 4.0
+
+// RulePlaintextSecret flags env: values, with: inputs and container
+// passwords that hold a credential in plain text instead of reading it from
+// the secrets context. Everyone who can read the repository can read them,
+// and GitHub does not mask them in logs.
+type RulePlaintextSecret struct {
+	actionlint.RuleBase
+}
+
+// NewPlaintextSecret creates a RulePlaintextSecret.
+func NewPlaintextSecret() *RulePlaintextSecret {
+	return &RulePlaintextSecret{
+		RuleBase: actionlint.NewRuleBase(KindPlaintextSecret, "Checks for credentials written in plain text"),
+	}
+}
+
+// VisitWorkflowPre checks the workflow's env.
+func (rule *RulePlaintextSecret) VisitWorkflowPre(n *actionlint.Workflow) error {
+	rule.checkEnv(n.Env)
+	return nil
+}
+
+// VisitJobPre checks the job's env and its containers.
+func (rule *RulePlaintextSecret) VisitJobPre(n *actionlint.Job) error {
+	rule.checkEnv(n.Env)
+	rule.checkContainer(n.Container)
+
+	if n.Services == nil {
+		return nil
+	}
+	names := make([]string, 0, len(n.Services.Value))
+	for name := range n.Services.Value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rule.checkContainer(n.Services.Value[name].Container)
+	}
+	return nil
+}
+
+// VisitStep checks the step's env and with: inputs.
+func (rule *RulePlaintextSecret) VisitStep(n *actionlint.Step) error {
+	rule.checkEnv(n.Env)
+	if exec, ok := n.Exec.(*actionlint.ExecAction); ok {
+		names := make([]string, 0, len(exec.Inputs))
+		for name := range exec.Inputs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			in := exec.Inputs[name]
+			if in.Name != nil {
+				rule.check("input", in.Name.Value, in.Value)
+			}
+		}
+	}
+	return nil
+}
+
+func (rule *RulePlaintextSecret) checkEnv(env *actionlint.Env) {
+	if env == nil {
+		return
+	}
+	names := make([]string, 0, len(env.Vars))
+	for name := range env.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v := env.Vars[name]
+		if v.Name != nil {
+			rule.check("environment variable", v.Name.Value, v.Value)
+		}
+	}
+}
+
+func (rule *RulePlaintextSecret) checkContainer(c *actionlint.Container) {
+	if c == nil {
+		return
+	}
+	rule.checkEnv(c.Env)
+	// Any literal password is in plain text, whatever it looks like
+	if cred := c.Credentials; cred != nil && cred.Password != nil && cred.Password.Value != "" && !strings.Contains(cred.Password.Value, "${{") {
+		rule.Errorf(cred.Password.Pos, "container password is written in plain text. store it as a secret and use ${{ secrets.NAME }} instead")
+	}
+}
+
+// check reports value when it holds a well-known credential format, or when
+// it is a high-entropy token under a credential-like name.
+func (rule *RulePlaintextSecret) check(what, name string, value *actionlint.String) {
+	if value == nil || value.Value == "" {
+		return
+	}
+	for _, p := range credentialPatterns {
+		if m := p.re.FindString(value.Value); m != "" {
+			rule.Errorf(value.Pos, "%s %q contains %s in plain text (%s). store it as a secret and use ${{ secrets.NAME }} instead, and revoke it since it is already in the repository history", what, name, p.what, redact(m))
+			return
+		}
+	}
+
+	v := strings.TrimSpace(value.Value)
+	if strings.Contains(v, "${{") || !secretName.MatchString(name) || !tokenLike.MatchString(v) || notToken.MatchString(v) {
+		return
+	}
+	if entropy(v) >= minTokenEntropy {
+		rule.Errorf(value.Pos, "%s %q looks like a credential written in plain text (%s). store it as a secret and use ${{ secrets.NAME }} instead", what, name, redact(v))
+	}
+}
+
+// redact keeps the first characters of a credential, enough to identify it
+// without repeating it.
+func redact(s string) string {
+	if strings.HasPrefix(s, "-----") {
+		return s
+	}
+	n := 4
+	if i := strings.IndexByte(s, '_'); i > 0 && i < 12 {
+		n = i + 1 // Keeps prefixes such as ghp_
+	}
+	if len(s) <= n {
+		return strings.Repeat("*", len(s))
+	}
+	return s[:n] + "..."
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	var e float64
+	n := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / n
+		e -= p * math.Log2(p)
+	}
+	return e
+}
@@ -0,0 +1,163 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindRequiredSteps is the name of RuleRequiredSteps.
+const KindRequiredSteps = "required-steps"
+
+// StepMatcher matches steps by regular expressions. A step matches when
+// every set pattern matches: Uses against the action it uses, and Run
+// against its script.
+type StepMatcher struct {
+	Uses string `yaml:"uses"`
+	Run  string `yaml:"run"`
+}
+
+func (m StepMatcher) empty() bool {
+	return m.Uses == "" && m.Run == ""
+}
+
+func (m StepMatcher) String() string {
+	var parts []string
+	if m.Uses != "" {
+		parts = append(parts, fmt.Sprintf("uses: /%s/", m.Uses))
+	}
+	if m.Run != "" {
+		parts = append(parts, fmt.Sprintf("run: /%s/", m.Run))
+	}
+	return strings.Join(parts, " and ")
+}
+
+// matches reports whether s matches m. Patterns are compiled by Validate
+// first, so compile errors cannot happen here.
+func (m StepMatcher) matches(s *actionlint.Step) bool {
+	var uses, run string
+	switch exec := s.Exec.(type) {
+	case *actionlint.ExecAction:
+		if exec.Uses != nil {
+			uses = exec.Uses.Value
+		}
+	case *actionlint.ExecRun:
+		if exec.Run != nil {
+			run = exec.Run.Value
+		}
+	}
+	if m.Uses != "" {
+		re, err := compilePattern(m.Uses)
+		if err != nil || uses == "" || !re.MatchString(uses) {
+			return false
+		}
+	}
+	if m.Run != "" {
+		re, err := compilePattern(m.Run)
+		if err != nil || run == "" || !re.MatchString(run) {
+			return false
+		}
+	}
+	return true
+}
+
+// StepPolicy requires a step in the jobs it applies to.
+type StepPolicy struct {
+	// Name identifies the policy in findings.
+	Name string `yaml:"name"`
+	// When limits the policy to jobs with a matching step. It applies to
+	// every job when unset.
+	When StepMatcher `yaml:"when"`
+	// Require matches the step each job must have.
+	Require StepMatcher `yaml:"require"`
+	// First requires the step to be the first of the job.
+	First bool `yaml:"first"`
+	// Message is added to findings, to say why the policy exists.
+	Message string `yaml:"message"`
+}
+
+// ValidatePolicies reports policies without a name or required step, and
+// patterns that are not valid regular expressions.
+func ValidatePolicies(policies []StepPolicy) error {
+	for i, p := range policies {
+		if p.Name == "" {
+			return fmt.Errorf("required step policy %d has no name", i+1)
+		}
+		if p.Require.empty() {
+			return fmt.Errorf("required step policy %q has no require pattern", p.Name)
+		}
+		for _, pattern := range []string{p.When.Uses, p.When.Run, p.Require.Uses, p.Require.Run} {
+			if _, err := compilePattern(pattern); err != nil {
+				return fmt.Errorf("invalid pattern %q in required step policy %q: %w", pattern, p.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// RuleRequiredSteps flags jobs that lack a step a configured policy
+// requires, such as a scan after every container build or harden-runner
+// as the first step. Jobs calling reusable workflows have no steps and are
+// skipped.
+type RuleRequiredSteps struct {
+	actionlint.RuleBase
+	policies []StepPolicy
+}
+
+// NewRequiredSteps creates a RuleRequiredSteps.
+func NewRequiredSteps(policies []StepPolicy) *RuleRequiredSteps {
+	return &RuleRequiredSteps{
+		RuleBase: actionlint.NewRuleBase(KindRequiredSteps, "Checks jobs against the required step policies"),
+		policies: policies,
+	}
+}
+
+// VisitJobPre checks the job against each policy.
+func (rule *RuleRequiredSteps) VisitJobPre(n *actionlint.Job) error {
+	if n.WorkflowCall != nil || len(n.Steps) == 0 {
+		return nil
+	}
+	for _, p := range rule.policies {
+		if p.Require.empty() {
+			continue
+		}
+		applies := p.When.empty()
+		for _, s := range n.Steps {
+			if !applies && p.When.matches(s) {
+				applies = true
+				break
+			}
+		}
+		if !applies {
+			continue
+		}
+
+		var problem string
+		if p.First {
+			if !p.Require.matches(n.Steps[0]) {
+				problem = fmt.Sprintf("its first step must match %s", p.Require)
+			}
+		} else {
+			found := false
+			for _, s := range n.Steps {
+				if p.Require.matches(s) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				problem = fmt.Sprintf("it has no step matching %s", p.Require)
+			}
+		}
+		if problem == "" {
+			continue
+		}
+		msg := fmt.Sprintf("job %q breaks required step policy %q: %s", n.ID.Value, p.Name, problem)
+		if p.Message != "" {
+			msg += ". " + p.Message
+		}
+		rule.Errorf(n.ID.Pos, "%s", msg)
+	}
+	return nil
+}
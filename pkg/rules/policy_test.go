@@ -0,0 +1,64 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicies = []StepPolicy{
+	{
+		Name:    "harden-runner",
+		Require: StepMatcher{Uses: `^step-security/harden-runner@`},
+		First:   true,
+	},
+	{
+		Name:    "container-scan",
+		When:    StepMatcher{Run: `\bdocker (buildx )?build\b`},
+		Require: StepMatcher{Uses: `^aquasecurity/trivy-action@`},
+		Message: "images must be scanned before they are pushed",
+	},
+}
+
+func TestRequiredSteps(t *testing.T) {
+	src := `on: push
+jobs:
+  image:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: step-security/harden-runner@v2
+      - run: docker build -t app .
+  scanned:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: step-security/harden-runner@v2
+      - run: docker buildx build -t app .
+      - uses: aquasecurity/trivy-action@0.28.0
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: step-security/harden-runner@v2
+  call:
+    uses: ./.github/workflows/reusable.yml
+`
+	errs := lintWith(t, func() actionlint.Rule { return NewRequiredSteps(testPolicies) }, src)
+	require.Len(t, errs, 2)
+	assert.Equal(t, 3, errs[0].Line)
+	assert.Contains(t, errs[0].Message, `job "image" breaks required step policy "container-scan": it has no step matching uses: /^aquasecurity/trivy-action@/. images must be scanned before they are pushed`)
+	assert.Equal(t, 14, errs[1].Line)
+	assert.Contains(t, errs[1].Message, `job "test" breaks required step policy "harden-runner": its first step must match uses: /^step-security/harden-runner@/`)
+	for _, e := range errs {
+		assert.Equal(t, KindRequiredSteps, e.Kind)
+	}
+}
+
+func TestValidatePolicies(t *testing.T) {
+	require.NoError(t, ValidatePolicies(testPolicies))
+	assert.ErrorContains(t, ValidatePolicies([]StepPolicy{{Require: StepMatcher{Uses: "x"}}}), "has no name")
+	assert.ErrorContains(t, ValidatePolicies([]StepPolicy{{Name: "p"}}), "has no require pattern")
+	assert.ErrorContains(t, ValidatePolicies([]StepPolicy{{Name: "p", Require: StepMatcher{Run: "("}}}), `invalid pattern "("`)
+	assert.ErrorContains(t, Config{RequiredSteps: []StepPolicy{{Name: "p"}}}.Validate(), "has no require pattern")
+}
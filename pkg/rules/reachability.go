@@ -0,0 +1,291 @@
+package rules
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindUnreachableJob is the name of RuleUnreachableJob.
+const KindUnreachableJob = "unreachable-job"
+
+// RuleUnreachableJob flags jobs that can never run: their if: condition
+// only accepts events that do not trigger the workflow or contradicts
+// itself, or a job they need never runs or only runs for other events.
+// Jobs whose condition uses always(), failure() or cancelled() run after
+// skipped needs and are only checked against their own condition.
+type RuleUnreachableJob struct {
+	actionlint.RuleBase
+}
+
+// NewUnreachableJob creates a RuleUnreachableJob.
+func NewUnreachableJob() *RuleUnreachableJob {
+	return &RuleUnreachableJob{
+		RuleBase: actionlint.NewRuleBase(KindUnreachableJob, "Checks for jobs that can never run"),
+	}
+}
+
+// eventSet is the set of event names a job can run for. nil means any
+// event, for workflows whose event name is not known statically.
+type eventSet map[string]bool
+
+func (s eventSet) empty() bool {
+	return s != nil && len(s) == 0
+}
+
+func (s eventSet) intersect(o eventSet) eventSet {
+	if s == nil {
+		return o
+	}
+	if o == nil {
+		return s
+	}
+	out := eventSet{}
+	for e := range s {
+		if o[e] {
+			out[e] = true
+		}
+	}
+	return out
+}
+
+func (s eventSet) union(o eventSet) eventSet {
+	if s == nil || o == nil {
+		return nil
+	}
+	out := eventSet{}
+	for e := range s {
+		out[e] = true
+	}
+	for e := range o {
+		out[e] = true
+	}
+	return out
+}
+
+func (s eventSet) without(o eventSet) eventSet {
+	if s == nil || o == nil {
+		return nil
+	}
+	out := eventSet{}
+	for e := range s {
+		if !o[e] {
+			out[e] = true
+		}
+	}
+	return out
+}
+
+func (s eventSet) String() string {
+	names := make([]string, 0, len(s))
+	for e := range s {
+		names = append(names, e)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// jobReach is the analysis state of one job.
+type jobReach struct {
+	events    eventSet
+	resolving bool
+	done      bool
+}
+
+type reachability struct {
+	rule     *RuleUnreachableJob
+	jobs     map[string]*actionlint.Job
+	triggers eventSet
+	state    map[string]*jobReach
+}
+
+// VisitWorkflowPost analyzes the jobs once the whole workflow is known.
+func (rule *RuleUnreachableJob) VisitWorkflowPost(w *actionlint.Workflow) error {
+	a := &reachability{
+		rule:     rule,
+		jobs:     w.Jobs,
+		triggers: workflowEvents(w),
+		state:    make(map[string]*jobReach, len(w.Jobs)),
+	}
+
+	ids := make([]string, 0, len(w.Jobs))
+	for id := range w.Jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		a.resolve(id)
+	}
+	return nil
+}
+
+// workflowEvents returns the events triggering w, or nil when a reusable
+// workflow call makes github.event_name the caller's event.
+func workflowEvents(w *actionlint.Workflow) eventSet {
+	if len(w.On) == 0 {
+		return nil
+	}
+	events := eventSet{}
+	for _, e := range w.On {
+		name := strings.ToLower(e.EventName())
+		if name == "workflow_call" {
+			return nil
+		}
+		events[name] = true
+	}
+	return events
+}
+
+// resolve computes the events the job can run for and reports it when
+// there are none.
+func (a *reachability) resolve(id string) eventSet {
+	job, ok := a.jobs[id]
+	if !ok {
+		return nil // Unknown needs are reported by actionlint
+	}
+	st := a.state[id]
+	if st == nil {
+		st = &jobReach{}
+		a.state[id] = st
+	}
+	if st.done || st.resolving {
+		return st.events // Cycles are reported by actionlint
+	}
+	st.resolving = true
+	defer func() { st.resolving, st.done = false, true }()
+
+	own := a.triggers
+	runsAfterSkips := false
+	if cond := parseCondition(job.If); cond != nil {
+		if v, known := constantValue(cond); known && !v {
+			// Switched off, or already reported as a constant condition
+			st.events = eventSet{}
+			return st.events
+		}
+		allowed := eventsAllowed(cond, a.triggers)
+		own = a.triggers.intersect(allowed)
+		runsAfterSkips = usesStatusFunction(cond)
+
+		if own.empty() {
+			st.events = own
+			if eventsAllowed(cond, nil).empty() {
+				a.rule.Errorf(job.If.Pos, "job %q never runs because its if: condition requires contradictory values of github.event_name", job.ID.Value)
+			} else {
+				a.rule.Errorf(job.If.Pos, "job %q never runs because its if: condition only accepts events that do not trigger this workflow (%s)", job.ID.Value, a.triggers.String())
+			}
+			return st.events
+		}
+	}
+
+	st.events = own
+	if runsAfterSkips {
+		return st.events
+	}
+
+	for _, need := range job.Needs {
+		ne := a.resolve(strings.ToLower(need.Value))
+		if ne.empty() {
+			st.events = eventSet{}
+			a.rule.Errorf(need.Pos, "job %q never runs because it needs job %q, which never runs", job.ID.Value, need.Value)
+			return st.events
+		}
+		st.events = st.events.intersect(ne)
+	}
+	if st.events.empty() {
+		a.rule.Errorf(job.ID.Pos, "job %q never runs because its if: condition and those of the jobs it needs never hold for the same event", job.ID.Value)
+	}
+	return st.events
+}
+
+// eventsAllowed returns the events for which cond can be true, judging
+// only by comparisons of github.event_name with string literals. nil means
+// the condition does not restrict the event. triggers is the set of all
+// possible events, needed to negate a restriction.
+func eventsAllowed(cond actionlint.ExprNode, triggers eventSet) eventSet {
+	switch n := cond.(type) {
+	case *actionlint.CompareOpNode:
+		name, ok := eventNameComparison(n)
+		if !ok {
+			return nil
+		}
+		switch n.Kind {
+		case actionlint.CompareOpNodeKindEq:
+			return eventSet{name: true}
+		case actionlint.CompareOpNodeKindNotEq:
+			return triggers.without(eventSet{name: true})
+		}
+		return nil
+	case *actionlint.LogicalOpNode:
+		l := eventsAllowed(n.Left, triggers)
+		r := eventsAllowed(n.Right, triggers)
+		if n.Kind == actionlint.LogicalOpNodeKindAnd {
+			return l.intersect(r)
+		}
+		return l.union(r)
+	case *actionlint.NotOpNode:
+		return triggers.without(eventsAllowed(n.Operand, triggers))
+	default:
+		return nil
+	}
+}
+
+// eventNameComparison returns the event name when n compares
+// github.event_name with a string literal.
+func eventNameComparison(n *actionlint.CompareOpNode) (string, bool) {
+	left, right := n.Left, n.Right
+	if _, ok := left.(*actionlint.StringNode); ok {
+		left, right = right, left
+	}
+	lit, ok := right.(*actionlint.StringNode)
+	if !ok {
+		return "", false
+	}
+	deref, ok := left.(*actionlint.ObjectDerefNode)
+	if !ok || !strings.EqualFold(deref.Property, "event_name") {
+		return "", false
+	}
+	v, ok := deref.Receiver.(*actionlint.VariableNode)
+	if !ok || !strings.EqualFold(v.Name, "github") {
+		return "", false
+	}
+	return strings.ToLower(lit.Value), true
+}
+
+// usesStatusFunction reports whether cond calls a status check function
+// that lets the job run when jobs it needs were skipped.
+func usesStatusFunction(cond actionlint.ExprNode) bool {
+	found := false
+	actionlint.VisitExprNode(cond, func(n, _ actionlint.ExprNode, entering bool) {
+		if call, ok := n.(*actionlint.FuncCallNode); ok && entering {
+			switch strings.ToLower(call.Callee) {
+			case "always", "failure", "cancelled":
+				found = true
+			}
+		}
+	})
+	return found
+}
+
+// ConditionAllowsEvent reports whether an if: condition can hold for the
+// event, judging only by comparisons of github.event_name. Conditions that
+// cannot be parsed are assumed to allow any event.
+func ConditionAllowsEvent(cond *actionlint.String, event string) bool {
+	expr := parseCondition(cond)
+	if expr == nil {
+		return true
+	}
+	if v, known := constantValue(expr); known {
+		return v
+	}
+	e := strings.ToLower(event)
+	allowed := eventsAllowed(expr, eventSet{e: true})
+	return allowed == nil || allowed[e]
+}
+
+// ConditionRunsAfterSkips reports whether an if: condition calls a status
+// check function that lets a job run although jobs it needs were skipped.
+func ConditionRunsAfterSkips(cond *actionlint.String) bool {
+	expr := parseCondition(cond)
+	return expr != nil && usesStatusFunction(expr)
+}
@@ -0,0 +1,149 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnreachableJob(t *testing.T) {
+	newRule := func() actionlint.Rule { return NewUnreachableJob() }
+
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "reachable jobs",
+			src: `on: [push, pull_request]
+jobs:
+  build:
+    if: github.event_name == 'push'
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo build
+  deploy:
+    needs: build
+    if: github.event_name != 'pull_request'
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo deploy
+`,
+		},
+		{
+			name: "event that does not trigger the workflow",
+			src: `on: push
+jobs:
+  release:
+    if: github.event_name == 'release'
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo release
+`,
+			want: []string{`job "release" never runs because its if: condition only accepts events that do not trigger this workflow (push)`},
+		},
+		{
+			name: "contradictory condition",
+			src: `on: [push, pull_request]
+jobs:
+  test:
+    if: github.event_name == 'push' && github.event_name == 'pull_request'
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo test
+`,
+			want: []string{"requires contradictory values of github.event_name"},
+		},
+		{
+			name: "needs a disabled job",
+			src: `on: push
+jobs:
+  build:
+    if: false
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo build
+  deploy:
+    needs: [build]
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo deploy
+  notify:
+    needs: deploy
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo notify
+  cleanup:
+    needs: deploy
+    if: always()
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo cleanup
+`,
+			want: []string{
+				`job "deploy" never runs because it needs job "build", which never runs`,
+				`job "notify" never runs because it needs job "deploy", which never runs`,
+			},
+		},
+		{
+			name: "needs filtered to another event",
+			src: `on: [push, pull_request]
+jobs:
+  build:
+    if: github.event_name == 'pull_request'
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo build
+  deploy:
+    needs: build
+    if: github.event_name == 'push'
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo deploy
+`,
+			want: []string{"never hold for the same event"},
+		},
+		{
+			name: "reusable workflows take the caller's event",
+			src: `on: workflow_call
+jobs:
+  release:
+    if: github.event_name == 'release'
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo release
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := lintWith(t, newRule, tt.src)
+			require.Len(t, errs, len(tt.want))
+			for i, want := range tt.want {
+				assert.Equal(t, KindUnreachableJob, errs[i].Kind)
+				assert.Contains(t, errs[i].Message, want)
+			}
+		})
+	}
+}
+
+func TestConditionAllowsEvent(t *testing.T) {
+	cond := func(s string) *actionlint.String { return &actionlint.String{Value: s} }
+
+	assert.True(t, ConditionAllowsEvent(nil, "push"))
+	assert.True(t, ConditionAllowsEvent(cond("github.event_name == 'push'"), "push"))
+	assert.False(t, ConditionAllowsEvent(cond("${{ github.event_name == 'pull_request' }}"), "push"))
+	assert.False(t, ConditionAllowsEvent(cond("github.event_name != 'push'"), "push"))
+	assert.True(t, ConditionAllowsEvent(cond("github.ref == 'refs/heads/main'"), "push"), "other contexts are unknown")
+	assert.False(t, ConditionAllowsEvent(cond("false"), "push"))
+}
+
+func TestConditionRunsAfterSkips(t *testing.T) {
+	assert.False(t, ConditionRunsAfterSkips(nil))
+	assert.False(t, ConditionRunsAfterSkips(&actionlint.String{Value: "success()"}))
+	assert.True(t, ConditionRunsAfterSkips(&actionlint.String{Value: "${{ always() }}"}))
+}
@@ -0,0 +1,158 @@
+package rules
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindRelease is the name of RuleRelease.
+const KindRelease = "release-automation"
+
+// releaseAction is a release automation action and the permissions it
+// needs. opensPRs is set when it opens pull requests whose checks should
+// run.
+type releaseAction struct {
+	name     string
+	scopes   []string
+	opensPRs bool
+}
+
+var (
+	releaseActions = []releaseAction{
+		{"googleapis/release-please-action", []string{"contents", "pull-requests"}, true},
+		{"google-github-actions/release-please-action", []string{"contents", "pull-requests"}, true},
+		{"changesets/action", []string{"contents", "pull-requests"}, true},
+		{"peter-evans/create-pull-request", []string{"contents", "pull-requests"}, true},
+		{"softprops/action-gh-release", []string{"contents"}, false},
+	}
+
+	// provenancePublish matches publishing commands that authenticate or
+	// sign with an OIDC token.
+	provenancePublish = regexp.MustCompile(`\bnpm +publish\b[^\n]*--provenance\b`)
+	// tagCondition matches conditions that only hold for tag refs.
+	tagCondition = regexp.MustCompile(`startsWith\(\s*github\.ref\s*,\s*'refs/tags/|github\.ref_type\s*==\s*'tag'`)
+	// defaultToken matches the job's own token.
+	defaultToken = regexp.MustCompile(`^\s*\$\{\{\s*(secrets\.GITHUB_TOKEN|github\.token)\s*\}\}\s*$`)
+)
+
+// RuleRelease flags release automation that cannot work as configured:
+// release and publishing steps without the permissions they need,
+// pull requests opened with GITHUB_TOKEN, which never trigger the checks
+// that should run on them, and tag conditions in workflows that never run
+// for a tag.
+type RuleRelease struct {
+	actionlint.RuleBase
+	permissions *actionlint.Permissions
+	tagEvents   bool
+}
+
+// NewRelease creates a RuleRelease.
+func NewRelease() *RuleRelease {
+	return &RuleRelease{
+		RuleBase: actionlint.NewRuleBase(KindRelease, "Checks permissions, tokens and triggers of release automation"),
+	}
+}
+
+// VisitWorkflowPre records the workflow's permissions and whether any of
+// its events can run it for a tag.
+func (rule *RuleRelease) VisitWorkflowPre(n *actionlint.Workflow) error {
+	rule.permissions = n.Permissions
+	rule.tagEvents = false
+	for _, e := range n.On {
+		switch e := e.(type) {
+		case *actionlint.WorkflowDispatchEvent, *actionlint.WorkflowCallEvent:
+			rule.tagEvents = true
+		case *actionlint.WebhookEvent:
+			switch e.Hook.Value {
+			case "create", "release":
+				rule.tagEvents = true
+			case "push":
+				// Branch filters alone keep tag pushes out
+				if !e.Tags.IsEmpty() || !e.TagsIgnore.IsEmpty() || (e.Branches.IsEmpty() && e.BranchesIgnore.IsEmpty()) {
+					rule.tagEvents = true
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// VisitJobPre checks the release steps of the job and its tag conditions.
+func (rule *RuleRelease) VisitJobPre(n *actionlint.Job) error {
+	perms := n.Permissions
+	if perms == nil {
+		perms = rule.permissions
+	}
+
+	rule.checkTagCondition(n.If)
+	for _, s := range n.Steps {
+		rule.checkTagCondition(s.If)
+		switch exec := s.Exec.(type) {
+		case *actionlint.ExecAction:
+			rule.checkAction(exec, perms)
+		case *actionlint.ExecRun:
+			if exec.Run != nil && provenancePublish.MatchString(exec.Run.Value) && !granted(perms, "id-token", true) {
+				rule.Errorf(exec.Run.Pos, "\"npm publish --provenance\" needs \"id-token: write\" to sign the package, but the job's permissions do not grant it")
+			}
+		}
+	}
+	return nil
+}
+
+func (rule *RuleRelease) checkAction(exec *actionlint.ExecAction, perms *actionlint.Permissions) {
+	if exec.Uses == nil {
+		return
+	}
+	name, _, _ := strings.Cut(exec.Uses.Value, "@")
+
+	if strings.EqualFold(name, "pypa/gh-action-pypi-publish") {
+		if _, ok := exec.Inputs["password"]; !ok && !granted(perms, "id-token", true) {
+			rule.Errorf(exec.Uses.Pos, "%q publishes with trusted publishing when no password is given, which needs \"id-token: write\", but the job's permissions do not grant it", exec.Uses.Value)
+		}
+		return
+	}
+
+	for _, a := range releaseActions {
+		if !strings.EqualFold(name, a.name) {
+			continue
+		}
+		var missing []string
+		for _, scope := range a.scopes {
+			if !granted(perms, scope, false) {
+				missing = append(missing, "\""+scope+": write\"")
+			}
+		}
+		if len(missing) > 0 {
+			rule.Errorf(exec.Uses.Pos, "%q needs %s, but the job's permissions do not grant it", exec.Uses.Value, strings.Join(missing, " and "))
+		}
+
+		token, ok := exec.Inputs["token"]
+		if a.opensPRs && (!ok || token.Value == nil || defaultToken.MatchString(token.Value.Value)) {
+			rule.Errorf(exec.Uses.Pos, "%q opens pull requests with GITHUB_TOKEN, and events caused by GITHUB_TOKEN do not trigger workflows, so no checks run on them. pass a personal access token or GitHub App token as \"token\"", exec.Uses.Value)
+		}
+		return
+	}
+}
+
+func (rule *RuleRelease) checkTagCondition(cond *actionlint.String) {
+	if cond == nil || rule.tagEvents || !tagCondition.MatchString(cond.Value) {
+		return
+	}
+	rule.Errorf(cond.Pos, "condition only holds for tag refs, but none of the workflow's events run it for a tag, so it is always false. add a \"tags\" filter to the push event")
+}
+
+// granted reports whether perms grant write access to scope. Without a
+// permissions block the default depends on repository settings, so scopes
+// are assumed granted unless offByDefault, as id-token is.
+func granted(perms *actionlint.Permissions, scope string, offByDefault bool) bool {
+	if perms == nil {
+		return !offByDefault
+	}
+	if perms.All != nil {
+		return perms.All.Value == "write-all"
+	}
+	s, ok := perms.Scopes[scope]
+	return ok && s.Value != nil && s.Value.Value == "write"
+}
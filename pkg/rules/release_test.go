@@ -0,0 +1,73 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelease(t *testing.T) {
+	src := `on:
+  push:
+    branches: [main]
+permissions:
+  contents: read
+jobs:
+  release:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: write
+    steps:
+      - uses: googleapis/release-please-action@v4
+      - uses: changesets/action@v1
+        with:
+          token: ${{ secrets.RELEASE_TOKEN }}
+  publish:
+    if: startsWith(github.ref, 'refs/tags/v')
+    runs-on: ubuntu-latest
+    steps:
+      - run: npm publish --provenance --access public
+      - uses: pypa/gh-action-pypi-publish@release/v1
+      - uses: pypa/gh-action-pypi-publish@release/v1
+        with:
+          password: ${{ secrets.PYPI_TOKEN }}
+`
+	errs := lintWith(t, func() actionlint.Rule { return NewRelease() }, src)
+	require.Len(t, errs, 6)
+
+	assert.Equal(t, 12, errs[0].Line)
+	assert.Contains(t, errs[0].Message, `"googleapis/release-please-action@v4" needs "pull-requests: write"`)
+	assert.Equal(t, 12, errs[1].Line)
+	assert.Contains(t, errs[1].Message, "opens pull requests with GITHUB_TOKEN")
+	assert.Equal(t, 13, errs[2].Line)
+	assert.Contains(t, errs[2].Message, `"changesets/action@v1" needs "pull-requests: write"`)
+	assert.Equal(t, 17, errs[3].Line)
+	assert.Contains(t, errs[3].Message, "condition only holds for tag refs")
+	assert.Equal(t, 20, errs[4].Line)
+	assert.Contains(t, errs[4].Message, `"npm publish --provenance" needs "id-token: write"`)
+	assert.Equal(t, 21, errs[5].Line)
+	assert.Contains(t, errs[5].Message, "trusted publishing")
+	for _, e := range errs {
+		assert.Equal(t, KindRelease, e.Kind)
+	}
+}
+
+func TestRelease_DefaultPermissions(t *testing.T) {
+	src := `on:
+  push:
+    tags: ['v*']
+jobs:
+  release:
+    if: github.ref_type == 'tag'
+    runs-on: ubuntu-latest
+    steps:
+      - uses: softprops/action-gh-release@v2
+      - uses: pypa/gh-action-pypi-publish@release/v1
+`
+	errs := lintWith(t, func() actionlint.Rule { return NewRelease() }, src)
+	require.Len(t, errs, 1)
+	assert.Equal(t, 10, errs[0].Line)
+	assert.Contains(t, errs[0].Message, `"id-token: write"`)
+}
@@ -0,0 +1,141 @@
+// Package rules implements checks that actionlint-mcp runs in addition to
+// actionlint's own rules. Each rule is an actionlint.Rule, so findings are
+// reported the same way as built-in ones, with the rule name as their kind.
+package rules
+
+import (
+	"slices"
+
+	"github.com/rhysd/actionlint"
+)
+
+// Config configures the rules. The zero value uses the defaults of every
+// rule.
+type Config struct {
+	Actions          ActionsConfig          `yaml:"actions"`
+	Checkout         CheckoutConfig         `yaml:"checkout"`
+	CloudDeploy      CloudDeployConfig      `yaml:"cloud-deploy"`
+	DeprecatedInputs DeprecatedInputsConfig `yaml:"deprecated-inputs"`
+	Egress           EgressConfig           `yaml:"egress"`
+	FailureHandling  FailureConfig          `yaml:"failure-handling"`
+	JobTimeout       JobTimeoutConfig       `yaml:"job-timeout"`
+	Matrix           MatrixConfig           `yaml:"matrix"`
+	Naming           NamingConfig           `yaml:"naming"`
+	Permissions      PermissionsConfig      `yaml:"permissions"`
+	Pins             PinsConfig             `yaml:"pins"`
+	Reusable         ReusableConfig         `yaml:"reusable"`
+	RunnerTools      RunnerToolsConfig      `yaml:"runner-tools"`
+	Schedule         ScheduleConfig         `yaml:"schedule"`
+	Script           ScriptConfig           `yaml:"script"`
+	Spelling         SpellingConfig         `yaml:"spelling"`
+
+	// Packs are the rule packs turned on, which run the checks that do
+	// not run by default. See Packs.
+	Packs []string `yaml:"packs"`
+
+	// RequiredSteps are the required step policies jobs are checked
+	// against.
+	RequiredSteps []StepPolicy `yaml:"required-steps"`
+
+	// Variables are the configuration variables defined for the
+	// repository, for the undefined-variable rule. nil disables the rule.
+	Variables []string `yaml:"variables"`
+	// Secrets are the secrets available to the repository's workflows, for
+	// the undefined-secret rule. GITHUB_TOKEN is always available. nil
+	// disables the rule.
+	Secrets []string `yaml:"secrets"`
+	// Labels are the labels of the repository, for the undefined-label
+	// rule. nil disables the rule.
+	Labels []string `yaml:"labels"`
+
+	// Root is the root of the repository the linted workflow belongs to,
+	// for rules that look at other files. It is set by the linter, and
+	// empty for content outside a repository.
+	Root string `yaml:"-"`
+}
+
+// ReusableConfig configures the checks of calls between local reusable
+// workflows, which the linter runs across files.
+type ReusableConfig struct {
+	// MaxDepth is how many levels of workflows a chain of calls may
+	// connect, counting the caller. Zero means GitHub's limit of 4 and a
+	// negative value disables the depth check.
+	MaxDepth int `yaml:"max-depth"`
+}
+
+// Validate reports settings that cannot be used, such as invalid patterns.
+func (c Config) Validate() error {
+	if err := c.Naming.Validate(); err != nil {
+		return err
+	}
+	if err := c.Permissions.Validate(); err != nil {
+		return err
+	}
+	if err := c.Egress.Validate(); err != nil {
+		return err
+	}
+	if err := c.DeprecatedInputs.Validate(); err != nil {
+		return err
+	}
+	if err := c.RunnerTools.Validate(); err != nil {
+		return err
+	}
+	if err := c.Actions.Validate(); err != nil {
+		return err
+	}
+	if err := c.Pins.Validate(); err != nil {
+		return err
+	}
+	if err := ValidatePacks(c.Packs); err != nil {
+		return err
+	}
+	return ValidatePolicies(c.RequiredSteps)
+}
+
+// New returns fresh instances of the rules configured by cfg, leaving out
+// those of packs that are off. actionlint asks for new rules for every
+// workflow it checks, since rules collect the errors they report.
+func New(cfg Config) []actionlint.Rule {
+	cfg = cfg.resolve()
+	rs := []actionlint.Rule{
+		NewMatrixSize(cfg.Matrix),
+		NewMatrixInclude(),
+		NewConstantCondition(),
+		NewUnreachableJob(),
+		NewEventFilter(),
+		NewLongScript(cfg.Script),
+		NewEnvFile(),
+		NewSecretEnvFile(),
+		NewSetupCache(cfg.Root),
+		NewCheckout(cfg.Checkout),
+		NewFailureHandling(cfg.FailureHandling),
+		NewSpelling(cfg.Spelling, cfg.Root),
+		NewUndefinedVariable(cfg.Variables),
+		NewUndefinedSecret(cfg.Secrets),
+		NewUndefinedLabel(cfg.Labels),
+		NewPlaintextSecret(),
+		NewRelease(),
+		NewSchedule(cfg.Schedule),
+		NewRequiredSteps(cfg.RequiredSteps),
+		NewStepOrder(),
+		NewTokenPermissions(cfg.Permissions, cfg.Root),
+		NewEgress(cfg.Egress),
+		NewUnpinnedAction(cfg.Pins),
+		NewForkSafety(cfg.Permissions, cfg.Root),
+		NewDeprecatedInput(cfg.DeprecatedInputs, cfg.Root),
+		NewActionMetadata(cfg.Actions, cfg.Root),
+		NewRunnerShell(),
+		NewPortableScript(),
+		NewRunnerTool(cfg.RunnerTools, cfg.Root),
+		NewMatrixOS(),
+		NewGHCLI(),
+		NewCloudDeploy(cfg.CloudDeploy),
+		NewJobTimeout(cfg.JobTimeout),
+		NewShellStrictness(),
+		NewWorkingDirectory(cfg.Root),
+		NewLiteralExpression(),
+		NewFixes(),
+	}
+	rs = append(rs, NewNaming(cfg.Naming)...)
+	return slices.DeleteFunc(rs, func(r actionlint.Rule) bool { return !cfg.Active(r.Name()) })
+}
@@ -0,0 +1,63 @@
+package rules
+
+import (
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/require"
+)
+
+// lintWith lints src running only the rule made by newRule.
+func lintWith(t *testing.T, newRule func() actionlint.Rule, src string) []*actionlint.Error {
+	t.Helper()
+
+	l, err := actionlint.NewLinter(io.Discard, &actionlint.LinterOptions{
+		OnRulesCreated: func([]actionlint.Rule) []actionlint.Rule {
+			return []actionlint.Rule{newRule()}
+		},
+	})
+	require.NoError(t, err)
+
+	errs, err := l.Lint("test.yml", []byte(src), nil)
+	require.NoError(t, err)
+	return errs
+}
+
+func TestNew(t *testing.T) {
+	names := func(cfg Config) map[string]bool {
+		out := map[string]bool{}
+		for _, r := range New(cfg) {
+			out[r.Name()] = true
+		}
+		return out
+	}
+
+	// Without packs, only the rules reporting once configured run
+	defaults := names(Config{})
+	require.Equal(t, map[string]bool{
+		KindUndefinedVariable: true,
+		KindUndefinedSecret:   true,
+		KindUndefinedLabel:    true,
+		KindRequiredSteps:     true,
+		KindActionMetadata:    true,
+		"fixes":               true,
+	}, defaults)
+
+	all := names(Config{}.WithPacks("cloud", "cost", "security", "style"))
+	for _, p := range Packs {
+		for _, kind := range p.Kinds {
+			if !slices.Contains(linterKinds, kind) {
+				require.True(t, all[kind], "%s runs with pack %s", kind, p.Name)
+			}
+		}
+	}
+	require.False(t, all[KindNamingStepName], "conventions outside packs only run once configured")
+}
+
+// linterKinds are the kinds of packs the linter checks rather than rules.
+var linterKinds = []string{
+	KindContextAvailability, KindNotWorkflow, KindMultiDocument, KindReusableCalls, KindOutputContract, KindDockerAction,
+	KindDuplicateName, KindConcurrencyDeadlock, KindConcurrencyStarvation, KindUnknownRef,
+}
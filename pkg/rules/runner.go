@@ -0,0 +1,326 @@
+package rules
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// Rule names, reported as the kind of their findings.
+const (
+	KindRunnerShell    = "runner-shell"
+	KindPortableScript = "portable-script"
+)
+
+var (
+	// matrixReference matches an expression reading a matrix value, whose
+	// key is captured.
+	matrixReference = regexp.MustCompile(`\$\{\{\s*matrix\s*\.\s*([\w-]+)\s*\}\}`)
+	// windowsPath matches a relative or absolute Windows path, captured.
+	windowsPath = regexp.MustCompile(`(?:^|[\s"'=(])((?:[A-Za-z]:|\.{1,2})\\[\w.-]+(?:\\[\w.-]+)*)`)
+	// bashSyntax matches syntax that means something else, or nothing, in
+	// PowerShell.
+	bashSyntax = regexp.MustCompile(`\$\{[A-Za-z_]|\$[A-Z_][A-Z0-9_]*\b|\bexport\s+\w+=|\[\[|\bthen\b|\bfi\b|\bdone\b|\besac\b|/dev/null`)
+	// osCondition matches conditions that limit a step to some runners.
+	osCondition = regexp.MustCompile(`(?i)\brunner\s*\.\s*os\b|windows|linux|macos|ubuntu`)
+)
+
+// jobRunner is a runner a job may run on, named by its labels, and its
+// operating system: linux, macos, windows, or "" when the labels do not
+// tell. For runners a matrix expands runs-on to, key is the lower-case
+// matrix key runs-on reads and value its value.
+type jobRunner struct {
+	label string
+	os    string
+	key   string
+	value string
+}
+
+// jobRunners returns the runners the job may run on, expanding the matrix
+// values its runs-on reads, and whether the matrix was expanded. Runners
+// given by other expressions are left out.
+func jobRunners(n *actionlint.Job) (runners []jobRunner, fromMatrix bool) {
+	if n.RunsOn == nil {
+		return nil, false
+	}
+	labels := make([]string, 0, len(n.RunsOn.Labels)+1)
+	if n.RunsOn.LabelsExpr != nil {
+		labels = append(labels, n.RunsOn.LabelsExpr.Value)
+	}
+	for _, l := range n.RunsOn.Labels {
+		labels = append(labels, l.Value)
+	}
+
+	key := ""
+	for _, l := range labels {
+		if m := matrixReference.FindStringSubmatch(l); m != nil {
+			key = strings.ToLower(m[1])
+			break
+		}
+	}
+	if key == "" {
+		for _, l := range labels {
+			if strings.Contains(l, "${{") {
+				return nil, false
+			}
+		}
+		return []jobRunner{{label: strings.Join(labels, ", "), os: runnerOS(labels)}}, false
+	}
+
+	seen := map[string]bool{}
+	for _, v := range matrixValues(n, key) {
+		expanded := make([]string, len(labels))
+		for i, l := range labels {
+			expanded[i] = matrixReference.ReplaceAllStringFunc(l, func(ref string) string {
+				if strings.EqualFold(matrixReference.FindStringSubmatch(ref)[1], key) {
+					return v
+				}
+				return ref
+			})
+			if strings.Contains(expanded[i], "${{") {
+				expanded = nil
+				break
+			}
+		}
+		if label := strings.Join(expanded, ", "); expanded != nil && !seen[label] {
+			seen[label] = true
+			runners = append(runners, jobRunner{label: label, os: runnerOS(expanded), key: key, value: v})
+		}
+	}
+	return runners, true
+}
+
+// matrixValues returns the string values of the matrix key of the job,
+// from its row and its include entries. Matrices built by expressions have
+// none.
+func matrixValues(n *actionlint.Job, key string) []string {
+	m := staticMatrix(n)
+	if m == nil {
+		return nil
+	}
+	var values []string
+	if row, ok := m.Rows[key]; ok {
+		for _, v := range row.Values {
+			if s, ok := v.(*actionlint.RawYAMLString); ok {
+				values = append(values, s.Value)
+			}
+		}
+	}
+	if m.Include != nil {
+		for _, inc := range m.Include.Combinations {
+			if a, ok := inc.Assigns[key]; ok {
+				if s, ok := a.Value.(*actionlint.RawYAMLString); ok {
+					values = append(values, s.Value)
+				}
+			}
+		}
+	}
+	return values
+}
+
+// runnerOS returns the operating system the first telling label of labels
+// names, as actionlint's shell-name rule does, or "".
+func runnerOS(labels []string) string {
+	for _, l := range labels {
+		l = strings.ToLower(l)
+		switch {
+		case l == "windows" || strings.HasPrefix(l, "windows-"):
+			return "windows"
+		case l == "linux" || strings.HasPrefix(l, "ubuntu-"):
+			return "linux"
+		case l == "macos" || strings.HasPrefix(l, "macos-"):
+			return "macos"
+		}
+	}
+	return ""
+}
+
+// defaultShell returns the shell of a run: step on a runner with os.
+func defaultShell(os string) string {
+	if os == "windows" {
+		return "pwsh"
+	}
+	return "bash"
+}
+
+// defaultsShell returns the shell set by defaults, or nil.
+func defaultsShell(d *actionlint.Defaults) *actionlint.String {
+	if d == nil || d.Run == nil {
+		return nil
+	}
+	return d.Run.Shell
+}
+
+// RuleRunnerShell flags shells missing from a runner the job runs on: cmd
+// and powershell exist only on Windows, and sh everywhere but on Windows.
+// actionlint checks the shells of steps and job defaults against literal
+// runs-on labels, so this rule checks them against the runners a matrix
+// expands runs-on to, and the workflow's default shell against every job.
+type RuleRunnerShell struct {
+	actionlint.RuleBase
+	shell *actionlint.String
+}
+
+// NewRunnerShell creates a RuleRunnerShell.
+func NewRunnerShell() *RuleRunnerShell {
+	return &RuleRunnerShell{
+		RuleBase: actionlint.NewRuleBase(KindRunnerShell, "Checks that shells exist on the runners their jobs run on"),
+	}
+}
+
+// VisitWorkflowPre records the workflow's default shell.
+func (rule *RuleRunnerShell) VisitWorkflowPre(n *actionlint.Workflow) error {
+	rule.shell = defaultsShell(n.Defaults)
+	return nil
+}
+
+// VisitJobPre checks the shells of the job's run: steps.
+func (rule *RuleRunnerShell) VisitJobPre(n *actionlint.Job) error {
+	runners, fromMatrix := jobRunners(n)
+	if len(runners) == 0 {
+		return nil
+	}
+	jobShell := defaultsShell(n.Defaults)
+	if fromMatrix && jobShell != nil {
+		rule.check(n, jobShell, runners)
+	}
+	usesWorkflowShell := false
+	for _, s := range n.Steps {
+		exec, ok := s.Exec.(*actionlint.ExecRun)
+		if !ok {
+			continue
+		}
+		switch {
+		case exec.Shell != nil:
+			if fromMatrix {
+				rule.check(n, exec.Shell, runners)
+			}
+		case jobShell == nil && rule.shell != nil:
+			usesWorkflowShell = true
+		}
+	}
+	if usesWorkflowShell {
+		rule.check(n, rule.shell, runners)
+	}
+	return nil
+}
+
+// check reports the runners of the job that lack shell.
+func (rule *RuleRunnerShell) check(n *actionlint.Job, shell *actionlint.String, runners []jobRunner) {
+	if shell.ContainsExpression() || strings.Contains(shell.Value, "{0}") {
+		return
+	}
+	name := strings.ToLower(shell.Value)
+	var lacking []string
+	for _, r := range runners {
+		switch {
+		case r.os == "":
+		case (name == "cmd" || name == "powershell") && r.os != "windows", name == "sh" && r.os == "windows":
+			lacking = append(lacking, r.label)
+		}
+	}
+	if len(lacking) == 0 {
+		return
+	}
+	if name == "sh" {
+		rule.Errorf(shell.Pos, "shell %q does not exist on Windows, but job %q runs on %s. use bash, which every runner has", shell.Value, n.ID.Value, strings.Join(lacking, " and "))
+		return
+	}
+	rule.Errorf(shell.Pos, "shell %q only exists on Windows, but job %q runs on %s. use pwsh, which every runner has, or limit the shell to Windows runners", shell.Value, n.ID.Value, strings.Join(lacking, " and "))
+}
+
+// RulePortableScript flags run: scripts written for another runner than
+// one their job runs on: Windows paths in scripts run by bash or sh, which
+// take backslashes as escapes, and bash scripts without shell: in jobs
+// whose matrix runs them on Windows, where they run in PowerShell, which
+// it offers a fix setting shell: bash on for every such step of the job.
+// Steps whose if: limits them to some runners are skipped.
+type RulePortableScript struct {
+	actionlint.RuleBase
+	fixes
+	shell *actionlint.String
+}
+
+// NewPortableScript creates a RulePortableScript.
+func NewPortableScript() *RulePortableScript {
+	return &RulePortableScript{
+		RuleBase: actionlint.NewRuleBase(KindPortableScript, "Checks that run: scripts work on every runner their job runs on"),
+	}
+}
+
+// VisitWorkflowPre records the workflow's default shell.
+func (rule *RulePortableScript) VisitWorkflowPre(n *actionlint.Workflow) error {
+	rule.shell = defaultsShell(n.Defaults)
+	return nil
+}
+
+// VisitJobPre checks the job's run: steps.
+func (rule *RulePortableScript) VisitJobPre(n *actionlint.Job) error {
+	runners, _ := jobRunners(n)
+	if len(runners) == 0 {
+		return nil
+	}
+	jobShell := defaultsShell(n.Defaults)
+	if jobShell == nil {
+		jobShell = rule.shell
+	}
+
+	var (
+		reportedShell *actionlint.Pos
+		shellEdits    []Edit
+	)
+	for _, s := range n.Steps {
+		exec, ok := s.Exec.(*actionlint.ExecRun)
+		if !ok || exec.Run == nil {
+			continue
+		}
+		if s.If != nil && osCondition.MatchString(s.If.Value) {
+			continue
+		}
+		shell := exec.Shell
+		if shell == nil {
+			shell = jobShell
+		}
+		if shell != nil && shell.ContainsExpression() {
+			continue
+		}
+
+		// The shell of the step on each runner
+		var bash, pwsh []string
+		for _, r := range runners {
+			name := ""
+			switch {
+			case shell != nil:
+				name = strings.ToLower(shell.Value)
+			case r.os != "":
+				name = defaultShell(r.os)
+			}
+			switch name {
+			case "bash", "sh":
+				bash = append(bash, r.label)
+			case "pwsh":
+				if shell == nil {
+					pwsh = append(pwsh, r.label)
+				}
+			}
+		}
+
+		if m := windowsPath.FindStringSubmatch(exec.Run.Value); m != nil && len(bash) > 0 {
+			rule.Errorf(exec.Run.Pos, "script uses the Windows path %q, but runs with bash on %s, which takes its backslashes as escapes. use forward slashes, which Windows accepts too", m[1], strings.Join(bash, " and "))
+		}
+		if len(bash) > 0 && len(pwsh) > 0 && bashSyntax.MatchString(exec.Run.Value) {
+			if reportedShell == nil {
+				reportedShell = exec.Run.Pos
+				rule.Errorf(exec.Run.Pos, "script is written for bash, but has no shell: and job %q runs on %s, where run: steps use pwsh by default. set shell: bash on the step, or defaults: run: shell: bash on the job", n.ID.Value, strings.Join(pwsh, " and "))
+			}
+			if s.Pos != nil && s.Pos.Col > 1 {
+				shellEdits = append(shellEdits, Edit{Line: s.Pos.Line, Column: s.Pos.Col, New: "shell: bash\n" + strings.Repeat(" ", s.Pos.Col-1)})
+			}
+		}
+	}
+	if reportedShell != nil && len(shellEdits) > 0 {
+		rule.addFix(KindPortableScript, reportedShell, "Run the job's bash scripts with shell: bash", shellEdits...)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunnerShell(t *testing.T) {
+	newRule := func() actionlint.Rule { return NewRunnerShell() }
+
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "matrix",
+			src: `jobs:
+  build:
+    strategy:
+      matrix:
+        os: [ubuntu-latest, windows-latest, macos-latest]
+    runs-on: ${{ matrix.os }}
+    steps:
+      - run: dir
+        shell: cmd
+      - run: ls
+        shell: sh
+      - run: ls
+        shell: bash`,
+			want: []string{
+				`shell "cmd" only exists on Windows, but job "build" runs on ubuntu-latest and macos-latest`,
+				`shell "sh" does not exist on Windows, but job "build" runs on windows-latest`,
+			},
+		},
+		{
+			name: "matrix include",
+			src: `jobs:
+  build:
+    strategy:
+      matrix:
+        include:
+          - os: windows-2022
+          - os: ubuntu-22.04
+    runs-on: ${{ matrix.os }}
+    defaults:
+      run:
+        shell: powershell
+    steps:
+      - run: Get-ChildItem`,
+			want: []string{`shell "powershell" only exists on Windows, but job "build" runs on ubuntu-22.04`},
+		},
+		{
+			name: "workflow defaults",
+			src: `defaults:
+  run:
+    shell: cmd
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+  windows:
+    runs-on: windows-latest
+    steps:
+      - run: echo
+  own:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+        shell: bash`,
+			want: []string{`shell "cmd" only exists on Windows, but job "build" runs on ubuntu-latest`},
+		},
+		{
+			name: "literal runs-on left to actionlint",
+			src: `jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: dir
+        shell: cmd`,
+		},
+		{
+			name: "unknown runners",
+			src: `jobs:
+  build:
+    runs-on: ${{ inputs.runner }}
+    steps:
+      - run: dir
+        shell: cmd
+  self-hosted:
+    strategy:
+      matrix:
+        runner: [[self-hosted, gpu]]
+    runs-on: ${{ matrix.runner }}
+    steps:
+      - run: ls
+        shell: sh`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := lintWith(t, newRule, "on: push\n"+tt.src+"\n")
+			require.Len(t, errs, len(tt.want), "%v", errs)
+			for i, want := range tt.want {
+				assert.Equal(t, KindRunnerShell, errs[i].Kind)
+				assert.Contains(t, errs[i].Message, want)
+			}
+		})
+	}
+}
+
+func TestPortableScript(t *testing.T) {
+	newRule := func() actionlint.Rule { return NewPortableScript() }
+
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "windows paths",
+			src: `    runs-on: ubuntu-latest
+    steps:
+      - run: .\scripts\build.sh --out "C:\out"
+      - run: printf 'a\nb\n' | sed 's/\./,/'
+      - run: .\build.ps1
+        shell: pwsh`,
+			want: []string{`script uses the Windows path ".\\scripts\\build.sh", but runs with bash on ubuntu-latest`},
+		},
+		{
+			name: "bash on a matrix with windows",
+			src: `    strategy:
+      matrix:
+        os: [ubuntu-latest, windows-latest]
+    runs-on: ${{ matrix.os }}
+    steps:
+      - run: npm test
+      - run: echo "version=$VERSION" >> $GITHUB_OUTPUT
+      - run: export CI=1
+      - if: runner.os == 'Linux'
+        run: |
+          if [[ -f x ]]; then rm x; fi
+      - run: echo "$HOME"
+        shell: bash`,
+			want: []string{`script is written for bash, but has no shell: and job "build" runs on windows-latest, where run: steps use pwsh by default`},
+		},
+		{
+			name: "job default shell",
+			src: `    strategy:
+      matrix:
+        os: [ubuntu-latest, windows-latest]
+    runs-on: ${{ matrix.os }}
+    defaults:
+      run:
+        shell: bash
+    steps:
+      - run: echo "$HOME"
+      - run: cp .\a .\b`,
+			want: []string{`script uses the Windows path ".\\a", but runs with bash on ubuntu-latest and windows-latest`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "on: push\njobs:\n  build:\n" + tt.src + "\n"
+			errs := lintWith(t, newRule, src)
+			require.Len(t, errs, len(tt.want), "%v", errs)
+			for i, want := range tt.want {
+				assert.Equal(t, KindPortableScript, errs[i].Kind)
+				assert.Contains(t, errs[i].Message, want)
+			}
+		})
+	}
+}
+
+func TestPortableScript_Fix(t *testing.T) {
+	src := `on: push
+jobs:
+  build:
+    strategy:
+      matrix:
+        os: [ubuntu-latest, windows-latest]
+    runs-on: ${{ matrix.os }}
+    steps:
+      - run: npm test
+      - name: Version
+        run: echo "version=$VERSION" >> $GITHUB_OUTPUT
+      - run: export CI=1
+`
+	rule := NewPortableScript()
+	errs := lintWith(t, func() actionlint.Rule { return rule }, src)
+	require.Len(t, errs, 1)
+
+	fixes := rule.Fixes()
+	require.Len(t, fixes, 1)
+	assert.Equal(t, KindPortableScript, fixes[0].Kind)
+	assert.Equal(t, []Edit{
+		{Line: 10, Column: 9, New: "shell: bash\n        "},
+		{Line: 12, Column: 9, New: "shell: bash\n        "},
+	}, fixes[0].Edits, "every bash script of the job is fixed")
+}
@@ -0,0 +1,266 @@
+package rules
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindRunnerTool is the name of RuleRunnerTool.
+const KindRunnerTool = "runner-tool"
+
+// RunnerToolsConfig configures the dataset of the tools preinstalled on
+// some hosted runner images only, which the runner-tool rule uses.
+type RunnerToolsConfig struct {
+	// Tools add to and override the embedded dataset, by command. A tool
+	// without runners is no longer checked.
+	Tools map[string]RunnerTool `yaml:"tools"`
+	// File is a dataset in the embedded format, relative to the repository
+	// root, which adds to and overrides the embedded one. Tools override it
+	// in turn.
+	File string `yaml:"file"`
+}
+
+// RunnerTool is where a tool is preinstalled: the operating systems
+// (linux, macos, windows) and image labels of the runners that have it,
+// the action that sets it up on the others, if any, and a hint.
+type RunnerTool struct {
+	Runners []string `yaml:"runners"`
+	Setup   string   `yaml:"setup"`
+	Hint    string   `yaml:"hint"`
+}
+
+// Validate reports tools without runners that set the other fields.
+func (c RunnerToolsConfig) Validate() error {
+	for name, tool := range c.Tools {
+		if len(tool.Runners) == 0 && (tool.Setup != "" || tool.Hint != "") {
+			return fmt.Errorf("runner-tools: %s: runners must list the runners the tool is on", name)
+		}
+	}
+	return nil
+}
+
+// RunnerTools maps commands to the runners they are preinstalled on.
+type RunnerTools map[string]RunnerTool
+
+//go:embed runner-tools.txt
+var runnerToolsData string
+
+var (
+	runnerToolsOnce sync.Once
+	runnerTools     RunnerTools
+)
+
+// parseRunnerTools adds the dataset in data to into.
+func parseRunnerTools(data string, into RunnerTools) error {
+	for i, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) < 3 {
+			return fmt.Errorf("line %d: want a command, its runners, a setup action or - and an optional hint", i+1)
+		}
+		tool := RunnerTool{Runners: strings.Split(strings.ToLower(fields[1]), ","), Hint: strings.Join(fields[3:], " ")}
+		if fields[2] != "-" {
+			tool.Setup = fields[2]
+		}
+		into[fields[0]] = tool
+	}
+	return nil
+}
+
+// embeddedRunnerTools parses the embedded dataset once.
+func embeddedRunnerTools() RunnerTools {
+	runnerToolsOnce.Do(func() {
+		runnerTools = RunnerTools{}
+		if err := parseRunnerTools(runnerToolsData, runnerTools); err != nil {
+			panic("runner-tools.txt: " + err.Error())
+		}
+	})
+	return runnerTools
+}
+
+// LoadRunnerTools returns the embedded dataset with the changes of cfg.
+// root is the repository root that cfg.File is relative to.
+func LoadRunnerTools(cfg RunnerToolsConfig, root string) (RunnerTools, error) {
+	out := RunnerTools{}
+	for name, tool := range embeddedRunnerTools() {
+		out[name] = tool
+	}
+	if cfg.File != "" {
+		file := cfg.File
+		if !filepath.IsAbs(file) && root != "" {
+			file = filepath.Join(root, file)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return out, fmt.Errorf("runner tools file %s cannot be read: %w", file, err)
+		}
+		if err := parseRunnerTools(string(data), out); err != nil {
+			return out, fmt.Errorf("runner tools file %s: %w", file, err)
+		}
+	}
+	for name, tool := range cfg.Tools {
+		if len(tool.Runners) == 0 {
+			delete(out, name)
+			continue
+		}
+		runners := make([]string, len(tool.Runners))
+		for i, r := range tool.Runners {
+			runners[i] = strings.ToLower(r)
+		}
+		tool.Runners = runners
+		out[name] = tool
+	}
+	return out, nil
+}
+
+// toolPattern is a tool of the dataset with the patterns matching a
+// script that runs it and one that checks for it first.
+type toolPattern struct {
+	name  string
+	tool  RunnerTool
+	run   *regexp.Regexp
+	check *regexp.Regexp
+}
+
+// RuleRunnerTool flags run: scripts that run a tool missing from a hosted
+// runner their job runs on, such as docker on macOS or apt-get on Windows,
+// according to a dataset of the tools preinstalled on some runner images
+// only. Steps whose if: compares runner.os or the matrix value runs-on
+// reads are checked on the runners it holds for. Runners given by other
+// expressions, self-hosted runners and jobs in containers are not checked,
+// nor are steps with other OS conditions, scripts that check for the tool
+// first, and tools an earlier step of the job sets up or installs.
+type RuleRunnerTool struct {
+	actionlint.RuleBase
+	tools   []toolPattern
+	loadErr error
+}
+
+// NewRunnerTool creates a RuleRunnerTool. root is the repository root that
+// cfg.File is relative to.
+func NewRunnerTool(cfg RunnerToolsConfig, root string) *RuleRunnerTool {
+	tools, err := LoadRunnerTools(cfg, root)
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	rule := &RuleRunnerTool{
+		RuleBase: actionlint.NewRuleBase(KindRunnerTool, "Checks that run: scripts only run tools the job's hosted runners have"),
+		loadErr:  err,
+	}
+	for _, name := range names {
+		quoted := regexp.QuoteMeta(name)
+		rule.tools = append(rule.tools, toolPattern{
+			name:  name,
+			tool:  tools[name],
+			run:   regexp.MustCompile(`(?im)(?:^|[;&|(` + "`" + `]|\bsudo\s+|\b(?:then|do|else|exec|time)\s+)\s*` + quoted + `(?:\.exe)?(?:[\s;&|)]|$)`),
+			check: regexp.MustCompile(`(?i)\b(?:command\s+-v|which|type|hash|Get-Command)\s+` + quoted + `\b|\binstall\b.*\b` + quoted + `\b`),
+		})
+	}
+	return rule
+}
+
+// VisitWorkflowPre reports a dataset that cannot be loaded.
+func (rule *RuleRunnerTool) VisitWorkflowPre(n *actionlint.Workflow) error {
+	if rule.loadErr != nil {
+		rule.Errorf(&actionlint.Pos{Line: 1, Col: 1}, "%v", rule.loadErr)
+	}
+	return nil
+}
+
+// VisitJobPre checks the job's run: steps against its hosted runners.
+func (rule *RuleRunnerTool) VisitJobPre(n *actionlint.Job) error {
+	if n.Container != nil {
+		return nil
+	}
+	all, _ := jobRunners(n)
+	var runners []jobRunner
+	for _, r := range all {
+		if r.os != "" && !strings.Contains(strings.ToLower(r.label), "self-hosted") {
+			runners = append(runners, r)
+		}
+	}
+	if len(runners) == 0 {
+		return nil
+	}
+
+	// Tools set up or installed by earlier steps
+	installed := map[string]bool{}
+	for _, s := range n.Steps {
+		switch exec := s.Exec.(type) {
+		case *actionlint.ExecAction:
+			if exec.Uses == nil {
+				continue
+			}
+			action, _, _ := strings.Cut(exec.Uses.Value, "@")
+			for _, t := range rule.tools {
+				if t.tool.Setup != "" && strings.EqualFold(action, t.tool.Setup) {
+					installed[t.name] = true
+				}
+			}
+		case *actionlint.ExecRun:
+			if exec.Run == nil || (exec.Shell != nil && strings.Contains(exec.Shell.Value, "{0}")) {
+				continue
+			}
+			// Conditions limiting the step to some runners are evaluated
+			// for each, and steps they cannot be told for are skipped
+			if on, known := runnersOf(s, runners); known || !osCondition.MatchString(s.If.Value) {
+				rule.check(n, exec, on, installed)
+			}
+			for _, t := range rule.tools {
+				if t.check.MatchString(exec.Run.Value) {
+					installed[t.name] = true
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// check reports the first tool script runs that a runner lacks.
+func (rule *RuleRunnerTool) check(n *actionlint.Job, exec *actionlint.ExecRun, runners []jobRunner, installed map[string]bool) {
+	for _, t := range rule.tools {
+		if installed[t.name] || !t.run.MatchString(exec.Run.Value) || t.check.MatchString(exec.Run.Value) {
+			continue
+		}
+		var lacking []string
+		for _, r := range runners {
+			if !slices.Contains(t.tool.Runners, r.os) && !slices.ContainsFunc(strings.Split(strings.ToLower(r.label), ", "), func(l string) bool {
+				return slices.Contains(t.tool.Runners, l)
+			}) {
+				lacking = append(lacking, r.label)
+			}
+		}
+		if len(lacking) == 0 {
+			continue
+		}
+		msg := fmt.Sprintf("script runs %q, which is not installed on %s, a runner job %q runs on", t.name, strings.Join(lacking, " and "), n.ID.Value)
+		if len(lacking) > 1 {
+			msg = fmt.Sprintf("script runs %q, which is not installed on %s, runners job %q runs on", t.name, strings.Join(lacking, " and "), n.ID.Value)
+		}
+		switch {
+		case t.tool.Setup != "" && t.tool.Hint != "":
+			msg += fmt.Sprintf(". set it up with %s first; %s", t.tool.Setup, t.tool.Hint)
+		case t.tool.Setup != "":
+			msg += fmt.Sprintf(". set it up with %s first", t.tool.Setup)
+		case t.tool.Hint != "":
+			msg += ". " + t.tool.Hint
+		default:
+			msg += ". limit the step to the runners that have it with if: runner.os"
+		}
+		rule.Errorf(exec.Run.Pos, "%s", msg)
+		return
+	}
+}
@@ -0,0 +1,99 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunnerTool(t *testing.T) {
+	src := `on: push
+jobs:
+  build:
+    strategy:
+      matrix:
+        os: [ubuntu-latest, macos-latest, windows-latest]
+    runs-on: ${{ matrix.os }}
+    steps:
+      - run: docker build .
+      - run: |
+          npm ci
+          sudo apt-get install -y libfoo
+      - if: runner.os == 'Linux'
+        run: xvfb-run npm test
+      - if: runner.os != 'Windows'
+        run: xcodebuild -version
+      - run: command -v brew && brew bundle
+      - run: echo "use docker or apt-get"
+  mac:
+    runs-on: macos-14
+    steps:
+      - run: brew install podman
+      - run: podman build .
+      - uses: docker/setup-docker-action@v4
+      - run: docker run hello-world
+  linux:
+    runs-on: ubuntu-24.04
+    steps:
+      - run: choco install jq
+      - run: docker build .
+  self-hosted:
+    runs-on: [self-hosted, macos]
+    steps:
+      - run: apt-get update
+  container:
+    runs-on: windows-latest
+    container: ubuntu
+    steps:
+      - run: apt-get update
+`
+	errs := lintWith(t, func() actionlint.Rule { return NewRunnerTool(RunnerToolsConfig{}, "") }, src)
+	require.Len(t, errs, 4)
+
+	assert.Equal(t, 9, errs[0].Line)
+	assert.Equal(t, KindRunnerTool, errs[0].Kind)
+	assert.Contains(t, errs[0].Message, `script runs "docker", which is not installed on macos-latest, a runner job "build" runs on. set it up with docker/setup-docker-action first`)
+	assert.Contains(t, errs[1].Message, `script runs "apt-get", which is not installed on macos-latest and windows-latest, runners job "build" runs on. install packages with brew`)
+	assert.Contains(t, errs[2].Message, `script runs "xcodebuild", which is not installed on ubuntu-latest, a runner job "build" runs on`, "only the runners the if: holds for are checked")
+	assert.Equal(t, 29, errs[3].Line)
+	assert.Contains(t, errs[3].Message, `"choco"`)
+}
+
+func TestRunnerTool_Config(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "tools.txt"), []byte("# Ours\nacme-cli linux,macos-13 acme/setup-cli\n"), 0644))
+
+	src := `on: push
+jobs:
+  build:
+    strategy:
+      matrix:
+        os: [ubuntu-latest, macos-13, macos-14]
+    runs-on: ${{ matrix.os }}
+    steps:
+      - run: acme-cli deploy
+      - run: xcodebuild -version
+      - run: sudo make install
+`
+	cfg := RunnerToolsConfig{
+		File: "tools.txt",
+		Tools: map[string]RunnerTool{
+			"xcodebuild": {Runners: []string{"macOS", "linux"}},
+			"sudo":       {},
+		},
+	}
+	errs := lintWith(t, func() actionlint.Rule { return NewRunnerTool(cfg, root) }, src)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, `script runs "acme-cli", which is not installed on macos-14, a runner job "build" runs on. set it up with acme/setup-cli first`)
+
+	errs = lintWith(t, func() actionlint.Rule { return NewRunnerTool(RunnerToolsConfig{File: "missing.txt"}, root) }, src)
+	require.NotEmpty(t, errs)
+	assert.Contains(t, errs[0].Message, "missing.txt cannot be read")
+
+	assert.Error(t, RunnerToolsConfig{Tools: map[string]RunnerTool{"acme-cli": {Setup: "acme/setup-cli"}}}.Validate())
+	assert.Error(t, parseRunnerTools("acme-cli linux\n", RunnerTools{}))
+}
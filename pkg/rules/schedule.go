@@ -0,0 +1,176 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindSchedule is the name of RuleSchedule.
+const KindSchedule = "schedule"
+
+// DefaultMinScheduleInterval is the shortest time between two runs of a
+// schedule allowed by default, in minutes.
+const DefaultMinScheduleInterval = 15
+
+// minutesPerDay is the length of the day cron schedules repeat over.
+const minutesPerDay = 24 * 60
+
+// ScheduleConfig configures the schedule rule.
+type ScheduleConfig struct {
+	// MinInterval is the shortest time between two runs of a cron schedule,
+	// in minutes. Zero means DefaultMinScheduleInterval and a negative value
+	// disables the check.
+	MinInterval int `yaml:"min-interval"`
+	// Fork is set when the repository is a fork, where scheduled workflows
+	// do not run until workflows are enabled for it.
+	Fork bool `yaml:"fork"`
+}
+
+// RuleSchedule flags cron schedules that run more often than configured,
+// and, in a fork, scheduled workflows that do not run there.
+type RuleSchedule struct {
+	actionlint.RuleBase
+	min  int
+	fork bool
+}
+
+// NewSchedule creates a RuleSchedule.
+func NewSchedule(cfg ScheduleConfig) *RuleSchedule {
+	min := cfg.MinInterval
+	if min == 0 {
+		min = DefaultMinScheduleInterval
+	}
+	return &RuleSchedule{
+		RuleBase: actionlint.NewRuleBase(KindSchedule, "Checks how often scheduled workflows run"),
+		min:      min,
+		fork:     cfg.Fork,
+	}
+}
+
+// VisitWorkflowPre checks the cron schedules of the workflow.
+func (rule *RuleSchedule) VisitWorkflowPre(n *actionlint.Workflow) error {
+	for _, e := range n.On {
+		sched, ok := e.(*actionlint.ScheduledEvent)
+		if !ok {
+			continue
+		}
+		if rule.fork {
+			rule.Errorf(sched.Pos, "scheduled workflows do not run in a fork until workflows are enabled in its Actions tab, and then only on the default branch")
+		}
+		if rule.min < 0 {
+			continue
+		}
+		for _, c := range sched.Cron {
+			minutes, err := ScheduleMinutes(c.Value)
+			if err != nil {
+				continue // actionlint reports invalid cron expressions
+			}
+			if gap := shortestGap(minutes); gap < rule.min {
+				rule.Errorf(c.Pos, "cron schedule %q runs every %s, more often than the minimum interval of %s. scheduled runs use minutes of the runner budget even when nothing changed", c.Value, describeMinutes(gap), describeMinutes(rule.min))
+			}
+		}
+	}
+	return nil
+}
+
+// ScheduleMinutes returns the minutes of the day, in UTC and in order, at
+// which the cron expression runs on the days it runs at all.
+func ScheduleMinutes(cron string) ([]int, error) {
+	fields := strings.Fields(cron)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q does not have 5 fields", cron)
+	}
+	mins, err := cronField(fields[0], 0, 59)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := cronField(fields[1], 0, 23)
+	if err != nil {
+		return nil, err
+	}
+	var minutes []int
+	for _, h := range hours {
+		for _, m := range mins {
+			minutes = append(minutes, h*60+m)
+		}
+	}
+	sort.Ints(minutes)
+	return minutes, nil
+}
+
+// cronField expands a numeric cron field: lists of *, values and ranges,
+// each with an optional /step.
+func cronField(field string, lo, hi int) ([]int, error) {
+	set := map[int]bool{}
+	for _, part := range strings.Split(field, ",") {
+		spec, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step in cron field %q", field)
+			}
+		}
+		from, to := lo, hi
+		if spec != "*" {
+			first, last, isRange := strings.Cut(spec, "-")
+			var err error
+			if from, err = strconv.Atoi(first); err != nil {
+				return nil, fmt.Errorf("invalid cron field %q", field)
+			}
+			to = from
+			if isRange {
+				if to, err = strconv.Atoi(last); err != nil {
+					return nil, fmt.Errorf("invalid cron field %q", field)
+				}
+			} else if hasStep {
+				to = hi
+			}
+		}
+		if from < lo || to > hi || from > to {
+			return nil, fmt.Errorf("cron field %q is out of range", field)
+		}
+		for v := from; v <= to; v += step {
+			set[v] = true
+		}
+	}
+	values := make([]int, 0, len(set))
+	for v := range set {
+		values = append(values, v)
+	}
+	sort.Ints(values)
+	return values, nil
+}
+
+// shortestGap returns the shortest time in minutes between two runs at the
+// given minutes of the day, counting the gap to the next day's first run.
+func shortestGap(minutes []int) int {
+	gap := minutesPerDay
+	for i, m := range minutes {
+		next := minutes[0] + minutesPerDay
+		if i+1 < len(minutes) {
+			next = minutes[i+1]
+		}
+		if next-m < gap {
+			gap = next - m
+		}
+	}
+	return gap
+}
+
+func describeMinutes(n int) string {
+	switch {
+	case n == 1:
+		return "minute"
+	case n == 60:
+		return "hour"
+	case n%60 == 0:
+		return fmt.Sprintf("%d hours", n/60)
+	default:
+		return fmt.Sprintf("%d minutes", n)
+	}
+}
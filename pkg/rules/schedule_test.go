@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleMinutes(t *testing.T) {
+	minutes, err := ScheduleMinutes("*/20 9-10 * * 1-5")
+	require.NoError(t, err)
+	assert.Equal(t, []int{540, 560, 580, 600, 620, 640}, minutes)
+
+	minutes, err = ScheduleMinutes("5,35 0/12 * * *")
+	require.NoError(t, err)
+	assert.Equal(t, []int{5, 35, 725, 755}, minutes)
+
+	_, err = ScheduleMinutes("0 24 * * *")
+	assert.Error(t, err)
+	_, err = ScheduleMinutes("@daily")
+	assert.Error(t, err)
+}
+
+func TestShortestGap(t *testing.T) {
+	assert.Equal(t, 20, shortestGap([]int{540, 560, 580}))
+	assert.Equal(t, 1440, shortestGap([]int{0}))
+	assert.Equal(t, 10, shortestGap([]int{5, 1435}))
+}
+
+func TestSchedule(t *testing.T) {
+	src := `on:
+  schedule:
+    - cron: '*/5 * * * *'
+    - cron: '0 * * * *'
+    - cron: '30 2 * * *'
+jobs:
+  nightly:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make nightly
+`
+	errs := lintWith(t, func() actionlint.Rule { return NewSchedule(ScheduleConfig{}) }, src)
+	require.Len(t, errs, 1)
+	assert.Equal(t, 3, errs[0].Line)
+	assert.Contains(t, errs[0].Message, `cron schedule "*/5 * * * *" runs every 5 minutes, more often than the minimum interval of 15 minutes`)
+
+	errs = lintWith(t, func() actionlint.Rule { return NewSchedule(ScheduleConfig{MinInterval: 120}) }, src)
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[1].Message, "runs every hour, more often than the minimum interval of 2 hours")
+
+	errs = lintWith(t, func() actionlint.Rule { return NewSchedule(ScheduleConfig{MinInterval: -1, Fork: true}) }, src)
+	require.Len(t, errs, 1)
+	assert.Equal(t, 2, errs[0].Line)
+	assert.Contains(t, errs[0].Message, "do not run in a fork")
+}
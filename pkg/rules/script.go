@@ -0,0 +1,81 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindLongScript is the name of RuleLongScript.
+const KindLongScript = "long-script"
+
+// DefaultMaxScriptLines is the longest run: script allowed by default.
+const DefaultMaxScriptLines = 50
+
+// ScriptConfig configures the script rules.
+type ScriptConfig struct {
+	// MaxLines is the largest number of lines a run: script may have. Zero
+	// means DefaultMaxScriptLines and a negative value disables the check.
+	MaxLines int `yaml:"max-lines"`
+}
+
+// RuleLongScript flags run: scripts long enough to be easier to read, test
+// and lint as a script file in the repository.
+type RuleLongScript struct {
+	actionlint.RuleBase
+	max  int
+	step int
+}
+
+// NewLongScript creates a RuleLongScript.
+func NewLongScript(cfg ScriptConfig) *RuleLongScript {
+	max := cfg.MaxLines
+	if max == 0 {
+		max = DefaultMaxScriptLines
+	}
+	return &RuleLongScript{
+		RuleBase: actionlint.NewRuleBase(KindLongScript, "Checks for run: scripts that should be script files"),
+		max:      max,
+	}
+}
+
+// VisitJobPre resets the step counter.
+func (rule *RuleLongScript) VisitJobPre(n *actionlint.Job) error {
+	rule.step = 0
+	return nil
+}
+
+// VisitStep checks the length of the step's script.
+func (rule *RuleLongScript) VisitStep(n *actionlint.Step) error {
+	rule.step++
+	if rule.max < 0 {
+		return nil
+	}
+	exec, ok := n.Exec.(*actionlint.ExecRun)
+	if !ok || exec.Run == nil {
+		return nil
+	}
+
+	lines := ScriptLines(exec.Run.Value)
+	if lines <= rule.max {
+		return nil
+	}
+
+	step := fmt.Sprintf("step %d", rule.step)
+	if n.Name != nil {
+		step += fmt.Sprintf(" (%q)", n.Name.Value)
+	}
+	rule.Errorf(exec.Run.Pos, "run: script of %s has %d lines, more than %d. move it into a script file, which the extract_script tool can do", step, lines, rule.max)
+	return nil
+}
+
+// ScriptLines counts the lines of a run: script, ignoring trailing empty
+// lines.
+func ScriptLines(script string) int {
+	script = strings.TrimRight(script, "\n")
+	if script == "" {
+		return 0
+	}
+	return strings.Count(script, "\n") + 1
+}
@@ -0,0 +1,46 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const scriptWorkflow = `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - name: Build
+        run: |
+          make deps
+          make build
+          make test
+
+      - run: echo short
+`
+
+func TestLongScript(t *testing.T) {
+	lint := func(cfg ScriptConfig) []*actionlint.Error {
+		return lintWith(t, func() actionlint.Rule { return NewLongScript(cfg) }, scriptWorkflow)
+	}
+
+	assert.Empty(t, lint(ScriptConfig{}), "default limit")
+	assert.Empty(t, lint(ScriptConfig{MaxLines: 3}), "trailing empty lines are not counted")
+	assert.Empty(t, lint(ScriptConfig{MaxLines: -1}), "negative limit disables the rule")
+
+	errs := lint(ScriptConfig{MaxLines: 2})
+	require.Len(t, errs, 1)
+	assert.Equal(t, KindLongScript, errs[0].Kind)
+	assert.Contains(t, errs[0].Message, `run: script of step 2 ("Build") has 3 lines, more than 2`)
+	assert.Equal(t, 8, errs[0].Line)
+}
+
+func TestScriptLines(t *testing.T) {
+	assert.Equal(t, 0, ScriptLines(""))
+	assert.Equal(t, 1, ScriptLines("echo hi"))
+	assert.Equal(t, 2, ScriptLines("a\nb\n\n"))
+}
@@ -0,0 +1,105 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindSecretEnvFile is the name of RuleSecretEnvFile.
+const KindSecretEnvFile = "secret-env-file"
+
+var (
+	// secretExpression matches the secrets and the job's token in an
+	// expression.
+	secretExpression = regexp.MustCompile(`\bsecrets\s*(?:\.\s*([A-Za-z_][\w-]*)|\[\s*'([^']+)'\s*\])|\bgithub\s*\.\s*token\b`)
+	// shellExpansion matches the variables a value expands.
+	shellExpansion = regexp.MustCompile(`\$(?:\{([A-Za-z_]\w*)\}|([A-Za-z_]\w*))`)
+)
+
+// RuleSecretEnvFile flags secrets written to $GITHUB_ENV, directly through
+// ${{ }} or through an environment variable set from one. Every later step
+// of the job then gets the secret in its environment, including the
+// actions and tools it runs, which need not see it.
+type RuleSecretEnvFile struct {
+	actionlint.RuleBase
+	env *actionlint.Env
+}
+
+// NewSecretEnvFile creates a RuleSecretEnvFile.
+func NewSecretEnvFile() *RuleSecretEnvFile {
+	return &RuleSecretEnvFile{
+		RuleBase: actionlint.NewRuleBase(KindSecretEnvFile, "Checks for secrets persisted through $GITHUB_ENV"),
+	}
+}
+
+// VisitWorkflowPre records the workflow's environment.
+func (rule *RuleSecretEnvFile) VisitWorkflowPre(n *actionlint.Workflow) error {
+	rule.env = n.Env
+	return nil
+}
+
+// VisitJobPre checks the job's scripts.
+func (rule *RuleSecretEnvFile) VisitJobPre(n *actionlint.Job) error {
+	for _, step := range n.Steps {
+		exec, ok := step.Exec.(*actionlint.ExecRun)
+		if !ok || exec.Run == nil || !strings.Contains(exec.Run.Value, "GITHUB_ENV") {
+			continue
+		}
+		envs := []*actionlint.Env{step.Env, n.Env, rule.env}
+		for _, e := range parseEnvFileWrites(exec.Run.Value) {
+			if e.file != "GITHUB_ENV" || e.op != "=" {
+				continue
+			}
+			if secret := writtenSecret(e.value, envs); secret != "" {
+				rule.Errorf(exec.Run.Pos, "%s is written to $GITHUB_ENV as %q, so every later step of job %q, and the actions and tools it runs, gets it in its environment. set it with env: on the steps that need it instead, or mask a value derived from it with ::add-mask:: and pass it as a step output", secret, e.name, n.ID.Value)
+			}
+		}
+	}
+	return nil
+}
+
+// writtenSecret describes the secret value holds, through an expression or
+// a variable of envs set from one, or returns "".
+func writtenSecret(value string, envs []*actionlint.Env) string {
+	for _, m := range expressionBody.FindAllStringSubmatch(value, -1) {
+		if s := secretIn(m[1]); s != "" {
+			return s
+		}
+	}
+	for _, m := range shellExpansion.FindAllStringSubmatch(value, -1) {
+		name := m[1] + m[2]
+		for _, env := range envs {
+			if env == nil {
+				continue
+			}
+			v, ok := env.Vars[strings.ToLower(name)]
+			if !ok || v.Value == nil {
+				continue
+			}
+			for _, e := range expressionBody.FindAllStringSubmatch(v.Value.Value, -1) {
+				if s := secretIn(e[1]); s != "" {
+					return fmt.Sprintf("%s, through $%s,", s, name)
+				}
+			}
+			break // The closest env setting the variable wins
+		}
+	}
+	return ""
+}
+
+// secretIn describes the first secret an expression reads, or returns "".
+func secretIn(expr string) string {
+	m := secretExpression.FindStringSubmatch(expr)
+	switch {
+	case m == nil:
+		return ""
+	case m[1] != "":
+		return "secret " + m[1]
+	case m[2] != "":
+		return "secret " + m[2]
+	}
+	return "the job's GITHUB_TOKEN"
+}
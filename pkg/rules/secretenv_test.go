@@ -0,0 +1,74 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretEnvFile(t *testing.T) {
+	newRule := func() actionlint.Rule { return NewSecretEnvFile() }
+
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "secret expression",
+			src: `    steps:
+      - run: |
+          echo "NPM_TOKEN=${{ secrets.NPM_TOKEN }}" >> "$GITHUB_ENV"
+          echo "TOKEN=${{ github.token }}" >> $GITHUB_ENV
+          echo "KEY=${{ secrets['DEPLOY_KEY'] }}" >> $GITHUB_ENV`,
+			want: []string{
+				`secret NPM_TOKEN is written to $GITHUB_ENV as "NPM_TOKEN", so every later step of job "build"`,
+				`the job's GITHUB_TOKEN is written to $GITHUB_ENV as "TOKEN"`,
+				`secret DEPLOY_KEY is written to $GITHUB_ENV as "KEY"`,
+			},
+		},
+		{
+			name: "through a variable",
+			src: `    env:
+      API_KEY: ${{ secrets.API_KEY }}
+    steps:
+      - run: echo "KEY=${API_KEY}" >> $GITHUB_ENV
+      - run: echo "VERSION=$VERSION" >> $GITHUB_ENV
+        env:
+          VERSION: ${{ github.ref_name }}`,
+			want: []string{`secret API_KEY, through $API_KEY, is written to $GITHUB_ENV as "KEY"`},
+		},
+		{
+			name: "closest env wins",
+			src: `    env:
+      API_KEY: ${{ secrets.API_KEY }}
+    steps:
+      - run: echo "KEY=$API_KEY" >> $GITHUB_ENV
+        env:
+          API_KEY: public`,
+		},
+		{
+			name: "outputs and heredocs",
+			src: `    steps:
+      - id: token
+        run: |
+          echo "token=${{ secrets.NPM_TOKEN }}" >> "$GITHUB_OUTPUT"
+          echo "NOTES<<EOF" >> $GITHUB_ENV
+      - run: echo "SHA=${{ github.sha }}" >> $GITHUB_ENV`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n" + tt.src + "\n"
+			errs := lintWith(t, newRule, src)
+			require.Len(t, errs, len(tt.want), "%v", errs)
+			for i, want := range tt.want {
+				assert.Equal(t, KindSecretEnvFile, errs[i].Kind)
+				assert.Contains(t, errs[i].Message, want)
+			}
+		})
+	}
+}
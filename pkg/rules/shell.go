@@ -0,0 +1,157 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindShellStrictness is the name of RuleShellStrictness.
+const KindShellStrictness = "shell-strictness"
+
+var (
+	// pipeMask matches quoted shell strings, whose pipes are not pipelines,
+	// and redirections such as 2>&1, which do not end commands.
+	pipeMask = regexp.MustCompile(`'[^']*'|"(?:[^"\\]|\\.)*"|\d*[<>]&\d*-?`)
+	// pipeline matches a pipe, capturing the command feeding it.
+	pipeline = regexp.MustCompile(`^\s*([^|#]*?[^|\s])\s*\|(?:[^|&]|$)`)
+	// harmlessSource matches commands feeding pipes that cannot fail.
+	harmlessSource = regexp.MustCompile(`^(?:echo|printf|yes|true)\b`)
+	// setErrexit and setPipefail match scripts setting the options
+	// themselves.
+	setErrexit  = regexp.MustCompile(`(?m)^\s*set\s+(?:-[a-zA-Z]*e|-o\s+errexit)`)
+	setPipefail = regexp.MustCompile(`(?m)^\s*set\s+(?:-[a-zA-Z]*\s+)*-[a-zA-Z]*o\s+pipefail`)
+	// errexitFlag matches -e among the flags of a custom shell.
+	errexitFlag = regexp.MustCompile(`\s-[a-zA-Z]*e[a-zA-Z]*\b|-o\s+errexit`)
+)
+
+// RuleShellStrictness flags run: scripts whose failures do not fail their
+// step because of the shell running them. GitHub runs scripts without
+// shell: with bash -e {0}, without pipefail, so a failing command feeding a
+// pipe, as in make test | tee log, goes unnoticed; shell: bash adds -o
+// pipefail. Custom bash shells such as bash {0} drop -e as well, so only
+// the last command of a script fails the step. Offers fixes setting
+// defaults: run: shell: bash on the job, or adding the flags to the custom
+// shell. Scripts setting the options themselves, and jobs in containers or
+// on Windows, whose defaults differ, are skipped.
+type RuleShellStrictness struct {
+	actionlint.RuleBase
+	fixes
+	shell *actionlint.String
+}
+
+// NewShellStrictness creates a RuleShellStrictness.
+func NewShellStrictness() *RuleShellStrictness {
+	return &RuleShellStrictness{
+		RuleBase: actionlint.NewRuleBase(KindShellStrictness, "Checks that the shells of run: scripts fail on failing commands"),
+	}
+}
+
+// VisitWorkflowPre records the workflow's default shell.
+func (rule *RuleShellStrictness) VisitWorkflowPre(n *actionlint.Workflow) error {
+	rule.shell = defaultsShell(n.Defaults)
+	return nil
+}
+
+// VisitJobPre checks the shells of the job's run: steps.
+func (rule *RuleShellStrictness) VisitJobPre(n *actionlint.Job) error {
+	if n.Container != nil {
+		return nil
+	}
+	runners, _ := jobRunners(n)
+	linux := false
+	for _, r := range runners {
+		switch r.os {
+		case "windows":
+			return nil
+		case "linux", "macos":
+			linux = true
+		}
+	}
+	if !linux {
+		return nil
+	}
+	jobShell := defaultsShell(n.Defaults)
+	if jobShell == nil {
+		jobShell = rule.shell
+	}
+
+	reportedDefault := false
+	for _, s := range n.Steps {
+		exec, ok := s.Exec.(*actionlint.ExecRun)
+		if !ok || exec.Run == nil {
+			continue
+		}
+		shell := exec.Shell
+		if shell == nil {
+			shell = jobShell
+		}
+		piped := pipedCommand(exec.Run.Value)
+
+		if shell == nil {
+			if piped == "" || reportedDefault {
+				continue
+			}
+			reportedDefault = true
+			rule.Errorf(exec.Run.Pos, "script pipes %q, but runs with GitHub's default shell, bash -e without pipefail, so the step passes when it fails. set defaults: run: shell: bash on job %q, which runs scripts with -eo pipefail, or start the script with set -euo pipefail", piped, n.ID.Value)
+			if n.Defaults == nil && n.ID.Pos.Col > 1 {
+				unit := strings.Repeat(" ", n.ID.Pos.Col-1)
+				block := fmt.Sprintf("\n%sdefaults:\n%srun:\n%sshell: bash\n", unit+unit, unit+unit+unit, unit+unit+unit+unit)
+				rule.addFix(KindShellStrictness, exec.Run.Pos, "Run the job's scripts with shell: bash",
+					Edit{Line: n.ID.Pos.Line, Column: n.ID.Pos.Col, Old: "\n", New: block})
+			}
+			continue
+		}
+
+		if shell.ContainsExpression() || !strings.Contains(shell.Value, "{0}") {
+			continue
+		}
+		if fields := strings.Fields(shell.Value); len(fields) == 0 || fields[0] != "bash" {
+			continue
+		}
+		statements := 0
+		for _, stmt := range splitStatements(exec.Run.Value) {
+			if stmt = strings.TrimSpace(stmt); stmt != "" && !strings.HasPrefix(stmt, "#") {
+				statements++
+			}
+		}
+		switch {
+		case !errexitFlag.MatchString(shell.Value) && statements > 1 && !setErrexit.MatchString(exec.Run.Value):
+			rule.Errorf(shell.Pos, "shell %q runs the script without -e, so a failing command only fails the step when it is the last one. add -eo pipefail to the shell, or use shell: bash", shell.Value)
+			rule.addFix(KindShellStrictness, shell.Pos, "Add -eo pipefail to the shell",
+				Edit{Line: shell.Pos.Line, Column: shell.Pos.Col, Old: "{0}", New: "-eo pipefail {0}"})
+		case !strings.Contains(shell.Value, "pipefail") && piped != "" && !setPipefail.MatchString(exec.Run.Value):
+			rule.Errorf(shell.Pos, "shell %q runs the script without pipefail, so the step passes when %q fails in its pipe. add -o pipefail to the shell, or use shell: bash", shell.Value, piped)
+			rule.addFix(KindShellStrictness, shell.Pos, "Add -o pipefail to the shell",
+				Edit{Line: shell.Pos.Line, Column: shell.Pos.Col, Old: "{0}", New: "-o pipefail {0}"})
+		}
+	}
+	return nil
+}
+
+// pipedCommand returns the first command of script feeding a pipe that can
+// fail, or "" when there is none or the script sets pipefail itself.
+func pipedCommand(script string) string {
+	if setPipefail.MatchString(script) {
+		return ""
+	}
+	for _, line := range strings.Split(script, "\n") {
+		// Quotes and redirections are masked, keeping the offsets of the line
+		masked := pipeMask.ReplaceAllStringFunc(line, func(q string) string { return strings.Repeat("x", len(q)) })
+		m := pipeline.FindStringSubmatchIndex(masked)
+		if m == nil {
+			continue
+		}
+		start := m[2]
+		// The last command of a list feeds the pipe
+		if i := strings.LastIndexAny(masked[start:m[3]], ";&"); i >= 0 {
+			start += i + 1
+		}
+		if cmd := strings.TrimSpace(line[start:m[3]]); cmd != "" && !harmlessSource.MatchString(cmd) {
+			return cmd
+		}
+	}
+	return ""
+}
@@ -0,0 +1,107 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellStrictness(t *testing.T) {
+	newRule := func() actionlint.Rule { return NewShellStrictness() }
+
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "default shell",
+			src: `    runs-on: ubuntu-latest
+    steps:
+      - run: echo "a|b" | grep -q a
+      - run: |
+          cd app && npm test 2>&1 | tee test.log
+      - run: make lint | tee lint.log`,
+			want: []string{`script pipes "npm test 2>&1", but runs with GitHub's default shell, bash -e without pipefail, so the step passes when it fails. set defaults: run: shell: bash on job "build"`},
+		},
+		{
+			name: "pipefail set",
+			src: `    runs-on: ubuntu-latest
+    steps:
+      - run: |
+          set -euo pipefail
+          make test | tee test.log
+      - run: make test | tee test.log
+        shell: bash`,
+		},
+		{
+			name: "custom shells",
+			src: `    runs-on: ubuntu-latest
+    steps:
+      - run: |
+          make build
+          make test
+        shell: bash {0}
+      - run: make test | tee test.log
+        shell: bash -e {0}
+      - run: make test
+        shell: bash {0}
+      - run: |
+          set -e
+          make build
+          make test
+        shell: bash --noprofile --norc {0}`,
+			want: []string{
+				`shell "bash {0}" runs the script without -e, so a failing command only fails the step when it is the last one`,
+				`shell "bash -e {0}" runs the script without pipefail, so the step passes when "make test" fails in its pipe`,
+			},
+		},
+		{
+			name: "windows and containers",
+			src: `    runs-on: windows-latest
+    steps:
+      - run: make test | tee test.log
+  container:
+    runs-on: ubuntu-latest
+    container: node:20
+    steps:
+      - run: make test | tee test.log`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "on: push\njobs:\n  build:\n" + tt.src + "\n"
+			errs := lintWith(t, newRule, src)
+			require.Len(t, errs, len(tt.want), "%v", errs)
+			for i, want := range tt.want {
+				assert.Equal(t, KindShellStrictness, errs[i].Kind)
+				assert.Contains(t, errs[i].Message, want)
+			}
+		})
+	}
+}
+
+func TestShellStrictness_Fixes(t *testing.T) {
+	src := `on: push
+jobs:
+    build:
+        runs-on: ubuntu-latest
+        steps:
+          - run: make test | tee test.log
+          - run: |
+              make build
+              make test
+            shell: bash {0}
+`
+	rule := NewShellStrictness()
+	errs := lintWith(t, func() actionlint.Rule { return rule }, src)
+	require.Len(t, errs, 2)
+
+	fixes := rule.Fixes()
+	require.Len(t, fixes, 2)
+	assert.Equal(t, []Edit{{Line: 3, Column: 5, Old: "\n", New: "\n        defaults:\n            run:\n                shell: bash\n"}}, fixes[0].Edits)
+	assert.Equal(t, []Edit{{Line: 10, Column: 20, Old: "{0}", New: "-eo pipefail {0}"}}, fixes[1].Edits)
+}
@@ -0,0 +1,161 @@
+package rules
+
+import (
+	_ "embed"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindSpelling is the name of RuleSpelling.
+const KindSpelling = "spelling"
+
+// SpellingConfig configures the spelling rule.
+type SpellingConfig struct {
+	// Enabled turns the rule on. It is off by default.
+	Enabled bool `yaml:"enabled"`
+	// Words are accepted even though the dictionary lists them as
+	// misspellings.
+	Words []string `yaml:"words"`
+	// WordsFile is a file of further accepted words, one per line, relative
+	// to the repository root. Lines starting with # are comments.
+	WordsFile string `yaml:"words-file"`
+}
+
+//go:embed misspellings.txt
+var misspellingsData string
+
+var (
+	misspellingsOnce sync.Once
+	misspellings     map[string]string
+
+	// nameWord matches the words of a name, splitting camelCase.
+	nameWord = regexp.MustCompile(`[A-Z]?[a-z]+|[A-Z]+`)
+	// nameExpression matches the ${{ }} expressions in a name.
+	nameExpression = regexp.MustCompile(`\$\{\{.*?\}\}`)
+)
+
+// loadMisspellings parses the embedded dictionary once.
+func loadMisspellings() map[string]string {
+	misspellingsOnce.Do(func() {
+		misspellings = map[string]string{}
+		for _, line := range strings.Split(misspellingsData, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 2 && !strings.HasPrefix(fields[0], "#") {
+				misspellings[fields[0]] = fields[1]
+			}
+		}
+	})
+	return misspellings
+}
+
+// RuleSpelling flags common misspellings in the workflow, job and step
+// names shown in the Checks UI, using an embedded dictionary of
+// misspellings and their corrections.
+type RuleSpelling struct {
+	actionlint.RuleBase
+	enabled  bool
+	accepted map[string]bool
+	fileErr  error
+	file     string
+}
+
+// NewSpelling creates a RuleSpelling. root is the repository root that
+// cfg.WordsFile is relative to.
+func NewSpelling(cfg SpellingConfig, root string) *RuleSpelling {
+	rule := &RuleSpelling{
+		RuleBase: actionlint.NewRuleBase(KindSpelling, "Checks for misspelled words in workflow, job and step names"),
+		enabled:  cfg.Enabled,
+		accepted: map[string]bool{},
+	}
+	if !cfg.Enabled {
+		return rule
+	}
+	for _, w := range cfg.Words {
+		rule.accepted[strings.ToLower(w)] = true
+	}
+	if cfg.WordsFile != "" {
+		rule.file = cfg.WordsFile
+		if !filepath.IsAbs(rule.file) && root != "" {
+			rule.file = filepath.Join(root, rule.file)
+		}
+		data, err := os.ReadFile(rule.file)
+		if err != nil {
+			rule.fileErr = err
+			return rule
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if w := strings.TrimSpace(line); w != "" && !strings.HasPrefix(w, "#") {
+				rule.accepted[strings.ToLower(w)] = true
+			}
+		}
+	}
+	return rule
+}
+
+// VisitWorkflowPre checks the workflow name.
+func (rule *RuleSpelling) VisitWorkflowPre(n *actionlint.Workflow) error {
+	if !rule.enabled {
+		return nil
+	}
+	if rule.fileErr != nil {
+		rule.Errorf(&actionlint.Pos{Line: 1, Col: 1}, "spelling words file %s cannot be read: %v", rule.file, rule.fileErr)
+	}
+	rule.check("workflow name", n.Name)
+	return nil
+}
+
+// VisitJobPre checks the job name, or its ID when it has none, since that
+// is what the Checks UI shows.
+func (rule *RuleSpelling) VisitJobPre(n *actionlint.Job) error {
+	if !rule.enabled {
+		return nil
+	}
+	if n.Name != nil {
+		rule.check("job name", n.Name)
+	} else {
+		rule.check("job ID", n.ID)
+	}
+	return nil
+}
+
+// VisitStep checks the step name.
+func (rule *RuleSpelling) VisitStep(n *actionlint.Step) error {
+	if rule.enabled {
+		rule.check("step name", n.Name)
+	}
+	return nil
+}
+
+func (rule *RuleSpelling) check(what string, s *actionlint.String) {
+	if s == nil {
+		return
+	}
+	dict := loadMisspellings()
+	seen := map[string]bool{}
+	for _, w := range nameWord.FindAllString(nameExpression.ReplaceAllString(s.Value, " "), -1) {
+		lower := strings.ToLower(w)
+		correction, ok := dict[lower]
+		if !ok || rule.accepted[lower] || seen[lower] {
+			continue
+		}
+		seen[lower] = true
+		rule.Errorf(s.Pos, "%s %q has a misspelled word %q. did you mean %q? add it to the spelling words if it is intended", what, s.Value, w, matchCase(w, correction))
+	}
+}
+
+// matchCase gives correction the capitalization of word.
+func matchCase(word, correction string) string {
+	switch {
+	case len(word) > 1 && strings.ToUpper(word) == word:
+		return strings.ToUpper(correction)
+	case word[0] >= 'A' && word[0] <= 'Z':
+		return strings.ToUpper(correction[:1]) + correction[1:]
+	default:
+		return correction
+	}
+}
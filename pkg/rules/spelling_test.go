@@ -0,0 +1,66 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const spellingWorkflow = `name: Relase ${{ github.ref_nmae }}
+on: push
+jobs:
+  bulid-docs:
+    runs-on: ubuntu-latest
+    steps:
+      - name: Upload artifcats to stroage
+        run: echo hi
+  test:
+    name: Run tests
+    runs-on: ubuntu-latest
+    steps:
+      - name: uploadArtifcats
+        run: echo hi
+`
+
+func TestSpelling(t *testing.T) {
+	assert.Empty(t, lintWith(t, func() actionlint.Rule { return NewSpelling(SpellingConfig{}, "") }, spellingWorkflow), "disabled by default")
+
+	errs := lintWith(t, func() actionlint.Rule { return NewSpelling(SpellingConfig{Enabled: true}, "") }, spellingWorkflow)
+	require.Len(t, errs, 5)
+	assert.Equal(t, KindSpelling, errs[0].Kind)
+	assert.Contains(t, errs[0].Message, `workflow name "Relase ${{ github.ref_nmae }}" has a misspelled word "Relase". did you mean "Release"?`)
+	assert.Contains(t, errs[1].Message, `job ID "bulid-docs" has a misspelled word "bulid". did you mean "build"?`)
+	assert.Contains(t, errs[2].Message, `"artifcats". did you mean "artifacts"?`)
+	assert.Contains(t, errs[3].Message, `"stroage". did you mean "storage"?`)
+	assert.Contains(t, errs[4].Message, `step name "uploadArtifcats" has a misspelled word "Artifcats". did you mean "Artifacts"?`)
+}
+
+func TestSpelling_Words(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "words.txt"), []byte("# project words\nbulid\n"), 0644))
+
+	errs := lintWith(t, func() actionlint.Rule {
+		return NewSpelling(SpellingConfig{Enabled: true, Words: []string{"Relase", "stroage"}, WordsFile: "words.txt"}, root)
+	}, spellingWorkflow)
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Message, `"artifcats"`)
+
+	errs = lintWith(t, func() actionlint.Rule {
+		return NewSpelling(SpellingConfig{Enabled: true, WordsFile: "missing.txt"}, root)
+	}, spellingWorkflow)
+	assert.Contains(t, errs[0].Message, "spelling words file")
+}
+
+func TestMisspellings(t *testing.T) {
+	dict := loadMisspellings()
+	assert.Greater(t, len(dict), 300)
+	for typo, correction := range dict {
+		_, chained := dict[correction]
+		assert.False(t, chained, "correction of %q is itself listed as a misspelling", typo)
+		assert.NotEqual(t, typo, correction)
+	}
+}
@@ -0,0 +1,39 @@
+package rules
+
+import (
+	"github.com/rhysd/actionlint"
+)
+
+// KindJobTimeout is the name of RuleJobTimeout.
+const KindJobTimeout = "job-timeout"
+
+// JobTimeoutConfig configures the job-timeout rule.
+type JobTimeoutConfig struct {
+	// Enabled turns the rule on. It is off by default.
+	Enabled bool `yaml:"enabled"`
+}
+
+// RuleJobTimeout flags jobs without timeout-minutes, which GitHub lets run
+// for 6 hours, so a hung step keeps its runner busy, and billed, for that
+// long. Jobs calling reusable workflows cannot set it and are skipped.
+type RuleJobTimeout struct {
+	actionlint.RuleBase
+	cfg JobTimeoutConfig
+}
+
+// NewJobTimeout creates a RuleJobTimeout.
+func NewJobTimeout(cfg JobTimeoutConfig) *RuleJobTimeout {
+	return &RuleJobTimeout{
+		RuleBase: actionlint.NewRuleBase(KindJobTimeout, "Checks that jobs set timeout-minutes"),
+		cfg:      cfg,
+	}
+}
+
+// VisitJobPre checks the job's timeout.
+func (rule *RuleJobTimeout) VisitJobPre(n *actionlint.Job) error {
+	if !rule.cfg.Enabled || n.WorkflowCall != nil || n.TimeoutMinutes != nil {
+		return nil
+	}
+	rule.Errorf(n.ID.Pos, "job %q has no timeout-minutes, so a hung step keeps it running, and billed, for up to 6 hours. set timeout-minutes to a few times the job's usual duration", n.ID.Value)
+	return nil
+}
@@ -0,0 +1,32 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobTimeout(t *testing.T) {
+	src := `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make
+  test:
+    runs-on: ubuntu-latest
+    timeout-minutes: 20
+    steps:
+      - run: make test
+  release:
+    uses: ./.github/workflows/release.yml
+`
+	errs := lintWith(t, func() actionlint.Rule { return NewJobTimeout(JobTimeoutConfig{Enabled: true}) }, src)
+	require.Len(t, errs, 1)
+	assert.Equal(t, 3, errs[0].Line)
+	assert.Contains(t, errs[0].Message, `job "build" has no timeout-minutes`)
+
+	assert.Empty(t, lintWith(t, func() actionlint.Rule { return NewJobTimeout(JobTimeoutConfig{}) }, src))
+}
@@ -0,0 +1,327 @@
+package rules
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindTokenPermissions is the name of RuleTokenPermissions.
+const KindTokenPermissions = "token-permissions"
+
+// PermissionsConfig configures the dataset of the GITHUB_TOKEN permissions
+// actions need, which the token-permissions rule and suggest_permissions
+// use.
+type PermissionsConfig struct {
+	// Actions add to and override the embedded dataset, mapping an action,
+	// as owner/repo or owner/repo/path, to the level of each scope it
+	// needs. An action mapped to no scopes needs none.
+	Actions map[string]map[string]string `yaml:"actions"`
+	// File is a dataset in the embedded format, relative to the repository
+	// root, which adds to and overrides the embedded one. Actions override
+	// it in turn.
+	File string `yaml:"file"`
+}
+
+// Validate reports levels other than read, write and none.
+func (c PermissionsConfig) Validate() error {
+	for action, scopes := range c.Actions {
+		for scope, level := range scopes {
+			if _, ok := accessLevels[level]; !ok {
+				return fmt.Errorf("permissions: %s: level of %q must be read, write or none, not %q", action, scope, level)
+			}
+		}
+	}
+	return nil
+}
+
+// ActionPermissions maps actions, by their lower-case name without a ref,
+// to the level of each GITHUB_TOKEN scope they need.
+type ActionPermissions map[string]map[string]string
+
+// accessLevels orders the access levels of a permission scope.
+var accessLevels = map[string]int{"none": 0, "read": 1, "write": 2}
+
+//go:embed action-permissions.txt
+var actionPermissionsData string
+
+var (
+	actionPermissionsOnce sync.Once
+	actionPermissions     ActionPermissions
+
+	// tokenInputs are the inputs through which actions take the token they
+	// authenticate with.
+	tokenInputs = []string{"token", "github-token", "github_token", "repo-token", "repo_token"}
+
+	// tokenCommands are the commands that need GITHUB_TOKEN permissions,
+	// with the scope and level they need. ghCommand is set for gh commands,
+	// which only use the job's token when it is passed in GH_TOKEN or
+	// GITHUB_TOKEN.
+	tokenCommands = []struct {
+		pattern   *regexp.Regexp
+		scope     string
+		level     string
+		ghCommand bool
+	}{
+		{regexp.MustCompile(`\bgit +push\b`), "contents", "write", false},
+		{provenancePublish, "id-token", "write", false},
+		{regexp.MustCompile(`\bgh +release +(create|upload|edit|delete)\b`), "contents", "write", true},
+		{regexp.MustCompile(`\bgh +release +(list|view|download)\b`), "contents", "read", true},
+		{regexp.MustCompile(`\bgh +pr +(create|merge|comment|edit|review|close|reopen|ready)\b`), "pull-requests", "write", true},
+		{regexp.MustCompile(`\bgh +pr +(list|view|diff|checks|status)\b`), "pull-requests", "read", true},
+		{regexp.MustCompile(`\bgh +issue +(create|comment|edit|close|reopen)\b`), "issues", "write", true},
+		{regexp.MustCompile(`\bgh +issue +(list|view|status)\b`), "issues", "read", true},
+		{regexp.MustCompile(`\bgh +(workflow +run|run +(rerun|cancel))\b`), "actions", "write", true},
+		{regexp.MustCompile(`\bgh +run +(list|view|download|watch)\b`), "actions", "read", true},
+	}
+	// ghAPI matches gh commands whose needs depend on their arguments.
+	ghAPI = regexp.MustCompile(`\bgh +api\b`)
+)
+
+// parseActionPermissions adds the dataset in data to into.
+func parseActionPermissions(data string, into ActionPermissions) error {
+	for i, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		scopes := map[string]string{}
+		for _, f := range fields[1:] {
+			scope, level, ok := strings.Cut(f, ":")
+			if _, valid := accessLevels[level]; !ok || scope == "" || !valid {
+				return fmt.Errorf("line %d: %q is not scope:level with level read, write or none", i+1, f)
+			}
+			if level != "none" {
+				scopes[scope] = level
+			}
+		}
+		into[strings.ToLower(fields[0])] = scopes
+	}
+	return nil
+}
+
+// embeddedActionPermissions parses the embedded dataset once.
+func embeddedActionPermissions() ActionPermissions {
+	actionPermissionsOnce.Do(func() {
+		actionPermissions = ActionPermissions{}
+		if err := parseActionPermissions(actionPermissionsData, actionPermissions); err != nil {
+			panic("action-permissions.txt: " + err.Error())
+		}
+	})
+	return actionPermissions
+}
+
+// LoadActionPermissions returns the embedded dataset with the changes of
+// cfg. root is the repository root that cfg.File is relative to.
+func LoadActionPermissions(cfg PermissionsConfig, root string) (ActionPermissions, error) {
+	out := ActionPermissions{}
+	for name, scopes := range embeddedActionPermissions() {
+		out[name] = scopes
+	}
+	if cfg.File != "" {
+		file := cfg.File
+		if !filepath.IsAbs(file) && root != "" {
+			file = filepath.Join(root, file)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return out, fmt.Errorf("permissions file %s cannot be read: %w", file, err)
+		}
+		if err := parseActionPermissions(string(data), out); err != nil {
+			return out, fmt.Errorf("permissions file %s: %w", file, err)
+		}
+	}
+	for name, scopes := range cfg.Actions {
+		needs := map[string]string{}
+		for scope, level := range scopes {
+			if level != "none" {
+				needs[scope] = level
+			}
+		}
+		out[strings.ToLower(name)] = needs
+	}
+	return out, nil
+}
+
+// StepPermissions returns the GITHUB_TOKEN scopes step needs and their
+// level. env are the job's and the workflow's environment variables, which
+// the step's own override. known is false when the needs cannot be told,
+// as for actions missing from actions and gh api calls, so the step may
+// need more.
+func StepPermissions(step *actionlint.Step, actions ActionPermissions, env ...*actionlint.Env) (needs map[string]string, known bool) {
+	needs = map[string]string{}
+	switch exec := step.Exec.(type) {
+	case *actionlint.ExecAction:
+		if exec.Uses == nil || strings.HasPrefix(exec.Uses.Value, "./") || strings.HasPrefix(exec.Uses.Value, "docker://") {
+			return needs, false
+		}
+		name, _, _ := strings.Cut(strings.ToLower(exec.Uses.Value), "@")
+		scopes, ok := actions[name]
+		if !ok {
+			return needs, false
+		}
+		// An action given another token only needs the OIDC token of the job
+		otherToken := false
+		for _, input := range tokenInputs {
+			if in, ok := exec.Inputs[input]; ok && in.Value != nil && !defaultToken.MatchString(in.Value.Value) {
+				otherToken = true
+			}
+		}
+		for scope, level := range scopes {
+			if !otherToken || scope == "id-token" {
+				needs[scope] = level
+			}
+		}
+		return needs, true
+	case *actionlint.ExecRun:
+		if exec.Run == nil {
+			return needs, true
+		}
+		ghToken := tokenEnv(append([]*actionlint.Env{step.Env}, env...))
+		for _, c := range tokenCommands {
+			if (!c.ghCommand || ghToken) && c.pattern.MatchString(exec.Run.Value) && accessLevels[c.level] > accessLevels[needs[c.scope]] {
+				needs[c.scope] = c.level
+			}
+		}
+		return needs, !ghToken || !ghAPI.MatchString(exec.Run.Value)
+	}
+	return needs, true
+}
+
+// tokenEnv reports whether gh authenticates with the job's token: whether
+// the first of envs setting GH_TOKEN or GITHUB_TOKEN sets it to the job's
+// token.
+func tokenEnv(envs []*actionlint.Env) bool {
+	v, _ := tokenVar(envs)
+	return v != nil && defaultToken.MatchString(v.Value)
+}
+
+// tokenVar returns the value of GH_TOKEN or GITHUB_TOKEN set by the first
+// of envs setting either, and whether any sets them. The value is nil when
+// an env is an expression, which may set them. gh prefers GH_TOKEN when
+// both are set.
+func tokenVar(envs []*actionlint.Env) (value *actionlint.String, set bool) {
+	for _, e := range envs {
+		if e == nil {
+			continue
+		}
+		if e.Expression != nil {
+			return nil, true
+		}
+		for _, name := range []string{"GH_TOKEN", "GITHUB_TOKEN"} {
+			for _, v := range e.Vars {
+				if v.Name != nil && v.Value != nil && strings.EqualFold(v.Name.Value, name) {
+					return v.Value, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// accessLevel returns the level perms grants to scope, or "" when perms is
+// nil and the repository's default applies.
+func accessLevel(perms *actionlint.Permissions, scope string) string {
+	switch {
+	case perms == nil:
+		return ""
+	case perms.All != nil:
+		switch perms.All.Value {
+		case "write-all":
+			return "write"
+		case "read-all":
+			return "read"
+		}
+		return "none"
+	}
+	if s, ok := perms.Scopes[scope]; ok && s.Value != nil {
+		return s.Value.Value
+	}
+	return "none"
+}
+
+// RuleTokenPermissions flags actions whose job's permissions block does not
+// grant the write access they need, according to a dataset of popular
+// actions. Read access is not checked, since public repositories can be
+// read without it, and jobs without a permissions block get the
+// repository's defaults, which cannot be checked.
+type RuleTokenPermissions struct {
+	actionlint.RuleBase
+	actions     ActionPermissions
+	loadErr     error
+	permissions *actionlint.Permissions
+	env         *actionlint.Env
+}
+
+// NewTokenPermissions creates a RuleTokenPermissions. root is the
+// repository root that cfg.File is relative to.
+func NewTokenPermissions(cfg PermissionsConfig, root string) *RuleTokenPermissions {
+	actions, err := LoadActionPermissions(cfg, root)
+	return &RuleTokenPermissions{
+		RuleBase: actionlint.NewRuleBase(KindTokenPermissions, "Checks that permissions blocks grant the scopes the job's actions need"),
+		actions:  actions,
+		loadErr:  err,
+	}
+}
+
+// VisitWorkflowPre records the workflow's permissions and environment.
+func (rule *RuleTokenPermissions) VisitWorkflowPre(n *actionlint.Workflow) error {
+	if rule.loadErr != nil {
+		rule.Errorf(&actionlint.Pos{Line: 1, Col: 1}, "%v", rule.loadErr)
+	}
+	rule.permissions = n.Permissions
+	rule.env = n.Env
+	return nil
+}
+
+// VisitJobPre checks the actions of the job against its permissions.
+func (rule *RuleTokenPermissions) VisitJobPre(n *actionlint.Job) error {
+	perms := n.Permissions
+	if perms == nil {
+		perms = rule.permissions
+	}
+	if perms == nil {
+		return nil
+	}
+	for _, s := range n.Steps {
+		exec, ok := s.Exec.(*actionlint.ExecAction)
+		if !ok || exec.Uses == nil || isReleaseAction(exec.Uses.Value) {
+			continue // Run steps and release automation are checked by the release rule
+		}
+		needs, _ := StepPermissions(s, rule.actions, n.Env, rule.env)
+		scopes := make([]string, 0, len(needs))
+		for scope := range needs {
+			scopes = append(scopes, scope)
+		}
+		sort.Strings(scopes)
+		var missing []string
+		for _, scope := range scopes {
+			if needs[scope] == "write" && accessLevel(perms, scope) != "write" {
+				missing = append(missing, fmt.Sprintf("%q", scope+": "+needs[scope]))
+			}
+		}
+		if len(missing) > 0 {
+			rule.Errorf(exec.Uses.Pos, "%q needs %s, but the job's permissions do not grant it", exec.Uses.Value, strings.Join(missing, " and "))
+		}
+	}
+	return nil
+}
+
+// isReleaseAction reports whether uses is one of the release rule's
+// actions.
+func isReleaseAction(uses string) bool {
+	name, _, _ := strings.Cut(uses, "@")
+	for _, a := range releaseActions {
+		if strings.EqualFold(name, a.name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenPermissions(t *testing.T) {
+	src := `on: push
+permissions:
+  contents: read
+jobs:
+  scan:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: github/codeql-action/analyze@v3
+      - uses: softprops/action-gh-release@v2
+  label:
+    runs-on: ubuntu-latest
+    permissions:
+      pull-requests: write
+    steps:
+      - uses: actions/labeler@v5
+      - uses: actions/stale@v9
+        with:
+          repo-token: ${{ secrets.STALE_TOKEN }}
+  defaults:
+    runs-on: ubuntu-latest
+    permissions: write-all
+    steps:
+      - uses: actions/deploy-pages@v4
+`
+	errs := lintWith(t, func() actionlint.Rule { return NewTokenPermissions(PermissionsConfig{}, "") }, src)
+	require.Len(t, errs, 1)
+
+	assert.Equal(t, 9, errs[0].Line)
+	assert.Equal(t, KindTokenPermissions, errs[0].Kind)
+	assert.Contains(t, errs[0].Message, `"github/codeql-action/analyze@v3" needs "security-events: write"`)
+}
+
+func TestTokenPermissions_Config(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "perms.txt"), []byte("# Ours\nacme/deploy deployments:write\n"), 0644))
+
+	src := `on: push
+permissions: {}
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: acme/deploy@v1
+      - uses: acme/notify@v1
+      - uses: actions/stale@v9
+`
+	cfg := PermissionsConfig{
+		File:    "perms.txt",
+		Actions: map[string]map[string]string{"Acme/Notify": {"issues": "write"}, "actions/stale": {}},
+	}
+	errs := lintWith(t, func() actionlint.Rule { return NewTokenPermissions(cfg, root) }, src)
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Message, `"acme/deploy@v1" needs "deployments: write"`)
+	assert.Contains(t, errs[1].Message, `"acme/notify@v1" needs "issues: write"`)
+
+	errs = lintWith(t, func() actionlint.Rule { return NewTokenPermissions(PermissionsConfig{File: "missing.txt"}, root) }, src)
+	require.NotEmpty(t, errs)
+	assert.Contains(t, errs[0].Message, "missing.txt cannot be read")
+
+	assert.Error(t, PermissionsConfig{Actions: map[string]map[string]string{"acme/deploy": {"contents": "admin"}}}.Validate())
+}
+
+func TestStepPermissions_Commands(t *testing.T) {
+	src := `on: push
+env:
+  GH_TOKEN: ${{ github.token }}
+jobs:
+  release:
+    runs-on: ubuntu-latest
+    steps:
+      - run: |
+          gh release create v1
+          gh pr list
+          git push origin HEAD
+      - run: gh issue comment 1 --body hi
+        env:
+          GH_TOKEN: ${{ secrets.BOT_TOKEN }}
+      - run: gh api repos/{owner}/{repo}/hooks
+`
+	w, errs := actionlint.Parse([]byte(src))
+	require.Empty(t, errs)
+	j := w.Jobs["release"]
+
+	needs, known := StepPermissions(j.Steps[0], nil, j.Env, w.Env)
+	assert.True(t, known)
+	assert.Equal(t, map[string]string{"contents": "write", "pull-requests": "read"}, needs)
+
+	needs, known = StepPermissions(j.Steps[1], nil, j.Env, w.Env)
+	assert.True(t, known)
+	assert.Empty(t, needs)
+
+	_, known = StepPermissions(j.Steps[2], nil, j.Env, w.Env)
+	assert.False(t, known)
+}
@@ -0,0 +1,136 @@
+package rules
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// Names of the rules checking context keys.
+const (
+	KindUndefinedVariable = "undefined-variable"
+	KindUndefinedSecret   = "undefined-secret"
+)
+
+// expressionBody matches the body of each ${{ }} expression.
+var expressionBody = regexp.MustCompile(`(?s)\$\{\{(.*?)\}\}`)
+
+// RuleUndefinedKey flags references to keys of the vars or secrets context
+// that are not defined, which GitHub evaluates to an empty string. Jobs
+// that use an environment are skipped, since environments define
+// variables and secrets of their own.
+type RuleUndefinedKey struct {
+	actionlint.RuleBase
+	context string
+	ref     *regexp.Regexp
+	defined map[string]bool
+	message string
+}
+
+// NewUndefinedVariable creates a RuleUndefinedKey for vars that accepts
+// the given variable names. A nil list disables the rule, since variables
+// are only known when they were listed or fetched.
+func NewUndefinedVariable(defined []string) *RuleUndefinedKey {
+	return newUndefinedKey(KindUndefinedVariable, "vars", defined,
+		"configuration variable %q is not defined for the repository or its organization, so vars.%s evaluates to an empty string")
+}
+
+// NewUndefinedSecret creates a RuleUndefinedKey for secrets that accepts
+// the given secret names, GITHUB_TOKEN, and the secrets a reusable
+// workflow declares. A nil list disables the rule.
+func NewUndefinedSecret(defined []string) *RuleUndefinedKey {
+	if defined != nil {
+		defined = append([]string{"GITHUB_TOKEN"}, defined...)
+	}
+	return newUndefinedKey(KindUndefinedSecret, "secrets", defined,
+		"secret %q is not among the declared secrets, so secrets.%s evaluates to an empty string")
+}
+
+func newUndefinedKey(kind, context string, defined []string, message string) *RuleUndefinedKey {
+	rule := &RuleUndefinedKey{
+		RuleBase: actionlint.NewRuleBase(kind, "Checks for references to undefined "+context+" keys"),
+		context:  context,
+		ref:      regexp.MustCompile(`(?:^|[^\w.-])` + context + `\s*(?:\.\s*([A-Za-z_]\w*)|\[\s*'([^']+)'\s*\])`),
+		message:  message,
+	}
+	if defined != nil {
+		rule.defined = make(map[string]bool, len(defined))
+		for _, name := range defined {
+			rule.defined[strings.ToUpper(name)] = true
+		}
+	}
+	return rule
+}
+
+// VisitWorkflowPre checks the expressions of the workflow and its jobs.
+func (rule *RuleUndefinedKey) VisitWorkflowPre(n *actionlint.Workflow) error {
+	if rule.defined == nil {
+		return nil
+	}
+	if rule.context == "secrets" {
+		for _, e := range n.On {
+			if call, ok := e.(*actionlint.WorkflowCallEvent); ok {
+				for _, s := range call.Secrets {
+					rule.defined[strings.ToUpper(s.Name.Value)] = true
+				}
+			}
+		}
+	}
+
+	var strs []*actionlint.String
+	for _, v := range []any{n.RunName, n.Env, n.Defaults, n.Concurrency} {
+		collectStrings(reflect.ValueOf(v), &strs)
+	}
+	rule.check(strs, nil)
+
+	ids := make([]string, 0, len(n.Jobs))
+	for id := range n.Jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		job := n.Jobs[id]
+		if job.Environment != nil {
+			continue
+		}
+		conditions := map[*actionlint.String]bool{job.If: true}
+		for _, s := range job.Steps {
+			conditions[s.If] = true
+		}
+		strs = strs[:0]
+		collectStrings(reflect.ValueOf(job), &strs)
+		rule.check(strs, conditions)
+	}
+	return nil
+}
+
+// check reports undefined keys in the expressions of strs. Strings in
+// conditions are expressions even without ${{ }}.
+func (rule *RuleUndefinedKey) check(strs []*actionlint.String, conditions map[*actionlint.String]bool) {
+	for _, s := range strs {
+		var exprs []string
+		if conditions[s] && !strings.Contains(s.Value, "${{") {
+			exprs = []string{s.Value}
+		} else {
+			for _, m := range expressionBody.FindAllStringSubmatch(s.Value, -1) {
+				exprs = append(exprs, m[1])
+			}
+		}
+
+		seen := map[string]bool{}
+		for _, e := range exprs {
+			for _, m := range rule.ref.FindAllStringSubmatch(e, -1) {
+				name := m[1] + m[2]
+				upper := strings.ToUpper(name)
+				if rule.defined[upper] || seen[upper] {
+					continue
+				}
+				seen[upper] = true
+				rule.Errorf(s.Pos, rule.message, name, name)
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const variablesWorkflow = `on: push
+run-name: Deploy ${{ vars.RUN_LABEL }}
+env:
+  REGION: ${{ vars.aws_region }}
+jobs:
+  build:
+    if: vars.ENABLE_BUILD == 'true'
+    runs-on: ${{ vars['RUNNER'] }}
+    steps:
+      - run: echo "${{ vars.IMAGE }} ${{ vars.IMAGE }}"
+      - run: echo vars.NOT_AN_EXPRESSION
+  deploy:
+    runs-on: ubuntu-latest
+    environment: production
+    steps:
+      - run: echo ${{ vars.DEPLOY_URL }}
+`
+
+func TestUndefinedVariable(t *testing.T) {
+	assert.Empty(t, lintWith(t, func() actionlint.Rule { return NewUndefinedVariable(nil) }, variablesWorkflow), "disabled without variables")
+
+	errs := lintWith(t, func() actionlint.Rule { return NewUndefinedVariable([]string{"AWS_REGION", "runner"}) }, variablesWorkflow)
+	require.Len(t, errs, 3)
+	assert.Equal(t, KindUndefinedVariable, errs[0].Kind)
+	assert.Contains(t, errs[0].Message, `configuration variable "RUN_LABEL" is not defined`)
+	assert.Equal(t, 2, errs[0].Line)
+	assert.Contains(t, errs[1].Message, `"ENABLE_BUILD"`)
+	assert.Equal(t, 7, errs[1].Line)
+	assert.Contains(t, errs[2].Message, `"IMAGE"`)
+	assert.Equal(t, 10, errs[2].Line)
+}
+
+func TestUndefinedSecret(t *testing.T) {
+	workflow := `on:
+  workflow_call:
+    secrets:
+      deploy_key:
+        required: true
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ secrets.GITHUB_TOKEN }} ${{ secrets.DEPLOY_KEY }} ${{ secrets.NPM_TOKEN }}
+      - run: echo ${{ secrets.NPM_TOKNE }}
+`
+	assert.Empty(t, lintWith(t, func() actionlint.Rule { return NewUndefinedSecret(nil) }, workflow), "disabled without secrets")
+
+	errs := lintWith(t, func() actionlint.Rule { return NewUndefinedSecret([]string{"npm_token"}) }, workflow)
+	require.Len(t, errs, 1)
+	assert.Equal(t, KindUndefinedSecret, errs[0].Kind)
+	assert.Contains(t, errs[0].Message, `secret "NPM_TOKNE" is not among the declared secrets, so secrets.NPM_TOKNE evaluates to an empty string`)
+	assert.Equal(t, 11, errs[0].Line)
+}
@@ -0,0 +1,139 @@
+package rules
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rhysd/actionlint"
+)
+
+// KindWorkingDirectory is the name of RuleWorkingDirectory.
+const KindWorkingDirectory = "working-directory"
+
+// RuleWorkingDirectory flags the working-directory of run: steps, set on
+// the step or by the defaults of the job or the workflow, when the
+// directory is not in the repository, or is but the step runs before
+// actions/checkout put it in the workspace. Directories a previous step
+// mentions, as mkdir -p build would, and those of jobs downloading files
+// some other way, are assumed to be created. Nothing is checked outside a
+// repository.
+type RuleWorkingDirectory struct {
+	actionlint.RuleBase
+	root string
+	dir  *actionlint.String
+}
+
+// NewWorkingDirectory creates a RuleWorkingDirectory for the repository at
+// root, which may be empty when the workflow is not in a repository.
+func NewWorkingDirectory(root string) *RuleWorkingDirectory {
+	return &RuleWorkingDirectory{
+		RuleBase: actionlint.NewRuleBase(KindWorkingDirectory, "Checks that working directories exist when their steps run"),
+		root:     root,
+	}
+}
+
+// VisitWorkflowPre records the workflow's default working directory.
+func (rule *RuleWorkingDirectory) VisitWorkflowPre(n *actionlint.Workflow) error {
+	rule.dir = defaultsDirectory(n.Defaults)
+	return nil
+}
+
+// VisitJobPre checks the working directories of the job's run: steps.
+func (rule *RuleWorkingDirectory) VisitJobPre(n *actionlint.Job) error {
+	if rule.root == "" {
+		return nil
+	}
+	jobDir := defaultsDirectory(n.Defaults)
+	if jobDir == nil {
+		jobDir = rule.dir
+	}
+
+	// The first checkout of the repository, and the directory it checks
+	// out to
+	checkout, prefix := -1, ""
+	for i, s := range n.Steps {
+		name, exec := usesName(s)
+		if name != "actions/checkout" {
+			continue
+		}
+		if exec.Inputs["repository"] != nil {
+			return nil // Directories may be in the other repository
+		}
+		if p := exec.Inputs["path"]; p != nil && p.Value != nil {
+			if p.Value.ContainsExpression() {
+				return nil
+			}
+			prefix = path.Clean(p.Value.Value)
+		}
+		checkout = i
+		break
+	}
+
+	reported := map[*actionlint.String]bool{}
+	for i, s := range n.Steps {
+		exec, ok := s.Exec.(*actionlint.ExecRun)
+		if !ok {
+			continue
+		}
+		dir := exec.WorkingDirectory
+		if dir == nil {
+			dir = jobDir
+		}
+		if dir == nil || reported[dir] || strings.ContainsAny(dir.Value, "$~") || path.IsAbs(dir.Value) || filepath.VolumeName(dir.Value) != "" {
+			continue
+		}
+		rel := path.Clean(dir.Value)
+		if prefix != "" && prefix != "." {
+			if rel != prefix && !strings.HasPrefix(rel, prefix+"/") {
+				continue // Outside of the checkout
+			}
+			rel = strings.TrimPrefix(strings.TrimPrefix(rel, prefix), "/")
+		}
+		if rel == "." || rel == "" || strings.HasPrefix(rel, "../") || createsDirectory(n.Steps[:i], rel) {
+			continue
+		}
+
+		info, err := os.Stat(filepath.Join(rule.root, filepath.FromSlash(rel)))
+		switch {
+		case err != nil:
+			reported[dir] = true
+			rule.Errorf(dir.Pos, "working-directory %q does not exist in the repository and no earlier step of job %q creates it, so its run: steps fail. fix the path, or create the directory in an earlier step", dir.Value, n.ID.Value)
+		case !info.IsDir():
+			reported[dir] = true
+			rule.Errorf(dir.Pos, "working-directory %q is a file of the repository, not a directory, so the run: steps of job %q using it fail", dir.Value, n.ID.Value)
+		case checkout < 0:
+			reported[dir] = true
+			rule.Errorf(dir.Pos, "working-directory %q is a directory of the repository, but job %q has no actions/checkout step, so it is not in the workspace and the step at line %d fails. add a checkout before it", dir.Value, n.ID.Value, stepPos(s).Line)
+		case checkout > i:
+			reported[dir] = true
+			rule.Errorf(dir.Pos, "working-directory %q is a directory of the repository, but actions/checkout only runs at line %d, after the step at line %d using it, which fails. move the checkout before it", dir.Value, stepPos(n.Steps[checkout]).Line, stepPos(s).Line)
+		}
+	}
+	return nil
+}
+
+// createsDirectory reports whether steps may create dir: whether one of
+// them mentions it, or brings files into the workspace other than by
+// checking out the repository.
+func createsDirectory(steps []*actionlint.Step, dir string) bool {
+	for _, s := range steps {
+		name, _ := usesName(s)
+		if name != "actions/checkout" && containsFold(fetchActions, name) {
+			return true
+		}
+		if run := runScript(s); run != nil && fetchCommand.MatchString(run.Value) {
+			return true
+		}
+	}
+	return mentions(steps, dir)
+}
+
+// defaultsDirectory returns the working directory set by defaults, or nil.
+func defaultsDirectory(d *actionlint.Defaults) *actionlint.String {
+	if d == nil || d.Run == nil {
+		return nil
+	}
+	return d.Run.WorkingDirectory
+}
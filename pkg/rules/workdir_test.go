@@ -0,0 +1,110 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rhysd/actionlint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkingDirectory(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "web", "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "Makefile"), nil, 0644))
+	newRule := func() actionlint.Rule { return NewWorkingDirectory(root) }
+
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "missing directories",
+			src: `    steps:
+      - uses: actions/checkout@v4
+      - run: npm ci
+        working-directory: ./web/
+      - run: npm ci
+        working-directory: frontend
+      - run: make
+        working-directory: Makefile
+      - run: ls
+        working-directory: ${{ matrix.dir }}
+      - run: ls
+        working-directory: /tmp`,
+			want: []string{
+				`working-directory "frontend" does not exist in the repository and no earlier step of job "build" creates it`,
+				`working-directory "Makefile" is a file of the repository, not a directory`,
+			},
+		},
+		{
+			name: "created by a step",
+			src: `    steps:
+      - uses: actions/checkout@v4
+      - run: cmake -B out
+      - run: make
+        working-directory: out
+      - uses: actions/download-artifact@v4
+      - run: ls
+        working-directory: dist`,
+		},
+		{
+			name: "before checkout",
+			src: `    defaults:
+      run:
+        working-directory: web
+    steps:
+      - run: npm ci
+      - run: npm test
+      - uses: actions/checkout@v4`,
+			want: []string{`working-directory "web" is a directory of the repository, but actions/checkout only runs at line 11, after the step at line 9 using it`},
+		},
+		{
+			name: "without checkout",
+			src: `    steps:
+      - run: npm ci
+        working-directory: web/src`,
+			want: []string{`working-directory "web/src" is a directory of the repository, but job "build" has no actions/checkout step`},
+		},
+		{
+			name: "checkout path",
+			src: `    steps:
+      - uses: actions/checkout@v4
+        with:
+          path: repo
+      - run: npm ci
+        working-directory: repo/web
+      - run: npm ci
+        working-directory: repo/app
+      - run: ls
+        working-directory: web`,
+			want: []string{`working-directory "repo/app" does not exist in the repository`},
+		},
+		{
+			name: "other repository",
+			src: `    steps:
+      - uses: actions/checkout@v4
+        with:
+          repository: acme/tools
+      - run: make
+        working-directory: tools`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n" + tt.src + "\n"
+			errs := lintWith(t, newRule, src)
+			require.Len(t, errs, len(tt.want), "%v", errs)
+			for i, want := range tt.want {
+				assert.Equal(t, KindWorkingDirectory, errs[i].Kind)
+				assert.Contains(t, errs[i].Message, want)
+			}
+		})
+	}
+
+	assert.Empty(t, lintWith(t, func() actionlint.Rule { return NewWorkingDirectory("") }, "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: ls\n        working-directory: nope\n"))
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxRateLimitRetries is how many times a request that hit GitHub's rate
+// limit is retried once the limit resets.
+const maxRateLimitRetries = 3
+
+// secondaryLimitDelay is how long to wait after a secondary rate limit
+// response that does not say when to retry, as GitHub asks.
+const secondaryLimitDelay = time.Minute
+
+// rateLimitedTransport spaces the requests made through it at least
+// interval apart, and waits out GitHub's rate limit when a response says it
+// was hit, retrying the request once the limit resets, unless that is
+// further away than maxWait. Only requests without a body, which can be
+// sent again, should go through it.
+type rateLimitedTransport struct {
+	base     http.RoundTripper
+	interval time.Duration
+	maxWait  time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := sleep(req.Context(), t.reserve()); err != nil {
+			return nil, err
+		}
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		delay, limited := rateLimitDelay(resp, time.Now())
+		if !limited || attempt == maxRateLimitRetries || delay > t.maxWait {
+			return resp, nil
+		}
+		resp.Body.Close()
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// reserve returns how long to wait before the next request may be sent.
+func (t *rateLimitedTransport) reserve() time.Duration {
+	if t.interval <= 0 {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	wait := t.next.Sub(now)
+	t.next = t.next.Add(t.interval)
+	return wait
+}
+
+// rateLimitDelay reports whether resp says the rate limit was hit and, if
+// so, how long after now to wait before retrying: Retry-After when it is
+// given, otherwise until X-RateLimit-Reset when no requests remain, and
+// otherwise a minute for a secondary rate limit.
+func rateLimitDelay(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(now)+time.Second, 0), true
+		}
+		return secondaryLimitDelay, true
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return secondaryLimitDelay, true
+	}
+	// Other 403 responses are a lack of permission, not a rate limit
+	return 0, false
+}
+
+// sleep waits for d, returning early with the error of ctx when it is
+// done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Limits on workflows fetched by lint_from_url.
+const (
+	maxRemoteWorkflowSize = 1 << 20
+	remoteWorkflowTimeout = 10 * time.Second
+)
+
+// remoteWorkflowHosts are the hosts lint_from_url fetches from, so the
+// server cannot be used to reach arbitrary addresses.
+var remoteWorkflowHosts = map[string]bool{
+	"raw.githubusercontent.com":  true,
+	"gist.githubusercontent.com": true,
+}
+
+// rawWorkflowURL checks that rawURL is an https URL of a raw file on GitHub
+// or in a gist, turning github.com blob pages and gist pages into the URLs
+// of their raw content.
+func rawWorkflowURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "https" {
+		return "", fmt.Errorf("url must use https")
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	switch u.Host {
+	case "github.com":
+		// github.com/owner/repo/blob/ref/path
+		if len(parts) < 5 || parts[2] != "blob" {
+			return "", fmt.Errorf("github.com url must point to a file, as https://github.com/owner/repo/blob/ref/path")
+		}
+		u.Host = "raw.githubusercontent.com"
+		u.Path = "/" + strings.Join(append(parts[:2:2], parts[3:]...), "/")
+		u.RawQuery = ""
+	case "gist.github.com":
+		// gist.github.com/owner/id serves the page, /raw the first file
+		if len(parts) != 2 {
+			return "", fmt.Errorf("gist url must be given as https://gist.github.com/owner/id")
+		}
+		u.Host = "gist.githubusercontent.com"
+		u.Path = "/" + parts[0] + "/" + parts[1] + "/raw"
+	}
+	if !remoteWorkflowHosts[u.Host] {
+		return "", fmt.Errorf("url must be on raw.githubusercontent.com, github.com or a gist, not %s", u.Host)
+	}
+	u.Fragment = ""
+	return u.String(), nil
+}
+
+// fetchWorkflow downloads the workflow at rawURL, refusing redirects to
+// other hosts and content larger than maxRemoteWorkflowSize.
+func fetchWorkflow(ctx context.Context, client *http.Client, rawURL string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, remoteWorkflowTimeout)
+	defer cancel()
+
+	c := *client
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return fmt.Errorf("too many redirects")
+		}
+		if req.URL.Scheme != "https" || !remoteWorkflowHosts[req.URL.Host] {
+			return fmt.Errorf("redirect to %s is not allowed", req.URL.Host)
+		}
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "actionlint-mcp/"+version)
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workflow: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: unexpected status %s", rawURL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteWorkflowSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workflow: %w", err)
+	}
+	if len(data) > maxRemoteWorkflowSize {
+		return nil, fmt.Errorf("GET %s: workflow exceeds %d bytes", rawURL, maxRemoteWorkflowSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRawWorkflowURL(t *testing.T) {
+	tests := []struct {
+		in, want, err string
+	}{
+		{in: "https://raw.githubusercontent.com/o/r/main/.github/workflows/ci.yml", want: "https://raw.githubusercontent.com/o/r/main/.github/workflows/ci.yml"},
+		{in: "https://github.com/o/r/blob/v1.2/.github/workflows/ci.yml?plain=1#L3", want: "https://raw.githubusercontent.com/o/r/v1.2/.github/workflows/ci.yml"},
+		{in: "https://gist.github.com/someone/abc123", want: "https://gist.githubusercontent.com/someone/abc123/raw"},
+		{in: "https://gist.githubusercontent.com/someone/abc123/raw/ci.yml", want: "https://gist.githubusercontent.com/someone/abc123/raw/ci.yml"},
+		{in: "http://raw.githubusercontent.com/o/r/main/ci.yml", err: "https"},
+		{in: "https://github.com/o/r", err: "must point to a file"},
+		{in: "https://example.com/ci.yml", err: "not example.com"},
+		{in: "https://169.254.169.254/latest/meta-data", err: "not 169.254.169.254"},
+	}
+	for _, tt := range tests {
+		got, err := rawWorkflowURL(tt.in)
+		if tt.err != "" {
+			assert.ErrorContains(t, err, tt.err, tt.in)
+			continue
+		}
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestFetchWorkflow(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ci.yml":
+			_, _ = w.Write([]byte("on: push\n"))
+		case "/large.yml":
+			_, _ = w.Write([]byte(strings.Repeat("#", maxRemoteWorkflowSize+1)))
+		case "/away":
+			http.Redirect(w, r, "https://example.com/ci.yml", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "https://")
+	remoteWorkflowHosts[host] = true
+	defer delete(remoteWorkflowHosts, host)
+
+	ctx := context.Background()
+	data, err := fetchWorkflow(ctx, server.Client(), server.URL+"/ci.yml")
+	require.NoError(t, err)
+	assert.Equal(t, "on: push\n", string(data))
+
+	_, err = fetchWorkflow(ctx, server.Client(), server.URL+"/large.yml")
+	assert.ErrorContains(t, err, "exceeds")
+
+	_, err = fetchWorkflow(ctx, server.Client(), server.URL+"/missing.yml")
+	assert.ErrorContains(t, err, "404")
+
+	_, err = fetchWorkflow(ctx, server.Client(), server.URL+"/away")
+	var urlErr *url.Error
+	require.ErrorAs(t, err, &urlErr)
+	assert.ErrorContains(t, err, "redirect to example.com is not allowed")
+}
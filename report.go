@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Formats of the result files written for output_path.
+const (
+	outputSARIF    = "sarif"
+	outputMarkdown = "markdown"
+)
+
+// sarifSchema is the schema of the SARIF version written.
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// checkOutputParams returns an error when the output_path and
+// output_format parameters of a tool cannot be used.
+func checkOutputParams(ctx context.Context, path, format string) error {
+	switch format {
+	case "", formatJSON, outputSARIF, outputMarkdown:
+	default:
+		return fmt.Errorf("unknown output_format %q; use %s, %s or %s", format, formatJSON, outputSARIF, outputMarkdown)
+	}
+	switch {
+	case path == "" && format != "":
+		return fmt.Errorf("output_format needs output_path")
+	case path != "" && !settingsFrom(ctx).allowWrites:
+		return withCode(codePathForbidden, errors.New("output_path needs the server to run with -allow-writes"))
+	}
+	return nil
+}
+
+// outputResult renders out, the output of lint_workflow holding lint, and
+// writes its findings to path in format, as writeOutput. The file has every
+// finding, while the result returned is fitted to limit bytes.
+func outputResult(out any, lint *LintResult, path, format string, limit int) (*mcp.CallToolResultFor[any], error) {
+	result, err := jsonResult(out)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeOutput(result, path, format, singleSummary(lint), out); err != nil {
+		return nil, err
+	}
+	return fitResult(limit, result, []*LintResult{lint}, func() (*mcp.CallToolResultFor[any], error) {
+		return jsonResult(out)
+	})
+}
+
+// writeOutput writes the findings of summary to path in format, json
+// writing out, the tool's own output, and notes the file in result. Nothing
+// is written when path is empty.
+func writeOutput(result *mcp.CallToolResultFor[any], path, format string, summary *linter.Summary, out any) error {
+	if path == "" {
+		return nil
+	}
+	if format == "" {
+		format = formatJSON
+	}
+
+	var data []byte
+	switch format {
+	case outputSARIF:
+		sarif, err := payloadResult(sarifLog(summary))
+		if err != nil {
+			return err
+		}
+		data = []byte(sarif.Content[0].(*mcp.TextContent).Text)
+	case outputMarkdown:
+		data = []byte(markdownReport(summary))
+	default:
+		res, err := jsonResult(out)
+		if err != nil {
+			return err
+		}
+		data = []byte(res.Content[0].(*mcp.TextContent).Text)
+	}
+	if !strings.HasSuffix(string(data), "\n") {
+		data = append(data, '\n')
+	}
+
+	path = linter.CleanPath(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	result.Content = append(result.Content, &mcp.TextContent{Text: fmt.Sprintf("Wrote the results as %s to %s", format, path)})
+	return nil
+}
+
+// singleSummary is the summary of a single lint result, for the files
+// written by lint_workflow.
+func singleSummary(result *LintResult) *linter.Summary {
+	summary := &linter.Summary{SchemaVersion: linter.SchemaVersion, TotalFiles: 1, Results: []linter.LintResult{*result}}
+	summary.Results[0].SchemaVersion = 0
+	summary.TotalErrors = len(result.Errors)
+	if summary.TotalErrors > 0 && !result.Valid {
+		summary.FilesWithErrors = 1
+	}
+	return summary
+}
+
+// SARIF 2.1.0 types, reduced to what code scanning reads.
+type (
+	sarifDocument struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		Version        string      `json:"version,omitempty"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules" jsonschema:"One rule per kind of finding reported"`
+	}
+	sarifRule struct {
+		ID string `json:"id" jsonschema:"The kind of finding"`
+	}
+	sarifResult struct {
+		RuleID    string          `json:"ruleId" jsonschema:"The kind of the finding, or actionlint-mcp for a finding without one"`
+		Level     string          `json:"level" jsonschema:"error for critical and error findings, warning for warning findings and note for info findings"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations" jsonschema:"The file of the finding"`
+	}
+	sarifMessage struct {
+		Text string `json:"text" jsonschema:"The message of the finding"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri" jsonschema:"The file path of the finding, with forward slashes"`
+	}
+	sarifRegion struct {
+		StartLine   int `json:"startLine" jsonschema:"The line of the finding; left out with the region for findings without one"`
+		StartColumn int `json:"startColumn,omitempty" jsonschema:"The column of the finding"`
+	}
+)
+
+// sarifLog converts summary to a SARIF log, with one rule per kind of
+// finding. Findings without a kind are reported under "actionlint-mcp".
+func sarifLog(summary *linter.Summary) sarifDocument {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "actionlint-mcp",
+			Version:        version,
+			InformationURI: "https://github.com/" + releaseRepo,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	rules := map[string]bool{}
+	for _, r := range summary.Results {
+		for _, e := range r.Errors {
+			id := e.Kind
+			if id == "" {
+				id = "actionlint-mcp"
+			}
+			if !rules[id] {
+				rules[id] = true
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: id})
+			}
+			loc := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(r.FilePath)},
+			}}
+			if e.Line > 0 {
+				loc.PhysicalLocation.Region = &sarifRegion{StartLine: e.Line, StartColumn: e.Column}
+			}
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    id,
+				Level:     sarifLevel(e.Severity),
+				Message:   sarifMessage{Text: e.Message},
+				Locations: []sarifLocation{loc},
+			})
+		}
+	}
+	return sarifDocument{Schema: sarifSchema, Version: "2.1.0", Runs: []sarifRun{run}}
+}
+
+// sarifLevel maps a severity to the SARIF level code scanning shows it at.
+func sarifLevel(severity string) string {
+	switch severity {
+	case linter.SeverityCritical, linter.SeverityError:
+		return "error"
+	case linter.SeverityWarning:
+		return "warning"
+	}
+	return "note"
+}
+
+// markdownReport renders summary as a Markdown report, such as a job
+// summary or a pull request comment: the totals, then a table of the
+// findings of each file.
+func markdownReport(summary *linter.Summary) string {
+	var b strings.Builder
+	b.WriteString("# actionlint results\n\n")
+	if summary.TotalErrors == 0 {
+		fmt.Fprintf(&b, "All %d workflows pass.\n", summary.TotalFiles)
+		return b.String()
+	}
+	findings := "findings"
+	if summary.TotalErrors == 1 {
+		findings = "finding"
+	}
+	fmt.Fprintf(&b, "%d %s in %d of %d workflows.\n", summary.TotalErrors, findings, summary.FilesWithErrors, summary.TotalFiles)
+	for _, r := range summary.Results {
+		if len(r.Errors) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## `%s`\n\n| Line | Severity | Rule | Message |\n| ---: | --- | --- | --- |\n", r.FilePath)
+		for _, e := range r.Errors {
+			fmt.Fprintf(&b, "| %d | %s | `%s` | %s |\n", e.Line, e.Severity, e.Kind, markdownCell(e.Message))
+		}
+	}
+	return b.String()
+}
+
+// markdownCell escapes text for a Markdown table cell.
+func markdownCell(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintWorkflow_OutputPath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ci.yml")
+	require.NoError(t, os.WriteFile(path, []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    timeout: 10\n    steps:\n      - run: echo hi\n"), 0644))
+	lint := func(args LintWorkflowParams) (*mcp.CallToolResultFor[any], error) {
+		return LintWorkflow(context.Background(), nil, &mcp.CallToolParamsFor[LintWorkflowParams]{Arguments: args})
+	}
+
+	_, err := lint(LintWorkflowParams{FilePath: path, OutputPath: filepath.Join(dir, "out.json")})
+	assert.ErrorContains(t, err, "-allow-writes")
+	assert.NoFileExists(t, filepath.Join(dir, "out.json"))
+
+	enableWrites(t)
+	out := filepath.Join(dir, "reports", "lint.json")
+	result, err := lint(LintWorkflowParams{FilePath: path, OutputPath: out})
+	require.NoError(t, err)
+	require.Len(t, result.Content, 2)
+	assert.Equal(t, "Wrote the results as json to "+out, result.Content[1].(*mcp.TextContent).Text)
+	written, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, result.Content[0].(*mcp.TextContent).Text+"\n", string(written))
+
+	_, err = lint(LintWorkflowParams{FilePath: path, OutputPath: out, OutputFormat: "html"})
+	assert.ErrorContains(t, err, `unknown output_format "html"`)
+	_, err = lint(LintWorkflowParams{FilePath: path, OutputFormat: outputSARIF})
+	assert.ErrorContains(t, err, "output_format needs output_path")
+}
+
+func TestCheckAllWorkflows_OutputPath(t *testing.T) {
+	enableWrites(t)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ci.yml"), []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    timeout: 10\n    steps:\n      - run: echo hi\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ok.yml"), []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n"), 0644))
+
+	sarifPath := filepath.Join(dir, "results.sarif")
+	_, err := CheckAllWorkflows(context.Background(), nil, &mcp.CallToolParamsFor[CheckAllWorkflowsParams]{
+		Arguments: CheckAllWorkflowsParams{Directory: dir, Format: formatSlackBlocks, OutputPath: sarifPath, OutputFormat: outputSARIF},
+	})
+	require.NoError(t, err)
+	data, err := os.ReadFile(sarifPath)
+	require.NoError(t, err)
+	var sarif sarifDocument
+	require.NoError(t, json.Unmarshal(data, &sarif))
+	assert.Equal(t, "2.1.0", sarif.Version)
+	require.Len(t, sarif.Runs, 1)
+	require.Len(t, sarif.Runs[0].Results, 1)
+	res := sarif.Runs[0].Results[0]
+	assert.Equal(t, "syntax-check", res.RuleID)
+	assert.Equal(t, "error", res.Level)
+	assert.Equal(t, filepath.ToSlash(filepath.Join(dir, "ci.yml")), res.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 5, res.Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Equal(t, []sarifRule{{ID: "syntax-check"}}, sarif.Runs[0].Tool.Driver.Rules)
+
+	mdPath := filepath.Join(dir, "summary.md")
+	_, err = CheckAllWorkflows(context.Background(), nil, &mcp.CallToolParamsFor[CheckAllWorkflowsParams]{
+		Arguments: CheckAllWorkflowsParams{Directory: dir, OutputPath: mdPath, OutputFormat: outputMarkdown},
+	})
+	require.NoError(t, err)
+	md, err := os.ReadFile(mdPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(md), "1 finding in 1 of 2 workflows.")
+	assert.Contains(t, string(md), "| 5 | error | `syntax-check` |")
+}
+
+func TestMarkdownReport(t *testing.T) {
+	summary := &linter.Summary{TotalFiles: 1}
+	assert.Equal(t, "# actionlint results\n\nAll 1 workflows pass.\n", markdownReport(summary))
+
+	summary = singleSummary(&LintResult{FilePath: "ci.yml", Errors: []LintError{{Message: "a | b", Line: 2, Kind: "k", Severity: "info"}}})
+	assert.Contains(t, markdownReport(summary), "| 2 | info | `k` | a \\| b |\n")
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+)
+
+// fetchRepositoryFacts reads from the API whether repo, given as
+// owner/name, is a template repository and whether it has deployments.
+func fetchRepositoryFacts(ctx context.Context, client *http.Client, repo string) (linter.RepositoryFacts, error) {
+	data, err := githubGet(ctx, client, fmt.Sprintf("%s/repos/%s", githubAPIBaseURL, repo))
+	if err != nil {
+		return linter.RepositoryFacts{}, fmt.Errorf("failed to read repository: %w", err)
+	}
+	var r struct {
+		IsTemplate bool `json:"is_template"`
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return linter.RepositoryFacts{}, fmt.Errorf("failed to parse repository: %w", err)
+	}
+
+	data, err = githubGet(ctx, client, fmt.Sprintf("%s/repos/%s/deployments?per_page=1", githubAPIBaseURL, repo))
+	if err != nil {
+		return linter.RepositoryFacts{}, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	var deployments []json.RawMessage
+	if err := json.Unmarshal(data, &deployments); err != nil {
+		return linter.RepositoryFacts{}, fmt.Errorf("failed to parse deployments: %w", err)
+	}
+	return linter.RepositoryFacts{Template: r.IsTemplate, NoDeployments: len(deployments) == 0}, nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindOrphanedWorkflows(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/repos/owner/template":
+			_, _ = w.Write([]byte(`{"full_name": "owner/template", "is_template": true}`))
+		case "/repos/owner/template/deployments":
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	oldURL := githubAPIBaseURL
+	githubAPIBaseURL = server.URL
+	defer func() { githubAPIBaseURL = oldURL }()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "release.yml"), []byte("on: release\njobs:\n  publish:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ci.yml"), []byte("on:\n  push:\n    branches: [master]\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n"), 0644))
+
+	call := func(repo string) orphanedWorkflows {
+		t.Helper()
+		result, err := FindOrphanedWorkflows(context.Background(), nil, &mcp.CallToolParamsFor[FindOrphanedWorkflowsParams]{
+			Arguments: FindOrphanedWorkflowsParams{Directory: dir, Repository: repo},
+		})
+		require.NoError(t, err)
+		var out orphanedWorkflows
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out))
+		return out
+	}
+
+	// Neither git nor the API is available
+	t.Setenv("GITHUB_TOKEN", "")
+	useSettings(t, serverConfig{}, false)
+	out := call("owner/template")
+	assert.Empty(t, out.Workflows)
+	assert.Empty(t, out.Sources)
+	require.Len(t, out.Skipped, 2)
+	assert.Contains(t, out.Skipped[0], "branch filters were not checked")
+	assert.Contains(t, out.Skipped[1], "set GITHUB_TOKEN to read owner/template")
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		require.NoError(t, err, "%s", output)
+	}
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	useSettings(t, serverConfig{}, false)
+	out = call("owner/template")
+	assert.Equal(t, []string{"git", "github:owner/template"}, out.Sources)
+	assert.Empty(t, out.Skipped)
+	require.Len(t, out.Workflows, 2)
+	assert.Equal(t, filepath.Join(dir, "ci.yml"), out.Workflows[0].FilePath)
+	assert.True(t, out.Workflows[0].Orphaned)
+	assert.Equal(t, "no branch of the repository matches the branches filter (master)", out.Workflows[0].Triggers[0].Reason)
+	assert.Equal(t, "release", out.Workflows[1].Triggers[0].Event)
+}
+
+func TestWorkflowCoverage(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".github", "workflows"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".github", "workflows", "ci.yml"), []byte("on: [push, pull_request]\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: cargo test\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "main.rs"), []byte("fn main() {}\n"), 0644))
+
+	result, err := WorkflowCoverage(context.Background(), nil, &mcp.CallToolParamsFor[WorkflowCoverageParams]{Arguments: WorkflowCoverageParams{Directory: root}})
+	require.NoError(t, err)
+	var out linter.Coverage
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out))
+	require.Len(t, out.Directories, 1)
+	assert.True(t, out.Directories[0].Covered)
+	require.Len(t, out.Languages, 1)
+	assert.Equal(t, "Rust", out.Languages[0].Language)
+	assert.True(t, out.Languages[0].Covered)
+	assert.Empty(t, out.MissingEvents)
+
+	_, err = WorkflowCoverage(context.Background(), nil, &mcp.CallToolParamsFor[WorkflowCoverageParams]{Arguments: WorkflowCoverageParams{Directory: filepath.Join(root, "missing")}})
+	assert.ErrorContains(t, err, "is not a directory")
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// schemaURIPrefix is the URI of the schema resources, followed by their
+// file name.
+const schemaURIPrefix = "actionlint-mcp://schemas/"
+
+// schemaDialect is the JSON Schema version the resources are written in.
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaResource is a JSON Schema published as an MCP resource.
+type schemaResource struct {
+	name        string
+	title       string
+	description string
+	schema      func() (*jsonschema.Schema, error)
+}
+
+// schemaResources are the schemas clients can read to check outputs and
+// configuration files programmatically. The result schemas are inferred
+// from the Go types, so they follow the types as fields are added.
+var schemaResources = []schemaResource{
+	{
+		name:        "lint-result.json",
+		title:       "Lint result",
+		description: "A lint_workflow result: the findings of one workflow file",
+		schema:      func() (*jsonschema.Schema, error) { return resultSchema[linter.LintResult]() },
+	},
+	{
+		name:        "summary.json",
+		title:       "Lint summary",
+		description: "A check_all_workflows summary: the results of the workflow files of a directory",
+		schema:      func() (*jsonschema.Schema, error) { return resultSchema[linter.Summary]() },
+	},
+	{
+		name:        "sarif.json",
+		title:       "SARIF log",
+		description: "The SARIF 2.1.0 log of output_format sarif and scan-org -sarif-dir: how findings map to SARIF rules, results, levels and locations",
+		schema:      sarifMappingSchema,
+	},
+	{
+		name:        "config.json",
+		title:       "Configuration file",
+		description: "The configuration file given with -config, including the required-steps policies; YAML files are checked as their JSON equivalent",
+		schema:      func() (*jsonschema.Schema, error) { return yamlSchema(reflect.TypeFor[serverConfig]()), nil },
+	},
+}
+
+// resultSchema is the schema of the JSON output of T, led by the
+// schema_version every tool output has.
+func resultSchema[T any]() (*jsonschema.Schema, error) {
+	s, err := jsonschema.For[T]()
+	if err != nil {
+		return nil, err
+	}
+	s.Properties["schema_version"] = &jsonschema.Schema{
+		Type:        "integer",
+		Description: "Version of the result format",
+		Const:       jsonschema.Ptr[any](linter.SchemaVersion),
+	}
+	s.Required = append([]string{"schema_version"}, s.Required...)
+	return s, nil
+}
+
+// sarifMappingSchema is the schema of the SARIF logs the server writes:
+// the part of SARIF 2.1.0 code scanning reads, with the values findings
+// map to. The logs also validate against the full SARIF schema.
+func sarifMappingSchema() (*jsonschema.Schema, error) {
+	s, err := jsonschema.For[sarifDocument]()
+	if err != nil {
+		return nil, err
+	}
+	s.Properties["$schema"].Const = jsonschema.Ptr[any](sarifSchema)
+	s.Properties["version"].Const = jsonschema.Ptr[any]("2.1.0")
+	result := s.Properties["runs"].Items.Properties["results"].Items
+	result.Properties["level"].Enum = []any{"error", "warning", "note"}
+	return s, nil
+}
+
+// yamlSchema is the schema of t as read from YAML with unknown keys
+// rejected, named by its yaml tags.
+func yamlSchema(t reflect.Type) *jsonschema.Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &jsonschema.Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &jsonschema.Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonschema.Schema{Type: "number"}
+	case reflect.String:
+		return &jsonschema.Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &jsonschema.Schema{Type: "array", Items: yamlSchema(t.Elem())}
+	case reflect.Map:
+		return &jsonschema.Schema{Type: "object", AdditionalProperties: yamlSchema(t.Elem())}
+	case reflect.Struct:
+		s := &jsonschema.Schema{
+			Type:                 "object",
+			Properties:           map[string]*jsonschema.Schema{},
+			AdditionalProperties: &jsonschema.Schema{Not: &jsonschema.Schema{}},
+		}
+		for i := range t.NumField() {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(f.Name)
+			}
+			s.Properties[name] = yamlSchema(f.Type)
+		}
+		return s
+	}
+	return &jsonschema.Schema{}
+}
+
+// addSchemaResources registers the schema resources on server.
+func addSchemaResources(server *mcp.Server) {
+	for _, r := range schemaResources {
+		server.AddResource(&mcp.Resource{
+			URI:         schemaURIPrefix + r.name,
+			Name:        r.name,
+			Title:       r.title,
+			Description: r.description,
+			MIMEType:    "application/schema+json",
+		}, readSchemaResource)
+	}
+}
+
+// readSchemaResource returns the schema resource params asks for.
+func readSchemaResource(ctx context.Context, session *mcp.ServerSession, params *mcp.ReadResourceParams) (*mcp.ReadResourceResult, error) {
+	name, ok := strings.CutPrefix(params.URI, schemaURIPrefix)
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(params.URI)
+	}
+	for _, r := range schemaResources {
+		if r.name != name {
+			continue
+		}
+		text, err := r.document()
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: params.URI, MIMEType: "application/schema+json", Text: text}},
+		}, nil
+	}
+	return nil, mcp.ResourceNotFoundError(params.URI)
+}
+
+// document renders the schema of r, identified by its URI.
+func (r schemaResource) document() (string, error) {
+	s, err := r.schema()
+	if err != nil {
+		return "", fmt.Errorf("failed to build schema %s: %w", r.name, err)
+	}
+	s.Schema = schemaDialect
+	s.ID = schemaURIPrefix + r.name
+	s.Title = r.title
+	s.Description = r.description
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal schema %s: %w", r.name, err)
+	}
+	return string(data), nil
+}
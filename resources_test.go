@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// readSchema reads the schema resource name and resolves it.
+func readSchema(t *testing.T, name string) *jsonschema.Resolved {
+	t.Helper()
+	res, err := readSchemaResource(context.Background(), nil, &mcp.ReadResourceParams{URI: schemaURIPrefix + name})
+	require.NoError(t, err)
+	require.Len(t, res.Contents, 1)
+	assert.Equal(t, "application/schema+json", res.Contents[0].MIMEType)
+
+	var s jsonschema.Schema
+	require.NoError(t, json.Unmarshal([]byte(res.Contents[0].Text), &s))
+	assert.Equal(t, schemaURIPrefix+name, s.ID)
+	resolved, err := s.Resolve(nil)
+	require.NoError(t, err)
+	return resolved
+}
+
+// instance returns the JSON value of text, as a validator sees it.
+func instance(t *testing.T, text string) any {
+	t.Helper()
+	var v any
+	require.NoError(t, json.Unmarshal([]byte(text), &v))
+	return v
+}
+
+func TestSchemaResources_Results(t *testing.T) {
+	content := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ github.event.issue.title }}\n"
+	result, err := linter.New(linter.Options{}).Lint(context.Background(), linter.Input{Content: []byte(content)})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Errors)
+	out, err := jsonResult(result)
+	require.NoError(t, err)
+
+	schema := readSchema(t, "lint-result.json")
+	assert.NoError(t, schema.Validate(instance(t, out.Content[0].(*mcp.TextContent).Text)))
+	assert.Error(t, schema.Validate(instance(t, `{"errors": [], "valid": true}`)), "schema_version is required")
+
+	summary := &linter.Summary{TotalFiles: 1, Results: []linter.LintResult{*result}}
+	out, err = jsonResult(summary)
+	require.NoError(t, err)
+	assert.NoError(t, readSchema(t, "summary.json").Validate(instance(t, out.Content[0].(*mcp.TextContent).Text)))
+}
+
+func TestSchemaResources_SARIF(t *testing.T) {
+	summary := &linter.Summary{TotalFiles: 1, Results: []linter.LintResult{{
+		FilePath: "ci.yml",
+		Errors: []linter.LintError{
+			{Message: "job needs a missing job", Line: 3, Column: 5, Kind: "job-needs", Severity: linter.SeverityError},
+			{Message: "file too large", Severity: linter.SeverityError},
+		},
+	}}}
+	data, err := json.Marshal(sarifLog(summary))
+	require.NoError(t, err)
+
+	schema := readSchema(t, "sarif.json")
+	assert.NoError(t, schema.Validate(instance(t, string(data))))
+	invalid := strings.Replace(string(data), `"level":"error"`, `"level":"critical"`, 1)
+	assert.Error(t, schema.Validate(instance(t, invalid)), "severities map to SARIF levels")
+}
+
+func TestSchemaResources_Config(t *testing.T) {
+	schema := readSchema(t, "config.json")
+
+	var cfg any
+	require.NoError(t, yaml.Unmarshal([]byte(`rules:
+  matrix:
+    max-combinations: 64
+  required-steps:
+    - name: harden-runner
+      require:
+        uses: step-security/harden-runner
+      first: true
+  permissions:
+    actions:
+      acme/deploy:
+        deployments: write
+webhook:
+  url: https://example.com/hook
+`), &cfg))
+	assert.NoError(t, schema.Validate(cfg))
+
+	require.NoError(t, yaml.Unmarshal([]byte("rules:\n  matrix:\n    max-combinatons: 64\n"), &cfg))
+	assert.Error(t, schema.Validate(cfg), "unknown keys are rejected, as when the file is loaded")
+}
+
+func TestSchemaResources_NotFound(t *testing.T) {
+	_, err := readSchemaResource(context.Background(), nil, &mcp.ReadResourceParams{URI: schemaURIPrefix + "missing.json"})
+	assert.Error(t, err)
+}
+
+func TestLatestResultsResource(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ci.yml"), []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo \"::set-output name=tag::v1\"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "release.yml"), []byte(brokenWorkflow), 0644))
+
+	ctx := context.Background()
+	server := newServer()
+	connect := func() *mcp.ClientSession {
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		_, err := server.Connect(ctx, serverTransport)
+		require.NoError(t, err)
+		session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil).Connect(ctx, clientTransport)
+		require.NoError(t, err)
+		t.Cleanup(func() { session.Close() })
+		return session
+	}
+	session, other := connect(), connect()
+
+	read := func(session *mcp.ClientSession, uri string) (lintRun, error) {
+		res, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+		if err != nil {
+			return lintRun{}, err
+		}
+		var run lintRun
+		require.NoError(t, json.Unmarshal([]byte(res.Contents[0].Text), &run))
+		return run, nil
+	}
+
+	_, err := read(session, latestResultsURI)
+	assert.ErrorContains(t, err, "run check_all_workflows first")
+
+	// The results keep every finding whatever the tool returned
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "check_all_workflows", Arguments: map[string]any{"directory": dir, "digest": true, "fail_on": "error"}})
+	require.NoError(t, err)
+	run, err := read(session, latestResultsURI)
+	require.NoError(t, err)
+	assert.Equal(t, dir, run.Directory)
+	assert.Equal(t, 2, run.TotalFiles)
+	assert.Equal(t, 2, run.FilesWithErrors)
+	require.Len(t, run.Results, 2)
+	assert.False(t, run.Results[0].Valid)
+
+	run, err = read(session, latestResultsURI+"?severity=error,critical")
+	require.NoError(t, err)
+	require.Len(t, run.Results, 1)
+	assert.Equal(t, filepath.Join(dir, "release.yml"), run.Results[0].FilePath)
+	assert.Equal(t, run.TotalErrors, len(run.Results[0].Errors))
+
+	run, err = read(session, latestResultsURI+"?rule=deprecated-commands&file=c%2A.yml")
+	require.NoError(t, err)
+	require.Len(t, run.Results, 1)
+	assert.Equal(t, "deprecated-commands", run.Results[0].Errors[0].Kind)
+
+	run, err = read(session, latestResultsURI+"?file=release.yml")
+	require.NoError(t, err)
+	assert.Equal(t, 1, run.TotalFiles)
+
+	_, err = read(session, latestResultsURI+"?severity=fatal")
+	assert.ErrorContains(t, err, `unknown severity "fatal"`)
+	_, err = read(session, latestResultsURI+"?kind=expression")
+	assert.ErrorContains(t, err, `unknown filter "kind"`)
+
+	// Each session has its own results
+	_, err = read(other, latestResultsURI)
+	assert.ErrorContains(t, err, "run check_all_workflows first")
+}
+
+func TestLatestResults_SessionEnd(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ci.yml"), []byte(brokenWorkflow), 0644))
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := newServer().Connect(ctx, serverTransport)
+	require.NoError(t, err)
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil).Connect(ctx, clientTransport)
+	require.NoError(t, err)
+
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "check_all_workflows", Arguments: map[string]any{"directory": dir}})
+	require.NoError(t, err)
+	_, ok := latestRuns.get(serverSession)
+	require.True(t, ok)
+
+	require.NoError(t, session.Close())
+	assert.Eventually(t, func() bool {
+		_, ok := latestRuns.get(serverSession)
+		return !ok
+	}, 5*time.Second, 10*time.Millisecond, "the results are forgotten when the session ends")
+}
+
+func TestResultStore_MaxSessions(t *testing.T) {
+	store := &resultStore{latest: map[*mcp.ServerSession]lintRun{}}
+	sessions := make([]*mcp.ServerSession, maxSessions+1)
+	for i := range sessions {
+		sessions[i] = &mcp.ServerSession{}
+		store.record(sessions[i], "", &linter.Summary{})
+	}
+	store.record(sessions[0], "", &linter.Summary{})
+	store.record(sessions[len(sessions)-1], "", &linter.Summary{})
+
+	assert.Len(t, store.latest, maxSessions)
+	_, ok := store.get(sessions[1])
+	assert.False(t, ok, "the least recently used session is forgotten")
+	_, ok = store.get(sessions[0])
+	assert.True(t, ok)
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// latestResultsURI is the URI of the resource holding the last
+// check_all_workflows results of the session. The rule, severity and file
+// query parameters filter it.
+const latestResultsURI = "actionlint://results/latest"
+
+// lintRun is a check_all_workflows run: the directory it linted, when, and
+// every finding, before compare_to and fail_on.
+type lintRun struct {
+	Directory string    `json:"directory"`
+	LintedAt  time.Time `json:"linted_at"`
+	*linter.Summary
+}
+
+// resultStore keeps the last check_all_workflows run of each session, for
+// the latest results resource, until the session ends.
+type resultStore struct {
+	mu       sync.Mutex
+	latest   map[*mcp.ServerSession]lintRun
+	sessions sessionKeys
+}
+
+// latestRuns is the store of the sessions' last runs.
+var latestRuns = &resultStore{latest: map[*mcp.ServerSession]lintRun{}}
+
+// record makes a copy of summary the last run of session, since the
+// filters of the tool change the findings of the summary in place.
+func (s *resultStore) record(session *mcp.ServerSession, directory string, summary *linter.Summary) {
+	saved := *summary
+	saved.Results = make([]linter.LintResult, len(summary.Results))
+	for i, r := range summary.Results {
+		r.Errors = slices.Clone(r.Errors)
+		saved.Results[i] = r
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latest[session] = lintRun{Directory: directory, LintedAt: time.Now().UTC(), Summary: &saved}
+	for _, old := range s.sessions.use(session) {
+		delete(s.latest, old)
+	}
+}
+
+// forget drops the last run of session.
+func (s *resultStore) forget(session *mcp.ServerSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.latest, session)
+	s.sessions.forget(session)
+}
+
+func (s *resultStore) get(session *mcp.ServerSession) (lintRun, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run, ok := s.latest[session]
+	return run, ok
+}
+
+// addResultsResource registers the latest results resource on server, and
+// its template taking the filters.
+func addResultsResource(server *mcp.Server) {
+	const description = "The last check_all_workflows results of the session, with every finding; filter them with the rule, severity and file query parameters, each taking a comma-separated list, without linting again"
+	server.AddResource(&mcp.Resource{
+		URI:         latestResultsURI,
+		Name:        "latest-results",
+		Title:       "Latest lint results",
+		Description: description,
+		MIMEType:    "application/json",
+	}, readLatestResults)
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: latestResultsURI + "{?rule,severity,file}",
+		Name:        "latest-results",
+		Title:       "Latest lint results",
+		Description: description,
+		MIMEType:    "application/json",
+	}, readLatestResults)
+}
+
+// readLatestResults returns the last run of the session, keeping the
+// findings that match the filters of the URI: rule by kind, severity, and
+// file by path or name, with * and ? wildcards. Totals are those of the
+// findings kept, and results without any are left out when filtering by
+// rule or severity.
+func readLatestResults(ctx context.Context, session *mcp.ServerSession, params *mcp.ReadResourceParams) (*mcp.ReadResourceResult, error) {
+	u, err := url.Parse(params.URI)
+	if err != nil || u.Scheme+"://"+u.Host+u.Path != latestResultsURI {
+		return nil, mcp.ResourceNotFoundError(params.URI)
+	}
+	filters := map[string][]string{}
+	for key, values := range u.Query() {
+		if key != "rule" && key != "severity" && key != "file" {
+			return nil, fmt.Errorf("unknown filter %q; use rule, severity or file", key)
+		}
+		for _, v := range values {
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					filters[key] = append(filters[key], item)
+				}
+			}
+		}
+	}
+	for _, s := range filters["severity"] {
+		if !slices.Contains(severityOrder, s) {
+			return nil, fmt.Errorf("unknown severity %q; use critical, error, warning or info", s)
+		}
+	}
+	for _, f := range filters["file"] {
+		if _, err := path.Match(f, ""); err != nil {
+			return nil, fmt.Errorf("invalid file pattern %q: %w", f, err)
+		}
+	}
+
+	run, ok := latestRuns.get(session)
+	if !ok {
+		return nil, fmt.Errorf("no results yet; run check_all_workflows first")
+	}
+	run.Summary = filterSummary(run.Summary, filters)
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: params.URI, MIMEType: "application/json", Text: string(data)}},
+	}, nil
+}
+
+// filterSummary returns the findings of summary that match filters, as
+// readLatestResults describes.
+func filterSummary(summary *linter.Summary, filters map[string][]string) *linter.Summary {
+	matches := func(values []string, v string) bool {
+		return len(values) == 0 || slices.Contains(values, v)
+	}
+	findingFilter := len(filters["rule"]) > 0 || len(filters["severity"]) > 0
+
+	out := &linter.Summary{SchemaVersion: summary.SchemaVersion, Results: []linter.LintResult{}, Meta: summary.Meta}
+	for _, r := range summary.Results {
+		if !matchesFile(filters["file"], r.FilePath) {
+			continue
+		}
+		errs := make([]linter.LintError, 0, len(r.Errors))
+		for _, e := range r.Errors {
+			if matches(filters["rule"], e.Kind) && matches(filters["severity"], e.Severity) {
+				errs = append(errs, e)
+			}
+		}
+		if findingFilter && len(errs) == 0 {
+			continue
+		}
+		r.Errors, r.Valid = errs, len(errs) == 0
+		out.Results = append(out.Results, r)
+		out.TotalFiles++
+		if !r.Valid {
+			out.FilesWithErrors++
+			out.TotalErrors += len(errs)
+		}
+	}
+	return out
+}
+
+// matchesFile reports whether file, or its base name, matches one of
+// patterns, or there are none.
+func matchesFile(patterns []string, file string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	file = filepath.ToSlash(file)
+	for _, p := range patterns {
+		p = filepath.ToSlash(p)
+		if ok, _ := path.Match(p, file); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, path.Base(file)); ok {
+			return true
+		}
+	}
+	return false
+}
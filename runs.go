@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+)
+
+// defaultRunHistory is how many recent runs workflow_flakiness looks at,
+// and maxRunHistory the most the API returns in one page.
+const (
+	defaultRunHistory = 50
+	maxRunHistory     = 100
+)
+
+type workflowRuns struct {
+	WorkflowRuns []struct {
+		Status     string `json:"status"`
+		Conclusion string `json:"conclusion"`
+	} `json:"workflow_runs"`
+}
+
+// fetchRunHistory counts the conclusions of the latest completed runs of a
+// workflow file in repo, which is given as owner/name.
+func fetchRunHistory(ctx context.Context, client *http.Client, repo, path string, limit int) (linter.RunHistory, error) {
+	u := fmt.Sprintf("%s/repos/%s/actions/workflows/%s/runs?status=completed&per_page=%d",
+		githubAPIBaseURL, repo, url.PathEscape(filepath.Base(path)), limit)
+	data, err := githubGet(ctx, client, u)
+	if err != nil {
+		return linter.RunHistory{}, fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	var runs workflowRuns
+	if err := json.Unmarshal(data, &runs); err != nil {
+		return linter.RunHistory{}, fmt.Errorf("failed to parse runs: %w", err)
+	}
+
+	var h linter.RunHistory
+	for _, r := range runs.WorkflowRuns {
+		if r.Status != "completed" {
+			continue
+		}
+		h.Runs++
+		switch r.Conclusion {
+		case "failure", "startup_failure":
+			h.Failures++
+		case "cancelled":
+			h.Cancelled++
+		case "timed_out":
+			h.TimedOut++
+		}
+	}
+	return h, nil
+}
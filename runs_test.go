@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowFlakiness(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/repos/owner/repo/actions/workflows/ci.yml/runs":
+			assert.Equal(t, "5", r.URL.Query().Get("per_page"))
+			_, _ = w.Write([]byte(`{"workflow_runs": [
+				{"status": "completed", "conclusion": "success"},
+				{"status": "completed", "conclusion": "cancelled"},
+				{"status": "completed", "conclusion": "timed_out"},
+				{"status": "completed", "conclusion": "failure"},
+				{"status": "in_progress", "conclusion": null}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	oldURL := githubAPIBaseURL
+	githubAPIBaseURL = server.URL
+	defer func() { githubAPIBaseURL = oldURL }()
+
+	dir := t.TempDir()
+	workflow := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ci.yml"), []byte(workflow), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.yml"), []byte(workflow), 0644))
+
+	params := &mcp.CallToolParamsFor[WorkflowFlakinessParams]{
+		Arguments: WorkflowFlakinessParams{Repository: "owner/repo", Directory: dir, Runs: 5},
+	}
+	t.Setenv("GITHUB_TOKEN", "")
+	useSettings(t, serverConfig{}, false)
+	_, err := WorkflowFlakiness(context.Background(), nil, params)
+	assert.ErrorContains(t, err, "needs a GitHub token")
+
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	useSettings(t, serverConfig{}, false)
+	result, err := WorkflowFlakiness(context.Background(), nil, params)
+	require.NoError(t, err)
+
+	var reports struct {
+		Items []flakinessReport `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &reports))
+	require.Len(t, reports.Items, 2)
+
+	assert.Equal(t, filepath.Join(dir, "ci.yml"), reports.Items[0].FilePath)
+	assert.Equal(t, 4, reports.Items[0].History.Runs)
+	assert.InDelta(t, 0.75, reports.Items[0].FailureRate, 1e-9)
+	require.NotEmpty(t, reports.Items[0].Hints)
+	assert.Equal(t, "high", reports.Items[0].Hints[0].Priority)
+
+	assert.Contains(t, reports.Items[1].Error, "404")
+
+	_, err = WorkflowFlakiness(context.Background(), nil, &mcp.CallToolParamsFor[WorkflowFlakinessParams]{
+		Arguments: WorkflowFlakinessParams{Repository: "repo", Directory: dir},
+	})
+	assert.ErrorContains(t, err, "owner/name")
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+)
+
+const scanOrgUsage = "Usage: actionlint-mcp scan-org [-concurrency n] [-requests-per-second n] [-max-wait duration] [-include-archived] [-format text|json] [-sarif-dir dir] [-config file] <organization>"
+
+type scanOrgOptions struct {
+	Concurrency       int
+	RequestsPerSecond float64
+	MaxWait           time.Duration
+	IncludeArchived   bool
+	Format            string
+	SARIFDir          string
+	ConfigFile        string
+}
+
+func newScanOrgFlagSet(opts *scanOrgOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet("scan-org", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), scanOrgUsage)
+		fs.PrintDefaults()
+	}
+
+	fs.IntVar(&opts.Concurrency, "concurrency", defaultAuditConcurrency, fmt.Sprintf("How many repositories to scan at once, at most %d", maxAuditConcurrency))
+	fs.Float64Var(&opts.RequestsPerSecond, "requests-per-second", 0, "Most GitHub API requests to send per second (0 sends them as fast as the rate limit allows)")
+	fs.DurationVar(&opts.MaxWait, "max-wait", 15*time.Minute, "Longest wait for GitHub's rate limit to reset before failing the request")
+	fs.BoolVar(&opts.IncludeArchived, "include-archived", false, "Scan archived repositories too")
+	fs.StringVar(&opts.Format, "format", "text", "Output format: text, one line per repository, or json")
+	fs.Var((*pathFlag)(&opts.SARIFDir), "sarif-dir", "Also write the findings of each repository to <dir>/<repository>.sarif")
+	fs.Var((*pathFlag)(&opts.ConfigFile), "config", "Read rule settings from this YAML configuration file")
+	return fs
+}
+
+func runScanOrg(args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return scanOrgCommand(ctx, args, os.Stdout)
+}
+
+// scanOrgCommand lists the repositories of the organization in args,
+// fetches their .github/workflows directories with GITHUB_TOKEN, lints
+// them and writes the audit_repositories report to out. Repositories that
+// cannot be read are reported without failing the scan.
+func scanOrgCommand(ctx context.Context, args []string, out io.Writer) error {
+	var opts scanOrgOptions
+	fs := newScanOrgFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &exitError{code: exitLintError, err: err}
+	}
+	if fs.NArg() != 1 {
+		return &exitError{code: exitLintError, err: errors.New(scanOrgUsage)}
+	}
+	switch {
+	case opts.Concurrency < 1 || opts.Concurrency > maxAuditConcurrency:
+		return &exitError{code: exitLintError, err: fmt.Errorf("concurrency must be between 1 and %d", maxAuditConcurrency)}
+	case opts.RequestsPerSecond < 0:
+		return &exitError{code: exitLintError, err: fmt.Errorf("requests-per-second must not be negative")}
+	case opts.Format != "text" && opts.Format != formatJSON:
+		return &exitError{code: exitLintError, err: fmt.Errorf("unknown format %q; use text or %s", opts.Format, formatJSON)}
+	}
+
+	cfg, err := loadServerConfig(opts.ConfigFile)
+	if err != nil {
+		return &exitError{code: exitLintError, err: err}
+	}
+	s, err := newSettings(cfg, false)
+	if err != nil {
+		return &exitError{code: exitLintError, err: err}
+	}
+	ctx = withSettings(ctx, s)
+
+	transport := &rateLimitedTransport{base: http.DefaultTransport, maxWait: opts.MaxWait}
+	if opts.RequestsPerSecond > 0 {
+		transport.interval = time.Duration(float64(time.Second) / opts.RequestsPerSecond)
+	}
+	client := &http.Client{Transport: transport}
+
+	org := fs.Arg(0)
+	repos, err := listOrganizationRepositories(ctx, client, githubAPIBaseURL, org, opts.IncludeArchived)
+	if err != nil {
+		return &exitError{code: exitLintError, err: err}
+	}
+	targets := make([]auditTarget, len(repos))
+	for i, r := range repos {
+		targets[i] = auditTarget{repo: r}
+	}
+
+	report := auditRepositories(ctx, linter.New(s.lint), client, targets, opts.Concurrency, opts.Format == formatJSON)
+	if err := ctx.Err(); err != nil {
+		return &exitError{code: exitLintError, err: err}
+	}
+	if opts.SARIFDir != "" {
+		if err := writeSARIFReports(opts.SARIFDir, report); err != nil {
+			return &exitError{code: exitLintError, err: err}
+		}
+	}
+	if err := writeScanReport(out, report, opts.Format); err != nil {
+		return &exitError{code: exitLintError, err: err}
+	}
+	return nil
+}
+
+// writeSARIFReports writes a SARIF log of each repository of report that
+// could be read to dir, named after the repository, for uploading to code
+// scanning. Locations are relative to the repository root.
+func writeSARIFReports(dir string, report auditReport) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	for _, a := range report.Ranking {
+		if a.summary == nil {
+			continue
+		}
+		data, err := json.MarshalIndent(sarifLog(a.summary), "", "  ")
+		if err != nil {
+			return err
+		}
+		file := filepath.Join(dir, path.Base(a.Repository)+".sarif")
+		if err := os.WriteFile(file, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// writeScanReport writes report to out in format: the report itself as
+// json, or a line per repository, most findings first, and the totals.
+func writeScanReport(out io.Writer, report auditReport, format string) error {
+	if format == formatJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	var b strings.Builder
+	for _, a := range report.Ranking {
+		switch {
+		case a.Error != "":
+			fmt.Fprintf(&b, "%s: failed: %s\n", a.Repository, a.Error)
+		case a.TotalErrors == 0:
+			fmt.Fprintf(&b, "%s: no findings in %s\n", a.Repository, plural(a.TotalFiles, "workflow"))
+		default:
+			var counts []string
+			for _, s := range severityOrder {
+				if n := a.Severities[s]; n > 0 {
+					counts = append(counts, fmt.Sprintf("%d %s", n, s))
+				}
+			}
+			fmt.Fprintf(&b, "%s: %s in %d of %s (%s)\n", a.Repository, plural(a.TotalErrors, "finding"), a.FilesWithErrors, plural(a.TotalFiles, "workflow"), strings.Join(counts, ", "))
+		}
+	}
+	fmt.Fprintf(&b, "%s, %d with findings, %s in %s\n", plural(report.Repositories, "repository"), report.RepositoriesWithFindings, plural(report.TotalErrors, "finding"), plural(report.TotalFiles, "workflow"))
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// plural returns n and noun, in the plural unless n is one.
+func plural(n int, noun string) string {
+	switch {
+	case n == 1:
+		return "1 " + noun
+	case strings.HasSuffix(noun, "y"):
+		return fmt.Sprintf("%d %sies", n, strings.TrimSuffix(noun, "y"))
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
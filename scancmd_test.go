@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanOrgCommand(t *testing.T) {
+	var limited atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orgs/acme/repos":
+			// The first listing hits the rate limit
+			if limited.CompareAndSwap(false, true) {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_, _ = w.Write([]byte(`[{"full_name": "acme/api"}, {"full_name": "acme/web"}]`))
+		case "/repos/acme/web/contents/.github/workflows":
+			_, _ = w.Write([]byte(`[{"name": "ci.yml", "path": ".github/workflows/ci.yml", "type": "file"}]`))
+		case "/repos/acme/web/contents/.github/workflows/ci.yml":
+			fmt.Fprintf(w, `{"content": %q, "encoding": "base64"}`, base64.StdEncoding.EncodeToString([]byte(brokenWorkflow)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	oldURL := githubAPIBaseURL
+	githubAPIBaseURL = server.URL
+	defer func() { githubAPIBaseURL = oldURL }()
+
+	var out bytes.Buffer
+	sarifDir := filepath.Join(t.TempDir(), "sarif")
+	require.NoError(t, scanOrgCommand(context.Background(), []string{"-sarif-dir", sarifDir, "acme"}, &out))
+	assert.Equal(t, "acme/web: 1 finding in 1 of 1 workflow (1 error)\nacme/api: no findings in 0 workflows\n2 repositories, 1 with findings, 1 finding in 1 workflow\n", out.String())
+
+	data, err := os.ReadFile(filepath.Join(sarifDir, "web.sarif"))
+	require.NoError(t, err)
+	var sarif sarifDocument
+	require.NoError(t, json.Unmarshal(data, &sarif))
+	require.Len(t, sarif.Runs[0].Results, 1)
+	assert.Equal(t, ".github/workflows/ci.yml", sarif.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.FileExists(t, filepath.Join(sarifDir, "api.sarif"))
+
+	out.Reset()
+	require.NoError(t, scanOrgCommand(context.Background(), []string{"-format", "json", "-requests-per-second", "1000", "acme"}, &out))
+	var report auditReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, 2, report.Repositories)
+	require.Len(t, report.Ranking[0].Results, 1, "json includes the findings")
+
+	for _, args := range [][]string{
+		{},
+		{"acme", "other"},
+		{"-concurrency", "0", "acme"},
+		{"-requests-per-second", "-1", "acme"},
+		{"-format", "sarif", "acme"},
+		{"missing"},
+	} {
+		err := scanOrgCommand(context.Background(), args, &bytes.Buffer{})
+		assert.Equal(t, exitLintError, exitCode(err), "%v", args)
+	}
+}
+
+func TestRateLimitDelay(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	response := func(code int, headers map[string]string) *http.Response {
+		resp := &http.Response{StatusCode: code, Header: http.Header{}}
+		for k, v := range headers {
+			resp.Header.Set(k, v)
+		}
+		return resp
+	}
+
+	tests := []struct {
+		name    string
+		resp    *http.Response
+		delay   time.Duration
+		limited bool
+	}{
+		{"ok", response(http.StatusOK, nil), 0, false},
+		{"forbidden", response(http.StatusForbidden, nil), 0, false},
+		{"retry after", response(http.StatusForbidden, map[string]string{"Retry-After": "30"}), 30 * time.Second, true},
+		{"primary limit", response(http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": strconv.FormatInt(now.Unix()+120, 10)}), 121 * time.Second, true},
+		{"reset passed", response(http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": strconv.FormatInt(now.Unix()-10, 10)}), 0, true},
+		{"secondary limit", response(http.StatusTooManyRequests, nil), secondaryLimitDelay, true},
+	}
+	for _, tt := range tests {
+		delay, limited := rateLimitDelay(tt.resp, now)
+		assert.Equal(t, tt.limited, limited, tt.name)
+		assert.Equal(t, tt.delay, delay, tt.name)
+	}
+}
+
+func TestRateLimitedTransport(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	// A reset further away than max-wait fails the request at once
+	client := &http.Client{Transport: &rateLimitedTransport{base: http.DefaultTransport, maxWait: time.Minute}}
+	_, err := githubGet(context.Background(), client, server.URL)
+	assert.ErrorContains(t, err, "429")
+	assert.Equal(t, int32(1), requests.Load())
+
+	// Requests are spaced by the interval
+	transport := &rateLimitedTransport{interval: 50 * time.Millisecond}
+	assert.Zero(t, transport.reserve())
+	assert.InDelta(t, 50*time.Millisecond, transport.reserve(), float64(10*time.Millisecond))
+}
@@ -0,0 +1,428 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	releaseRepo     = "hongkongkiwi/actionlint-mcp"
+	checksumsAsset  = "checksums.txt"
+	maxAssetSize    = 100 << 20
+	cosignIssuer    = "https://token.actions.githubusercontent.com"
+	cosignIdentity  = "^https://github.com/" + releaseRepo + "/"
+	selfUpdateUsage = "Usage: actionlint-mcp self-update [-check] [-force] [-version vX.Y.Z] [-require-signature]"
+)
+
+type githubRelease struct {
+	TagName string         `json:"tag_name"`
+	Assets  []releaseAsset `json:"assets"`
+}
+
+type releaseAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+type selfUpdateOptions struct {
+	CheckOnly        bool
+	Force            bool
+	Version          string
+	RequireSignature bool
+	Target           string
+}
+
+func newSelfUpdateFlagSet(opts *selfUpdateOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet("self-update", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), selfUpdateUsage)
+		fs.PrintDefaults()
+	}
+
+	fs.BoolVar(&opts.CheckOnly, "check", false, "Only report whether a newer release is available")
+	fs.BoolVar(&opts.Force, "force", false, "Install even if the release is not newer than the running binary")
+	fs.StringVar(&opts.Version, "version", "", "Install a specific release tag instead of the latest")
+	fs.BoolVar(&opts.RequireSignature, "require-signature", false, "Fail if the cosign signature of checksums.txt cannot be verified")
+	return fs
+}
+
+func runSelfUpdate(args []string) error {
+	var opts selfUpdateOptions
+	fs := newSelfUpdateFlagSet(&opts)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate running binary: %w", err)
+	}
+	if opts.Target, err = filepath.EvalSymlinks(exe); err != nil {
+		return fmt.Errorf("failed to resolve running binary: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	return selfUpdate(ctx, http.DefaultClient, opts, os.Stdout)
+}
+
+func selfUpdate(ctx context.Context, client *http.Client, opts selfUpdateOptions, out io.Writer) error {
+	release, err := fetchRelease(ctx, client, opts.Version)
+	if err != nil {
+		return err
+	}
+
+	newer := compareVersions(release.TagName, version) > 0
+	if opts.CheckOnly {
+		if newer {
+			fmt.Fprintf(out, "A newer release is available: %s (running %s)\n", release.TagName, version)
+		} else {
+			fmt.Fprintf(out, "actionlint-mcp %s is up to date (latest %s)\n", version, release.TagName)
+		}
+		return nil
+	}
+	if !newer && !opts.Force && opts.Version == "" {
+		fmt.Fprintf(out, "actionlint-mcp %s is up to date (latest %s)\n", version, release.TagName)
+		return nil
+	}
+
+	name := releaseAssetName(runtime.GOOS, runtime.GOARCH, goarm())
+	archive := findAsset(release, name)
+	if archive == nil {
+		return fmt.Errorf("release %s has no asset %s for this platform", release.TagName, name)
+	}
+	sums := findAsset(release, checksumsAsset)
+	if sums == nil {
+		return fmt.Errorf("release %s has no %s, refusing to install an unverified binary", release.TagName, checksumsAsset)
+	}
+
+	sumsData, err := download(ctx, client, sums.BrowserDownloadURL)
+	if err != nil {
+		return err
+	}
+	if err := verifySignature(ctx, client, release, sumsData, opts.RequireSignature, out); err != nil {
+		return err
+	}
+
+	archiveData, err := download(ctx, client, archive.BrowserDownloadURL)
+	if err != nil {
+		return err
+	}
+	if err := verifyChecksum(sumsData, name, archiveData); err != nil {
+		return err
+	}
+
+	binary, err := extractBinary(name, archiveData)
+	if err != nil {
+		return err
+	}
+	if err := replaceBinary(opts.Target, binary); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Updated %s from %s to %s\n", opts.Target, version, release.TagName)
+	return nil
+}
+
+func fetchRelease(ctx context.Context, client *http.Client, tag string) (*githubRelease, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", githubAPIBaseURL, releaseRepo)
+	if tag != "" {
+		if !strings.HasPrefix(tag, "v") {
+			tag = "v" + tag
+		}
+		url = fmt.Sprintf("%s/repos/%s/releases/tags/%s", githubAPIBaseURL, releaseRepo, tag)
+	}
+
+	data, err := githubGet(ctx, client, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query releases: %w", err)
+	}
+
+	var release githubRelease
+	if err := json.Unmarshal(data, &release); err != nil {
+		return nil, fmt.Errorf("failed to parse release metadata: %w", err)
+	}
+	if release.TagName == "" {
+		return nil, fmt.Errorf("release metadata has no tag name")
+	}
+	return &release, nil
+}
+
+// download returns a release asset, failing when it is larger than
+// maxAssetSize.
+func download(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	return fetch(ctx, client, url, maxAssetSize)
+}
+
+func findAsset(release *githubRelease, name string) *releaseAsset {
+	for i := range release.Assets {
+		if release.Assets[i].Name == name {
+			return &release.Assets[i]
+		}
+	}
+	return nil
+}
+
+// releaseAssetName mirrors the archive name_template in .goreleaser.yml.
+func releaseAssetName(goos, goarch, arm string) string {
+	osName := strings.ToUpper(goos[:1]) + goos[1:]
+
+	arch := goarch
+	switch goarch {
+	case "amd64":
+		arch = "x86_64"
+	case "386":
+		arch = "i386"
+	case "arm":
+		arch = "armv" + arm
+	}
+
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	return fmt.Sprintf("actionlint-mcp_%s_%s%s", osName, arch, ext)
+}
+
+// goarm reports the GOARM level the running binary was built with.
+func goarm() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "GOARM" && s.Value != "" {
+				return strings.TrimSuffix(strings.TrimSuffix(s.Value, ",softfloat"), ",hardfloat")
+			}
+		}
+	}
+	return "6"
+}
+
+func verifyChecksum(sums []byte, name string, data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(fields[0], hex.EncodeToString(sum[:])) {
+			return fmt.Errorf("checksum mismatch for %s", name)
+		}
+		return nil
+	}
+	return fmt.Errorf("no checksum listed for %s", name)
+}
+
+// verifySignature checks the cosign keyless signature of checksums.txt when
+// cosign is installed. The signature is optional unless required is set.
+func verifySignature(ctx context.Context, client *http.Client, release *githubRelease, sums []byte, required bool, out io.Writer) error {
+	sig := findAsset(release, checksumsAsset+".sig")
+	cert := findAsset(release, checksumsAsset+".pem")
+	cosign, lookErr := exec.LookPath("cosign")
+
+	switch {
+	case sig == nil || cert == nil:
+		if required {
+			return fmt.Errorf("release %s is not signed", release.TagName)
+		}
+		fmt.Fprintln(out, "warning: release is not signed, relying on checksums only")
+		return nil
+	case lookErr != nil:
+		if required {
+			return fmt.Errorf("cosign is required to verify the release signature: %w", lookErr)
+		}
+		fmt.Fprintln(out, "warning: cosign not found, skipping signature verification")
+		return nil
+	}
+
+	dir, err := os.MkdirTemp("", "actionlint-mcp-update-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string][]byte{checksumsAsset: sums}
+	for _, asset := range []*releaseAsset{sig, cert} {
+		data, err := download(ctx, client, asset.BrowserDownloadURL)
+		if err != nil {
+			return err
+		}
+		files[asset.Name] = data
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			return err
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, cosign, "verify-blob",
+		"--certificate", filepath.Join(dir, cert.Name),
+		"--signature", filepath.Join(dir, sig.Name),
+		"--certificate-identity-regexp", cosignIdentity,
+		"--certificate-oidc-issuer", cosignIssuer,
+		filepath.Join(dir, checksumsAsset))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("signature verification failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func extractBinary(name string, data []byte) ([]byte, error) {
+	if strings.HasSuffix(name, ".zip") {
+		return extractFromZip(data)
+	}
+	return extractFromTarGz(data)
+}
+
+func isBinaryEntry(name string) bool {
+	base := filepath.Base(name)
+	return base == "actionlint-mcp" || base == "actionlint-mcp.exe"
+}
+
+func extractFromTarGz(data []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeReg && isBinaryEntry(hdr.Name) {
+			return io.ReadAll(io.LimitReader(tr, maxAssetSize))
+		}
+	}
+	return nil, fmt.Errorf("archive does not contain the actionlint-mcp binary")
+}
+
+func extractFromZip(data []byte) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	for _, f := range zr.File {
+		if !isBinaryEntry(f.Name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(io.LimitReader(rc, maxAssetSize))
+	}
+	return nil, fmt.Errorf("archive does not contain the actionlint-mcp binary")
+}
+
+// replaceBinary atomically swaps target for the new binary. The new file is
+// written next to the target so the final rename never crosses filesystems.
+func replaceBinary(target string, binary []byte) error {
+	mode := os.FileMode(0o755)
+	if info, err := os.Stat(target); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(target)
+	tmp, err := os.CreateTemp(dir, ".actionlint-mcp-*.new")
+	if err != nil {
+		return fmt.Errorf("failed to stage update: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to stage update: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to stage update: %w", err)
+	}
+	if err := os.Chmod(tmpName, mode); err != nil {
+		return fmt.Errorf("failed to stage update: %w", err)
+	}
+
+	// Windows refuses to overwrite a running executable but allows renaming it.
+	if runtime.GOOS == "windows" {
+		old := target + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(target, old); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to move current binary aside: %w", err)
+		}
+	}
+
+	if err := os.Rename(tmpName, target); err != nil {
+		return fmt.Errorf("failed to install update: %w", err)
+	}
+	return nil
+}
+
+// compareVersions compares two dotted versions with an optional "v" prefix.
+// Unparseable versions such as "dev" sort before any release.
+func compareVersions(a, b string) int {
+	pa, okA := parseVersion(a)
+	pb, okB := parseVersion(b)
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] > pb[i] {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
+
+func parseVersion(v string) ([3]int, bool) {
+	var parts [3]int
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	fields := strings.Split(v, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return parts, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTarGz(t *testing.T, name string, content []byte) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "README.md", Mode: 0644, Size: 2, Typeflag: tar.TypeReg}))
+	_, err := tw.Write([]byte("hi"))
+	require.NoError(t, err)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+	_, err = tw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestReleaseAssetName(t *testing.T) {
+	testCases := []struct {
+		goos, goarch, arm string
+		expected          string
+	}{
+		{"linux", "amd64", "", "actionlint-mcp_Linux_x86_64.tar.gz"},
+		{"darwin", "arm64", "", "actionlint-mcp_Darwin_arm64.tar.gz"},
+		{"windows", "386", "", "actionlint-mcp_Windows_i386.zip"},
+		{"linux", "arm", "7", "actionlint-mcp_Linux_armv7.tar.gz"},
+		{"freebsd", "amd64", "", "actionlint-mcp_Freebsd_x86_64.tar.gz"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, releaseAssetName(tc.goos, tc.goarch, tc.arm))
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, 1, compareVersions("v1.2.0", "1.1.9"))
+	assert.Equal(t, 0, compareVersions("v1.2.0", "1.2.0"))
+	assert.Equal(t, -1, compareVersions("v1.2.0", "v1.10.0"))
+	assert.Equal(t, 1, compareVersions("v0.1.0", "dev"))
+	assert.Equal(t, 0, compareVersions("v1.2.3-rc1", "1.2.3"))
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	data := []byte("binary")
+	sum := sha256.Sum256(data)
+	sums := []byte(hex.EncodeToString(sum[:]) + "  archive.tar.gz\n")
+
+	assert.NoError(t, verifyChecksum(sums, "archive.tar.gz", data))
+
+	err := verifyChecksum(sums, "archive.tar.gz", []byte("tampered"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+
+	err = verifyChecksum(sums, "other.tar.gz", data)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no checksum listed")
+}
+
+func TestExtractBinary(t *testing.T) {
+	t.Run("tar_gz", func(t *testing.T) {
+		archive := makeTarGz(t, "actionlint-mcp", []byte("new-binary"))
+		binary, err := extractBinary("actionlint-mcp_Linux_x86_64.tar.gz", archive)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("new-binary"), binary)
+	})
+
+	t.Run("zip", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		w, err := zw.Create("actionlint-mcp.exe")
+		require.NoError(t, err)
+		_, err = w.Write([]byte("win-binary"))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+
+		binary, err := extractBinary("actionlint-mcp_Windows_x86_64.zip", buf.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, []byte("win-binary"), binary)
+	})
+
+	t.Run("missing_binary", func(t *testing.T) {
+		archive := makeTarGz(t, "something-else", []byte("x"))
+		_, err := extractBinary("actionlint-mcp_Linux_x86_64.tar.gz", archive)
+		assert.Error(t, err)
+	})
+}
+
+func TestSelfUpdate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("archive layout differs on windows")
+	}
+	t.Setenv("PATH", "")
+
+	assetName := releaseAssetName(runtime.GOOS, runtime.GOARCH, goarm())
+	archive := makeTarGz(t, "actionlint-mcp", []byte("updated-binary"))
+	sum := sha256.Sum256(archive)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/" + releaseRepo + "/releases/latest":
+			_ = json.NewEncoder(w).Encode(githubRelease{
+				TagName: "v99.0.0",
+				Assets: []releaseAsset{
+					{Name: assetName, BrowserDownloadURL: server.URL + "/download/" + assetName},
+					{Name: checksumsAsset, BrowserDownloadURL: server.URL + "/download/" + checksumsAsset},
+				},
+			})
+		case "/download/" + assetName:
+			_, _ = w.Write(archive)
+		case "/download/" + checksumsAsset:
+			fmt.Fprintf(w, "%s  %s\n", hex.EncodeToString(sum[:]), assetName)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	oldURL := githubAPIBaseURL
+	githubAPIBaseURL = server.URL
+	defer func() { githubAPIBaseURL = oldURL }()
+
+	oldVersion := version
+	version = "1.0.0"
+	defer func() { version = oldVersion }()
+
+	target := filepath.Join(t.TempDir(), "actionlint-mcp")
+	require.NoError(t, os.WriteFile(target, []byte("old-binary"), 0750))
+
+	t.Run("check_only", func(t *testing.T) {
+		var out bytes.Buffer
+		err := selfUpdate(context.Background(), server.Client(), selfUpdateOptions{CheckOnly: true, Target: target}, &out)
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "v99.0.0")
+
+		data, err := os.ReadFile(target)
+		require.NoError(t, err)
+		assert.Equal(t, "old-binary", string(data))
+	})
+
+	t.Run("install", func(t *testing.T) {
+		var out bytes.Buffer
+		err := selfUpdate(context.Background(), server.Client(), selfUpdateOptions{Target: target}, &out)
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "Updated")
+
+		data, err := os.ReadFile(target)
+		require.NoError(t, err)
+		assert.Equal(t, "updated-binary", string(data))
+
+		info, err := os.Stat(target)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0750), info.Mode().Perm())
+	})
+
+	t.Run("require_signature", func(t *testing.T) {
+		var out bytes.Buffer
+		err := selfUpdate(context.Background(), server.Client(), selfUpdateOptions{Force: true, RequireSignature: true, Target: target}, &out)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not signed")
+	})
+}
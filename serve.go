@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sdListenFDsStart is the first file descriptor passed by systemd socket
+// activation (SD_LISTEN_FDS_START in sd-daemon.h).
+const sdListenFDsStart = 3
+
+// newStdioTransport is swapped out by tests that exercise the stdio path.
+var newStdioTransport = func() mcp.Transport { return mcp.NewStdioTransport() }
+
+// run serves the MCP server until ctx is cancelled. A systemd-activated
+// socket takes precedence over -http. Stdio is served when requested with
+// -stdio or when no network transport is configured.
+func run(ctx context.Context, server *mcp.Server, opts serverOptions) error {
+	if opts.PidFile != "" {
+		if err := writePidFile(opts.PidFile); err != nil {
+			return err
+		}
+		defer os.Remove(opts.PidFile)
+	}
+
+	ln, err := activationListener()
+	if err != nil {
+		return err
+	}
+	if ln == nil && opts.HTTPAddr != "" {
+		if ln, err = net.Listen("tcp", opts.HTTPAddr); err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", opts.HTTPAddr, err)
+		}
+	}
+
+	return serveTransports(ctx, server, ln, opts.Stdio || ln == nil)
+}
+
+// serveTransports serves every enabled transport against the same server,
+// so sessions from all of them share one engine. When any transport stops,
+// for example because the stdio client hung up, the others are shut down.
+func serveTransports(ctx context.Context, server *mcp.Server, ln net.Listener, stdio bool) error {
+	var transports []func(context.Context) error
+	if ln != nil {
+		transports = append(transports, func(ctx context.Context) error {
+			return serveHTTP(ctx, server, ln)
+		})
+	}
+	if stdio {
+		transports = append(transports, func(ctx context.Context) error {
+			return server.Run(ctx, newStdioTransport())
+		})
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errc := make(chan error, len(transports))
+	for _, serve := range transports {
+		go func() { errc <- serve(ctx) }()
+	}
+
+	var firstErr error
+	for range transports {
+		err := <-errc
+		cancel()
+		if err != nil && !errors.Is(err, context.Canceled) && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// serveHTTP exposes server over streamable HTTP on ln until ctx is cancelled.
+func serveHTTP(ctx context.Context, server *mcp.Server, ln net.Listener) error {
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+	srv := &http.Server{
+		Handler:           limitRequestSize(handler, maxRequestSize),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+
+	select {
+	case err := <-errc:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// activationFDs reports how many sockets systemd passed to this process, or
+// zero when the process was not socket activated.
+func activationFDs(getenv func(string) string, pid int) int {
+	if getenv("LISTEN_PID") != strconv.Itoa(pid) {
+		return 0
+	}
+	n, err := strconv.Atoi(getenv("LISTEN_FDS"))
+	if err != nil || n < 1 {
+		return 0
+	}
+	return n
+}
+
+// activationListener returns the first socket passed by systemd socket
+// activation, or nil when the process was started normally.
+func activationListener() (net.Listener, error) {
+	n := activationFDs(os.Getenv, os.Getpid())
+	if n == 0 {
+		return nil, nil
+	}
+	if n > 1 {
+		return nil, fmt.Errorf("socket activation passed %d sockets, expected 1", n)
+	}
+
+	// Don't leak the activation environment to child processes.
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	f := os.NewFile(uintptr(sdListenFDsStart), "LISTEN_FD_3")
+	ln, err := net.FileListener(f)
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to use activated socket: %w", err)
+	}
+	return ln, nil
+}
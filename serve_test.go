@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivationFDs(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+
+	assert.Equal(t, 0, activationFDs(env(nil), 42))
+	assert.Equal(t, 1, activationFDs(env(map[string]string{"LISTEN_PID": "42", "LISTEN_FDS": "1"}), 42))
+	assert.Equal(t, 0, activationFDs(env(map[string]string{"LISTEN_PID": "7", "LISTEN_FDS": "1"}), 42))
+	assert.Equal(t, 0, activationFDs(env(map[string]string{"LISTEN_PID": "42", "LISTEN_FDS": "zero"}), 42))
+}
+
+func TestServeHTTP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serveHTTP(ctx, newServer(), ln) }()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, mcp.NewStreamableClientTransport("http://"+ln.Addr().String(), nil))
+	require.NoError(t, err)
+
+	tools, err := session.ListTools(ctx, &mcp.ListToolsParams{})
+	require.NoError(t, err)
+
+	var names []string
+	for _, tool := range tools.Tools {
+		names = append(names, tool.Name)
+	}
+	assert.Contains(t, names, "lint_workflow")
+	assert.Contains(t, names, "check_all_workflows")
+	assert.Contains(t, names, "find_misplaced_workflows")
+	assert.Contains(t, names, "move_misplaced_workflows")
+	assert.Contains(t, names, "extract_script")
+	assert.Contains(t, names, "format_workflow")
+	assert.Contains(t, names, "apply_fixes")
+	assert.Contains(t, names, "lint_patch")
+	assert.Contains(t, names, "dry_run_workflow")
+	assert.Contains(t, names, "simulate_trigger")
+	assert.Contains(t, names, "workflow_flakiness")
+	assert.Contains(t, names, "check_variables")
+	assert.Contains(t, names, "check_labels")
+	assert.Contains(t, names, "provisioning_checklist")
+	assert.Contains(t, names, "lint_from_url")
+	assert.Contains(t, names, "check_template_drift")
+	assert.Contains(t, names, "lint_trends")
+	assert.Contains(t, names, "suggest_permissions")
+	assert.Contains(t, names, "infer_workflow_call")
+	assert.Contains(t, names, "suggest_updates_for_action")
+	assert.Contains(t, names, "extract_composite_action")
+	assert.Contains(t, names, "undo_fixes")
+	assert.Contains(t, names, "find_orphaned_workflows")
+	assert.Contains(t, names, "workflow_coverage")
+	assert.Contains(t, names, "generate_test_fixtures")
+	assert.Contains(t, names, "audit_repositories")
+	assert.Contains(t, names, "sync_action_metadata")
+	assert.Contains(t, names, "list_rules")
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "lint_patch",
+		Arguments: map[string]any{"base": "on: push\n", "patch": "--- a/ci.yml\n+++ b/ci.yml\n@@ -1 +1,2 @@\n on: push\n+jobs: {}\n", "result_format_version": 1},
+	})
+	require.NoError(t, err)
+	require.False(t, res.IsError, "%v", res.Content)
+	assert.EqualValues(t, 1, res.Meta["schema_version"])
+	session.Close()
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
+
+func TestServeTransports_StdioAndHTTP(t *testing.T) {
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	oldStdio := newStdioTransport
+	newStdioTransport = func() mcp.Transport { return serverTransport }
+	defer func() { newStdioTransport = oldStdio }()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	done := make(chan error, 1)
+	go func() { done <- serveTransports(ctx, newServer(), ln, true) }()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+
+	stdioSession, err := client.Connect(ctx, clientTransport)
+	require.NoError(t, err)
+	httpSession, err := client.Connect(ctx, mcp.NewStreamableClientTransport("http://"+ln.Addr().String(), nil))
+	require.NoError(t, err)
+
+	for _, session := range []*mcp.ClientSession{stdioSession, httpSession} {
+		tools, err := session.ListTools(ctx, &mcp.ListToolsParams{})
+		require.NoError(t, err)
+		assert.NotEmpty(t, tools.Tools)
+	}
+	httpSession.Close()
+
+	// Hanging up the stdio client stops the HTTP transport too
+	stdioSession.Close()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("transports did not shut down")
+	}
+}
+
+func TestResolveTransport(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		opts     serverOptions
+		httpAddr string
+		err      string
+	}{
+		{name: "default"},
+		{name: "stdio", opts: serverOptions{Transport: "stdio"}},
+		{name: "http", opts: serverOptions{Transport: "http", Listen: ":8080"}, httpAddr: ":8080"},
+		{name: "http_flag", opts: serverOptions{Transport: "http", HTTPAddr: ":8080"}, httpAddr: ":8080"},
+		{name: "same_address", opts: serverOptions{Transport: "http", HTTPAddr: ":8080", Listen: ":8080"}, httpAddr: ":8080"},
+		{name: "http_without_address", opts: serverOptions{Transport: "http"}, err: "-transport http needs -listen"},
+		{name: "different_addresses", opts: serverOptions{Transport: "http", HTTPAddr: ":8080", Listen: ":9090"}, err: "different addresses"},
+		{name: "stdio_with_listen", opts: serverOptions{Transport: "stdio", Listen: ":8080"}, err: "use -stdio to serve both"},
+		{name: "listen_alone", opts: serverOptions{Listen: ":8080"}, err: "-listen needs -transport http"},
+		{name: "unknown", opts: serverOptions{Transport: "sse"}, err: `unknown -transport "sse"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.opts.resolveTransport()
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.httpAddr, tc.opts.HTTPAddr)
+		})
+	}
+}
@@ -0,0 +1,886 @@
+package main
+
+import (
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// newServer creates the MCP server and registers all tools and resources
+// on it.
+func newServer() *mcp.Server {
+	// Create the server
+	server := mcp.NewServer(&mcp.Implementation{
+		Name:    "actionlint-mcp",
+		Version: version,
+	}, &mcp.ServerOptions{InitializedHandler: watchSession})
+
+	// Register the lint_workflow tool
+	lintSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"file_path": {
+				Type:        "string",
+				Description: "Path to the workflow file to lint",
+			},
+			"content": {
+				Type:        "string",
+				Description: "Content of the workflow file to lint (if file_path is not provided)",
+			},
+			"filename": {
+				Type:        "string",
+				Description: "Path the content will be saved to, used in results and to find the repository's actionlint config",
+			},
+			"scope": {
+				Type:        "string",
+				Description: "Only report findings in this job, given by its ID, or in one of its steps, given as job/step with the step's id or position counting from 1",
+			},
+			"skip_ref_filters": {
+				Type:        "boolean",
+				Description: "Skip checking branches and tags filters against the refs of the git repository, as for a template repository",
+			},
+			"compare_to": {
+				Type:        "string",
+				Description: "Only report findings that are not in this baseline: a git ref such as origin/main, at which the file is linted, or the path of a saved lint_workflow or check_all_workflows result",
+			},
+			"output_path": {
+				Type:        "string",
+				Description: "Also write the results to this file, as a CI artifact; needs the server to run with -allow-writes",
+			},
+			"output_format": {
+				Type:        "string",
+				Description: "Format of the output_path file: json (default, the tool's output), sarif for code scanning or markdown for a job summary or comment",
+				Enum:        []any{formatJSON, outputSARIF, outputMarkdown},
+			},
+			"fail_on":               failOnSchema(),
+			"result_format_version": resultFormatVersionSchema(),
+			"max_bytes":             maxBytesSchema(),
+			"max_response_tokens":   maxResponseTokensSchema(),
+			"packs":                 packsSchema(),
+		},
+		OneOf: []*jsonschema.Schema{
+			{Required: []string{"file_path"}},
+			{Required: []string{"content"}},
+		},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "lint_workflow",
+		Description: "Lint a GitHub Actions workflow file using actionlint",
+		InputSchema: lintSchema,
+	}, LintWorkflow)
+
+	// Register the check_all_workflows tool
+	checkSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"directory": {
+				Type:        "string",
+				Description: "Directory to search for workflow files (defaults to .github/workflows)",
+			},
+			"results_as_map": {
+				Type:        "boolean",
+				Description: "Return results as an object keyed by file path instead of a sorted array (legacy format)",
+			},
+			"digest": {
+				Type:        "boolean",
+				Description: "Return only the totals, the rules reported most and a finding of each of the most severe rules instead of the results of every file",
+			},
+			"digest_findings": {
+				Type:        "integer",
+				Description: "Most findings the digest shows (defaults to 5, at most 50)",
+			},
+			"format": {
+				Type:        "string",
+				Description: "Output format: json (default), slack_blocks for a Slack message or teams_card for a Teams Adaptive Card",
+				Enum:        []any{formatJSON, formatSlackBlocks, formatTeamsCard},
+			},
+			"skip_ref_filters": {
+				Type:        "boolean",
+				Description: "Skip checking branches and tags filters against the refs of the git repository, as for a template repository",
+			},
+			"compare_to": {
+				Type:        "string",
+				Description: "Only report findings that are not in this baseline: a git ref such as origin/main, at which the files are linted, or the path of a saved check_all_workflows result",
+			},
+			"output_path": {
+				Type:        "string",
+				Description: "Also write the results to this file, as a CI artifact; needs the server to run with -allow-writes",
+			},
+			"output_format": {
+				Type:        "string",
+				Description: "Format of the output_path file: json (default, the tool's output), sarif for code scanning or markdown for a job summary or comment",
+				Enum:        []any{formatJSON, outputSARIF, outputMarkdown},
+			},
+			"fail_on":               failOnSchema(),
+			"result_format_version": resultFormatVersionSchema(),
+			"max_bytes":             maxBytesSchema(),
+			"max_response_tokens":   maxResponseTokensSchema(),
+			"packs":                 packsSchema(),
+		},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "check_all_workflows",
+		Description: "Check all GitHub Actions workflow files in a directory",
+		InputSchema: checkSchema,
+	}, CheckAllWorkflows)
+
+	// Register the list of rules and rule packs
+	listRulesSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"directory": {
+				Type:        "string",
+				Description: "Root of the repository whose " + linter.RepositoryConfigFile + " turns on further packs (defaults to the current directory)",
+			},
+			"packs": packsSchema(),
+		},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "list_rules",
+		Description: "List the rules, the severity of their findings and the rule packs, with whether each runs given the configuration, the repository and the packs asked for",
+		InputSchema: listRulesSchema,
+	}, ListRules)
+
+	// Register the misplaced workflow check and its fixer
+	misplacedSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"directory": {
+				Type:        "string",
+				Description: "Repository root to search (defaults to the current directory)",
+			},
+		},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "find_misplaced_workflows",
+		Description: "Find workflow files outside .github/workflows that GitHub will never run",
+		InputSchema: misplacedSchema,
+	}, FindMisplacedWorkflows)
+
+	moveSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"directory": {
+				Type:        "string",
+				Description: "Repository root to search (defaults to the current directory)",
+			},
+			"paths": {
+				Type:        "array",
+				Items:       &jsonschema.Schema{Type: "string"},
+				Description: "Misplaced workflow files to move (defaults to all that are found)",
+			},
+			"dry_run": {
+				Type:        "boolean",
+				Description: "Report the moves without making them, which is always the case unless the server runs with -allow-writes",
+			},
+		},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "move_misplaced_workflows",
+		Description: "Move misplaced workflow files into .github/workflows, never overwriting existing files",
+		InputSchema: moveSchema,
+	}, MoveMisplacedWorkflows)
+
+	// Register the incremental linter for patches
+	patchSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"file_path": {
+				Type:        "string",
+				Description: "Path to the workflow file the patch applies to; a missing file is treated as empty",
+			},
+			"base": {
+				Type:        "string",
+				Description: "Content the patch applies to (if file_path is not provided)",
+			},
+			"filename": {
+				Type:        "string",
+				Description: "Path the base content is saved at, used in results and to find the repository's actionlint config",
+			},
+			"patch": {
+				Type:        "string",
+				Description: "Unified diff of the workflow file",
+			},
+			"result_format_version": resultFormatVersionSchema(),
+			"max_bytes":             maxBytesSchema(),
+			"max_response_tokens":   maxResponseTokensSchema(),
+		},
+		Required: []string{"patch"},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "lint_patch",
+		Description: "Apply a unified diff to a workflow in memory, lint the result and report only findings on changed lines, with the write permissions, privileged triggers and unpinned actions the diff adds",
+		InputSchema: patchSchema,
+	}, LintPatch)
+
+	// Register the act integration
+	dryRunSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"file_path": {
+				Type:        "string",
+				Description: "Path to the workflow file to run",
+			},
+			"event": {
+				Type:        "string",
+				Description: "Event to simulate (defaults to push)",
+			},
+			"payload": {
+				Type:        "string",
+				Description: "JSON event payload passed to act",
+			},
+			"run_act": {
+				Type:        "boolean",
+				Description: "Run the jobs with act, in its dry-run mode (-n), instead of only listing them; needs a container runtime",
+			},
+			"result_format_version": resultFormatVersionSchema(),
+			"max_bytes":             maxBytesSchema(),
+			"max_response_tokens":   maxResponseTokensSchema(),
+		},
+		Required: []string{"file_path"},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "dry_run_workflow",
+		Description: "Check with nektos/act that a workflow resolves to runnable jobs for an event, merging act's errors into the lint result",
+		InputSchema: dryRunSchema,
+	}, DryRunWorkflow)
+
+	// Register the trigger simulator
+	triggerSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"directory": {
+				Type:        "string",
+				Description: "Directory of the workflow files to check (defaults to .github/workflows)",
+			},
+			"event": {
+				Type:        "string",
+				Description: "Name of the event, such as push or pull_request",
+			},
+			"payload": {
+				Type:        "string",
+				Description: "JSON webhook payload of the event",
+			},
+			"changed_files": {
+				Type:        "array",
+				Items:       &jsonschema.Schema{Type: "string"},
+				Description: "Files changed by the event, for paths filters (defaults to the files of the commits in a push payload)",
+			},
+		},
+		Required: []string{"event"},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "simulate_trigger",
+		Description: "Report which workflows and jobs an event with a sample payload would trigger, and which branch, tag, path or type filters exclude the others",
+		InputSchema: triggerSchema,
+	}, SimulateTrigger)
+
+	// Register the run history analysis
+	flakinessSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"repository": {
+				Type:        "string",
+				Description: "Repository as owner/name (defaults to GITHUB_REPOSITORY)",
+			},
+			"directory": {
+				Type:        "string",
+				Description: "Directory of the workflow files to check (defaults to .github/workflows)",
+			},
+			"runs": {
+				Type:        "integer",
+				Description: "Number of recent completed runs to look at per workflow (defaults to 50, at most 100)",
+			},
+		},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "workflow_flakiness",
+		Description: "Fetch recent run conclusions of each workflow with GITHUB_TOKEN and rank missing timeouts, missing concurrency groups and unpinned actions by how many failed or cancelled runs they can explain",
+		InputSchema: flakinessSchema,
+	}, WorkflowFlakiness)
+
+	// Register the configuration variable check
+	variablesSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"file_path": {
+				Type:        "string",
+				Description: "Path to a single workflow file to check",
+			},
+			"directory": {
+				Type:        "string",
+				Description: "Directory of the workflow files to check (defaults to .github/workflows)",
+			},
+			"repository": {
+				Type:        "string",
+				Description: "Repository as owner/name whose variables are fetched (defaults to GITHUB_REPOSITORY)",
+			},
+			"variables": {
+				Type:        "array",
+				Items:       &jsonschema.Schema{Type: "string"},
+				Description: "Names of the defined configuration variables; when given nothing is fetched",
+			},
+		},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "check_variables",
+		Description: "Flag vars.* references to configuration variables that are not defined, using a given list or the repository and organization variables fetched with GITHUB_TOKEN",
+		InputSchema: variablesSchema,
+	}, CheckVariables)
+
+	// Register the label check
+	labelsSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"file_path": {
+				Type:        "string",
+				Description: "Path to a single workflow file to check",
+			},
+			"directory": {
+				Type:        "string",
+				Description: "Directory of the workflow files to check (defaults to .github/workflows)",
+			},
+			"repository": {
+				Type:        "string",
+				Description: "Repository as owner/name whose labels are fetched (defaults to GITHUB_REPOSITORY)",
+			},
+			"labels": {
+				Type:        "array",
+				Items:       &jsonschema.Schema{Type: "string"},
+				Description: "Names of the labels of the repository; when given nothing is fetched",
+			},
+		},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "check_labels",
+		Description: "Flag label names in label conditions and gh pr or gh issue label flags that the repository does not have, using a given list or the labels fetched with GITHUB_TOKEN",
+		InputSchema: labelsSchema,
+	}, CheckLabels)
+
+	// Register the secrets and variables provisioning checklist
+	provisioningSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"file_path": {
+				Type:        "string",
+				Description: "Path to a single workflow file to summarize",
+			},
+			"directory": {
+				Type:        "string",
+				Description: "Directory of the workflow files to summarize (defaults to .github/workflows)",
+			},
+			"compare": {
+				Type:        "boolean",
+				Description: "Compare the checklist with the secrets and variables the repository, its organization and its environments define, fetched with GITHUB_TOKEN",
+			},
+			"repository": {
+				Type:        "string",
+				Description: "Repository as owner/name to compare with (defaults to GITHUB_REPOSITORY)",
+			},
+		},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "provisioning_checklist",
+		Description: "Summarize per workflow the secrets and configuration variables it reads and the scope to provision each at (repository, environment, or the caller of a reusable workflow), as a checklist that can be compared with the repository's settings",
+		InputSchema: provisioningSchema,
+	}, ProvisioningChecklist)
+
+	// Register linting of workflows fetched from GitHub
+	urlSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"url": {
+				Type:        "string",
+				Description: "https URL of the workflow on raw.githubusercontent.com, github.com (a blob page) or a gist",
+			},
+			"result_format_version": resultFormatVersionSchema(),
+			"max_bytes":             maxBytesSchema(),
+			"max_response_tokens":   maxResponseTokensSchema(),
+		},
+		Required: []string{"url"},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "lint_from_url",
+		Description: "Fetch a workflow from raw.githubusercontent.com, a github.com file page or a gist and lint it, for workflows that are not in the local checkout",
+		InputSchema: urlSchema,
+	}, LintFromURL)
+
+	// Register the golden template comparison
+	driftSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"directory": {
+				Type:        "string",
+				Description: "Directory of the workflow files to compare (defaults to .github/workflows)",
+			},
+			"templates": {
+				Type:        "string",
+				Description: "Local directory of the golden templates (defaults to the templates in the config file)",
+			},
+			"repository": {
+				Type:        "string",
+				Description: "Repository as owner/name whose workflow-templates directory holds the golden templates",
+			},
+			"ref": {
+				Type:        "string",
+				Description: "Branch, tag or commit of the template repository",
+			},
+		},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "check_template_drift",
+		Description: "Compare workflows with the golden templates of the same name and report semantic deviations: removed triggers, jobs and security steps, widened permissions, and actions no longer pinned",
+		InputSchema: driftSchema,
+	}, CheckTemplateDrift)
+
+	// Register the lint history
+	trendsSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"directory": {
+				Type:        "string",
+				Description: "Directory of the workflow files whose history is returned (defaults to .github/workflows)",
+			},
+			"rule": {
+				Type:        "string",
+				Description: "Only count the findings of this rule, by kind",
+			},
+			"days": {
+				Type:        "integer",
+				Description: "Number of days of history to return (defaults to 90)",
+			},
+		},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "lint_trends",
+		Description: "Return the findings per rule of past check_all_workflows runs as a time series, to show lint debt over time; needs history.path in the config file",
+		InputSchema: trendsSchema,
+	}, LintTrends)
+
+	// Register the permissions suggester
+	permissionsSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"file_path": {
+				Type:        "string",
+				Description: "Path to the workflow file",
+			},
+			"content": {
+				Type:        "string",
+				Description: "Content of the workflow (if file_path is not provided)",
+			},
+		},
+		OneOf: []*jsonschema.Schema{
+			{Required: []string{"file_path"}},
+			{Required: []string{"content"}},
+		},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "suggest_permissions",
+		Description: "Infer the minimal GITHUB_TOKEN permissions block of each job from the actions it uses and the commands it runs, and compare it with the job's current permissions",
+		InputSchema: permissionsSchema,
+	}, SuggestPermissions)
+
+	// Register the reusable workflow generator
+	workflowCallSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"file_path": {
+				Type:        "string",
+				Description: "Path to the workflow file",
+			},
+			"content": {
+				Type:        "string",
+				Description: "Content of the workflow (if file_path is not provided)",
+			},
+			"job": {
+				Type:        "string",
+				Description: "ID of the job to turn into a reusable workflow",
+			},
+		},
+		Required: []string{"job"},
+		OneOf: []*jsonschema.Schema{
+			{Required: []string{"file_path"}},
+			{Required: []string{"content"}},
+		},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "infer_workflow_call",
+		Description: "Turn a job into a reusable workflow: infer the workflow_call inputs, secrets and outputs, with types and defaults, from the expressions the job references, and generate the reusable workflow and the job calling it",
+		InputSchema: workflowCallSchema,
+	}, InferWorkflowCall)
+
+	// Register the action update suggester
+	actionUpdatesSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"action": {
+				Type:        "string",
+				Description: "Action as owner/repo or owner/repo/path, such as actions/setup-node",
+			},
+			"directory": {
+				Type:        "string",
+				Description: "Directory of the workflow files whose usages are compared (defaults to .github/workflows)",
+			},
+		},
+		Required: []string{"action"},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "suggest_updates_for_action",
+		Description: "Return the inputs schema of the latest version of an action and, for each step using it, the inputs it passes that the latest version no longer takes and the inputs newer versions add",
+		InputSchema: actionUpdatesSchema,
+	}, SuggestUpdatesForAction)
+
+	// Register the formatter
+	formatSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"file_path": {
+				Type:        "string",
+				Description: "Path to the workflow file to format",
+			},
+			"content": {
+				Type:        "string",
+				Description: "Content of the workflow to format (if file_path is not provided)",
+			},
+			"write": {
+				Type:        "boolean",
+				Description: "Write the formatted workflow back to file_path, when the server runs with -allow-writes",
+			},
+			"dry_run": {
+				Type:        "boolean",
+				Description: "Return the diff without writing it, even with write",
+			},
+		},
+		OneOf: []*jsonschema.Schema{
+			{Required: []string{"file_path"}},
+			{Required: []string{"content"}},
+		},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "format_workflow",
+		Description: "Format a workflow as canonical YAML, keeping comments, and return the diff",
+		InputSchema: formatSchema,
+	}, FormatWorkflow)
+
+	// Register the fixer for findings that carry a fix
+	fixSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"file_path": {
+				Type:        "string",
+				Description: "Path to the workflow file to fix",
+			},
+			"fixes": {
+				Type:        "array",
+				Items:       &jsonschema.Schema{Type: "string"},
+				Description: "IDs of the fixes to apply, as reported by lint_workflow (defaults to all)",
+			},
+			"dry_run": {
+				Type:        "boolean",
+				Description: "Return the diff without writing it, which is always the case unless the server runs with -allow-writes",
+			},
+		},
+		Required: []string{"file_path"},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "apply_fixes",
+		Description: "Apply the fixes offered for a workflow's findings and return the diff",
+		InputSchema: fixSchema,
+	}, ApplyFixes)
+
+	// Register the fixer for long-script findings
+	extractSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"file_path": {
+				Type:        "string",
+				Description: "Path to the workflow file",
+			},
+			"job": {
+				Type:        "string",
+				Description: "ID of the job containing the step",
+			},
+			"step": {
+				Type:        "integer",
+				Description: "Position of the step in the job, counting from 1 as in long-script findings",
+			},
+			"dry_run": {
+				Type:        "boolean",
+				Description: "Return the changes without writing them, which is always the case unless the server runs with -allow-writes",
+			},
+		},
+		Required: []string{"file_path", "job", "step"},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "extract_script",
+		Description: "Move a step's run: script into scripts/<workflow>-<job>-<step>.sh and make the step invoke it",
+		InputSchema: extractSchema,
+	}, ExtractScript)
+
+	// Register the composite action extractor
+	compositeSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"file_path": {
+				Type:        "string",
+				Description: "Path to the workflow file in .github/workflows",
+			},
+			"job": {
+				Type:        "string",
+				Description: "ID of the job containing the steps",
+			},
+			"first_step": {
+				Type:        "integer",
+				Description: "Position of the first step to extract, counting from 1",
+			},
+			"last_step": {
+				Type:        "integer",
+				Description: "Position of the last step to extract (defaults to first_step)",
+			},
+			"name": {
+				Type:        "string",
+				Description: "Name of the action, which is written to .github/actions/<name>/action.yml",
+			},
+			"description": {
+				Type:        "string",
+				Description: "Description of the action",
+			},
+			"write": {
+				Type:        "boolean",
+				Description: "Write the action and the rewritten workflows, when the server runs with -allow-writes",
+			},
+			"dry_run": {
+				Type:        "boolean",
+				Description: "Return the changes without writing them, even with write",
+			},
+		},
+		Required: []string{"file_path", "job", "first_step", "name"},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "extract_composite_action",
+		Description: "Move duplicated steps into a composite action under .github/actions/<name>, replace every identical run of the steps in the repository's workflows with a step using it, and lint the result; returns the changes as diffs",
+		InputSchema: compositeSchema,
+	}, ExtractCompositeAction)
+
+	// Register the audit of several repositories
+	auditSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"paths": {
+				Type:        "array",
+				Items:       &jsonschema.Schema{Type: "string"},
+				Description: "Local checkouts of the repositories to audit",
+			},
+			"organization": {
+				Type:        "string",
+				Description: "GitHub organization whose repositories are audited, fetching their workflows with GITHUB_TOKEN",
+			},
+			"include_archived": {
+				Type:        "boolean",
+				Description: "Audit the archived repositories of the organization too",
+			},
+			"concurrency": {
+				Type:        "integer",
+				Description: "How many repositories to audit at once (defaults to 4, at most 16)",
+			},
+			"details": {
+				Type:        "boolean",
+				Description: "Include the findings of each file, not only the counts",
+			},
+		},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "audit_repositories",
+		Description: "Lint every workflow of several local repositories, or of the repositories of a GitHub organization, concurrently and rank the repositories by their findings",
+		InputSchema: auditSchema,
+	}, AuditRepositories)
+
+	// Register the action metadata crawler
+	syncActionsSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"organizations": {
+				Type:        "array",
+				Items:       &jsonschema.Schema{Type: "string"},
+				Description: "Organizations whose repositories are crawled for actions (defaults to action-metadata.organizations of the config file)",
+			},
+			"directory": {
+				Type:        "string",
+				Description: "Repository root the metadata file is relative to (defaults to the current directory)",
+			},
+			"file": {
+				Type:        "string",
+				Description: "Metadata file to write (defaults to rules.actions.file of the config file)",
+			},
+			"include_archived": {
+				Type:        "boolean",
+				Description: "Crawl the archived repositories of the organizations too",
+			},
+			"dry_run": {
+				Type:        "boolean",
+				Description: "Return the changes without writing them, which is always the case unless the server runs with -allow-writes",
+			},
+		},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "sync_action_metadata",
+		Description: "Crawl the repositories of organizations, on GitHub or a GitHub Enterprise Server, for the inputs and outputs of their actions and write them to the metadata file the action-metadata rule checks steps against",
+		InputSchema: syncActionsSchema,
+	}, SyncActionMetadata)
+
+	// Register the fixture generator
+	fixturesSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"directory": {
+				Type:        "string",
+				Description: "Directory to create the fixture workflows in",
+			},
+			"kinds": {
+				Type:        "array",
+				Items:       &jsonschema.Schema{Type: "string"},
+				Description: "Kinds of finding to create fixtures for (defaults to all)",
+			},
+			"dry_run": {
+				Type:        "boolean",
+				Description: "Return the changes without writing them, which is always the case unless the server runs with -allow-writes",
+			},
+		},
+		Required: []string{"directory"},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "generate_test_fixtures",
+		Description: "Create intentionally broken workflows, one per kind of finding, in a directory to validate a policy configuration against",
+		InputSchema: fixturesSchema,
+	}, GenerateTestFixtures)
+
+	// Register the undo of the fixer tools' writes
+	addTool(server, &mcp.Tool{
+		Name:        "undo_fixes",
+		Description: "Revert the last file change made in this session by apply_fixes, format_workflow, extract_script, extract_composite_action, move_misplaced_workflows or generate_test_fixtures",
+		InputSchema: &jsonschema.Schema{Type: "object"},
+	}, UndoFixes)
+
+	// Register the search for workflows whose triggers cannot fire
+	orphanSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"directory": {
+				Type:        "string",
+				Description: "Directory of the repository's workflow files (defaults to .github/workflows)",
+			},
+			"repository": {
+				Type:        "string",
+				Description: "Repository as owner/name, whose template status and deployments are read with GITHUB_TOKEN (defaults to GITHUB_REPOSITORY)",
+			},
+		},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "find_orphaned_workflows",
+		Description: "Find workflow triggers that can never fire in the repository, such as branch filters no git branch matches, workflow_run triggers naming no workflow, release events in a template repository and deployment events without deployments",
+		InputSchema: orphanSchema,
+	}, FindOrphanedWorkflows)
+
+	// Register the report of the repository areas no workflow checks
+	coverageSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"directory": {
+				Type:        "string",
+				Description: "Root of the repository (defaults to the current directory)",
+			},
+		},
+	}
+
+	addTool(server, &mcp.Tool{
+		Name:        "workflow_coverage",
+		Description: "Report the areas of a repository its workflows do not check: top-level directories no push or pull_request workflow runs for given their paths filters, languages no workflow builds or tests, and missing pull_request or push triggers",
+		InputSchema: coverageSchema,
+	}, WorkflowCoverage)
+
+	// Register the JSON Schemas of the results and the configuration file
+	addSchemaResources(server)
+
+	// Register the resource holding the last check_all_workflows results
+	addResultsResource(server)
+
+	return server
+}
+
+// resultFormatVersionSchema is the result_format_version parameter of the
+// tools returning lint results.
+func resultFormatVersionSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "integer",
+		Description: "Version of the result format the client expects; the tool fails rather than answer in another (defaults to the current version)",
+	}
+}
+
+// maxBytesSchema is the max_bytes parameter of the tools returning lint
+// results.
+func maxBytesSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "integer",
+		Description: "Most bytes of text to return; a larger result drops the edits of fixes, then shortens messages, then leaves out the least severe findings, keeping counts and totals",
+	}
+}
+
+// maxResponseTokensSchema is the max_response_tokens parameter of the
+// tools returning lint results.
+func maxResponseTokensSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "integer",
+		Description: "Most tokens of text to return, at about 4 bytes a token; the smaller of this and max_bytes applies",
+	}
+}
+
+// failOnSchema is the fail_on parameter of the tools returning lint
+// results.
+func failOnSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "string",
+		Description: "Least severe finding that makes a file invalid: error (which includes critical), warning or info; less severe findings are still reported (defaults to any finding)",
+		Enum:        []any{linter.SeverityError, linter.SeverityWarning, linter.SeverityInfo},
+	}
+}
+
+// packsSchema is the packs parameter of the tools running the rules.
+func packsSchema() *jsonschema.Schema {
+	names := make([]any, len(rules.Packs))
+	for i, p := range rules.Packs {
+		names[i] = p.Name
+	}
+	return &jsonschema.Schema{
+		Type:        "array",
+		Items:       &jsonschema.Schema{Type: "string", Enum: names},
+		Description: "Rule packs to turn on for this call, in addition to those of the configuration and the repository",
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxSessions is how many sessions the stores of the server keep state
+// for. A session over streamable HTTP ends only when its client deletes
+// it, so the state of the least recently used ones is forgotten past it.
+const maxSessions = 100
+
+// sessionKeys are the sessions a store keeps state for, least recently
+// used first. Its methods are called with the lock of the store held.
+type sessionKeys struct {
+	order []*mcp.ServerSession
+}
+
+// use makes session the most recently used, and returns the sessions past
+// maxSessions, which the store must forget.
+func (k *sessionKeys) use(session *mcp.ServerSession) []*mcp.ServerSession {
+	if i := slices.Index(k.order, session); i >= 0 {
+		k.order = slices.Delete(k.order, i, i+1)
+	}
+	k.order = append(k.order, session)
+	n := len(k.order) - maxSessions
+	if n <= 0 {
+		return nil
+	}
+	evicted := slices.Clone(k.order[:n])
+	k.order = slices.Delete(k.order, 0, n)
+	return evicted
+}
+
+// forget stops keeping session.
+func (k *sessionKeys) forget(session *mcp.ServerSession) {
+	k.order = slices.DeleteFunc(k.order, func(s *mcp.ServerSession) bool { return s == session })
+}
+
+// watchSession has the stores forget session once it ends. It is the
+// initialized handler of the server, so it runs once for every session.
+func watchSession(_ context.Context, session *mcp.ServerSession, _ *mcp.InitializedParams) {
+	go func() {
+		_ = session.Wait()
+		latestRuns.forget(session)
+		fileOperations.forget(session)
+	}()
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// settings is everything the tools read besides their arguments: the
+// configuration file, the environment variables they use and the working
+// directory relative paths are resolved against. It is captured once at
+// startup and never changed afterwards, so concurrent calls all see the
+// same values, and nothing else in the process, such as a change of
+// directory or environment, reaches them mid-call.
+type settings struct {
+	config      serverConfig
+	allowWrites bool
+	// workDir is the working directory at startup, which relative paths
+	// and omitted directories in arguments are resolved against.
+	workDir string
+	// lint is what the tools lint with: the library defaults, with the
+	// external linters and limits from the environment, and the
+	// configured rules.
+	lint linter.Options
+
+	githubToken      string
+	githubRepository string
+	actCommand       string
+	webhookSecret    string
+}
+
+// newSettings captures cfg with the environment and working directory of
+// the process.
+func newSettings(cfg serverConfig, allowWrites bool) (*settings, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	lint := linter.DefaultOptions()
+	if lint.ConfigFile != "" && !filepath.IsAbs(lint.ConfigFile) {
+		lint.ConfigFile = filepath.Join(wd, lint.ConfigFile)
+	}
+	lint.Rules = cfg.Rules
+	lint.ServerVersion = version
+
+	s := &settings{
+		config:           cfg,
+		allowWrites:      allowWrites,
+		workDir:          wd,
+		lint:             lint,
+		githubToken:      os.Getenv("GITHUB_TOKEN"),
+		githubRepository: os.Getenv("GITHUB_REPOSITORY"),
+		actCommand:       os.Getenv("ACT_COMMAND"),
+	}
+	if cfg.Webhook.SecretEnv != "" {
+		s.webhookSecret = os.Getenv(cfg.Webhook.SecretEnv)
+	}
+	return s, nil
+}
+
+// path resolves p, a path given in arguments, against the working
+// directory, accepting Windows separators as linter.CleanPath does.
+func (s *settings) path(p string) string {
+	p = linter.CleanPath(p)
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(s.workDir, p)
+}
+
+// dir resolves dir as path does, defaulting to the working directory.
+func (s *settings) dir(dir string) string {
+	if dir == "" {
+		return s.workDir
+	}
+	return s.path(dir)
+}
+
+// startupSettings are the settings the server or command started with.
+var startupSettings atomic.Pointer[settings]
+
+// environmentSettings are the settings of the process environment without
+// a configuration file, for code running before any are stored.
+var environmentSettings = sync.OnceValue(func() *settings {
+	s, err := newSettings(serverConfig{}, false)
+	if err != nil {
+		s = &settings{lint: linter.DefaultOptions(), workDir: "."}
+	}
+	return s
+})
+
+// settingsKey is the context key of the settings of a call.
+type settingsKey struct{}
+
+// withSettings returns ctx carrying s.
+func withSettings(ctx context.Context, s *settings) context.Context {
+	return context.WithValue(ctx, settingsKey{}, s)
+}
+
+// settingsFrom returns the settings ctx carries, or the startup settings
+// when it carries none.
+func settingsFrom(ctx context.Context) *settings {
+	if ctx != nil {
+		if s, ok := ctx.Value(settingsKey{}).(*settings); ok {
+			return s
+		}
+	}
+	if s := startupSettings.Load(); s != nil {
+		return s
+	}
+	return environmentSettings()
+}
+
+// settingsMiddleware passes s to the handlers of every request.
+func settingsMiddleware(s *settings) mcp.Middleware[*mcp.ServerSession] {
+	return func(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+		return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+			return next(withSettings(ctx, s), session, method, params)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useSettings makes the tools run with cfg and the environment and working
+// directory of the test, as if the server started now, until it ends.
+func useSettings(t testing.TB, cfg serverConfig, allowWrites bool) *settings {
+	t.Helper()
+	s, err := newSettings(cfg, allowWrites)
+	require.NoError(t, err)
+	old := startupSettings.Swap(s)
+	t.Cleanup(func() { startupSettings.Store(old) })
+	return s
+}
+
+func TestNewSettings(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("SHELLCHECK_COMMAND", "shellcheck-at-startup")
+	t.Setenv("GITHUB_TOKEN", "startup-token")
+	t.Setenv("GITHUB_REPOSITORY", "acme/app")
+	t.Setenv("LINT_WEBHOOK_SECRET", "s3cret")
+
+	cfg := serverConfig{Rules: rules.Config{Variables: []string{"IMAGE"}}, Webhook: webhookConfig{URL: "https://hooks.example.com", SecretEnv: "LINT_WEBHOOK_SECRET"}}
+	s, err := newSettings(cfg, true)
+	require.NoError(t, err)
+
+	// Later changes of the environment and directory are not seen
+	t.Setenv("SHELLCHECK_COMMAND", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Chdir(t.TempDir())
+
+	assert.Equal(t, "shellcheck-at-startup", s.lint.Shellcheck)
+	assert.Equal(t, []string{"IMAGE"}, s.lint.Rules.Variables)
+	assert.Equal(t, version, s.lint.ServerVersion)
+	assert.Equal(t, "startup-token", s.githubToken)
+	assert.Equal(t, "acme/app", s.githubRepository)
+	assert.Equal(t, "s3cret", s.webhookSecret)
+	assert.True(t, s.allowWrites)
+
+	assert.Equal(t, dir, s.workDir)
+	assert.Equal(t, filepath.Join(dir, ".github", "workflows"), s.path(".github/workflows"))
+	assert.Equal(t, filepath.Join(dir, "ci.yml"), s.path(`.\ci.yml`))
+	assert.Equal(t, "/abs/ci.yml", s.path("/abs/ci.yml"))
+	assert.Equal(t, dir, s.dir(""))
+}
+
+func TestSettingsFrom(t *testing.T) {
+	startup := useSettings(t, serverConfig{}, false)
+	call := &settings{allowWrites: true}
+
+	assert.Same(t, startup, settingsFrom(context.Background()))
+	assert.Same(t, call, settingsFrom(withSettings(context.Background(), call)))
+
+	// The middleware passes its settings to every request, concurrently
+	handler := settingsMiddleware(call)(func(ctx context.Context, _ *mcp.ServerSession, _ string, _ mcp.Params) (mcp.Result, error) {
+		assert.Same(t, call, settingsFrom(ctx))
+		return nil, nil
+	})
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := handler(context.Background(), nil, "tools/call", nil)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+}
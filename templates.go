@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+)
+
+// defaultTemplatesDirectory is where an organization's .github repository
+// keeps its workflow templates.
+const defaultTemplatesDirectory = "workflow-templates"
+
+// templatesConfig locates the golden workflow templates that
+// check_template_drift compares workflows with: a local directory, or a
+// directory of a GitHub repository.
+type templatesConfig struct {
+	Path       string `yaml:"path"`
+	Repository string `yaml:"repository"`
+	Directory  string `yaml:"directory"`
+	Ref        string `yaml:"ref"`
+}
+
+func (c templatesConfig) validate() error {
+	if c.Path != "" && c.Repository != "" {
+		return fmt.Errorf("templates: path and repository are mutually exclusive")
+	}
+	return nil
+}
+
+// templateName is the name a template and the workflows following it
+// share: the file name without its extension.
+func templateName(file string) string {
+	base := filepath.Base(file)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// loadTemplates reads the workflow templates in dir, keyed by template
+// name.
+func loadTemplates(dir string) (map[string][]byte, error) {
+	files, err := linter.FindWorkflowFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates: %w", err)
+	}
+	templates := make(map[string][]byte, len(files))
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		templates[templateName(f)] = data
+	}
+	return templates, nil
+}
+
+type repositoryContent struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Type     string `json:"type"`
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+// fetchTemplates downloads the workflow templates in dir of repo, given as
+// owner/name, at ref, or the default branch when ref is empty.
+func fetchTemplates(ctx context.Context, client *http.Client, repo, dir, ref string) (map[string][]byte, error) {
+	files, err := fetchWorkflowFiles(ctx, client, repo, dir, ref)
+	if err != nil {
+		return nil, err
+	}
+	templates := map[string][]byte{}
+	for p, content := range files {
+		templates[templateName(path.Base(p))] = content
+	}
+	return templates, nil
+}
+
+// fetchWorkflowFiles downloads the workflow files in dir of repo, given as
+// owner/name, at ref, or the default branch when ref is empty, keyed by
+// their path in the repository.
+func fetchWorkflowFiles(ctx context.Context, client *http.Client, repo, dir, ref string) (map[string][]byte, error) {
+	contentsURL := func(p string) string {
+		u := fmt.Sprintf("%s/repos/%s/contents/%s", githubAPIBaseURL, repo, strings.Trim(p, "/"))
+		if ref != "" {
+			u += "?ref=" + url.QueryEscape(ref)
+		}
+		return u
+	}
+
+	data, err := githubGet(ctx, client, contentsURL(dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+	var entries []repositoryContent
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", dir, err)
+	}
+
+	files := map[string][]byte{}
+	for _, e := range entries {
+		if e.Type != "file" || !linter.IsWorkflowFile(e.Name) {
+			continue
+		}
+		data, err := githubGet(ctx, client, contentsURL(e.Path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", e.Path, err)
+		}
+		var file repositoryContent
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", e.Path, err)
+		}
+		if file.Encoding != "base64" {
+			return nil, fmt.Errorf("%s has unsupported encoding %q", e.Path, file.Encoding)
+		}
+		content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", e.Path, err)
+		}
+		files[e.Path] = content
+	}
+	return files, nil
+}
+
+// templateDrift is how one workflow departs from its template.
+type templateDrift struct {
+	FilePath   string             `json:"file_path"`
+	Template   string             `json:"template"`
+	Deviations []linter.Deviation `json:"deviations"`
+	Error      string             `json:"error,omitempty"`
+}
+
+type driftReport struct {
+	Templates       string          `json:"templates"`
+	Workflows       []templateDrift `json:"workflows"`
+	UnusedTemplates []string        `json:"unused_templates,omitempty"`
+}
+
+// compareTemplates compares each workflow file with the template of the
+// same name. Workflows without a template are left out, and templates no
+// workflow follows are listed as unused.
+func compareTemplates(files []string, templates map[string][]byte) driftReport {
+	report := driftReport{Workflows: []templateDrift{}}
+	used := map[string]bool{}
+	for _, f := range files {
+		name := templateName(f)
+		template, ok := templates[name]
+		if !ok {
+			continue
+		}
+		used[name] = true
+		drift := templateDrift{FilePath: f, Template: name, Deviations: []linter.Deviation{}}
+		content, err := os.ReadFile(f)
+		if err == nil {
+			var deviations []linter.Deviation
+			if deviations, err = linter.TemplateDrift(template, content); err == nil && deviations != nil {
+				drift.Deviations = deviations
+			}
+		}
+		if err != nil {
+			drift.Error = err.Error()
+		}
+		report.Workflows = append(report.Workflows, drift)
+	}
+	for name := range templates {
+		if !used[name] {
+			report.UnusedTemplates = append(report.UnusedTemplates, name)
+		}
+	}
+	sort.Strings(report.UnusedTemplates)
+	return report
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ciTemplate = `on: push
+permissions:
+  contents: read
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: step-security/harden-runner@v2
+      - run: make
+`
+
+func TestCheckTemplateDrift(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "v1", r.URL.Query().Get("ref"))
+		switch r.URL.Path {
+		case "/repos/acme/.github/contents/workflow-templates":
+			_, _ = w.Write([]byte(`[
+				{"name": "ci.yml", "path": "workflow-templates/ci.yml", "type": "file"},
+				{"name": "ci.properties.json", "path": "workflow-templates/ci.properties.json", "type": "file"},
+				{"name": "release.yaml", "path": "workflow-templates/release.yaml", "type": "file"}
+			]`))
+		case "/repos/acme/.github/contents/workflow-templates/ci.yml":
+			fmt.Fprintf(w, `{"content": %q, "encoding": "base64"}`, base64.StdEncoding.EncodeToString([]byte(ciTemplate)))
+		case "/repos/acme/.github/contents/workflow-templates/release.yaml":
+			fmt.Fprintf(w, `{"content": %q, "encoding": "base64"}`, base64.StdEncoding.EncodeToString([]byte("on: push\njobs: {}\n")))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	oldURL := githubAPIBaseURL
+	githubAPIBaseURL = server.URL
+	defer func() { githubAPIBaseURL = oldURL }()
+
+	dir := t.TempDir()
+	workflow := "on: push\npermissions:\n  contents: read\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: make\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ci.yml"), []byte(workflow), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "other.yml"), []byte(workflow), 0644))
+
+	call := func(args CheckTemplateDriftParams) driftReport {
+		t.Helper()
+		result, err := CheckTemplateDrift(context.Background(), nil, &mcp.CallToolParamsFor[CheckTemplateDriftParams]{Arguments: args})
+		require.NoError(t, err)
+		var out driftReport
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out))
+		return out
+	}
+
+	report := call(CheckTemplateDriftParams{Directory: dir, Repository: "acme/.github", Ref: "v1"})
+	assert.Equal(t, "github:acme/.github/workflow-templates@v1", report.Templates)
+	require.Len(t, report.Workflows, 1)
+	assert.Equal(t, "ci", report.Workflows[0].Template)
+	require.Len(t, report.Workflows[0].Deviations, 1)
+	assert.Equal(t, linter.DriftStep, report.Workflows[0].Deviations[0].Kind)
+	assert.Equal(t, linter.SeverityError, report.Workflows[0].Deviations[0].Severity)
+	assert.Equal(t, []string{"release"}, report.UnusedTemplates)
+
+	// Local templates, also from the config file
+	templates := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(templates, "ci.yaml"), []byte(workflow), 0644))
+	report = call(CheckTemplateDriftParams{Directory: dir, Templates: templates})
+	require.Len(t, report.Workflows, 1)
+	assert.Empty(t, report.Workflows[0].Deviations)
+	assert.Empty(t, report.UnusedTemplates)
+
+	useSettings(t, serverConfig{Templates: templatesConfig{Path: templates}}, false)
+	report = call(CheckTemplateDriftParams{Directory: dir})
+	assert.Equal(t, templates, report.Templates)
+
+	useSettings(t, serverConfig{}, false)
+	_, err := CheckTemplateDrift(context.Background(), nil, &mcp.CallToolParamsFor[CheckTemplateDriftParams]{Arguments: CheckTemplateDriftParams{Directory: dir}})
+	assert.ErrorContains(t, err, "configure templates")
+	_, err = CheckTemplateDrift(context.Background(), nil, &mcp.CallToolParamsFor[CheckTemplateDriftParams]{Arguments: CheckTemplateDriftParams{Directory: dir, Templates: t.TempDir()}})
+	assert.ErrorContains(t, err, "no templates found")
+}
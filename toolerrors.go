@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// errorCode names the cause of a failed tool call, so that clients can
+// act on it without matching the message, which may change.
+type errorCode string
+
+const (
+	// codeFileNotFound is a file or directory in the arguments that does
+	// not exist.
+	codeFileNotFound errorCode = "FILE_NOT_FOUND"
+	// codePathForbidden is a path the server may not read or write, either
+	// for lack of permission or because it runs without -allow-writes.
+	codePathForbidden errorCode = "PATH_FORBIDDEN"
+	// codeYAMLTooLarge is a workflow exceeding the input limits.
+	codeYAMLTooLarge errorCode = "YAML_TOO_LARGE"
+	// codeLinterInitFailed is an actionlint or repository configuration
+	// the linter cannot be set up with.
+	codeLinterInitFailed errorCode = "LINTER_INIT_FAILED"
+	// codeTimeout is a call that ran out of time.
+	codeTimeout errorCode = "TIMEOUT"
+	// codeToolFailed is any other failure.
+	codeToolFailed errorCode = "TOOL_FAILED"
+)
+
+// codedError is an error whose code is known where it is returned.
+type codedError struct {
+	code errorCode
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+
+func (e *codedError) Unwrap() error { return e.err }
+
+// withCode returns err reported with code.
+func withCode(code errorCode, err error) error {
+	return &codedError{code: code, err: err}
+}
+
+// errorCodeOf returns the code a tool call failing with err is reported
+// with.
+func errorCodeOf(err error) errorCode {
+	var coded *codedError
+	var initErr *linter.InitError
+	switch {
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.Is(err, linter.ErrInputLimit):
+		return codeYAMLTooLarge
+	case errors.As(err, &initErr):
+		return codeLinterInitFailed
+	case errors.Is(err, context.DeadlineExceeded):
+		return codeTimeout
+	case errors.Is(err, os.ErrNotExist):
+		return codeFileNotFound
+	case errors.Is(err, os.ErrPermission):
+		return codePathForbidden
+	}
+	return codeToolFailed
+}
+
+// toolError is the structured content of a failed tool call.
+type toolError struct {
+	Error struct {
+		Code    errorCode `json:"code"`
+		Message string    `json:"message"`
+	} `json:"error"`
+}
+
+// errorResult returns the result of a tool call failing with err: its
+// message as text, as the SDK reports errors, and its code and message
+// as structured content.
+func errorResult(err error) *mcp.CallToolResultFor[any] {
+	var out toolError
+	out.Error.Code = errorCodeOf(err)
+	out.Error.Message = err.Error()
+	return &mcp.CallToolResultFor[any]{
+		Content:           []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+		StructuredContent: out,
+		IsError:           true,
+	}
+}
+
+// addTool adds the tool with handler to server, reporting the errors of
+// handler with errorResult.
+func addTool[In any](server *mcp.Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, any]) {
+	mcp.AddTool(server, tool, func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[In]) (*mcp.CallToolResultFor[any], error) {
+		result, err := handler(ctx, session, params)
+		if err != nil {
+			return errorResult(err), nil
+		}
+		return result, nil
+	})
+}
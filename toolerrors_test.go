@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCodeOf(t *testing.T) {
+	_, notFound := os.ReadFile(filepath.Join(t.TempDir(), "missing.yml"))
+	assert.Equal(t, codeFileNotFound, errorCodeOf(fmt.Errorf("failed to read file: %w", notFound)))
+	assert.Equal(t, codePathForbidden, errorCodeOf(&os.PathError{Op: "open", Path: "/etc/shadow", Err: os.ErrPermission}))
+	assert.Equal(t, codeYAMLTooLarge, errorCodeOf(fmt.Errorf("%w: too deep", linter.ErrInputLimit)))
+	assert.Equal(t, codeLinterInitFailed, errorCodeOf(&linter.InitError{Op: "create linter", Err: notFound}), "a missing config file fails the linter")
+	assert.Equal(t, codeTimeout, errorCodeOf(fmt.Errorf("failed to fetch: %w", context.DeadlineExceeded)))
+	assert.Equal(t, codePathForbidden, errorCodeOf(withCode(codePathForbidden, errors.New("needs -allow-writes"))))
+	assert.Equal(t, codeToolFailed, errorCodeOf(errors.New("unknown output_format")))
+}
+
+func TestToolErrors(t *testing.T) {
+	useSettings(t, serverConfig{}, false)
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := newServer().Connect(ctx, serverTransport)
+	require.NoError(t, err)
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil).Connect(ctx, clientTransport)
+	require.NoError(t, err)
+	defer session.Close()
+
+	call := func(name string, args map[string]any) (code, message string) {
+		t.Helper()
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: args})
+		require.NoError(t, err)
+		require.True(t, res.IsError)
+		out, ok := res.StructuredContent.(map[string]any)
+		require.True(t, ok, "%v", res.StructuredContent)
+		e := out["error"].(map[string]any)
+		assert.Equal(t, res.Content[0].(*mcp.TextContent).Text, e["message"], "the text is the message")
+		return e["code"].(string), e["message"].(string)
+	}
+
+	code, message := call("apply_fixes", map[string]any{"file_path": filepath.Join(t.TempDir(), "missing.yml")})
+	assert.Equal(t, "FILE_NOT_FOUND", code)
+	assert.Contains(t, message, "missing.yml")
+
+	large := filepath.Join(t.TempDir(), "large.yml")
+	require.NoError(t, os.WriteFile(large, []byte("on: push\n"+strings.Repeat("#", linter.DefaultMaxInputBytes)), 0644))
+	code, _ = call("apply_fixes", map[string]any{"file_path": large})
+	assert.Equal(t, "YAML_TOO_LARGE", code)
+
+	code, _ = call("check_all_workflows", map[string]any{"directory": t.TempDir(), "output_path": "results.json"})
+	assert.Equal(t, "PATH_FORBIDDEN", code)
+}
@@ -0,0 +1,1633 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/rules"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type LintWorkflowParams struct {
+	FilePath            string   `json:"file_path,omitempty" jsonschema:"description=Path to the workflow file to lint"`
+	Content             string   `json:"content,omitempty" jsonschema:"description=Content of the workflow file to lint (if file_path is not provided)"`
+	Filename            string   `json:"filename,omitempty" jsonschema:"description=Path the content will be saved to, used in results and to find the repository's actionlint config"`
+	Scope               string   `json:"scope,omitempty" jsonschema:"description=Only report findings in this job, given by its ID, or in one of its steps, given as job/step with the step's id or position counting from 1"`
+	SkipRefFilters      bool     `json:"skip_ref_filters,omitempty" jsonschema:"description=Skip checking branches and tags filters against the refs of the git repository, as for a template repository"`
+	CompareTo           string   `json:"compare_to,omitempty" jsonschema:"description=Only report findings that are not in this baseline: a git ref such as origin/main, at which the file is linted, or the path of a saved lint_workflow or check_all_workflows result"`
+	OutputPath          string   `json:"output_path,omitempty" jsonschema:"description=Also write the results to this file, as a CI artifact; needs the server to run with -allow-writes"`
+	OutputFormat        string   `json:"output_format,omitempty" jsonschema:"description=Format of the output_path file: json (default, the tool's output), sarif for code scanning or markdown for a job summary or comment"`
+	FailOn              string   `json:"fail_on,omitempty" jsonschema:"description=Least severe finding that makes a file invalid: error (which includes critical), warning or info; less severe findings are still reported (defaults to any finding)"`
+	ResultFormatVersion int      `json:"result_format_version,omitempty" jsonschema:"description=Version of the result format the client expects; the tool fails rather than answer in another (defaults to the current version)"`
+	MaxBytes            int      `json:"max_bytes,omitempty" jsonschema:"description=Most bytes of text to return; a larger result drops the edits of fixes, then shortens messages, then leaves out the least severe findings, keeping counts and totals"`
+	MaxResponseTokens   int      `json:"max_response_tokens,omitempty" jsonschema:"description=Most tokens of text to return, at about 4 bytes a token; the smaller of this and max_bytes applies"`
+	Packs               []string `json:"packs,omitempty" jsonschema:"description=Rule packs to turn on for this call, in addition to those of the configuration and the repository"`
+}
+
+// scopedResult is the lint_workflow output when a scope is given: the
+// findings in the scope and how many findings elsewhere were left out.
+type scopedResult struct {
+	*LintResult
+	Scope              string `json:"scope"`
+	OutOfScopeFindings int    `json:"out_of_scope_findings"`
+}
+
+// comparedResult is the lint_workflow output when compare_to is given: the
+// findings not in the baseline and how many were left out, along with the
+// scope when one is given too.
+type comparedResult struct {
+	*LintResult
+	CompareTo          string `json:"compare_to"`
+	BaselineFindings   int    `json:"baseline_findings"`
+	Scope              string `json:"scope,omitempty"`
+	OutOfScopeFindings int    `json:"out_of_scope_findings,omitempty"`
+}
+
+// comparedSummary is the check_all_workflows output when compare_to is
+// given: the summary of the findings not in the baseline and how many were
+// left out.
+type comparedSummary struct {
+	*linter.Summary
+	CompareTo        string `json:"compare_to"`
+	BaselineFindings int    `json:"baseline_findings"`
+}
+
+type CheckAllWorkflowsParams struct {
+	Directory           string   `json:"directory,omitempty" jsonschema:"description=Directory to search for workflow files (defaults to .github/workflows)"`
+	ResultsAsMap        bool     `json:"results_as_map,omitempty" jsonschema:"description=Return results as an object keyed by file path instead of a sorted array (legacy format)"`
+	Digest              bool     `json:"digest,omitempty" jsonschema:"description=Return only the totals, the rules reported most and a finding of each of the most severe rules instead of the results of every file"`
+	DigestFindings      int      `json:"digest_findings,omitempty" jsonschema:"description=Most findings the digest shows (defaults to 5, at most 50)"`
+	Format              string   `json:"format,omitempty" jsonschema:"description=Output format: json (default), slack_blocks for a Slack message or teams_card for a Teams Adaptive Card"`
+	SkipRefFilters      bool     `json:"skip_ref_filters,omitempty" jsonschema:"description=Skip checking branches and tags filters against the refs of the git repository, as for a template repository"`
+	CompareTo           string   `json:"compare_to,omitempty" jsonschema:"description=Only report findings that are not in this baseline: a git ref such as origin/main, at which the files are linted, or the path of a saved check_all_workflows result"`
+	OutputPath          string   `json:"output_path,omitempty" jsonschema:"description=Also write the results to this file, as a CI artifact; needs the server to run with -allow-writes"`
+	OutputFormat        string   `json:"output_format,omitempty" jsonschema:"description=Format of the output_path file: json (default, the tool's output), sarif for code scanning or markdown for a job summary or comment"`
+	FailOn              string   `json:"fail_on,omitempty" jsonschema:"description=Least severe finding that makes a file invalid: error (which includes critical), warning or info; less severe findings are still reported (defaults to any finding)"`
+	ResultFormatVersion int      `json:"result_format_version,omitempty" jsonschema:"description=Version of the result format the client expects; the tool fails rather than answer in another (defaults to the current version)"`
+	MaxBytes            int      `json:"max_bytes,omitempty" jsonschema:"description=Most bytes of text to return; a larger result drops the edits of fixes, then shortens messages, then leaves out the least severe findings, keeping counts and totals"`
+	MaxResponseTokens   int      `json:"max_response_tokens,omitempty" jsonschema:"description=Most tokens of text to return, at about 4 bytes a token; the smaller of this and max_bytes applies"`
+	Packs               []string `json:"packs,omitempty" jsonschema:"description=Rule packs to turn on for this call, in addition to those of the configuration and the repository"`
+}
+
+type ListRulesParams struct {
+	Directory string   `json:"directory,omitempty" jsonschema:"description=Root of the repository whose .github/actionlint-mcp.yaml turns on further packs (defaults to the current directory)"`
+	Packs     []string `json:"packs,omitempty" jsonschema:"description=Rule packs to turn on, as a lint call would"`
+}
+
+type FindMisplacedWorkflowsParams struct {
+	Directory string `json:"directory,omitempty" jsonschema:"description=Repository root to search (defaults to the current directory)"`
+}
+
+type MoveMisplacedWorkflowsParams struct {
+	Directory string   `json:"directory,omitempty" jsonschema:"description=Repository root to search (defaults to the current directory)"`
+	Paths     []string `json:"paths,omitempty" jsonschema:"description=Misplaced workflow files to move (defaults to all that are found)"`
+	DryRun    bool     `json:"dry_run,omitempty" jsonschema:"description=Report the moves without making them, which is always the case unless the server runs with -allow-writes"`
+}
+
+type ExtractScriptParams struct {
+	FilePath string `json:"file_path" jsonschema:"description=Path to the workflow file"`
+	Job      string `json:"job" jsonschema:"description=ID of the job containing the step"`
+	Step     int    `json:"step" jsonschema:"description=Position of the step in the job, counting from 1 as in long-script findings"`
+	DryRun   bool   `json:"dry_run,omitempty" jsonschema:"description=Return the changes without writing them, which is always the case unless the server runs with -allow-writes"`
+}
+
+// scriptExtraction is the extract_script output: the extraction and
+// whether it was written.
+type scriptExtraction struct {
+	*linter.ScriptExtraction
+	Written bool `json:"written"`
+}
+
+type ExtractCompositeActionParams struct {
+	FilePath    string `json:"file_path" jsonschema:"description=Path to the workflow file in .github/workflows"`
+	Job         string `json:"job" jsonschema:"description=ID of the job containing the steps"`
+	FirstStep   int    `json:"first_step" jsonschema:"description=Position of the first step to extract, counting from 1"`
+	LastStep    int    `json:"last_step,omitempty" jsonschema:"description=Position of the last step to extract (defaults to first_step)"`
+	Name        string `json:"name" jsonschema:"description=Name of the action, which is written to .github/actions/<name>/action.yml"`
+	Description string `json:"description,omitempty" jsonschema:"description=Description of the action"`
+	Write       bool   `json:"write,omitempty" jsonschema:"description=Write the action and the rewritten workflows, when the server runs with -allow-writes"`
+	DryRun      bool   `json:"dry_run,omitempty" jsonschema:"description=Return the changes without writing them, even with write"`
+}
+
+// compositeExtraction is the extract_composite_action output: the
+// extraction and the findings of the changed workflows.
+type compositeExtraction struct {
+	*linter.CompositeExtraction
+	Lint    []*linter.LintResult `json:"lint"`
+	Written bool                 `json:"written"`
+}
+
+type FormatWorkflowParams struct {
+	FilePath string `json:"file_path,omitempty" jsonschema:"description=Path to the workflow file to format"`
+	Content  string `json:"content,omitempty" jsonschema:"description=Content of the workflow to format (if file_path is not provided)"`
+	Write    bool   `json:"write,omitempty" jsonschema:"description=Write the formatted workflow back to file_path, when the server runs with -allow-writes"`
+	DryRun   bool   `json:"dry_run,omitempty" jsonschema:"description=Return the diff without writing it, even with write"`
+}
+
+// formattedWorkflow is the format_workflow output. Formatted is left out
+// when the file was written, and Backup is the copy of the file it
+// replaced.
+type formattedWorkflow struct {
+	FilePath  string `json:"file_path,omitempty"`
+	Changed   bool   `json:"changed"`
+	Written   bool   `json:"written"`
+	Backup    string `json:"backup,omitempty"`
+	Diff      string `json:"diff,omitempty"`
+	Formatted string `json:"formatted,omitempty"`
+}
+
+type ApplyFixesParams struct {
+	FilePath string   `json:"file_path" jsonschema:"description=Path to the workflow file to fix"`
+	Fixes    []string `json:"fixes,omitempty" jsonschema:"description=IDs of the fixes to apply, as reported by lint_workflow (defaults to all)"`
+	DryRun   bool     `json:"dry_run,omitempty" jsonschema:"description=Return the diff without writing it, which is always the case unless the server runs with -allow-writes"`
+}
+
+// fixResult is the apply_fixes output: the applied fixes, whether the
+// file was written and where the file it replaced was copied.
+type fixResult struct {
+	*linter.FixResult
+	Written bool   `json:"written"`
+	Backup  string `json:"backup,omitempty"`
+}
+
+type UndoFixesParams struct{}
+
+type LintPatchParams struct {
+	FilePath            string `json:"file_path,omitempty" jsonschema:"description=Path to the workflow file the patch applies to; a missing file is treated as empty"`
+	Base                string `json:"base,omitempty" jsonschema:"description=Content the patch applies to (if file_path is not provided)"`
+	Filename            string `json:"filename,omitempty" jsonschema:"description=Path the base content is saved at, used in results and to find the repository's actionlint config"`
+	Patch               string `json:"patch" jsonschema:"description=Unified diff of the workflow file"`
+	ResultFormatVersion int    `json:"result_format_version,omitempty" jsonschema:"description=Version of the result format the client expects; the tool fails rather than answer in another (defaults to the current version)"`
+	MaxBytes            int    `json:"max_bytes,omitempty" jsonschema:"description=Most bytes of text to return; a larger result drops the edits of fixes, then shortens messages, then leaves out the least severe findings, keeping counts and totals"`
+	MaxResponseTokens   int    `json:"max_response_tokens,omitempty" jsonschema:"description=Most tokens of text to return, at about 4 bytes a token; the smaller of this and max_bytes applies"`
+}
+
+// patchResult is the lint_patch output: the findings on changed lines, how
+// many findings on other lines were left out, and the changes reviewers
+// should look at for security.
+type patchResult struct {
+	*LintResult
+	UnchangedFindings int                     `json:"unchanged_findings"`
+	SecurityChanges   []linter.SecurityChange `json:"security_changes,omitempty"`
+}
+
+type DryRunWorkflowParams struct {
+	FilePath            string `json:"file_path" jsonschema:"description=Path to the workflow file to run"`
+	Event               string `json:"event,omitempty" jsonschema:"description=Event to simulate (defaults to push)"`
+	Payload             string `json:"payload,omitempty" jsonschema:"description=JSON event payload passed to act"`
+	RunAct              bool   `json:"run_act,omitempty" jsonschema:"description=Run the jobs with act, in its dry-run mode (-n), instead of only listing them; needs a container runtime"`
+	ResultFormatVersion int    `json:"result_format_version,omitempty" jsonschema:"description=Version of the result format the client expects; the tool fails rather than answer in another (defaults to the current version)"`
+	MaxBytes            int    `json:"max_bytes,omitempty" jsonschema:"description=Most bytes of text to return; a larger result drops the edits of fixes, then shortens messages, then leaves out the least severe findings, keeping counts and totals"`
+	MaxResponseTokens   int    `json:"max_response_tokens,omitempty" jsonschema:"description=Most tokens of text to return, at about 4 bytes a token; the smaller of this and max_bytes applies"`
+}
+
+// dryRunResult is the dry_run_workflow output: the lint result with act's
+// findings merged in, and what act reported.
+type dryRunResult struct {
+	*LintResult
+	Act *linter.ActResult `json:"act"`
+}
+
+type SimulateTriggerParams struct {
+	Directory    string   `json:"directory,omitempty" jsonschema:"description=Directory of the workflow files to check (defaults to .github/workflows)"`
+	Event        string   `json:"event" jsonschema:"description=Name of the event, such as push or pull_request"`
+	Payload      string   `json:"payload,omitempty" jsonschema:"description=JSON webhook payload of the event"`
+	ChangedFiles []string `json:"changed_files,omitempty" jsonschema:"description=Files changed by the event, for paths filters (defaults to the files of the commits in a push payload)"`
+}
+
+type FindOrphanedWorkflowsParams struct {
+	Directory  string `json:"directory,omitempty" jsonschema:"description=Directory of the repository's workflow files (defaults to .github/workflows)"`
+	Repository string `json:"repository,omitempty" jsonschema:"description=Repository as owner/name, whose template status and deployments are read with GITHUB_TOKEN (defaults to GITHUB_REPOSITORY)"`
+}
+
+type WorkflowCoverageParams struct {
+	Directory string `json:"directory,omitempty" jsonschema:"description=Root of the repository (defaults to the current directory)"`
+}
+
+// orphanedWorkflows is the find_orphaned_workflows output: the workflows
+// with triggers that can never fire, what was known of the repository, and
+// which checks were skipped for lack of it.
+type orphanedWorkflows struct {
+	Workflows []linter.OrphanedWorkflow `json:"workflows"`
+	Sources   []string                  `json:"sources"`
+	Skipped   []string                  `json:"skipped,omitempty"`
+}
+
+type WorkflowFlakinessParams struct {
+	Repository string `json:"repository,omitempty" jsonschema:"description=Repository as owner/name (defaults to GITHUB_REPOSITORY)"`
+	Directory  string `json:"directory,omitempty" jsonschema:"description=Directory of the workflow files to check (defaults to .github/workflows)"`
+	Runs       int    `json:"runs,omitempty" jsonschema:"description=Number of recent completed runs to look at per workflow (defaults to 50, at most 100)"`
+}
+
+// flakinessReport is the workflow_flakiness output for one workflow: its
+// recent run conclusions, how many lint findings it has and the hints that
+// can explain its failures, most relevant first.
+type flakinessReport struct {
+	FilePath    string                 `json:"file_path"`
+	History     linter.RunHistory      `json:"history"`
+	FailureRate float64                `json:"failure_rate"`
+	Findings    int                    `json:"findings"`
+	Hints       []linter.FlakinessHint `json:"hints,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+}
+
+type CheckVariablesParams struct {
+	FilePath   string   `json:"file_path,omitempty" jsonschema:"description=Path to a single workflow file to check"`
+	Directory  string   `json:"directory,omitempty" jsonschema:"description=Directory of the workflow files to check (defaults to .github/workflows)"`
+	Repository string   `json:"repository,omitempty" jsonschema:"description=Repository as owner/name whose variables are fetched (defaults to GITHUB_REPOSITORY)"`
+	Variables  []string `json:"variables,omitempty" jsonschema:"description=Names of the defined configuration variables; when given nothing is fetched"`
+}
+
+type CheckLabelsParams struct {
+	FilePath   string   `json:"file_path,omitempty" jsonschema:"description=Path to a single workflow file to check"`
+	Directory  string   `json:"directory,omitempty" jsonschema:"description=Directory of the workflow files to check (defaults to .github/workflows)"`
+	Repository string   `json:"repository,omitempty" jsonschema:"description=Repository as owner/name whose labels are fetched (defaults to GITHUB_REPOSITORY)"`
+	Labels     []string `json:"labels,omitempty" jsonschema:"description=Names of the labels of the repository; when given nothing is fetched"`
+}
+
+type ProvisioningChecklistParams struct {
+	FilePath   string `json:"file_path,omitempty" jsonschema:"description=Path to a single workflow file to summarize"`
+	Directory  string `json:"directory,omitempty" jsonschema:"description=Directory of the workflow files to summarize (defaults to .github/workflows)"`
+	Compare    bool   `json:"compare,omitempty" jsonschema:"description=Compare the checklist with the secrets and variables the repository defines, fetched with GITHUB_TOKEN"`
+	Repository string `json:"repository,omitempty" jsonschema:"description=Repository as owner/name to compare with (defaults to GITHUB_REPOSITORY)"`
+}
+
+// provisioningResult is the provisioning_checklist output, with the
+// repository the checklist was compared with, if any.
+type provisioningResult struct {
+	*linter.Provisioning
+	Source string `json:"source,omitempty"`
+}
+
+// variablesResult is the check_variables output: the variables checked
+// against, where they came from, and the undefined-variable findings.
+type variablesResult struct {
+	Variables []string `json:"variables"`
+	Source    string   `json:"source"`
+	*linter.Summary
+}
+
+// labelsResult is the check_labels output: the labels checked against,
+// where they came from, and the undefined-label findings.
+type labelsResult struct {
+	Labels []string `json:"labels"`
+	Source string   `json:"source"`
+	*linter.Summary
+}
+
+// LintFromURLParams are the arguments of lint_from_url.
+type LintFromURLParams struct {
+	URL                 string `json:"url" jsonschema:"description=https URL of the workflow on raw.githubusercontent.com, github.com or a gist"`
+	ResultFormatVersion int    `json:"result_format_version,omitempty" jsonschema:"description=Version of the result format the client expects; the tool fails rather than answer in another (defaults to the current version)"`
+	MaxBytes            int    `json:"max_bytes,omitempty" jsonschema:"description=Most bytes of text to return; a larger result drops the edits of fixes, then shortens messages, then leaves out the least severe findings, keeping counts and totals"`
+	MaxResponseTokens   int    `json:"max_response_tokens,omitempty" jsonschema:"description=Most tokens of text to return, at about 4 bytes a token; the smaller of this and max_bytes applies"`
+}
+
+type LintTrendsParams struct {
+	Directory string `json:"directory,omitempty" jsonschema:"description=Directory of the workflow files whose history is returned (defaults to .github/workflows)"`
+	Rule      string `json:"rule,omitempty" jsonschema:"description=Only count the findings of this rule, by kind"`
+	Days      int    `json:"days,omitempty" jsonschema:"description=Number of days of history to return (defaults to 90)"`
+}
+
+type SuggestPermissionsParams struct {
+	FilePath string `json:"file_path,omitempty" jsonschema:"description=Path to the workflow file"`
+	Content  string `json:"content,omitempty" jsonschema:"description=Content of the workflow (if file_path is not provided)"`
+}
+
+// permissionsSuggestion is the suggest_permissions output.
+type permissionsSuggestion struct {
+	FilePath string                  `json:"file_path,omitempty"`
+	Jobs     []linter.JobPermissions `json:"jobs"`
+}
+
+type InferWorkflowCallParams struct {
+	FilePath string `json:"file_path,omitempty" jsonschema:"description=Path to the workflow file"`
+	Content  string `json:"content,omitempty" jsonschema:"description=Content of the workflow (if file_path is not provided)"`
+	Job      string `json:"job" jsonschema:"description=ID of the job to turn into a reusable workflow"`
+}
+
+type SuggestUpdatesForActionParams struct {
+	Action    string `json:"action" jsonschema:"description=Action as owner/repo or owner/repo/path, such as actions/setup-node"`
+	Directory string `json:"directory,omitempty" jsonschema:"description=Directory of the workflow files whose usages are compared (defaults to .github/workflows)"`
+}
+
+type CheckTemplateDriftParams struct {
+	Directory  string `json:"directory,omitempty" jsonschema:"description=Directory of the workflow files to compare (defaults to .github/workflows)"`
+	Templates  string `json:"templates,omitempty" jsonschema:"description=Local directory of the golden templates"`
+	Repository string `json:"repository,omitempty" jsonschema:"description=Repository as owner/name holding the golden templates"`
+	Ref        string `json:"ref,omitempty" jsonschema:"description=Branch, tag or commit of the template repository"`
+}
+
+type AuditRepositoriesParams struct {
+	Paths           []string `json:"paths,omitempty" jsonschema:"description=Local checkouts of the repositories to audit"`
+	Organization    string   `json:"organization,omitempty" jsonschema:"description=GitHub organization whose repositories are audited, fetching their workflows with GITHUB_TOKEN"`
+	IncludeArchived bool     `json:"include_archived,omitempty" jsonschema:"description=Audit the archived repositories of the organization too"`
+	Concurrency     int      `json:"concurrency,omitempty" jsonschema:"description=How many repositories to audit at once (defaults to 4, at most 16)"`
+	Details         bool     `json:"details,omitempty" jsonschema:"description=Include the findings of each file, not only the counts"`
+}
+
+type SyncActionMetadataParams struct {
+	Organizations   []string `json:"organizations,omitempty" jsonschema:"description=Organizations whose repositories are crawled for actions (defaults to action-metadata.organizations of the config file)"`
+	Directory       string   `json:"directory,omitempty" jsonschema:"description=Repository root the metadata file is relative to (defaults to the current directory)"`
+	File            string   `json:"file,omitempty" jsonschema:"description=Metadata file to write (defaults to rules.actions.file of the config file)"`
+	IncludeArchived bool     `json:"include_archived,omitempty" jsonschema:"description=Crawl the archived repositories of the organizations too"`
+	DryRun          bool     `json:"dry_run,omitempty" jsonschema:"description=Return the changes without writing them, which is always the case unless the server runs with -allow-writes"`
+}
+
+type GenerateTestFixturesParams struct {
+	Directory string   `json:"directory" jsonschema:"description=Directory to create the fixture workflows in"`
+	Kinds     []string `json:"kinds,omitempty" jsonschema:"description=Kinds of finding to create fixtures for (defaults to all)"`
+	DryRun    bool     `json:"dry_run,omitempty" jsonschema:"description=Return the changes without writing them, which is always the case unless the server runs with -allow-writes"`
+}
+
+// generatedFixtures is the generate_test_fixtures output: the fixtures,
+// the changes creating them and whether they were written.
+type generatedFixtures struct {
+	Fixtures []linter.Fixture    `json:"fixtures"`
+	Changes  []linter.FileChange `json:"changes"`
+	Written  bool                `json:"written"`
+}
+
+// movedWorkflow reports what move_misplaced_workflows did with one file.
+type movedWorkflow struct {
+	From    string `json:"from"`
+	To      string `json:"to,omitempty"`
+	Moved   bool   `json:"moved"`
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// validate rejects ambiguous or empty lint_workflow arguments.
+func (p LintWorkflowParams) validate(ctx context.Context) error {
+	switch {
+	case p.FilePath != "" && p.Content != "":
+		return fmt.Errorf("file_path and content are mutually exclusive; provide only one")
+	case p.FilePath == "" && p.Content == "":
+		return fmt.Errorf("either file_path or content must be provided")
+	case p.FilePath == "" && strings.TrimSpace(p.Content) == "":
+		return fmt.Errorf("content contains only whitespace; provide the workflow YAML to lint")
+	case p.FilePath != "" && p.Filename != "":
+		return fmt.Errorf("filename only applies to content; use file_path alone to lint a file")
+	}
+	if err := checkOutputParams(ctx, p.OutputPath, p.OutputFormat); err != nil {
+		return err
+	}
+	if err := checkFailOn(p.FailOn); err != nil {
+		return err
+	}
+	if err := rules.ValidatePacks(p.Packs); err != nil {
+		return err
+	}
+	return checkResultFormatVersion(p.ResultFormatVersion)
+}
+
+// mapSummary is the legacy check_all_workflows output, with results keyed by
+// file path. It is only produced when results_as_map is set.
+type mapSummary struct {
+	TotalFiles      int                   `json:"total_files"`
+	FilesWithErrors int                   `json:"files_with_errors"`
+	TotalErrors     int                   `json:"total_errors"`
+	Results         map[string]LintResult `json:"results"`
+	Meta            *linter.Meta          `json:"meta,omitempty"`
+}
+
+// LintResult and LintError are the library result types, aliased so the
+// tool handlers and their callers can keep using the short names.
+type (
+	LintResult = linter.LintResult
+	LintError  = linter.LintError
+)
+
+func LintWorkflow(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[LintWorkflowParams]) (*mcp.CallToolResultFor[any], error) {
+	if err := params.Arguments.validate(ctx); err != nil {
+		return nil, err
+	}
+	limit, err := responseLimit(params.Arguments.MaxBytes, params.Arguments.MaxResponseTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	var input linter.Input
+	if params.Arguments.FilePath != "" {
+		input.Path = settingsFrom(ctx).path(params.Arguments.FilePath)
+	} else {
+		input.Path = linter.CleanPath(params.Arguments.Filename)
+		input.Content = []byte(params.Arguments.Content)
+	}
+
+	opts := settingsFrom(ctx).lint
+	opts.SkipRefFilters = params.Arguments.SkipRefFilters
+	opts.Rules = opts.Rules.WithPacks(params.Arguments.Packs...)
+	l := linter.New(opts)
+	result, err := l.Lint(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	var compared *comparedResult
+	if params.Arguments.CompareTo != "" {
+		baseline, err := l.Baseline(ctx, params.Arguments.CompareTo, []string{result.FilePath})
+		if err != nil {
+			return nil, err
+		}
+		compared = &comparedResult{LintResult: result, CompareTo: params.Arguments.CompareTo, BaselineFindings: baseline.OnlyNew(result)}
+	}
+	// Before the scope, which leaves valid describing the whole file
+	if params.Arguments.FailOn != "" {
+		result.FailOn(params.Arguments.FailOn)
+	}
+	if params.Arguments.Scope == "" {
+		if compared != nil {
+			return outputResult(compared, result, params.Arguments.OutputPath, params.Arguments.OutputFormat, limit)
+		}
+		return outputResult(result, result, params.Arguments.OutputPath, params.Arguments.OutputFormat, limit)
+	}
+
+	content := input.Content
+	if input.Path != "" && content == nil {
+		if content, err = os.ReadFile(input.Path); err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+	lines, err := linter.ScopeLines(content, params.Arguments.Scope)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scope %q: %w", params.Arguments.Scope, err)
+	}
+	removed := linter.OnlyLines(result, lines)
+	if compared != nil {
+		compared.Scope, compared.OutOfScopeFindings = params.Arguments.Scope, removed
+		return outputResult(compared, result, params.Arguments.OutputPath, params.Arguments.OutputFormat, limit)
+	}
+	return outputResult(scopedResult{LintResult: result, Scope: params.Arguments.Scope, OutOfScopeFindings: removed}, result, params.Arguments.OutputPath, params.Arguments.OutputFormat, limit)
+}
+
+func CheckAllWorkflows(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[CheckAllWorkflowsParams]) (*mcp.CallToolResultFor[any], error) {
+	if err := checkResultFormatVersion(params.Arguments.ResultFormatVersion); err != nil {
+		return nil, err
+	}
+	directory := settingsFrom(ctx).path(linter.WorkflowsDir)
+	if params.Arguments.Directory != "" {
+		directory = settingsFrom(ctx).path(params.Arguments.Directory)
+	}
+	switch params.Arguments.Format {
+	case "", formatJSON, formatSlackBlocks, formatTeamsCard:
+	default:
+		return nil, fmt.Errorf("unknown format %q; use %s, %s or %s", params.Arguments.Format, formatJSON, formatSlackBlocks, formatTeamsCard)
+	}
+	if params.Arguments.ResultsAsMap && params.Arguments.Format != "" && params.Arguments.Format != formatJSON {
+		return nil, fmt.Errorf("results_as_map only applies to the json format")
+	}
+	switch {
+	case params.Arguments.Digest && params.Arguments.Format != "" && params.Arguments.Format != formatJSON:
+		return nil, fmt.Errorf("digest only applies to the json format")
+	case params.Arguments.Digest && params.Arguments.ResultsAsMap:
+		return nil, fmt.Errorf("digest and results_as_map are mutually exclusive")
+	case params.Arguments.DigestFindings != 0 && !params.Arguments.Digest:
+		return nil, fmt.Errorf("digest_findings needs digest")
+	}
+	if err := checkDigestFindings(params.Arguments.DigestFindings); err != nil {
+		return nil, err
+	}
+	if err := checkOutputParams(ctx, params.Arguments.OutputPath, params.Arguments.OutputFormat); err != nil {
+		return nil, err
+	}
+	if err := checkFailOn(params.Arguments.FailOn); err != nil {
+		return nil, err
+	}
+	if err := rules.ValidatePacks(params.Arguments.Packs); err != nil {
+		return nil, err
+	}
+	limit, err := responseLimit(params.Arguments.MaxBytes, params.Arguments.MaxResponseTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	if info, err := os.Stat(directory); err == nil && !info.IsDir() {
+		return nil, fmt.Errorf("%s is a file, not a directory; use lint_workflow to lint a single file", directory)
+	}
+
+	// Find all workflow files
+	files, err := linter.FindWorkflowFiles(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	// Lint all files. A directory without any gives a summary of no files,
+	// which is not kept in the history or exported
+	opts := settingsFrom(ctx).lint
+	opts.SkipRefFilters = params.Arguments.SkipRefFilters
+	opts.Rules = opts.Rules.WithPacks(params.Arguments.Packs...)
+	l := linter.New(opts)
+	summary := l.LintFiles(ctx, files)
+	if len(files) > 0 {
+		recordHistory(ctx, directory, summary)
+		exportSummary(ctx, directory, summary)
+	}
+	latestRuns.record(session, directory, summary)
+
+	// History, exports and the latest results keep every finding; the
+	// baseline only filters what is returned
+	var compared *comparedSummary
+	if params.Arguments.CompareTo != "" {
+		baseline, err := l.Baseline(ctx, params.Arguments.CompareTo, files)
+		if err != nil {
+			return nil, err
+		}
+		compared = &comparedSummary{Summary: summary, CompareTo: params.Arguments.CompareTo, BaselineFindings: summary.OnlyNew(baseline)}
+	}
+	if params.Arguments.FailOn != "" {
+		summary.FailOn(params.Arguments.FailOn)
+	}
+
+	output := func(summary *linter.Summary) any {
+		switch {
+		case params.Arguments.ResultsAsMap:
+			return mapSummary{
+				TotalFiles:      summary.TotalFiles,
+				FilesWithErrors: summary.FilesWithErrors,
+				TotalErrors:     summary.TotalErrors,
+				Results:         summary.ResultsByPath(),
+				Meta:            summary.Meta,
+			}
+		case params.Arguments.Digest:
+			digest := digestWorkflows(summary, params.Arguments.DigestFindings)
+			if compared != nil {
+				digest.CompareTo, digest.BaselineFindings = compared.CompareTo, compared.BaselineFindings
+			}
+			return digest
+		case compared != nil:
+			return &comparedSummary{Summary: summary, CompareTo: compared.CompareTo, BaselineFindings: compared.BaselineFindings}
+		}
+		return summary
+	}
+	out := output(summary)
+
+	var result *mcp.CallToolResultFor[any]
+	switch params.Arguments.Format {
+	case formatSlackBlocks:
+		result, err = payloadResult(slackBlocks(summary))
+	case formatTeamsCard:
+		result, err = payloadResult(teamsAdaptiveCard(summary))
+	default:
+		result, err = jsonResult(out)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := writeOutput(result, params.Arguments.OutputPath, params.Arguments.OutputFormat, summary, out); err != nil {
+		return nil, err
+	}
+	if limit == 0 || params.Arguments.Digest || (params.Arguments.Format != "" && params.Arguments.Format != formatJSON) {
+		// Digests and chat messages are short already
+		return result, nil
+	}
+
+	// The summary is trimmed in a copy, since history and the latest
+	// results keep every finding
+	trimmed := *summary
+	trimmed.Results = slices.Clone(summary.Results)
+	results := make([]*LintResult, len(trimmed.Results))
+	for i := range trimmed.Results {
+		results[i] = &trimmed.Results[i]
+	}
+	return fitResult(limit, result, results, func() (*mcp.CallToolResultFor[any], error) {
+		return jsonResult(output(&trimmed))
+	})
+}
+
+func ListRules(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[ListRulesParams]) (*mcp.CallToolResultFor[any], error) {
+	if err := rules.ValidatePacks(params.Arguments.Packs); err != nil {
+		return nil, err
+	}
+	root := settingsFrom(ctx).workDir
+	if params.Arguments.Directory != "" {
+		root = settingsFrom(ctx).path(params.Arguments.Directory)
+	}
+	opts := settingsFrom(ctx).lint
+	opts.Rules = opts.Rules.WithPacks(params.Arguments.Packs...)
+	list, err := linter.New(opts).ListRules(root)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResult(list)
+}
+
+func FindMisplacedWorkflows(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[FindMisplacedWorkflowsParams]) (*mcp.CallToolResultFor[any], error) {
+	root := settingsFrom(ctx).workDir
+	if params.Arguments.Directory != "" {
+		root = settingsFrom(ctx).path(params.Arguments.Directory)
+	}
+
+	found, err := linter.FindMisplacedWorkflows(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", root, err)
+	}
+	if found == nil {
+		found = []linter.MisplacedWorkflow{}
+	}
+
+	return jsonResult(found)
+}
+
+func MoveMisplacedWorkflows(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[MoveMisplacedWorkflowsParams]) (*mcp.CallToolResultFor[any], error) {
+	root := settingsFrom(ctx).workDir
+	if params.Arguments.Directory != "" {
+		root = settingsFrom(ctx).path(params.Arguments.Directory)
+	}
+
+	found, err := linter.FindMisplacedWorkflows(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", root, err)
+	}
+
+	// Only files the search reported may be moved, so the tool cannot be
+	// used to move arbitrary files around.
+	selected := make(map[string]bool, len(params.Arguments.Paths))
+	for _, p := range params.Arguments.Paths {
+		selected[filepath.Clean(settingsFrom(ctx).path(p))] = true
+	}
+
+	moved := []movedWorkflow{}
+	var done []movedWorkflow
+	for _, m := range found {
+		if len(selected) > 0 && !selected[filepath.Clean(m.Path)] {
+			continue
+		}
+		delete(selected, filepath.Clean(m.Path))
+		move := moveWorkflow(m, writeFiles(ctx, params.Arguments.DryRun))
+		moved = append(moved, move)
+		if move.Moved {
+			done = append(done, move)
+		}
+	}
+	if len(done) > 0 {
+		fileOperations.record(session, fileOperation{tool: "move_misplaced_workflows", moves: done})
+	}
+	unknown := make([]string, 0, len(selected))
+	for p := range selected {
+		unknown = append(unknown, p)
+	}
+	sort.Strings(unknown)
+	for _, p := range unknown {
+		moved = append(moved, movedWorkflow{From: p, Skipped: "not a misplaced workflow"})
+	}
+
+	return jsonResult(moved)
+}
+
+func ExtractScript(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[ExtractScriptParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if args.FilePath == "" || args.Job == "" || args.Step == 0 {
+		return nil, fmt.Errorf("file_path, job and step must be provided")
+	}
+
+	extracted, err := linter.ExtractScript(settingsFrom(ctx).path(args.FilePath), args.Job, args.Step)
+	if err != nil {
+		return nil, err
+	}
+
+	out := scriptExtraction{ScriptExtraction: extracted}
+	if writeFiles(ctx, args.DryRun) {
+		if err := linter.WriteChanges(extracted.Changes); err != nil {
+			return nil, err
+		}
+		out.Written = true
+		fileOperations.record(session, fileOperation{tool: "extract_script", changes: extracted.Changes})
+	}
+	return jsonResult(out)
+}
+
+func ExtractCompositeAction(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[ExtractCompositeActionParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if args.FilePath == "" || args.Job == "" || args.FirstStep == 0 || args.Name == "" {
+		return nil, fmt.Errorf("file_path, job, first_step and name must be provided")
+	}
+
+	extracted, err := linter.ExtractCompositeAction(settingsFrom(ctx).path(args.FilePath), args.Job, args.FirstStep, args.LastStep, args.Name, args.Description)
+	if err != nil {
+		return nil, err
+	}
+	results, err := linter.New(settingsFrom(ctx).lint).LintChanges(ctx, extracted.Root, extracted.Changes)
+	if err != nil {
+		return nil, err
+	}
+
+	out := compositeExtraction{CompositeExtraction: extracted, Lint: results}
+	if args.Write && writeFiles(ctx, args.DryRun) {
+		if err := linter.WriteChanges(extracted.Changes); err != nil {
+			return nil, err
+		}
+		out.Written = true
+		fileOperations.record(session, fileOperation{tool: "extract_composite_action", changes: extracted.Changes})
+	}
+	return jsonResult(out)
+}
+
+func FormatWorkflow(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[FormatWorkflowParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	switch {
+	case args.FilePath != "" && args.Content != "":
+		return nil, fmt.Errorf("file_path and content are mutually exclusive; provide only one")
+	case args.FilePath == "" && args.Content == "":
+		return nil, fmt.Errorf("either file_path or content must be provided")
+	case args.FilePath == "" && args.Write:
+		return nil, fmt.Errorf("write requires file_path")
+	}
+
+	path := settingsFrom(ctx).path(args.FilePath)
+	original := []byte(args.Content)
+	name := linter.InlineFileName
+	if path != "" {
+		var err error
+		if original, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		name = path
+	}
+
+	if err := linter.CheckInput(original, settingsFrom(ctx).lint.Limits); err != nil {
+		return nil, err
+	}
+	formatted, err := linter.Format(original)
+	if err != nil {
+		return nil, err
+	}
+
+	out := formattedWorkflow{
+		FilePath: path,
+		Changed:  string(formatted) != string(original),
+		Diff:     linter.UnifiedDiff(name, original, formatted),
+	}
+	write := args.Write && writeFiles(ctx, args.DryRun)
+	if write && out.Changed {
+		changes := []linter.FileChange{{Path: path, Diff: out.Diff, Content: formatted}}
+		if err := linter.WriteChanges(changes); err != nil {
+			return nil, err
+		}
+		out.Written = true
+		out.Backup = changes[0].Backup
+		fileOperations.record(session, fileOperation{tool: "format_workflow", changes: changes})
+	}
+	if !write {
+		out.Formatted = string(formatted)
+	}
+
+	return jsonResult(out)
+}
+
+func ApplyFixes(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[ApplyFixesParams]) (*mcp.CallToolResultFor[any], error) {
+	if params.Arguments.FilePath == "" {
+		return nil, fmt.Errorf("file_path must be provided")
+	}
+
+	fixed, err := linter.New(settingsFrom(ctx).lint).FixFile(ctx, settingsFrom(ctx).path(params.Arguments.FilePath), params.Arguments.Fixes)
+	if err != nil {
+		return nil, err
+	}
+
+	out := fixResult{FixResult: fixed}
+	if len(fixed.Changes) > 0 && writeFiles(ctx, params.Arguments.DryRun) {
+		if err := linter.WriteChanges(fixed.Changes); err != nil {
+			return nil, err
+		}
+		out.Written = true
+		out.Backup = fixed.Changes[0].Backup
+		fileOperations.record(session, fileOperation{tool: "apply_fixes", changes: fixed.Changes})
+	}
+	return jsonResult(out)
+}
+
+func UndoFixes(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[UndoFixesParams]) (*mcp.CallToolResultFor[any], error) {
+	undone, err := fileOperations.undo(session)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonResult(undone)
+}
+
+func LintPatch(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[LintPatchParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	switch {
+	case args.Patch == "":
+		return nil, fmt.Errorf("patch must be provided")
+	case args.FilePath != "" && args.Base != "":
+		return nil, fmt.Errorf("file_path and base are mutually exclusive; provide only one")
+	case args.FilePath != "" && args.Filename != "":
+		return nil, fmt.Errorf("filename only applies to base; use file_path alone to patch a file")
+	}
+	if err := checkResultFormatVersion(args.ResultFormatVersion); err != nil {
+		return nil, err
+	}
+	limit, err := responseLimit(args.MaxBytes, args.MaxResponseTokens)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := settingsFrom(ctx).lint.Limits
+	path := linter.CleanPath(args.Filename)
+	base := []byte(args.Base)
+	if args.FilePath != "" {
+		path = settingsFrom(ctx).path(args.FilePath)
+		var err error
+		base, err = linter.ReadInput(path, limits)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+	if err := linter.CheckInput(base, limits); err != nil {
+		return nil, err
+	}
+
+	patched, changed, err := linter.ApplyPatch(base, args.Patch)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := linter.New(settingsFrom(ctx).lint).Lint(ctx, linter.Input{Path: path, Content: patched})
+	if err != nil {
+		return nil, err
+	}
+	unchanged := linter.OnlyLines(result, changed)
+
+	out := patchResult{
+		LintResult:        result,
+		UnchangedFindings: unchanged,
+		SecurityChanges:   linter.SecurityChanges(base, patched),
+	}
+	return fittedResult(limit, out, result)
+}
+
+func DryRunWorkflow(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[DryRunWorkflowParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if args.FilePath == "" {
+		return nil, fmt.Errorf("file_path must be provided")
+	}
+	if args.Payload != "" && !json.Valid([]byte(args.Payload)) {
+		return nil, fmt.Errorf("payload is not valid JSON")
+	}
+	if err := checkResultFormatVersion(args.ResultFormatVersion); err != nil {
+		return nil, err
+	}
+	limit, err := responseLimit(args.MaxBytes, args.MaxResponseTokens)
+	if err != nil {
+		return nil, err
+	}
+	path := settingsFrom(ctx).path(args.FilePath)
+
+	result, err := linter.New(settingsFrom(ctx).lint).Lint(ctx, linter.Input{Path: path})
+	if err != nil {
+		return nil, err
+	}
+
+	act, findings, err := linter.RunAct(ctx, path, linter.ActOptions{
+		Command: settingsFrom(ctx).actCommand,
+		Event:   args.Event,
+		Payload: []byte(args.Payload),
+		DryRun:  args.RunAct,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Errors = append(result.Errors, findings...)
+	result.Valid = len(result.Errors) == 0
+
+	return fittedResult(limit, dryRunResult{LintResult: result, Act: act}, result)
+}
+
+func SimulateTrigger(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[SimulateTriggerParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if args.Event == "" {
+		return nil, fmt.Errorf("event must be provided")
+	}
+	payload := map[string]any{}
+	if args.Payload != "" {
+		if err := json.Unmarshal([]byte(args.Payload), &payload); err != nil {
+			return nil, fmt.Errorf("payload is not a JSON object: %w", err)
+		}
+	}
+	directory := settingsFrom(ctx).path(linter.WorkflowsDir)
+	if args.Directory != "" {
+		directory = settingsFrom(ctx).path(args.Directory)
+	}
+
+	files, err := linter.FindWorkflowFiles(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no workflow files found in %s", directory)
+	}
+
+	results, err := linter.SimulateTrigger(files, linter.TriggerEvent{
+		Name:         args.Event,
+		Payload:      payload,
+		ChangedFiles: args.ChangedFiles,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jsonResult(results)
+}
+
+func WorkflowCoverage(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[WorkflowCoverageParams]) (*mcp.CallToolResultFor[any], error) {
+	root := settingsFrom(ctx).workDir
+	if params.Arguments.Directory != "" {
+		root = settingsFrom(ctx).path(params.Arguments.Directory)
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	coverage, err := linter.CheckCoverage(root)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResult(coverage)
+}
+
+func FindOrphanedWorkflows(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[FindOrphanedWorkflowsParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	directory := settingsFrom(ctx).path(linter.WorkflowsDir)
+	if args.Directory != "" {
+		directory = settingsFrom(ctx).path(args.Directory)
+	}
+
+	files, err := linter.FindWorkflowFiles(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no workflow files found in %s", directory)
+	}
+
+	// Each source of facts is optional: the checks needing a missing one
+	// are skipped and reported as such.
+	var facts linter.RepositoryFacts
+	out := orphanedWorkflows{Sources: []string{}}
+	if refs, err := linter.ListGitRefs(ctx, directory); err != nil {
+		out.Skipped = append(out.Skipped, fmt.Sprintf("branch filters were not checked: %v", err))
+	} else {
+		facts.Branches = refs.Branches
+		out.Sources = append(out.Sources, "git")
+	}
+	repo, err := githubRepository(ctx, args.Repository)
+	switch {
+	case err != nil:
+		out.Skipped = append(out.Skipped, "release and deployment events were not checked: pass repository, or set GITHUB_REPOSITORY")
+	case settingsFrom(ctx).githubToken == "":
+		out.Skipped = append(out.Skipped, fmt.Sprintf("release and deployment events were not checked: set GITHUB_TOKEN to read %s", repo))
+	default:
+		github, err := fetchRepositoryFacts(ctx, http.DefaultClient, repo)
+		if err != nil {
+			return nil, err
+		}
+		facts.Template, facts.NoDeployments = github.Template, github.NoDeployments
+		out.Sources = append(out.Sources, "github:"+repo)
+	}
+
+	if out.Workflows, err = linter.FindOrphanedWorkflows(files, facts); err != nil {
+		return nil, err
+	}
+	return jsonResult(out)
+}
+
+func WorkflowFlakiness(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[WorkflowFlakinessParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if settingsFrom(ctx).githubToken == "" {
+		return nil, fmt.Errorf("workflow_flakiness needs a GitHub token in GITHUB_TOKEN to read workflow runs")
+	}
+	repo, err := githubRepository(ctx, args.Repository)
+	if err != nil {
+		return nil, err
+	}
+	limit := args.Runs
+	switch {
+	case limit <= 0:
+		limit = defaultRunHistory
+	case limit > maxRunHistory:
+		limit = maxRunHistory
+	}
+	directory := settingsFrom(ctx).path(linter.WorkflowsDir)
+	if args.Directory != "" {
+		directory = settingsFrom(ctx).path(args.Directory)
+	}
+
+	files, err := linter.FindWorkflowFiles(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no workflow files found in %s", directory)
+	}
+
+	l := linter.New(settingsFrom(ctx).lint)
+	reports := make([]flakinessReport, 0, len(files))
+	for _, path := range files {
+		reports = append(reports, workflowFlakiness(ctx, l, repo, path, limit))
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].FailureRate > reports[j].FailureRate
+	})
+	return jsonResult(reports)
+}
+
+// workflowFlakiness builds the flakiness report of one workflow file.
+func workflowFlakiness(ctx context.Context, l *linter.Linter, repo, path string, limit int) flakinessReport {
+	report := flakinessReport{FilePath: path}
+	history, err := fetchRunHistory(ctx, http.DefaultClient, repo, path, limit)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	report.History, report.FailureRate = history, history.FailureRate()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	if report.Hints, err = linter.FlakinessHints(content, history); err != nil {
+		report.Error = err.Error()
+	}
+	if result, err := l.Lint(ctx, linter.Input{Path: path, Content: content}); err == nil {
+		report.Findings = len(result.Errors)
+	}
+	return report
+}
+
+func CheckVariables(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[CheckVariablesParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+
+	var files []string
+	if args.FilePath != "" {
+		files = []string{settingsFrom(ctx).path(args.FilePath)}
+	} else {
+		directory := settingsFrom(ctx).path(linter.WorkflowsDir)
+		if args.Directory != "" {
+			directory = settingsFrom(ctx).path(args.Directory)
+		}
+		var err error
+		if files, err = linter.FindWorkflowFiles(directory); err != nil {
+			return nil, fmt.Errorf("failed to read directory: %w", err)
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no workflow files found in %s", directory)
+		}
+	}
+
+	variables, source := args.Variables, "provided"
+	if variables == nil {
+		repo, err := githubRepository(ctx, args.Repository)
+		if err != nil {
+			return nil, err
+		}
+		if settingsFrom(ctx).githubToken == "" {
+			return nil, fmt.Errorf("pass variables, or set GITHUB_TOKEN to fetch the variables of %s", repo)
+		}
+		if variables, err = fetchVariables(ctx, http.DefaultClient, repo); err != nil {
+			return nil, err
+		}
+		source = "github:" + repo
+	}
+
+	opts := settingsFrom(ctx).lint
+	opts.Rules.Variables = variables
+	summary := linter.New(opts).LintFiles(ctx, files)
+	summary.KeepKinds(rules.KindUndefinedVariable)
+
+	return jsonResult(variablesResult{Variables: variables, Source: source, Summary: summary})
+}
+
+func CheckLabels(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[CheckLabelsParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+
+	var files []string
+	if args.FilePath != "" {
+		files = []string{settingsFrom(ctx).path(args.FilePath)}
+	} else {
+		directory := settingsFrom(ctx).path(linter.WorkflowsDir)
+		if args.Directory != "" {
+			directory = settingsFrom(ctx).path(args.Directory)
+		}
+		var err error
+		if files, err = linter.FindWorkflowFiles(directory); err != nil {
+			return nil, fmt.Errorf("failed to read directory: %w", err)
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no workflow files found in %s", directory)
+		}
+	}
+
+	labels, source := args.Labels, "provided"
+	if labels == nil {
+		repo, err := githubRepository(ctx, args.Repository)
+		if err != nil {
+			return nil, err
+		}
+		if settingsFrom(ctx).githubToken == "" {
+			return nil, fmt.Errorf("pass labels, or set GITHUB_TOKEN to fetch the labels of %s", repo)
+		}
+		if labels, err = fetchLabels(ctx, http.DefaultClient, repo); err != nil {
+			return nil, err
+		}
+		source = "github:" + repo
+	}
+
+	opts := settingsFrom(ctx).lint
+	opts.Rules.Labels = labels
+	summary := linter.New(opts).LintFiles(ctx, files)
+	summary.KeepKinds(rules.KindUndefinedLabel)
+
+	return jsonResult(labelsResult{Labels: labels, Source: source, Summary: summary})
+}
+
+func ProvisioningChecklist(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[ProvisioningChecklistParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+
+	var files []string
+	if args.FilePath != "" {
+		files = []string{settingsFrom(ctx).path(args.FilePath)}
+	} else {
+		directory := settingsFrom(ctx).path(linter.WorkflowsDir)
+		if args.Directory != "" {
+			directory = settingsFrom(ctx).path(args.Directory)
+		}
+		var err error
+		if files, err = linter.FindWorkflowFiles(directory); err != nil {
+			return nil, fmt.Errorf("failed to read directory: %w", err)
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no workflow files found in %s", directory)
+		}
+	}
+
+	provisioning, err := linter.ProvisioningChecklist(files)
+	if err != nil {
+		return nil, err
+	}
+	result := provisioningResult{Provisioning: provisioning}
+	if args.Compare {
+		repo, err := githubRepository(ctx, args.Repository)
+		if err != nil {
+			return nil, err
+		}
+		if settingsFrom(ctx).githubToken == "" {
+			return nil, fmt.Errorf("set GITHUB_TOKEN to compare with the settings of %s", repo)
+		}
+		settings, err := fetchSettings(ctx, http.DefaultClient, repo, provisioning.Environments())
+		if err != nil {
+			return nil, err
+		}
+		provisioning.Compare(settings)
+		result.Source = "github:" + repo
+	}
+	return jsonResult(result)
+}
+
+func LintFromURL(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[LintFromURLParams]) (*mcp.CallToolResultFor[any], error) {
+	if params.Arguments.URL == "" {
+		return nil, fmt.Errorf("url is required")
+	}
+	if err := checkResultFormatVersion(params.Arguments.ResultFormatVersion); err != nil {
+		return nil, err
+	}
+	limit, err := responseLimit(params.Arguments.MaxBytes, params.Arguments.MaxResponseTokens)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := rawWorkflowURL(params.Arguments.URL)
+	if err != nil {
+		return nil, err
+	}
+	content, err := fetchWorkflow(ctx, http.DefaultClient, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	// The content is linted as unnamed content, since the repository it
+	// belongs to is not available
+	result, err := linter.New(settingsFrom(ctx).lint).Lint(ctx, linter.Input{Content: content})
+	if err != nil {
+		return nil, err
+	}
+	result.FilePath = params.Arguments.URL
+	return fittedResult(limit, result, result)
+}
+
+func CheckTemplateDrift(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[CheckTemplateDriftParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	cfg := settingsFrom(ctx).config.Templates
+	if args.Templates != "" || args.Repository != "" {
+		cfg = templatesConfig{Path: settingsFrom(ctx).path(args.Templates), Repository: args.Repository, Ref: args.Ref}
+	} else if args.Ref != "" {
+		cfg.Ref = args.Ref
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	var templates map[string][]byte
+	var source string
+	var err error
+	switch {
+	case cfg.Path != "":
+		templates, err = loadTemplates(cfg.Path)
+		source = cfg.Path
+	case cfg.Repository != "":
+		if _, err := githubRepository(ctx, cfg.Repository); err != nil {
+			return nil, err
+		}
+		dir := cfg.Directory
+		if dir == "" {
+			dir = defaultTemplatesDirectory
+		}
+		source = "github:" + cfg.Repository + "/" + dir
+		if cfg.Ref != "" {
+			source += "@" + cfg.Ref
+		}
+		templates, err = fetchTemplates(ctx, http.DefaultClient, cfg.Repository, dir, cfg.Ref)
+	default:
+		return nil, fmt.Errorf("pass templates or repository, or configure templates in the config file")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("no templates found in %s", source)
+	}
+
+	directory := settingsFrom(ctx).path(linter.WorkflowsDir)
+	if args.Directory != "" {
+		directory = settingsFrom(ctx).path(args.Directory)
+	}
+	files, err := linter.FindWorkflowFiles(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	report := compareTemplates(files, templates)
+	report.Templates = source
+	return jsonResult(report)
+}
+
+// githubRepository returns repo, or GITHUB_REPOSITORY when it is empty,
+// checking that it has the owner/name form.
+func githubRepository(ctx context.Context, repo string) (string, error) {
+	if repo == "" {
+		repo = settingsFrom(ctx).githubRepository
+	}
+	if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", fmt.Errorf("repository must be given as owner/name")
+	}
+	return repo, nil
+}
+
+// moveWorkflow moves m to its suggested path, refusing to overwrite a file
+// that is already there. Without write it only reports where m would go.
+func moveWorkflow(m linter.MisplacedWorkflow, write bool) movedWorkflow {
+	if _, err := os.Lstat(m.SuggestedPath); err == nil {
+		return movedWorkflow{From: m.Path, Skipped: fmt.Sprintf("%s already exists", m.SuggestedPath)}
+	}
+	if !write {
+		return movedWorkflow{From: m.Path, To: m.SuggestedPath}
+	}
+	if err := os.MkdirAll(filepath.Dir(m.SuggestedPath), 0755); err != nil {
+		return movedWorkflow{From: m.Path, Skipped: err.Error()}
+	}
+	if err := os.Rename(m.Path, m.SuggestedPath); err != nil {
+		return movedWorkflow{From: m.Path, Skipped: err.Error()}
+	}
+	return movedWorkflow{From: m.Path, To: m.SuggestedPath, Moved: true}
+}
+
+// writeFiles reports whether a tool may write its changes: the server
+// runs with -allow-writes and the call is not a dry run. Without it the
+// tools that change files run as dry runs, returning the changes without
+// writing them.
+func writeFiles(ctx context.Context, dryRun bool) bool {
+	return settingsFrom(ctx).allowWrites && !dryRun
+}
+
+// resultFormatVersions are the versions of the result format the tools can
+// produce, for their result_format_version parameter.
+var resultFormatVersions = []int{linter.SchemaVersion}
+
+// checkFailOn checks a fail_on parameter, which takes the levels of the
+// lint command's -fail-level.
+func checkFailOn(level string) error {
+	if level == "" || slices.Contains(failLevels, level) {
+		return nil
+	}
+	return fmt.Errorf("unknown fail_on %q; use error, warning or info", level)
+}
+
+// checkResultFormatVersion returns an error when a client asks for a result
+// format the server cannot produce. Zero asks for the current one.
+func checkResultFormatVersion(v int) error {
+	if v == 0 || slices.Contains(resultFormatVersions, v) {
+		return nil
+	}
+	return fmt.Errorf("result_format_version %d is not supported; this server produces version %d", v, linter.SchemaVersion)
+}
+
+// jsonResult renders v as indented JSON text content, as a
+// versionedOutput, with the schema_version also set in the result's
+// _meta.
+func jsonResult(v any) (*mcp.CallToolResultFor[any], error) {
+	resultJSON, err := json.MarshalIndent(versionedOutput{v}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return versionedResult(string(resultJSON)), nil
+}
+
+// versionedOutput is the output of a tool, as an object led by the
+// schema_version of the result format: the members of the output when it
+// is an object, or else the output as items, as for a list. An output
+// with a schema_version of its own, as the results of the linter have,
+// keeps it.
+type versionedOutput struct {
+	output any
+}
+
+func (o versionedOutput) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(o.output)
+	if err != nil {
+		return nil, err
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil || members == nil {
+		return json.Marshal(struct {
+			SchemaVersion int             `json:"schema_version"`
+			Items         json.RawMessage `json:"items"`
+		}{linter.SchemaVersion, data})
+	}
+	if _, ok := members["schema_version"]; ok {
+		return data, nil
+	}
+	version, err := json.Marshal(struct {
+		SchemaVersion int `json:"schema_version"`
+	}{linter.SchemaVersion})
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return version, nil
+	}
+	// Both are objects, so the members of the output follow the version
+	return append(append(version[:len(version)-1], ','), data[1:]...), nil
+}
+
+// payloadResult renders v, a message in another service's format such as a
+// Slack message, as indented JSON text content that is left as it is, so
+// its schema version is only in the result's _meta.
+func payloadResult(v any) (*mcp.CallToolResultFor[any], error) {
+	resultJSON, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return versionedResult(string(resultJSON)), nil
+}
+
+func versionedResult(text string) *mcp.CallToolResultFor[any] {
+	return &mcp.CallToolResultFor[any]{
+		Meta: mcp.Meta{"schema_version": linter.SchemaVersion},
+		Content: []mcp.Content{
+			&mcp.TextContent{
+				Text: text,
+			},
+		},
+	}
+}
+
+func LintTrends(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[LintTrendsParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if settingsFrom(ctx).config.History.Path == "" {
+		return nil, fmt.Errorf("lint history is not recorded; set history.path in the config file")
+	}
+	if args.Days < 0 {
+		return nil, fmt.Errorf("days must not be negative")
+	}
+	days := args.Days
+	if days == 0 {
+		days = defaultTrendDays
+	}
+
+	directory := settingsFrom(ctx).path(linter.WorkflowsDir)
+	if args.Directory != "" {
+		directory = settingsFrom(ctx).path(args.Directory)
+	}
+
+	h, err := openHistory(ctx, settingsFrom(ctx).config.History.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer h.Close()
+
+	since := time.Now().AddDate(0, 0, -days)
+	report, err := h.trends(ctx, historyRepository(directory), args.Rule, since)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResult(report)
+}
+
+func SuggestPermissions(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[SuggestPermissionsParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	switch {
+	case args.FilePath != "" && args.Content != "":
+		return nil, fmt.Errorf("file_path and content are mutually exclusive; provide only one")
+	case args.FilePath == "" && args.Content == "":
+		return nil, fmt.Errorf("either file_path or content must be provided")
+	}
+
+	path := settingsFrom(ctx).path(args.FilePath)
+	content := []byte(args.Content)
+	name := linter.InlineFileName
+	if path != "" {
+		var err error
+		if content, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		name = path
+	}
+
+	if err := linter.CheckInput(content, settingsFrom(ctx).lint.Limits); err != nil {
+		return nil, err
+	}
+	jobs, err := linter.SuggestPermissions(content, name, settingsFrom(ctx).config.Rules)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResult(permissionsSuggestion{FilePath: path, Jobs: jobs})
+}
+
+func InferWorkflowCall(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[InferWorkflowCallParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	switch {
+	case args.FilePath != "" && args.Content != "":
+		return nil, fmt.Errorf("file_path and content are mutually exclusive; provide only one")
+	case args.FilePath == "" && args.Content == "":
+		return nil, fmt.Errorf("either file_path or content must be provided")
+	case args.Job == "":
+		return nil, fmt.Errorf("job is required")
+	}
+
+	content := []byte(args.Content)
+	if args.FilePath != "" {
+		var err error
+		if content, err = os.ReadFile(settingsFrom(ctx).path(args.FilePath)); err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+
+	if err := linter.CheckInput(content, settingsFrom(ctx).lint.Limits); err != nil {
+		return nil, err
+	}
+	call, err := linter.InferWorkflowCall(content, args.Job)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResult(call)
+}
+
+func SuggestUpdatesForAction(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[SuggestUpdatesForActionParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if args.Action == "" {
+		return nil, fmt.Errorf("action is required")
+	}
+	directory := settingsFrom(ctx).path(linter.WorkflowsDir)
+	if args.Directory != "" {
+		directory = settingsFrom(ctx).path(args.Directory)
+	}
+
+	files, err := linter.FindWorkflowFiles(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find workflow files: %w", err)
+	}
+	updates, err := linter.SuggestActionUpdates(args.Action, files)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResult(updates)
+}
+
+func GenerateTestFixtures(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[GenerateTestFixturesParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if args.Directory == "" {
+		return nil, fmt.Errorf("directory is required")
+	}
+
+	changes, fixtures, err := linter.FixtureChanges(settingsFrom(ctx).path(args.Directory), args.Kinds)
+	if err != nil {
+		return nil, err
+	}
+	out := generatedFixtures{Fixtures: fixtures, Changes: changes}
+	if writeFiles(ctx, args.DryRun) {
+		if err := linter.WriteChanges(changes); err != nil {
+			return nil, err
+		}
+		out.Written = true
+		fileOperations.record(session, fileOperation{tool: "generate_test_fixtures", changes: changes})
+	}
+	return jsonResult(out)
+}
+
+func AuditRepositories(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[AuditRepositoriesParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	if len(args.Paths) == 0 && args.Organization == "" {
+		return nil, fmt.Errorf("either paths or organization must be provided")
+	}
+	concurrency := args.Concurrency
+	switch {
+	case concurrency < 0 || concurrency > maxAuditConcurrency:
+		return nil, fmt.Errorf("concurrency must be between 1 and %d", maxAuditConcurrency)
+	case concurrency == 0:
+		concurrency = defaultAuditConcurrency
+	}
+
+	var targets []auditTarget
+	for _, p := range args.Paths {
+		targets = append(targets, auditTarget{path: settingsFrom(ctx).path(p)})
+	}
+	if args.Organization != "" {
+		repos, err := listOrganizationRepositories(ctx, http.DefaultClient, githubAPIBaseURL, args.Organization, args.IncludeArchived)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range repos {
+			targets = append(targets, auditTarget{repo: r})
+		}
+	}
+
+	report := auditRepositories(ctx, linter.New(settingsFrom(ctx).lint), http.DefaultClient, targets, concurrency, args.Details)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return jsonResult(report)
+}
+
+func SyncActionMetadata(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[SyncActionMetadataParams]) (*mcp.CallToolResultFor[any], error) {
+	args := params.Arguments
+	orgs := args.Organizations
+	if len(orgs) == 0 {
+		orgs = settingsFrom(ctx).config.ActionMetadata.Organizations
+	}
+	if len(orgs) == 0 {
+		return nil, fmt.Errorf("pass organizations, or set action-metadata.organizations in the config file")
+	}
+	root := settingsFrom(ctx).workDir
+	if args.Directory != "" {
+		root = settingsFrom(ctx).path(args.Directory)
+	}
+	file, err := actionMetadataFile(ctx, root, args.File)
+	if err != nil {
+		return nil, err
+	}
+	if settingsFrom(ctx).githubToken == "" {
+		return nil, fmt.Errorf("sync_action_metadata needs a GitHub token in GITHUB_TOKEN to read the repositories of %s", strings.Join(orgs, ", "))
+	}
+
+	crawled, err := crawlActionMetadata(ctx, http.DefaultClient, settingsFrom(ctx).config.ActionMetadata.apiURL(), orgs, args.IncludeArchived, defaultAuditConcurrency)
+	if err != nil {
+		return nil, err
+	}
+	change, removed, err := actionMetadataChange(file, orgs, crawled)
+	if err != nil {
+		return nil, err
+	}
+
+	out := syncedActionMetadata{
+		File:          file,
+		Organizations: orgs,
+		Repositories:  len(crawled.repositories),
+		Actions:       make([]string, 0, len(crawled.actions)),
+		Removed:       removed,
+		Failed:        crawled.failed,
+		Changes:       []linter.FileChange{},
+	}
+	for spec := range crawled.actions {
+		out.Actions = append(out.Actions, spec)
+	}
+	sort.Strings(out.Actions)
+	if change.Diff != "" {
+		out.Changes = append(out.Changes, change)
+	}
+	if len(out.Changes) > 0 && writeFiles(ctx, args.DryRun) {
+		if err := linter.WriteChanges(out.Changes); err != nil {
+			return nil, err
+		}
+		out.Written = true
+		fileOperations.record(session, fileOperation{tool: "sync_action_metadata", changes: out.Changes})
+	}
+	return jsonResult(out)
+}
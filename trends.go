@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+)
+
+// defaultTrendDays is how far back lint_trends looks by default.
+const defaultTrendDays = 90
+
+// historyConfig enables recording the findings of check_all_workflows
+// runs in a SQLite database, which lint_trends reads.
+type historyConfig struct {
+	Path string `yaml:"path"`
+}
+
+const historySchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id          INTEGER PRIMARY KEY,
+	repository  TEXT    NOT NULL,
+	recorded_at INTEGER NOT NULL,
+	files       INTEGER NOT NULL,
+	findings    INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS runs_repository ON runs (repository, recorded_at);
+CREATE TABLE IF NOT EXISTS rule_counts (
+	run_id   INTEGER NOT NULL REFERENCES runs (id) ON DELETE CASCADE,
+	rule     TEXT    NOT NULL,
+	findings INTEGER NOT NULL,
+	PRIMARY KEY (run_id, rule)
+);
+`
+
+// historyStore is the database of recorded runs.
+type historyStore struct {
+	db *sql.DB
+}
+
+// openHistory opens the database at path, creating it and its tables when
+// missing. Several servers may share one database, so writers wait for
+// each other instead of failing.
+func openHistory(ctx context.Context, path string) (*historyStore, error) {
+	db, err := sql.Open("sqlite", "file:"+filepath.ToSlash(path)+"?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, historySchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open history %s: %w", path, err)
+	}
+	return &historyStore{db: db}, nil
+}
+
+func (h *historyStore) Close() error {
+	return h.db.Close()
+}
+
+// historyRepository is the key runs of the workflows in directory are
+// recorded under: the absolute path of their repository.
+func historyRepository(directory string) string {
+	abs, err := filepath.Abs(directory)
+	if err != nil {
+		abs = filepath.Clean(directory)
+	}
+	if strings.HasSuffix(abs, string(filepath.Separator)+linter.WorkflowsDir) {
+		return filepath.Dir(filepath.Dir(abs))
+	}
+	return abs
+}
+
+// record stores the number of findings of each rule in summary.
+func (h *historyStore) record(ctx context.Context, repository string, at time.Time, summary *linter.Summary) error {
+	counts := map[string]int{}
+	for _, r := range summary.Results {
+		for _, e := range r.Errors {
+			counts[e.Kind]++
+		}
+	}
+
+	tx, err := h.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to record run: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO runs (repository, recorded_at, files, findings) VALUES (?, ?, ?, ?)`,
+		repository, at.Unix(), summary.TotalFiles, summary.TotalErrors)
+	if err != nil {
+		return fmt.Errorf("failed to record run: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to record run: %w", err)
+	}
+	for rule, n := range counts {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO rule_counts (run_id, rule, findings) VALUES (?, ?, ?)`, id, rule, n); err != nil {
+			return fmt.Errorf("failed to record run: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to record run: %w", err)
+	}
+	return nil
+}
+
+// trendPoint is one recorded run. Rules holds the findings per rule, or
+// only those of the requested rule.
+type trendPoint struct {
+	RecordedAt time.Time      `json:"recorded_at"`
+	Files      int            `json:"files"`
+	Findings   int            `json:"findings"`
+	Rules      map[string]int `json:"rules"`
+}
+
+// trendReport is the lint_trends output. Change is the difference between
+// the findings of the last and first run, of the requested rule if any, so
+// a negative value means the debt went down.
+type trendReport struct {
+	Repository string       `json:"repository"`
+	Rule       string       `json:"rule,omitempty"`
+	Since      time.Time    `json:"since"`
+	Points     []trendPoint `json:"points"`
+	Change     int          `json:"change"`
+}
+
+// trends returns the runs of repository recorded since the given time,
+// oldest first.
+func (h *historyStore) trends(ctx context.Context, repository, rule string, since time.Time) (trendReport, error) {
+	report := trendReport{Repository: repository, Rule: rule, Since: since.UTC(), Points: []trendPoint{}}
+
+	rows, err := h.db.QueryContext(ctx, `
+		SELECT r.id, r.recorded_at, r.files, r.findings, c.rule, c.findings
+		FROM runs r LEFT JOIN rule_counts c ON c.run_id = r.id AND (? = '' OR c.rule = ?)
+		WHERE r.repository = ? AND r.recorded_at >= ?
+		ORDER BY r.recorded_at, r.id`,
+		rule, rule, repository, since.Unix())
+	if err != nil {
+		return report, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer rows.Close()
+
+	lastID := int64(-1)
+	for rows.Next() {
+		var id, at int64
+		var files, findings int
+		var ruleName sql.NullString
+		var count sql.NullInt64
+		if err := rows.Scan(&id, &at, &files, &findings, &ruleName, &count); err != nil {
+			return report, fmt.Errorf("failed to read history: %w", err)
+		}
+		if id != lastID {
+			lastID = id
+			report.Points = append(report.Points, trendPoint{
+				RecordedAt: time.Unix(at, 0).UTC(),
+				Files:      files,
+				Findings:   findings,
+				Rules:      map[string]int{},
+			})
+			if rule != "" {
+				report.Points[len(report.Points)-1].Rules[rule] = 0
+			}
+		}
+		if ruleName.Valid {
+			report.Points[len(report.Points)-1].Rules[ruleName.String] = int(count.Int64)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return report, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	if n := len(report.Points); n > 1 {
+		first, last := report.Points[0], report.Points[n-1]
+		if rule != "" {
+			report.Change = last.Rules[rule] - first.Rules[rule]
+		} else {
+			report.Change = last.Findings - first.Findings
+		}
+	}
+	return report, nil
+}
+
+// recordHistory records summary when history is enabled. Failing to record
+// does not fail the lint run, so errors are only logged.
+func recordHistory(ctx context.Context, directory string, summary *linter.Summary) {
+	cfg := settingsFrom(ctx).config.History
+	if cfg.Path == "" {
+		return
+	}
+	h, err := openHistory(ctx, cfg.Path)
+	if err == nil {
+		err = h.record(ctx, historyRepository(directory), time.Now(), summary)
+		h.Close()
+	}
+	if err != nil {
+		log.Printf("lint history: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func summaryWith(kinds ...string) *linter.Summary {
+	result := linter.LintResult{FilePath: "ci.yml"}
+	for _, k := range kinds {
+		result.Errors = append(result.Errors, linter.LintError{Kind: k})
+	}
+	return &linter.Summary{TotalFiles: 1, TotalErrors: len(kinds), Results: []linter.LintResult{result}}
+}
+
+func TestHistoryStore(t *testing.T) {
+	ctx := context.Background()
+	h, err := openHistory(ctx, filepath.Join(t.TempDir(), "history.db"))
+	require.NoError(t, err)
+	defer h.Close()
+
+	now := time.Now()
+	require.NoError(t, h.record(ctx, "/repo", now.AddDate(0, 0, -100), summaryWith("expression")))
+	require.NoError(t, h.record(ctx, "/repo", now.AddDate(0, 0, -10), summaryWith("shellcheck", "shellcheck", "expression")))
+	require.NoError(t, h.record(ctx, "/repo", now.AddDate(0, 0, -1), summaryWith("shellcheck")))
+	require.NoError(t, h.record(ctx, "/other", now, summaryWith("expression")))
+
+	report, err := h.trends(ctx, "/repo", "", now.AddDate(0, 0, -90))
+	require.NoError(t, err)
+	require.Len(t, report.Points, 2)
+	assert.Equal(t, map[string]int{"shellcheck": 2, "expression": 1}, report.Points[0].Rules)
+	assert.Equal(t, 3, report.Points[0].Findings)
+	assert.Equal(t, map[string]int{"shellcheck": 1}, report.Points[1].Rules)
+	assert.Equal(t, -2, report.Change)
+
+	report, err = h.trends(ctx, "/repo", "expression", now.AddDate(0, 0, -90))
+	require.NoError(t, err)
+	require.Len(t, report.Points, 2)
+	assert.Equal(t, map[string]int{"expression": 0}, report.Points[1].Rules)
+	assert.Equal(t, 1, report.Points[1].Findings)
+	assert.Equal(t, -1, report.Change)
+}
+
+func TestHistoryRepository(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, dir, historyRepository(filepath.Join(dir, ".github", "workflows")))
+	assert.Equal(t, filepath.Join(dir, "ci"), historyRepository(filepath.Join(dir, "ci")))
+}
+
+func TestLintTrends(t *testing.T) {
+	repo := t.TempDir()
+	dir := filepath.Join(repo, ".github", "workflows")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ci.yml"), []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ github.undefined }}\n"), 0644))
+
+	call := func(args LintTrendsParams) (trendReport, error) {
+		t.Helper()
+		var out trendReport
+		result, err := LintTrends(context.Background(), nil, &mcp.CallToolParamsFor[LintTrendsParams]{Arguments: args})
+		if err == nil {
+			require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out))
+		}
+		return out, err
+	}
+
+	useSettings(t, serverConfig{}, false)
+	_, err := call(LintTrendsParams{Directory: dir})
+	assert.ErrorContains(t, err, "history.path")
+
+	// Runs of check_all_workflows are recorded when history is enabled
+	useSettings(t, serverConfig{History: historyConfig{Path: filepath.Join(t.TempDir(), "history.db")}}, false)
+	for range 2 {
+		_, err := CheckAllWorkflows(context.Background(), nil, &mcp.CallToolParamsFor[CheckAllWorkflowsParams]{Arguments: CheckAllWorkflowsParams{Directory: dir}})
+		require.NoError(t, err)
+	}
+
+	report, err := call(LintTrendsParams{Directory: dir, Rule: "expression"})
+	require.NoError(t, err)
+	assert.Equal(t, repo, report.Repository)
+	require.Len(t, report.Points, 2)
+	assert.Equal(t, 1, report.Points[1].Rules["expression"])
+	assert.Equal(t, 0, report.Change)
+
+	_, err = call(LintTrendsParams{Days: -1})
+	assert.ErrorContains(t, err, "days")
+}
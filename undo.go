@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxUndo is how many write operations undo_fixes can revert per session.
+const maxUndo = 20
+
+// fileOperation is a write made by one call of a fixer tool: the changes
+// it wrote, with their backups, or the workflows it moved.
+type fileOperation struct {
+	tool    string
+	changes []linter.FileChange
+	moves   []movedWorkflow
+}
+
+// undoJournal keeps the write operations of each session, most recent
+// last, for undo_fixes, until the session ends.
+type undoJournal struct {
+	mu       sync.Mutex
+	ops      map[*mcp.ServerSession][]fileOperation
+	sessions sessionKeys
+}
+
+// fileOperations is the journal of the tools' write operations.
+var fileOperations = &undoJournal{ops: map[*mcp.ServerSession][]fileOperation{}}
+
+// record adds op to the operations of session, forgetting the oldest one
+// past maxUndo.
+func (j *undoJournal) record(session *mcp.ServerSession, op fileOperation) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	ops := append(j.ops[session], op)
+	if len(ops) > maxUndo {
+		ops = ops[len(ops)-maxUndo:]
+	}
+	j.ops[session] = ops
+	for _, old := range j.sessions.use(session) {
+		delete(j.ops, old)
+	}
+}
+
+// forget drops the operations of session.
+func (j *undoJournal) forget(session *mcp.ServerSession) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.ops, session)
+	j.sessions.forget(session)
+}
+
+// undo reverts the last operation of session and forgets it. The operation
+// is kept when a file it wrote changed since, so that undo never discards
+// edits made after it.
+func (j *undoJournal) undo(session *mcp.ServerSession) (*undoneOperation, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	ops := j.ops[session]
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("no file changes to undo in this session")
+	}
+	op := ops[len(ops)-1]
+	if err := op.check(); err != nil {
+		return nil, fmt.Errorf("cannot undo %s: %w", op.tool, err)
+	}
+
+	out := &undoneOperation{Tool: op.tool, Restored: []string{}, Remaining: len(ops) - 1}
+	for i := len(op.moves) - 1; i >= 0; i-- {
+		m := op.moves[i]
+		if err := os.Rename(m.To, m.From); err != nil {
+			return nil, fmt.Errorf("cannot undo %s: %w", op.tool, err)
+		}
+		out.Restored = append(out.Restored, m.From)
+	}
+	if err := linter.RestoreChanges(op.changes); err != nil {
+		return nil, fmt.Errorf("cannot undo %s: %w", op.tool, err)
+	}
+	for _, c := range op.changes {
+		if c.Backup == "" {
+			out.Removed = append(out.Removed, c.Path)
+		} else {
+			out.Restored = append(out.Restored, c.Path)
+		}
+	}
+	if len(ops) == 1 {
+		delete(j.ops, session)
+		j.sessions.forget(session)
+	} else {
+		j.ops[session] = ops[:len(ops)-1]
+	}
+	return out, nil
+}
+
+// check returns an error when a file op wrote or moved is no longer as op
+// left it.
+func (op fileOperation) check() error {
+	for _, c := range op.changes {
+		current, err := os.ReadFile(c.Path)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(current, c.Content) {
+			return fmt.Errorf("%s changed since it was written", c.Path)
+		}
+	}
+	for _, m := range op.moves {
+		if _, err := os.Lstat(m.To); err != nil {
+			return err
+		}
+		if _, err := os.Lstat(m.From); err == nil {
+			return fmt.Errorf("%s was created again since it was moved", m.From)
+		}
+	}
+	return nil
+}
+
+// undoneOperation is the undo_fixes output: the tool whose write was
+// reverted, the files given back their previous content or path, the files
+// it created that were removed, and how many operations are left to undo.
+type undoneOperation struct {
+	Tool      string   `json:"tool"`
+	Restored  []string `json:"restored"`
+	Removed   []string `json:"removed,omitempty"`
+	Remaining int      `json:"remaining"`
+}
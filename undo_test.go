@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enableWrites lets the fixer tools write for the duration of t, keeping
+// their backups in a temporary directory.
+func enableWrites(t *testing.T) {
+	t.Helper()
+	t.Setenv("TMPDIR", t.TempDir())
+	useSettings(t, serverConfig{}, true)
+}
+
+func undo(t *testing.T, session *mcp.ServerSession) (undoneOperation, error) {
+	t.Helper()
+	var out undoneOperation
+	result, err := UndoFixes(context.Background(), session, &mcp.CallToolParamsFor[UndoFixesParams]{})
+	if err != nil {
+		return out, err
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out))
+	return out, nil
+}
+
+func TestUndoFixes(t *testing.T) {
+	enableWrites(t)
+	session := &mcp.ServerSession{}
+	root := t.TempDir()
+	path := filepath.Join(root, ".github", "workflows", "ci.yml")
+	workflow := "on: push\njobs:\n    test:\n        runs-on: ubuntu-latest\n        steps:\n        - run: echo \"::add-path::/opt/bin\"\n"
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(workflow), 0644))
+
+	_, err := undo(t, session)
+	assert.ErrorContains(t, err, "no file changes to undo")
+
+	_, err = ApplyFixes(context.Background(), session, &mcp.CallToolParamsFor[ApplyFixesParams]{
+		Arguments: ApplyFixesParams{FilePath: path},
+	})
+	require.NoError(t, err)
+	fixed, err := os.ReadFile(path)
+	require.NoError(t, err)
+	_, err = ExtractScript(context.Background(), session, &mcp.CallToolParamsFor[ExtractScriptParams]{
+		Arguments: ExtractScriptParams{FilePath: path, Job: "test", Step: 1},
+	})
+	require.NoError(t, err)
+	script := filepath.Join(root, "scripts", "ci-test-step1.sh")
+	require.FileExists(t, script)
+
+	// Writes of other sessions are not undone
+	_, err = undo(t, &mcp.ServerSession{})
+	assert.Error(t, err)
+
+	undone, err := undo(t, session)
+	require.NoError(t, err)
+	assert.Equal(t, undoneOperation{Tool: "extract_script", Restored: []string{path}, Removed: []string{script}, Remaining: 1}, undone)
+	assert.NoFileExists(t, script)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(fixed), string(content))
+
+	undone, err = undo(t, session)
+	require.NoError(t, err)
+	assert.Equal(t, "apply_fixes", undone.Tool)
+	assert.Equal(t, 0, undone.Remaining)
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, workflow, string(content))
+
+	_, err = undo(t, session)
+	assert.ErrorContains(t, err, "no file changes to undo")
+}
+
+func TestUndoFixes_KeepsLaterEdits(t *testing.T) {
+	enableWrites(t)
+	session := &mcp.ServerSession{}
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	require.NoError(t, os.WriteFile(path, []byte("jobs:\n    test:\n        runs-on: ubuntu-latest\n        steps:\n        - run: echo hi\non: push\n"), 0644))
+
+	_, err := FormatWorkflow(context.Background(), session, &mcp.CallToolParamsFor[FormatWorkflowParams]{
+		Arguments: FormatWorkflowParams{FilePath: path, Write: true},
+	})
+	require.NoError(t, err)
+	edited := "on: push\njobs: {}\n"
+	require.NoError(t, os.WriteFile(path, []byte(edited), 0644))
+
+	_, err = undo(t, session)
+	assert.ErrorContains(t, err, "changed since it was written")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, edited, string(content))
+}
+
+func TestUndoFixes_Moves(t *testing.T) {
+	enableWrites(t)
+	session := &mcp.ServerSession{}
+	root := t.TempDir()
+	misplaced := filepath.Join(root, "ci", "build.yml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(misplaced), 0755))
+	require.NoError(t, os.WriteFile(misplaced, []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n"), 0644))
+
+	_, err := MoveMisplacedWorkflows(context.Background(), session, &mcp.CallToolParamsFor[MoveMisplacedWorkflowsParams]{
+		Arguments: MoveMisplacedWorkflowsParams{Directory: root},
+	})
+	require.NoError(t, err)
+	require.NoFileExists(t, misplaced)
+
+	undone, err := undo(t, session)
+	require.NoError(t, err)
+	assert.Equal(t, undoneOperation{Tool: "move_misplaced_workflows", Restored: []string{misplaced}}, undone)
+	assert.FileExists(t, misplaced)
+	assert.NoFileExists(t, filepath.Join(root, ".github", "workflows", "build.yml"))
+}
+
+func TestUndoJournal_SessionEnd(t *testing.T) {
+	enableWrites(t)
+	path := filepath.Join(t.TempDir(), "ci.yml")
+	require.NoError(t, os.WriteFile(path, []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo \"::add-path::/opt/bin\"\n"), 0644))
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := newServer().Connect(ctx, serverTransport)
+	require.NoError(t, err)
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil).Connect(ctx, clientTransport)
+	require.NoError(t, err)
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "apply_fixes", Arguments: map[string]any{"file_path": path}})
+	require.NoError(t, err)
+	require.False(t, res.IsError)
+	pending := func() int {
+		fileOperations.mu.Lock()
+		defer fileOperations.mu.Unlock()
+		return len(fileOperations.ops[serverSession])
+	}
+	require.Equal(t, 1, pending())
+
+	require.NoError(t, session.Close())
+	assert.Eventually(t, func() bool { return pending() == 0 }, 5*time.Second, 10*time.Millisecond, "the journal is forgotten when the session ends")
+}
+
+func TestUndoJournal_MaxSessions(t *testing.T) {
+	journal := &undoJournal{ops: map[*mcp.ServerSession][]fileOperation{}}
+	sessions := make([]*mcp.ServerSession, maxSessions+1)
+	for i := range sessions {
+		sessions[i] = &mcp.ServerSession{}
+		journal.record(sessions[i], fileOperation{tool: "apply_fixes"})
+	}
+	assert.Len(t, journal.ops, maxSessions)
+	_, ok := journal.ops[sessions[0]]
+	assert.False(t, ok, "the least recently used session is forgotten")
+	_, ok = journal.ops[sessions[1]]
+	assert.True(t, ok)
+}
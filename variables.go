@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+)
+
+// variablesPageSize is the page size used to list variables, the most the
+// API allows.
+const variablesPageSize = 30
+
+// fetchVariables lists the names of the configuration variables visible to
+// workflows of repo, given as owner/name: its own and those its
+// organization shares with it.
+func fetchVariables(ctx context.Context, client *http.Client, repo string) ([]string, error) {
+	seen := map[string]bool{}
+	for _, endpoint := range []string{"variables", "organization-variables"} {
+		names, err := fetchNames(ctx, client, fmt.Sprintf("repos/%s/actions/%s", repo, endpoint), "variables")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", strings.ReplaceAll(endpoint, "-", " "), err)
+		}
+		for _, name := range names {
+			seen[strings.ToUpper(name)] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// labelsPageSize is the page size used to list labels, the most the API
+// allows.
+const labelsPageSize = 100
+
+// fetchLabels lists the names of the labels of repo, given as owner/name.
+func fetchLabels(ctx context.Context, client *http.Client, repo string) ([]string, error) {
+	names := []string{}
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s/repos/%s/labels?per_page=%d&page=%d", githubAPIBaseURL, repo, labelsPageSize, page)
+		data, err := githubGet(ctx, client, url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list labels: %w", err)
+		}
+		var labels []struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(data, &labels); err != nil {
+			return nil, fmt.Errorf("failed to list labels: %w", err)
+		}
+		for _, l := range labels {
+			names = append(names, l.Name)
+		}
+		if len(labels) < labelsPageSize {
+			sort.Strings(names)
+			return names, nil
+		}
+	}
+}
+
+// fetchNames lists the names of the secrets or variables at path of the
+// API, which lists them under key.
+func fetchNames(ctx context.Context, client *http.Client, path, key string) ([]string, error) {
+	var names []string
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s/%s?per_page=%d&page=%d", githubAPIBaseURL, path, variablesPageSize, page)
+		data, err := githubGet(ctx, client, url)
+		if err != nil {
+			return nil, err
+		}
+		var p map[string]json.RawMessage
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, err
+		}
+		var total int
+		var items []struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(p["total_count"], &total); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(p[key], &items); err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			names = append(names, item.Name)
+		}
+		if len(items) < variablesPageSize || page*variablesPageSize >= total {
+			return names, nil
+		}
+	}
+}
+
+// fetchSettings lists the names of the secrets and variables of repo at
+// each scope: its own, those its organization shares with it, and those of
+// environments. Organizations and environments that do not exist have none.
+func fetchSettings(ctx context.Context, client *http.Client, repo string, environments []string) ([]linter.SettingsScope, error) {
+	scopes := []linter.SettingsScope{{Scope: linter.ScopeRepository}, {Scope: linter.ScopeOrganization}}
+	prefixes := []string{"repos/" + repo + "/actions/", "repos/" + repo + "/actions/organization-"}
+	for _, env := range environments {
+		scopes = append(scopes, linter.SettingsScope{Scope: linter.ScopeEnvironment, Environment: env})
+		prefixes = append(prefixes, "repos/"+repo+"/environments/"+url.PathEscape(env)+"/")
+	}
+
+	for i := range scopes {
+		s := &scopes[i]
+		for _, key := range []string{"secrets", "variables"} {
+			names, err := fetchNames(ctx, client, prefixes[i]+key, key)
+			var status *statusError
+			if errors.As(err, &status) && status.code == http.StatusNotFound && s.Scope != linter.ScopeRepository {
+				continue
+			}
+			if err != nil {
+				where := s.Scope
+				if s.Environment != "" {
+					where += " " + s.Environment
+				}
+				return nil, fmt.Errorf("failed to list %s %s: %w", where, key, err)
+			}
+			if key == "secrets" {
+				s.Secrets = names
+			} else {
+				s.Variables = names
+			}
+		}
+	}
+	return scopes, nil
+}
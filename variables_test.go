@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckVariables(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/actions/variables":
+			if r.URL.Query().Get("page") == "1" {
+				names := ""
+				for i := 1; i < variablesPageSize; i++ {
+					names += fmt.Sprintf(`{"name": "VAR_%d"},`, i)
+				}
+				fmt.Fprintf(w, `{"total_count": 31, "variables": [%s{"name": "image"}]}`, names)
+				return
+			}
+			_, _ = w.Write([]byte(`{"total_count": 31, "variables": [{"name": "LAST"}]}`))
+		case "/repos/owner/repo/actions/organization-variables":
+			_, _ = w.Write([]byte(`{"total_count": 1, "variables": [{"name": "ORG_WIDE"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	oldURL := githubAPIBaseURL
+	githubAPIBaseURL = server.URL
+	defer func() { githubAPIBaseURL = oldURL }()
+
+	dir := t.TempDir()
+	workflow := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ vars.IMAGE }} ${{ vars.LAST }} ${{ vars.ORG_WIDE }} ${{ vars.MISSING }}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ci.yml"), []byte(workflow), 0644))
+
+	call := func(args CheckVariablesParams) variablesResult {
+		t.Helper()
+		result, err := CheckVariables(context.Background(), nil, &mcp.CallToolParamsFor[CheckVariablesParams]{Arguments: args})
+		require.NoError(t, err)
+		var out variablesResult
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out))
+		return out
+	}
+
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	useSettings(t, serverConfig{}, false)
+	fetched := call(CheckVariablesParams{Directory: dir, Repository: "owner/repo"})
+	assert.Equal(t, "github:owner/repo", fetched.Source)
+	assert.Len(t, fetched.Variables, 32)
+	assert.Equal(t, 1, fetched.TotalErrors)
+	require.Len(t, fetched.Results, 1)
+	assert.Contains(t, fetched.Results[0].Errors[0].Message, `"MISSING"`)
+
+	provided := call(CheckVariablesParams{FilePath: filepath.Join(dir, "ci.yml"), Variables: []string{"IMAGE"}})
+	assert.Equal(t, "provided", provided.Source)
+	assert.Equal(t, 3, provided.TotalErrors)
+
+	t.Setenv("GITHUB_TOKEN", "")
+	useSettings(t, serverConfig{}, false)
+	_, err := CheckVariables(context.Background(), nil, &mcp.CallToolParamsFor[CheckVariablesParams]{
+		Arguments: CheckVariablesParams{Directory: dir, Repository: "owner/repo"},
+	})
+	assert.ErrorContains(t, err, "set GITHUB_TOKEN")
+}
+
+func TestProvisioningChecklist(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/actions/secrets":
+			_, _ = w.Write([]byte(`{"total_count": 1, "secrets": [{"name": "NPM_TOKEN"}]}`))
+		case "/repos/owner/repo/actions/variables", "/repos/owner/repo/environments/production/variables":
+			_, _ = w.Write([]byte(`{"total_count": 0, "variables": []}`))
+		case "/repos/owner/repo/environments/production/secrets":
+			_, _ = w.Write([]byte(`{"total_count": 1, "secrets": [{"name": "DEPLOY_KEY"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	oldURL := githubAPIBaseURL
+	githubAPIBaseURL = server.URL
+	defer func() { githubAPIBaseURL = oldURL }()
+
+	dir := t.TempDir()
+	workflow := "on: push\njobs:\n  deploy:\n    runs-on: ubuntu-latest\n    environment: production\n    steps:\n      - run: ./deploy.sh ${{ vars.REGION }}\n        env:\n          KEY: ${{ secrets.DEPLOY_KEY }}\n          NPM: ${{ secrets.NPM_TOKEN }}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "deploy.yml"), []byte(workflow), 0644))
+
+	call := func(args ProvisioningChecklistParams) (provisioningResult, error) {
+		t.Helper()
+		result, err := ProvisioningChecklist(context.Background(), nil, &mcp.CallToolParamsFor[ProvisioningChecklistParams]{Arguments: args})
+		if err != nil {
+			return provisioningResult{}, err
+		}
+		var out provisioningResult
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out))
+		return out, nil
+	}
+
+	t.Setenv("GITHUB_TOKEN", "")
+	useSettings(t, serverConfig{}, false)
+	offline, err := call(ProvisioningChecklistParams{Directory: dir})
+	require.NoError(t, err)
+	assert.Empty(t, offline.Source)
+	require.Len(t, offline.Checklist, 3)
+	assert.Empty(t, offline.Checklist[0].Status)
+
+	_, err = call(ProvisioningChecklistParams{Directory: dir, Compare: true, Repository: "owner/repo"})
+	assert.ErrorContains(t, err, "set GITHUB_TOKEN")
+
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	useSettings(t, serverConfig{}, false)
+	compared, err := call(ProvisioningChecklistParams{Directory: dir, Compare: true, Repository: "owner/repo"})
+	require.NoError(t, err)
+	assert.Equal(t, "github:owner/repo", compared.Source)
+	status := map[string]string{}
+	for _, item := range compared.Checklist {
+		status[item.Name] = item.Status
+	}
+	assert.Equal(t, map[string]string{"DEPLOY_KEY": "provisioned", "NPM_TOKEN": "provisioned", "REGION": "missing"}, status)
+}
+
+func TestCheckLabels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/labels" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("page") == "1" {
+			names := ""
+			for i := 1; i < labelsPageSize; i++ {
+				names += fmt.Sprintf(`{"name": "label-%d"},`, i)
+			}
+			fmt.Fprintf(w, `[%s{"name": "Bug"}]`, names)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"name": "deploy"}]`))
+	}))
+	defer server.Close()
+
+	oldURL := githubAPIBaseURL
+	githubAPIBaseURL = server.URL
+	defer func() { githubAPIBaseURL = oldURL }()
+
+	dir := t.TempDir()
+	workflow := "on: issues\njobs:\n  triage:\n    runs-on: ubuntu-latest\n    steps:\n      - run: gh issue edit 1 --add-label bug,deploy,missing\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "triage.yml"), []byte(workflow), 0644))
+
+	call := func(args CheckLabelsParams) labelsResult {
+		t.Helper()
+		result, err := CheckLabels(context.Background(), nil, &mcp.CallToolParamsFor[CheckLabelsParams]{Arguments: args})
+		require.NoError(t, err)
+		var out labelsResult
+		require.NoError(t, json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &out))
+		return out
+	}
+
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	useSettings(t, serverConfig{}, false)
+	fetched := call(CheckLabelsParams{Directory: dir, Repository: "owner/repo"})
+	assert.Equal(t, "github:owner/repo", fetched.Source)
+	assert.Len(t, fetched.Labels, 101)
+	assert.Equal(t, 1, fetched.TotalErrors)
+	require.Len(t, fetched.Results, 1)
+	assert.Contains(t, fetched.Results[0].Errors[0].Message, `"missing"`)
+
+	provided := call(CheckLabelsParams{FilePath: filepath.Join(dir, "triage.yml"), Labels: []string{"bug"}})
+	assert.Equal(t, "provided", provided.Source)
+	assert.Equal(t, 2, provided.TotalErrors)
+
+	t.Setenv("GITHUB_TOKEN", "")
+	useSettings(t, serverConfig{}, false)
+	_, err := CheckLabels(context.Background(), nil, &mcp.CallToolParamsFor[CheckLabelsParams]{
+		Arguments: CheckLabelsParams{Directory: dir, Repository: "owner/repo"},
+	})
+	assert.ErrorContains(t, err, "set GITHUB_TOKEN")
+}
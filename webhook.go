@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+)
+
+// webhookTimeout bounds the delivery of one summary.
+const webhookTimeout = 10 * time.Second
+
+// signatureHeader carries the HMAC of a delivery, named and formatted as
+// GitHub's, so existing webhook receivers can verify it.
+const signatureHeader = "X-Hub-Signature-256"
+
+// webhookConfig sends the summary of each check_all_workflows run to URL.
+// SecretEnv names the environment variable holding the key deliveries are
+// signed with, so the secret is not kept in the config file.
+type webhookConfig struct {
+	URL       string `yaml:"url"`
+	SecretEnv string `yaml:"secret-env"`
+}
+
+func (c webhookConfig) validate() error {
+	if c.URL == "" {
+		if c.SecretEnv != "" {
+			return fmt.Errorf("webhook: secret-env needs url")
+		}
+		return nil
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("webhook: url must be an http or https URL, not %q", c.URL)
+	}
+	return nil
+}
+
+// webhookPayload is the body of a delivery.
+type webhookPayload struct {
+	SchemaVersion int             `json:"schema_version"`
+	Event         string          `json:"event"`
+	Repository    string          `json:"repository"`
+	Directory     string          `json:"directory"`
+	Summary       *linter.Summary `json:"summary"`
+}
+
+// signPayload returns the signature header value of body under secret.
+func signPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// sendWebhook posts summary to the configured webhook, signed with secret,
+// the value of cfg.SecretEnv, when one is configured.
+func sendWebhook(ctx context.Context, client *http.Client, cfg webhookConfig, secret, directory string, summary *linter.Summary) error {
+	body, err := json.Marshal(webhookPayload{
+		SchemaVersion: linter.SchemaVersion,
+		Event:         "check_all_workflows",
+		Repository:    historyRepository(directory),
+		Directory:     directory,
+		Summary:       summary,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "actionlint-mcp/"+version)
+	if cfg.SecretEnv != "" {
+		if secret == "" {
+			return fmt.Errorf("%s is not set, so the summary cannot be signed", cfg.SecretEnv)
+		}
+		req.Header.Set(signatureHeader, signPayload(secret, body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver summary: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("POST %s: unexpected status %s", req.URL.Redacted(), resp.Status)
+	}
+	return nil
+}
+
+// exportSummary sends summary to the webhook when one is configured.
+// Failing to deliver does not fail the lint run, so errors are only logged.
+func exportSummary(ctx context.Context, directory string, summary *linter.Summary) {
+	s := settingsFrom(ctx)
+	if s.config.Webhook.URL == "" {
+		return
+	}
+	if err := sendWebhook(ctx, http.DefaultClient, s.config.Webhook, s.webhookSecret, directory, summary); err != nil {
+		log.Printf("webhook: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hongkongkiwi/actionlint-mcp/pkg/linter"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendWebhook(t *testing.T) {
+	var body []byte
+	var signature string
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(signatureHeader)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	summary := &linter.Summary{TotalFiles: 2, TotalErrors: 1, Results: []linter.LintResult{}}
+	cfg := webhookConfig{URL: server.URL, SecretEnv: "LINT_WEBHOOK_SECRET"}
+	require.NoError(t, sendWebhook(context.Background(), server.Client(), cfg, "s3cret", "ci", summary))
+
+	var payload webhookPayload
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, linter.SchemaVersion, payload.SchemaVersion)
+	assert.Equal(t, "check_all_workflows", payload.Event)
+	assert.Equal(t, "ci", payload.Directory)
+	assert.Equal(t, 1, payload.Summary.TotalErrors)
+	assert.Equal(t, signPayload("s3cret", body), signature)
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, signature)
+
+	// Unsigned without a secret
+	require.NoError(t, sendWebhook(context.Background(), server.Client(), webhookConfig{URL: server.URL}, "", "ci", summary))
+	assert.Empty(t, signature)
+
+	status = http.StatusBadGateway
+	assert.ErrorContains(t, sendWebhook(context.Background(), server.Client(), webhookConfig{URL: server.URL}, "", "ci", summary), "502")
+
+	assert.ErrorContains(t, sendWebhook(context.Background(), server.Client(), cfg, "", "ci", summary), "LINT_WEBHOOK_SECRET is not set")
+}
+
+func TestWebhookConfigValidate(t *testing.T) {
+	assert.NoError(t, webhookConfig{}.validate())
+	assert.NoError(t, webhookConfig{URL: "https://hooks.example.com/lint", SecretEnv: "SECRET"}.validate())
+	assert.ErrorContains(t, webhookConfig{URL: "ftp://hooks.example.com"}.validate(), "http or https URL")
+	assert.ErrorContains(t, webhookConfig{SecretEnv: "SECRET"}.validate(), "needs url")
+}
+
+func TestCheckAllWorkflows_Webhook(t *testing.T) {
+	received := make(chan webhookPayload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhookPayload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		received <- payload
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ci.yml"), []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo hi\n"), 0644))
+
+	useSettings(t, serverConfig{Webhook: webhookConfig{URL: server.URL}}, false)
+
+	_, err := CheckAllWorkflows(context.Background(), nil, &mcp.CallToolParamsFor[CheckAllWorkflowsParams]{Arguments: CheckAllWorkflowsParams{Directory: dir}})
+	require.NoError(t, err)
+	payload := <-received
+	assert.Equal(t, 1, payload.Summary.TotalFiles)
+	assert.Equal(t, dir, payload.Repository)
+}